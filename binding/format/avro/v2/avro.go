@@ -51,26 +51,131 @@ func StringOfApplicationCloudEventsAvro() *string {
 	return &a
 }
 
-type avroFmt struct{}
+type avroFmt struct {
+	api             avro.API
+	singleObject    bool
+	strict          bool
+	timestampMicros bool
+}
+
+// FormatOption configures a format returned by NewFormat.
+type FormatOption func(*avroFmt)
+
+// WithSingleObjectEncoding makes the format use the Avro single-object
+// encoding: the events are prefixed with the C3 01 marker and the CRC-64-AVRO
+// fingerprint of the CloudEvents schema, which is verified when unmarshaling,
+// so that receivers do not decode events written with another schema.
+func WithSingleObjectEncoding() FormatOption {
+	return func(f *avroFmt) {
+		f.singleObject = true
+	}
+}
+
+// WithTimestampMicros makes the format encode the time attribute as an Avro
+// long with the timestamp-micros logical type, rather than an RFC 3339 string,
+// with the schema.CloudEventTimestampMicros schema. The consumers must decode
+// the events with this schema, which the format does with or without the
+// option, so that it accepts both representations.
+func WithTimestampMicros() FormatOption {
+	return func(f *avroFmt) {
+		f.timestampMicros = true
+	}
+}
+
+// WithFormatAPI makes the format marshal and unmarshal the events with api,
+// rather than avro.DefaultConfig, e.g. one frozen from an avro.Config raising
+// the MaxByteSliceSize of the data of the events, 1 MiB by default.
+func WithFormatAPI(api avro.API) FormatOption {
+	return func(f *avroFmt) {
+		f.api = api
+	}
+}
+
+// NewFormat returns the "application/cloudevents+avro" format configured by
+// the options. Registered with format.Add, it replaces the built-in Avro
+// format, e.g. for the structured messages of the bindings. It implements
+// StreamFormat.
+func NewFormat(opts ...FormatOption) format.Format {
+	f := avroFmt{}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	return f
+}
 
 func (avroFmt) MediaType() string {
 	return ApplicationCloudEventsAvro
 }
 
-func (avroFmt) Marshal(e *event.Event) ([]byte, error) {
-	record, err := ToAvro(e)
+func (f avroFmt) Marshal(e *event.Event) ([]byte, error) {
+	s, record, err := f.toRecord(e)
 	if err != nil {
 		return nil, err
 	}
-	return avro.Marshal(schema.CloudEvent, record)
+	b, err := f.avroAPI().Marshal(s, record)
+	if err != nil || !f.singleObject {
+		return b, err
+	}
+	return append(AppendSingleObjectHeader(make([]byte, 0, singleObjectHeaderSize+len(b)), s), b...), nil
 }
 
-func (avroFmt) Unmarshal(b []byte, e *event.Event) error {
+func (f avroFmt) Unmarshal(b []byte, e *event.Event) error {
+	// The timestamps extend the union of the attribute values, so this
+	// schema decodes the events encoded with or without them.
+	s := schema.CloudEventTimestampMicros
+	if f.singleObject {
+		var err error
+		if b, s, err = unframeSingleObject(b, f.writerSchemas()...); err != nil {
+			return err
+		}
+	}
 	record := &schema.CloudEventRecord{}
-	if err := avro.Unmarshal(schema.CloudEvent, b, record); err != nil {
+	if err := f.avroAPI().Unmarshal(s, b, record); err != nil {
 		return err
 	}
-	e2, err := FromAvro(record)
+	return f.fromRecord(record, e)
+}
+
+// avroAPI returns the API of the format, see WithFormatAPI.
+func (f avroFmt) avroAPI() avro.API {
+	if f.api == nil {
+		return avro.DefaultConfig
+	}
+	return f.api
+}
+
+// toRecord converts e to the record marshaled by the format, and returns the
+// schema to marshal it with.
+func (f avroFmt) toRecord(e *event.Event) (avro.Schema, *schema.CloudEventRecord, error) {
+	record, err := ToAvro(e)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !f.timestampMicros {
+		return schema.CloudEvent, record, nil
+	}
+	if _, ok := record.Attribute[time]; ok {
+		record.Attribute[time] = e.Time()
+	}
+	return schema.CloudEventTimestampMicros, record, nil
+}
+
+// writerSchemas returns the schemas of the events in the single-object
+// encoding the format accepts, the one it writes first.
+func (f avroFmt) writerSchemas() []avro.Schema {
+	if f.timestampMicros {
+		return []avro.Schema{schema.CloudEventTimestampMicros, schema.CloudEvent}
+	}
+	return []avro.Schema{schema.CloudEvent, schema.CloudEventTimestampMicros}
+}
+
+// fromRecord converts the unmarshaled record into e.
+func (f avroFmt) fromRecord(record *schema.CloudEventRecord, e *event.Event) error {
+	fromAvro := FromAvro
+	if f.strict {
+		fromAvro = FromAvroStrict
+	}
+	e2, err := fromAvro(record)
 	if err != nil {
 		return err
 	}
@@ -154,6 +259,8 @@ func attributeValueFor(v interface{}) (any, error) {
 }
 
 // FromAvro converts an Avro record back into the generic SDK event.
+// The attributes of unexpected types are ignored, and the event is not
+// validated, see FromAvroStrict.
 func FromAvro(record *schema.CloudEventRecord) (*event.Event, error) {
 	e := event.New()
 
@@ -200,16 +307,20 @@ func FromAvro(record *schema.CloudEventRecord) (*event.Event, error) {
 				e.SetSubject(sv)
 			}
 		case time:
-			if sv, ok := value.(string); ok {
-				t, err := stdtime.Parse(stdtime.RFC3339Nano, sv)
+			switch tv := value.(type) {
+			case string:
+				t, err := stdtime.Parse(stdtime.RFC3339Nano, tv)
 				if err != nil {
 					// Try without nano precision
-					t, err = stdtime.Parse(stdtime.RFC3339, sv)
+					t, err = stdtime.Parse(stdtime.RFC3339, tv)
 					if err != nil {
 						return nil, fmt.Errorf("failed to parse time attribute: %w", err)
 					}
 				}
 				e.SetTime(t)
+			case stdtime.Time:
+				// Encoded as a timestamp-micros long, see WithTimestampMicros
+				e.SetTime(tv)
 			}
 		default:
 			// Extension attribute
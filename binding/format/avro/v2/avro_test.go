@@ -144,6 +144,45 @@ func TestAvroFormatWithNoData(t *testing.T) {
 	require.Nil(e2.Data())
 }
 
+func TestAvroFormatTimestampMicros(t *testing.T) {
+	require := require.New(t)
+	e := event.New()
+	e.SetID("test")
+	e.SetSource("test")
+	e.SetType("test")
+	e.SetTime(stdtime.Date(2024, 1, 2, 3, 4, 5, 123456789, stdtime.UTC))
+
+	f := avrofmt.NewFormat(avrofmt.WithTimestampMicros())
+	b, err := f.Marshal(&e)
+	require.NoError(err)
+	plain, err := avrofmt.Avro.Marshal(&e)
+	require.NoError(err)
+	require.Less(len(b), len(plain))
+
+	// Both representations are decoded, with or without the option
+	want := stdtime.Date(2024, 1, 2, 3, 4, 5, 123456000, stdtime.UTC)
+	for _, format := range []interface {
+		Unmarshal([]byte, *event.Event) error
+	}{f, avrofmt.Avro} {
+		var got event.Event
+		require.NoError(format.Unmarshal(b, &got))
+		require.True(want.Equal(got.Time()), got.Time())
+		require.NoError(format.Unmarshal(plain, &got))
+		require.True(e.Time().Equal(got.Time()), got.Time())
+	}
+
+	// And so are the single-object encodings
+	so := avrofmt.NewFormat(avrofmt.WithSingleObjectEncoding(), avrofmt.WithTimestampMicros())
+	b, err = so.Marshal(&e)
+	require.NoError(err)
+	fingerprint, _, err := avrofmt.ParseSingleObjectHeader(b)
+	require.NoError(err)
+	require.Equal(avrofmt.SchemaFingerprint(schema.CloudEventTimestampMicros), fingerprint)
+	var got event.Event
+	require.NoError(avrofmt.NewFormat(avrofmt.WithSingleObjectEncoding()).Unmarshal(b, &got))
+	require.True(want.Equal(got.Time()), got.Time())
+}
+
 func TestAvroFormatMediaType(t *testing.T) {
 	require.Equal(t, "application/cloudevents+avro", avrofmt.Avro.MediaType())
 	require.Equal(t, "application/cloudevents+avro", avrofmt.ApplicationCloudEventsAvro)
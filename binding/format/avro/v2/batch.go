@@ -0,0 +1,87 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hamba/avro/v2"
+
+	"github.com/cloudevents/sdk-go/binding/format/avro/v2/schema"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// ApplicationCloudEventsBatchAvro is the content type for batches of
+// CloudEvents in Avro format.
+const ApplicationCloudEventsBatchAvro = "application/cloudevents-batch+avro"
+
+// AvroBatch is the built-in "application/cloudevents-batch+avro" format, the
+// Avro encoding of an array of CloudEvent records. Like the JSON batch format,
+// it can not marshal single events: the batches are marshaled with
+// MarshalBatch and unmarshaled with UnmarshalBatch.
+var AvroBatch = avroBatchFmt{}
+
+func init() {
+	format.Add(AvroBatch)
+}
+
+// StringOfApplicationCloudEventsBatchAvro returns a string pointer to
+// "application/cloudevents-batch+avro"
+func StringOfApplicationCloudEventsBatchAvro() *string {
+	a := ApplicationCloudEventsBatchAvro
+	return &a
+}
+
+type avroBatchFmt struct{}
+
+func (avroBatchFmt) MediaType() string {
+	return ApplicationCloudEventsBatchAvro
+}
+
+// Marshal returns an error, see MarshalBatch.
+func (avroBatchFmt) Marshal(e *event.Event) ([]byte, error) {
+	return nil, errors.New("not supported for batch events")
+}
+
+// Unmarshal returns an error, see UnmarshalBatch.
+func (avroBatchFmt) Unmarshal(b []byte, e *event.Event) error {
+	return errors.New("not supported for batch events")
+}
+
+// MarshalBatch returns the "application/cloudevents-batch+avro" encoding of
+// events, amortizing the per-message overhead of the transports over the
+// batch.
+func MarshalBatch(events []event.Event) ([]byte, error) {
+	records := make([]*schema.CloudEventRecord, len(events))
+	for i := range events {
+		record, err := ToAvro(&events[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert event %d: %w", i, err)
+		}
+		records[i] = record
+	}
+	return avro.Marshal(schema.CloudEventBatch, records)
+}
+
+// UnmarshalBatch returns the events of b, in the
+// "application/cloudevents-batch+avro" encoding.
+func UnmarshalBatch(b []byte) ([]event.Event, error) {
+	var records []*schema.CloudEventRecord
+	if err := avro.Unmarshal(schema.CloudEventBatch, b, &records); err != nil {
+		return nil, err
+	}
+	events := make([]event.Event, len(records))
+	for i, record := range records {
+		e, err := FromAvro(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert event %d: %w", i, err)
+		}
+		events[i] = *e
+	}
+	return events, nil
+}
@@ -0,0 +1,52 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+func TestAvroBatchFormat(t *testing.T) {
+	require.Equal(t, avrofmt.AvroBatch, format.Lookup(avrofmt.ApplicationCloudEventsBatchAvro))
+	require.Equal(t, avrofmt.ApplicationCloudEventsBatchAvro, *avrofmt.StringOfApplicationCloudEventsBatchAvro())
+
+	e := event.New()
+	_, err := avrofmt.AvroBatch.Marshal(&e)
+	require.Error(t, err)
+	require.Error(t, avrofmt.AvroBatch.Unmarshal(nil, &e))
+}
+
+func TestMarshalBatch(t *testing.T) {
+	events := ocfEvents(t, 5)
+	b, err := avrofmt.MarshalBatch(events)
+	require.NoError(t, err)
+
+	got, err := avrofmt.UnmarshalBatch(b)
+	require.NoError(t, err)
+	require.Len(t, got, len(events))
+	for i := range events {
+		require.Equal(t, events[i].ID(), got[i].ID())
+		require.Equal(t, events[i].DataContentType(), got[i].DataContentType())
+		require.Equal(t, events[i].Data(), got[i].Data())
+		require.Equal(t, events[i].Extensions(), got[i].Extensions())
+	}
+
+	empty, err := avrofmt.MarshalBatch(nil)
+	require.NoError(t, err)
+	got, err = avrofmt.UnmarshalBatch(empty)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = avrofmt.UnmarshalBatch([]byte{0x02, 0x02, 0x01})
+	require.Error(t, err)
+}
@@ -0,0 +1,147 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/hamba/avro/v2"
+)
+
+// confluentMagicByte starts the payloads in the Confluent Schema Registry wire
+// format, followed by the 4-byte big-endian ID of the schema.
+const confluentMagicByte = 0
+
+// SubjectRegistry registers schemas under the subjects of a remote schema
+// registry, such as Confluent Schema Registry, and returns their global IDs.
+// Implementations must be safe for concurrent use.
+type SubjectRegistry interface {
+	Register(ctx context.Context, subject string, schema avro.Schema) (int, error)
+}
+
+// SubjectNameStrategy returns the subject the schema of the keys (key is
+// true) or of the values sent to topic are registered under.
+type SubjectNameStrategy func(topic string, schema avro.NamedSchema, key bool) string
+
+// TopicNameStrategy registers the schemas under "<topic>-key" and
+// "<topic>-value", the default of the Confluent serializers.
+func TopicNameStrategy(topic string, schema avro.NamedSchema, key bool) string {
+	return topic + subjectSuffix(key)
+}
+
+// RecordNameStrategy registers the schemas under their full name, so that
+// several topics share them.
+func RecordNameStrategy(topic string, schema avro.NamedSchema, key bool) string {
+	return schema.FullName()
+}
+
+// TopicRecordNameStrategy registers the schemas under "<topic>-<full name>",
+// so that a topic holds several record types.
+func TopicRecordNameStrategy(topic string, schema avro.NamedSchema, key bool) string {
+	return topic + "-" + schema.FullName()
+}
+
+func subjectSuffix(key bool) string {
+	if key {
+		return "-key"
+	}
+	return "-value"
+}
+
+// appendConfluentHeader appends the wire format header of the schema with the
+// given ID to b.
+func appendConfluentHeader(b []byte, id int) []byte {
+	b = append(b, confluentMagicByte)
+	return binary.BigEndian.AppendUint32(b, uint32(id))
+}
+
+// ErrNotConfluentWireFormat is returned when decoding data which does not
+// start with the header of the Confluent Schema Registry wire format.
+var ErrNotConfluentWireFormat = errors.New("data is not in the Confluent Schema Registry wire format")
+
+// ParseConfluentHeader splits data in the Confluent Schema Registry wire
+// format into the ID of its writer schema and its Avro payload.
+func ParseConfluentHeader(data []byte) (int, []byte, error) {
+	if len(data) < 5 || data[0] != confluentMagicByte {
+		return 0, nil, ErrNotConfluentWireFormat
+	}
+	return int(binary.BigEndian.Uint32(data[1:5])), data[5:], nil
+}
+
+// ConfluentWireFormat makes EncodeData and DecodeData use the Confluent Schema
+// Registry wire format: the Avro payload is prefixed with a zero magic byte
+// and the 4-byte ID of its schema in the registry, as expected by the
+// standard Kafka Avro serializers.
+type ConfluentWireFormat struct {
+	// Registry registers the schemas of the encoded values. As it is called
+	// for each encoded value, it should cache the IDs of the schemas.
+	Registry SubjectRegistry
+	// Subject is the subject the schemas are registered under. Empty uses
+	// the full name of the schemas, as RecordNameStrategy.
+	Subject string
+}
+
+// schemaID returns the ID of schema in the registry of w.
+func (w *ConfluentWireFormat) schemaID(ctx context.Context, schema avro.Schema) (int, error) {
+	if w.Registry == nil {
+		return 0, errors.New("the Confluent wire format has no registry")
+	}
+	subject := w.Subject
+	if subject == "" {
+		named, ok := schema.(avro.NamedSchema)
+		if !ok {
+			return 0, fmt.Errorf("the Confluent wire format needs a subject for the unnamed %s schema", schema.Type())
+		}
+		subject = named.FullName()
+	}
+	id, err := w.Registry.Register(ctx, subject, schema)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register schema under subject %q: %w", subject, err)
+	}
+	return id, nil
+}
+
+// Frame implements WireFormat.Frame: it registers schema and prefixes payload
+// with the header of its ID.
+func (w *ConfluentWireFormat) Frame(ctx context.Context, schema avro.Schema, payload []byte) ([]byte, error) {
+	id, err := w.schemaID(ctx, schema)
+	if err != nil {
+		return nil, err
+	}
+	return append(appendConfluentHeader(make([]byte, 0, 5+len(payload)), id), payload...), nil
+}
+
+// Unframe implements WireFormat.Unframe: it strips the header of data.
+func (w *ConfluentWireFormat) Unframe(ctx context.Context, data []byte) ([]byte, error) {
+	_, payload, err := ParseConfluentHeader(data)
+	return payload, err
+}
+
+// SetConfluentWireFormat sets the Confluent Schema Registry wire format as the
+// default wire format, see SetWireFormat. Nil restores the plain Avro binary
+// encoding.
+func SetConfluentWireFormat(w *ConfluentWireFormat) {
+	if w == nil {
+		SetWireFormat(nil)
+		return
+	}
+	SetWireFormat(w)
+}
+
+// WithConfluentWireFormat returns a context making EncodeData and DecodeData
+// use the Confluent Schema Registry wire format w, see WithWireFormat. A nil w
+// uses the plain Avro binary encoding.
+func WithConfluentWireFormat(ctx context.Context, w *ConfluentWireFormat) context.Context {
+	if w == nil {
+		return WithWireFormat(ctx, nil)
+	}
+	return WithWireFormat(ctx, w)
+}
+
+var _ WireFormat = (*ConfluentWireFormat)(nil)
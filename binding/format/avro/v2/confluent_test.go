@@ -0,0 +1,96 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+func TestDataCodecWithConfluentWireFormat(t *testing.T) {
+	require := require.New(t)
+	registry := newFakeRegistry()
+	ctx := avrofmt.WithConfluentWireFormat(context.Background(), &avrofmt.ConfluentWireFormat{Registry: registry})
+
+	original := &schemaProviderRecord{TestRecord: TestRecord{Name: "test-name", Value: 42}}
+	encoded, err := avrofmt.EncodeData(ctx, original)
+	require.NoError(err)
+	require.Equal([]byte{0, 0, 0, 0, 100}, encoded[:5])
+	require.Equal(map[string]int{"test.TestRecord": 100}, registry.subjects)
+
+	id, payload, err := avrofmt.ParseConfluentHeader(encoded)
+	require.NoError(err)
+	require.Equal(100, id)
+	plain, err := avrofmt.EncodeData(context.Background(), original)
+	require.NoError(err)
+	require.Equal(plain, payload)
+
+	decoded := &schemaProviderRecord{}
+	require.NoError(avrofmt.DecodeData(ctx, encoded, decoded))
+	require.Equal(original.TestRecord, decoded.TestRecord)
+
+	err = avrofmt.DecodeData(ctx, plain, decoded)
+	require.ErrorIs(err, avrofmt.ErrNotConfluentWireFormat)
+}
+
+func TestDataCodecWithConfluentWireFormatSubject(t *testing.T) {
+	require := require.New(t)
+	registry := newFakeRegistry()
+	ctx := avrofmt.WithConfluentWireFormat(context.Background(), &avrofmt.ConfluentWireFormat{
+		Registry: registry,
+		Subject:  "orders-value",
+	})
+
+	_, err := avrofmt.EncodeData(ctx, &schemaProviderRecord{})
+	require.NoError(err)
+	require.Equal(map[string]int{"orders-value": 100}, registry.subjects)
+}
+
+func TestSetConfluentWireFormat(t *testing.T) {
+	require := require.New(t)
+	avrofmt.SetConfluentWireFormat(&avrofmt.ConfluentWireFormat{Registry: newFakeRegistry()})
+	defer avrofmt.SetConfluentWireFormat(nil)
+
+	encoded, err := avrofmt.EncodeData(context.Background(), &schemaProviderRecord{})
+	require.NoError(err)
+	require.Equal(byte(0), encoded[0])
+	require.Len(encoded, 7)
+
+	// The context overrides the default wire format.
+	plain, err := avrofmt.EncodeData(avrofmt.WithConfluentWireFormat(context.Background(), nil), &schemaProviderRecord{})
+	require.NoError(err)
+	require.Equal(encoded[5:], plain)
+}
+
+func TestParseConfluentHeader(t *testing.T) {
+	_, _, err := avrofmt.ParseConfluentHeader([]byte{0, 0, 0})
+	require.ErrorIs(t, err, avrofmt.ErrNotConfluentWireFormat)
+	_, _, err = avrofmt.ParseConfluentHeader([]byte{1, 0, 0, 0, 1, 2})
+	require.ErrorIs(t, err, avrofmt.ErrNotConfluentWireFormat)
+
+	id, payload, err := avrofmt.ParseConfluentHeader([]byte{0, 0, 0, 1, 2, 42})
+	require.NoError(t, err)
+	require.Equal(t, 258, id)
+	require.Equal(t, []byte{42}, payload)
+}
+
+func TestResolveSchemas(t *testing.T) {
+	require := require.New(t)
+	registry := newFakeRegistry()
+	ctx := avrofmt.WithConfluentWireFormat(context.Background(), &avrofmt.ConfluentWireFormat{Registry: registry})
+
+	require.NoError(avrofmt.ResolveSchemas(ctx, &schemaProviderRecord{}))
+	require.Equal(map[string]int{"test.TestRecord": 100}, registry.subjects)
+
+	type noSchemaRecord struct{ Any interface{} }
+	err := avrofmt.ResolveSchemas(ctx, &schemaProviderRecord{}, &noSchemaRecord{})
+	require.ErrorContains(err, "noSchemaRecord")
+	require.ErrorContains(err, "no schema available")
+}
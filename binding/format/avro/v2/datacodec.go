@@ -7,7 +7,9 @@ package avro
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hamba/avro/v2"
 
@@ -30,27 +32,87 @@ func init() {
 // Users should implement this interface to provide schema resolution for their data types.
 type SchemaRegistry interface {
 	// GetSchema returns the Avro schema for the given type.
+	// It returns an error wrapping ErrSchemaNotFound, or a nil schema, when
+	// it knows no schema for the type, so that it is inferred instead.
 	GetSchema(v interface{}) (avro.Schema, error)
 }
 
-// defaultRegistry is a simple schema registry that can be set by the user.
-var defaultRegistry SchemaRegistry
+// ErrSchemaNotFound is wrapped by the errors of the SchemaRegistry knowing no
+// schema for a type.
+var ErrSchemaNotFound = errors.New("schema not found")
 
-// SetSchemaRegistry sets the default schema registry used for encoding/decoding.
+var (
+	defaultRegistryMu sync.RWMutex
+	// defaultRegistry is a simple schema registry that can be set by the user.
+	defaultRegistry SchemaRegistry
+)
+
+// SetSchemaRegistry sets the default schema registry used for encoding/decoding
+// when the context has none, see WithSchemaRegistry.
 func SetSchemaRegistry(r SchemaRegistry) {
+	defaultRegistryMu.Lock()
+	defer defaultRegistryMu.Unlock()
 	defaultRegistry = r
 }
 
+type schemaRegistryKey struct{}
+
+// WithSchemaRegistry returns a context making EncodeData and DecodeData look up
+// the schemas in r, overriding the registry set with SetSchemaRegistry, so that
+// clients with different registries can run in one process. A nil r uses no
+// registry.
+func WithSchemaRegistry(ctx context.Context, r SchemaRegistry) context.Context {
+	return context.WithValue(ctx, schemaRegistryKey{}, schemaRegistryValue{r})
+}
+
+// schemaRegistryValue wraps the schema registry of a context, so that a nil
+// registry can be told apart from no registry.
+type schemaRegistryValue struct {
+	r SchemaRegistry
+}
+
+// schemaRegistryFrom returns the schema registry of ctx, or the default one.
+func schemaRegistryFrom(ctx context.Context) SchemaRegistry {
+	if v, ok := ctx.Value(schemaRegistryKey{}).(schemaRegistryValue); ok {
+		return v.r
+	}
+	defaultRegistryMu.RLock()
+	defer defaultRegistryMu.RUnlock()
+	return defaultRegistry
+}
+
 // DecodeData decodes Avro-encoded bytes into the target value.
-// The target must have a registered schema in the schema registry,
-// or implement the SchemaProvider interface.
+// The target must implement the SchemaProvider interface, or have a
+// registered schema in the schema registry, see WithSchemaRegistry, or else
+// be a struct whose schema is inferred, see InferSchema.
+// With a WireFormat, the payload is first extracted from in.
+// When the writer schema of in is known, set with WithWriterSchema or
+// resolved by the WireFormat, see WriterSchemaResolver, the schema of the
+// target is the reader schema, and in is decoded with the Avro schema
+// resolution rules.
 func DecodeData(ctx context.Context, in []byte, out interface{}) error {
-	schema, err := getSchemaFor(out)
+	schema, err := getSchemaFor(ctx, out)
 	if err != nil {
 		return fmt.Errorf("failed to get schema for decoding: %w", err)
 	}
 
-	if err := avro.Unmarshal(schema, in, out); err != nil {
+	w := wireFormatFrom(ctx)
+	writer, err := writerSchemaFrom(ctx, w, in)
+	if err != nil {
+		return err
+	}
+	if writer != nil {
+		if schema, err = resolveSchema(schema, writer); err != nil {
+			return err
+		}
+	}
+	if w != nil {
+		if in, err = w.Unframe(ctx, in); err != nil {
+			return err
+		}
+	}
+
+	if err := apiFrom(ctx).Unmarshal(schema, in, out); err != nil {
 		return fmt.Errorf("failed to unmarshal Avro data: %w", err)
 	}
 	return nil
@@ -59,17 +121,47 @@ func DecodeData(ctx context.Context, in []byte, out interface{}) error {
 // EncodeData encodes a value to Avro bytes.
 // Like the official datacodec implementations, this one returns the given value
 // as-is if it is already a byte slice.
+// With a WireFormat, the bytes are framed by it.
 func EncodeData(ctx context.Context, in interface{}) ([]byte, error) {
 	if b, ok := in.([]byte); ok {
 		return b, nil
 	}
 
-	schema, err := getSchemaFor(in)
+	schema, err := getSchemaFor(ctx, in)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get schema for encoding: %w", err)
 	}
 
-	return avro.Marshal(schema, in)
+	data, err := apiFrom(ctx).Marshal(schema, in)
+	if err != nil {
+		return nil, err
+	}
+	if w := wireFormatFrom(ctx); w != nil {
+		return w.Frame(ctx, schema, data)
+	}
+	return data, nil
+}
+
+// ResolveSchemas resolves the schemas of the values up front, as EncodeData
+// does, e.g. at startup with client.WithPingCheck, so that a misconfigured
+// schema registry is reported before the first event. With a WireFormat, an
+// empty payload is framed with each schema too, so that its reference in the
+// schema registry is looked up, or registered, and cached. The failures of all
+// the values are returned joined.
+func ResolveSchemas(ctx context.Context, values ...interface{}) error {
+	var errs []error
+	for _, v := range values {
+		schema, err := getSchemaFor(ctx, v)
+		if err == nil {
+			if w := wireFormatFrom(ctx); w != nil {
+				_, err = w.Frame(ctx, schema, nil)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resolve schema for %T: %w", v, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // SchemaProvider is an interface that types can implement to provide their own Avro schema.
@@ -77,8 +169,64 @@ type SchemaProvider interface {
 	AvroSchema() avro.Schema
 }
 
-// getSchemaFor retrieves the Avro schema for a given value.
-func getSchemaFor(v interface{}) (avro.Schema, error) {
+var (
+	defaultAPIMu sync.RWMutex
+	// defaultAPI is the API of EncodeData and DecodeData, unless the
+	// context has one.
+	defaultAPI avro.API = avro.DefaultConfig
+)
+
+// SetAPI sets the API EncodeData and DecodeData marshal and unmarshal the
+// values with when the context has none, see WithAPI. It is
+// avro.DefaultConfig by default, or if api is nil.
+func SetAPI(api avro.API) {
+	if api == nil {
+		api = avro.DefaultConfig
+	}
+	defaultAPIMu.Lock()
+	defer defaultAPIMu.Unlock()
+	defaultAPI = api
+}
+
+type apiKey struct{}
+
+// WithAPI returns a context making EncodeData and DecodeData marshal and
+// unmarshal the values with api, frozen from the avro.Config of the caller,
+// e.g. raising its MaxByteSliceSize or with its UnionResolutionError, rather
+// than with the API set with SetAPI.
+func WithAPI(ctx context.Context, api avro.API) context.Context {
+	return context.WithValue(ctx, apiKey{}, api)
+}
+
+// apiFrom returns the API of ctx, or the default one.
+func apiFrom(ctx context.Context) avro.API {
+	if api, ok := ctx.Value(apiKey{}).(avro.API); ok && api != nil {
+		return api
+	}
+	defaultAPIMu.RLock()
+	defer defaultAPIMu.RUnlock()
+	return defaultAPI
+}
+
+type schemaKey struct{}
+
+// WithSchema returns a context making EncodeData and DecodeData use schema
+// for the value, instead of the one of its SchemaProvider, registry or
+// inferred, e.g. to encode a Go type under several versions of its schema.
+// With DecodeData, schema is the reader schema the data is resolved into,
+// see WithWriterSchema.
+func WithSchema(ctx context.Context, schema avro.Schema) context.Context {
+	return context.WithValue(ctx, schemaKey{}, schema)
+}
+
+// getSchemaFor retrieves the Avro schema for a given value, from ctx or
+// looking up the schema registry of ctx.
+func getSchemaFor(ctx context.Context, v interface{}) (avro.Schema, error) {
+	// The schema forced by the context comes first
+	if schema, ok := ctx.Value(schemaKey{}).(avro.Schema); ok && schema != nil {
+		return schema, nil
+	}
+
 	// First check if the value implements SchemaProvider
 	if sp, ok := v.(SchemaProvider); ok {
 		return sp.AvroSchema(), nil
@@ -89,10 +237,21 @@ func getSchemaFor(v interface{}) (avro.Schema, error) {
 		return sp.AvroSchema(), nil
 	}
 
-	// Try the default registry
-	if defaultRegistry != nil {
-		return defaultRegistry.GetSchema(v)
+	// Try the registry of the context, or the default one
+	if r := schemaRegistryFrom(ctx); r != nil {
+		schema, err := r.GetSchema(v)
+		if err != nil && !errors.Is(err, ErrSchemaNotFound) {
+			return nil, err
+		}
+		if schema != nil {
+			return schema, nil
+		}
 	}
 
-	return nil, fmt.Errorf("no schema available for type %T: implement SchemaProvider interface or set a SchemaRegistry", v)
+	// Fall back to the schema of the struct fields
+	schema, err := InferSchema(v)
+	if err != nil {
+		return nil, fmt.Errorf("no schema available for type %T: implement SchemaProvider interface or set a SchemaRegistry: %w", v, err)
+	}
+	return schema, nil
 }
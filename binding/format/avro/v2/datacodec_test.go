@@ -7,6 +7,7 @@ package avro_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/hamba/avro/v2"
@@ -98,16 +99,23 @@ func TestDataCodecWithoutSchema(t *testing.T) {
 	require := require.New(t)
 	ctx := context.Background()
 
-	// Without a schema, encoding should fail
-	type NoSchemaRecord struct {
-		Field string
-	}
+	// Without a schema, the schema of a struct is inferred
+	data, err := avrofmt.EncodeData(ctx, &NoSchemaRecord{Field: "test"})
+	require.NoError(err)
+	var decoded NoSchemaRecord
+	require.NoError(avrofmt.DecodeData(ctx, data, &decoded))
+	require.Equal("test", decoded.Field)
 
-	_, err := avrofmt.EncodeData(ctx, &NoSchemaRecord{Field: "test"})
+	// Other types can not be inferred
+	_, err = avrofmt.EncodeData(ctx, map[int]string{1: "test"})
 	require.Error(err)
 	require.Contains(err.Error(), "no schema available")
 }
 
+type NoSchemaRecord struct {
+	Field string
+}
+
 // TestSchemaRegistry implements SchemaRegistry for testing
 type TestSchemaRegistry struct {
 	schemas map[string]avro.Schema
@@ -159,6 +167,72 @@ func TestDataCodecWithSchemaRegistry(t *testing.T) {
 	require.Equal(original.Value, decoded.Value)
 }
 
+func TestDataCodecWithContextSchemaRegistry(t *testing.T) {
+	require := require.New(t)
+	failure := errors.New("registry unavailable")
+	avrofmt.SetSchemaRegistry(notFoundRegistry{err: failure})
+	defer avrofmt.SetSchemaRegistry(nil)
+
+	// A TestRecord value is no SchemaProvider
+	original := TestRecord{Name: "context-test", Value: 7}
+	_, err := avrofmt.EncodeData(context.Background(), original)
+	require.ErrorIs(err, failure)
+
+	// The registry of the context overrides the default one
+	ctx := avrofmt.WithSchemaRegistry(context.Background(), NewTestSchemaRegistry())
+	encoded, err := avrofmt.EncodeData(ctx, original)
+	require.NoError(err)
+	decoded := &TestRecord{}
+	require.NoError(avrofmt.DecodeData(ctx, encoded, decoded))
+	require.Equal(original, *decoded)
+
+	// Even with no registry
+	_, err = avrofmt.EncodeData(avrofmt.WithSchemaRegistry(context.Background(), nil), original)
+	require.NoError(err)
+}
+
+func TestDataCodecWithSchema(t *testing.T) {
+	require := require.New(t)
+	original := &TestRecord{Name: "schema-test", Value: 3}
+
+	// The schema of the context overrides the one of the SchemaProvider
+	ctx := avrofmt.WithSchema(context.Background(), testRecordV2Schema)
+	encoded, err := avrofmt.EncodeData(ctx, original)
+	require.NoError(err)
+
+	v2 := &testRecordV2{}
+	require.NoError(avrofmt.DecodeData(context.Background(), encoded, v2))
+	require.Equal(&testRecordV2{Label: "none", Value: 3, Name: "schema-test"}, v2)
+
+	decoded := &TestRecord{}
+	require.NoError(avrofmt.DecodeData(ctx, encoded, decoded))
+	require.Equal(original, decoded)
+
+	// Without it, the data is misread
+	misread := &TestRecord{}
+	_ = avrofmt.DecodeData(context.Background(), encoded, misread)
+	require.NotEqual(original, misread)
+}
+
+func TestDataCodecWithAPI(t *testing.T) {
+	require := require.New(t)
+	original := &TestRecord{Name: "a name longer than the limit", Value: 4}
+	encoded, err := avrofmt.EncodeData(context.Background(), original)
+	require.NoError(err)
+
+	small := avro.Config{MaxByteSliceSize: 8}.Freeze()
+	decoded := &TestRecord{}
+	err = avrofmt.DecodeData(avrofmt.WithAPI(context.Background(), small), encoded, decoded)
+	require.ErrorContains(err, "MaxByteSliceSize")
+
+	avrofmt.SetAPI(small)
+	defer avrofmt.SetAPI(nil)
+	require.Error(avrofmt.DecodeData(context.Background(), encoded, decoded))
+	// The API of the context overrides the default one.
+	require.NoError(avrofmt.DecodeData(avrofmt.WithAPI(context.Background(), avro.DefaultConfig), encoded, decoded))
+	require.Equal(original, decoded)
+}
+
 func TestContentTypeConstant(t *testing.T) {
 	require.Equal(t, "application/avro", avrofmt.ContentTypeAvro)
 }
@@ -0,0 +1,136 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hamba/avro/v2"
+)
+
+const (
+	// glueHeaderVersion is the first byte of the Glue wire format.
+	glueHeaderVersion = 3
+	// glueHeaderSize is the size of the header of the Glue wire format: the
+	// version, the compression and the UUID of the schema version.
+	glueHeaderSize = 18
+)
+
+// Compressions of the payloads of the AWS Glue Schema Registry wire format.
+const (
+	GlueCompressionNone byte = 0
+	GlueCompressionZlib byte = 5
+)
+
+// ErrNotGlueWireFormat is returned when decoding data which does not start
+// with the header of the AWS Glue Schema Registry wire format.
+var ErrNotGlueWireFormat = errors.New("data is not in the AWS Glue Schema Registry wire format")
+
+// GlueRegistry resolves the IDs of the schema versions of an AWS Glue Schema
+// Registry.
+type GlueRegistry interface {
+	// SchemaVersionID returns the UUID of the version of the schema named
+	// schemaName whose definition is schema, registering it if needed.
+	SchemaVersionID(ctx context.Context, schemaName string, schema avro.Schema) ([16]byte, error)
+}
+
+// GlueWireFormat is the wire format of the AWS Glue Schema Registry serializers:
+// the Avro payload, optionally compressed with zlib, is prefixed with the
+// header version 3, the compression and the 16-byte UUID of the version of its
+// schema in the registry.
+type GlueWireFormat struct {
+	// Registry resolves the schema versions of the encoded values. As it is
+	// called for each encoded value, it should cache the IDs.
+	Registry GlueRegistry
+	// SchemaName is the name of the Glue schema the schemas are versions of.
+	// Empty uses the full name of the schemas.
+	SchemaName string
+	// Compress compresses the payloads with zlib.
+	Compress bool
+}
+
+// ParseGlueHeader splits data in the AWS Glue Schema Registry wire format into
+// the UUID of the version of its writer schema and its Avro payload,
+// decompressed if needed.
+func ParseGlueHeader(data []byte) ([16]byte, []byte, error) {
+	var id [16]byte
+	if len(data) < glueHeaderSize || data[0] != glueHeaderVersion {
+		return id, nil, ErrNotGlueWireFormat
+	}
+	copy(id[:], data[2:glueHeaderSize])
+	payload := data[glueHeaderSize:]
+	switch data[1] {
+	case GlueCompressionNone:
+		return id, payload, nil
+	case GlueCompressionZlib:
+		r, err := zlib.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return id, nil, fmt.Errorf("invalid zlib payload: %w", err)
+		}
+		defer r.Close()
+		payload, err = io.ReadAll(r)
+		if err != nil {
+			return id, nil, fmt.Errorf("invalid zlib payload: %w", err)
+		}
+		return id, payload, nil
+	default:
+		return id, nil, fmt.Errorf("unsupported AWS Glue Schema Registry compression %d", data[1])
+	}
+}
+
+// Frame implements WireFormat.Frame: it resolves the schema version of schema
+// and prefixes payload with the header of its UUID.
+func (w *GlueWireFormat) Frame(ctx context.Context, schema avro.Schema, payload []byte) ([]byte, error) {
+	if w.Registry == nil {
+		return nil, errors.New("the Glue wire format has no registry")
+	}
+	name := w.SchemaName
+	if name == "" {
+		named, ok := schema.(avro.NamedSchema)
+		if !ok {
+			return nil, fmt.Errorf("the Glue wire format needs a schema name for the unnamed %s schema", schema.Type())
+		}
+		name = named.FullName()
+	}
+	id, err := w.Registry.SchemaVersionID(ctx, name, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version of schema %q: %w", name, err)
+	}
+
+	var b bytes.Buffer
+	b.Grow(glueHeaderSize + len(payload))
+	b.WriteByte(glueHeaderVersion)
+	if !w.Compress {
+		b.WriteByte(GlueCompressionNone)
+		b.Write(id[:])
+		b.Write(payload)
+		return b.Bytes(), nil
+	}
+	b.WriteByte(GlueCompressionZlib)
+	b.Write(id[:])
+	zw := zlib.NewWriter(&b)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Unframe implements WireFormat.Unframe: it strips the header of data and
+// decompresses the payload if needed.
+func (w *GlueWireFormat) Unframe(ctx context.Context, data []byte) ([]byte, error) {
+	_, payload, err := ParseGlueHeader(data)
+	return payload, err
+}
+
+var _ WireFormat = (*GlueWireFormat)(nil)
@@ -0,0 +1,74 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+// fakeGlueRegistry assigns a UUID to each schema name.
+type fakeGlueRegistry map[string][16]byte
+
+func (r fakeGlueRegistry) SchemaVersionID(ctx context.Context, schemaName string, schema avro.Schema) ([16]byte, error) {
+	id, ok := r[schemaName]
+	if !ok {
+		id[15] = byte(len(r) + 1)
+		r[schemaName] = id
+	}
+	return id, nil
+}
+
+func TestDataCodecWithGlueWireFormat(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		registry := fakeGlueRegistry{}
+		ctx := avrofmt.WithWireFormat(context.Background(), &avrofmt.GlueWireFormat{Registry: registry, Compress: compress})
+
+		original := &schemaProviderRecord{TestRecord: TestRecord{Name: "test-name", Value: 42}}
+		encoded, err := avrofmt.EncodeData(ctx, original)
+		require.NoError(t, err)
+		require.Equal(t, byte(3), encoded[0])
+		if compress {
+			require.Equal(t, avrofmt.GlueCompressionZlib, encoded[1])
+		} else {
+			require.Equal(t, avrofmt.GlueCompressionNone, encoded[1])
+		}
+
+		id, payload, err := avrofmt.ParseGlueHeader(encoded)
+		require.NoError(t, err)
+		require.Equal(t, registry["test.TestRecord"], id)
+		plain, err := avrofmt.EncodeData(context.Background(), original)
+		require.NoError(t, err)
+		require.Equal(t, plain, payload)
+
+		decoded := &schemaProviderRecord{}
+		require.NoError(t, avrofmt.DecodeData(ctx, encoded, decoded))
+		require.Equal(t, original.TestRecord, decoded.TestRecord)
+
+		err = avrofmt.DecodeData(ctx, plain, decoded)
+		require.ErrorIs(t, err, avrofmt.ErrNotGlueWireFormat)
+	}
+}
+
+func TestParseGlueHeader(t *testing.T) {
+	_, _, err := avrofmt.ParseGlueHeader(make([]byte, 10))
+	require.ErrorIs(t, err, avrofmt.ErrNotGlueWireFormat)
+
+	data := make([]byte, 19)
+	data[0] = 3
+	data[1] = 1
+	_, _, err = avrofmt.ParseGlueHeader(data)
+	require.Error(t, err)
+
+	data[1] = avrofmt.GlueCompressionZlib
+	_, _, err = avrofmt.ParseGlueHeader(data)
+	require.Error(t, err)
+}
@@ -3,6 +3,7 @@ module github.com/cloudevents/sdk-go/binding/format/avro/v2
 go 1.24.0
 
 require (
+	github.com/cloudevents/sdk-go/protocol/http/sigv4/v2 v2.16.2
 	github.com/cloudevents/sdk-go/v2 v2.16.2
 	github.com/hamba/avro/v2 v2.27.0
 	github.com/stretchr/testify v1.11.1
@@ -10,11 +11,18 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/snappy v1.0.0 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
-	github.com/kr/text v0.2.0 // indirect
+	github.com/klauspost/compress v1.18.1 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/multierr v1.11.0 // indirect
+	go.uber.org/zap v1.27.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/cloudevents/sdk-go/v2 => ../../../../v2
+
+replace github.com/cloudevents/sdk-go/protocol/http/sigv4/v2 => ../../../../protocol/http/sigv4/v2
@@ -0,0 +1,212 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	stdtime "time"
+
+	"github.com/hamba/avro/v2"
+)
+
+// inferredSchemas caches the schemas inferred by type.
+var inferredSchemas sync.Map // map[reflect.Type]avro.Schema
+
+var (
+	majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+	timeType     = reflect.TypeOf(stdtime.Time{})
+	durationType = reflect.TypeOf(stdtime.Duration(0))
+)
+
+// InferSchema returns the Avro schema of the Go struct type of v, or of the
+// struct it points to, derived from its fields the way they are encoded: the
+// exported fields are named by their avro tag, or their Go name, and the
+// fields of the embedded structs are promoted. The records are named after
+// their Go type, in the namespace of the last element of their package path
+// other than a major version suffix.
+//
+// The Go types map to the Avro types as follows:
+//   - bool to boolean, string to string, []byte to bytes, [N]byte to fixed,
+//   - int8, int16, int32, uint8 and uint16 to int,
+//   - int, int64 and uint32 to long,
+//   - float32 to float, float64 to double,
+//   - time.Time to long with the timestamp-micros logical type,
+//   - time.Duration to long with the time-micros logical type,
+//   - slices to arrays, maps with string keys to maps, structs to records,
+//   - pointers to the union of null, the default, and their element type.
+//
+// The other types, such as uint64 or interfaces, can not be inferred.
+func InferSchema(v interface{}) (avro.Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t == timeType {
+		return nil, fmt.Errorf("can not infer Avro schema of %T: not a struct", v)
+	}
+	if schema, ok := inferredSchemas.Load(t); ok {
+		return schema.(avro.Schema), nil
+	}
+
+	i := inferrer{defined: map[reflect.Type]string{}}
+	def, err := i.schemaOf(t)
+	if err != nil {
+		return nil, fmt.Errorf("can not infer Avro schema of %T: %w", v, err)
+	}
+	b, err := json.Marshal(def)
+	if err != nil {
+		return nil, err
+	}
+	// The inferred names must not collide with the schemas parsed elsewhere.
+	schema, err := avro.ParseWithCache(string(b), "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("can not infer Avro schema of %T: %w", v, err)
+	}
+	inferredSchemas.Store(t, schema)
+	return schema, nil
+}
+
+// inferrer builds the JSON definition of a schema, referencing the records
+// already defined by name.
+type inferrer struct {
+	defined map[reflect.Type]string
+}
+
+func (i *inferrer) schemaOf(t reflect.Type) (interface{}, error) {
+	switch t {
+	case timeType:
+		return map[string]string{"type": "long", "logicalType": "timestamp-micros"}, nil
+	case durationType:
+		return map[string]string{"type": "long", "logicalType": "time-micros"}, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.String:
+		return "string", nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return "int", nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "long", nil
+	case reflect.Float32:
+		return "float", nil
+	case reflect.Float64:
+		return "double", nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+		items, err := i.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return nil, fmt.Errorf("unsupported array type %s", t)
+		}
+		return map[string]interface{}{"type": "fixed", "name": fmt.Sprintf("fixed%d", t.Len()), "size": t.Len()}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map type %s: the keys must be strings", t)
+		}
+		values, err := i.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "map", "values": values}, nil
+	case reflect.Ptr:
+		elem, err := i.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{"null", elem}, nil
+	case reflect.Struct:
+		return i.record(t)
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+func (i *inferrer) record(t reflect.Type) (interface{}, error) {
+	if t.Name() == "" {
+		return nil, fmt.Errorf("unsupported anonymous struct %s", t)
+	}
+	elems := strings.Split(t.PkgPath(), "/")
+	namespace := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersion.MatchString(namespace) {
+		namespace = elems[len(elems)-2]
+	}
+	namespace = strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, namespace)
+	name := namespace + "." + t.Name()
+	if namespace == "" {
+		name = t.Name()
+	}
+	if defined, ok := i.defined[t]; ok {
+		return defined, nil
+	}
+	i.defined[t] = name
+
+	var fields []interface{}
+	seen := map[string]bool{}
+	// The fields of the embedded structs are promoted, the shallower first,
+	// as the encoder of hamba/avro does.
+	next := []reflect.Type{t}
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		var curr []reflect.Type
+		curr, next = next, nil
+		for _, st := range curr {
+			if visited[st] {
+				continue
+			}
+			visited[st] = true
+			for j := 0; j < st.NumField(); j++ {
+				f := st.Field(j)
+				if f.Anonymous {
+					ft := f.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if ft.Kind() == reflect.Struct {
+						next = append(next, ft)
+					}
+					continue
+				}
+				if !f.IsExported() {
+					continue
+				}
+				fieldName := f.Name
+				if tag, ok := f.Tag.Lookup("avro"); ok {
+					fieldName = tag
+				}
+				if seen[fieldName] {
+					continue
+				}
+				seen[fieldName] = true
+				schema, err := i.schemaOf(f.Type)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", f.Name, err)
+				}
+				field := map[string]interface{}{"name": fieldName, "type": schema}
+				if f.Type.Kind() == reflect.Ptr {
+					field["default"] = nil
+				}
+				fields = append(fields, field)
+			}
+		}
+	}
+	return map[string]interface{}{"type": "record", "name": name, "fields": fields}, nil
+}
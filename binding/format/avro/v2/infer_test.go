@@ -0,0 +1,142 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+type inferredBase struct {
+	ID string `avro:"id"`
+}
+
+type inferredItem struct {
+	SKU      string `avro:"sku"`
+	Quantity int32  `avro:"quantity"`
+}
+
+type inferredNode struct {
+	Name string
+	Next *inferredNode
+}
+
+type inferredOrder struct {
+	inferredBase
+	Paid     bool              `avro:"paid"`
+	Total    float64           `avro:"total"`
+	Count    int               `avro:"count"`
+	Ratio    float32           `avro:"ratio"`
+	Digest   [4]byte           `avro:"digest"`
+	Payload  []byte            `avro:"payload"`
+	Created  time.Time         `avro:"created"`
+	Elapsed  time.Duration     `avro:"elapsed"`
+	Items    []inferredItem    `avro:"items"`
+	Labels   map[string]string `avro:"labels"`
+	Note     *string           `avro:"note"`
+	Head     *inferredNode     `avro:"head"`
+	internal string
+}
+
+func TestInferSchema(t *testing.T) {
+	require := require.New(t)
+
+	schema, err := avrofmt.InferSchema(&inferredOrder{})
+	require.NoError(err)
+	record := schema.(*avro.RecordSchema)
+	require.Equal("v2_test.inferredOrder", record.FullName())
+
+	var names []string
+	for _, f := range record.Fields() {
+		names = append(names, f.Name())
+	}
+	require.Equal([]string{"paid", "total", "count", "ratio", "digest", "payload", "created",
+		"elapsed", "items", "labels", "note", "head", "id"}, names)
+	require.Equal(avro.Double, record.Fields()[1].Type().Type())
+	require.Equal(avro.Long, record.Fields()[2].Type().Type())
+	require.Equal(avro.Fixed, record.Fields()[4].Type().Type())
+	require.Equal(avro.TimestampMicros, record.Fields()[6].Type().(avro.LogicalTypeSchema).Logical().Type())
+	require.Equal(avro.Union, record.Fields()[10].Type().Type())
+	require.True(record.Fields()[10].HasDefault())
+
+	again, err := avrofmt.InferSchema(inferredOrder{})
+	require.NoError(err)
+	require.Same(schema, again)
+
+	note := "fragile"
+	in := &inferredOrder{
+		inferredBase: inferredBase{ID: "42"},
+		Paid:         true,
+		Total:        12.5,
+		Count:        3,
+		Ratio:        0.5,
+		Digest:       [4]byte{1, 2, 3, 4},
+		Payload:      []byte("raw"),
+		Created:      time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Elapsed:      time.Minute,
+		Items:        []inferredItem{{SKU: "a", Quantity: 2}},
+		Labels:       map[string]string{"k": "v"},
+		Note:         &note,
+		Head:         &inferredNode{Name: "first", Next: &inferredNode{Name: "second"}},
+	}
+	data, err := avrofmt.EncodeData(context.Background(), in)
+	require.NoError(err)
+	var out inferredOrder
+	require.NoError(avrofmt.DecodeData(context.Background(), data, &out))
+	require.Equal(in, &out)
+}
+
+func TestInferSchemaErrors(t *testing.T) {
+	for name, v := range map[string]interface{}{
+		"not a struct":     "test",
+		"nil":              nil,
+		"time":             time.Time{},
+		"anonymous struct": struct{ Field string }{},
+		"uint64": struct {
+			inferredBase
+			Big uint64
+		}{},
+		"interface field": &struct{ Any interface{} }{},
+		"int keys":        &struct{ M map[int]string }{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := avrofmt.InferSchema(v)
+			require.Error(t, err)
+		})
+	}
+}
+
+// notFoundRegistry knows no schema.
+type notFoundRegistry struct {
+	err error
+}
+
+func (r notFoundRegistry) GetSchema(v interface{}) (avro.Schema, error) {
+	return nil, r.err
+}
+
+func TestInferSchemaWithRegistry(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	defer avrofmt.SetSchemaRegistry(nil)
+
+	avrofmt.SetSchemaRegistry(notFoundRegistry{err: avrofmt.ErrSchemaNotFound})
+	_, err := avrofmt.EncodeData(ctx, &inferredItem{SKU: "a"})
+	require.NoError(err)
+
+	// The other failures of the registry are not masked.
+	failure := errors.New("registry unavailable")
+	avrofmt.SetSchemaRegistry(notFoundRegistry{err: failure})
+	_, err = avrofmt.EncodeData(ctx, &inferredItem{SKU: "a"})
+	require.ErrorIs(err, failure)
+}
@@ -0,0 +1,167 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// DefaultKeyRecordName is the full name of the record schema of the keys
+// encoded by a KeyEncoder.
+const DefaultKeyRecordName = "io.cloudevents.CloudEventKey"
+
+// KeyEncoder encodes a projection of the attributes of the events, e.g.
+// source and subject, as Avro records in the Confluent Schema Registry wire
+// format, to be used as the keys of Kafka messages. The schema of the keys is
+// registered under the key subject of each topic on first use.
+// It implements the KeyEncoder of the Kafka bindings, e.g.
+// kafka_sarama.WithKeyEncoder.
+type KeyEncoder struct {
+	attributes []string
+	recordName string
+	strategy   SubjectNameStrategy
+	registry   SubjectRegistry
+	schema     *avro.RecordSchema
+
+	mu  sync.Mutex
+	ids map[string]int
+}
+
+// KeyOption configures a KeyEncoder.
+type KeyOption func(*KeyEncoder) error
+
+// WithKeyRecordName sets the full name of the record schema of the keys,
+// DefaultKeyRecordName by default.
+func WithKeyRecordName(fullName string) KeyOption {
+	return func(k *KeyEncoder) error {
+		if fullName == "" {
+			return errors.New("key record name must not be empty")
+		}
+		k.recordName = fullName
+		return nil
+	}
+}
+
+// WithKeySubjectNameStrategy sets the subject the schema of the keys is
+// registered under, TopicNameStrategy by default.
+func WithKeySubjectNameStrategy(strategy SubjectNameStrategy) KeyOption {
+	return func(k *KeyEncoder) error {
+		if strategy == nil {
+			return errors.New("key subject name strategy must not be nil")
+		}
+		k.strategy = strategy
+		return nil
+	}
+}
+
+// NewKeyEncoder returns a KeyEncoder of the given attributes, context
+// attributes or extensions, registering the schema of the keys in registry.
+// Each attribute is a nullable string field of the key record, in order.
+func NewKeyEncoder(registry SubjectRegistry, attributes []string, opts ...KeyOption) (*KeyEncoder, error) {
+	if registry == nil {
+		return nil, errors.New("key encoder registry must not be nil")
+	}
+	if len(attributes) == 0 {
+		return nil, errors.New("key encoder must project at least one attribute")
+	}
+	k := &KeyEncoder{
+		attributes: append([]string(nil), attributes...),
+		recordName: DefaultKeyRecordName,
+		strategy:   TopicNameStrategy,
+		registry:   registry,
+		ids:        map[string]int{},
+	}
+	for _, opt := range opts {
+		if err := opt(k); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := make([]*avro.Field, len(k.attributes))
+	for i, name := range k.attributes {
+		nullable, err := avro.NewUnionSchema([]avro.Schema{avro.NewNullSchema(), avro.NewPrimitiveSchema(avro.String, nil)})
+		if err != nil {
+			return nil, err
+		}
+		if fields[i], err = avro.NewField(name, nullable, avro.WithDefault(nil)); err != nil {
+			return nil, fmt.Errorf("invalid key attribute %q: %w", name, err)
+		}
+	}
+	schema, err := avro.NewRecordSchema(k.recordName, "", fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key schema: %w", err)
+	}
+	k.schema = schema
+	return k, nil
+}
+
+// Schema returns the record schema of the keys.
+func (k *KeyEncoder) Schema() avro.Schema {
+	return k.schema
+}
+
+// EncodeKey encodes the key of the message read by r, sent to topic.
+func (k *KeyEncoder) EncodeKey(ctx context.Context, topic string, r binding.MessageMetadataReader) ([]byte, error) {
+	id, err := k.schemaID(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	record := make(map[string]any, len(k.attributes))
+	for _, name := range k.attributes {
+		v, err := attributeString(r, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode key attribute %s: %w", name, err)
+		}
+		record[name] = v
+	}
+	data, err := avro.Marshal(k.schema, record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+	return append(appendConfluentHeader(make([]byte, 0, 5+len(data)), id), data...), nil
+}
+
+// schemaID returns the ID of the key schema in the subject of topic,
+// registering it on first use.
+func (k *KeyEncoder) schemaID(ctx context.Context, topic string) (int, error) {
+	subject := k.strategy(topic, k.schema, true)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if id, ok := k.ids[subject]; ok {
+		return id, nil
+	}
+	id, err := k.registry.Register(ctx, subject, k.schema)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register key schema under subject %q: %w", subject, err)
+	}
+	k.ids[subject] = id
+	return id, nil
+}
+
+// attributeString returns the value of the context attribute or extension
+// name of r as a string, or nil if it is not set.
+func attributeString(r binding.MessageMetadataReader, name string) (any, error) {
+	var v interface{}
+	_, sv := r.GetAttribute(spec.SpecVersion)
+	if version := spec.VS.Version(fmt.Sprint(sv)); version != nil && version.Attribute(name) != nil {
+		_, v = r.GetAttribute(version.Attribute(name).Kind())
+	} else {
+		v = r.GetExtension(name)
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return types.Format(v)
+}
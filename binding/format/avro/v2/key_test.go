@@ -0,0 +1,94 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// fakeRegistry assigns IDs to the registered subjects.
+type fakeRegistry struct {
+	mu       sync.Mutex
+	subjects map[string]int
+	schemas  map[int]avro.Schema
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{subjects: map[string]int{}, schemas: map[int]avro.Schema{}}
+}
+
+func (r *fakeRegistry) Register(ctx context.Context, subject string, schema avro.Schema) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.subjects[subject]; ok {
+		return id, nil
+	}
+	id := len(r.subjects) + 100
+	r.subjects[subject] = id
+	r.schemas[id] = schema
+	return id, nil
+}
+
+func TestKeyEncoder(t *testing.T) {
+	registry := newFakeRegistry()
+	enc, err := avrofmt.NewKeyEncoder(registry, []string{"source", "subject", "tenant"})
+	require.NoError(t, err)
+
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/orders")
+	e.SetType("order.created")
+	e.SetExtension("tenant", "acme")
+
+	key, err := enc.EncodeKey(context.Background(), "orders", (*binding.EventMessage)(&e))
+	require.NoError(t, err)
+	require.Equal(t, byte(0), key[0])
+	id := int(binary.BigEndian.Uint32(key[1:5]))
+	require.Equal(t, map[string]int{"orders-key": id}, registry.subjects)
+
+	var record map[string]any
+	require.NoError(t, avro.Unmarshal(registry.schemas[id], key[5:], &record))
+	require.Equal(t, map[string]any{"source": "/orders", "subject": nil, "tenant": "acme"}, record)
+
+	// The schema is registered once per subject.
+	_, err = enc.EncodeKey(context.Background(), "orders", (*binding.EventMessage)(&e))
+	require.NoError(t, err)
+	_, err = enc.EncodeKey(context.Background(), "payments", (*binding.EventMessage)(&e))
+	require.NoError(t, err)
+	require.Len(t, registry.subjects, 2)
+}
+
+func TestKeyEncoderSubjectNameStrategy(t *testing.T) {
+	registry := newFakeRegistry()
+	enc, err := avrofmt.NewKeyEncoder(registry, []string{"source"},
+		avrofmt.WithKeyRecordName("com.example.OrderKey"),
+		avrofmt.WithKeySubjectNameStrategy(avrofmt.TopicRecordNameStrategy))
+	require.NoError(t, err)
+
+	e := event.New()
+	_, err = enc.EncodeKey(context.Background(), "orders", (*binding.EventMessage)(&e))
+	require.NoError(t, err)
+	require.Contains(t, registry.subjects, "orders-com.example.OrderKey")
+}
+
+func TestNewKeyEncoderInvalid(t *testing.T) {
+	_, err := avrofmt.NewKeyEncoder(nil, []string{"source"})
+	require.Error(t, err)
+	_, err = avrofmt.NewKeyEncoder(newFakeRegistry(), nil)
+	require.Error(t, err)
+	_, err = avrofmt.NewKeyEncoder(newFakeRegistry(), []string{"1source"})
+	require.Error(t, err)
+}
@@ -0,0 +1,186 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
+
+	"github.com/cloudevents/sdk-go/binding/format/avro/v2/schema"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// DefaultOCFBlockLength is the default number of events of the blocks of an
+// Object Container File.
+const DefaultOCFBlockLength = 100
+
+// OCFOption configures an OCFWriter.
+type OCFOption func(*ocfConfig)
+
+type ocfConfig struct {
+	codec       ocf.CodecName
+	blockLength int
+	metadata    map[string][]byte
+}
+
+// WithOCFCodec sets the codec compressing the blocks, e.g. ocf.Snappy.
+// Defaults to ocf.Deflate.
+func WithOCFCodec(codec ocf.CodecName) OCFOption {
+	return func(c *ocfConfig) {
+		c.codec = codec
+	}
+}
+
+// WithOCFBlockLength sets the number of events of the blocks, which are
+// compressed and written once full. Defaults to DefaultOCFBlockLength.
+func WithOCFBlockLength(length int) OCFOption {
+	return func(c *ocfConfig) {
+		c.blockLength = length
+	}
+}
+
+// WithOCFMetadata adds the metadata key to the header of the file, e.g. to
+// describe the archived events. The keys starting with "avro." are reserved.
+func WithOCFMetadata(key string, value []byte) OCFOption {
+	return func(c *ocfConfig) {
+		if c.metadata == nil {
+			c.metadata = map[string][]byte{}
+		}
+		c.metadata[key] = value
+	}
+}
+
+// OCFWriter writes CloudEvents to an Avro Object Container File, whose header
+// embeds the schema of the Avro format, for archival and batch replay. The
+// events are written in compressed blocks, so the writer must be closed to
+// write the last one.
+type OCFWriter struct {
+	enc *ocf.Encoder
+}
+
+// NewOCFWriter returns an OCFWriter writing a file to w, configured by the
+// options. The header of the file is written right away.
+func NewOCFWriter(w io.Writer, opts ...OCFOption) (*OCFWriter, error) {
+	c := ocfConfig{codec: ocf.Deflate, blockLength: DefaultOCFBlockLength}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.blockLength < 1 {
+		return nil, fmt.Errorf("OCF block length must be positive, got %d", c.blockLength)
+	}
+	encOpts := []ocf.EncoderFunc{ocf.WithCodec(c.codec), ocf.WithBlockLength(c.blockLength)}
+	if c.metadata != nil {
+		encOpts = append(encOpts, ocf.WithMetadata(c.metadata))
+	}
+	enc, err := ocf.NewEncoderWithSchema(schema.CloudEvent, w, encOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCF encoder: %w", err)
+	}
+	return &OCFWriter{enc: enc}, nil
+}
+
+// Write appends e to the current block, which is written once full.
+func (w *OCFWriter) Write(e *event.Event) error {
+	record, err := ToAvro(e)
+	if err != nil {
+		return err
+	}
+	return w.enc.Encode(record)
+}
+
+// Flush writes the current block, if not empty.
+func (w *OCFWriter) Flush() error {
+	return w.enc.Flush()
+}
+
+// Close writes the current block, if not empty. It does not close the
+// underlying writer.
+func (w *OCFWriter) Close() error {
+	return w.enc.Close()
+}
+
+// OCFReader reads the CloudEvents of an Avro Object Container File written
+// with the schema of the Avro format, e.g. by an OCFWriter.
+type OCFReader struct {
+	dec *ocf.Decoder
+}
+
+// NewOCFReader returns an OCFReader reading a file from r. The header of the
+// file is read right away, and its schema checked.
+func NewOCFReader(r io.Reader) (*OCFReader, error) {
+	dec, err := ocf.NewDecoder(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCF file: %w", err)
+	}
+	named, ok := dec.Schema().(avro.NamedSchema)
+	if !ok || named.FullName() != schema.CloudEvent.(avro.NamedSchema).FullName() {
+		return nil, fmt.Errorf("OCF file does not hold CloudEvents, its schema is %s", dec.Schema().String())
+	}
+	return &OCFReader{dec: dec}, nil
+}
+
+// Metadata returns the metadata of the header of the file.
+func (r *OCFReader) Metadata() map[string][]byte {
+	return r.dec.Metadata()
+}
+
+// Next returns the next event of the file, or io.EOF at its end.
+func (r *OCFReader) Next() (*event.Event, error) {
+	if !r.dec.HasNext() {
+		if err := r.dec.Error(); err != nil {
+			return nil, fmt.Errorf("failed to read OCF block: %w", err)
+		}
+		return nil, io.EOF
+	}
+	record := &schema.CloudEventRecord{}
+	if err := r.dec.Decode(record); err != nil {
+		return nil, fmt.Errorf("failed to decode OCF record: %w", err)
+	}
+	return FromAvro(record)
+}
+
+// MarshalOCF returns the Object Container File of events, configured by the
+// options.
+func MarshalOCF(events []event.Event, opts ...OCFOption) ([]byte, error) {
+	var b bytes.Buffer
+	w, err := NewOCFWriter(&b, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if err := w.Write(&events[i]); err != nil {
+			return nil, fmt.Errorf("failed to write event %d: %w", i, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalOCF returns the events of the Object Container File b.
+func UnmarshalOCF(b []byte) ([]event.Event, error) {
+	r, err := NewOCFReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	var events []event.Event
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+func ocfEvents(t *testing.T, n int) []event.Event {
+	events := make([]event.Event, n)
+	for i := range events {
+		e := event.New()
+		e.SetID(fmt.Sprintf("id-%d", i))
+		e.SetSource("/source")
+		e.SetType("test.type")
+		e.SetExtension("seq", int32(i))
+		if i%2 == 0 {
+			require.NoError(t, e.SetData(event.ApplicationJSON, map[string]int{"n": i}))
+		} else {
+			require.NoError(t, e.SetData("application/octet-stream", []byte{byte(i), 0, 1}))
+		}
+		events[i] = e
+	}
+	return events
+}
+
+func TestOCFRoundTrip(t *testing.T) {
+	events := ocfEvents(t, 25)
+	for _, codec := range []ocf.CodecName{ocf.Null, ocf.Deflate, ocf.Snappy, ocf.ZStandard} {
+		t.Run(string(codec), func(t *testing.T) {
+			b, err := avrofmt.MarshalOCF(events, avrofmt.WithOCFCodec(codec), avrofmt.WithOCFBlockLength(10))
+			require.NoError(t, err)
+
+			got, err := avrofmt.UnmarshalOCF(b)
+			require.NoError(t, err)
+			require.Len(t, got, len(events))
+			for i := range events {
+				require.Equal(t, events[i].ID(), got[i].ID())
+				require.Equal(t, events[i].DataContentType(), got[i].DataContentType())
+				require.Equal(t, events[i].Data(), got[i].Data())
+				require.Equal(t, events[i].Extensions()["seq"], got[i].Extensions()["seq"])
+			}
+		})
+	}
+}
+
+func TestOCFReaderWriter(t *testing.T) {
+	var b bytes.Buffer
+	w, err := avrofmt.NewOCFWriter(&b, avrofmt.WithOCFMetadata("source", []byte("/source")))
+	require.NoError(t, err)
+	events := ocfEvents(t, 3)
+	for i := range events {
+		require.NoError(t, w.Write(&events[i]))
+	}
+	require.NoError(t, w.Flush())
+	require.NoError(t, w.Close())
+
+	r, err := avrofmt.NewOCFReader(&b)
+	require.NoError(t, err)
+	require.Equal(t, []byte("/source"), r.Metadata()["source"])
+	for i := range events {
+		e, err := r.Next()
+		require.NoError(t, err)
+		require.Equal(t, events[i].ID(), e.ID())
+	}
+	_, err = r.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestOCFEmpty(t *testing.T) {
+	b, err := avrofmt.MarshalOCF(nil)
+	require.NoError(t, err)
+	got, err := avrofmt.UnmarshalOCF(b)
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
+
+func TestOCFInvalid(t *testing.T) {
+	_, err := avrofmt.NewOCFWriter(io.Discard, avrofmt.WithOCFBlockLength(0))
+	require.Error(t, err)
+
+	_, err = avrofmt.UnmarshalOCF([]byte("not an OCF file"))
+	require.Error(t, err)
+
+	// A file of records of another schema.
+	var b bytes.Buffer
+	enc, err := ocf.NewEncoder(`{"type":"record","name":"Other","fields":[{"name":"id","type":"string"}]}`, &b)
+	require.NoError(t, err)
+	require.NoError(t, enc.Encode(map[string]any{"id": "1"}))
+	require.NoError(t, enc.Close())
+	_, err = avrofmt.NewOCFReader(&b)
+	require.Error(t, err)
+
+	// A truncated file.
+	b.Reset()
+	w, err := avrofmt.NewOCFWriter(&b)
+	require.NoError(t, err)
+	events := ocfEvents(t, 3)
+	for i := range events {
+		require.NoError(t, w.Write(&events[i]))
+	}
+	require.NoError(t, w.Close())
+	_, err = avrofmt.UnmarshalOCF(b.Bytes()[:b.Len()-20])
+	require.Error(t, err)
+}
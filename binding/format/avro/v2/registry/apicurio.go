@@ -0,0 +1,349 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+// DefaultApicurioGroup is the artifact group of the artifacts without group.
+const DefaultApicurioGroup = "default"
+
+// ApicurioError is an error response of an Apicurio Registry.
+type ApicurioError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is the error code of the registry.
+	Code int `json:"error_code"`
+	// Message is the error message of the registry.
+	Message string `json:"message"`
+}
+
+func (e *ApicurioError) Error() string {
+	return fmt.Sprintf("apicurio registry error %d (HTTP %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// ApicurioArtifact is a version of an Avro artifact of an Apicurio Registry.
+type ApicurioArtifact struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	// GlobalID identifies the artifact version in the registry.
+	GlobalID int64
+	// ContentID identifies the content of the artifact version, shared by
+	// the versions with the same content.
+	ContentID int64
+	Schema    avro.Schema
+}
+
+// apicurioMeta is the metadata of an artifact version.
+type apicurioMeta struct {
+	GroupID   string `json:"groupId"`
+	ID        string `json:"id"`
+	Version   string `json:"version"`
+	GlobalID  int64  `json:"globalId"`
+	ContentID int64  `json:"contentId"`
+}
+
+// apicurioBinding is the artifact version the schema of a Go type is
+// resolved from.
+type apicurioBinding struct {
+	artifactID string
+	version    string
+}
+
+// ApicurioClient is a client of the version 2 REST API of an Apicurio
+// Registry, for the artifacts of a group. The schemas are cached in memory:
+// the global IDs and the artifact versions are immutable, so they are fetched
+// once.
+//
+// ApicurioClient implements avro.SchemaRegistry for the Go types bound to an
+// artifact with Bind, and avro.SubjectRegistry with the global IDs of the
+// artifacts, the subjects being the artifact IDs.
+type ApicurioClient struct {
+	baseURL string
+	group   string
+	client  *http.Client
+
+	mu       sync.RWMutex
+	byID     map[int64]avro.Schema
+	versions map[apicurioBinding]*ApicurioArtifact
+	contents map[string]*ApicurioArtifact
+	types    map[reflect.Type]apicurioBinding
+	resolved map[reflect.Type]avro.Schema
+}
+
+// NewApicurioClient returns a client of the registry API at baseURL, e.g.
+// http://registry:8080/apis/registry/v2, for the artifacts of group, or of
+// DefaultApicurioGroup if empty. The HTTP client is configured by the
+// options, see NewHTTPClient.
+func NewApicurioClient(baseURL, group string, opts ...Option) (*ApicurioClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apicurio registry URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("apicurio registry URL must be http or https, got %q", baseURL)
+	}
+	if group == "" {
+		group = DefaultApicurioGroup
+	}
+	client, err := NewHTTPClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ApicurioClient{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		group:    group,
+		client:   client,
+		byID:     map[int64]avro.Schema{},
+		versions: map[apicurioBinding]*ApicurioArtifact{},
+		contents: map[string]*ApicurioArtifact{},
+		types:    map[reflect.Type]apicurioBinding{},
+		resolved: map[reflect.Type]avro.Schema{},
+	}, nil
+}
+
+// Bind resolves the schema of the values of the type of v, or of the type it
+// points to, from the given version of artifactID. An empty version is the
+// latest version at the time the schema is first resolved.
+func (c *ApicurioClient) Bind(v interface{}, artifactID, version string) {
+	t := indirectType(v)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.types[t] = apicurioBinding{artifactID: artifactID, version: version}
+	delete(c.resolved, t)
+}
+
+// GetSchema implements avro.SchemaRegistry.GetSchema for the types bound with
+// Bind.
+func (c *ApicurioClient) GetSchema(v interface{}) (avro.Schema, error) {
+	t := indirectType(v)
+	c.mu.RLock()
+	b, ok := c.types[t]
+	schema, resolved := c.resolved[t]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no apicurio artifact bound to %T: %w", v, avrofmt.ErrSchemaNotFound)
+	}
+	if resolved {
+		return schema, nil
+	}
+	a, err := c.ArtifactVersion(context.Background(), b.artifactID, b.version)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resolved[t] = a.Schema
+	return a.Schema, nil
+}
+
+// SchemaByGlobalID returns the schema of the artifact version with the given
+// global ID.
+func (c *ApicurioClient) SchemaByGlobalID(ctx context.Context, globalID int64) (avro.Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.byID[globalID]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	content, err := c.do(ctx, http.MethodGet, "/ids/globalIds/"+strconv.FormatInt(globalID, 10), "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	schema, err = avro.Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %d: %w", globalID, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byID[globalID] = schema
+	return schema, nil
+}
+
+// ArtifactVersion returns the given version of artifactID. An empty version is
+// the latest version, which is fetched each time.
+func (c *ApicurioClient) ArtifactVersion(ctx context.Context, artifactID, version string) (*ApicurioArtifact, error) {
+	key := apicurioBinding{artifactID: artifactID, version: version}
+	c.mu.RLock()
+	a, ok := c.versions[key]
+	c.mu.RUnlock()
+	if ok {
+		return a, nil
+	}
+
+	v := "latest"
+	if version != "" {
+		v = url.PathEscape(version)
+	}
+	path := c.artifactPath(artifactID) + "/versions/" + v
+	var meta apicurioMeta
+	content, err := c.do(ctx, http.MethodGet, path+"/meta", "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &meta); err != nil {
+		return nil, fmt.Errorf("invalid apicurio registry response: %w", err)
+	}
+	// Fetch the content of the version of the metadata, since the latest
+	// version can change in between.
+	content, err = c.do(ctx, http.MethodGet, c.artifactPath(artifactID)+"/versions/"+url.PathEscape(meta.Version), "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := avro.Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema of artifact %q version %s: %w", artifactID, meta.Version, err)
+	}
+	a = c.artifact(meta, schema)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byID[a.GlobalID] = schema
+	c.versions[apicurioBinding{artifactID: artifactID, version: a.Version}] = a
+	return a, nil
+}
+
+// Lookup returns the version of artifactID whose content is schema, compared
+// in canonical form.
+func (c *ApicurioClient) Lookup(ctx context.Context, artifactID string, schema avro.Schema) (*ApicurioArtifact, error) {
+	key := artifactID + "\x00" + schema.String()
+	c.mu.RLock()
+	a, ok := c.contents[key]
+	c.mu.RUnlock()
+	if ok {
+		return a, nil
+	}
+
+	content, err := c.do(ctx, http.MethodPost, c.artifactPath(artifactID)+"/meta?canonical=true", "application/json", []byte(schema.String()), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.cacheContent(key, content, schema)
+}
+
+// Register implements avro.SubjectRegistry.Register, subject being the
+// artifact ID: it creates the artifact, or a version of it, unless schema is
+// already its content, and returns the global ID of the version.
+func (c *ApicurioClient) Register(ctx context.Context, subject string, schema avro.Schema) (int, error) {
+	key := subject + "\x00" + schema.String()
+	c.mu.RLock()
+	a, ok := c.contents[key]
+	c.mu.RUnlock()
+	if !ok {
+		path := "/groups/" + url.PathEscape(c.group) + "/artifacts?ifExists=RETURN_OR_UPDATE&canonical=true"
+		content, err := c.do(ctx, http.MethodPost, path, "application/json", []byte(schema.String()), map[string]string{
+			"X-Registry-ArtifactId":   subject,
+			"X-Registry-ArtifactType": "AVRO",
+		})
+		if err != nil {
+			return 0, err
+		}
+		if a, err = c.cacheContent(key, content, schema); err != nil {
+			return 0, err
+		}
+	}
+	return int(a.GlobalID), nil
+}
+
+// cacheContent caches the artifact version of the metadata content, whose
+// schema is schema.
+func (c *ApicurioClient) cacheContent(key string, content []byte, schema avro.Schema) (*ApicurioArtifact, error) {
+	var meta apicurioMeta
+	if err := json.Unmarshal(content, &meta); err != nil {
+		return nil, fmt.Errorf("invalid apicurio registry response: %w", err)
+	}
+	a := c.artifact(meta, schema)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.contents[key] = a
+	c.byID[a.GlobalID] = schema
+	return a, nil
+}
+
+func (c *ApicurioClient) artifact(meta apicurioMeta, schema avro.Schema) *ApicurioArtifact {
+	group := meta.GroupID
+	if group == "" {
+		group = c.group
+	}
+	return &ApicurioArtifact{
+		GroupID:    group,
+		ArtifactID: meta.ID,
+		Version:    meta.Version,
+		GlobalID:   meta.GlobalID,
+		ContentID:  meta.ContentID,
+		Schema:     schema,
+	}
+}
+
+func (c *ApicurioClient) artifactPath(artifactID string) string {
+	return "/groups/" + url.PathEscape(c.group) + "/artifacts/" + url.PathEscape(artifactID)
+}
+
+// do sends a request with the given headers to the registry and returns the
+// body of its response.
+func (c *ApicurioClient) do(ctx context.Context, method, path, contentType string, body []byte, headers map[string]string) ([]byte, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, r)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("apicurio registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		e := &ApicurioError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(content, e); err != nil || e.Message == "" {
+			e.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, e
+	}
+	if len(content) == 0 {
+		return nil, errors.New("empty apicurio registry response")
+	}
+	return content, nil
+}
+
+var (
+	_ avrofmt.SchemaRegistry  = (*ApicurioClient)(nil)
+	_ avrofmt.SubjectRegistry = (*ApicurioClient)(nil)
+)
@@ -0,0 +1,144 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+// apicurioServer is a minimal Apicurio Registry with the version 3 of the
+// artifact orders of the group shop, counting the requests by path.
+type apicurioServer struct {
+	mu       sync.Mutex
+	requests map[string]int
+	headers  http.Header
+}
+
+func (s *apicurioServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.Method+" "+r.URL.Path]++
+	s.mu.Unlock()
+	meta := `{"groupId":"shop","id":"orders","version":"3","globalId":42,"contentId":7}`
+	switch r.Method + " " + r.URL.Path {
+	case "GET /apis/registry/v2/ids/globalIds/42", "GET /apis/registry/v2/groups/shop/artifacts/orders/versions/3":
+		fmt.Fprint(w, orderSchema)
+	case "GET /apis/registry/v2/groups/shop/artifacts/orders/versions/latest/meta", "GET /apis/registry/v2/groups/shop/artifacts/orders/versions/3/meta":
+		fmt.Fprint(w, meta)
+	case "POST /apis/registry/v2/groups/shop/artifacts/orders/meta":
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Query().Get("canonical") != "true" || !json.Valid(body) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, meta)
+	case "POST /apis/registry/v2/groups/shop/artifacts":
+		s.mu.Lock()
+		s.headers = r.Header.Clone()
+		s.mu.Unlock()
+		if r.URL.Query().Get("ifExists") != "RETURN_OR_UPDATE" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		fmt.Fprint(w, `{"groupId":"shop","id":"test.Order","version":"1","globalId":43,"contentId":7}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error_code":404,"message":"No artifact with ID 'payments' in group 'shop' was found."}`)
+	}
+}
+
+func newApicurioTestClient(t *testing.T) (*ApicurioClient, *apicurioServer) {
+	s := &apicurioServer{requests: map[string]int{}}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	c, err := NewApicurioClient(server.URL+"/apis/registry/v2/", "shop")
+	require.NoError(t, err)
+	return c, s
+}
+
+func TestApicurioClientLookups(t *testing.T) {
+	c, s := newApicurioTestClient(t)
+	ctx := context.Background()
+
+	schema, err := c.SchemaByGlobalID(ctx, 42)
+	require.NoError(t, err)
+	require.Equal(t, "test.Order", schema.(avro.NamedSchema).FullName())
+	_, err = c.SchemaByGlobalID(ctx, 42)
+	require.NoError(t, err)
+	require.Equal(t, 1, s.requests["GET /apis/registry/v2/ids/globalIds/42"])
+
+	for i := 0; i < 2; i++ {
+		a, err := c.ArtifactVersion(ctx, "orders", "3")
+		require.NoError(t, err)
+		require.Equal(t, ApicurioArtifact{GroupID: "shop", ArtifactID: "orders", Version: "3", GlobalID: 42, ContentID: 7, Schema: a.Schema}, *a)
+	}
+	require.Equal(t, 1, s.requests["GET /apis/registry/v2/groups/shop/artifacts/orders/versions/3/meta"])
+
+	// The latest version is fetched each time.
+	for i := 0; i < 2; i++ {
+		_, err = c.ArtifactVersion(ctx, "orders", "")
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, s.requests["GET /apis/registry/v2/groups/shop/artifacts/orders/versions/latest/meta"])
+
+	for i := 0; i < 2; i++ {
+		a, err := c.Lookup(ctx, "orders", schema)
+		require.NoError(t, err)
+		require.Equal(t, int64(42), a.GlobalID)
+	}
+	require.Equal(t, 1, s.requests["POST /apis/registry/v2/groups/shop/artifacts/orders/meta"])
+
+	_, err = c.ArtifactVersion(ctx, "payments", "1")
+	var registryErr *ApicurioError
+	require.True(t, errors.As(err, &registryErr))
+	require.Equal(t, http.StatusNotFound, registryErr.StatusCode)
+	require.Equal(t, 404, registryErr.Code)
+}
+
+func TestApicurioClientSchemaRegistry(t *testing.T) {
+	c, s := newApicurioTestClient(t)
+	c.Bind(order{}, "orders", "")
+	avrofmt.SetSchemaRegistry(c)
+	defer avrofmt.SetSchemaRegistry(nil)
+
+	ctx := avrofmt.WithConfluentWireFormat(context.Background(), &avrofmt.ConfluentWireFormat{Registry: c})
+	for i := 0; i < 2; i++ {
+		data, err := avrofmt.EncodeData(ctx, &order{ID: "42"})
+		require.NoError(t, err)
+		id, _, err := avrofmt.ParseConfluentHeader(data)
+		require.NoError(t, err)
+		require.Equal(t, 43, id)
+
+		var decoded order
+		require.NoError(t, avrofmt.DecodeData(ctx, data, &decoded))
+		require.Equal(t, "42", decoded.ID)
+	}
+	require.Equal(t, 1, s.requests["GET /apis/registry/v2/groups/shop/artifacts/orders/versions/latest/meta"])
+	require.Equal(t, 1, s.requests["POST /apis/registry/v2/groups/shop/artifacts"])
+	require.Equal(t, "test.Order", s.headers.Get("X-Registry-ArtifactId"))
+	require.Equal(t, "AVRO", s.headers.Get("X-Registry-ArtifactType"))
+}
+
+func TestNewApicurioClient(t *testing.T) {
+	c, err := NewApicurioClient("http://registry:8080/apis/registry/v2", "")
+	require.NoError(t, err)
+	require.Equal(t, DefaultApicurioGroup, c.group)
+
+	_, err = NewApicurioClient("registry:8080", "")
+	require.Error(t, err)
+}
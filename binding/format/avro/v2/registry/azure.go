@@ -0,0 +1,423 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamba/avro/v2"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+const (
+	// AzureSchemaRegistryScope is the scope of the Microsoft Entra ID access
+	// tokens of the Azure Schema Registry.
+	AzureSchemaRegistryScope = "https://eventhubs.azure.net/.default"
+	// azureAPIVersion is the version of the Schema Registry REST API.
+	azureAPIVersion = "2023-07-01"
+	// azureAvroContentType is the media type of the Avro schemas.
+	azureAvroContentType = "application/json; serialization=Avro"
+	// azureTokenRefresh is how long before their expiration the access tokens
+	// are refreshed.
+	azureTokenRefresh = 2 * time.Minute
+)
+
+// AzureToken is a Microsoft Entra ID access token.
+type AzureToken struct {
+	Token     string
+	ExpiresOn time.Time
+}
+
+// AzureTokenCredential provides the access tokens authenticating the requests
+// to an Azure Schema Registry, e.g. an adapter of the azcore.TokenCredential
+// of the Azure SDK:
+//
+//	func (c adapter) GetToken(ctx context.Context, scopes []string) (registry.AzureToken, error) {
+//		t, err := c.cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: scopes})
+//		return registry.AzureToken{Token: t.Token, ExpiresOn: t.ExpiresOn}, err
+//	}
+type AzureTokenCredential interface {
+	GetToken(ctx context.Context, scopes []string) (AzureToken, error)
+}
+
+// AzureError is an error response of an Azure Schema Registry.
+type AzureError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is the error code of the registry, e.g. ItemNotFound.
+	Code string
+	// Message is the error message.
+	Message string
+}
+
+func (e *AzureError) Error() string {
+	return fmt.Sprintf("azure schema registry error %s (HTTP %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// AzureSchema is a version of a schema of an Azure Schema Registry.
+type AzureSchema struct {
+	// ID identifies the schema version in the registry.
+	ID      string
+	Group   string
+	Name    string
+	Version int
+	Schema  avro.Schema
+}
+
+// azureBinding is the schema version the schema of a Go type is resolved
+// from.
+type azureBinding struct {
+	name    string
+	version int
+}
+
+// AzureClient is a client of an Azure Schema Registry, as provided by the Event
+// Hubs namespaces, for the schemas of a schema group. The schemas are cached
+// in memory: the schema IDs and versions are immutable, so they are fetched
+// once.
+//
+// AzureClient implements avro.SchemaRegistry for the Go types bound to a
+// schema with Bind.
+type AzureClient struct {
+	endpoint string
+	group    string
+	client   *http.Client
+
+	mu       sync.RWMutex
+	byID     map[string]avro.Schema
+	versions map[azureBinding]*AzureSchema
+	ids      map[string]*AzureSchema
+	types    map[reflect.Type]azureBinding
+	resolved map[reflect.Type]avro.Schema
+}
+
+// NewAzureClient returns a client of the schemas of group in the Schema
+// Registry of the Event Hubs namespace, e.g. <namespace>.servicebus.windows.net,
+// authenticating the requests with the access tokens of credential. The HTTP
+// client is configured by the options, see NewHTTPClient, but its
+// authentication.
+func NewAzureClient(namespace, group string, credential AzureTokenCredential, opts ...Option) (*AzureClient, error) {
+	if namespace == "" || group == "" {
+		return nil, errors.New("azure schema registry requires a namespace and a schema group")
+	}
+	if credential == nil {
+		return nil, errors.New("azure schema registry requires a token credential")
+	}
+	endpoint := namespace
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid azure schema registry namespace %q", namespace)
+	}
+	client, err := NewHTTPClient(append(opts, WithBearerTokenSource(newAzureTokenSource(credential)))...)
+	if err != nil {
+		return nil, err
+	}
+	return &AzureClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		group:    group,
+		client:   client,
+		byID:     map[string]avro.Schema{},
+		versions: map[azureBinding]*AzureSchema{},
+		ids:      map[string]*AzureSchema{},
+		types:    map[reflect.Type]azureBinding{},
+		resolved: map[reflect.Type]avro.Schema{},
+	}, nil
+}
+
+// newAzureTokenSource returns a bearer token source caching the access tokens
+// of credential until shortly before their expiration.
+func newAzureTokenSource(credential AzureTokenCredential) func(context.Context) (string, error) {
+	var (
+		mu    sync.Mutex
+		token AzureToken
+	)
+	return func(ctx context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if token.Token != "" && time.Until(token.ExpiresOn) > azureTokenRefresh {
+			return token.Token, nil
+		}
+		t, err := credential.GetToken(ctx, []string{AzureSchemaRegistryScope})
+		if err != nil {
+			return "", err
+		}
+		token = t
+		return token.Token, nil
+	}
+}
+
+// Bind resolves the schema of the values of the type of v, or of the type it
+// points to, from the given version of the schema named name. A version lower
+// than 1 is the latest version at the time the schema is first resolved.
+func (c *AzureClient) Bind(v interface{}, name string, version int) {
+	if version < 1 {
+		version = 0
+	}
+	t := indirectType(v)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.types[t] = azureBinding{name: name, version: version}
+	delete(c.resolved, t)
+}
+
+// GetSchema implements avro.SchemaRegistry.GetSchema for the types bound with
+// Bind.
+func (c *AzureClient) GetSchema(v interface{}) (avro.Schema, error) {
+	t := indirectType(v)
+	c.mu.RLock()
+	b, ok := c.types[t]
+	schema, resolved := c.resolved[t]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no azure schema bound to %T: %w", v, avrofmt.ErrSchemaNotFound)
+	}
+	if resolved {
+		return schema, nil
+	}
+	s, err := c.SchemaVersion(context.Background(), b.name, b.version)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resolved[t] = s.Schema
+	return s.Schema, nil
+}
+
+// SchemaByID returns the schema with the given ID, as carried by the content
+// type avro/binary+<id> of the Event Hubs events.
+func (c *AzureClient) SchemaByID(ctx context.Context, id string) (avro.Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.byID[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	header, content, err := c.do(ctx, http.MethodGet, "/$schemaGroups/$schemas/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.schema(header, content)
+	if err != nil {
+		return nil, err
+	}
+	return s.Schema, nil
+}
+
+// SchemaVersion returns the given version of the schema named name. A version
+// lower than 1 is the latest version, which is fetched each time.
+func (c *AzureClient) SchemaVersion(ctx context.Context, name string, version int) (*AzureSchema, error) {
+	if version < 1 {
+		latest, err := c.latestVersion(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		version = latest
+	}
+	key := azureBinding{name: name, version: version}
+	c.mu.RLock()
+	s, ok := c.versions[key]
+	c.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	header, content, err := c.do(ctx, http.MethodGet, c.schemaPath(name)+"/versions/"+strconv.Itoa(version), nil)
+	if err != nil {
+		return nil, err
+	}
+	if s, err = c.schema(header, content); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions[key] = s
+	return s, nil
+}
+
+// latestVersion returns the latest version of the schema named name.
+func (c *AzureClient) latestVersion(ctx context.Context, name string) (int, error) {
+	latest := 0
+	path := c.schemaPath(name) + "/versions"
+	for path != "" {
+		_, content, err := c.do(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return 0, err
+		}
+		var page struct {
+			Value    []int  `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := json.Unmarshal(content, &page); err != nil {
+			return 0, fmt.Errorf("invalid azure schema registry response: %w", err)
+		}
+		for _, v := range page.Value {
+			latest = max(latest, v)
+		}
+		path = ""
+		if page.NextLink != "" {
+			next, err := url.Parse(page.NextLink)
+			if err != nil {
+				return 0, fmt.Errorf("invalid azure schema registry next link %q: %w", page.NextLink, err)
+			}
+			path = next.EscapedPath()
+			if next.RawQuery != "" {
+				path += "?" + next.RawQuery
+			}
+		}
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("azure schema %q has no version", name)
+	}
+	return latest, nil
+}
+
+// Lookup returns the version of the schema named name whose definition is
+// schema.
+func (c *AzureClient) Lookup(ctx context.Context, name string, schema avro.Schema) (*AzureSchema, error) {
+	return c.register(ctx, http.MethodPost, c.schemaPath(name)+":get-id", name, schema)
+}
+
+// Register registers schema as a version of the schema named name, unless it
+// is already one, and returns the version. Its ID is the one to send in the
+// content type avro/binary+<id> of the Event Hubs events.
+func (c *AzureClient) Register(ctx context.Context, name string, schema avro.Schema) (*AzureSchema, error) {
+	return c.register(ctx, http.MethodPut, c.schemaPath(name), name, schema)
+}
+
+// register sends schema to the registry and returns the version of the
+// response, cached by definition.
+func (c *AzureClient) register(ctx context.Context, method, path, name string, schema avro.Schema) (*AzureSchema, error) {
+	key := name + "\x00" + schema.String()
+	c.mu.RLock()
+	s, ok := c.ids[key]
+	c.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	header, _, err := c.do(ctx, method, path, []byte(schema.String()))
+	if err != nil {
+		return nil, err
+	}
+	if s, err = c.schemaOf(header, schema); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids[key] = s
+	c.byID[s.ID] = schema
+	return s, nil
+}
+
+// schema parses and caches the schema version of a response.
+func (c *AzureClient) schema(header http.Header, content []byte) (*AzureSchema, error) {
+	schema, err := avro.Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", header.Get("Schema-Id"), err)
+	}
+	s, err := c.schemaOf(header, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byID[s.ID] = schema
+	return s, nil
+}
+
+// schemaOf returns the schema version described by the headers of a response.
+func (c *AzureClient) schemaOf(header http.Header, schema avro.Schema) (*AzureSchema, error) {
+	id := header.Get("Schema-Id")
+	if id == "" {
+		return nil, errors.New("azure schema registry response has no schema ID")
+	}
+	version, err := strconv.Atoi(header.Get("Schema-Version"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure schema version %q", header.Get("Schema-Version"))
+	}
+	return &AzureSchema{
+		ID:      id,
+		Group:   header.Get("Schema-Group-Name"),
+		Name:    header.Get("Schema-Name"),
+		Version: version,
+		Schema:  schema,
+	}, nil
+}
+
+func (c *AzureClient) schemaPath(name string) string {
+	return "/$schemaGroups/" + url.PathEscape(c.group) + "/schemas/" + url.PathEscape(name)
+}
+
+// do sends a request to the registry and returns the headers and the body of
+// its response.
+func (c *AzureClient) do(ctx context.Context, method, path string, body []byte) (http.Header, []byte, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	if !strings.Contains(path, "api-version=") {
+		path += sep + "api-version=" + azureAPIVersion
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", azureAvroContentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("azure schema registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(content, &e)
+		if e.Error.Message == "" {
+			e.Error.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, nil, &AzureError{StatusCode: resp.StatusCode, Code: e.Error.Code, Message: e.Error.Message}
+	}
+	return resp.Header, content, nil
+}
+
+var _ avrofmt.SchemaRegistry = (*AzureClient)(nil)
@@ -0,0 +1,196 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+const azureSchemaID = "0a1b2c3d4e5f60718293a4b5c6d7e8f9"
+
+// azureServer is a minimal Azure Schema Registry with the versions 1 and 2 of
+// the schema orders of the group shop, counting the requests by path.
+type azureServer struct {
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (s *azureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.Method+" "+r.URL.Path]++
+	s.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("api-version") != azureAPIVersion {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	version := func() {
+		w.Header().Set("Schema-Id", azureSchemaID)
+		w.Header().Set("Schema-Group-Name", "shop")
+		w.Header().Set("Schema-Name", "orders")
+		w.Header().Set("Schema-Version", "2")
+	}
+	switch r.Method + " " + r.URL.Path {
+	case "GET /$schemaGroups/$schemas/" + azureSchemaID, "GET /$schemaGroups/shop/schemas/orders/versions/2":
+		version()
+		fmt.Fprint(w, orderSchema)
+	case "GET /$schemaGroups/shop/schemas/orders/versions":
+		if r.URL.Query().Get("page") == "" {
+			fmt.Fprint(w, `{"value":[1],"nextLink":"/$schemaGroups/shop/schemas/orders/versions?page=2&api-version=`+azureAPIVersion+`"}`)
+		} else {
+			fmt.Fprint(w, `{"value":[2]}`)
+		}
+	case "POST /$schemaGroups/shop/schemas/orders:get-id", "PUT /$schemaGroups/shop/schemas/orders":
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != azureAvroContentType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		if _, err := avro.Parse(string(body)); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		version()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"ItemNotFound","message":"Schema not found."}}`)
+	}
+}
+
+// staticCredential is an AzureTokenCredential counting the tokens it issues.
+type staticCredential struct {
+	expiresIn time.Duration
+	tokens    int
+}
+
+func (c *staticCredential) GetToken(_ context.Context, scopes []string) (AzureToken, error) {
+	if len(scopes) != 1 || scopes[0] != AzureSchemaRegistryScope {
+		return AzureToken{}, fmt.Errorf("unexpected scopes %v", scopes)
+	}
+	c.tokens++
+	return AzureToken{Token: "token", ExpiresOn: time.Now().Add(c.expiresIn)}, nil
+}
+
+func newAzureTestClient(t *testing.T, credential AzureTokenCredential) (*AzureClient, *azureServer) {
+	s := &azureServer{requests: map[string]int{}}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	c, err := NewAzureClient(server.URL, "shop", credential)
+	require.NoError(t, err)
+	return c, s
+}
+
+func TestAzureClientLookups(t *testing.T) {
+	credential := &staticCredential{expiresIn: time.Hour}
+	c, s := newAzureTestClient(t, credential)
+	ctx := context.Background()
+
+	schema, err := c.SchemaByID(ctx, azureSchemaID)
+	require.NoError(t, err)
+	require.Equal(t, "test.Order", schema.(avro.NamedSchema).FullName())
+	_, err = c.SchemaByID(ctx, azureSchemaID)
+	require.NoError(t, err)
+	require.Equal(t, 1, s.requests["GET /$schemaGroups/$schemas/"+azureSchemaID])
+
+	for i := 0; i < 2; i++ {
+		v, err := c.SchemaVersion(ctx, "orders", 2)
+		require.NoError(t, err)
+		require.Equal(t, AzureSchema{ID: azureSchemaID, Group: "shop", Name: "orders", Version: 2, Schema: v.Schema}, *v)
+	}
+	require.Equal(t, 1, s.requests["GET /$schemaGroups/shop/schemas/orders/versions/2"])
+
+	// The latest version is looked up each time, following the pages.
+	for i := 0; i < 2; i++ {
+		v, err := c.SchemaVersion(ctx, "orders", 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, v.Version)
+	}
+	require.Equal(t, 4, s.requests["GET /$schemaGroups/shop/schemas/orders/versions"])
+
+	for i := 0; i < 2; i++ {
+		v, err := c.Lookup(ctx, "orders", schema)
+		require.NoError(t, err)
+		require.Equal(t, azureSchemaID, v.ID)
+	}
+	require.Equal(t, 1, s.requests["POST /$schemaGroups/shop/schemas/orders:get-id"])
+
+	v, err := c.Register(ctx, "orders", schema)
+	require.NoError(t, err)
+	require.Equal(t, 2, v.Version)
+
+	_, err = c.SchemaVersion(ctx, "payments", 1)
+	var registryErr *AzureError
+	require.True(t, errors.As(err, &registryErr))
+	require.Equal(t, http.StatusNotFound, registryErr.StatusCode)
+	require.Equal(t, "ItemNotFound", registryErr.Code)
+
+	// The token is cached until it is about to expire.
+	require.Equal(t, 1, credential.tokens)
+}
+
+func TestAzureClientTokenRefresh(t *testing.T) {
+	credential := &staticCredential{expiresIn: time.Minute}
+	c, _ := newAzureTestClient(t, credential)
+	for i := 0; i < 2; i++ {
+		_, err := c.SchemaVersion(context.Background(), "orders", 2)
+		require.NoError(t, err)
+		_, err = c.SchemaByID(context.Background(), azureSchemaID)
+		require.NoError(t, err)
+	}
+	// The versions are cached, the schema ID is cached by the first request.
+	require.Equal(t, 1, credential.tokens)
+
+	_, err := c.SchemaVersion(context.Background(), "orders", 0)
+	require.NoError(t, err)
+	require.Equal(t, 3, credential.tokens)
+}
+
+func TestAzureClientSchemaRegistry(t *testing.T) {
+	c, s := newAzureTestClient(t, &staticCredential{expiresIn: time.Hour})
+	c.Bind(order{}, "orders", 2)
+	avrofmt.SetSchemaRegistry(c)
+	defer avrofmt.SetSchemaRegistry(nil)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		data, err := avrofmt.EncodeData(ctx, order{ID: "o-1"})
+		require.NoError(t, err)
+		var got order
+		require.NoError(t, avrofmt.DecodeData(ctx, data, &got))
+		require.Equal(t, order{ID: "o-1"}, got)
+	}
+	require.Equal(t, 1, s.requests["GET /$schemaGroups/shop/schemas/orders/versions/2"])
+
+	_, err := c.GetSchema(struct{}{})
+	require.Error(t, err)
+}
+
+func TestNewAzureClient(t *testing.T) {
+	credential := &staticCredential{expiresIn: time.Hour}
+	c, err := NewAzureClient("shop.servicebus.windows.net", "shop", credential)
+	require.NoError(t, err)
+	require.Equal(t, "https://shop.servicebus.windows.net", c.endpoint)
+
+	_, err = NewAzureClient("", "shop", credential)
+	require.Error(t, err)
+	_, err = NewAzureClient("shop.servicebus.windows.net", "", credential)
+	require.Error(t, err)
+	_, err = NewAzureClient("shop.servicebus.windows.net", "shop", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,304 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+// confluentContentType is the media type of the Confluent Schema Registry
+// REST API.
+const confluentContentType = "application/vnd.schemaregistry.v1+json"
+
+// ConfluentError is an error response of a Confluent Schema Registry.
+type ConfluentError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is the error code of the registry, e.g. 40401 when the subject
+	// is not found.
+	Code int `json:"error_code"`
+	// Message is the error message of the registry.
+	Message string `json:"message"`
+}
+
+func (e *ConfluentError) Error() string {
+	return fmt.Sprintf("schema registry error %d (HTTP %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// ConfluentSchema is a schema version of a subject of a Confluent Schema
+// Registry.
+type ConfluentSchema struct {
+	Subject string
+	Version int
+	ID      int
+	Schema  avro.Schema
+}
+
+// confluentBinding is the subject version the schema of a Go type is
+// resolved from.
+type confluentBinding struct {
+	subject string
+	version int
+}
+
+// ConfluentClient is a client of a Confluent Schema Registry, or of any
+// registry implementing its REST API. The schemas are cached in memory: the
+// schema IDs and the subject versions are immutable, so they are fetched
+// once.
+//
+// ConfluentClient implements avro.SchemaRegistry for the Go types bound to a
+// subject with Bind, so that it can be given to avro.SetSchemaRegistry or
+// avro.WithSchemaRegistry, and avro.SubjectRegistry, so that it can register
+// the schemas of a ConfluentWireFormat or of a KeyEncoder.
+type ConfluentClient struct {
+	baseURL *url.URL
+	client  *http.Client
+
+	mu       sync.RWMutex
+	byID     map[int]avro.Schema
+	versions map[confluentBinding]*ConfluentSchema
+	ids      map[string]int
+	types    map[reflect.Type]confluentBinding
+	resolved map[reflect.Type]avro.Schema
+}
+
+// NewConfluentClient returns a client of the registry at baseURL, reached
+// with an HTTP client configured by the options, see NewHTTPClient.
+func NewConfluentClient(baseURL string, opts ...Option) (*ConfluentClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema registry URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("schema registry URL must be http or https, got %q", baseURL)
+	}
+	client, err := NewHTTPClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfluentClient{
+		baseURL:  u,
+		client:   client,
+		byID:     map[int]avro.Schema{},
+		versions: map[confluentBinding]*ConfluentSchema{},
+		ids:      map[string]int{},
+		types:    map[reflect.Type]confluentBinding{},
+		resolved: map[reflect.Type]avro.Schema{},
+	}, nil
+}
+
+// Bind resolves the schema of the values of the type of v, or of the type it
+// points to, from the given version of subject. A version lower than 1 is the
+// latest version at the time the schema is first resolved.
+func (c *ConfluentClient) Bind(v interface{}, subject string, version int) {
+	if version < 1 {
+		version = 0
+	}
+	t := indirectType(v)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.types[t] = confluentBinding{subject: subject, version: version}
+	delete(c.resolved, t)
+}
+
+// GetSchema implements avro.SchemaRegistry.GetSchema for the types bound with
+// Bind.
+func (c *ConfluentClient) GetSchema(v interface{}) (avro.Schema, error) {
+	t := indirectType(v)
+	c.mu.RLock()
+	b, ok := c.types[t]
+	schema, resolved := c.resolved[t]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no schema registry subject bound to %T: %w", v, avrofmt.ErrSchemaNotFound)
+	}
+	if resolved {
+		return schema, nil
+	}
+	s, err := c.SchemaBySubject(context.Background(), b.subject, b.version)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resolved[t] = s.Schema
+	return s.Schema, nil
+}
+
+// SchemaByID returns the schema with the given ID.
+func (c *ConfluentClient) SchemaByID(ctx context.Context, id int) (avro.Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.byID[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	var resp struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	schema, err := parseConfluentSchema(resp.SchemaType, resp.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %d: %w", id, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byID[id] = schema
+	return schema, nil
+}
+
+// SchemaBySubject returns the given version of subject. A version lower than
+// 1 is the latest version, which is fetched each time.
+func (c *ConfluentClient) SchemaBySubject(ctx context.Context, subject string, version int) (*ConfluentSchema, error) {
+	key := confluentBinding{subject: subject, version: version}
+	if version < 1 {
+		key.version = 0
+	}
+	c.mu.RLock()
+	s, ok := c.versions[key]
+	c.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	v := "latest"
+	if key.version > 0 {
+		v = strconv.Itoa(key.version)
+	}
+	var resp struct {
+		Subject    string `json:"subject"`
+		Version    int    `json:"version"`
+		ID         int    `json:"id"`
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/"+v, nil, &resp); err != nil {
+		return nil, err
+	}
+	schema, err := parseConfluentSchema(resp.SchemaType, resp.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema of subject %q version %d: %w", subject, resp.Version, err)
+	}
+	s = &ConfluentSchema{Subject: resp.Subject, Version: resp.Version, ID: resp.ID, Schema: schema}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byID[s.ID] = schema
+	c.versions[confluentBinding{subject: subject, version: s.Version}] = s
+	return s, nil
+}
+
+// Register implements avro.SubjectRegistry.Register: it registers schema
+// under subject, unless already registered, and returns its ID.
+func (c *ConfluentClient) Register(ctx context.Context, subject string, schema avro.Schema) (int, error) {
+	key := subject + "\x00" + schema.String()
+	c.mu.RLock()
+	id, ok := c.ids[key]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	req := struct {
+		Schema string `json:"schema"`
+	}{Schema: schema.String()}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", req, &resp); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids[key] = resp.ID
+	c.byID[resp.ID] = schema
+	return resp.ID, nil
+}
+
+// do sends a request to the registry and decodes its JSON response into out.
+func (c *ConfluentClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.baseURL.String(), "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", confluentContentType)
+	if in != nil {
+		req.Header.Set("Content-Type", confluentContentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("schema registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		e := &ConfluentError{StatusCode: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(e); err != nil || e.Message == "" {
+			e.Message = http.StatusText(resp.StatusCode)
+		}
+		return e
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid schema registry response: %w", err)
+	}
+	return nil
+}
+
+// parseConfluentSchema parses a schema of a registry, which are Avro schemas
+// unless their type says otherwise.
+func parseConfluentSchema(schemaType, schema string) (avro.Schema, error) {
+	if schemaType != "" && schemaType != "AVRO" {
+		return nil, fmt.Errorf("unsupported schema type %q", schemaType)
+	}
+	if schema == "" {
+		return nil, errors.New("empty schema")
+	}
+	return avro.Parse(schema)
+}
+
+// indirectType returns the type of v, or the type it points to.
+func indirectType(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+var (
+	_ avrofmt.SchemaRegistry  = (*ConfluentClient)(nil)
+	_ avrofmt.SubjectRegistry = (*ConfluentClient)(nil)
+)
@@ -0,0 +1,142 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+const orderSchema = `{"type":"record","name":"Order","namespace":"test","fields":[{"name":"id","type":"string"}]}`
+
+type order struct {
+	ID string `avro:"id"`
+}
+
+// confluentServer is a minimal Confluent Schema Registry counting the
+// requests by path.
+type confluentServer struct {
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (s *confluentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.Method+" "+r.URL.EscapedPath()]++
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", confluentContentType)
+	switch r.Method + " " + r.URL.EscapedPath() {
+	case "GET /schemas/ids/7":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"schema": orderSchema})
+	case "GET /subjects/orders-value/versions/latest", "GET /subjects/orders-value/versions/2":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"subject": "orders-value", "version": 2, "id": 7, "schema": orderSchema})
+	case "POST /subjects/test.Order/versions":
+		if r.Header.Get("Content-Type") != confluentContentType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		var req struct {
+			Schema string `json:"schema"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": 8})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error_code": 40401, "message": "Subject not found"})
+	}
+}
+
+func newConfluentTestClient(t *testing.T) (*ConfluentClient, *confluentServer) {
+	s := &confluentServer{requests: map[string]int{}}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	c, err := NewConfluentClient(server.URL + "/")
+	require.NoError(t, err)
+	return c, s
+}
+
+func TestConfluentClientLookups(t *testing.T) {
+	c, s := newConfluentTestClient(t)
+	ctx := context.Background()
+
+	schema, err := c.SchemaByID(ctx, 7)
+	require.NoError(t, err)
+	require.Equal(t, "test.Order", schema.(interface{ FullName() string }).FullName())
+	_, err = c.SchemaByID(ctx, 7)
+	require.NoError(t, err)
+	require.Equal(t, 1, s.requests["GET /schemas/ids/7"])
+
+	for i := 0; i < 2; i++ {
+		v, err := c.SchemaBySubject(ctx, "orders-value", 2)
+		require.NoError(t, err)
+		require.Equal(t, ConfluentSchema{Subject: "orders-value", Version: 2, ID: 7, Schema: v.Schema}, *v)
+	}
+	require.Equal(t, 1, s.requests["GET /subjects/orders-value/versions/2"])
+
+	// The latest version is fetched each time.
+	for i := 0; i < 2; i++ {
+		_, err = c.SchemaBySubject(ctx, "orders-value", 0)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, s.requests["GET /subjects/orders-value/versions/latest"])
+
+	_, err = c.SchemaBySubject(ctx, "payments-value", 1)
+	var registryErr *ConfluentError
+	require.True(t, errors.As(err, &registryErr))
+	require.Equal(t, ConfluentError{StatusCode: http.StatusNotFound, Code: 40401, Message: "Subject not found"}, *registryErr)
+}
+
+func TestConfluentClientSchemaRegistry(t *testing.T) {
+	c, s := newConfluentTestClient(t)
+	c.Bind(order{}, "orders-value", 0)
+	avrofmt.SetSchemaRegistry(c)
+	defer avrofmt.SetSchemaRegistry(nil)
+
+	ctx := context.Background()
+	data, err := avrofmt.EncodeData(ctx, &order{ID: "42"})
+	require.NoError(t, err)
+	var decoded order
+	require.NoError(t, avrofmt.DecodeData(ctx, data, &decoded))
+	require.Equal(t, "42", decoded.ID)
+	// The latest version is resolved once for the bound type.
+	require.Equal(t, 1, s.requests["GET /subjects/orders-value/versions/latest"])
+
+	_, err = c.GetSchema(struct{}{})
+	require.Error(t, err)
+}
+
+func TestConfluentClientRegister(t *testing.T) {
+	c, s := newConfluentTestClient(t)
+	c.Bind(order{}, "orders-value", 2)
+	avrofmt.SetSchemaRegistry(c)
+	defer avrofmt.SetSchemaRegistry(nil)
+	ctx := avrofmt.WithConfluentWireFormat(context.Background(), &avrofmt.ConfluentWireFormat{Registry: c})
+
+	for i := 0; i < 2; i++ {
+		data, err := avrofmt.EncodeData(ctx, order{ID: "42"})
+		require.NoError(t, err)
+		id, _, err := avrofmt.ParseConfluentHeader(data)
+		require.NoError(t, err)
+		require.Equal(t, 8, id)
+	}
+	require.Equal(t, 1, s.requests["POST /subjects/test.Order/versions"])
+}
+
+func TestNewConfluentClientInvalid(t *testing.T) {
+	_, err := NewConfluentClient("registry:8081")
+	require.Error(t, err)
+	_, err = NewConfluentClient("http://registry:8081", WithTimeout(0))
+	require.Error(t, err)
+}
@@ -0,0 +1,50 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package registry holds the configuration shared by the clients of remote
+schema registries: the TLS settings (custom CA, mutual TLS), the
+authentication (basic, bearer token, AWS SigV4) and the HTTP proxy, since
+registries often sit behind corporate gateways.
+
+NewHTTPClient builds the *http.Client used to reach a registry:
+
+	client, err := registry.NewHTTPClient(
+		registry.WithCACertificate(caPEM),
+		registry.WithClientCertificate(certPEM, keyPEM),
+		registry.WithBasicAuth("user", "password"),
+		registry.WithProxy("http://proxy.internal:3128"),
+	)
+
+ConfluentClient is a client of a Confluent Schema Registry, configured by the
+same options. It resolves the schemas of the Go types bound to a subject and
+registers the schemas of the Confluent wire format:
+
+	c, err := registry.NewConfluentClient("https://registry.internal:8081",
+		registry.WithBasicAuth("key", "secret"),
+	)
+	c.Bind(Order{}, "orders-value", 0)
+	avro.SetSchemaRegistry(c)
+	avro.SetConfluentWireFormat(&avro.ConfluentWireFormat{Registry: c})
+
+ApicurioClient is the equivalent client of an Apicurio Registry, for the
+artifacts of a group, identified by their global IDs. GlueClient is the one of
+an AWS Glue Schema Registry, whose schema versions are identified by UUIDs in
+the avro.GlueWireFormat.
+
+AzureClient is the client of the Schema Registry of an Azure Event Hubs
+namespace, for the schemas of a schema group, authenticated with the Microsoft
+Entra ID access tokens of an AzureTokenCredential:
+
+	c, err := registry.NewAzureClient("shop.servicebus.windows.net", "orders", credential)
+	c.Bind(Order{}, "order", 0)
+	avro.SetSchemaRegistry(c)
+
+Clients of different registries can be used in one process, each given to
+avro.EncodeData and avro.DecodeData by the context of its events:
+
+	ctx = avro.WithSchemaRegistry(ctx, c)
+*/
+package registry
@@ -0,0 +1,328 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+// glueContentType is the media type of the AWS Glue API.
+const glueContentType = "application/x-amz-json-1.1"
+
+// GlueError is an error response of the AWS Glue API.
+type GlueError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Type is the type of the error, e.g. EntityNotFoundException.
+	Type string
+	// Message is the error message.
+	Message string
+}
+
+func (e *GlueError) Error() string {
+	return fmt.Sprintf("glue schema registry error %s (HTTP %d): %s", e.Type, e.StatusCode, e.Message)
+}
+
+// GlueSchemaVersion is a version of a schema of an AWS Glue Schema Registry.
+type GlueSchemaVersion struct {
+	SchemaName string
+	Version    int64
+	// ID is the UUID of the schema version.
+	ID     [16]byte
+	Schema avro.Schema
+}
+
+// glueBinding is the schema version the schema of a Go type is resolved from.
+type glueBinding struct {
+	schemaName string
+	version    int64
+}
+
+// GlueClient is a client of an AWS Glue Schema Registry, signing its requests
+// with SigV4. The schemas are cached in memory: the schema versions are
+// immutable, so they are fetched once.
+//
+// GlueClient implements avro.SchemaRegistry for the Go types bound to a schema
+// with Bind, and avro.GlueRegistry, so that it can resolve the schema versions
+// of a GlueWireFormat.
+type GlueClient struct {
+	endpoint string
+	registry string
+	client   *http.Client
+
+	mu       sync.RWMutex
+	byID     map[[16]byte]avro.Schema
+	versions map[glueBinding]*GlueSchemaVersion
+	ids      map[string][16]byte
+	types    map[reflect.Type]glueBinding
+	resolved map[reflect.Type]avro.Schema
+}
+
+// NewGlueClient returns a client of the Glue Schema Registry named registryName
+// in region, signing the requests with credentials. The HTTP client is
+// configured by the options, see NewHTTPClient, but its authentication.
+func NewGlueClient(region, registryName string, credentials CredentialsProvider, opts ...Option) (*GlueClient, error) {
+	if region == "" || registryName == "" {
+		return nil, errors.New("glue schema registry requires a region and a registry name")
+	}
+	client, err := NewHTTPClient(append(opts, WithSigV4(credentials, region, "glue"))...)
+	if err != nil {
+		return nil, err
+	}
+	return &GlueClient{
+		endpoint: "https://glue." + region + ".amazonaws.com/",
+		registry: registryName,
+		client:   client,
+		byID:     map[[16]byte]avro.Schema{},
+		versions: map[glueBinding]*GlueSchemaVersion{},
+		ids:      map[string][16]byte{},
+		types:    map[reflect.Type]glueBinding{},
+		resolved: map[reflect.Type]avro.Schema{},
+	}, nil
+}
+
+// Bind resolves the schema of the values of the type of v, or of the type it
+// points to, from the given version of the schema named schemaName. A version
+// lower than 1 is the latest version at the time the schema is first resolved.
+func (c *GlueClient) Bind(v interface{}, schemaName string, version int64) {
+	if version < 1 {
+		version = 0
+	}
+	t := indirectType(v)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.types[t] = glueBinding{schemaName: schemaName, version: version}
+	delete(c.resolved, t)
+}
+
+// GetSchema implements avro.SchemaRegistry.GetSchema for the types bound with
+// Bind.
+func (c *GlueClient) GetSchema(v interface{}) (avro.Schema, error) {
+	t := indirectType(v)
+	c.mu.RLock()
+	b, ok := c.types[t]
+	schema, resolved := c.resolved[t]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no glue schema bound to %T: %w", v, avrofmt.ErrSchemaNotFound)
+	}
+	if resolved {
+		return schema, nil
+	}
+	s, err := c.SchemaVersion(context.Background(), b.schemaName, b.version)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resolved[t] = s.Schema
+	return s.Schema, nil
+}
+
+// glueSchemaVersionResponse is the response of GetSchemaVersion.
+type glueSchemaVersionResponse struct {
+	SchemaVersionID  string `json:"SchemaVersionId"`
+	SchemaDefinition string `json:"SchemaDefinition"`
+	DataFormat       string `json:"DataFormat"`
+	SchemaArn        string `json:"SchemaArn"`
+	VersionNumber    int64  `json:"VersionNumber"`
+	Status           string `json:"Status"`
+}
+
+// SchemaByVersionID returns the schema of the schema version with the given
+// UUID, as found in the Glue wire format.
+func (c *GlueClient) SchemaByVersionID(ctx context.Context, id [16]byte) (avro.Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.byID[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	var resp glueSchemaVersionResponse
+	if err := c.do(ctx, "GetSchemaVersion", map[string]interface{}{"SchemaVersionId": FormatUUID(id)}, &resp); err != nil {
+		return nil, err
+	}
+	s, err := c.schemaVersion(resp)
+	if err != nil {
+		return nil, err
+	}
+	return s.Schema, nil
+}
+
+// SchemaVersion returns the given version of the schema named schemaName. A
+// version lower than 1 is the latest version, which is fetched each time.
+func (c *GlueClient) SchemaVersion(ctx context.Context, schemaName string, version int64) (*GlueSchemaVersion, error) {
+	key := glueBinding{schemaName: schemaName, version: version}
+	if version < 1 {
+		key.version = 0
+	}
+	c.mu.RLock()
+	s, ok := c.versions[key]
+	c.mu.RUnlock()
+	if ok {
+		return s, nil
+	}
+
+	number := map[string]interface{}{"LatestVersion": true}
+	if key.version > 0 {
+		number = map[string]interface{}{"VersionNumber": key.version}
+	}
+	var resp glueSchemaVersionResponse
+	if err := c.do(ctx, "GetSchemaVersion", map[string]interface{}{
+		"SchemaId":            c.schemaID(schemaName),
+		"SchemaVersionNumber": number,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	s, err := c.schemaVersion(resp)
+	if err != nil {
+		return nil, err
+	}
+	s.SchemaName = schemaName
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions[glueBinding{schemaName: schemaName, version: s.Version}] = s
+	return s, nil
+}
+
+// SchemaVersionID implements avro.GlueRegistry.SchemaVersionID: it looks up
+// the version of schemaName by definition, and registers it if not found.
+func (c *GlueClient) SchemaVersionID(ctx context.Context, schemaName string, schema avro.Schema) ([16]byte, error) {
+	key := schemaName + "\x00" + schema.String()
+	c.mu.RLock()
+	id, ok := c.ids[key]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	req := map[string]interface{}{"SchemaId": c.schemaID(schemaName), "SchemaDefinition": schema.String()}
+	var resp struct {
+		SchemaVersionID string `json:"SchemaVersionId"`
+	}
+	err := c.do(ctx, "GetSchemaByDefinition", req, &resp)
+	var glueErr *GlueError
+	if errors.As(err, &glueErr) && glueErr.Type == "EntityNotFoundException" {
+		err = c.do(ctx, "RegisterSchemaVersion", req, &resp)
+	}
+	if err != nil {
+		return id, err
+	}
+	if id, err = ParseUUID(resp.SchemaVersionID); err != nil {
+		return id, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids[key] = id
+	c.byID[id] = schema
+	return id, nil
+}
+
+// schemaVersion parses and caches the schema version of resp.
+func (c *GlueClient) schemaVersion(resp glueSchemaVersionResponse) (*GlueSchemaVersion, error) {
+	if resp.DataFormat != "" && resp.DataFormat != "AVRO" {
+		return nil, fmt.Errorf("unsupported glue data format %q", resp.DataFormat)
+	}
+	id, err := ParseUUID(resp.SchemaVersionID)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := avro.Parse(resp.SchemaDefinition)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema version %s: %w", resp.SchemaVersionID, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byID[id] = schema
+	return &GlueSchemaVersion{Version: resp.VersionNumber, ID: id, Schema: schema}, nil
+}
+
+func (c *GlueClient) schemaID(schemaName string) map[string]string {
+	return map[string]string{"RegistryName": c.registry, "SchemaName": schemaName}
+}
+
+// do invokes the action of the Glue API and decodes its response into out.
+func (c *GlueClient) do(ctx context.Context, action string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", glueContentType)
+	req.Header.Set("X-Amz-Target", "AWSGlue."+action)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("glue schema registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(content, &e)
+		// The type may be prefixed with a namespace.
+		e.Type = e.Type[strings.LastIndex(e.Type, "#")+1:]
+		if e.Message == "" {
+			e.Message = http.StatusText(resp.StatusCode)
+		}
+		return &GlueError{StatusCode: resp.StatusCode, Type: e.Type, Message: e.Message}
+	}
+	if err := json.Unmarshal(content, out); err != nil {
+		return fmt.Errorf("invalid glue schema registry response: %w", err)
+	}
+	return nil
+}
+
+// FormatUUID returns the canonical form of the UUID id, e.g. of a Glue schema
+// version.
+func FormatUUID(id [16]byte) string {
+	s := hex.EncodeToString(id[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// ParseUUID parses a UUID in canonical form.
+func ParseUUID(s string) ([16]byte, error) {
+	var id [16]byte
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return id, fmt.Errorf("invalid UUID %q", s)
+	}
+	if _, err := hex.Decode(id[:], []byte(strings.ReplaceAll(s, "-", ""))); err != nil {
+		return id, fmt.Errorf("invalid UUID %q: %w", s, err)
+	}
+	return id, nil
+}
+
+var (
+	_ avrofmt.SchemaRegistry = (*GlueClient)(nil)
+	_ avrofmt.GlueRegistry   = (*GlueClient)(nil)
+)
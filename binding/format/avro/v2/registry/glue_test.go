@@ -0,0 +1,143 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+const glueVersionID = "b7b4a7f0-9c96-4e4a-a8d4-0b0e8c1c4c2d"
+
+// glueServer is a minimal Glue API with the version 2 of the schema
+// orders of the registry shop, counting the requests by action.
+type glueServer struct {
+	mu         sync.Mutex
+	requests   map[string]int
+	registered bool
+}
+
+func (s *glueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AWSGlue.")
+	s.requests[action]++
+	if r.Host != "glue.eu-west-1.amazonaws.com" || !strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/glue/aws4_request") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	var req struct {
+		SchemaVersionID     string            `json:"SchemaVersionId"`
+		SchemaID            map[string]string `json:"SchemaId"`
+		SchemaVersionNumber map[string]interface{}
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	version := fmt.Sprintf(`{"SchemaVersionId":%q,"SchemaDefinition":%q,"DataFormat":"AVRO","VersionNumber":2}`, glueVersionID, orderSchema)
+	switch {
+	case action == "GetSchemaVersion" && req.SchemaVersionID == glueVersionID:
+		fmt.Fprint(w, version)
+	case action == "GetSchemaVersion" && req.SchemaID["RegistryName"] == "shop" && req.SchemaID["SchemaName"] == "orders":
+		fmt.Fprint(w, version)
+	case action == "GetSchemaByDefinition" && s.registered:
+		fmt.Fprintf(w, `{"SchemaVersionId":%q}`, glueVersionID)
+	case action == "RegisterSchemaVersion":
+		s.registered = true
+		fmt.Fprintf(w, `{"SchemaVersionId":%q,"VersionNumber":2}`, glueVersionID)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"__type":"com.amazonaws.glue#EntityNotFoundException","message":"Schema is not found."}`)
+	}
+}
+
+func newGlueTestClient(t *testing.T) (*GlueClient, *glueServer) {
+	s := &glueServer{requests: map[string]int{}}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	// Send the requests to the Glue endpoint to the test server.
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Host = req.URL.Host
+		req.URL.Scheme = serverURL.Scheme
+		req.URL.Host = serverURL.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	c, err := NewGlueClient("eu-west-1", "shop", StaticCredentials("id", "secret", ""), WithTransport(transport))
+	require.NoError(t, err)
+	return c, s
+}
+
+func TestGlueClientLookups(t *testing.T) {
+	c, s := newGlueTestClient(t)
+	ctx := context.Background()
+	id, err := ParseUUID(glueVersionID)
+	require.NoError(t, err)
+	require.Equal(t, glueVersionID, FormatUUID(id))
+
+	for i := 0; i < 2; i++ {
+		schema, err := c.SchemaByVersionID(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, "record", string(schema.Type()))
+	}
+	require.Equal(t, 1, s.requests["GetSchemaVersion"])
+
+	for i := 0; i < 2; i++ {
+		v, err := c.SchemaVersion(ctx, "orders", 2)
+		require.NoError(t, err)
+		require.Equal(t, GlueSchemaVersion{SchemaName: "orders", Version: 2, ID: id, Schema: v.Schema}, *v)
+	}
+	require.Equal(t, 2, s.requests["GetSchemaVersion"])
+
+	_, err = c.SchemaVersion(ctx, "payments", 0)
+	var glueErr *GlueError
+	require.True(t, errors.As(err, &glueErr))
+	require.Equal(t, GlueError{StatusCode: http.StatusBadRequest, Type: "EntityNotFoundException", Message: "Schema is not found."}, *glueErr)
+}
+
+func TestGlueClientWireFormat(t *testing.T) {
+	c, s := newGlueTestClient(t)
+	c.Bind(order{}, "orders", 0)
+	avrofmt.SetSchemaRegistry(c)
+	defer avrofmt.SetSchemaRegistry(nil)
+	ctx := avrofmt.WithWireFormat(context.Background(), &avrofmt.GlueWireFormat{Registry: c, SchemaName: "orders"})
+
+	for i := 0; i < 2; i++ {
+		data, err := avrofmt.EncodeData(ctx, &order{ID: "42"})
+		require.NoError(t, err)
+		id, _, err := avrofmt.ParseGlueHeader(data)
+		require.NoError(t, err)
+		require.Equal(t, glueVersionID, FormatUUID(id))
+
+		var decoded order
+		require.NoError(t, avrofmt.DecodeData(ctx, data, &decoded))
+		require.Equal(t, "42", decoded.ID)
+	}
+	// The definition is registered when not found, once.
+	require.Equal(t, 1, s.requests["GetSchemaByDefinition"])
+	require.Equal(t, 1, s.requests["RegisterSchemaVersion"])
+}
+
+func TestNewGlueClientInvalid(t *testing.T) {
+	_, err := NewGlueClient("", "shop", StaticCredentials("id", "secret", ""))
+	require.Error(t, err)
+	_, err = NewGlueClient("eu-west-1", "shop", nil)
+	require.Error(t, err)
+	_, err = ParseUUID("not-a-uuid")
+	require.Error(t, err)
+}
@@ -0,0 +1,254 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cloudevents/sdk-go/protocol/http/sigv4/v2"
+)
+
+// DefaultTimeout is the default timeout of the requests to a registry.
+const DefaultTimeout = 30 * time.Second
+
+// Option configures the HTTP client of a remote schema registry.
+type Option func(*config) error
+
+type config struct {
+	tls     *tls.Config
+	rootCAs *x509.CertPool
+	certs   []tls.Certificate
+	auth    func(http.RoundTripper) http.RoundTripper
+	proxy   func(*http.Request) (*url.URL, error)
+	timeout time.Duration
+	base    http.RoundTripper
+}
+
+// WithTLSConfig sets the TLS configuration of the connections to the
+// registry. The options adding certificates apply on top of it.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *config) error {
+		if tlsConfig == nil {
+			return errors.New("registry TLS config can not be nil")
+		}
+		c.tls = tlsConfig.Clone()
+		return nil
+	}
+}
+
+// WithCACertificate trusts the PEM encoded certificates of pemCerts to
+// verify the registry, instead of the system certificates.
+func WithCACertificate(pemCerts []byte) Option {
+	return func(c *config) error {
+		if c.rootCAs == nil {
+			c.rootCAs = x509.NewCertPool()
+		}
+		if !c.rootCAs.AppendCertsFromPEM(pemCerts) {
+			return errors.New("registry CA certificate option was given no valid PEM certificate")
+		}
+		return nil
+	}
+}
+
+// WithClientCertificate authenticates to the registry with mutual TLS, using
+// the PEM encoded certificate and private key.
+func WithClientCertificate(certPEM, keyPEM []byte) Option {
+	return func(c *config) error {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("registry client certificate option was given an invalid key pair: %w", err)
+		}
+		c.certs = append(c.certs, cert)
+		return nil
+	}
+}
+
+// WithBasicAuth authenticates the requests to the registry with HTTP basic
+// authentication, as used by Confluent Schema Registry API keys.
+func WithBasicAuth(username, password string) Option {
+	return func(c *config) error {
+		if username == "" {
+			return errors.New("registry basic auth option was given an empty username")
+		}
+		c.auth = func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req = req.Clone(req.Context())
+				req.SetBasicAuth(username, password)
+				return next.RoundTrip(req)
+			})
+		}
+		return nil
+	}
+}
+
+// WithBearerToken authenticates the requests to the registry with the given
+// bearer token.
+func WithBearerToken(token string) Option {
+	return WithBearerTokenSource(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// WithBearerTokenSource authenticates the requests to the registry with the
+// bearer token returned by source for each request, so that tokens can be
+// refreshed, e.g. by an OAuth2 token source.
+func WithBearerTokenSource(source func(ctx context.Context) (string, error)) Option {
+	return func(c *config) error {
+		if source == nil {
+			return errors.New("registry bearer token source can not be nil")
+		}
+		c.auth = func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				token, err := source(req.Context())
+				if err != nil {
+					return nil, fmt.Errorf("failed to get registry bearer token: %w", err)
+				}
+				req = req.Clone(req.Context())
+				req.Header.Set("Authorization", "Bearer "+token)
+				return next.RoundTrip(req)
+			})
+		}
+		return nil
+	}
+}
+
+// Credentials are AWS credentials, see sigv4.Credentials.
+type Credentials = sigv4.Credentials
+
+// CredentialsProvider returns the AWS credentials signing a request, see
+// sigv4.CredentialsProvider.
+type CredentialsProvider = sigv4.CredentialsProvider
+
+// StaticCredentials returns a CredentialsProvider always returning the given
+// credentials.
+func StaticCredentials(accessKeyID, secretAccessKey, sessionToken string) CredentialsProvider {
+	return sigv4.StaticCredentials(accessKeyID, secretAccessKey, sessionToken)
+}
+
+// WithSigV4 signs the requests to the registry with AWS Signature Version 4,
+// as required by AWS Glue Schema Registry. service is the signing name of the
+// service, "glue" for Glue.
+func WithSigV4(credentials CredentialsProvider, region, service string) Option {
+	return func(c *config) error {
+		if credentials == nil {
+			return errors.New("registry SigV4 credentials provider can not be nil")
+		}
+		if region == "" || service == "" {
+			return errors.New("registry SigV4 option requires a region and a service")
+		}
+		s, err := sigv4.NewSigner(credentials, region, service)
+		if err != nil {
+			return err
+		}
+		c.auth = func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				// The payload is hashed, read it and sign a copy of req.
+				var body []byte
+				if req.Body != nil && req.Body != http.NoBody {
+					b, err := io.ReadAll(req.Body)
+					_ = req.Body.Close()
+					if err != nil {
+						return nil, err
+					}
+					body = b
+				}
+				req = req.Clone(req.Context())
+				if body != nil {
+					req.Body = io.NopCloser(bytes.NewReader(body))
+				}
+				if err := s.Sign(req, body); err != nil {
+					return nil, fmt.Errorf("failed to sign the registry request: %w", err)
+				}
+				return next.RoundTrip(req)
+			})
+		}
+		return nil
+	}
+}
+
+// WithProxy sends the requests to the registry through the HTTP proxy at
+// proxyURL. By default, the proxy is taken from the environment
+// (HTTP_PROXY, HTTPS_PROXY and NO_PROXY).
+func WithProxy(proxyURL string) Option {
+	return func(c *config) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("registry proxy option was given an invalid URL: %w", err)
+		}
+		c.proxy = http.ProxyURL(u)
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of the requests to the registry. Defaults to
+// DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) error {
+		if timeout <= 0 {
+			return fmt.Errorf("registry timeout must be positive, got %s", timeout)
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithTransport sets the transport the requests are sent with, the TLS and
+// proxy options are then ignored. This is mostly useful for tests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *config) error {
+		if transport == nil {
+			return errors.New("registry transport can not be nil")
+		}
+		c.base = transport
+		return nil
+	}
+}
+
+// NewHTTPClient returns an *http.Client configured by the options to reach a
+// remote schema registry.
+func NewHTTPClient(opts ...Option) (*http.Client, error) {
+	c := &config{
+		tls:     &tls.Config{MinVersion: tls.VersionTLS12},
+		proxy:   http.ProxyFromEnvironment,
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	transport := c.base
+	if transport == nil {
+		if c.rootCAs != nil {
+			c.tls.RootCAs = c.rootCAs
+		}
+		c.tls.Certificates = append(c.tls.Certificates, c.certs...)
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = c.tls
+		t.Proxy = c.proxy
+		transport = t
+	}
+	if c.auth != nil {
+		transport = c.auth(transport)
+	}
+	return &http.Client{Transport: transport, Timeout: c.timeout}, nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
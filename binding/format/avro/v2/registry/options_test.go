@@ -0,0 +1,163 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func certPEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// newClientCertificate returns a self-signed client certificate and its key.
+func newClientCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return certPEM(der), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestNewHTTPClientTLS(t *testing.T) {
+	clientCert, clientKey := newClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(clientCert))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Len(t, req.TLS.PeerCertificates, 1)
+		require.Equal(t, "client", req.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	serverCA := certPEM(server.Certificate().Raw)
+
+	// The server certificate is not trusted by default.
+	client, err := NewHTTPClient()
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	require.Error(t, err)
+
+	// The client certificate is required.
+	client, err = NewHTTPClient(WithCACertificate(serverCA))
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	require.Error(t, err)
+
+	client, err = NewHTTPClient(WithCACertificate(serverCA), WithClientCertificate(clientCert, clientKey))
+	require.NoError(t, err)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestNewHTTPClientAuth(t *testing.T) {
+	testCases := map[string]struct {
+		option Option
+		check  func(t *testing.T, req *http.Request)
+	}{
+		"basic": {
+			option: WithBasicAuth("user", "secret"),
+			check: func(t *testing.T, req *http.Request) {
+				user, password, ok := req.BasicAuth()
+				require.True(t, ok)
+				require.Equal(t, "user", user)
+				require.Equal(t, "secret", password)
+			},
+		},
+		"bearer": {
+			option: WithBearerToken("token"),
+			check: func(t *testing.T, req *http.Request) {
+				require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+			},
+		},
+		"sigv4": {
+			option: WithSigV4(StaticCredentials("AKID", "secret", "session"), "eu-west-1", "glue"),
+			check: func(t *testing.T, req *http.Request) {
+				require.Contains(t, req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/")
+				require.Contains(t, req.Header.Get("Authorization"), "/eu-west-1/glue/aws4_request")
+				require.Equal(t, "session", req.Header.Get("X-Amz-Security-Token"))
+				require.NotEmpty(t, req.Header.Get("X-Amz-Date"))
+			},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				tc.check(t, req)
+			}))
+			defer server.Close()
+
+			client, err := NewHTTPClient(tc.option)
+			require.NoError(t, err)
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+		})
+	}
+}
+
+func TestNewHTTPClientBearerTokenSourceError(t *testing.T) {
+	client, err := NewHTTPClient(WithBearerTokenSource(func(context.Context) (string, error) {
+		return "", errors.New("expired")
+	}))
+	require.NoError(t, err)
+	_, err = client.Get("http://registry.invalid")
+	require.ErrorContains(t, err, "failed to get registry bearer token: expired")
+}
+
+func TestNewHTTPClientProxy(t *testing.T) {
+	var proxied *url.URL
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		proxied = req.URL
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(WithProxy(proxy.URL))
+	require.NoError(t, err)
+	resp, err := client.Get("http://registry.internal/subjects")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "http://registry.internal/subjects", proxied.String())
+}
+
+func TestNewHTTPClientOptions(t *testing.T) {
+	_, err := NewHTTPClient(WithCACertificate([]byte("invalid")))
+	require.EqualError(t, err, "registry CA certificate option was given no valid PEM certificate")
+	_, err = NewHTTPClient(WithBasicAuth("", ""))
+	require.EqualError(t, err, "registry basic auth option was given an empty username")
+	_, err = NewHTTPClient(WithSigV4(nil, "eu-west-1", "glue"))
+	require.EqualError(t, err, "registry SigV4 credentials provider can not be nil")
+	_, err = NewHTTPClient(WithTimeout(0))
+	require.EqualError(t, err, "registry timeout must be positive, got 0s")
+}
@@ -0,0 +1,135 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+// WriterSchemaResolver is implemented by the wire formats which can resolve
+// the writer schema of the data they frame, usually from the reference to the
+// schema in their header. DecodeData then decodes the data with the Avro
+// schema resolution rules, from the writer schema to the reader schema of the
+// target value, so that the producers and the consumers can evolve their
+// schemas independently.
+type WriterSchemaResolver interface {
+	// WriterSchema returns the schema data was encoded with.
+	WriterSchema(ctx context.Context, data []byte) (avro.Schema, error)
+}
+
+// ConfluentSchemaLookup looks up the schemas of a Confluent Schema Registry by
+// ID, as registry.ConfluentClient does. When the Registry of a
+// ConfluentWireFormat implements it, the wire format resolves the writer
+// schemas of the decoded data.
+type ConfluentSchemaLookup interface {
+	SchemaByID(ctx context.Context, id int) (avro.Schema, error)
+}
+
+// GlueSchemaLookup looks up the schema versions of an AWS Glue Schema Registry
+// by UUID, as registry.GlueClient does. When the Registry of a GlueWireFormat
+// implements it, the wire format resolves the writer schemas of the decoded
+// data.
+type GlueSchemaLookup interface {
+	SchemaByVersionID(ctx context.Context, id [16]byte) (avro.Schema, error)
+}
+
+// WriterSchema implements WriterSchemaResolver: it looks up the schema of the
+// ID of the header of data, if the registry of w implements
+// ConfluentSchemaLookup. Otherwise, it returns nil.
+func (w *ConfluentWireFormat) WriterSchema(ctx context.Context, data []byte) (avro.Schema, error) {
+	lookup, ok := w.Registry.(ConfluentSchemaLookup)
+	if !ok {
+		return nil, nil
+	}
+	id, _, err := ParseConfluentHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := lookup.SchemaByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up writer schema %d: %w", id, err)
+	}
+	return schema, nil
+}
+
+// WriterSchema implements WriterSchemaResolver: it looks up the schema version
+// of the UUID of the header of data, if the registry of w implements
+// GlueSchemaLookup. Otherwise, it returns nil.
+func (w *GlueWireFormat) WriterSchema(ctx context.Context, data []byte) (avro.Schema, error) {
+	lookup, ok := w.Registry.(GlueSchemaLookup)
+	if !ok {
+		return nil, nil
+	}
+	if len(data) < glueHeaderSize || data[0] != glueHeaderVersion {
+		return nil, ErrNotGlueWireFormat
+	}
+	var id [16]byte
+	copy(id[:], data[2:glueHeaderSize])
+	schema, err := lookup.SchemaByVersionID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up writer schema version %x: %w", id, err)
+	}
+	return schema, nil
+}
+
+type writerSchemaKey struct{}
+
+// WithWriterSchema returns a context making DecodeData decode the data as
+// encoded with the writer schema, e.g. embedded in the metadata of a file or
+// referenced by the dataschema attribute of the event, into the reader schema
+// of the target value. It overrides the writer schema resolved by the wire
+// format.
+func WithWriterSchema(ctx context.Context, schema avro.Schema) context.Context {
+	return context.WithValue(ctx, writerSchemaKey{}, schema)
+}
+
+// writerSchemaFrom returns the writer schema of in, from ctx or from the wire
+// format w, or nil if unknown.
+func writerSchemaFrom(ctx context.Context, w WireFormat, in []byte) (avro.Schema, error) {
+	if schema, ok := ctx.Value(writerSchemaKey{}).(avro.Schema); ok && schema != nil {
+		return schema, nil
+	}
+	if r, ok := w.(WriterSchemaResolver); ok {
+		return r.WriterSchema(ctx, in)
+	}
+	return nil, nil
+}
+
+var (
+	schemaCompatibility = avro.NewSchemaCompatibility()
+	// resolvedSchemas caches the composite schemas of the pairs of reader and
+	// writer fingerprints.
+	resolvedSchemas sync.Map // map[[2][32]byte]avro.Schema
+)
+
+// resolveSchema returns the schema decoding the data encoded with writer into
+// the values of reader, following the Avro schema resolution rules: the fields
+// are matched by name, the fields missing from writer take their default, the
+// fields missing from reader are skipped and the numbers are promoted.
+func resolveSchema(reader, writer avro.Schema) (avro.Schema, error) {
+	key := [2][32]byte{reader.Fingerprint(), writer.Fingerprint()}
+	if key[0] == key[1] {
+		return reader, nil
+	}
+	if schema, ok := resolvedSchemas.Load(key); ok {
+		return schema.(avro.Schema), nil
+	}
+	schema, err := schemaCompatibility.Resolve(reader, writer)
+	if err != nil {
+		return nil, fmt.Errorf("writer schema is not compatible with reader schema: %w", err)
+	}
+	resolvedSchemas.Store(key, schema)
+	return schema, nil
+}
+
+var (
+	_ WriterSchemaResolver = (*ConfluentWireFormat)(nil)
+	_ WriterSchemaResolver = (*GlueWireFormat)(nil)
+)
@@ -0,0 +1,95 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+)
+
+// SchemaByID makes fakeRegistry a ConfluentSchemaLookup.
+func (r *fakeRegistry) SchemaByID(ctx context.Context, id int) (avro.Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	schema, ok := r.schemas[id]
+	if !ok {
+		return nil, fmt.Errorf("no schema %d", id)
+	}
+	return schema, nil
+}
+
+// testRecordV2 is the next version of TestRecord: its fields are reordered,
+// value is promoted to a long and the new field label has a default.
+type testRecordV2 struct {
+	Label string `avro:"label"`
+	Value int64  `avro:"value"`
+	Name  string `avro:"name"`
+}
+
+var testRecordV2Schema = avro.MustParse(`{
+	"type": "record",
+	"name": "TestRecord",
+	"namespace": "test",
+	"fields": [
+		{"name": "label", "type": "string", "default": "none"},
+		{"name": "value", "type": "long"},
+		{"name": "name", "type": "string"}
+	]
+}`)
+
+func (t *testRecordV2) AvroSchema() avro.Schema {
+	return testRecordV2Schema
+}
+
+func TestDecodeDataWithWriterSchema(t *testing.T) {
+	require := require.New(t)
+	plain, err := avrofmt.EncodeData(context.Background(), &schemaProviderRecord{TestRecord: TestRecord{Name: "test-name", Value: 42}})
+	require.NoError(err)
+
+	ctx := avrofmt.WithWriterSchema(context.Background(), testRecordSchema)
+	for i := 0; i < 2; i++ {
+		got := &testRecordV2{}
+		require.NoError(avrofmt.DecodeData(ctx, plain, got))
+		require.Equal(testRecordV2{Label: "none", Value: 42, Name: "test-name"}, *got)
+	}
+
+	// The same schema decodes as before.
+	got := &schemaProviderRecord{}
+	require.NoError(avrofmt.DecodeData(ctx, plain, got))
+	require.Equal(TestRecord{Name: "test-name", Value: 42}, got.TestRecord)
+
+	// A removed field without default is not compatible.
+	ctx = avrofmt.WithWriterSchema(context.Background(), avro.MustParse(`{
+		"type": "record",
+		"name": "TestRecord",
+		"namespace": "test",
+		"fields": [{"name": "name", "type": "string"}]
+	}`))
+	require.ErrorContains(avrofmt.DecodeData(ctx, plain, &testRecordV2{}), "not compatible")
+}
+
+func TestDecodeDataWithConfluentWriterSchema(t *testing.T) {
+	require := require.New(t)
+	registry := newFakeRegistry()
+	ctx := avrofmt.WithConfluentWireFormat(context.Background(), &avrofmt.ConfluentWireFormat{Registry: registry})
+
+	encoded, err := avrofmt.EncodeData(ctx, &schemaProviderRecord{TestRecord: TestRecord{Name: "test-name", Value: 42}})
+	require.NoError(err)
+
+	got := &testRecordV2{}
+	require.NoError(avrofmt.DecodeData(ctx, encoded, got))
+	require.Equal(testRecordV2{Label: "none", Value: 42, Name: "test-name"}, *got)
+
+	// An unknown ID fails.
+	encoded[4] = 1
+	require.ErrorContains(avrofmt.DecodeData(ctx, encoded, got), "no schema 1")
+}
@@ -7,6 +7,7 @@ package schema
 
 import (
 	_ "embed"
+	"encoding/json"
 
 	"github.com/hamba/avro/v2"
 )
@@ -17,12 +18,45 @@ var cloudEventSchemaJSON string
 // CloudEvent is the parsed Avro schema for CloudEvents.
 var CloudEvent avro.Schema
 
+// CloudEventBatch is the Avro schema of the batches of CloudEvents, an array
+// of CloudEvent records.
+var CloudEventBatch avro.Schema
+
+// CloudEventTimestampMicros is the CloudEvent schema whose attribute values
+// can also be timestamps, encoded as longs with the timestamp-micros logical
+// type. Appended to the union of the attribute values, the timestamps keep the
+// other values encoded as with CloudEvent, so it decodes both encodings.
+var CloudEventTimestampMicros avro.Schema
+
 func init() {
 	var err error
 	CloudEvent, err = avro.Parse(cloudEventSchemaJSON)
 	if err != nil {
 		panic("failed to parse CloudEvents Avro schema: " + err.Error())
 	}
+	CloudEventBatch = avro.NewArraySchema(CloudEvent)
+
+	CloudEventTimestampMicros, err = withTimestampAttributes(cloudEventSchemaJSON)
+	if err != nil {
+		panic("failed to parse CloudEvents Avro schema with timestamps: " + err.Error())
+	}
+}
+
+// withTimestampAttributes parses the CloudEvent schema s with the
+// timestamp-micros longs appended to the union of the attribute values.
+func withTimestampAttributes(s string) (avro.Schema, error) {
+	var def map[string]any
+	if err := json.Unmarshal([]byte(s), &def); err != nil {
+		return nil, err
+	}
+	attribute := def["fields"].([]any)[0].(map[string]any)["type"].(map[string]any)
+	attribute["values"] = append(attribute["values"].([]any), map[string]any{"type": "long", "logicalType": "timestamp-micros"})
+	b, err := json.Marshal(def)
+	if err != nil {
+		return nil, err
+	}
+	// Its record has the name of the one of CloudEvent, hence its own cache.
+	return avro.ParseWithCache(string(b), "", &avro.SchemaCache{})
 }
 
 // CloudEventRecord represents the Avro record structure for CloudEvents.
@@ -0,0 +1,76 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/pkg/crc64"
+)
+
+// singleObjectHeaderSize is the size of the header of the Avro single-object
+// encoding: the 2-byte marker and the 8-byte fingerprint of the schema.
+const singleObjectHeaderSize = 10
+
+// singleObjectMarker is the marker the Avro single-object encoding starts with.
+var singleObjectMarker = [2]byte{0xC3, 0x01}
+
+// ErrNotSingleObject is returned when decoding data which does not start with
+// the header of the Avro single-object encoding.
+var ErrNotSingleObject = errors.New("data is not in the Avro single-object encoding")
+
+// SchemaFingerprint returns the CRC-64-AVRO fingerprint of the parsing
+// canonical form of schema, as found in the Avro single-object encoding.
+func SchemaFingerprint(schema avro.Schema) uint64 {
+	h := crc64.New()
+	_, _ = h.Write([]byte(schema.String()))
+	return h.Sum64()
+}
+
+// AppendSingleObjectHeader appends the header of the Avro single-object
+// encoding of a value with the given schema to b: the C3 01 marker and the
+// little-endian fingerprint of the schema.
+func AppendSingleObjectHeader(b []byte, schema avro.Schema) []byte {
+	b = append(b, singleObjectMarker[:]...)
+	return binary.LittleEndian.AppendUint64(b, SchemaFingerprint(schema))
+}
+
+// ParseSingleObjectHeader splits data in the Avro single-object encoding into
+// the fingerprint of its writer schema and its Avro payload.
+func ParseSingleObjectHeader(data []byte) (uint64, []byte, error) {
+	if len(data) < singleObjectHeaderSize || data[0] != singleObjectMarker[0] || data[1] != singleObjectMarker[1] {
+		return 0, nil, ErrNotSingleObject
+	}
+	return binary.LittleEndian.Uint64(data[2:singleObjectHeaderSize]), data[singleObjectHeaderSize:], nil
+}
+
+// unframeSingleObject returns the payload of data, in the Avro single-object
+// encoding of a value with one of schemas, and this schema.
+func unframeSingleObject(data []byte, schemas ...avro.Schema) ([]byte, avro.Schema, error) {
+	fingerprint, payload, err := ParseSingleObjectHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, err := schemaForFingerprint(fingerprint, schemas...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return payload, schema, nil
+}
+
+// schemaForFingerprint returns the schema of schemas whose fingerprint is
+// fingerprint.
+func schemaForFingerprint(fingerprint uint64, schemas ...avro.Schema) (avro.Schema, error) {
+	for _, schema := range schemas {
+		if SchemaFingerprint(schema) == fingerprint {
+			return schema, nil
+		}
+	}
+	return nil, fmt.Errorf("writer schema fingerprint %016x does not match %016x", fingerprint, SchemaFingerprint(schemas[0]))
+}
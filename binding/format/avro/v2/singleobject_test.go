@@ -0,0 +1,74 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+	"github.com/cloudevents/sdk-go/binding/format/avro/v2/schema"
+)
+
+func TestSchemaFingerprint(t *testing.T) {
+	want, err := schema.CloudEvent.FingerprintUsing(avro.CRC64Avro)
+	require.NoError(t, err)
+	require.Equal(t, binary.BigEndian.Uint64(want), avrofmt.SchemaFingerprint(schema.CloudEvent))
+}
+
+func TestAvroFormatSingleObjectEncoding(t *testing.T) {
+	require := require.New(t)
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("/source")
+	e.SetType("test.type")
+	require.NoError(e.SetData(event.ApplicationJSON, map[string]string{"key": "value"}))
+
+	f := avrofmt.NewFormat(avrofmt.WithSingleObjectEncoding())
+	require.Equal(avrofmt.ApplicationCloudEventsAvro, f.MediaType())
+	b, err := f.Marshal(&e)
+	require.NoError(err)
+	require.Equal([]byte{0xC3, 0x01}, b[:2])
+
+	fingerprint, payload, err := avrofmt.ParseSingleObjectHeader(b)
+	require.NoError(err)
+	require.Equal(avrofmt.SchemaFingerprint(schema.CloudEvent), fingerprint)
+	// The payload is the plain encoding, whose attribute map is not ordered.
+	var decoded event.Event
+	require.NoError(avrofmt.Avro.Unmarshal(payload, &decoded))
+	require.Equal(e.ID(), decoded.ID())
+	plain, err := avrofmt.Avro.Marshal(&e)
+	require.NoError(err)
+
+	var got event.Event
+	require.NoError(f.Unmarshal(b, &got))
+	require.Equal(e.ID(), got.ID())
+	require.Equal(e.Data(), got.Data())
+
+	// The plain encoding is rejected.
+	require.ErrorIs(f.Unmarshal(plain, &got), avrofmt.ErrNotSingleObject)
+
+	// So is a writer schema with another fingerprint.
+	other := append(avrofmt.AppendSingleObjectHeader(nil, avro.MustParse(`"string"`)), payload...)
+	require.ErrorContains(f.Unmarshal(other, &got), "fingerprint")
+}
+
+func TestParseSingleObjectHeader(t *testing.T) {
+	_, _, err := avrofmt.ParseSingleObjectHeader([]byte{0xC3, 0x01, 0})
+	require.ErrorIs(t, err, avrofmt.ErrNotSingleObject)
+	_, _, err = avrofmt.ParseSingleObjectHeader([]byte{0xC3, 0x02, 0, 0, 0, 0, 0, 0, 0, 0})
+	require.ErrorIs(t, err, avrofmt.ErrNotSingleObject)
+
+	fingerprint, payload, err := avrofmt.ParseSingleObjectHeader([]byte{0xC3, 0x01, 1, 0, 0, 0, 0, 0, 0, 0, 42})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), fingerprint)
+	require.Equal(t, []byte{42}, payload)
+}
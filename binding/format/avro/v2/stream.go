@@ -0,0 +1,98 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudevents/sdk-go/binding/format/avro/v2/schema"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// StreamFormat is a format.Format marshaling the events to a writer, and
+// unmarshaling them from a reader, without buffering them whole twice, e.g.
+// for the events with multi-megabyte payloads. The Avro format and the
+// formats returned by NewFormat implement it.
+type StreamFormat interface {
+	format.Format
+	// MarshalTo writes e to w as Marshal encodes it.
+	MarshalTo(w io.Writer, e *event.Event) error
+	// UnmarshalFrom reads an event encoded by Marshal from r into e.
+	UnmarshalFrom(r io.Reader, e *event.Event) error
+}
+
+// MarshalTo writes e to w as Marshal encodes it, with the streaming encoder
+// of hamba/avro, so that the data of e is copied once, to the buffer of the
+// encoder written to w.
+func (f avroFmt) MarshalTo(w io.Writer, e *event.Event) error {
+	s, record, err := f.toRecord(e)
+	if err != nil {
+		return err
+	}
+	if f.singleObject {
+		if _, err := w.Write(AppendSingleObjectHeader(make([]byte, 0, singleObjectHeaderSize), s)); err != nil {
+			return err
+		}
+	}
+	return f.avroAPI().NewEncoder(s, w).Encode(record)
+}
+
+// UnmarshalFrom reads an event encoded by Marshal from r into e, with the
+// streaming decoder of hamba/avro, so that the data of the event is read
+// from r into e without reading the whole event first. The decoder buffers
+// r, which must hold the event only as it may be read past its end, and
+// rejects the data larger than the MaxByteSliceSize of the API of the
+// format, 1 MiB by default, see WithFormatAPI. UnmarshalFrom returns io.EOF if r is
+// empty, and io.ErrUnexpectedEOF if the event is truncated.
+func (f avroFmt) UnmarshalFrom(r io.Reader, e *event.Event) error {
+	s := schema.CloudEventTimestampMicros
+	if f.singleObject {
+		header := make([]byte, singleObjectHeaderSize)
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return ErrNotSingleObject
+			}
+			return err
+		}
+		fingerprint, _, err := ParseSingleObjectHeader(header)
+		if err != nil {
+			return err
+		}
+		if s, err = schemaForFingerprint(fingerprint, f.writerSchemas()...); err != nil {
+			return err
+		}
+	}
+	cr := &countingReader{r: r}
+	record := &schema.CloudEventRecord{}
+	if err := f.avroAPI().NewDecoder(s, cr).Decode(record); err != nil {
+		if !f.singleObject && cr.n == 0 {
+			return io.EOF
+		}
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("truncated event: %w", io.ErrUnexpectedEOF)
+		}
+		return err
+	}
+	return f.fromRecord(record, e)
+}
+
+// countingReader counts the bytes read, so that UnmarshalFrom tells an empty
+// reader from a truncated event.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
+
+var _ StreamFormat = avroFmt{}
@@ -0,0 +1,136 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+	"github.com/cloudevents/sdk-go/binding/format/avro/v2/schema"
+)
+
+func TestAvroFormatStream(t *testing.T) {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("/source")
+	e.SetType("test.type")
+	e.SetTime(time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC))
+	e.SetExtension("count", 3)
+	// Under the 1 MiB of the default hamba/avro configuration.
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<15)
+	require.NoError(t, e.SetData("application/octet-stream", data))
+
+	for name, f := range map[string]avrofmt.StreamFormat{
+		"default":        avrofmt.Avro,
+		"single object":  avrofmt.NewFormat(avrofmt.WithSingleObjectEncoding()).(avrofmt.StreamFormat),
+		"timestamps":     avrofmt.NewFormat(avrofmt.WithTimestampMicros(), avrofmt.WithSingleObjectEncoding()).(avrofmt.StreamFormat),
+		"strict":         avrofmt.NewFormat(avrofmt.WithStrictValidation()).(avrofmt.StreamFormat),
+		"strict objects": avrofmt.NewFormat(avrofmt.WithStrictValidation(), avrofmt.WithSingleObjectEncoding()).(avrofmt.StreamFormat),
+	} {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			var buf bytes.Buffer
+			require.NoError(f.MarshalTo(&buf, &e))
+			b, err := f.Marshal(&e)
+			require.NoError(err)
+			require.Equal(len(b), buf.Len())
+
+			var got event.Event
+			require.NoError(f.UnmarshalFrom(&buf, &got))
+			require.Equal(e.ID(), got.ID())
+			require.Equal(e.Time(), got.Time())
+			require.Equal(int32(3), got.Extensions()["count"])
+			require.Equal(data, got.Data())
+
+			// The encodings are the same.
+			got = event.Event{}
+			require.NoError(f.UnmarshalFrom(bytes.NewReader(b), &got))
+			require.Equal(data, got.Data())
+			buf.Reset()
+			require.NoError(f.MarshalTo(&buf, &e))
+			got = event.Event{}
+			require.NoError(f.Unmarshal(buf.Bytes(), &got))
+			require.Equal(data, got.Data())
+
+			require.ErrorIs(f.UnmarshalFrom(bytes.NewReader(nil), &got), io.EOF)
+		})
+	}
+}
+
+func TestAvroFormatStreamErrors(t *testing.T) {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("/source")
+	e.SetType("test.type")
+
+	plain, err := avrofmt.Avro.Marshal(&e)
+	require.NoError(t, err)
+	var got event.Event
+
+	f := avrofmt.NewFormat(avrofmt.WithSingleObjectEncoding()).(avrofmt.StreamFormat)
+	require.ErrorIs(t, f.UnmarshalFrom(bytes.NewReader(plain), &got), avrofmt.ErrNotSingleObject)
+	require.ErrorIs(t, f.UnmarshalFrom(bytes.NewReader([]byte{0xC3, 0x01}), &got), avrofmt.ErrNotSingleObject)
+
+	// A truncated event is not reported as the end of the stream.
+	require.ErrorIs(t, avrofmt.Avro.UnmarshalFrom(bytes.NewReader(plain[:len(plain)-1]), &got), io.ErrUnexpectedEOF)
+	framed, err := f.Marshal(&e)
+	require.NoError(t, err)
+	require.ErrorIs(t, f.UnmarshalFrom(bytes.NewReader(framed[:len(framed)-1]), &got), io.ErrUnexpectedEOF)
+
+	// The writer errors are returned.
+	require.Error(t, f.MarshalTo(failingWriter{}, &e))
+	require.Error(t, avrofmt.Avro.MarshalTo(failingWriter{}, &e))
+
+	strict := avrofmt.NewFormat(avrofmt.WithStrictValidation()).(avrofmt.StreamFormat)
+	record, err := avrofmt.ToAvro(&e)
+	require.NoError(t, err)
+	delete(record.Attribute, "id")
+	b, err := avro.Marshal(schema.CloudEvent, record)
+	require.NoError(t, err)
+	require.ErrorIs(t, strict.UnmarshalFrom(bytes.NewReader(b), &got), avrofmt.ErrMissingAttribute)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestAvroFormatAPI(t *testing.T) {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("/source")
+	e.SetType("test.type")
+	data := bytes.Repeat([]byte{42}, 2<<20)
+	require.NoError(t, e.SetData("application/octet-stream", data))
+
+	b, err := avrofmt.Avro.Marshal(&e)
+	require.NoError(t, err)
+	var got event.Event
+	require.ErrorContains(t, avrofmt.Avro.Unmarshal(b, &got), "MaxByteSliceSize")
+	require.ErrorContains(t, avrofmt.Avro.UnmarshalFrom(bytes.NewReader(b), &got), "MaxByteSliceSize")
+
+	api := avro.Config{MaxByteSliceSize: 4 << 20}.Freeze()
+	f := avrofmt.NewFormat(avrofmt.WithFormatAPI(api), avrofmt.WithSingleObjectEncoding()).(avrofmt.StreamFormat)
+	b, err = f.Marshal(&e)
+	require.NoError(t, err)
+	require.NoError(t, f.Unmarshal(b, &got))
+	require.Equal(t, data, got.Data())
+
+	var buf bytes.Buffer
+	require.NoError(t, f.MarshalTo(&buf, &e))
+	got = event.Event{}
+	require.NoError(t, f.UnmarshalFrom(&buf, &got))
+	require.Equal(t, data, got.Data())
+}
@@ -0,0 +1,88 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"errors"
+	"fmt"
+	stdtime "time"
+
+	"github.com/cloudevents/sdk-go/binding/format/avro/v2/schema"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+var (
+	// ErrMissingAttribute is wrapped by the AttributeError of a required
+	// attribute missing, or empty, in a strictly converted record.
+	ErrMissingAttribute = errors.New("missing required attribute")
+	// ErrInvalidAttributeType is wrapped by the AttributeError of an
+	// attribute of a strictly converted record whose value is not of the
+	// type of the attribute.
+	ErrInvalidAttributeType = errors.New("invalid attribute type")
+)
+
+// AttributeError is the error of an attribute of a record rejected by
+// FromAvroStrict.
+type AttributeError struct {
+	// Name is the name of the attribute.
+	Name string
+	Err  error
+}
+
+func (e *AttributeError) Error() string {
+	return fmt.Sprintf("attribute %s: %v", e.Name, e.Err)
+}
+
+func (e *AttributeError) Unwrap() error {
+	return e.Err
+}
+
+// WithStrictValidation makes the format unmarshal the events with
+// FromAvroStrict, rejecting the malformed events instead of failing later.
+func WithStrictValidation() FormatOption {
+	return func(f *avroFmt) {
+		f.strict = true
+	}
+}
+
+// FromAvroStrict converts an Avro record into the generic SDK event as
+// FromAvro does, but rejects the records whose required attributes are
+// missing or empty, whose context attributes are not strings, except the
+// timestamps of WithTimestampMicros, or whose event is not valid, see
+// event.Event.Validate. The errors of the attributes are AttributeError
+// wrapping ErrMissingAttribute or ErrInvalidAttributeType, joined.
+func FromAvroStrict(record *schema.CloudEventRecord) (*event.Event, error) {
+	var errs []error
+	for _, name := range []string{specversion, id, source, typ} {
+		v, ok := record.Attribute[name]
+		if !ok || v == nil || v == "" {
+			errs = append(errs, &AttributeError{Name: name, Err: ErrMissingAttribute})
+		}
+	}
+	for _, name := range []string{specversion, id, source, typ, datacontenttype, dataschema, subject, time} {
+		if v, ok := record.Attribute[name]; ok && v != nil {
+			if _, ok := v.(stdtime.Time); ok && name == time {
+				// See WithTimestampMicros
+				continue
+			}
+			if _, ok := v.(string); !ok {
+				errs = append(errs, &AttributeError{Name: name, Err: fmt.Errorf("%w: %T", ErrInvalidAttributeType, v)})
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	e, err := FromAvro(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,107 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro_test
+
+import (
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+
+	avrofmt "github.com/cloudevents/sdk-go/binding/format/avro/v2"
+	"github.com/cloudevents/sdk-go/binding/format/avro/v2/schema"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestFromAvroStrict(t *testing.T) {
+	valid := func() *schema.CloudEventRecord {
+		return &schema.CloudEventRecord{Attribute: map[string]any{
+			"specversion": "1.0",
+			"id":          "1",
+			"source":      "/source",
+			"type":        "com.example.test",
+		}}
+	}
+
+	e, err := avrofmt.FromAvroStrict(valid())
+	require.NoError(t, err)
+	require.Equal(t, "1", e.ID())
+
+	testCases := map[string]struct {
+		update    func(r *schema.CloudEventRecord)
+		attribute string
+		err       error
+	}{
+		"missing id": {
+			update:    func(r *schema.CloudEventRecord) { delete(r.Attribute, "id") },
+			attribute: "id",
+			err:       avrofmt.ErrMissingAttribute,
+		},
+		"empty source": {
+			update:    func(r *schema.CloudEventRecord) { r.Attribute["source"] = "" },
+			attribute: "source",
+			err:       avrofmt.ErrMissingAttribute,
+		},
+		"null type": {
+			update:    func(r *schema.CloudEventRecord) { r.Attribute["type"] = nil },
+			attribute: "type",
+			err:       avrofmt.ErrMissingAttribute,
+		},
+		"int id": {
+			update:    func(r *schema.CloudEventRecord) { r.Attribute["id"] = int32(1) },
+			attribute: "id",
+			err:       avrofmt.ErrInvalidAttributeType,
+		},
+		"bytes time": {
+			update:    func(r *schema.CloudEventRecord) { r.Attribute["time"] = []byte("2024-01-02T03:04:05Z") },
+			attribute: "time",
+			err:       avrofmt.ErrInvalidAttributeType,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := valid()
+			tc.update(r)
+			_, err := avrofmt.FromAvroStrict(r)
+			require.ErrorIs(t, err, tc.err)
+			var attrErr *avrofmt.AttributeError
+			require.ErrorAs(t, err, &attrErr)
+			require.Equal(t, tc.attribute, attrErr.Name)
+
+			// FromAvro is lenient
+			_, err = avrofmt.FromAvro(r)
+			require.NoError(t, err)
+		})
+	}
+
+	// The event is validated too
+	r := valid()
+	r.Attribute["specversion"] = "0.1"
+	_, err = avrofmt.FromAvroStrict(r)
+	require.Error(t, err)
+}
+
+func TestStrictFormat(t *testing.T) {
+	f := avrofmt.NewFormat(avrofmt.WithStrictValidation())
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("com.example.test")
+	b, err := f.Marshal(&e)
+	require.NoError(t, err)
+	var got event.Event
+	require.NoError(t, f.Unmarshal(b, &got))
+	require.Equal(t, "1", got.ID())
+
+	b, err = avro.Marshal(schema.CloudEvent, &schema.CloudEventRecord{Attribute: map[string]any{
+		"specversion": "1.0",
+		"source":      "/source",
+		"type":        "com.example.test",
+	}})
+	require.NoError(t, err)
+	require.ErrorIs(t, f.Unmarshal(b, &got), avrofmt.ErrMissingAttribute)
+	require.NoError(t, avrofmt.Avro.Unmarshal(b, &got))
+}
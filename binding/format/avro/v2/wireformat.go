@@ -0,0 +1,65 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package avro
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+// WireFormat frames the Avro binary encoding of the values with the metadata
+// expected by the consumers, usually a reference to the schema of the value in
+// a schema registry, e.g. ConfluentWireFormat. EncodeData and DecodeData use
+// the wire format of their context, see WithWireFormat, or the default one,
+// see SetWireFormat.
+type WireFormat interface {
+	// Frame returns the data carrying payload, the encoding of a value with
+	// schema.
+	Frame(ctx context.Context, schema avro.Schema, payload []byte) ([]byte, error)
+	// Unframe returns the payload carried by data.
+	Unframe(ctx context.Context, data []byte) ([]byte, error)
+}
+
+var (
+	defaultWireFormatMu sync.RWMutex
+	defaultWireFormat   WireFormat
+)
+
+// SetWireFormat sets the wire format used by EncodeData and DecodeData when
+// the context has none, e.g. for event.SetData and event.DataAs. Nil restores
+// the plain Avro binary encoding.
+func SetWireFormat(w WireFormat) {
+	defaultWireFormatMu.Lock()
+	defer defaultWireFormatMu.Unlock()
+	defaultWireFormat = w
+}
+
+type wireFormatKey struct{}
+
+// WithWireFormat returns a context making EncodeData and DecodeData use the
+// wire format w, overriding the one set with SetWireFormat. A nil w uses the
+// plain Avro binary encoding.
+func WithWireFormat(ctx context.Context, w WireFormat) context.Context {
+	return context.WithValue(ctx, wireFormatKey{}, wireFormatValue{w})
+}
+
+// wireFormatValue wraps the wire format of a context, so that a nil wire
+// format can be told apart from no wire format.
+type wireFormatValue struct {
+	w WireFormat
+}
+
+// wireFormatFrom returns the wire format of ctx, or the default one.
+func wireFormatFrom(ctx context.Context) WireFormat {
+	if v, ok := ctx.Value(wireFormatKey{}).(wireFormatValue); ok {
+		return v.w
+	}
+	defaultWireFormatMu.RLock()
+	defer defaultWireFormatMu.RUnlock()
+	return defaultWireFormat
+}
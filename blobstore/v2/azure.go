@@ -0,0 +1,219 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+const (
+	// DefaultAzureBlockSize is the default size of the blocks of the block
+	// uploads, and the size above which the block upload is used.
+	DefaultAzureBlockSize = 16 << 20
+	// maxAzureBlockSize is the maximal size of a block.
+	maxAzureBlockSize = 4000 << 20
+	// azureVersion is the version of the Blob service REST API.
+	azureVersion = "2021-08-06"
+)
+
+// AzureConfig configures an AzureStore.
+type AzureConfig struct {
+	// ContainerURL is the URL of the container the data is stored in, e.g.
+	// https://<account>.blob.core.windows.net/<container>. Required.
+	ContainerURL string
+	// Prefix is prepended to the names of the blobs.
+	Prefix string
+	// Token authenticates the requests with Microsoft Entra ID access tokens
+	// for https://storage.azure.com/.
+	Token TokenSource
+	// SAS is a shared access signature authenticating the requests, without
+	// its leading "?". Either Token or SAS is required.
+	SAS string
+	// EncryptionScope is the encryption scope of the blobs. Empty uses the
+	// default encryption of the container.
+	EncryptionScope string
+	// BlockSize is the size of the blocks of the block uploads, used for the
+	// data larger than BlockSize. Defaults to DefaultAzureBlockSize.
+	BlockSize int
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// AzureStore is a middleware.ClaimCheckStore storing the data in an Azure
+// Blob Storage container. Its datarefs are the URLs of the blobs, without
+// shared access signature.
+type AzureStore struct {
+	config    AzureConfig
+	container *url.URL
+	client    *http.Client
+}
+
+// NewAzureStore returns an AzureStore configured by config.
+func NewAzureStore(config AzureConfig) (*AzureStore, error) {
+	container, err := url.Parse(strings.TrimSuffix(config.ContainerURL, "/"))
+	if err != nil || container.Host == "" || strings.Trim(container.Path, "/") == "" {
+		return nil, fmt.Errorf("invalid azure container URL %q", config.ContainerURL)
+	}
+	container.RawQuery = ""
+	if (config.Token == nil) == (config.SAS == "") {
+		return nil, errors.New("azure store requires either a token source or a shared access signature")
+	}
+	if _, err := url.ParseQuery(config.SAS); err != nil {
+		return nil, fmt.Errorf("invalid azure shared access signature: %w", err)
+	}
+	if config.BlockSize == 0 {
+		config.BlockSize = DefaultAzureBlockSize
+	}
+	if config.BlockSize < 0 || config.BlockSize > maxAzureBlockSize {
+		return nil, fmt.Errorf("azure block size must be positive and at most %d, got %d", maxAzureBlockSize, config.BlockSize)
+	}
+	return &AzureStore{config: config, container: container, client: httpClient(config.Client)}, nil
+}
+
+// Put implements middleware.ClaimCheckStore.Put. The data larger than the
+// block size is uploaded in blocks, committed once all are uploaded.
+func (s *AzureStore) Put(ctx context.Context, key string, data []byte) (string, error) {
+	blob := s.container.JoinPath(s.config.Prefix + key)
+	if len(data) > s.config.BlockSize {
+		if err := s.putBlocks(ctx, blob, data); err != nil {
+			return "", err
+		}
+	} else {
+		req, err := s.request(ctx, http.MethodPut, blob, nil, data)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+		s.encryption(req.Header)
+		if _, err := discard(s.client, req); err != nil {
+			return "", err
+		}
+	}
+	return blob.String(), nil
+}
+
+// Get implements middleware.ClaimCheckStore.Get.
+func (s *AzureStore) Get(ctx context.Context, dataref string) ([]byte, error) {
+	blob, err := s.blob(dataref)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.request(ctx, http.MethodGet, blob, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return readAll(s.client, req)
+}
+
+// Delete removes the data referenced by dataref.
+func (s *AzureStore) Delete(ctx context.Context, dataref string) error {
+	blob, err := s.blob(dataref)
+	if err != nil {
+		return err
+	}
+	req, err := s.request(ctx, http.MethodDelete, blob, nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = discard(s.client, req)
+	return err
+}
+
+// putBlocks uploads data in blocks and commits them, see
+// https://learn.microsoft.com/rest/api/storageservices/put-block-list
+func (s *AzureStore) putBlocks(ctx context.Context, blob *url.URL, data []byte) error {
+	var list struct {
+		XMLName xml.Name `xml:"BlockList"`
+		Latest  []string `xml:"Latest"`
+	}
+	for offset := 0; offset < len(data); offset += s.config.BlockSize {
+		// The IDs of the blocks of a blob must have the same length.
+		id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%08d", len(list.Latest))))
+		query := url.Values{"comp": {"block"}, "blockid": {id}}
+		req, err := s.request(ctx, http.MethodPut, blob, query, data[offset:min(offset+s.config.BlockSize, len(data))])
+		if err != nil {
+			return err
+		}
+		s.encryption(req.Header)
+		if _, err := discard(s.client, req); err != nil {
+			return fmt.Errorf("failed to upload azure block %d: %w", len(list.Latest), err)
+		}
+		list.Latest = append(list.Latest, id)
+	}
+
+	body, err := xml.Marshal(list)
+	if err != nil {
+		return err
+	}
+	req, err := s.request(ctx, http.MethodPut, blob, url.Values{"comp": {"blocklist"}}, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	s.encryption(req.Header)
+	if _, err := discard(s.client, req); err != nil {
+		return fmt.Errorf("failed to commit azure block list: %w", err)
+	}
+	return nil
+}
+
+// encryption sets the encryption scope header of an upload.
+func (s *AzureStore) encryption(header http.Header) {
+	if s.config.EncryptionScope != "" {
+		header.Set("X-Ms-Encryption-Scope", s.config.EncryptionScope)
+	}
+}
+
+// request returns an authenticated request for blob.
+func (s *AzureStore) request(ctx context.Context, method string, blob *url.URL, query url.Values, body []byte) (*http.Request, error) {
+	u := *blob
+	rawQuery := query.Encode()
+	if s.config.SAS != "" {
+		if rawQuery != "" {
+			rawQuery += "&"
+		}
+		rawQuery += s.config.SAS
+	}
+	u.RawQuery = rawQuery
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("X-Ms-Version", azureVersion)
+	if err := bearer(req, s.config.Token); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// blob returns the URL of the blob referenced by dataref.
+func (s *AzureStore) blob(dataref string) (*url.URL, error) {
+	u, err := url.Parse(dataref)
+	prefix := strings.TrimSuffix(s.container.Path, "/") + "/"
+	if err != nil || u.Scheme != s.container.Scheme || u.Host != s.container.Host || !strings.HasPrefix(u.Path, prefix) {
+		return nil, middleware.ErrForeignDataRef
+	}
+	if u.Path == prefix {
+		return nil, fmt.Errorf("dataref %q has no blob name", dataref)
+	}
+	u.RawQuery = ""
+	return u, nil
+}
+
+var _ middleware.ClaimCheckStore = (*AzureStore)(nil)
@@ -0,0 +1,143 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+// azureServer is a minimal Blob service API storing the blobs of the
+// container "claims".
+type azureServer struct {
+	mu      sync.Mutex
+	blobs   map[string][]byte
+	headers map[string]http.Header
+	blocks  map[string][]byte
+}
+
+func (s *azureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query := r.URL.Query()
+	if query.Get("sig") != "secret" || r.Header.Get("X-Ms-Version") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	name, ok := strings.CutPrefix(r.URL.Path, "/claims/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodPut && query.Get("comp") == "block":
+		s.blocks[query.Get("blockid")] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && query.Get("comp") == "blocklist":
+		var list struct {
+			Latest []string `xml:"Latest"`
+		}
+		_ = xml.Unmarshal(body, &list)
+		var blob []byte
+		for _, id := range list.Latest {
+			blob = append(blob, s.blocks[id]...)
+		}
+		s.blobs[name] = blob
+		s.headers[name] = r.Header.Clone()
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		if r.Header.Get("X-Ms-Blob-Type") != "BlockBlob" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.blobs[name] = body
+		s.headers[name] = r.Header.Clone()
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet:
+		data, ok := s.blobs[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(s.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func newTestAzureStore(t *testing.T, config AzureConfig) (*AzureStore, *azureServer, string) {
+	s := &azureServer{blobs: map[string][]byte{}, headers: map[string]http.Header{}, blocks: map[string][]byte{}}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	config.ContainerURL = server.URL + "/claims"
+	config.SAS = "sv=2021-08-06&sig=secret"
+	store, err := NewAzureStore(config)
+	require.NoError(t, err)
+	return store, s, server.URL
+}
+
+func TestAzureStore(t *testing.T) {
+	store, server, serverURL := newTestAzureStore(t, AzureConfig{Prefix: "orders/", EncryptionScope: "claims-scope"})
+	ctx := context.Background()
+
+	dataref, err := store.Put(ctx, "2024/05/01/abc", []byte("payload"))
+	require.NoError(t, err)
+	require.Equal(t, serverURL+"/claims/orders/2024/05/01/abc", dataref)
+	require.Equal(t, "claims-scope", server.headers["orders/2024/05/01/abc"].Get("X-Ms-Encryption-Scope"))
+
+	data, err := store.Get(ctx, dataref)
+	require.NoError(t, err)
+	require.Equal(t, "payload", string(data))
+
+	for _, foreign := range []string{serverURL + "/other/abc", "https://example.com/claims/abc"} {
+		_, err = store.Get(ctx, foreign)
+		require.ErrorIs(t, err, middleware.ErrForeignDataRef, foreign)
+	}
+
+	require.NoError(t, store.Delete(ctx, dataref))
+	_, err = store.Get(ctx, dataref)
+	require.Error(t, err)
+}
+
+func TestAzureStoreBlocks(t *testing.T) {
+	store, server, _ := newTestAzureStore(t, AzureConfig{BlockSize: 1024})
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789"), 250)
+	dataref, err := store.Put(ctx, "big", data)
+	require.NoError(t, err)
+	require.Len(t, server.blocks, 3)
+
+	got, err := store.Get(ctx, dataref)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+}
+
+func TestNewAzureStoreInvalid(t *testing.T) {
+	token := func(context.Context) (string, error) { return "token", nil }
+	for name, config := range map[string]AzureConfig{
+		"no container": {ContainerURL: "https://account.blob.core.windows.net", SAS: "sig=secret"},
+		"no auth":      {ContainerURL: "https://account.blob.core.windows.net/claims"},
+		"both auths":   {ContainerURL: "https://account.blob.core.windows.net/claims", SAS: "sig=secret", Token: token},
+		"block size":   {ContainerURL: "https://account.blob.core.windows.net/claims", Token: token, BlockSize: -1},
+	} {
+		_, err := NewAzureStore(config)
+		require.Error(t, err, name)
+	}
+}
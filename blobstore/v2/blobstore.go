@@ -0,0 +1,94 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TokenSource returns the bearer token authenticating a request. It is
+// invoked for each request, so that tokens can be refreshed, e.g. by an
+// OAuth2 token source.
+type TokenSource func(ctx context.Context) (string, error)
+
+// StatusError is the error returned when a store answers a request with an
+// unexpected status.
+type StatusError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Body is the beginning of the body of the response, which usually
+	// describes the error.
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("blob store responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+// maxErrorBody is the size of the beginning of the body of an error response
+// kept in a StatusError.
+const maxErrorBody = 1024
+
+// do sends req with client and returns the response if its status is
+// successful. Otherwise, the response is closed and a *StatusError is
+// returned.
+func do(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return resp, nil
+}
+
+// readAll sends req with client and returns the body of the response.
+func readAll(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := do(client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// discard sends req with client and discards the body of the response.
+func discard(client *http.Client, req *http.Request) (http.Header, error) {
+	resp, err := do(client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Header, nil
+}
+
+// bearer sets the authorization header of req to the token of source, if any.
+func bearer(req *http.Request, source TokenSource) error {
+	if source == nil {
+		return nil
+	}
+	token, err := source(req.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get blob store token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// httpClient returns client, or the default client if nil.
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+	return client
+}
@@ -0,0 +1,25 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package blobstore provides middleware.ClaimCheckStore implementations backed by
+the object stores of the major clouds: Amazon S3 (and S3 compatible stores),
+Google Cloud Storage and Azure Blob Storage. They talk to the REST APIs of the
+stores directly, so that no cloud SDK is required.
+
+The data is stored under middleware.ClaimCheckKey, prefixed by the configured
+prefix, so that lifecycle rules can expire the offloaded data by date:
+
+	store, err := blobstore.NewS3Store(blobstore.S3Config{
+		Bucket:      "claim-checks",
+		Region:      "eu-west-1",
+		Prefix:      "orders/",
+		Credentials: blobstore.StaticCredentials(id, secret, ""),
+		SSE:         blobstore.SSEKMS,
+	})
+	...
+	offloaded, err := middleware.OffloadData(ctx, store, &e, 256*1024)
+*/
+package blobstore
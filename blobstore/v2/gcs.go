@@ -0,0 +1,197 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+const (
+	// DefaultGCSChunkSize is the default size of the chunks of the resumable
+	// uploads, and the size above which the resumable upload is used.
+	DefaultGCSChunkSize = 16 << 20
+	// gcsChunkUnit is the unit of the size of the chunks but the last one.
+	gcsChunkUnit = 256 << 10
+	// gcsEndpoint is the endpoint of Google Cloud Storage.
+	gcsEndpoint = "https://storage.googleapis.com"
+)
+
+// GCSConfig configures a GCSStore.
+type GCSConfig struct {
+	// Bucket is the bucket the data is stored in. Required.
+	Bucket string
+	// Endpoint is the URL of the JSON API, e.g. of an emulator. Defaults to
+	// https://storage.googleapis.com.
+	Endpoint string
+	// Prefix is prepended to the names of the objects.
+	Prefix string
+	// Token authenticates the requests, e.g. with the access tokens of a
+	// service account. Nil sends unauthenticated requests.
+	Token TokenSource
+	// KMSKeyName is the Cloud KMS key encrypting the objects. Empty uses the
+	// default encryption of the bucket.
+	KMSKeyName string
+	// ChunkSize is the size of the chunks of the resumable uploads, used for
+	// the data larger than ChunkSize. Defaults to DefaultGCSChunkSize, and
+	// must be a multiple of 256 KiB.
+	ChunkSize int
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// GCSStore is a middleware.ClaimCheckStore storing the data in a Google Cloud
+// Storage bucket. Its datarefs are gs://<bucket>/<name>.
+type GCSStore struct {
+	config   GCSConfig
+	endpoint string
+	client   *http.Client
+}
+
+// NewGCSStore returns a GCSStore configured by config.
+func NewGCSStore(config GCSConfig) (*GCSStore, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("gcs store requires a bucket")
+	}
+	if config.ChunkSize == 0 {
+		config.ChunkSize = DefaultGCSChunkSize
+	}
+	if config.ChunkSize < 0 || config.ChunkSize%gcsChunkUnit != 0 {
+		return nil, fmt.Errorf("gcs chunk size must be a positive multiple of %d, got %d", gcsChunkUnit, config.ChunkSize)
+	}
+	endpoint := gcsEndpoint
+	if config.Endpoint != "" {
+		u, err := url.Parse(config.Endpoint)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid gcs endpoint %q", config.Endpoint)
+		}
+		endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	}
+	return &GCSStore{config: config, endpoint: endpoint, client: httpClient(config.Client)}, nil
+}
+
+// Put implements middleware.ClaimCheckStore.Put. The data larger than the
+// chunk size is uploaded with a resumable upload.
+func (s *GCSStore) Put(ctx context.Context, key string, data []byte) (string, error) {
+	name := s.config.Prefix + key
+	query := url.Values{"name": {name}, "uploadType": {"media"}}
+	if s.config.KMSKeyName != "" {
+		query.Set("kmsKeyName", s.config.KMSKeyName)
+	}
+	uploadURL := s.endpoint + "/upload/storage/v1/b/" + url.PathEscape(s.config.Bucket) + "/o?"
+	if len(data) > s.config.ChunkSize {
+		query.Set("uploadType", "resumable")
+		if err := s.putResumable(ctx, uploadURL+query.Encode(), data); err != nil {
+			return "", err
+		}
+	} else {
+		req, err := s.request(ctx, http.MethodPost, uploadURL+query.Encode(), data)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		if _, err := discard(s.client, req); err != nil {
+			return "", err
+		}
+	}
+	u := url.URL{Scheme: "gs", Host: s.config.Bucket, Path: "/" + name}
+	return u.String(), nil
+}
+
+// Get implements middleware.ClaimCheckStore.Get.
+func (s *GCSStore) Get(ctx context.Context, dataref string) ([]byte, error) {
+	req, err := s.objectRequest(ctx, http.MethodGet, dataref)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = "alt=media"
+	return readAll(s.client, req)
+}
+
+// Delete removes the data referenced by dataref.
+func (s *GCSStore) Delete(ctx context.Context, dataref string) error {
+	req, err := s.objectRequest(ctx, http.MethodDelete, dataref)
+	if err != nil {
+		return err
+	}
+	_, err = discard(s.client, req)
+	return err
+}
+
+// putResumable uploads data in chunks to a new resumable upload session, see
+// https://cloud.google.com/storage/docs/performing-resumable-uploads
+func (s *GCSStore) putResumable(ctx context.Context, uploadURL string, data []byte) error {
+	req, err := s.request(ctx, http.MethodPost, uploadURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Upload-Content-Type", "application/octet-stream")
+	header, err := discard(s.client, req)
+	if err != nil {
+		return fmt.Errorf("failed to initiate gcs resumable upload: %w", err)
+	}
+	session := header.Get("Location")
+	if session == "" {
+		return errors.New("gcs resumable upload has no session URI")
+	}
+
+	for offset := 0; offset < len(data); offset += s.config.ChunkSize {
+		end := min(offset+s.config.ChunkSize, len(data))
+		req, err := s.request(ctx, http.MethodPut, session, data[offset:end])
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, end-1, len(data)))
+		// The chunks but the last one are acknowledged with 308, which is
+		// not followed as it has no Location.
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to upload gcs chunk at %d: %w", offset, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusPermanentRedirect && resp.StatusCode/100 != 2 {
+			return fmt.Errorf("failed to upload gcs chunk at %d: %w", offset, &StatusError{StatusCode: resp.StatusCode})
+		}
+	}
+	return nil
+}
+
+// request returns an authenticated request with the given body.
+func (s *GCSStore) request(ctx context.Context, method, u string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	if err := bearer(req, s.config.Token); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// objectRequest returns a request for the object referenced by dataref.
+func (s *GCSStore) objectRequest(ctx context.Context, method, dataref string) (*http.Request, error) {
+	u, err := url.Parse(dataref)
+	if err != nil || u.Scheme != "gs" || u.Host != s.config.Bucket {
+		return nil, middleware.ErrForeignDataRef
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	if name == "" {
+		return nil, fmt.Errorf("dataref %q has no object name", dataref)
+	}
+	return s.request(ctx, method, s.endpoint+"/storage/v1/b/"+url.PathEscape(s.config.Bucket)+"/o/"+url.PathEscape(name), nil)
+}
+
+var _ middleware.ClaimCheckStore = (*GCSStore)(nil)
@@ -0,0 +1,150 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+// gcsServer is a minimal Cloud Storage JSON API storing the objects of the
+// bucket "claims".
+type gcsServer struct {
+	url string
+
+	mu       sync.Mutex
+	objects  map[string][]byte
+	queries  map[string]string
+	sessions map[string]*bytes.Buffer
+	chunks   int
+}
+
+func (s *gcsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/claims/o":
+		name := query.Get("name")
+		s.queries[name] = r.URL.RawQuery
+		if query.Get("uploadType") == "resumable" {
+			s.sessions[name] = &bytes.Buffer{}
+			w.Header().Set("Location", s.url+"/upload/session?name="+name)
+			return
+		}
+		s.objects[name] = body
+		fmt.Fprintf(w, `{"name":%q}`, name)
+	case r.Method == http.MethodPut && r.URL.Path == "/upload/session":
+		name := query.Get("name")
+		var start, end, total int
+		if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err != nil ||
+			start != s.sessions[name].Len() || end-start+1 != len(body) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.chunks++
+		s.sessions[name].Write(body)
+		if s.sessions[name].Len() < total {
+			w.WriteHeader(http.StatusPermanentRedirect)
+			return
+		}
+		s.objects[name] = s.sessions[name].Bytes()
+	case strings.HasPrefix(r.URL.Path, "/storage/v1/b/claims/o/"):
+		name := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/claims/o/")
+		data, ok := s.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(s.objects, name)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if query.Get("alt") != "media" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestGCSStore(t *testing.T, config GCSConfig) (*GCSStore, *gcsServer) {
+	s := &gcsServer{objects: map[string][]byte{}, queries: map[string]string{}, sessions: map[string]*bytes.Buffer{}}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	s.url = server.URL
+	config.Bucket = "claims"
+	config.Endpoint = server.URL
+	config.Token = func(context.Context) (string, error) { return "token", nil }
+	store, err := NewGCSStore(config)
+	require.NoError(t, err)
+	return store, s
+}
+
+func TestGCSStore(t *testing.T) {
+	store, server := newTestGCSStore(t, GCSConfig{Prefix: "orders/", KMSKeyName: "projects/p/locations/l/keyRings/r/cryptoKeys/k"})
+	ctx := context.Background()
+
+	dataref, err := store.Put(ctx, "2024/05/01/abc", []byte("payload"))
+	require.NoError(t, err)
+	require.Equal(t, "gs://claims/orders/2024/05/01/abc", dataref)
+	require.Contains(t, server.queries["orders/2024/05/01/abc"], "kmsKeyName=projects%2Fp%2Flocations%2Fl%2FkeyRings%2Fr%2FcryptoKeys%2Fk")
+
+	data, err := store.Get(ctx, dataref)
+	require.NoError(t, err)
+	require.Equal(t, "payload", string(data))
+
+	_, err = store.Get(ctx, "s3://claims/orders/2024/05/01/abc")
+	require.ErrorIs(t, err, middleware.ErrForeignDataRef)
+
+	require.NoError(t, store.Delete(ctx, dataref))
+	_, err = store.Get(ctx, dataref)
+	require.Error(t, err)
+}
+
+func TestGCSStoreResumable(t *testing.T) {
+	store, server := newTestGCSStore(t, GCSConfig{ChunkSize: gcsChunkUnit})
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789"), (2*gcsChunkUnit+100)/10)
+	dataref, err := store.Put(ctx, "big", data)
+	require.NoError(t, err)
+	require.Equal(t, 3, server.chunks)
+
+	got, err := store.Get(ctx, dataref)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+}
+
+func TestNewGCSStoreInvalid(t *testing.T) {
+	for name, config := range map[string]GCSConfig{
+		"no bucket":  {},
+		"chunk size": {Bucket: "claims", ChunkSize: 1000},
+		"endpoint":   {Bucket: "claims", Endpoint: "storage"},
+	} {
+		_, err := NewGCSStore(config)
+		require.Error(t, err, name)
+	}
+}
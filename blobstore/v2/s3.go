@@ -0,0 +1,337 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/protocol/http/sigv4/v2"
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+// Server-side encryption modes of S3.
+const (
+	// SSES3 encrypts the objects with keys managed by S3.
+	SSES3 = "AES256"
+	// SSEKMS encrypts the objects with a KMS key, the default key of the
+	// account unless S3Config.SSEKMSKeyID is set.
+	SSEKMS = "aws:kms"
+)
+
+const (
+	// DefaultS3PartSize is the default size of the parts of the multipart
+	// uploads, and the size above which the multipart upload is used.
+	DefaultS3PartSize = 16 << 20
+	// minS3PartSize is the minimal size of the parts but the last one.
+	minS3PartSize = 5 << 20
+)
+
+// Credentials are AWS credentials, see sigv4.Credentials.
+type Credentials = sigv4.Credentials
+
+// CredentialsProvider returns the AWS credentials signing a request, see
+// sigv4.CredentialsProvider.
+type CredentialsProvider = sigv4.CredentialsProvider
+
+// StaticCredentials returns a CredentialsProvider always returning the given
+// credentials.
+func StaticCredentials(accessKeyID, secretAccessKey, sessionToken string) CredentialsProvider {
+	return sigv4.StaticCredentials(accessKeyID, secretAccessKey, sessionToken)
+}
+
+// S3Config configures an S3Store.
+type S3Config struct {
+	// Bucket is the bucket the data is stored in. Required.
+	Bucket string
+	// Region is the region of the bucket. Required.
+	Region string
+	// Endpoint is the URL of an S3 compatible store, e.g. MinIO, reached
+	// with path-style requests. Empty uses the virtual-hosted endpoint of the
+	// bucket on AWS.
+	Endpoint string
+	// Prefix is prepended to the keys of the objects.
+	Prefix string
+	// Credentials sign the requests. Required.
+	Credentials CredentialsProvider
+	// SSE is the server-side encryption of the objects, SSES3 or SSEKMS.
+	// Empty uses the default encryption of the bucket.
+	SSE string
+	// SSEKMSKeyID is the KMS key encrypting the objects with SSEKMS.
+	SSEKMSKeyID string
+	// PartSize is the size of the parts of the multipart uploads, used for
+	// the data larger than PartSize. Defaults to DefaultS3PartSize, and must
+	// be at least 5 MiB.
+	PartSize int
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// S3Store is a middleware.ClaimCheckStore storing the data in an S3 bucket.
+// Its datarefs are s3://<bucket>/<key>.
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	signer   *sigv4.Signer
+	client   *http.Client
+}
+
+// NewS3Store returns an S3Store configured by config.
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Bucket == "" || config.Region == "" {
+		return nil, errors.New("s3 store requires a bucket and a region")
+	}
+	if config.Credentials == nil {
+		return nil, errors.New("s3 store requires credentials")
+	}
+	switch config.SSE {
+	case "", SSES3, SSEKMS:
+	default:
+		return nil, fmt.Errorf("invalid s3 server-side encryption %q", config.SSE)
+	}
+	if config.SSEKMSKeyID != "" && config.SSE != SSEKMS {
+		return nil, errors.New("s3 KMS key requires the aws:kms server-side encryption")
+	}
+	if config.PartSize == 0 {
+		config.PartSize = DefaultS3PartSize
+	}
+	if config.PartSize < minS3PartSize {
+		return nil, fmt.Errorf("s3 part size must be at least %d, got %d", minS3PartSize, config.PartSize)
+	}
+
+	var endpoint *url.URL
+	if config.Endpoint == "" {
+		endpoint = &url.URL{Scheme: "https", Host: config.Bucket + ".s3." + config.Region + ".amazonaws.com"}
+	} else {
+		u, err := url.Parse(config.Endpoint)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+		}
+		endpoint = u.JoinPath(config.Bucket)
+	}
+	// S3 requires the hash of the payload in the X-Amz-Content-Sha256 header.
+	signer, err := sigv4.NewSigner(config.Credentials, config.Region, "s3", sigv4.WithPayloadHashHeader())
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		signer:   signer,
+		client:   httpClient(config.Client),
+	}, nil
+}
+
+// Put implements middleware.ClaimCheckStore.Put. The data larger than the
+// part size is uploaded with a multipart upload, aborted on failure.
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) (string, error) {
+	key = s.config.Prefix + key
+	if len(data) > s.config.PartSize {
+		if err := s.putMultipart(ctx, key, data); err != nil {
+			return "", err
+		}
+	} else {
+		req, err := s.request(ctx, http.MethodPut, key, nil, data)
+		if err != nil {
+			return "", err
+		}
+		s.encryption(req.Header)
+		if _, err := s.send(req, data); err != nil {
+			return "", err
+		}
+	}
+	u := url.URL{Scheme: "s3", Host: s.config.Bucket, Path: "/" + key}
+	return u.String(), nil
+}
+
+// Get implements middleware.ClaimCheckStore.Get.
+func (s *S3Store) Get(ctx context.Context, dataref string) ([]byte, error) {
+	key, err := s.key(dataref)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.request(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.signer.Sign(req, nil); err != nil {
+		return nil, err
+	}
+	return readAll(s.client, req)
+}
+
+// Delete removes the data referenced by dataref.
+func (s *S3Store) Delete(ctx context.Context, dataref string) error {
+	key, err := s.key(dataref)
+	if err != nil {
+		return err
+	}
+	req, err := s.request(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = s.send(req, nil)
+	return err
+}
+
+// putMultipart uploads data to key in parts.
+func (s *S3Store) putMultipart(ctx context.Context, key string, data []byte) error {
+	req, err := s.request(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return err
+	}
+	s.encryption(req.Header)
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := s.sendXML(req, nil, &initiated); err != nil {
+		return fmt.Errorf("failed to initiate s3 multipart upload: %w", err)
+	}
+
+	if err := s.uploadParts(ctx, key, initiated.UploadID, data); err != nil {
+		// Abort so that the uploaded parts are not billed.
+		if req, abortErr := s.request(context.WithoutCancel(ctx), http.MethodDelete, key, url.Values{"uploadId": {initiated.UploadID}}, nil); abortErr == nil {
+			_, _ = s.send(req, nil)
+		}
+		return err
+	}
+	return nil
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// uploadParts uploads the parts of data and completes the multipart upload.
+func (s *S3Store) uploadParts(ctx context.Context, key, uploadID string, data []byte) error {
+	var complete struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}
+	for offset, number := 0, 1; offset < len(data); offset, number = offset+s.config.PartSize, number+1 {
+		part := data[offset:min(offset+s.config.PartSize, len(data))]
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+		req, err := s.request(ctx, http.MethodPut, key, query, part)
+		if err != nil {
+			return err
+		}
+		header, err := s.send(req, part)
+		if err != nil {
+			return fmt.Errorf("failed to upload s3 part %d: %w", number, err)
+		}
+		complete.Parts = append(complete.Parts, s3CompletedPart{PartNumber: number, ETag: header.Get("ETag")})
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	req, err := s.request(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	// The completion can fail after a successful status, with an error in
+	// the body of the response.
+	var completed struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := s.sendXML(req, body, &completed); err != nil {
+		return fmt.Errorf("failed to complete s3 multipart upload: %w", err)
+	}
+	if completed.XMLName.Local == "Error" {
+		return fmt.Errorf("failed to complete s3 multipart upload: %s: %s", completed.Code, completed.Message)
+	}
+	return nil
+}
+
+// encryption sets the server-side encryption headers of an upload.
+func (s *S3Store) encryption(header http.Header) {
+	if s.config.SSE != "" {
+		header.Set("X-Amz-Server-Side-Encryption", s.config.SSE)
+	}
+	if s.config.SSEKMSKeyID != "" {
+		header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", s.config.SSEKMSKeyID)
+	}
+}
+
+// request returns a request for the object key.
+func (s *S3Store) request(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawPath = strings.TrimSuffix(s.endpoint.EscapedPath(), "/") + "/" + s3Escape(key)
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	return req, nil
+}
+
+// send signs and sends req, whose body is body, and returns the headers of
+// the response.
+func (s *S3Store) send(req *http.Request, body []byte) (http.Header, error) {
+	if err := s.signer.Sign(req, body); err != nil {
+		return nil, err
+	}
+	return discard(s.client, req)
+}
+
+// sendXML signs and sends req, whose body is body, and decodes the XML
+// response into out.
+func (s *S3Store) sendXML(req *http.Request, body []byte, out interface{}) error {
+	if err := s.signer.Sign(req, body); err != nil {
+		return err
+	}
+	b, err := readAll(s.client, req)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(b, out)
+}
+
+// key returns the key of the object referenced by dataref.
+func (s *S3Store) key(dataref string) (string, error) {
+	u, err := url.Parse(dataref)
+	if err != nil || u.Scheme != "s3" || u.Host != s.config.Bucket {
+		return "", middleware.ErrForeignDataRef
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if key == "" {
+		return "", fmt.Errorf("dataref %q has no object key", dataref)
+	}
+	return key, nil
+}
+
+// s3Escape escapes key as required by SigV4 for S3: all the characters but
+// the unreserved ones and the slashes are escaped.
+func s3Escape(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+var _ middleware.ClaimCheckStore = (*S3Store)(nil)
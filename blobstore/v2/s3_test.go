@@ -0,0 +1,172 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+// s3Server is a minimal S3 API storing the objects of the bucket "claims".
+type s3Server struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	headers map[string]http.Header
+	parts   map[string][][]byte
+	aborted int
+	// failComplete makes the completion of the multipart uploads fail.
+	failComplete bool
+}
+
+func newS3Server() *s3Server {
+	return &s3Server{objects: map[string][]byte{}, headers: map[string]http.Header{}, parts: map[string][][]byte{}}
+}
+
+func (s *s3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=id/") || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/claims/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.headers[key] = r.Header.Clone()
+		s.parts["upload-1"] = nil
+		fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		if len(body) > 6<<20 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.parts[query.Get("uploadId")] = append(s.parts[query.Get("uploadId")], body)
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%s"`, query.Get("partNumber")))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete struct {
+			Parts []s3CompletedPart `xml:"Part"`
+		}
+		_ = xml.Unmarshal(body, &complete)
+		if s.failComplete || len(complete.Parts) != len(s.parts[query.Get("uploadId")]) || complete.Parts[0].ETag != `"etag-1"` {
+			fmt.Fprint(w, `<Error><Code>InvalidPart</Code><Message>invalid part</Message></Error>`)
+			return
+		}
+		s.objects[key] = bytes.Join(s.parts[query.Get("uploadId")], nil)
+		fmt.Fprint(w, `<CompleteMultipartUploadResult></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		s.aborted++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		s.objects[key] = body
+		s.headers[key] = r.Header.Clone()
+	case r.Method == http.MethodGet:
+		data, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestS3Store(t *testing.T, config S3Config) (*S3Store, *s3Server) {
+	s := newS3Server()
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	config.Bucket = "claims"
+	config.Region = "eu-west-1"
+	config.Endpoint = server.URL
+	config.Credentials = StaticCredentials("id", "secret", "")
+	store, err := NewS3Store(config)
+	require.NoError(t, err)
+	return store, s
+}
+
+func TestS3Store(t *testing.T) {
+	store, server := newTestS3Store(t, S3Config{Prefix: "orders/", SSE: SSEKMS, SSEKMSKeyID: "key-1"})
+	ctx := context.Background()
+
+	dataref, err := store.Put(ctx, "2024/05/01/abc", []byte("payload"))
+	require.NoError(t, err)
+	require.Equal(t, "s3://claims/orders/2024/05/01/abc", dataref)
+	require.Equal(t, "aws:kms", server.headers["orders/2024/05/01/abc"].Get("X-Amz-Server-Side-Encryption"))
+	require.Equal(t, "key-1", server.headers["orders/2024/05/01/abc"].Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"))
+
+	data, err := store.Get(ctx, dataref)
+	require.NoError(t, err)
+	require.Equal(t, "payload", string(data))
+
+	_, err = store.Get(ctx, "s3://other/orders/2024/05/01/abc")
+	require.ErrorIs(t, err, middleware.ErrForeignDataRef)
+
+	require.NoError(t, store.Delete(ctx, dataref))
+	_, err = store.Get(ctx, dataref)
+	var statusErr *StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
+
+func TestS3StoreMultipart(t *testing.T) {
+	store, server := newTestS3Store(t, S3Config{PartSize: minS3PartSize, SSE: SSES3})
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789"), (2*minS3PartSize+100)/10)
+	dataref, err := store.Put(ctx, "big", data)
+	require.NoError(t, err)
+	require.Len(t, server.parts["upload-1"], 3)
+	require.Equal(t, "AES256", server.headers["big"].Get("X-Amz-Server-Side-Encryption"))
+
+	got, err := store.Get(ctx, dataref)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+	require.Zero(t, server.aborted)
+
+	// A failed completion aborts the upload.
+	server.mu.Lock()
+	server.failComplete = true
+	server.mu.Unlock()
+	_, err = store.Put(ctx, "bigger", data)
+	require.ErrorContains(t, err, "InvalidPart")
+	require.Equal(t, 1, server.aborted)
+}
+
+func TestNewS3StoreInvalid(t *testing.T) {
+	creds := StaticCredentials("id", "secret", "")
+	for name, config := range map[string]S3Config{
+		"no bucket":      {Region: "eu-west-1", Credentials: creds},
+		"no credentials": {Bucket: "claims", Region: "eu-west-1"},
+		"invalid sse":    {Bucket: "claims", Region: "eu-west-1", Credentials: creds, SSE: "rot13"},
+		"kms key":        {Bucket: "claims", Region: "eu-west-1", Credentials: creds, SSEKMSKeyID: "key-1"},
+		"small parts":    {Bucket: "claims", Region: "eu-west-1", Credentials: creds, PartSize: 1 << 20},
+		"endpoint":       {Bucket: "claims", Region: "eu-west-1", Credentials: creds, Endpoint: "minio"},
+	} {
+		_, err := NewS3Store(config)
+		require.Error(t, err, name)
+	}
+}
@@ -0,0 +1,376 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Config is the configuration of a client, as read from a document.
+type Config struct {
+	Protocol Protocol `yaml:"protocol"`
+	// TLS configures the TLS connections of the protocol, if set.
+	TLS *TLS `yaml:"tls"`
+	// Retries are the retries of the events sent, if set.
+	Retries *Retries `yaml:"retries"`
+	Client  Client   `yaml:"client"`
+	// Middleware are the middlewares of the receiver, in order, see
+	// client.WithMiddleware.
+	Middleware []Middleware `yaml:"middleware"`
+	Formats    Formats      `yaml:"formats"`
+}
+
+// Protocol is the protocol of a client.
+type Protocol struct {
+	// Type is the name of the ProtocolFactory building the protocol.
+	Type     string   `yaml:"type"`
+	Settings Settings `yaml:"settings"`
+}
+
+// TLS configures the TLS connections of a protocol, see protocol.TLSOption.
+type TLS struct {
+	RootCAFile string `yaml:"rootCAFile"`
+	CertFile   string `yaml:"certFile"`
+	KeyFile    string `yaml:"keyFile"`
+	ServerName string `yaml:"serverName"`
+	// MinVersion is the minimum version of TLS, e.g. "1.2".
+	MinVersion         string `yaml:"minVersion"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
+
+// Retries configures retries, see context.RetryParams.
+type Retries struct {
+	// Strategy is "none", "constant", "linear" or "exponential".
+	Strategy string        `yaml:"strategy"`
+	MaxTries int           `yaml:"maxTries"`
+	Period   time.Duration `yaml:"period"`
+	// BudgetRatio, if set, limits the retries of the events sent to this
+	// ratio of the events sent, see context.NewRetryBudget.
+	BudgetRatio float64 `yaml:"budgetRatio"`
+	BudgetBurst int     `yaml:"budgetBurst"`
+}
+
+// Client are the options of a client, see the client options of the same
+// names.
+type Client struct {
+	UUIDs                bool          `yaml:"uuids"`
+	TimeNow              bool          `yaml:"timeNow"`
+	PollGoroutines       int           `yaml:"pollGoroutines"`
+	BlockingCallback     bool          `yaml:"blockingCallback"`
+	HandlerTimeout       time.Duration `yaml:"handlerTimeout"`
+	AckMalformedEvent    bool          `yaml:"ackMalformedEvent"`
+	RedeliveryExtensions bool          `yaml:"redeliveryExtensions"`
+	OrderedDispatch      int           `yaml:"orderedDispatch"`
+	InflightHigh         int           `yaml:"inflightHigh"`
+	InflightLow          int           `yaml:"inflightLow"`
+}
+
+// Middleware is a middleware of a client.
+type Middleware struct {
+	// Name is the name of the MiddlewareFactory building the middleware.
+	Name     string   `yaml:"name"`
+	Settings Settings `yaml:"settings"`
+}
+
+// Formats configure the encoding of the events sent.
+type Formats struct {
+	// Encoding is the encoding of the events sent, "binary" or
+	// "structured", see client.WithForceBinary and
+	// client.WithForceStructured. Defaults to the one of the protocol.
+	Encoding     string                `yaml:"encoding"`
+	ContentTypes []ContentTypeEncoding `yaml:"contentTypes"`
+}
+
+// ContentTypeEncoding is the encoding of the events of a content type, see
+// client.WithContentTypeEncoding.
+type ContentTypeEncoding struct {
+	ContentType string `yaml:"contentType"`
+	// Encoding is "binary" or "structured".
+	Encoding string `yaml:"encoding"`
+	// Format is the media type of the format of the structured events,
+	// registered with format.Add. Defaults to JSON.
+	Format string `yaml:"format"`
+}
+
+// Settings are the settings of a protocol or a middleware, decoded by its
+// factory.
+type Settings struct {
+	node *yaml.Node
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *Settings) UnmarshalYAML(node *yaml.Node) error {
+	s.node = node
+	return nil
+}
+
+// Decode decodes the settings into v, a pointer to a struct with yaml field
+// tags, rejecting the unknown fields. It leaves v unchanged if there are no
+// settings.
+func (s Settings) Decode(v interface{}) error {
+	if s.node == nil {
+		return nil
+	}
+	return decodeStrict(s.node, v)
+}
+
+// ParseOption configures Parse and ReadFile.
+type ParseOption func(*parser)
+
+type parser struct {
+	lookupEnv func(string) (string, bool)
+}
+
+// WithLookupEnv sets the function looking up the environment variables
+// referenced by the document. Defaults to os.LookupEnv.
+func WithLookupEnv(lookup func(name string) (string, bool)) ParseOption {
+	return func(p *parser) {
+		p.lookupEnv = lookup
+	}
+}
+
+// Parse parses the YAML, or JSON, document doc, replacing its environment
+// variable references. The unknown fields are rejected.
+func Parse(doc []byte, opts ...ParseOption) (*Config, error) {
+	p := parser{lookupEnv: os.LookupEnv}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(doc, &node); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if node.Kind == 0 {
+		return nil, errors.New("config: empty document")
+	}
+	if err := expandNode(&node, p.lookupEnv); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	c := &Config{}
+	if err := decodeStrict(&node, c); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return c, nil
+}
+
+// ReadFile parses the document of the file at path, see Parse.
+func ReadFile(path string, opts ...ParseOption) (*Config, error) {
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return Parse(doc, opts...)
+}
+
+// NewClient builds the client configured by c, its protocol, middlewares and
+// options, followed by opts, e.g. the observability service.
+func (c *Config) NewClient(ctx context.Context, opts ...client.Option) (client.Client, error) {
+	if c.Protocol.Type == "" {
+		return nil, errors.New("config: protocol type is required")
+	}
+	factory, err := lookupProtocol(c.Protocol.Type)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	var tlsOpts []protocol.TLSOption
+	if c.TLS != nil {
+		if tlsOpts, err = c.TLS.options(); err != nil {
+			return nil, fmt.Errorf("config: tls: %w", err)
+		}
+	}
+	p, err := factory(ctx, c.Protocol.Settings, tlsOpts)
+	if err != nil {
+		return nil, fmt.Errorf("config: protocol %s: %w", c.Protocol.Type, err)
+	}
+
+	clientOpts, err := c.clientOptions()
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	cl, err := client.New(p, append(clientOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	return cl, nil
+}
+
+func (c *Config) clientOptions() ([]client.Option, error) {
+	var opts []client.Option
+	if r := c.Retries; r != nil {
+		params, err := r.params()
+		if err != nil {
+			return nil, fmt.Errorf("retries: %w", err)
+		}
+		opts = append(opts, client.WithRetryParams(params))
+		if r.BudgetRatio != 0 {
+			budget, err := cecontext.NewRetryBudget(r.BudgetRatio, r.BudgetBurst)
+			if err != nil {
+				return nil, fmt.Errorf("retries: %w", err)
+			}
+			opts = append(opts, client.WithRetryBudget(budget))
+		}
+	}
+
+	cc := c.Client
+	if cc.UUIDs {
+		opts = append(opts, client.WithUUIDs())
+	}
+	if cc.TimeNow {
+		opts = append(opts, client.WithTimeNow())
+	}
+	if cc.PollGoroutines != 0 {
+		opts = append(opts, client.WithPollGoroutines(cc.PollGoroutines))
+	}
+	if cc.BlockingCallback {
+		opts = append(opts, client.WithBlockingCallback())
+	}
+	if cc.HandlerTimeout != 0 {
+		opts = append(opts, client.WithHandlerTimeout(cc.HandlerTimeout))
+	}
+	if cc.AckMalformedEvent {
+		opts = append(opts, client.WithAckMalformedEvent())
+	}
+	if cc.RedeliveryExtensions {
+		opts = append(opts, client.WithRedeliveryExtensions())
+	}
+	if cc.OrderedDispatch != 0 {
+		opts = append(opts, client.WithOrderedDispatch(cc.OrderedDispatch))
+	}
+	if cc.InflightHigh != 0 || cc.InflightLow != 0 {
+		opts = append(opts, client.WithInflightWatermarks(cc.InflightHigh, cc.InflightLow))
+	}
+
+	mws := make([]client.Middleware, 0, len(c.Middleware))
+	for i, m := range c.Middleware {
+		factory, err := lookupMiddleware(m.Name)
+		if err != nil {
+			return nil, fmt.Errorf("middleware %d: %w", i, err)
+		}
+		mw, err := factory(m.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("middleware %s: %w", m.Name, err)
+		}
+		mws = append(mws, mw)
+	}
+	if len(mws) > 0 {
+		opts = append(opts, client.WithMiddleware(mws...))
+	}
+
+	switch c.Formats.Encoding {
+	case "":
+	case "binary":
+		opts = append(opts, client.WithForceBinary())
+	case "structured":
+		opts = append(opts, client.WithForceStructured())
+	default:
+		return nil, fmt.Errorf("formats: unknown encoding %q", c.Formats.Encoding)
+	}
+	for _, ct := range c.Formats.ContentTypes {
+		encoding, err := parseEncoding(ct.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("formats: content type %s: %w", ct.ContentType, err)
+		}
+		var f format.Format
+		if ct.Format != "" {
+			if f = format.Lookup(ct.Format); f == nil {
+				return nil, fmt.Errorf("formats: content type %s: format %q is not registered", ct.ContentType, ct.Format)
+			}
+		}
+		opts = append(opts, client.WithContentTypeEncoding(ct.ContentType, encoding, f))
+	}
+	return opts, nil
+}
+
+func (t *TLS) options() ([]protocol.TLSOption, error) {
+	var opts []protocol.TLSOption
+	if t.RootCAFile != "" {
+		opts = append(opts, protocol.WithTLSRootCAFile(t.RootCAFile))
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		opts = append(opts, protocol.WithTLSClientKeyPair(t.CertFile, t.KeyFile))
+	}
+	if t.ServerName != "" {
+		opts = append(opts, protocol.WithTLSServerName(t.ServerName))
+	}
+	if t.MinVersion != "" {
+		version, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown minimum version %q", t.MinVersion)
+		}
+		opts = append(opts, protocol.WithTLSMinVersion(version))
+	}
+	if t.InsecureSkipVerify {
+		opts = append(opts, protocol.WithTLSInsecureSkipVerify())
+	}
+	return opts, nil
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func (r *Retries) params() (cecontext.RetryParams, error) {
+	params := cecontext.RetryParams{
+		Strategy: cecontext.BackoffStrategy(r.Strategy),
+		MaxTries: r.MaxTries,
+		Period:   r.Period,
+	}
+	switch params.Strategy {
+	case "":
+		params.Strategy = cecontext.BackoffStrategyNone
+	case cecontext.BackoffStrategyNone, cecontext.BackoffStrategyConstant, cecontext.BackoffStrategyLinear, cecontext.BackoffStrategyExponential:
+	default:
+		return params, fmt.Errorf("unknown strategy %q", r.Strategy)
+	}
+	if r.MaxTries < 0 {
+		return params, errors.New("maxTries can not be negative")
+	}
+	return params, nil
+}
+
+func parseEncoding(encoding string) (binding.Encoding, error) {
+	switch encoding {
+	case "binary":
+		return binding.EncodingBinary, nil
+	case "structured":
+		return binding.EncodingStructured, nil
+	default:
+		return binding.EncodingUnknown, fmt.Errorf("unknown encoding %q", encoding)
+	}
+}
+
+// decodeStrict decodes node into v, rejecting the fields v does not have,
+// which yaml.Node.Decode does not do.
+func decodeStrict(node *yaml.Node, v interface{}) error {
+	b, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
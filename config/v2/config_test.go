@@ -0,0 +1,220 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package config_test
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/config/v2"
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+)
+
+const document = `
+protocol:
+  type: http
+  settings:
+    target: ${SINK_URL}
+    port: ${PORT:-0}
+    headers:
+      X-Team: payments
+retries:
+  strategy: constant
+  maxTries: 2
+  period: 1ms
+client:
+  uuids: true
+  timeNow: true
+  handlerTimeout: 30s
+middleware:
+  - name: deadline
+  - name: concurrencyLimit
+    settings:
+      default: 4
+      limits:
+        order.created: 2
+formats:
+  encoding: structured
+  contentTypes:
+    - contentType: application/xml
+      encoding: binary
+`
+
+func lookup(env map[string]string) config.ParseOption {
+	return config.WithLookupEnv(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+}
+
+func TestParse(t *testing.T) {
+	c, err := config.Parse([]byte(document), lookup(map[string]string{"SINK_URL": "http://sink", "PORT": "8080"}))
+	require.NoError(t, err)
+	require.Equal(t, "http", c.Protocol.Type)
+	require.Equal(t, &config.Retries{Strategy: "constant", MaxTries: 2, Period: time.Millisecond}, c.Retries)
+	require.Equal(t, config.Client{UUIDs: true, TimeNow: true, HandlerTimeout: 30 * time.Second}, c.Client)
+	require.Len(t, c.Middleware, 2)
+	require.Equal(t, "concurrencyLimit", c.Middleware[1].Name)
+	require.Equal(t, "structured", c.Formats.Encoding)
+	require.Equal(t, []config.ContentTypeEncoding{{ContentType: "application/xml", Encoding: "binary"}}, c.Formats.ContentTypes)
+
+	var settings struct {
+		Target  string            `yaml:"target"`
+		Port    int               `yaml:"port"`
+		Headers map[string]string `yaml:"headers"`
+	}
+	require.NoError(t, c.Protocol.Settings.Decode(&settings))
+	require.Equal(t, "http://sink", settings.Target)
+	require.Equal(t, 8080, settings.Port)
+	require.Equal(t, map[string]string{"X-Team": "payments"}, settings.Headers)
+}
+
+func TestParseJSON(t *testing.T) {
+	c, err := config.Parse([]byte(`{"protocol": {"type": "http", "settings": {"port": 8080}}, "client": {"pollGoroutines": 2}}`))
+	require.NoError(t, err)
+	require.Equal(t, "http", c.Protocol.Type)
+	require.Equal(t, 2, c.Client.PollGoroutines)
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		doc, err string
+	}{
+		"empty":           {doc: "", err: "empty document"},
+		"syntax":          {doc: "protocol: [", err: "config: yaml"},
+		"unset variable":  {doc: "protocol:\n  type: ${PROTOCOL}", err: "line 2: environment variable PROTOCOL is not set"},
+		"unknown field":   {doc: "protocol:\n  type: http\n  target: http://sink", err: "field target not found"},
+		"wrong type":      {doc: "client:\n  pollGoroutines: many", err: "cannot unmarshal"},
+		"quoted variable": {doc: "client:\n  pollGoroutines: '${N}'", err: "cannot unmarshal"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := config.Parse([]byte(tc.doc), lookup(map[string]string{"N": "2"}))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("protocol:\n  type: http\n"), 0o600))
+	c, err := config.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "http", c.Protocol.Type)
+
+	_, err = config.ReadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestNewClient(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(nethttp.StatusServiceUnavailable)
+			return
+		}
+		require.Equal(t, "payments", r.Header.Get("X-Team"))
+		require.True(t, strings.HasPrefix(r.Header.Get("Content-Type"), "application/cloudevents+json"))
+		w.WriteHeader(nethttp.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c, err := config.Parse([]byte(document), lookup(map[string]string{"SINK_URL": server.URL}))
+	require.NoError(t, err)
+	cl, err := c.NewClient(ctx)
+	require.NoError(t, err)
+
+	e := event.New()
+	e.SetSource("/config")
+	e.SetType("order.created")
+	// The id and time are set by the client, and the retries of the
+	// document retry the unavailable server.
+	require.True(t, protocol.IsACK(cl.Send(ctx, e)))
+	require.Equal(t, int32(2), requests.Load())
+
+	flow, err := client.DescribeFlow(cl)
+	require.NoError(t, err)
+	var middlewares []string
+	for _, stage := range flow.Inbound {
+		switch stage.Name {
+		case "middleware":
+			middlewares = stage.Components
+		case "handle":
+			require.Equal(t, "30s", stage.Config["timeout"])
+		}
+	}
+	require.Len(t, middlewares, 2)
+	require.Contains(t, middlewares[0], "middleware.Deadline")
+	require.Contains(t, middlewares[1], "middleware.ConcurrencyLimit")
+}
+
+func TestNewClientErrors(t *testing.T) {
+	ctx := context.Background()
+	for name, tc := range map[string]struct {
+		doc, err string
+	}{
+		"no protocol":         {doc: "client:\n  uuids: true", err: "protocol type is required"},
+		"unknown protocol":    {doc: "protocol:\n  type: carrier-pigeon", err: `unknown protocol type "carrier-pigeon", registered: [`},
+		"protocol settings":   {doc: "protocol:\n  type: http\n  settings:\n    port: -1", err: "protocol http:"},
+		"unknown setting":     {doc: "protocol:\n  type: http\n  settings:\n    url: x", err: "field url not found"},
+		"tls version":         {doc: "protocol:\n  type: http\ntls:\n  minVersion: '2.0'", err: `tls: unknown minimum version "2.0"`},
+		"tls files":           {doc: "protocol:\n  type: http\ntls:\n  rootCAFile: /missing.pem", err: "protocol http:"},
+		"retry strategy":      {doc: "protocol:\n  type: http\nretries:\n  strategy: fibonacci", err: `retries: unknown strategy "fibonacci"`},
+		"retry budget":        {doc: "protocol:\n  type: http\nretries:\n  budgetRatio: 2", err: "retries: retry budget ratio"},
+		"client option":       {doc: "protocol:\n  type: http\nclient:\n  orderedDispatch: -1", err: "ordered dispatch"},
+		"unknown middleware":  {doc: "protocol:\n  type: http\nmiddleware:\n  - name: magic", err: `middleware 0: unknown middleware "magic"`},
+		"middleware settings": {doc: "protocol:\n  type: http\nmiddleware:\n  - name: retry\n    settings:\n      maxTries: -1", err: "middleware retry: maxTries"},
+		"encoding":            {doc: "protocol:\n  type: http\nformats:\n  encoding: morse", err: `formats: unknown encoding "morse"`},
+		"content encoding":    {doc: "protocol:\n  type: http\nformats:\n  contentTypes:\n    - contentType: text/plain", err: `content type text/plain: unknown encoding ""`},
+		"format":              {doc: "protocol:\n  type: http\nformats:\n  contentTypes:\n    - contentType: text/plain\n      encoding: structured\n      format: application/morse", err: `format "application/morse" is not registered`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := config.Parse([]byte(tc.doc))
+			require.NoError(t, err)
+			_, err = c.NewClient(ctx)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	var settings struct {
+		Depth int `yaml:"depth"`
+	}
+	config.RegisterProtocol("gochan", func(_ context.Context, s config.Settings, _ []protocol.TLSOption) (interface{}, error) {
+		if err := s.Decode(&settings); err != nil {
+			return nil, err
+		}
+		return gochan.New(), nil
+	})
+	var built bool
+	config.RegisterMiddleware("noop", func(config.Settings) (client.Middleware, error) {
+		built = true
+		return func(next client.Handler) client.Handler { return next }, nil
+	})
+
+	c, err := config.Parse([]byte("protocol:\n  type: gochan\n  settings:\n    depth: 3\nmiddleware:\n  - name: noop\n"))
+	require.NoError(t, err)
+	cl, err := c.NewClient(context.Background(), client.WithBlockingCallback())
+	require.NoError(t, err)
+	require.Equal(t, 3, settings.Depth)
+	require.True(t, built)
+	flow, err := client.DescribeFlow(cl)
+	require.NoError(t, err)
+	require.Equal(t, "*gochan.SendReceiver", flow.Protocol)
+}
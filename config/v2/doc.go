@@ -0,0 +1,49 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package config builds CloudEvents clients from a declarative YAML, or JSON,
+document, so that the services of a platform set up their clients the same
+way:
+
+	protocol:
+	  type: http
+	  settings:
+	    target: ${SINK_URL}
+	    port: ${PORT:-8080}
+	tls:
+	  rootCAFile: /etc/ssl/private-ca.pem
+	retries:
+	  strategy: exponential
+	  maxTries: 5
+	  period: 100ms
+	client:
+	  uuids: true
+	  timeNow: true
+	  handlerTimeout: 30s
+	middleware:
+	  - name: deadline
+	  - name: concurrencyLimit
+	    settings:
+	      default: 16
+	formats:
+	  contentTypes:
+	    - contentType: application/avro
+	      encoding: structured
+	      format: application/cloudevents+avro
+
+The ${VAR} references of the values are replaced with the environment
+variable VAR, which must be set, and ${VAR:-default} with default if it is
+unset or empty. $$ escapes a $.
+
+The protocols and the middlewares are built by the factories registered with
+RegisterProtocol and RegisterMiddleware under their name. The "http" protocol
+and the "deadline", "decompress", "retry" and "concurrencyLimit" middlewares
+of the client/middleware package are built in; the other protocols, living in
+their own modules, are registered by the programs using them. Likewise, the
+formats of the content types are looked up in the formats registered with
+format.Add, e.g. by importing their package.
+*/
+package config
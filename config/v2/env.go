@@ -0,0 +1,82 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expandNode replaces the environment variable references of the scalar
+// values of node and its children, see expand.
+func expandNode(node *yaml.Node, lookup func(string) (string, bool)) error {
+	if node.Kind == yaml.ScalarNode {
+		if !strings.Contains(node.Value, "$") {
+			return nil
+		}
+		value, err := expand(node.Value, lookup)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		node.Value = value
+		if node.Style == 0 {
+			// Resolve the type of the substituted plain value, e.g. the
+			// integer of ${PORT}.
+			node.Tag = ""
+		}
+		return nil
+	}
+	for _, child := range node.Content {
+		if err := expandNode(child, lookup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expand replaces the ${VAR} references of s with the value of the
+// environment variable VAR, which must be set, and the ${VAR:-default} ones
+// with default if VAR is unset or empty. $$ is replaced with $.
+func expand(s string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			s = s[i+2:]
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference %q", s[i:])
+			}
+			ref := s[i+2 : i+end]
+			name, def, hasDefault := strings.Cut(ref, ":-")
+			if name == "" {
+				return "", fmt.Errorf("empty variable reference %q", s[i:i+end+1])
+			}
+			value, ok := lookup(name)
+			switch {
+			case hasDefault && value == "":
+				value = def
+			case !ok:
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			b.WriteString(value)
+			s = s[i+end+1:]
+		default:
+			b.WriteByte('$')
+			s = s[i+1:]
+		}
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	env := map[string]string{"HOST": "example.com", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	for _, tc := range []struct {
+		in, want, err string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "https://${HOST}/events", want: "https://example.com/events"},
+		{in: "${HOST}${HOST}", want: "example.comexample.com"},
+		{in: "${PORT:-8080}", want: "8080"},
+		{in: "${EMPTY:-default}", want: "default"},
+		{in: "${EMPTY}", want: ""},
+		{in: "${HOST:-default}", want: "example.com"},
+		{in: "$$HOST costs $5 $", want: "$HOST costs $5 $"},
+		{in: "${PORT}", err: "environment variable PORT is not set"},
+		{in: "${HOST", err: "unterminated variable reference"},
+		{in: "${:-x}", err: "empty variable reference"},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := expand(tc.in, lookup)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
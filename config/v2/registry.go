@@ -0,0 +1,171 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// ProtocolFactory builds the protocol of a client, e.g. a *http.Protocol,
+// from the settings of the protocol of the document, and the options of its
+// tls section, if any.
+type ProtocolFactory func(ctx context.Context, settings Settings, tls []protocol.TLSOption) (interface{}, error)
+
+// MiddlewareFactory builds a middleware of a client from its settings in the
+// document.
+type MiddlewareFactory func(settings Settings) (client.Middleware, error)
+
+var (
+	registryMu sync.RWMutex
+	protocols  = map[string]ProtocolFactory{
+		"http": newHTTP,
+	}
+	middlewares = map[string]MiddlewareFactory{
+		"deadline":         newDeadline,
+		"decompress":       newDecompress,
+		"retry":            newRetry,
+		"concurrencyLimit": newConcurrencyLimit,
+	}
+)
+
+// RegisterProtocol registers the factory of the protocols of type name,
+// replacing the one registered with this name, if any.
+func RegisterProtocol(name string, factory ProtocolFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	protocols[name] = factory
+}
+
+// RegisterMiddleware registers the factory of the middlewares named name,
+// replacing the one registered with this name, if any.
+func RegisterMiddleware(name string, factory MiddlewareFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	middlewares[name] = factory
+}
+
+func lookupProtocol(name string) (ProtocolFactory, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if f, ok := protocols[name]; ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unknown protocol type %q, registered: %v", name, names(protocols))
+}
+
+func lookupMiddleware(name string) (MiddlewareFactory, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if f, ok := middlewares[name]; ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unknown middleware %q, registered: %v", name, names(middlewares))
+}
+
+func names[F any](factories map[string]F) []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// httpSettings are the settings of the "http" protocol.
+type httpSettings struct {
+	Target          string            `yaml:"target"`
+	Port            int               `yaml:"port"`
+	Path            string            `yaml:"path"`
+	Method          string            `yaml:"method"`
+	Headers         map[string]string `yaml:"headers"`
+	ReadTimeout     time.Duration     `yaml:"readTimeout"`
+	WriteTimeout    time.Duration     `yaml:"writeTimeout"`
+	ShutdownTimeout time.Duration     `yaml:"shutdownTimeout"`
+	MaxRequestBytes int64             `yaml:"maxRequestBytes"`
+}
+
+func newHTTP(_ context.Context, settings Settings, tls []protocol.TLSOption) (interface{}, error) {
+	var s httpSettings
+	if err := settings.Decode(&s); err != nil {
+		return nil, err
+	}
+	var opts []http.Option
+	if s.Target != "" {
+		opts = append(opts, http.WithTarget(s.Target))
+	}
+	if s.Port != 0 {
+		opts = append(opts, http.WithPort(s.Port))
+	}
+	if s.Path != "" {
+		opts = append(opts, http.WithPath(s.Path))
+	}
+	if s.Method != "" {
+		opts = append(opts, http.WithMethod(s.Method))
+	}
+	for key, value := range s.Headers {
+		opts = append(opts, http.WithHeader(key, value))
+	}
+	if s.ReadTimeout != 0 {
+		opts = append(opts, http.WithReadTimeout(s.ReadTimeout))
+	}
+	if s.WriteTimeout != 0 {
+		opts = append(opts, http.WithWriteTimeout(s.WriteTimeout))
+	}
+	if s.ShutdownTimeout != 0 {
+		opts = append(opts, http.WithShutdownTimeout(s.ShutdownTimeout))
+	}
+	if s.MaxRequestBytes != 0 {
+		opts = append(opts, http.WithMaxRequestBytes(s.MaxRequestBytes))
+	}
+	if len(tls) > 0 {
+		opts = append(opts, http.WithTLS(tls...))
+	}
+	return http.New(opts...)
+}
+
+func newDeadline(Settings) (client.Middleware, error) {
+	return middleware.Deadline(), nil
+}
+
+func newDecompress(Settings) (client.Middleware, error) {
+	return middleware.Decompress(), nil
+}
+
+func newRetry(settings Settings) (client.Middleware, error) {
+	var r Retries
+	if err := settings.Decode(&r); err != nil {
+		return nil, err
+	}
+	params, err := r.params()
+	if err != nil {
+		return nil, err
+	}
+	return middleware.Retry(params), nil
+}
+
+// concurrencyLimitSettings are the settings of the "concurrencyLimit"
+// middleware, limiting the events handled concurrently by type.
+type concurrencyLimitSettings struct {
+	Limits  map[string]int `yaml:"limits"`
+	Default int            `yaml:"default"`
+}
+
+func newConcurrencyLimit(settings Settings) (client.Middleware, error) {
+	var s concurrencyLimitSettings
+	if err := settings.Decode(&s); err != nil {
+		return nil, err
+	}
+	return middleware.ConcurrencyLimit(s.Limits, middleware.WithDefaultConcurrencyLimit(s.Default)), nil
+}
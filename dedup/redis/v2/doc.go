@@ -0,0 +1,9 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package redis implements a middleware.DedupCommitStore keeping the keys
+// of the processed events in Redis, so that the instances of a horizontally
+// scaled consumer share the duplicate suppression.
+package redis
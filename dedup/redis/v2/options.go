@@ -0,0 +1,37 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redis
+
+import "errors"
+
+// DefaultKeyPrefix is the prefix of the Redis keys used when WithKeyPrefix is
+// not provided.
+const DefaultKeyPrefix = "cloudevents:dedup:"
+
+// Option is the function signature required to be considered an redis.Option.
+type Option func(*Store) error
+
+// WithKeyPrefix sets the prefix of the Redis keys, to share a Redis database
+// between several consumers.
+func WithKeyPrefix(prefix string) Option {
+	return func(s *Store) error {
+		if prefix == "" {
+			return errors.New("redis key prefix can not be empty")
+		}
+		s.prefix = prefix
+		return nil
+	}
+}
+
+// WithResponses makes the Store keep the response of the processed events,
+// so that the duplicates of a request get the response of the original
+// request. See middleware.DedupResponseStore.
+func WithResponses() Option {
+	return func(s *Store) error {
+		s.responses = true
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// Store is a middleware.DedupStore backed by Redis.
+//
+// Each key is recorded with SET NX and expires with its ttl. The value of a
+// key is pendingValue until it is committed. When the responses are kept, the
+// response of an event is stored as the value of its key, in JSON format.
+type Store struct {
+	client    redis.UniversalClient
+	prefix    string
+	responses bool
+}
+
+// pendingValue is the value of a key not committed yet. A response is never
+// equal to it.
+const pendingValue = "pending"
+
+// commitScript replaces the value of a pending key, keeping its ttl, so that a
+// response recorded before the commit is kept.
+var commitScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("SET", KEYS[1], "", "KEEPTTL")
+end
+return false
+`)
+
+// New returns a Store using the given client, which is not closed by the
+// Store.
+func New(client redis.UniversalClient, opts ...Option) (*Store, error) {
+	if client == nil {
+		return nil, errors.New("redis client can not be nil")
+	}
+	s := &Store{client: client, prefix: DefaultKeyPrefix}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add implements middleware.DedupStore.Add.
+func (s *Store) Add(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl < 0 {
+		ttl = 0
+	}
+	added, err := s.client.SetNX(ctx, s.prefix+key, pendingValue, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis: failed to add key: %w", err)
+	}
+	return added, nil
+}
+
+// Remove implements middleware.DedupStore.Remove.
+func (s *Store) Remove(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.prefix+key).Err(); err != nil {
+		return fmt.Errorf("redis: failed to remove key: %w", err)
+	}
+	return nil
+}
+
+// Commit implements middleware.DedupCommitStore.Commit.
+func (s *Store) Commit(ctx context.Context, key string) error {
+	err := commitScript.Run(ctx, s.client, []string{s.prefix + key}, pendingValue).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("redis: failed to commit key: %w", err)
+	}
+	return nil
+}
+
+// Committed implements middleware.DedupCommitStore.Committed.
+func (s *Store) Committed(ctx context.Context, key string) (bool, error) {
+	v, err := s.client.Get(ctx, s.prefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("redis: failed to get key: %w", err)
+	}
+	return v != pendingValue, nil
+}
+
+// SetResponse implements middleware.DedupResponseStore.SetResponse. It does
+// nothing unless WithResponses is provided, or if key has expired.
+func (s *Store) SetResponse(ctx context.Context, key string, resp *event.Event) error {
+	if !s.responses {
+		return nil
+	}
+	b, err := format.JSON.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	err = s.client.SetArgs(ctx, s.prefix+key, b, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("redis: failed to set response: %w", err)
+	}
+	return nil
+}
+
+// GetResponse implements middleware.DedupResponseStore.GetResponse.
+func (s *Store) GetResponse(ctx context.Context, key string) (*event.Event, error) {
+	if !s.responses {
+		return nil, nil
+	}
+	b, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) || len(b) == 0 || string(b) == pendingValue {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to get response: %w", err)
+	}
+	resp := event.New()
+	if err := format.JSON.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("redis: failed to decode response: %w", err)
+	}
+	return &resp, nil
+}
+
+var (
+	_ middleware.DedupResponseStore = (*Store)(nil)
+	_ middleware.DedupCommitStore   = (*Store)(nil)
+)
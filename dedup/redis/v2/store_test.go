@@ -0,0 +1,144 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func newTestStore(t *testing.T, opts ...Option) (*Store, *miniredis.Miniredis) {
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	s, err := New(client, opts...)
+	require.NoError(t, err)
+	return s, m
+}
+
+func TestStore(t *testing.T) {
+	s, m := newTestStore(t, WithKeyPrefix("test:"))
+	ctx := context.Background()
+
+	added, err := s.Add(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.True(t, m.Exists("test:a"))
+	require.Equal(t, time.Minute, m.TTL("test:a"))
+
+	added, err = s.Add(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.False(t, added)
+
+	require.NoError(t, s.Remove(ctx, "a"))
+	added, err = s.Add(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	m.FastForward(time.Minute)
+	added, err = s.Add(ctx, "a", 0)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Zero(t, m.TTL("test:a"))
+}
+
+func TestStoreResponses(t *testing.T) {
+	s, m := newTestStore(t, WithResponses())
+	ctx := context.Background()
+
+	added, err := s.Add(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	resp, err := s.GetResponse(ctx, "a")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	want := event.New()
+	want.SetID("1")
+	want.SetSource("/source")
+	want.SetType("reply")
+	require.NoError(t, s.SetResponse(ctx, "a", &want))
+	// The response keeps the ttl of the key.
+	require.Equal(t, time.Minute, m.TTL(DefaultKeyPrefix+"a"))
+
+	resp, err = s.GetResponse(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, want.ID(), resp.ID())
+	require.Equal(t, want.Type(), resp.Type())
+
+	// Responses of expired keys are not recorded.
+	m.FastForward(time.Minute)
+	require.NoError(t, s.SetResponse(ctx, "a", &want))
+	require.False(t, m.Exists(DefaultKeyPrefix+"a"))
+}
+
+func TestStoreWithoutResponses(t *testing.T) {
+	s, m := newTestStore(t)
+	ctx := context.Background()
+
+	_, err := s.Add(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	e := event.New()
+	require.NoError(t, s.SetResponse(ctx, "a", &e))
+	v, err := m.Get(DefaultKeyPrefix + "a")
+	require.NoError(t, err)
+	require.Equal(t, pendingValue, v)
+}
+
+func TestStoreCommit(t *testing.T) {
+	s, m := newTestStore(t, WithResponses())
+	ctx := context.Background()
+
+	_, err := s.Add(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	committed, err := s.Committed(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, committed)
+
+	require.NoError(t, s.Commit(ctx, "a"))
+	committed, err = s.Committed(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, committed)
+	require.Equal(t, time.Minute, m.TTL(DefaultKeyPrefix+"a"))
+
+	// A response recorded before the commit is kept.
+	_, err = s.Add(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	want := event.New()
+	want.SetID("1")
+	want.SetSource("/source")
+	want.SetType("reply")
+	require.NoError(t, s.SetResponse(ctx, "b", &want))
+	require.NoError(t, s.Commit(ctx, "b"))
+	resp, err := s.GetResponse(ctx, "b")
+	require.NoError(t, err)
+	require.Equal(t, want.ID(), resp.ID())
+	committed, err = s.Committed(ctx, "b")
+	require.NoError(t, err)
+	require.True(t, committed)
+
+	// Unknown keys are not committed, nor created.
+	require.NoError(t, s.Commit(ctx, "c"))
+	require.False(t, m.Exists(DefaultKeyPrefix+"c"))
+	committed, err = s.Committed(ctx, "c")
+	require.NoError(t, err)
+	require.False(t, committed)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil)
+	require.EqualError(t, err, "redis client can not be nil")
+	_, err = New(redis.NewClient(&redis.Options{}), WithKeyPrefix(""))
+	require.EqualError(t, err, "redis key prefix can not be empty")
+}
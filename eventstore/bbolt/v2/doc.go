@@ -0,0 +1,8 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bbolt implements an eventstore.Store persisting the events in an
+// embedded bbolt (https://github.com/etcd-io/bbolt) database file.
+package bbolt
@@ -0,0 +1,202 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbolt
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/eventstore"
+)
+
+var streamsBucket = []byte("streams")
+
+// readBatchSize is the number of records loaded per read transaction, so
+// that slow ReadFuncs do not keep a transaction open.
+const readBatchSize = 256
+
+// Store is an eventstore.Store backed by a bbolt database.
+//
+// Each stream is a bucket whose keys are the big-endian sequences and values
+// are laid out as:
+//
+//	| append time (8 bytes) | event in JSON format |
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (or creates) the bbolt database at path.
+func Open(path string, options *bolt.Options) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, options)
+	if err != nil {
+		return nil, fmt.Errorf("eventstore: failed to open bbolt database: %w", err)
+	}
+	return New(db)
+}
+
+// New returns a Store using the given database. Closing the Store closes db.
+func New(db *bolt.DB) (*Store, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(streamsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("eventstore: failed to initialize bbolt database: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Append implements eventstore.Store.Append.
+func (s *Store) Append(ctx context.Context, stream string, events ...event.Event) (uint64, error) {
+	if stream == "" {
+		return 0, errors.New("eventstore: stream name can not be empty")
+	}
+	values := make([][]byte, 0, len(events))
+	for _, e := range events {
+		if err := e.Validate(); err != nil {
+			return 0, err
+		}
+		b, err := format.JSON.Marshal(&e)
+		if err != nil {
+			return 0, err
+		}
+		values = append(values, b)
+	}
+
+	var last uint64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(streamsBucket).CreateBucketIfNotExists([]byte(stream))
+		if err != nil {
+			return err
+		}
+		now := cecontext.ClockFrom(ctx).Now()
+		for _, b := range values {
+			seq, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(encodeSequence(seq), encodeValue(now, b)); err != nil {
+				return err
+			}
+			last = seq
+		}
+		if len(values) == 0 {
+			last = bucket.Sequence()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: failed to append events: %w", err)
+	}
+	return last, nil
+}
+
+// Read implements eventstore.Store.Read.
+func (s *Store) Read(ctx context.Context, stream string, q eventstore.Query, fn eventstore.ReadFunc) error {
+	next := q.FromSequence
+	read := 0
+	for {
+		batch, done, err := s.readBatch(stream, q, next)
+		if err != nil {
+			return err
+		}
+		for _, r := range batch {
+			if q.Limit > 0 && read >= q.Limit {
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(ctx, r); err != nil {
+				if errors.Is(err, eventstore.ErrStop) {
+					return nil
+				}
+				return err
+			}
+			read++
+		}
+		if done {
+			return nil
+		}
+		next = batch[len(batch)-1].Sequence + 1
+	}
+}
+
+// readBatch loads up to readBatchSize records matching q starting at
+// sequence from, and reports whether the end of the stream was reached.
+func (s *Store) readBatch(stream string, q eventstore.Query, from uint64) ([]eventstore.Record, bool, error) {
+	var records []eventstore.Record
+	done := true
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(streamsBucket).Bucket([]byte(stream))
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		for k, v := c.Seek(encodeSequence(from)); k != nil; k, v = c.Next() {
+			if len(records) == readBatchSize {
+				done = false
+				return nil
+			}
+			r, err := decodeRecord(stream, k, v)
+			if err != nil {
+				return err
+			}
+			if q.Matches(r) {
+				records = append(records, r)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("eventstore: failed to read stream %q: %w", stream, err)
+	}
+	return records, done, nil
+}
+
+// Close implements eventstore.Store.Close.
+func (s *Store) Close(ctx context.Context) error {
+	return s.db.Close()
+}
+
+func encodeSequence(seq uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, seq)
+	return b
+}
+
+func encodeValue(appended time.Time, data []byte) []byte {
+	b := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint64(b[0:8], uint64(appended.UnixNano()))
+	copy(b[8:], data)
+	return b
+}
+
+func decodeRecord(stream string, k, v []byte) (eventstore.Record, error) {
+	if len(k) != 8 || len(v) < 8 {
+		return eventstore.Record{}, errors.New("malformed record")
+	}
+	e := event.New()
+	if err := format.JSON.Unmarshal(v[8:], &e); err != nil {
+		return eventstore.Record{}, err
+	}
+	return eventstore.Record{
+		Stream:   stream,
+		Sequence: binary.BigEndian.Uint64(k),
+		Appended: time.Unix(0, int64(binary.BigEndian.Uint64(v[0:8]))),
+		Event:    e,
+	}, nil
+}
+
+var _ eventstore.Store = (*Store)(nil)
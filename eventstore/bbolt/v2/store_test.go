@@ -0,0 +1,55 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/eventstore"
+	"github.com/cloudevents/sdk-go/v2/eventstore/test"
+)
+
+func TestStore(t *testing.T) {
+	test.RunStoreTests(t, func(t *testing.T) eventstore.Store {
+		s, err := Open(filepath.Join(t.TempDir(), "events.db"), nil)
+		require.NoError(t, err)
+		return s
+	})
+}
+
+func TestStoreReadsInBatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.db")
+	s, err := Open(path, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	events := test.Events(readBatchSize*2 + 10)
+	_, err = s.Append(ctx, "s", events...)
+	require.NoError(t, err)
+	require.NoError(t, s.Close(ctx))
+
+	// Reopen to verify the events are persisted.
+	s, err = Open(path, nil)
+	require.NoError(t, err)
+	defer s.Close(ctx)
+
+	var count int
+	require.NoError(t, s.Read(ctx, "s", eventstore.Query{FromSequence: 5}, func(ctx context.Context, r eventstore.Record) error {
+		require.Equal(t, uint64(5+count), r.Sequence)
+		require.Equal(t, events[r.Sequence-1].ID(), r.Event.ID())
+		count++
+		return nil
+	}))
+	require.Equal(t, len(events)-4, count)
+
+	seq, err := s.Append(ctx, "s", events[0])
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(events)+1), seq)
+}
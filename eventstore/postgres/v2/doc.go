@@ -0,0 +1,10 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package postgres implements an eventstore.Store persisting the events in a
+// PostgreSQL table. It only depends on database/sql: the caller registers the
+// driver of its choice (e.g. github.com/jackc/pgx/v5/stdlib or
+// github.com/lib/pq) and opens the *sql.DB.
+package postgres
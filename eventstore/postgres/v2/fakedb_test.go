@@ -0,0 +1,130 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/test/sqltest"
+)
+
+// fakeDB is a table of events, served by connections understanding the
+// statements of the package.
+type fakeDB struct {
+	mu      sync.Mutex
+	streams map[string][]fakeRow
+	// lock is the advisory lock serializing the appends.
+	lock sync.Mutex
+}
+
+type fakeRow struct {
+	sequence int64
+	appended time.Time
+	time     time.Time
+	event    string
+}
+
+// newFakeDB returns a *sql.DB connected to an empty fakeDB.
+func newFakeDB(t *testing.T) *sql.DB {
+	f := &fakeDB{streams: map[string][]fakeRow{}}
+	return sqltest.Open(t, func() sqltest.Conn { return &fakeConn{db: f} })
+}
+
+// fakeConn buffers the inserts of its transaction.
+type fakeConn struct {
+	db      *fakeDB
+	stream  string
+	pending []fakeRow
+	locked  bool
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.pending = nil
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	f := c.db
+	f.mu.Lock()
+	f.streams[c.stream] = append(f.streams[c.stream], c.pending...)
+	f.mu.Unlock()
+	return c.Rollback()
+}
+
+func (c *fakeConn) Rollback() error {
+	c.pending = nil
+	if c.locked {
+		c.locked = false
+		c.db.lock.Unlock()
+	}
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query = strings.TrimSpace(query)
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+	case strings.HasPrefix(query, "SELECT pg_advisory_xact_lock"):
+		c.db.lock.Lock()
+		c.locked = true
+	case strings.HasPrefix(query, "INSERT INTO"):
+		c.stream = args[0].Value.(string)
+		c.pending = append(c.pending, fakeRow{
+			sequence: args[1].Value.(int64),
+			appended: args[2].Value.(time.Time),
+			time:     args[3].Value.(time.Time),
+			event:    args[4].Value.(string),
+		})
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rows := f.streams[args[0].Value.(string)]
+	switch {
+	case strings.HasPrefix(query, "SELECT COALESCE(MAX(sequence), 0)"):
+		var last int64
+		if len(rows) > 0 {
+			last = rows[len(rows)-1].sequence
+		}
+		return sqltest.Rows([]string{"max"}, []driver.Value{last}), nil
+	case strings.HasPrefix(query, "SELECT sequence, appended, event"):
+		from := args[1].Value.(int64)
+		next := 2
+		var since, until time.Time
+		if strings.Contains(query, "time >= $") {
+			since = args[next].Value.(time.Time)
+			next++
+		}
+		if strings.Contains(query, "time < $") {
+			until = args[next].Value.(time.Time)
+		}
+		var values [][]driver.Value
+		for _, r := range rows {
+			if r.sequence < from || (!since.IsZero() && r.time.Before(since)) || (!until.IsZero() && !r.time.Before(until)) {
+				continue
+			}
+			if len(values) == readBatchSize {
+				break
+			}
+			values = append(values, []driver.Value{r.sequence, r.appended, []byte(r.event)})
+		}
+		return sqltest.Rows([]string{"sequence", "appended", "event"}, values...), nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
@@ -0,0 +1,210 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/eventstore"
+)
+
+// readBatchSize is the number of rows fetched per query, so that slow
+// ReadFuncs do not keep a connection busy.
+const readBatchSize = 256
+
+// Store is an eventstore.Store backed by a PostgreSQL table, see CreateTable
+// for its layout.
+type Store struct {
+	db    *sql.DB
+	table string
+}
+
+// New returns a Store using db. The table must exist, see CreateTable.
+// Closing the Store closes db.
+func New(db *sql.DB, opts ...Option) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("postgres db can not be nil")
+	}
+	s := &Store{db: db, table: DefaultTable}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// CreateTable creates the table storing the events, if it does not exist.
+//
+// The time column holds the time used to filter the events in a time range,
+// see eventstore.Record.Time.
+func (s *Store) CreateTable(ctx context.Context) error {
+	index := strings.ReplaceAll(s.table, ".", "_") + "_time_idx"
+	_, err := s.db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS `+s.table+` (
+	stream   TEXT        NOT NULL,
+	sequence BIGINT      NOT NULL,
+	appended TIMESTAMPTZ NOT NULL,
+	time     TIMESTAMPTZ NOT NULL,
+	event    JSONB       NOT NULL,
+	PRIMARY KEY (stream, sequence)
+);
+CREATE INDEX IF NOT EXISTS `+index+` ON `+s.table+` (stream, time);`)
+	if err != nil {
+		return fmt.Errorf("eventstore: failed to create table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// Append implements eventstore.Store.Append.
+func (s *Store) Append(ctx context.Context, stream string, events ...event.Event) (uint64, error) {
+	if stream == "" {
+		return 0, errors.New("eventstore: stream name can not be empty")
+	}
+	values := make([]string, 0, len(events))
+	for _, e := range events {
+		if err := e.Validate(); err != nil {
+			return 0, err
+		}
+		b, err := format.JSON.Marshal(&e)
+		if err != nil {
+			return 0, err
+		}
+		values = append(values, string(b))
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: failed to append events: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Serialize the appends to the same stream until the end of the
+	// transaction, so that the sequences have no gaps.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, stream); err != nil {
+		return 0, fmt.Errorf("eventstore: failed to lock stream %q: %w", stream, err)
+	}
+	var last uint64
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM `+s.table+` WHERE stream = $1`, stream).Scan(&last)
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: failed to read stream %q: %w", stream, err)
+	}
+
+	insert, err := tx.PrepareContext(ctx, `INSERT INTO `+s.table+` (stream, sequence, appended, time, event) VALUES ($1, $2, $3, $4, $5)`)
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: failed to append events: %w", err)
+	}
+	defer insert.Close()
+	now := cecontext.ClockFrom(ctx).Now().UTC()
+	for i, v := range values {
+		last++
+		t := events[i].Time()
+		if t.IsZero() {
+			t = now
+		}
+		if _, err := insert.ExecContext(ctx, stream, last, now, t.UTC(), v); err != nil {
+			return 0, fmt.Errorf("eventstore: failed to append events: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("eventstore: failed to append events: %w", err)
+	}
+	return last, nil
+}
+
+// Read implements eventstore.Store.Read.
+func (s *Store) Read(ctx context.Context, stream string, q eventstore.Query, fn eventstore.ReadFunc) error {
+	next := q.FromSequence
+	read := 0
+	for {
+		batch, err := s.readBatch(ctx, stream, q, next)
+		if err != nil {
+			return err
+		}
+		for _, r := range batch {
+			if q.Limit > 0 && read >= q.Limit {
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(ctx, r); err != nil {
+				if errors.Is(err, eventstore.ErrStop) {
+					return nil
+				}
+				return err
+			}
+			read++
+		}
+		if len(batch) < readBatchSize {
+			return nil
+		}
+		next = batch[len(batch)-1].Sequence + 1
+	}
+}
+
+// readBatch loads up to readBatchSize records matching q starting at
+// sequence from.
+func (s *Store) readBatch(ctx context.Context, stream string, q eventstore.Query, from uint64) ([]eventstore.Record, error) {
+	query := `SELECT sequence, appended, event FROM ` + s.table + ` WHERE stream = $1 AND sequence >= $2`
+	args := []interface{}{stream, from}
+	if !q.Since.IsZero() {
+		args = append(args, q.Since.UTC())
+		query += fmt.Sprintf(" AND time >= $%d", len(args))
+	}
+	if !q.Until.IsZero() {
+		args = append(args, q.Until.UTC())
+		query += fmt.Sprintf(" AND time < $%d", len(args))
+	}
+	query += fmt.Sprintf(" ORDER BY sequence LIMIT %d", readBatchSize)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("eventstore: failed to read stream %q: %w", stream, err)
+	}
+	defer rows.Close()
+
+	var records []eventstore.Record
+	for rows.Next() {
+		var (
+			seq      uint64
+			appended time.Time
+			data     []byte
+		)
+		if err := rows.Scan(&seq, &appended, &data); err != nil {
+			return nil, fmt.Errorf("eventstore: failed to read stream %q: %w", stream, err)
+		}
+		e := event.New()
+		if err := format.JSON.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("eventstore: failed to decode event at sequence %d: %w", seq, err)
+		}
+		records = append(records, eventstore.Record{
+			Stream:   stream,
+			Sequence: seq,
+			Appended: appended,
+			Event:    e,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("eventstore: failed to read stream %q: %w", stream, err)
+	}
+	return records, nil
+}
+
+// Close implements eventstore.Store.Close.
+func (s *Store) Close(ctx context.Context) error {
+	return s.db.Close()
+}
+
+var _ eventstore.Store = (*Store)(nil)
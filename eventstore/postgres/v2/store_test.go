@@ -0,0 +1,62 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/eventstore"
+	"github.com/cloudevents/sdk-go/v2/eventstore/test"
+)
+
+func TestStore(t *testing.T) {
+	test.RunStoreTests(t, func(t *testing.T) eventstore.Store {
+		db := newFakeDB(t)
+		s, err := New(db, WithTable("public.events"))
+		require.NoError(t, err)
+		require.NoError(t, s.CreateTable(context.Background()))
+		return s
+	})
+}
+
+func TestStoreReadsInBatches(t *testing.T) {
+	ctx := context.Background()
+	db := newFakeDB(t)
+	s, err := New(db)
+	require.NoError(t, err)
+
+	events := test.Events(readBatchSize*2 + 10)
+	_, err = s.Append(ctx, "s", events...)
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, s.Read(ctx, "s", eventstore.Query{FromSequence: 5}, func(ctx context.Context, r eventstore.Record) error {
+		require.Equal(t, uint64(5+count), r.Sequence)
+		require.Equal(t, events[r.Sequence-1].ID(), r.Event.ID())
+		count++
+		return nil
+	}))
+	require.Equal(t, len(events)-4, count)
+
+	seq, err := s.Append(ctx, "s", events[0])
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(events)+1), seq)
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New(nil)
+	require.Error(t, err)
+	db := newFakeDB(t)
+	_, err = New(db, WithTable("events; DROP TABLE users"))
+	require.Error(t, err)
+	s, err := New(db)
+	require.NoError(t, err)
+	_, err = s.Append(context.Background(), "", test.Events(1)...)
+	require.Error(t, err)
+}
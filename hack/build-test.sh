@@ -20,3 +20,15 @@ do
 
   popd
 done
+
+# The core module must keep compiling to WebAssembly, for the browsers and
+# the WASI plugins.
+pushd v2
+for target in js/wasm wasip1/wasm
+do
+  echo
+  echo --- Building v2 for ${target} ---
+  echo
+  GOOS="${target%/*}" GOARCH="${target#*/}" go build ./...
+done
+popd
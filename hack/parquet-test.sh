@@ -0,0 +1,48 @@
+#!/usr/bin/env bash
+
+# Copyright 2024 The CloudEvents Authors
+# SPDX-License-Identifier: Apache-2.0
+
+# Reads the golden files of v2/export/parquet with pyarrow, an independent
+# Parquet implementation, and checks them against testdata/events.json.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+python3 -m pip install --quiet pyarrow
+
+python3 - v2/export/parquet/testdata <<'PY'
+import datetime
+import json
+import pathlib
+import sys
+
+import pyarrow.parquet as pq
+
+testdata = pathlib.Path(sys.argv[1])
+golden = json.loads((testdata / "events.json").read_text())
+
+
+def normalize(v):
+    if isinstance(v, datetime.datetime):
+        if v.tzinfo is not None:
+            v = v.astimezone(datetime.timezone.utc).replace(tzinfo=None)
+        return v.strftime("%Y-%m-%dT%H:%M:%S.%fZ")
+    if isinstance(v, bytes):
+        return v.decode()
+    return v
+
+
+for path in sorted(testdata.glob("*.parquet")):
+    table = pq.read_table(path)
+    if table.num_rows != golden["rows"]:
+        sys.exit(f"{path}: {table.num_rows} rows, want {golden['rows']}")
+    if table.column_names != golden["columns"]:
+        sys.exit(f"{path}: columns {table.column_names}, want {golden['columns']}")
+    for name in table.column_names:
+        got = [normalize(v) for v in table.column(name).to_pylist()]
+        if got != golden["values"][name]:
+            sys.exit(f"{path}: column {name} is {got}, want {golden['values'][name]}")
+    print(f"{path}: ok")
+PY
@@ -25,10 +25,19 @@ MODULES=(
   "protocol/pubsub"
   "protocol/kafka_sarama"
   "protocol/ws"
+  "protocol/postgres"
+  "protocol/http/sigv4"
   "observability/opencensus"
   "observability/opentelemetry"
   "sql"
   "binding/format/protobuf"
+  "eventstore/bbolt"
+  "eventstore/postgres"
+  "dedup/redis"
+  "blobstore"
+  "resolver/kubernetes"
+  "webhook/postgres"
+  "config"
 )
 
 REPOINT=(
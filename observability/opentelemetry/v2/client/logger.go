@@ -0,0 +1,34 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/observability"
+)
+
+// ContextWithTraceLogger returns a context whose logger, as returned by
+// cecontext.LoggerFrom, adds the trace and span IDs of the span of ctx to the
+// log lines, so that they can be correlated with the traces. ctx is returned
+// as is if it has no valid span.
+//
+// The OTelObservabilityService applies it to the contexts of the spans it
+// starts, so the log lines of the client and the protocols carry the IDs.
+func ContextWithTraceLogger(ctx context.Context) context.Context {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx
+	}
+	logger := cecontext.LoggerFrom(ctx).With(
+		observability.TraceIDLogField, sc.TraceID().String(),
+		observability.SpanIDLogField, sc.SpanID().String(),
+	)
+	return cecontext.WithLogger(ctx, logger)
+}
@@ -27,6 +27,8 @@ type OTelObservabilityService struct {
 	tracer               trace.Tracer
 	spanAttributesGetter func(cloudevents.Event) []attribute.KeyValue
 	spanNameFormatter    func(cloudevents.Event) string
+	projection           *observability.Projection
+	projected            bool
 }
 
 // NewOTelObservabilityService returns an OpenTelemetry-enabled observability service
@@ -70,18 +72,19 @@ func (o OTelObservabilityService) RecordReceivedMalformedEvent(ctx context.Conte
 
 // RecordCallingInvoker starts a new span before calling the invoker upon a received event.
 // In case the operation fails, the error is recorded and the span is marked as failed.
+// The logger of the returned context adds the trace and span IDs to the log lines.
 func (o OTelObservabilityService) RecordCallingInvoker(ctx context.Context, event *cloudevents.Event) (context.Context, func(errOrResult error)) {
 	spanName := o.getSpanName(event, "process")
 	ctx, span := o.tracer.Start(
 		ctx, spanName,
 		trace.WithSpanKind(trace.SpanKindConsumer),
-		trace.WithAttributes(GetDefaultSpanAttributes(event, getFuncName())...))
+		trace.WithAttributes(o.spanAttributes(event, getFuncName())...))
 
 	if span.IsRecording() && o.spanAttributesGetter != nil {
 		span.SetAttributes(o.spanAttributesGetter(*event)...)
 	}
 
-	return ctx, func(errOrResult error) {
+	return ContextWithTraceLogger(ctx), func(errOrResult error) {
 		recordSpanError(span, errOrResult)
 		span.End()
 	}
@@ -89,19 +92,20 @@ func (o OTelObservabilityService) RecordCallingInvoker(ctx context.Context, even
 
 // RecordSendingEvent starts a new span before sending the event.
 // In case the operation fails, the error is recorded and the span is marked as failed.
+// The logger of the returned context adds the trace and span IDs to the log lines.
 func (o OTelObservabilityService) RecordSendingEvent(ctx context.Context, event cloudevents.Event) (context.Context, func(errOrResult error)) {
 	spanName := o.getSpanName(&event, "send")
 
 	ctx, span := o.tracer.Start(
 		ctx, spanName,
 		trace.WithSpanKind(trace.SpanKindProducer),
-		trace.WithAttributes(GetDefaultSpanAttributes(&event, getFuncName())...))
+		trace.WithAttributes(o.spanAttributes(&event, getFuncName())...))
 
 	if span.IsRecording() && o.spanAttributesGetter != nil {
 		span.SetAttributes(o.spanAttributesGetter(event)...)
 	}
 
-	return ctx, func(errOrResult error) {
+	return ContextWithTraceLogger(ctx), func(errOrResult error) {
 		recordSpanError(span, errOrResult)
 		span.End()
 	}
@@ -109,19 +113,20 @@ func (o OTelObservabilityService) RecordSendingEvent(ctx context.Context, event
 
 // RecordRequestEvent starts a new span before transmitting the given request.
 // In case the operation fails, the error is recorded and the span is marked as failed.
+// The logger of the returned context adds the trace and span IDs to the log lines.
 func (o OTelObservabilityService) RecordRequestEvent(ctx context.Context, event cloudevents.Event) (context.Context, func(errOrResult error, event *cloudevents.Event)) {
 	spanName := o.getSpanName(&event, "send")
 
 	ctx, span := o.tracer.Start(
 		ctx, spanName,
 		trace.WithSpanKind(trace.SpanKindProducer),
-		trace.WithAttributes(GetDefaultSpanAttributes(&event, getFuncName())...))
+		trace.WithAttributes(o.spanAttributes(&event, getFuncName())...))
 
 	if span.IsRecording() && o.spanAttributesGetter != nil {
 		span.SetAttributes(o.spanAttributesGetter(event)...)
 	}
 
-	return ctx, func(errOrResult error, event *cloudevents.Event) {
+	return ContextWithTraceLogger(ctx), func(errOrResult error, event *cloudevents.Event) {
 		recordSpanError(span, errOrResult)
 		span.End()
 	}
@@ -146,6 +151,21 @@ func GetDefaultSpanAttributes(e *cloudevents.Event, method string) []attribute.K
 	return attr
 }
 
+// spanAttributes returns the attributes of the spans of e, the default ones
+// unless a projection is set with WithAttributeProjection.
+func (o OTelObservabilityService) spanAttributes(e *cloudevents.Event, method string) []attribute.KeyValue {
+	if !o.projected {
+		return GetDefaultSpanAttributes(e, method)
+	}
+	projected := o.projection.Attributes(*e)
+	attr := make([]attribute.KeyValue, 0, len(projected)+1)
+	attr = append(attr, attribute.String(string(semconv.CodeFunctionKey), method))
+	for _, a := range projected {
+		attr = append(attr, attribute.String(a.Key, a.Value))
+	}
+	return attr
+}
+
 // Extracts the traceparent from the msg and enriches the context to enable propagation
 func tracePropagatorContextDecorator(ctx context.Context, msg binding.Message) context.Context {
 	var messageCtx context.Context
@@ -162,7 +182,7 @@ func tracePropagatorContextDecorator(ctx context.Context, msg binding.Message) c
 	if span == nil {
 		return ctx
 	}
-	return trace.ContextWithSpan(ctx, span)
+	return ContextWithTraceLogger(trace.ContextWithSpan(ctx, span))
 }
 
 func recordSpanError(span trace.Span, errOrResult error) {
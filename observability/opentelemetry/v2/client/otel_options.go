@@ -37,6 +37,16 @@ func WithSpanNameFormatter(nameFormatter func(cloudevents.Event) string) OTelObs
 	}
 }
 
+// WithAttributeProjection replaces the event attributes added to the spans
+// by the ones selected by p, e.g. to hash a tenant extension or to leave the id
+// out. A nil p adds all the attributes and extensions.
+func WithAttributeProjection(p *observability.Projection) OTelObservabilityServiceOption {
+	return func(os *OTelObservabilityService) {
+		os.projection = p
+		os.projected = true
+	}
+}
+
 var defaultSpanNameFormatter func(cloudevents.Event) string = func(e cloudevents.Event) string {
 	return observability.ClientSpanName + "." + e.Context.GetType()
 }
@@ -0,0 +1,102 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package metrics implements the observability.Metrics interface on top of
+// an OpenTelemetry meter.
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/cloudevents/sdk-go/v2/observability"
+)
+
+const instrumentationName = "github.com/cloudevents/sdk-go/observability/opentelemetry/v2"
+
+// Metrics records the measurements of the protocol bindings as OpenTelemetry
+// instruments. Durations are recorded in seconds.
+type Metrics struct {
+	meter metric.Meter
+
+	mu         sync.Mutex
+	counters   map[string]metric.Int64Counter
+	gauges     map[string]metric.Int64Gauge
+	histograms map[string]metric.Float64Histogram
+}
+
+// New returns a Metrics creating its instruments with meter. If meter is
+// nil, the meter is obtained from the global MeterProvider.
+func New(meter metric.Meter) *Metrics {
+	if meter == nil {
+		meter = otel.GetMeterProvider().Meter(instrumentationName)
+	}
+	return &Metrics{
+		meter:      meter,
+		counters:   make(map[string]metric.Int64Counter),
+		gauges:     make(map[string]metric.Int64Gauge),
+		histograms: make(map[string]metric.Float64Histogram),
+	}
+}
+
+// AddCounter implements observability.Metrics.AddCounter.
+func (m *Metrics) AddCounter(name string, delta int64, attrs ...observability.Attribute) {
+	m.mu.Lock()
+	c, ok := m.counters[name]
+	if !ok {
+		var err error
+		if c, err = m.meter.Int64Counter(name); err != nil {
+			otel.Handle(err)
+		}
+		m.counters[name] = c
+	}
+	m.mu.Unlock()
+	c.Add(context.Background(), delta, metric.WithAttributes(toAttributes(attrs)...))
+}
+
+// SetGauge implements observability.Metrics.SetGauge.
+func (m *Metrics) SetGauge(name string, value int64, attrs ...observability.Attribute) {
+	m.mu.Lock()
+	g, ok := m.gauges[name]
+	if !ok {
+		var err error
+		if g, err = m.meter.Int64Gauge(name); err != nil {
+			otel.Handle(err)
+		}
+		m.gauges[name] = g
+	}
+	m.mu.Unlock()
+	g.Record(context.Background(), value, metric.WithAttributes(toAttributes(attrs)...))
+}
+
+// RecordDuration implements observability.Metrics.RecordDuration.
+func (m *Metrics) RecordDuration(name string, d time.Duration, attrs ...observability.Attribute) {
+	m.mu.Lock()
+	h, ok := m.histograms[name]
+	if !ok {
+		var err error
+		if h, err = m.meter.Float64Histogram(name, metric.WithUnit("s")); err != nil {
+			otel.Handle(err)
+		}
+		m.histograms[name] = h
+	}
+	m.mu.Unlock()
+	h.Record(context.Background(), d.Seconds(), metric.WithAttributes(toAttributes(attrs)...))
+}
+
+func toAttributes(attrs []observability.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, len(attrs))
+	for i, a := range attrs {
+		kvs[i] = attribute.String(a.Key, a.Value)
+	}
+	return kvs
+}
+
+var _ observability.Metrics = (*Metrics)(nil)
@@ -0,0 +1,96 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Azure/go-amqp"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// creditFlow issues the credit of the receiver link, see WithReceiverCredit.
+type creditFlow struct {
+	credit uint32
+
+	mu     sync.Mutex
+	link   *amqp.Receiver
+	paused bool
+	// owed is the credit of the messages received while paused.
+	owed uint32
+}
+
+// attach issues the credit of a new receiver link, replacing the previous
+// one.
+func (f *creditFlow) attach(link *amqp.Receiver) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.link = link
+	if f.paused {
+		f.owed = f.credit
+		return nil
+	}
+	f.owed = 0
+	return link.IssueCredit(f.credit)
+}
+
+// received issues the credit of a message received on link, unless paused.
+func (f *creditFlow) received(link *amqp.Receiver) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if link != f.link {
+		// The link was replaced when reconnecting.
+		return nil
+	}
+	if f.paused {
+		f.owed++
+		return nil
+	}
+	return link.IssueCredit(1)
+}
+
+func (f *creditFlow) pause() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = true
+}
+
+func (f *creditFlow) resume() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = false
+	if f.owed == 0 || f.link == nil {
+		return nil
+	}
+	owed := f.owed
+	f.owed = 0
+	return f.link.IssueCredit(owed)
+}
+
+// Pause implements protocol.Pauser.Pause: the receiver link stops issuing
+// credit, so that the broker stops delivering messages once the credit
+// already granted, at most the one set with WithReceiverCredit, is used.
+// Without WithReceiverCredit, it does nothing: the link manages its credit
+// itself.
+func (t *Protocol) Pause(ctx context.Context) error {
+	if t.flow != nil {
+		t.flow.pause()
+	}
+	return nil
+}
+
+// Resume implements protocol.Pauser.Resume: the receiver link issues the
+// credit of the messages received while paused.
+func (t *Protocol) Resume(ctx context.Context) error {
+	if t.flow != nil {
+		return t.flow.resume()
+	}
+	return nil
+}
+
+var _ protocol.Pauser = (*Protocol)(nil)
@@ -0,0 +1,57 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// nextCredit returns the link credit of the next flow of the receiver link.
+func (c *brokerConn) nextCredit(t *testing.T) uint32 {
+	select {
+	case credit := <-c.credits:
+		return credit
+	case <-time.After(5 * time.Second):
+		t.Fatal("the receiver link issued no credit")
+		return 0
+	}
+}
+
+func TestReceiverCredit(t *testing.T) {
+	b := newFakeBroker(t)
+	p, err := NewReceiverProtocol(b.url(), "queue", nil, nil, WithReceiverCredit(2))
+	require.NoError(t, err)
+	defer p.Close(context.Background())
+	ctx := context.Background()
+
+	conn := b.next()
+	require.Equal(t, uint32(2), conn.nextCredit(t))
+	conn.deliver(t, "a")
+	requireData(t, receive(ctx, p), "a")
+	// The credit of the message received is issued again.
+	require.Equal(t, uint32(2), conn.nextCredit(t))
+
+	// While paused, the credit of the messages received is not issued.
+	require.NoError(t, p.Pause(ctx))
+	conn.deliver(t, "b")
+	requireData(t, receive(ctx, p), "b")
+	select {
+	case credit := <-conn.credits:
+		t.Fatalf("the paused receiver link issued a credit of %d", credit)
+	case <-time.After(100 * time.Millisecond):
+	}
+	require.NoError(t, p.Resume(ctx))
+	require.Equal(t, uint32(2), conn.nextCredit(t))
+}
+
+func TestReceiverCreditInvalid(t *testing.T) {
+	_, err := NewReceiverProtocolFromClient(nil, nil, "queue", WithReceiverCredit(0))
+	require.EqualError(t, err, "the receiver credit must be positive")
+}
@@ -0,0 +1,236 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Azure/go-amqp"
+
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// Section is a section of an AMQP message holding CloudEvents attributes.
+type Section int
+
+const (
+	// ApplicationProperties is the application-properties section.
+	ApplicationProperties Section = iota
+	// MessageAnnotations is the message-annotations section.
+	MessageAnnotations
+	// PropertiesSection is the properties section. Only the id (message-id),
+	// subject (subject) and time (creation-time) attributes can be mapped to
+	// it. The datacontenttype attribute is always mapped to content-type.
+	PropertiesSection
+)
+
+// Mapping configures how the CloudEvents attributes and extensions are mapped
+// onto the sections of an AMQP message, since brokers have different
+// conventions. The zero value is the mapping of the CloudEvents AMQP binding:
+// all the attributes and extensions are application properties prefixed with
+// "cloudEvents:".
+type Mapping struct {
+	// Prefix is the prefix of the attribute and extension names in the
+	// application properties and message annotations. Defaults to "cloudEvents:".
+	Prefix string
+
+	// Sections maps attribute and extension names to the section holding them.
+	// The ones not listed are application properties.
+	Sections map[string]Section
+
+	// AnnotationExtension, if not nil, is invoked when reading a message with
+	// the key of each message annotation not holding an attribute or extension,
+	// e.g. "x-opt-enqueued-time" set by Azure Service Bus. It returns the name
+	// of the extension surfacing the annotation, or false to ignore it.
+	AnnotationExtension func(key string) (string, bool)
+}
+
+// SanitizedAnnotationExtension can be used as Mapping.AnnotationExtension to
+// surface all the message annotations as extensions, named after their key
+// lowercased and stripped of the characters not allowed in extension names.
+func SanitizedAnnotationExtension(key string) (string, bool) {
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), b.Len() > 0
+}
+
+// mapping is a Mapping with its defaults applied.
+type mapping struct {
+	prefix              string
+	specs               *spec.Versions
+	sections            map[string]Section
+	annotationExtension func(key string) (string, bool)
+}
+
+var defaultMapping = newMapping(Mapping{})
+
+func newMapping(m Mapping) *mapping {
+	c := &mapping{
+		prefix:              m.Prefix,
+		specs:               specs,
+		sections:            make(map[string]Section, len(m.Sections)),
+		annotationExtension: m.AnnotationExtension,
+	}
+	if c.prefix == "" {
+		c.prefix = prefix
+	} else if c.prefix != prefix {
+		c.specs = spec.WithPrefix(c.prefix)
+	}
+	for name, section := range m.Sections {
+		c.sections[strings.ToLower(name)] = section
+	}
+	return c
+}
+
+func (m *mapping) section(name string) Section {
+	section := m.sections[name]
+	if section == PropertiesSection && !inPropertiesSection(name) {
+		return ApplicationProperties
+	}
+	return section
+}
+
+func inPropertiesSection(name string) bool {
+	return name == "id" || name == "subject" || name == "time"
+}
+
+// specVersion returns the spec version of message, if it is a binary message.
+func (m *mapping) specVersion(message *amqp.Message) spec.Version {
+	var sv interface{}
+	key := m.prefix + "specversion"
+	if m.section("specversion") == MessageAnnotations {
+		sv = annotation(message.Annotations, key)
+	} else {
+		sv = message.ApplicationProperties[key]
+	}
+	if svs, ok := sv.(string); ok {
+		return m.specs.Version(svs)
+	}
+	return nil
+}
+
+// get returns the value of the attribute or extension name in message.
+func (m *mapping) get(message *amqp.Message, name string) interface{} {
+	switch m.section(name) {
+	case MessageAnnotations:
+		return annotation(message.Annotations, m.prefix+name)
+	case PropertiesSection:
+		if message.Properties == nil {
+			return nil
+		}
+		switch name {
+		case "id":
+			if message.Properties.MessageID == nil {
+				return nil
+			}
+			if s, ok := message.Properties.MessageID.(string); ok {
+				return s
+			}
+			return fmt.Sprint(message.Properties.MessageID)
+		case "subject":
+			if message.Properties.Subject != nil {
+				return *message.Properties.Subject
+			}
+		case "time":
+			if message.Properties.CreationTime != nil {
+				return *message.Properties.CreationTime
+			}
+		}
+		return nil
+	default:
+		return message.ApplicationProperties[m.prefix+name]
+	}
+}
+
+// set sets the value of the attribute or extension name in message, or
+// removes it if value is nil.
+func (m *mapping) set(message *amqp.Message, name string, value interface{}) error {
+	section := m.section(name)
+	if value == nil {
+		switch section {
+		case MessageAnnotations:
+			delete(message.Annotations, m.prefix+name)
+		case PropertiesSection:
+			switch name {
+			case "id":
+				message.Properties.MessageID = nil
+			case "subject":
+				message.Properties.Subject = nil
+			case "time":
+				message.Properties.CreationTime = nil
+			}
+		default:
+			delete(message.ApplicationProperties, m.prefix+name)
+		}
+		return nil
+	}
+
+	v, err := safeAMQPPropertiesUnwrap(value)
+	if err != nil {
+		return err
+	}
+	switch section {
+	case MessageAnnotations:
+		if message.Annotations == nil {
+			message.Annotations = make(amqp.Annotations)
+		}
+		message.Annotations[m.prefix+name] = v
+	case PropertiesSection:
+		switch name {
+		case "id":
+			s, err := types.ToString(value)
+			if err != nil {
+				return err
+			}
+			message.Properties.MessageID = s
+		case "subject":
+			s, err := types.ToString(value)
+			if err != nil {
+				return err
+			}
+			message.Properties.Subject = &s
+		case "time":
+			t, err := types.ToTime(value)
+			if err != nil {
+				return err
+			}
+			message.Properties.CreationTime = &t
+		}
+	default:
+		message.ApplicationProperties[m.prefix+name] = v
+	}
+	return nil
+}
+
+// annotation returns the value of the message annotation key. The keys of
+// the received annotations are symbols, while the keys set by the
+// application are usually strings.
+func annotation(annotations amqp.Annotations, key string) interface{} {
+	if v, ok := annotations[key]; ok {
+		return v
+	}
+	for k, v := range annotations {
+		if s, ok := annotationKey(k); ok && s == key {
+			return v
+		}
+	}
+	return nil
+}
+
+// annotationKey returns the string form of an annotation key.
+func annotationKey(k interface{}) (string, bool) {
+	if v := reflect.ValueOf(k); v.Kind() == reflect.String {
+		return v.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,118 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-amqp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/types"
+	. "github.com/cloudevents/sdk-go/v2/test"
+)
+
+// roundTrip encodes and decodes message, as if it was sent to a broker.
+func roundTrip(t *testing.T, message *amqp.Message) *amqp.Message {
+	b, err := message.MarshalBinary()
+	require.NoError(t, err)
+	var got amqp.Message
+	require.NoError(t, got.UnmarshalBinary(b))
+	return &got
+}
+
+func TestMapping(t *testing.T) {
+	mapping := Mapping{
+		Prefix: "cloudEvents_",
+		Sections: map[string]Section{
+			"id":          PropertiesSection,
+			"time":        PropertiesSection,
+			"subject":     PropertiesSection,
+			"specversion": MessageAnnotations,
+			"type":        MessageAnnotations,
+			"exbool":      MessageAnnotations,
+			// Not supported in the properties section.
+			"source": PropertiesSection,
+		},
+	}
+
+	eventIn := FullEvent()
+	// URL extensions are read back as strings.
+	eventIn.SetExtension("exurl", nil)
+	ctx := binding.WithForceBinary(context.Background())
+	message := &amqp.Message{}
+	require.NoError(t, WriteMessageWithMapping(ctx, binding.ToMessage(&eventIn), message, mapping))
+
+	require.Equal(t, eventIn.ID(), message.Properties.MessageID)
+	require.Equal(t, eventIn.Subject(), *message.Properties.Subject)
+	require.True(t, eventIn.Time().Equal(*message.Properties.CreationTime))
+	require.Equal(t, eventIn.Type(), message.Annotations["cloudEvents_type"])
+	require.Equal(t, eventIn.SpecVersion(), message.Annotations["cloudEvents_specversion"])
+	require.Equal(t, true, message.Annotations["cloudEvents_exbool"])
+	require.Equal(t, eventIn.Source(), message.ApplicationProperties["cloudEvents_source"])
+	require.NotContains(t, message.ApplicationProperties, "cloudEvents_id")
+	require.NotContains(t, message.ApplicationProperties, "cloudEvents_type")
+
+	received := roundTrip(t, message)
+	got := NewMessageWithMapping(received, nil, mapping)
+	require.Equal(t, binding.EncodingBinary, got.ReadEncoding())
+	_, typ := got.GetAttribute(spec.Type)
+	require.Equal(t, eventIn.Type(), typ)
+	require.Equal(t, true, got.GetExtension("exbool"))
+
+	eventOut, err := binding.ToEvent(ctx, got)
+	require.NoError(t, err)
+	AssertEventEquals(t, eventIn, *eventOut)
+
+	// The default mapping does not find the spec version in the annotations.
+	require.Equal(t, binding.EncodingUnknown, NewMessage(received, nil).ReadEncoding())
+}
+
+func TestMappingAnnotationExtension(t *testing.T) {
+	eventIn := MinEvent()
+	message := &amqp.Message{}
+	require.NoError(t, WriteMessage(binding.WithForceBinary(context.Background()), binding.ToMessage(&eventIn), message))
+	enqueued := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	message.Annotations = amqp.Annotations{
+		"x-opt-enqueued-time":   enqueued,
+		"x-opt-sequence-number": int64(42),
+		"x-opt-partition-id":    []string{"unsupported"},
+	}
+	received := roundTrip(t, message)
+
+	eventOut, err := binding.ToEvent(context.Background(), NewMessage(received, nil))
+	require.NoError(t, err)
+	require.Empty(t, eventOut.Extensions())
+
+	mapping := Mapping{AnnotationExtension: func(key string) (string, bool) {
+		if key == "x-opt-partition-id" {
+			return "", false
+		}
+		return SanitizedAnnotationExtension(key)
+	}}
+	eventOut, err = binding.ToEvent(context.Background(), NewMessageWithMapping(received, nil, mapping))
+	require.NoError(t, err)
+	require.Len(t, eventOut.Extensions(), 2)
+	gotTime, err := types.ToTime(eventOut.Extensions()["xoptenqueuedtime"])
+	require.NoError(t, err)
+	require.True(t, enqueued.Equal(gotTime))
+	gotSeq, err := types.ToInteger(eventOut.Extensions()["xoptsequencenumber"])
+	require.NoError(t, err)
+	require.Equal(t, int32(42), gotSeq)
+}
+
+func TestSanitizedAnnotationExtension(t *testing.T) {
+	name, ok := SanitizedAnnotationExtension("x-opt-Enqueued-Time")
+	require.True(t, ok)
+	require.Equal(t, "xoptenqueuedtime", name)
+
+	_, ok = SanitizedAnnotationExtension("-_-")
+	require.False(t, ok)
+}
@@ -8,6 +8,7 @@ package amqp
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/format"
 	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/types"
 )
 
 const prefix = "cloudEvents:" // Name prefix for AMQP properties that hold CE attributes.
@@ -34,20 +36,32 @@ type Message struct {
 
 	version spec.Version
 	format  format.Format
+	mapping *mapping
 }
 
 // NewMessage wrap an *amqp.Message in a binding.Message.
 // The returned message *can* be read several times safely
 func NewMessage(message *amqp.Message, receiver *amqp.Receiver) *Message {
+	return newMessage(message, receiver, defaultMapping)
+}
+
+// NewMessageWithMapping wraps an *amqp.Message in a binding.Message, reading
+// the attributes and extensions as configured by mapping.
+// The returned message *can* be read several times safely
+func NewMessageWithMapping(message *amqp.Message, receiver *amqp.Receiver, mapping Mapping) *Message {
+	return newMessage(message, receiver, newMapping(mapping))
+}
+
+func newMessage(message *amqp.Message, receiver *amqp.Receiver, mapping *mapping) *Message {
 	var vn spec.Version
 	var fmt format.Format
 	if message.Properties != nil && message.Properties.ContentType != nil &&
 		format.IsFormat(*message.Properties.ContentType) {
 		fmt = format.Lookup(*message.Properties.ContentType)
-	} else if sv := getSpecVersion(message); sv != nil {
+	} else if sv := mapping.specVersion(message); sv != nil {
 		vn = sv
 	}
-	return &Message{AMQP: message, AMQPrcv: receiver, format: fmt, version: vn}
+	return &Message{AMQP: message, AMQPrcv: receiver, format: fmt, version: vn, mapping: mapping}
 }
 
 var (
@@ -55,15 +69,6 @@ var (
 	_ binding.MessageMetadataReader = (*Message)(nil)
 )
 
-func getSpecVersion(message *amqp.Message) spec.Version {
-	if sv, ok := message.ApplicationProperties[specs.PrefixedSpecVersionName()]; ok {
-		if svs, ok := sv.(string); ok {
-			return specs.Version(svs)
-		}
-	}
-	return nil
-}
-
 func (m *Message) ReadEncoding() binding.Encoding {
 	if m.version != nil {
 		return binding.EncodingBinary
@@ -95,13 +100,35 @@ func (m *Message) ReadBinary(ctx context.Context, encoder binding.BinaryWriter)
 		}
 	}
 
+	for name, section := range m.mapping.sections {
+		if section != PropertiesSection || !inPropertiesSection(name) {
+			continue
+		}
+		if v := m.mapping.get(m.AMQP, name); v != nil {
+			if err = encoder.SetAttribute(m.version.Attribute(m.mapping.prefix+name), v); err != nil {
+				return err
+			}
+		}
+	}
+
 	for k, v := range m.AMQP.ApplicationProperties {
-		if strings.HasPrefix(k, prefix) {
-			attr := m.version.Attribute(k)
-			if attr != nil {
-				err = encoder.SetAttribute(attr, v)
-			} else {
-				err = encoder.SetExtension(strings.ToLower(strings.TrimPrefix(k, prefix)), v)
+		if strings.HasPrefix(k, m.mapping.prefix) {
+			if err = m.setPrefixed(encoder, k, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	for ak, v := range m.AMQP.Annotations {
+		k, ok := annotationKey(ak)
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(k, m.mapping.prefix) {
+			err = m.setPrefixed(encoder, k, v)
+		} else if m.mapping.annotationExtension != nil {
+			if name, ok := m.mapping.annotationExtension(k); ok {
+				err = encoder.SetExtension(name, extensionValue(v))
 			}
 		}
 		if err != nil {
@@ -119,16 +146,33 @@ func (m *Message) ReadBinary(ctx context.Context, encoder binding.BinaryWriter)
 	return nil
 }
 
+// setPrefixed sets the attribute or extension stored with the prefixed key k.
+func (m *Message) setPrefixed(encoder binding.BinaryWriter, k string, v interface{}) error {
+	if attr := m.version.Attribute(k); attr != nil {
+		return encoder.SetAttribute(attr, v)
+	}
+	return encoder.SetExtension(strings.ToLower(strings.TrimPrefix(k, m.mapping.prefix)), v)
+}
+
+// extensionValue converts the value of a broker annotation to a valid
+// extension value, falling back to its string form.
+func extensionValue(v interface{}) interface{} {
+	if cv, err := types.Validate(v); err == nil {
+		return cv
+	}
+	return fmt.Sprint(v)
+}
+
 func (m *Message) GetAttribute(k spec.Kind) (spec.Attribute, interface{}) {
 	attr := m.version.AttributeFromKind(k)
 	if attr != nil {
-		return attr, m.AMQP.ApplicationProperties[attr.PrefixedName()]
+		return attr, m.mapping.get(m.AMQP, attr.Name())
 	}
 	return nil, nil
 }
 
 func (m *Message) GetExtension(name string) interface{} {
-	return m.AMQP.ApplicationProperties[prefix+name]
+	return m.mapping.get(m.AMQP, name)
 }
 
 func (m *Message) Finish(err error) error {
@@ -6,7 +6,11 @@
 package amqp
 
 import (
+	"fmt"
+
 	"github.com/Azure/go-amqp"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 // Option is the function signature required to be considered an amqp.Option.
@@ -25,6 +29,17 @@ func WithConnSASLPlain(username, password string) Option {
 	return WithConnOpt(amqp.ConnSASLPlain(username, password))
 }
 
+// TLSConnOption returns the connection option securing the connection to the
+// server with the shared protocol.TLSOption set. It is given to the
+// constructors along with the other connection options.
+func TLSConnOption(opts ...protocol.TLSOption) (amqp.ConnOption, error) {
+	config, err := protocol.NewTLSConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return amqp.ConnTLSConfig(config), nil
+}
+
 // WithSessionOpt sets a session option for amqp
 func WithSessionOpt(opt amqp.SessionOption) Option {
 	return func(t *Protocol) error {
@@ -49,5 +64,62 @@ func WithReceiverLinkOption(opt amqp.LinkOption) Option {
 	}
 }
 
+// WithMapping sets how the CloudEvents attributes and extensions are mapped
+// onto the AMQP messages sent and received.
+func WithMapping(mapping Mapping) Option {
+	return func(t *Protocol) error {
+		t.mapping = newMapping(mapping)
+		return nil
+	}
+}
+
+// WithReconnect makes the protocol reconnect when its receiver link fails,
+// e.g. because the broker restarted, instead of closing the receiver: a
+// protocol created with NewProtocol or NewReceiverProtocol dials a new
+// connection following policy and opens new links on it. Once it gives up,
+// or if the protocol was created from an existing client, Receive and
+// OpenInbound return an error.
+func WithReconnect(policy protocol.ReconnectPolicy) Option {
+	return func(t *Protocol) error {
+		t.reconnect = &policy
+		return nil
+	}
+}
+
+// WithReceiverCredit makes the receiver link grant the broker the credit of
+// at most credit messages at a time, issuing the credit of each message as it
+// is received. Pausing the protocol, see protocol.Pauser, then stops issuing
+// credit until it is resumed. Without it, the link manages its credit itself,
+// see amqp.LinkCredit, and pausing it does nothing.
+func WithReceiverCredit(credit uint32) Option {
+	return func(t *Protocol) error {
+		if credit == 0 {
+			return fmt.Errorf("the receiver credit must be positive")
+		}
+		t.receiverLinkOpts = append(t.receiverLinkOpts, amqp.LinkWithManualCredits(), amqp.LinkCredit(credit))
+		t.flow = &creditFlow{credit: credit}
+		return nil
+	}
+}
+
 // SenderOptionFunc is the type of amqp.Sender options
 type SenderOptionFunc func(sender *sender)
+
+// WithSenderMapping sets how the CloudEvents attributes and extensions are
+// mapped onto the AMQP messages sent.
+func WithSenderMapping(mapping Mapping) SenderOptionFunc {
+	return func(sender *sender) {
+		sender.mapping = newMapping(mapping)
+	}
+}
+
+// ReceiverOptionFunc is the type of amqp.Receiver options
+type ReceiverOptionFunc func(receiver *receiver)
+
+// WithReceiverMapping sets how the CloudEvents attributes and extensions are
+// read from the AMQP messages received.
+func WithReceiverMapping(mapping Mapping) ReceiverOptionFunc {
+	return func(receiver *receiver) {
+		receiver.mapping = newMapping(mapping)
+	}
+}
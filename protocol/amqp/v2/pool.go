@@ -0,0 +1,457 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-amqp"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+const (
+	// DefaultPoolIdleTimeout is the time after which an unused sender link
+	// is closed, see WithPoolIdleTimeout.
+	DefaultPoolIdleTimeout = 5 * time.Minute
+	// DefaultPoolHealthCheckInterval is the interval between two health
+	// checks of the pooled connections, see WithPoolHealthCheckInterval.
+	DefaultPoolHealthCheckInterval = 30 * time.Second
+)
+
+// ErrPoolClosed is returned when sending with a closed Pool.
+var ErrPoolClosed = errors.New("amqp pool closed")
+
+// Pool shares AMQP connections and sender links between the senders of many
+// target addresses, so that sending to an address does not open a link each
+// time. A link is opened on the first send to its address, on one of the
+// pooled connections, and closed once it has not been used for the idle
+// timeout. The connections are checked periodically: a connection failing
+// the check, or a send, is closed with its links, and opened again on the
+// next send.
+type Pool struct {
+	dial           func() (poolConn, error)
+	idleTimeout    time.Duration
+	healthInterval time.Duration
+	linkOpts       []amqp.LinkOption
+	mapping        *mapping
+	now            func() time.Time
+
+	slots []*poolSlot
+
+	mu     sync.Mutex
+	links  map[string]*pooledLink
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// poolConn is a connection of the pool, with the session opening the links.
+type poolConn interface {
+	newSender(opts ...amqp.LinkOption) (linkSender, error)
+	// probe checks the connection is alive.
+	probe(ctx context.Context) error
+	close() error
+}
+
+// linkSender is a sender link.
+type linkSender interface {
+	Send(ctx context.Context, msg *amqp.Message) error
+	Close(ctx context.Context) error
+}
+
+// poolSlot holds one of the pooled connections, opened on demand.
+type poolSlot struct {
+	// mu is held while dialing.
+	mu   sync.Mutex
+	conn poolConn
+}
+
+type pooledLink struct {
+	// ready is closed once the link is opened, or failed to open.
+	ready  chan struct{}
+	conn   poolConn
+	sender linkSender
+	err    error
+
+	// guarded by Pool.mu
+	inflight int
+	lastUsed time.Time
+}
+
+// opened returns whether the link has been opened.
+func (l *pooledLink) opened() bool {
+	select {
+	case <-l.ready:
+		return l.err == nil
+	default:
+		return false
+	}
+}
+
+// PoolOption is the function signature required to be considered an
+// amqp.PoolOption.
+type PoolOption func(*Pool) error
+
+// WithPoolSize sets the number of pooled connections, 1 by default. The
+// links of an address always use the same connection.
+func WithPoolSize(size int) PoolOption {
+	return func(p *Pool) error {
+		if size <= 0 {
+			return fmt.Errorf("amqp pool size option was given a non positive size: %d", size)
+		}
+		p.slots = make([]*poolSlot, size)
+		for i := range p.slots {
+			p.slots[i] = &poolSlot{}
+		}
+		return nil
+	}
+}
+
+// WithPoolIdleTimeout sets the time after which an unused sender link is
+// closed, DefaultPoolIdleTimeout by default.
+func WithPoolIdleTimeout(timeout time.Duration) PoolOption {
+	return func(p *Pool) error {
+		if timeout <= 0 {
+			return fmt.Errorf("amqp pool idle timeout option was given a non positive timeout: %v", timeout)
+		}
+		p.idleTimeout = timeout
+		return nil
+	}
+}
+
+// WithPoolHealthCheckInterval sets the interval between two health checks of
+// the pooled connections, DefaultPoolHealthCheckInterval by default. The
+// idle links are reaped at the same interval.
+func WithPoolHealthCheckInterval(interval time.Duration) PoolOption {
+	return func(p *Pool) error {
+		if interval <= 0 {
+			return fmt.Errorf("amqp pool health check interval option was given a non positive interval: %v", interval)
+		}
+		p.healthInterval = interval
+		return nil
+	}
+}
+
+// WithPoolSenderLinkOption sets a link option for the sender links of the
+// pool. The target address is set by the pool.
+func WithPoolSenderLinkOption(opt amqp.LinkOption) PoolOption {
+	return func(p *Pool) error {
+		p.linkOpts = append(p.linkOpts, opt)
+		return nil
+	}
+}
+
+// WithPoolMapping sets how the CloudEvents attributes and extensions are
+// mapped onto the AMQP messages sent.
+func WithPoolMapping(mapping Mapping) PoolOption {
+	return func(p *Pool) error {
+		p.mapping = newMapping(mapping)
+		return nil
+	}
+}
+
+// NewPool creates a new pool of connections to server. The connections are
+// opened on demand.
+func NewPool(server string, connOption []amqp.ConnOption, sessionOption []amqp.SessionOption, opts ...PoolOption) (*Pool, error) {
+	dial := redial(server, connOption, sessionOption)
+	return newPool(func() (poolConn, error) {
+		client, session, err := dial()
+		if err != nil {
+			return nil, err
+		}
+		return &amqpConn{client: client, session: session}, nil
+	}, opts...)
+}
+
+func newPool(dial func() (poolConn, error), opts ...PoolOption) (*Pool, error) {
+	p := &Pool{
+		dial:           dial,
+		idleTimeout:    DefaultPoolIdleTimeout,
+		healthInterval: DefaultPoolHealthCheckInterval,
+		mapping:        defaultMapping,
+		now:            time.Now,
+		slots:          []*poolSlot{{}},
+		links:          make(map[string]*pooledLink),
+		done:           make(chan struct{}),
+	}
+	for _, fn := range opts {
+		if err := fn(p); err != nil {
+			return nil, err
+		}
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.healthInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				p.maintain(context.Background())
+			}
+		}
+	}()
+	return p, nil
+}
+
+// Sender returns a sender sending the messages to the given target address
+// through the pool.
+func (p *Pool) Sender(address string) protocol.Sender {
+	return &poolSender{pool: p, address: address}
+}
+
+// Close closes the links and the connections of the pool.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	links := p.links
+	p.links = make(map[string]*pooledLink)
+	p.mu.Unlock()
+
+	close(p.done)
+	p.wg.Wait()
+
+	for _, l := range links {
+		<-l.ready
+		if l.sender != nil {
+			_ = l.sender.Close(ctx)
+		}
+	}
+	var errs []error
+	for _, s := range p.slots {
+		s.mu.Lock()
+		if s.conn != nil {
+			errs = append(errs, s.conn.close())
+			s.conn = nil
+		}
+		s.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// acquire returns the link of address, opening it if needed. The link must
+// be released once used.
+func (p *Pool) acquire(ctx context.Context, address string) (*pooledLink, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+	l, ok := p.links[address]
+	if !ok {
+		l = &pooledLink{ready: make(chan struct{})}
+		p.links[address] = l
+	}
+	l.inflight++
+	p.mu.Unlock()
+
+	if !ok {
+		l.conn, l.sender, l.err = p.open(address)
+		if l.err != nil {
+			p.mu.Lock()
+			if p.links[address] == l {
+				delete(p.links, address)
+			}
+			p.mu.Unlock()
+		}
+		close(l.ready)
+	}
+
+	select {
+	case <-l.ready:
+	case <-ctx.Done():
+		p.release(address, l, nil)
+		return nil, ctx.Err()
+	}
+	if l.err != nil {
+		p.release(address, l, nil)
+		return nil, l.err
+	}
+	return l, nil
+}
+
+// open opens a sender link to address, on the connection of its slot.
+func (p *Pool) open(address string) (poolConn, linkSender, error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(address))
+	slot := p.slots[h.Sum32()%uint32(len(p.slots))]
+
+	slot.mu.Lock()
+	if slot.conn == nil {
+		conn, err := p.dial()
+		if err != nil {
+			slot.mu.Unlock()
+			return nil, nil, err
+		}
+		slot.conn = conn
+	}
+	conn := slot.conn
+	slot.mu.Unlock()
+
+	opts := append(append([]amqp.LinkOption(nil), p.linkOpts...), amqp.LinkTargetAddress(address))
+	sender, err := conn.newSender(opts...)
+	if err != nil {
+		if connFailed(err) {
+			p.dropConn(conn)
+		}
+		return nil, nil, err
+	}
+	return conn, sender, nil
+}
+
+// release releases a link acquired with acquire, err being the result of
+// the send. The link, or its connection, is closed if it failed.
+func (p *Pool) release(address string, l *pooledLink, err error) {
+	p.mu.Lock()
+	l.inflight--
+	l.lastUsed = p.now()
+	p.mu.Unlock()
+
+	switch {
+	case connFailed(err):
+		p.dropConn(l.conn)
+	case linkFailed(err):
+		p.mu.Lock()
+		evicted := p.links[address] == l
+		if evicted {
+			delete(p.links, address)
+		}
+		p.mu.Unlock()
+		if evicted {
+			_ = l.sender.Close(context.Background())
+		}
+	}
+}
+
+// dropConn closes conn and forgets it with its links.
+func (p *Pool) dropConn(conn poolConn) {
+	for _, s := range p.slots {
+		s.mu.Lock()
+		if s.conn == conn {
+			s.conn = nil
+		}
+		s.mu.Unlock()
+	}
+
+	p.mu.Lock()
+	for address, l := range p.links {
+		if l.opened() && l.conn == conn {
+			delete(p.links, address)
+		}
+	}
+	p.mu.Unlock()
+	_ = conn.close()
+}
+
+// maintain closes the idle links and the connections failing the health
+// check.
+func (p *Pool) maintain(ctx context.Context) {
+	var idle []linkSender
+	p.mu.Lock()
+	now := p.now()
+	for address, l := range p.links {
+		if l.opened() && l.inflight == 0 && now.Sub(l.lastUsed) >= p.idleTimeout {
+			delete(p.links, address)
+			idle = append(idle, l.sender)
+		}
+	}
+	p.mu.Unlock()
+	for _, sender := range idle {
+		_ = sender.Close(ctx)
+	}
+
+	for _, s := range p.slots {
+		s.mu.Lock()
+		conn := s.conn
+		s.mu.Unlock()
+		if conn == nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(ctx, p.healthInterval)
+		err := conn.probe(ctx)
+		cancel()
+		if err != nil {
+			p.dropConn(conn)
+		}
+	}
+}
+
+// connFailed returns whether err means the connection of a link failed.
+func connFailed(err error) bool {
+	return errors.Is(err, amqp.ErrConnClosed) || errors.Is(err, amqp.ErrSessionClosed)
+}
+
+// linkFailed returns whether err means a link failed.
+func linkFailed(err error) bool {
+	var detach *amqp.DetachError
+	return errors.Is(err, amqp.ErrLinkClosed) || errors.As(err, &detach)
+}
+
+// amqpConn is a poolConn of an AMQP client.
+type amqpConn struct {
+	client  *amqp.Client
+	session *amqp.Session
+}
+
+func (c *amqpConn) newSender(opts ...amqp.LinkOption) (linkSender, error) {
+	return c.session.NewSender(opts...)
+}
+
+func (c *amqpConn) probe(ctx context.Context) error {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return err
+	}
+	return session.Close(ctx)
+}
+
+func (c *amqpConn) close() error {
+	return c.client.Close()
+}
+
+// poolSender sends the messages to an address through a Pool.
+type poolSender struct {
+	pool    *Pool
+	address string
+}
+
+func (s *poolSender) Send(ctx context.Context, in binding.Message, transformers ...binding.Transformer) (err error) {
+	defer func() { _ = in.Finish(err) }()
+
+	var msg *amqp.Message
+	if m, ok := in.(*Message); ok { // Already an AMQP message.
+		msg = m.AMQP
+	} else {
+		msg = &amqp.Message{}
+		if err = writeMessage(ctx, in, msg, s.pool.mapping, transformers...); err != nil {
+			return err
+		}
+	}
+
+	l, err := s.pool.acquire(ctx, s.address)
+	if err != nil {
+		return err
+	}
+	err = l.sender.Send(ctx, msg)
+	s.pool.release(s.address, l, err)
+	return sendResult(err)
+}
+
+var _ protocol.Sender = (*poolSender)(nil)
@@ -0,0 +1,182 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-amqp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+type fakeConn struct {
+	mu       sync.Mutex
+	links    int
+	sendErr  error
+	probeErr error
+	closed   bool
+}
+
+func (c *fakeConn) newSender(opts ...amqp.LinkOption) (linkSender, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, amqp.ErrConnClosed
+	}
+	c.links++
+	return &fakeLink{conn: c}, nil
+}
+
+func (c *fakeConn) probe(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.probeErr
+}
+
+func (c *fakeConn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+type fakeLink struct {
+	conn   *fakeConn
+	mu     sync.Mutex
+	sent   int
+	closed bool
+}
+
+func (l *fakeLink) Send(context.Context, *amqp.Message) error {
+	l.conn.mu.Lock()
+	err := l.conn.sendErr
+	l.conn.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sent++
+	return nil
+}
+
+func (l *fakeLink) Close(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	return nil
+}
+
+func newFakePool(t *testing.T, opts ...PoolOption) (*Pool, *[]*fakeConn) {
+	var conns []*fakeConn
+	p, err := newPool(func() (poolConn, error) {
+		c := &fakeConn{}
+		conns = append(conns, c)
+		return c, nil
+	}, append([]PoolOption{WithPoolHealthCheckInterval(time.Hour)}, opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close(context.Background()) })
+	return p, &conns
+}
+
+func send(t *testing.T, p *Pool, address string) error {
+	e := test.FullEvent()
+	return p.Sender(address).Send(context.Background(), binding.ToMessage(&e))
+}
+
+func TestPoolReusesLinks(t *testing.T) {
+	p, conns := newFakePool(t)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, send(t, p, "a"))
+		require.NoError(t, send(t, p, "b"))
+	}
+	require.Len(t, *conns, 1)
+	require.Equal(t, 2, (*conns)[0].links)
+	require.Equal(t, 3, p.links["a"].sender.(*fakeLink).sent)
+}
+
+func TestPoolEvictsFailedLinks(t *testing.T) {
+	p, conns := newFakePool(t)
+	require.NoError(t, send(t, p, "a"))
+	link := p.links["a"].sender.(*fakeLink)
+
+	(*conns)[0].sendErr = &amqp.DetachError{}
+	require.Error(t, send(t, p, "a"))
+	require.True(t, link.closed)
+	require.NotContains(t, p.links, "a")
+
+	(*conns)[0].sendErr = nil
+	require.NoError(t, send(t, p, "a"))
+	require.Equal(t, 2, (*conns)[0].links)
+}
+
+func TestPoolDropsFailedConnections(t *testing.T) {
+	p, conns := newFakePool(t)
+	require.NoError(t, send(t, p, "a"))
+
+	(*conns)[0].sendErr = amqp.ErrConnClosed
+	require.ErrorIs(t, send(t, p, "a"), amqp.ErrConnClosed)
+	require.True(t, (*conns)[0].closed)
+
+	require.NoError(t, send(t, p, "a"))
+	require.Len(t, *conns, 2)
+}
+
+func TestPoolMaintain(t *testing.T) {
+	now := time.Unix(0, 0)
+	p, conns := newFakePool(t, WithPoolIdleTimeout(time.Minute))
+	p.now = func() time.Time { return now }
+
+	require.NoError(t, send(t, p, "a"))
+	now = now.Add(30 * time.Second)
+	require.NoError(t, send(t, p, "b"))
+	idle := p.links["a"].sender.(*fakeLink)
+
+	now = now.Add(45 * time.Second)
+	p.maintain(context.Background())
+	require.True(t, idle.closed)
+	require.NotContains(t, p.links, "a")
+	require.Contains(t, p.links, "b")
+
+	(*conns)[0].probeErr = errors.New("unhealthy")
+	p.maintain(context.Background())
+	require.True(t, (*conns)[0].closed)
+	require.Empty(t, p.links)
+
+	require.NoError(t, send(t, p, "b"))
+	require.Len(t, *conns, 2)
+}
+
+func TestPoolSize(t *testing.T) {
+	p, conns := newFakePool(t, WithPoolSize(4))
+	for _, address := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		require.NoError(t, send(t, p, address))
+	}
+	require.LessOrEqual(t, len(*conns), 4)
+	require.Greater(t, len(*conns), 1)
+
+	_, err := newPool(nil, WithPoolSize(0))
+	require.Error(t, err)
+}
+
+func TestPoolClose(t *testing.T) {
+	p, conns := newFakePool(t)
+	require.NoError(t, send(t, p, "a"))
+	link := p.links["a"].sender.(*fakeLink)
+
+	require.NoError(t, p.Close(context.Background()))
+	require.True(t, link.closed)
+	require.True(t, (*conns)[0].closed)
+	require.ErrorIs(t, send(t, p, "a"), ErrPoolClosed)
+}
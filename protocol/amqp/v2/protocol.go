@@ -7,6 +7,7 @@ package amqp
 
 import (
 	"context"
+	"sync"
 
 	"github.com/Azure/go-amqp"
 
@@ -19,6 +20,7 @@ type Protocol struct {
 	sessionOpts      []amqp.SessionOption
 	senderLinkOpts   []amqp.LinkOption
 	receiverLinkOpts []amqp.LinkOption
+	mapping          *mapping
 
 	// AMQP
 	Client      *amqp.Client
@@ -32,6 +34,22 @@ type Protocol struct {
 
 	// Receiver
 	Receiver *receiver
+	// flow issues the credit of the receiver link, see WithReceiverCredit.
+	flow *creditFlow
+
+	// Reconnection, see WithReconnect
+	reconnect *protocol.ReconnectPolicy
+	// redial opens a new client and session, nil if the client was given
+	// by the caller.
+	redial func() (*amqp.Client, *amqp.Session, error)
+	// connMutex guards the client, the session and the links, replaced
+	// when reconnecting.
+	connMutex    sync.RWMutex
+	reconnecting chan struct{}
+	reconnectErr error
+	failed       chan struct{}
+	closed       chan struct{}
+	closeOnce    sync.Once
 }
 
 // NewProtocolFromClient creates a new amqp transport.
@@ -42,6 +60,8 @@ func NewProtocolFromClient(client *amqp.Client, session *amqp.Session, queue str
 		receiverLinkOpts: []amqp.LinkOption(nil),
 		Client:           client,
 		Session:          session,
+		failed:           make(chan struct{}),
+		closed:           make(chan struct{}),
 	}
 	if err := t.applyOptions(opts...); err != nil {
 		return nil, err
@@ -56,7 +76,7 @@ func NewProtocolFromClient(client *amqp.Client, session *amqp.Session, queue str
 		_ = session.Close(context.Background())
 		return nil, err
 	}
-	t.Sender = NewSender(amqpSender).(*sender)
+	t.Sender = t.newSender(amqpSender)
 	t.SenderContextDecorators = []func(context.Context) context.Context{}
 
 	t.receiverLinkOpts = append(t.receiverLinkOpts, amqp.LinkSourceAddress(t.Node))
@@ -64,7 +84,9 @@ func NewProtocolFromClient(client *amqp.Client, session *amqp.Session, queue str
 	if err != nil {
 		return nil, err
 	}
-	t.Receiver = NewReceiver(amqpReceiver).(*receiver)
+	if t.Receiver, err = t.newReceiver(amqpReceiver); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
@@ -88,6 +110,7 @@ func NewProtocol(server, queue string, connOption []amqp.ConnOption, sessionOpti
 	}
 
 	p.ownedClient = true
+	p.redial = redial(server, connOption, sessionOption)
 	return p, nil
 }
 
@@ -99,6 +122,8 @@ func NewSenderProtocolFromClient(client *amqp.Client, session *amqp.Session, add
 		receiverLinkOpts: []amqp.LinkOption(nil),
 		Client:           client,
 		Session:          session,
+		failed:           make(chan struct{}),
+		closed:           make(chan struct{}),
 	}
 	if err := t.applyOptions(opts...); err != nil {
 		return nil, err
@@ -111,7 +136,7 @@ func NewSenderProtocolFromClient(client *amqp.Client, session *amqp.Session, add
 		_ = session.Close(context.Background())
 		return nil, err
 	}
-	t.Sender = NewSender(amqpSender).(*sender)
+	t.Sender = t.newSender(amqpSender)
 	t.SenderContextDecorators = []func(context.Context) context.Context{}
 
 	return t, nil
@@ -125,6 +150,8 @@ func NewReceiverProtocolFromClient(client *amqp.Client, session *amqp.Session, a
 		receiverLinkOpts: []amqp.LinkOption(nil),
 		Client:           client,
 		Session:          session,
+		failed:           make(chan struct{}),
+		closed:           make(chan struct{}),
 	}
 	if err := t.applyOptions(opts...); err != nil {
 		return nil, err
@@ -136,7 +163,9 @@ func NewReceiverProtocolFromClient(client *amqp.Client, session *amqp.Session, a
 	if err != nil {
 		return nil, err
 	}
-	t.Receiver = NewReceiver(amqpReceiver).(*receiver)
+	if t.Receiver, err = t.newReceiver(amqpReceiver); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
@@ -184,9 +213,32 @@ func NewReceiverProtocol(server, address string, connOption []amqp.ConnOption, s
 	}
 
 	p.ownedClient = true
+	p.redial = redial(server, connOption, sessionOption)
 	return p, nil
 }
 
+func (t *Protocol) newSender(amqpSender *amqp.Sender) *sender {
+	s := NewSender(amqpSender).(*sender)
+	if t.mapping != nil {
+		s.mapping = t.mapping
+	}
+	return s
+}
+
+func (t *Protocol) newReceiver(amqpReceiver *amqp.Receiver) (*receiver, error) {
+	r := NewReceiver(amqpReceiver).(*receiver)
+	if t.mapping != nil {
+		r.mapping = t.mapping
+	}
+	if t.flow != nil {
+		r.flow = t.flow
+		if err := t.flow.attach(amqpReceiver); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
 func (t *Protocol) applyOptions(opts ...Option) error {
 	for _, fn := range opts {
 		if err := fn(t); err != nil {
@@ -197,6 +249,14 @@ func (t *Protocol) applyOptions(opts ...Option) error {
 }
 
 func (t *Protocol) Close(ctx context.Context) (err error) {
+	t.closeOnce.Do(func() {
+		if t.closed != nil {
+			close(t.closed)
+		}
+	})
+	t.connMutex.RLock()
+	defer t.connMutex.RUnlock()
+
 	if t.ownedClient {
 		// Closing the client will close at cascade sender and receiver
 		return t.Client.Close()
@@ -217,13 +277,69 @@ func (t *Protocol) Close(ctx context.Context) (err error) {
 }
 
 func (t *Protocol) Send(ctx context.Context, in binding.Message, transformers ...binding.Transformer) error {
-	return t.Sender.Send(ctx, in, transformers...)
+	t.connMutex.RLock()
+	s := t.Sender
+	t.connMutex.RUnlock()
+	return s.Send(ctx, in, transformers...)
 }
 
 func (t *Protocol) Receive(ctx context.Context) (binding.Message, error) {
-	return t.Receiver.Receive(ctx)
+	if t.reconnect == nil {
+		return t.Receiver.Receive(ctx)
+	}
+	return t.receiveReconnecting(ctx)
+}
+
+// OpenInbound implements Opener.OpenInbound, it blocks until ctx is done. If
+// the protocol gave up reconnecting, see WithReconnect, it returns the error.
+func (t *Protocol) OpenInbound(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+	case <-t.closed:
+	case <-t.failed:
+		t.connMutex.RLock()
+		defer t.connMutex.RUnlock()
+		return t.reconnectErr
+	}
+	return nil
+}
+
+// Ping implements protocol.Pinger, it begins and ends a session on the
+// connection, so that a broker which is not responding is reported too. If
+// the protocol gave up reconnecting, see WithReconnect, it returns the error.
+func (t *Protocol) Ping(ctx context.Context) error {
+	select {
+	case <-t.failed:
+		t.connMutex.RLock()
+		defer t.connMutex.RUnlock()
+		return t.reconnectErr
+	default:
+	}
+	t.connMutex.RLock()
+	client := t.Client
+	t.connMutex.RUnlock()
+
+	// NewSession does not take a context: the session is ended once begun,
+	// even if ctx is done meanwhile.
+	ended := make(chan error, 1)
+	go func() {
+		session, err := client.NewSession()
+		if err != nil {
+			ended <- err
+			return
+		}
+		ended <- session.Close(context.Background())
+	}()
+	select {
+	case err := <-ended:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 var _ protocol.Sender = (*Protocol)(nil)
 var _ protocol.Receiver = (*Protocol)(nil)
 var _ protocol.Closer = (*Protocol)(nil)
+var _ protocol.Opener = (*Protocol)(nil)
+var _ protocol.Pinger = (*Protocol)(nil)
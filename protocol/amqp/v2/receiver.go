@@ -19,7 +19,12 @@ import (
 const serverDown = "session ended by server"
 
 // receiver wraps an amqp.Receiver as a binding.Receiver
-type receiver struct{ amqp *amqp.Receiver }
+type receiver struct {
+	amqp    *amqp.Receiver
+	mapping *mapping
+	// flow, if set, issues the credit of the messages received.
+	flow *creditFlow
+}
 
 func (r *receiver) Receive(ctx context.Context) (binding.Message, error) {
 	m, err := r.amqp.Receive(ctx)
@@ -33,11 +38,20 @@ func (r *receiver) Receive(ctx context.Context) (binding.Message, error) {
 		}
 		return nil, err
 	}
+	if r.flow != nil {
+		if err := r.flow.received(r.amqp); err != nil {
+			return nil, err
+		}
+	}
 
-	return NewMessage(m, r.amqp), nil
+	return newMessage(m, r.amqp, r.mapping), nil
 }
 
 // NewReceiver create a new Receiver which wraps an amqp.Receiver in a binding.Receiver
-func NewReceiver(amqp *amqp.Receiver) protocol.Receiver {
-	return &receiver{amqp: amqp}
+func NewReceiver(amqp *amqp.Receiver, options ...ReceiverOptionFunc) protocol.Receiver {
+	r := &receiver{amqp: amqp, mapping: defaultMapping}
+	for _, o := range options {
+		o(r)
+	}
+	return r
 }
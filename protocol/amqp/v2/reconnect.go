@@ -0,0 +1,164 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Azure/go-amqp"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+)
+
+// redial returns a function opening a new client and session with the
+// given configuration.
+func redial(server string, connOption []amqp.ConnOption, sessionOption []amqp.SessionOption) func() (*amqp.Client, *amqp.Session, error) {
+	return func() (*amqp.Client, *amqp.Session, error) {
+		client, err := amqp.Dial(server, connOption...)
+		if err != nil {
+			return nil, nil, err
+		}
+		session, err := client.NewSession(sessionOption...)
+		if err != nil {
+			_ = client.Close()
+			return nil, nil, err
+		}
+		return client, session, nil
+	}
+}
+
+// receiveReconnecting receives a message, reconnecting when the receiver
+// link fails.
+func (t *Protocol) receiveReconnecting(ctx context.Context) (binding.Message, error) {
+	for {
+		t.connMutex.RLock()
+		r, err := t.Receiver, t.reconnectErr
+		t.connMutex.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := r.Receive(ctx)
+		if err == nil {
+			return m, nil
+		}
+		if ctx.Err() != nil || t.isClosed() {
+			return nil, io.EOF
+		}
+		if err := t.reconnectReceiver(ctx, r, err); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *Protocol) isClosed() bool {
+	select {
+	case <-t.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// reconnectReceiver replaces the failed receiver by a new one, on a new
+// connection, following the reconnect policy. The receivers failing while
+// another goroutine reconnects wait for it.
+func (t *Protocol) reconnectReceiver(ctx context.Context, failed *receiver, cause error) error {
+	t.connMutex.Lock()
+	if t.Receiver != failed || t.reconnectErr != nil {
+		// Already reconnected, or given up.
+		t.connMutex.Unlock()
+		return nil
+	}
+	if reconnecting := t.reconnecting; reconnecting != nil {
+		t.connMutex.Unlock()
+		select {
+		case <-reconnecting:
+			return nil
+		case <-ctx.Done():
+			return io.EOF
+		}
+	}
+	if t.redial == nil {
+		t.fail(fmt.Errorf("amqp receiver failed and can not reconnect a client it did not open: %w", cause))
+		t.connMutex.Unlock()
+		return t.reconnectErr
+	}
+	reconnecting := make(chan struct{})
+	t.reconnecting = reconnecting
+	t.connMutex.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-t.closed:
+			cancel()
+		}
+	}()
+
+	err := t.reconnect.Reconnect(ctx, func(context.Context) error {
+		client, session, err := t.redial()
+		if err != nil {
+			return err
+		}
+		amqpReceiver, err := session.NewReceiver(t.receiverLinkOpts...)
+		if err != nil {
+			_ = client.Close()
+			return err
+		}
+		var amqpSender *amqp.Sender
+		if t.Sender != nil {
+			if amqpSender, err = session.NewSender(t.senderLinkOpts...); err != nil {
+				_ = client.Close()
+				return err
+			}
+		}
+		r, err := t.newReceiver(amqpReceiver)
+		if err != nil {
+			_ = client.Close()
+			return err
+		}
+
+		t.connMutex.Lock()
+		defer t.connMutex.Unlock()
+		_ = t.Client.Close()
+		t.Client, t.Session = client, session
+		t.Receiver = r
+		if amqpSender != nil {
+			t.Sender = t.newSender(amqpSender)
+		}
+		return nil
+	})
+
+	t.connMutex.Lock()
+	t.reconnecting = nil
+	if err != nil && ctx.Err() == nil {
+		t.fail(err)
+	}
+	t.connMutex.Unlock()
+	close(reconnecting)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return io.EOF
+		}
+		return err
+	}
+	return nil
+}
+
+// fail records that the protocol gave up reconnecting, t.connMutex must be
+// held.
+func (t *Protocol) fail(err error) {
+	t.reconnectErr = err
+	if t.failed != nil {
+		close(t.failed)
+	}
+}
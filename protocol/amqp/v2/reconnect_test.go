@@ -0,0 +1,431 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-amqp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Descriptor codes of the AMQP performatives and terminus served by the
+// fakeBroker.
+const (
+	codeOpen     = 0x10
+	codeBegin    = 0x11
+	codeAttach   = 0x12
+	codeFlow     = 0x13
+	codeTransfer = 0x14
+	codeDetach   = 0x16
+	codeEnd      = 0x17
+	codeClose    = 0x18
+	codeSource   = 0x28
+	codeTarget   = 0x29
+)
+
+var amqpHeader = []byte{'A', 'M', 'Q', 'P', 0, 1, 0, 0}
+
+// fakeBroker is an AMQP 1.0 broker serving the receiver links opened by the
+// protocol, just enough to deliver messages to them and to drop their
+// connections.
+type fakeBroker struct {
+	t        *testing.T
+	ln       net.Listener
+	accepted chan *brokerConn
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b := &fakeBroker{t: t, ln: ln, accepted: make(chan *brokerConn, 10)}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c := &brokerConn{conn: conn, flowed: make(chan struct{}), credits: make(chan uint32, 10)}
+			t.Cleanup(c.drop)
+			b.accepted <- c
+			go c.serve()
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) url() string {
+	return "amqp://" + b.ln.Addr().String()
+}
+
+// next returns the next connection opened to the broker.
+func (b *fakeBroker) next() *brokerConn {
+	select {
+	case c := <-b.accepted:
+		return c
+	case <-time.After(5 * time.Second):
+		b.t.Fatal("no connection opened to the broker")
+		return nil
+	}
+}
+
+type brokerConn struct {
+	conn net.Conn
+	mu   sync.Mutex
+	// handle is the handle of the receiver link, flowed is closed once it
+	// has granted credit, and credits receives the link credit of each flow.
+	handle     uint32
+	flowed     chan struct{}
+	flowOnce   sync.Once
+	credits    chan uint32
+	deliveries uint32
+}
+
+func (c *brokerConn) serve() {
+	header := make([]byte, len(amqpHeader))
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return
+	}
+	c.write(amqpHeader)
+	for {
+		channel, code, fields, err := readFrame(c.conn)
+		if err != nil {
+			return
+		}
+		switch code {
+		case codeOpen:
+			c.write(frame(channel, described(codeOpen, amqpString("broker"))))
+		case codeBegin:
+			c.write(frame(channel, described(codeBegin, amqpUshort(channel), amqpUint(0), amqpUint(1000), amqpUint(1000))))
+		case codeAttach:
+			name, handle := attachFields(fields)
+			c.mu.Lock()
+			c.handle = handle
+			c.mu.Unlock()
+			c.write(frame(channel, described(codeAttach,
+				amqpString(name), amqpUint(handle), amqpBool(false), amqpNull, amqpNull,
+				described(codeSource, amqpString("queue")), described(codeTarget),
+				amqpNull, amqpNull, amqpUint(0))))
+		case codeFlow:
+			c.flowOnce.Do(func() { close(c.flowed) })
+			if fields := listFields(fields); len(fields) > 6 {
+				select {
+				case c.credits <- uintValue(fields[6]):
+				default:
+				}
+			}
+		case codeDetach:
+			c.write(frame(channel, described(codeDetach, amqpUint(c.handle), amqpBool(true))))
+		case codeEnd:
+			c.write(frame(channel, described(codeEnd)))
+		case codeClose:
+			c.write(frame(channel, described(codeClose)))
+			c.drop()
+			return
+		}
+	}
+}
+
+func (c *brokerConn) write(b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = c.conn.Write(b)
+}
+
+// deliver sends a message with the given data on the receiver link, once it
+// has credit.
+func (c *brokerConn) deliver(t *testing.T, data string) {
+	select {
+	case <-c.flowed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the receiver link granted no credit")
+	}
+	payload, err := amqp.NewMessage([]byte(data)).MarshalBinary()
+	require.NoError(t, err)
+	c.mu.Lock()
+	id, handle := c.deliveries, c.handle
+	c.deliveries++
+	c.mu.Unlock()
+	tag := make([]byte, 4)
+	binary.BigEndian.PutUint32(tag, id)
+	body := described(codeTransfer, amqpUint(handle), amqpUint(id), amqpBinary(tag), amqpUint(0), amqpBool(false), amqpBool(false))
+	c.write(frame(0, append(body, payload...)))
+}
+
+// drop closes the connection, as a broker restarting does.
+func (c *brokerConn) drop() {
+	_ = c.conn.Close()
+}
+
+// readFrame reads an AMQP frame, returning the descriptor code of its
+// performative, 0 for an empty frame, and its encoded fields.
+func readFrame(r io.Reader) (uint16, byte, []byte, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:4])
+	channel := binary.BigEndian.Uint16(header[6:])
+	body := make([]byte, size-uint32(header[4])*4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, 0, nil, err
+	}
+	if len(body) < 3 {
+		return channel, 0, nil, nil
+	}
+	return channel, body[2], body[3:], nil
+}
+
+// attachFields decodes the name and the handle of an attach.
+func attachFields(list []byte) (string, uint32) {
+	switch list[0] {
+	case 0xc0:
+		list = list[3:]
+	case 0xd0:
+		list = list[9:]
+	}
+	var name string
+	switch list[0] {
+	case 0xa1:
+		name, list = string(list[2:2+list[1]]), list[2+list[1]:]
+	case 0xb1:
+		n := binary.BigEndian.Uint32(list[1:])
+		name, list = string(list[5:5+n]), list[5+n:]
+	}
+	switch list[0] {
+	case 0x52:
+		return name, uint32(list[1])
+	case 0x70:
+		return name, binary.BigEndian.Uint32(list[1:])
+	}
+	return name, 0
+}
+
+// listFields splits a list of fixed width values, as the fields of a flow.
+func listFields(list []byte) [][]byte {
+	var count int
+	switch list[0] {
+	case 0xc0:
+		count, list = int(list[2]), list[3:]
+	case 0xd0:
+		count, list = int(binary.BigEndian.Uint32(list[5:])), list[9:]
+	}
+	fields := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		n := 1
+		switch list[0] {
+		case 0x52, 0x53, 0x56:
+			n = 2
+		case 0x70:
+			n = 5
+		case 0x80:
+			n = 9
+		}
+		fields, list = append(fields, list[:n]), list[n:]
+	}
+	return fields
+}
+
+// uintValue decodes a uint.
+func uintValue(b []byte) uint32 {
+	switch b[0] {
+	case 0x52:
+		return uint32(b[1])
+	case 0x70:
+		return binary.BigEndian.Uint32(b[1:])
+	}
+	return 0
+}
+
+func frame(channel uint16, body []byte) []byte {
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+	b[4] = 2
+	binary.BigEndian.PutUint16(b[6:], channel)
+	return append(b, body...)
+}
+
+// described encodes a described list of fields.
+func described(code byte, fields ...[]byte) []byte {
+	var list []byte
+	for _, f := range fields {
+		list = append(list, f...)
+	}
+	b := []byte{0x00, 0x53, code, 0xd0}
+	b = binary.BigEndian.AppendUint32(b, uint32(4+len(list)))
+	b = binary.BigEndian.AppendUint32(b, uint32(len(fields)))
+	return append(b, list...)
+}
+
+var amqpNull = []byte{0x40}
+
+func amqpBool(v bool) []byte {
+	if v {
+		return []byte{0x41}
+	}
+	return []byte{0x42}
+}
+
+func amqpUshort(v uint16) []byte {
+	return binary.BigEndian.AppendUint16([]byte{0x60}, v)
+}
+
+func amqpUint(v uint32) []byte {
+	return binary.BigEndian.AppendUint32([]byte{0x70}, v)
+}
+
+func amqpString(s string) []byte {
+	return append([]byte{0xa1, byte(len(s))}, s...)
+}
+
+func amqpBinary(b []byte) []byte {
+	return append([]byte{0xa0, byte(len(b))}, b...)
+}
+
+// receive receives a message in the background, so that the connection can
+// be dropped meanwhile.
+func receive(ctx context.Context, p *Protocol) <-chan interface{} {
+	received := make(chan interface{}, 1)
+	go func() {
+		m, err := p.Receive(ctx)
+		if err != nil {
+			received <- err
+			return
+		}
+		received <- m
+	}()
+	return received
+}
+
+func requireData(t *testing.T, received <-chan interface{}, data string) {
+	var got interface{}
+	select {
+	case got = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+	m, ok := got.(binding.Message)
+	require.True(t, ok, "received %v", got)
+	require.Equal(t, data, string(m.(*Message).AMQP.GetData()))
+	require.NoError(t, m.Finish(nil))
+}
+
+func TestReconnectResumes(t *testing.T) {
+	b := newFakeBroker(t)
+	reconnected := make(chan struct{}, 1)
+	p, err := NewReceiverProtocol(b.url(), "queue", nil, nil, WithReconnect(protocol.ReconnectPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxRetries:     3,
+		OnReconnect:    []func(context.Context){func(context.Context) { reconnected <- struct{}{} }},
+	}))
+	require.NoError(t, err)
+	defer p.Close(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbound := make(chan error, 1)
+	go func() { inbound <- p.OpenInbound(ctx) }()
+
+	first := b.next()
+	received := receive(ctx, p)
+	first.deliver(t, "before")
+	requireData(t, received, "before")
+
+	// The broker restarts: the protocol opens a new connection and link,
+	// and keeps receiving on it.
+	received = receive(ctx, p)
+	first.drop()
+	second := b.next()
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the OnReconnect hook was not invoked")
+	}
+	second.deliver(t, "after")
+	requireData(t, received, "after")
+
+	cancel()
+	require.NoError(t, <-inbound)
+}
+
+func TestReconnectGivesUp(t *testing.T) {
+	b := newFakeBroker(t)
+	p, err := NewReceiverProtocol(b.url(), "queue", nil, nil, WithReconnect(protocol.ReconnectPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxRetries:     2,
+	}))
+	require.NoError(t, err)
+	defer p.Close(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inbound := make(chan error, 1)
+	go func() { inbound <- p.OpenInbound(ctx) }()
+
+	// The broker is gone for good: the protocol fails with the error of the
+	// policy once it gave up.
+	conn := b.next()
+	require.NoError(t, b.ln.Close())
+	received := receive(ctx, p)
+	conn.drop()
+
+	var got interface{}
+	select {
+	case got = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the receiver did not fail")
+	}
+	err, ok := got.(error)
+	require.True(t, ok, "received %v", got)
+	require.ErrorContains(t, err, "failed to reconnect after 2 attempts")
+	require.Equal(t, err, <-inbound)
+
+	_, err = p.Receive(ctx)
+	require.ErrorContains(t, err, "failed to reconnect after 2 attempts")
+}
+
+func TestReconnectFromClient(t *testing.T) {
+	b := newFakeBroker(t)
+	client, err := amqp.Dial(b.url())
+	require.NoError(t, err)
+	session, err := client.NewSession()
+	require.NoError(t, err)
+	p, err := NewReceiverProtocolFromClient(client, session, "queue", WithReconnect(protocol.ReconnectPolicy{InitialBackoff: time.Millisecond}))
+	require.NoError(t, err)
+	defer p.Close(context.Background())
+
+	// The protocol can not reopen a client it was given.
+	received := receive(context.Background(), p)
+	b.next().drop()
+	got := <-received
+	err, ok := got.(error)
+	require.True(t, ok, "received %v", got)
+	require.ErrorContains(t, err, "can not reconnect a client it did not open")
+}
+
+func TestPing(t *testing.T) {
+	b := newFakeBroker(t)
+	p, err := NewReceiverProtocol(b.url(), "queue", nil, nil)
+	require.NoError(t, err)
+	defer p.Close(context.Background())
+	conn := b.next()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, p.Ping(ctx))
+
+	// The broker drops the connection.
+	conn.drop()
+	require.Error(t, p.Ping(ctx))
+}
@@ -0,0 +1,45 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"errors"
+
+	"github.com/Azure/go-amqp"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// retriableConditions are the error conditions of the AMQP peers which are
+// transient, including the ones of Azure Service Bus and Event Hubs.
+var retriableConditions = map[amqp.ErrorCondition]bool{
+	amqp.ErrorInternalError:         true,
+	amqp.ErrorResourceLimitExceeded: true,
+	amqp.ErrorResourceLocked:        true,
+	amqp.ErrorConnectionForced:      true,
+	amqp.ErrorDetachForced:          true,
+	"com.microsoft:server-busy":     true,
+	"com.microsoft:timeout":         true,
+}
+
+// sendResult returns the protocol.BrokerResult of the error of a send.
+func sendResult(err error) error {
+	if err == nil {
+		return nil
+	}
+	var detachErr *amqp.DetachError
+	if errors.As(err, &detachErr) {
+		// A link detached gracefully can be attached again.
+		transient := detachErr.RemoteError == nil || retriableConditions[detachErr.RemoteError.Condition]
+		return protocol.NewBrokerResult(err, transient, 0)
+	}
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) {
+		return protocol.NewBrokerResult(err, retriableConditions[amqpErr.Condition], 0)
+	}
+	transient := errors.Is(err, amqp.ErrTimeout) || errors.Is(err, amqp.ErrConnClosed)
+	return protocol.NewBrokerResult(err, transient, 0)
+}
@@ -0,0 +1,43 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package amqp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/go-amqp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestSendResult(t *testing.T) {
+	require.NoError(t, sendResult(nil))
+
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"server busy":       {err: &amqp.Error{Condition: "com.microsoft:server-busy"}, want: true},
+		"not found":         {err: &amqp.Error{Condition: amqp.ErrorNotFound}, want: false},
+		"graceful detach":   {err: &amqp.DetachError{}, want: true},
+		"forced detach":     {err: &amqp.DetachError{RemoteError: &amqp.Error{Condition: amqp.ErrorDetachForced}}, want: true},
+		"unauthorized":      {err: &amqp.DetachError{RemoteError: &amqp.Error{Condition: amqp.ErrorUnauthorizedAccess}}, want: false},
+		"connection closed": {err: amqp.ErrConnClosed, want: true},
+		"link closed":       {err: amqp.ErrLinkClosed, want: false},
+		"other":             {err: errors.New("invalid message"), want: false},
+	}
+	for n, tc := range tests {
+		t.Run(n, func(t *testing.T) {
+			result := sendResult(tc.err)
+			require.Equal(t, tc.want, protocol.IsRetriable(result))
+			brokerErr, ok := protocol.BrokerError(result)
+			require.True(t, ok)
+			require.Equal(t, tc.err, brokerErr)
+		})
+	}
+}
@@ -16,7 +16,8 @@ import (
 
 // sender wraps an amqp.Sender as a binding.Sender
 type sender struct {
-	amqp *amqp.Sender
+	amqp    *amqp.Sender
+	mapping *mapping
 }
 
 func (s *sender) Send(ctx context.Context, in binding.Message, transformers ...binding.Transformer) error {
@@ -24,22 +25,22 @@ func (s *sender) Send(ctx context.Context, in binding.Message, transformers ...b
 	defer func() { _ = in.Finish(err) }()
 	if m, ok := in.(*Message); ok { // Already an AMQP message.
 		err = s.amqp.Send(ctx, m.AMQP)
-		return err
+		return sendResult(err)
 	}
 
 	var amqpMessage amqp.Message
-	err = WriteMessage(ctx, in, &amqpMessage, transformers...)
+	err = writeMessage(ctx, in, &amqpMessage, s.mapping, transformers...)
 	if err != nil {
 		return err
 	}
 
 	err = s.amqp.Send(ctx, &amqpMessage)
-	return err
+	return sendResult(err)
 }
 
 // NewSender creates a new Sender which wraps an amqp.Sender in a binding.Sender
 func NewSender(amqpSender *amqp.Sender, options ...SenderOptionFunc) protocol.Sender {
-	s := &sender{amqp: amqpSender}
+	s := &sender{amqp: amqpSender, mapping: defaultMapping}
 	for _, o := range options {
 		o(s)
 	}
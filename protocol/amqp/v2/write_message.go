@@ -20,8 +20,18 @@ import (
 // WriteMessage fills the provided amqpMessage with the message m.
 // Using context you can tweak the encoding processing (more details on binding.Write documentation).
 func WriteMessage(ctx context.Context, m binding.Message, amqpMessage *amqp.Message, transformers ...binding.Transformer) error {
-	structuredWriter := (*amqpMessageWriter)(amqpMessage)
-	binaryWriter := (*amqpMessageWriter)(amqpMessage)
+	return writeMessage(ctx, m, amqpMessage, defaultMapping, transformers...)
+}
+
+// WriteMessageWithMapping fills the provided amqpMessage with the message m,
+// writing the attributes and extensions as configured by mapping.
+func WriteMessageWithMapping(ctx context.Context, m binding.Message, amqpMessage *amqp.Message, mapping Mapping, transformers ...binding.Transformer) error {
+	return writeMessage(ctx, m, amqpMessage, newMapping(mapping), transformers...)
+}
+
+func writeMessage(ctx context.Context, m binding.Message, amqpMessage *amqp.Message, mapping *mapping, transformers ...binding.Transformer) error {
+	structuredWriter := &amqpMessageWriter{Message: amqpMessage, mapping: mapping}
+	binaryWriter := structuredWriter
 
 	_, err := binding.Write(
 		ctx,
@@ -33,7 +43,10 @@ func WriteMessage(ctx context.Context, m binding.Message, amqpMessage *amqp.Mess
 	return err
 }
 
-type amqpMessageWriter amqp.Message
+type amqpMessageWriter struct {
+	*amqp.Message
+	mapping *mapping
+}
 
 func (b *amqpMessageWriter) SetStructuredEvent(ctx context.Context, format format.Format, event io.Reader) error {
 	val, err := io.ReadAll(event)
@@ -77,26 +90,13 @@ func (b *amqpMessageWriter) SetAttribute(attribute spec.Attribute, value interfa
 		}
 		b.Properties.ContentType = &s
 	} else {
-		if value == nil {
-			delete(b.ApplicationProperties, prefix+attribute.Name())
-			return nil
-		}
-		v, err := safeAMQPPropertiesUnwrap(value)
-		if err != nil {
-			return err
-		}
-		b.ApplicationProperties[prefix+attribute.Name()] = v
+		return b.mapping.set(b.Message, attribute.Name(), value)
 	}
 	return nil
 }
 
 func (b *amqpMessageWriter) SetExtension(name string, value interface{}) error {
-	v, err := safeAMQPPropertiesUnwrap(value)
-	if err != nil {
-		return err
-	}
-	b.ApplicationProperties[prefix+name] = v
-	return nil
+	return b.mapping.set(b.Message, name, value)
 }
 
 var (
@@ -0,0 +1,180 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package sigv4 signs the HTTP requests to AWS services with AWS Signature
+// Version 4, see
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_aws-signing.html
+//
+// It is shared by the clients of the SDK reaching AWS without the AWS SDK,
+// e.g. the S3 claim-check store and the Glue schema registry client.
+package sigv4
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+const (
+	algorithm  = "AWS4-HMAC-SHA256"
+	timeFormat = "20060102T150405Z"
+	dateFormat = "20060102"
+)
+
+// Credentials are AWS credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+}
+
+// CredentialsProvider returns the AWS credentials signing a request. It is
+// invoked for each request, so that temporary credentials can be refreshed.
+type CredentialsProvider func(ctx context.Context) (Credentials, error)
+
+// StaticCredentials returns a CredentialsProvider always returning the given
+// credentials.
+func StaticCredentials(accessKeyID, secretAccessKey, sessionToken string) CredentialsProvider {
+	return func(context.Context) (Credentials, error) {
+		return Credentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, SessionToken: sessionToken}, nil
+	}
+}
+
+// Signer signs the requests to an AWS service in a region.
+type Signer struct {
+	credentials   CredentialsProvider
+	region        string
+	service       string
+	payloadHeader bool
+}
+
+// Option is the function signature for the options of NewSigner.
+type Option func(*Signer) error
+
+// WithPayloadHashHeader sets the X-Amz-Content-Sha256 header to the hash of
+// the payload of the signed requests, as S3 requires.
+func WithPayloadHashHeader() Option {
+	return func(s *Signer) error {
+		s.payloadHeader = true
+		return nil
+	}
+}
+
+// NewSigner returns a Signer of the requests to service in region, e.g. "s3"
+// or "glue", with the credentials of the given provider.
+func NewSigner(credentials CredentialsProvider, region, service string, opts ...Option) (*Signer, error) {
+	if credentials == nil {
+		return nil, errors.New("sigv4 credentials provider can not be nil")
+	}
+	if region == "" || service == "" {
+		return nil, errors.New("sigv4 signer requires a region and a service")
+	}
+	s := &Signer{credentials: credentials, region: region, service: service}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Sign adds the signature headers to req, whose body is body. The signing
+// time is read from the clock of the context of req, see cecontext.WithClock.
+// The Host, Content-Type, Content-MD5 and X-Amz-* headers are signed.
+func (s *Signer) Sign(req *http.Request, body []byte) error {
+	ctx := req.Context()
+	creds, err := s.credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get SigV4 credentials: %w", err)
+	}
+
+	now := cecontext.ClockFrom(ctx).Now().UTC()
+	payloadHash := sha256.Sum256(body)
+	if s.payloadHeader {
+		req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	}
+	req.Header.Set("X-Amz-Date", now.Format(timeFormat))
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "content-type" || name == "content-md5" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(dateFormat), s.region, s.service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{algorithm, now.Format(timeFormat), scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), now.Format(dateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func canonicalQuery(u *url.URL) string {
+	// Encode sorts the keys, the values of a key keep their order.
+	query := u.Query()
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
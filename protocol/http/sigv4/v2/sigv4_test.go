@@ -0,0 +1,69 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package sigv4
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+func TestSign(t *testing.T) {
+	// The get-vanilla case of the AWS SigV4 test suite.
+	s, err := NewSigner(StaticCredentials("AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", ""), "us-east-1", "service")
+	require.NoError(t, err)
+	ctx := cecontext.WithClock(context.Background(), cecontext.NewFakeClock(time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Sign(req, nil))
+	require.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	require.Empty(t, req.Header.Get("X-Amz-Content-Sha256"))
+	require.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", req.Header.Get("Authorization"))
+}
+
+func TestSignPayloadHashHeader(t *testing.T) {
+	s, err := NewSigner(StaticCredentials("id", "secret", "token"), "eu-west-1", "s3", WithPayloadHashHeader())
+	require.NoError(t, err)
+	body := []byte("data")
+	req, err := http.NewRequest(http.MethodPut, "https://claims.s3.eu-west-1.amazonaws.com/key", nil)
+	require.NoError(t, err)
+	req.Header.Set("Content-MD5", "md5")
+
+	require.NoError(t, s.Sign(req, body))
+	hash := sha256.Sum256(body)
+	require.Equal(t, hex.EncodeToString(hash[:]), req.Header.Get("X-Amz-Content-Sha256"))
+	require.Equal(t, "token", req.Header.Get("X-Amz-Security-Token"))
+	require.True(t, strings.Contains(req.Header.Get("Authorization"),
+		"SignedHeaders=content-md5;host;x-amz-content-sha256;x-amz-date;x-amz-security-token,"), req.Header.Get("Authorization"))
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	creds := StaticCredentials("id", "secret", "")
+	_, err := NewSigner(nil, "eu-west-1", "s3")
+	require.Error(t, err)
+	_, err = NewSigner(creds, "", "s3")
+	require.Error(t, err)
+	_, err = NewSigner(creds, "eu-west-1", "")
+	require.Error(t, err)
+
+	s, err := NewSigner(func(context.Context) (Credentials, error) { return Credentials{}, errors.New("expired") }, "eu-west-1", "s3")
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	require.ErrorContains(t, s.Sign(req, nil), "expired")
+}
@@ -10,7 +10,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/protocol"
@@ -20,12 +22,18 @@ import (
 )
 
 var (
-	_ protocol.Sender   = (*Protocol)(nil)
-	_ protocol.Opener   = (*Protocol)(nil)
-	_ protocol.Receiver = (*Protocol)(nil)
-	_ protocol.Closer   = (*Protocol)(nil)
+	_ protocol.Sender      = (*Protocol)(nil)
+	_ protocol.AsyncSender = (*Protocol)(nil)
+	_ protocol.Opener      = (*Protocol)(nil)
+	_ protocol.Receiver    = (*Protocol)(nil)
+	_ protocol.Closer      = (*Protocol)(nil)
+	_ protocol.Pauser      = (*Protocol)(nil)
+	_ protocol.Pinger      = (*Protocol)(nil)
 )
 
+// defaultPingTimeout bounds Ping when its context has no deadline.
+const defaultPingTimeout = 10 * time.Second
+
 type Protocol struct {
 	kafkaConfigMap *kafka.ConfigMap
 
@@ -107,6 +115,25 @@ func (p *Protocol) applyOptions(opts ...Option) error {
 
 // Send message by kafka.Producer. You must monitor the Events() channel when using this function.
 func (p *Protocol) Send(ctx context.Context, in binding.Message, transformers ...binding.Transformer) (err error) {
+	return p.produce(ctx, in, nil, transformers...)
+}
+
+// SendAsync sends the message by kafka.Producer, and invokes fn with its
+// delivery report instead of emitting it on the Events() channel.
+func (p *Protocol) SendAsync(ctx context.Context, in binding.Message, fn protocol.ResultFn, transformers ...binding.Transformer) error {
+	deliveryChan := make(chan kafka.Event, 1)
+	if err := p.produce(ctx, in, deliveryChan, transformers...); err != nil {
+		return err
+	}
+	go func() {
+		// The producer flushes the outstanding messages when closed, so a
+		// delivery report is always received.
+		fn(deliveryResult(<-deliveryChan))
+	}()
+	return nil
+}
+
+func (p *Protocol) produce(ctx context.Context, in binding.Message, deliveryChan chan kafka.Event, transformers ...binding.Transformer) (err error) {
 	if p.producer == nil {
 		return errors.New("producer client must be set")
 	}
@@ -138,12 +165,27 @@ func (p *Protocol) Send(ctx context.Context, in binding.Message, transformers ..
 		return fmt.Errorf("create producer message: %w", err)
 	}
 
-	if err = p.producer.Produce(kafkaMsg, nil); err != nil {
-		return fmt.Errorf("produce message: %w", err)
+	if err = p.producer.Produce(kafkaMsg, deliveryChan); err != nil {
+		return fmt.Errorf("produce message: %w", brokerResult(err))
 	}
 	return nil
 }
 
+// deliveryResult converts a delivery report to a protocol.Result.
+func deliveryResult(e kafka.Event) protocol.Result {
+	switch e := e.(type) {
+	case *kafka.Message:
+		if e.TopicPartition.Error != nil {
+			return protocol.NewReceipt(false, "delivery failed: %w", brokerResult(e.TopicPartition.Error))
+		}
+		return protocol.ResultACK
+	case kafka.Error:
+		return protocol.NewReceipt(false, "delivery failed: %w", brokerResult(e))
+	default:
+		return protocol.NewReceipt(false, "unexpected delivery report: %v", e)
+	}
+}
+
 func (p *Protocol) OpenInbound(ctx context.Context) error {
 	if p.consumer == nil {
 		return errors.New("the consumer client must be set")
@@ -226,6 +268,81 @@ func (p *Protocol) Receive(ctx context.Context) (binding.Message, error) {
 	}
 }
 
+// Pause implements protocol.Pauser.Pause: it pauses the fetching of the
+// partitions currently assigned to the consumer. The partitions assigned by a
+// later rebalance are not paused.
+func (p *Protocol) Pause(ctx context.Context) error {
+	if p.consumer == nil {
+		return errors.New("the consumer client must be set")
+	}
+	partitions, err := p.consumer.Assignment()
+	if err != nil {
+		return err
+	}
+	return p.consumer.Pause(partitions)
+}
+
+// Resume implements protocol.Pauser.Resume: it resumes the fetching of the
+// partitions currently assigned to the consumer.
+func (p *Protocol) Resume(ctx context.Context) error {
+	if p.consumer == nil {
+		return errors.New("the consumer client must be set")
+	}
+	partitions, err := p.consumer.Assignment()
+	if err != nil {
+		return err
+	}
+	return p.consumer.Resume(partitions)
+}
+
+// Ping implements protocol.Pinger, it requests the metadata of the sender
+// topic from the brokers with the producer, and the ones of the receiver
+// topics with the consumer, reporting the topics that are not available. The
+// receiver topics subscribed by regular expression, starting with "^", are
+// not checked.
+func (p *Protocol) Ping(ctx context.Context) error {
+	timeout := defaultPingTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	var errs []error
+	if p.producer != nil && p.producerDefaultTopic != "" {
+		errs = append(errs, pingTopics(p.producer, []string{p.producerDefaultTopic}, timeout))
+	}
+	if p.consumer != nil {
+		errs = append(errs, pingTopics(p.consumer, p.consumerTopics, timeout))
+	}
+	return errors.Join(errs...)
+}
+
+// pingTopics requests the metadata of topics with client, a producer or a
+// consumer, or the metadata of the brokers only if no topic is checked.
+func pingTopics(client interface {
+	GetMetadata(topic *string, allTopics bool, timeoutMs int) (*kafka.Metadata, error)
+}, topics []string, timeout time.Duration) error {
+	var errs []error
+	checked := false
+	for _, topic := range topics {
+		if strings.HasPrefix(topic, "^") {
+			continue
+		}
+		checked = true
+		metadata, err := client.GetMetadata(&topic, false, int(timeout.Milliseconds()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := metadata.Topics[topic].Error; err.Code() != kafka.ErrNoError {
+			errs = append(errs, fmt.Errorf("topic %s: %w", topic, err))
+		}
+	}
+	if !checked {
+		_, err := client.GetMetadata(nil, false, int(timeout.Milliseconds()))
+		return err
+	}
+	return errors.Join(errs...)
+}
+
 // Close cleans up resources after use. Must be called to properly close underlying Kafka resources and avoid resource leaks
 func (p *Protocol) Close(ctx context.Context) error {
 	p.closerMux.Lock()
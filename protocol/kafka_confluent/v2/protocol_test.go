@@ -8,9 +8,12 @@ package kafka_confluent
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 func TestNewProtocol(t *testing.T) {
@@ -67,3 +70,43 @@ func TestNewProtocol(t *testing.T) {
 		})
 	}
 }
+
+func TestDeliveryResult(t *testing.T) {
+	topic := "topic"
+	assert.True(t, protocol.IsACK(deliveryResult(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic}})))
+	assert.True(t, protocol.IsNACK(deliveryResult(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Error: kafka.NewError(kafka.ErrMsgTimedOut, "timed out", false)}})))
+	assert.True(t, protocol.IsNACK(deliveryResult(kafka.NewError(kafka.ErrAllBrokersDown, "down", false))))
+}
+
+func TestDeliveryResultDetails(t *testing.T) {
+	topic := "topic"
+	result := deliveryResult(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Error: kafka.NewError(kafka.ErrNotEnoughReplicas, "not enough replicas", false)}})
+	assert.True(t, protocol.IsRetriable(result))
+	code, ok := protocol.StatusCode(result)
+	assert.True(t, ok)
+	assert.Equal(t, int(kafka.ErrNotEnoughReplicas), code)
+
+	result = deliveryResult(&kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Error: kafka.NewError(kafka.ErrMsgSizeTooLarge, "too large", false)}})
+	assert.True(t, protocol.IsNACK(result))
+	assert.False(t, protocol.IsRetriable(result))
+
+	result = deliveryResult(kafka.NewError(kafka.ErrAllBrokersDown, "down", true))
+	assert.False(t, protocol.IsRetriable(result))
+	err, ok := protocol.BrokerError(result)
+	assert.True(t, ok)
+	assert.Equal(t, kafka.ErrAllBrokersDown, err.(kafka.Error).Code())
+}
+
+func TestPing(t *testing.T) {
+	p, err := New(WithConfigMap(&kafka.ConfigMap{
+		"bootstrap.servers": "127.0.0.1:1",
+	}), WithSenderTopic("topic"))
+	assert.NoError(t, err)
+	// Close flushes the producer until a broker is reachable.
+	defer p.producer.Close()
+
+	// No broker is listening: the metadata request times out.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Error(t, p.Ping(ctx))
+}
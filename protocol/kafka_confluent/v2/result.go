@@ -0,0 +1,47 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka_confluent
+
+import (
+	"errors"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// retriableErrors are the errors of librdkafka and of the Kafka brokers which
+// are transient, as librdkafka only flags the retriable errors of the
+// transactional producer.
+var retriableErrors = map[kafka.ErrorCode]bool{
+	kafka.ErrTransport:                    true,
+	kafka.ErrMsgTimedOut:                  true,
+	kafka.ErrAllBrokersDown:               true,
+	kafka.ErrTimedOut:                     true,
+	kafka.ErrQueueFull:                    true,
+	kafka.ErrTimedOutQueue:                true,
+	kafka.ErrUnknownTopicOrPart:           true,
+	kafka.ErrLeaderNotAvailable:           true,
+	kafka.ErrNotLeaderForPartition:        true,
+	kafka.ErrRequestTimedOut:              true,
+	kafka.ErrBrokerNotAvailable:           true,
+	kafka.ErrReplicaNotAvailable:          true,
+	kafka.ErrNetworkException:             true,
+	kafka.ErrNotEnoughReplicas:            true,
+	kafka.ErrNotEnoughReplicasAfterAppend: true,
+	kafka.ErrKafkaStorageError:            true,
+	kafka.ErrThrottlingQuotaExceeded:      true,
+}
+
+// brokerResult returns the protocol.BrokerResult of err, with the Kafka error
+// code as status code if err is a kafka.Error.
+func brokerResult(err error) error {
+	var kerr kafka.Error
+	if !errors.As(err, &kerr) {
+		return protocol.NewBrokerResult(err, false, 0)
+	}
+	transient := !kerr.IsFatal() && (kerr.IsRetriable() || retriableErrors[kerr.Code()])
+	return protocol.NewBrokerResult(err, transient, int(kerr.Code()))
+}
@@ -0,0 +1,91 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka_sarama
+
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+)
+
+type committerKeyType struct{}
+
+var committerKey = committerKeyType{}
+
+// Committer commits the offset of a message consumed by a Receiver.
+// It is available to the receiver function through CommitterFrom.
+type Committer struct {
+	receiver *Receiver
+	session  sarama.ConsumerGroupSession
+	msg      *sarama.ConsumerMessage
+}
+
+// CommitterFrom returns the Committer of the message being handled, or nil
+// if the message has not been consumed by a kafka_sarama Receiver.
+func CommitterFrom(ctx context.Context) *Committer {
+	c, _ := ctx.Value(committerKey).(*Committer)
+	return c
+}
+
+// Topic returns the topic of the consumed message.
+func (c *Committer) Topic() string {
+	return c.msg.Topic
+}
+
+// Partition returns the partition of the consumed message.
+func (c *Committer) Partition() int32 {
+	return c.msg.Partition
+}
+
+// Offset returns the offset of the consumed message.
+func (c *Committer) Offset() int64 {
+	return c.msg.Offset
+}
+
+// Commit marks the message as consumed. Its offset is committed to the
+// broker with the next commit: either by sarama, if
+// Config.Consumer.Offsets.AutoCommit is enabled, or by the Receiver, if
+// WithCommitInterval is used. Committing an offset also commits the offsets
+// before it in the same partition.
+func (c *Committer) Commit() {
+	c.session.MarkMessage(c.msg, "")
+}
+
+// CommitSync marks the message as consumed and synchronously commits the
+// marked offsets of the session to the broker.
+func (c *Committer) CommitSync() {
+	c.Commit()
+	c.receiver.commit(c.session)
+}
+
+// consumerMessage is a consumed Message exposing the Committer in its
+// context, see binding.MessageContext.
+type consumerMessage struct {
+	*Message
+	ctx    context.Context
+	finish func(error)
+}
+
+func (m *consumerMessage) Context() context.Context {
+	return m.ctx
+}
+
+func (m *consumerMessage) GetWrappedMessage() binding.Message {
+	return m.Message
+}
+
+func (m *consumerMessage) Finish(err error) error {
+	err2 := m.Message.Finish(err)
+	m.finish(err)
+	return err2
+}
+
+var (
+	_ binding.MessageWrapper = (*consumerMessage)(nil)
+	_ binding.MessageContext = (*consumerMessage)(nil)
+)
@@ -0,0 +1,41 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka_sarama
+
+import (
+	"strconv"
+
+	"github.com/cloudevents/sdk-go/v2/observability"
+)
+
+// Metrics emitted by the Receiver, see WithMetrics.
+const (
+	// MetricConsumerLag is a gauge of the number of messages of a partition
+	// not consumed yet, updated on every consumed message.
+	MetricConsumerLag = "messaging.kafka.consumer.lag"
+	// MetricAssignedPartitions is a gauge of the number of partitions of a
+	// topic assigned to the Receiver.
+	MetricAssignedPartitions = "messaging.kafka.consumer.assigned_partitions"
+	// MetricAssignmentChanges is a counter of the consumer group sessions
+	// started, i.e. of the partition assignment changes.
+	MetricAssignmentChanges = "messaging.kafka.consumer.assignment_changes"
+	// MetricCommitDuration is a histogram of the duration of the offset
+	// commits made by the Receiver.
+	MetricCommitDuration = "messaging.kafka.consumer.commit.duration"
+
+	// AttrTopic is the attribute holding the topic of a measurement.
+	AttrTopic = "messaging.destination.name"
+	// AttrPartition is the attribute holding the partition of a measurement.
+	AttrPartition = "messaging.kafka.destination.partition"
+)
+
+func topicAttr(topic string) observability.Attribute {
+	return observability.Attribute{Key: AttrTopic, Value: topic}
+}
+
+func partitionAttr(partition int32) observability.Attribute {
+	return observability.Attribute{Key: AttrPartition, Value: strconv.FormatInt(int64(partition), 10)}
+}
@@ -7,14 +7,36 @@ package kafka_sarama
 
 import (
 	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
+// ConfigureTLS secures the connections to the brokers of saramaConfig with
+// the shared protocol.TLSOption set. It must be called before saramaConfig is
+// given to a constructor.
+func ConfigureTLS(saramaConfig *sarama.Config, opts ...protocol.TLSOption) error {
+	config, err := protocol.NewTLSConfig(opts...)
+	if err != nil {
+		return err
+	}
+	saramaConfig.Net.TLS.Enable = true
+	saramaConfig.Net.TLS.Config = config
+	return nil
+}
+
 // SenderOptionFunc is the type of kafka_sarama.Sender options
 type SenderOptionFunc func(sender *Sender)
 
 // ProtocolOptionFunc is the type of kafka_sarama.Protocol options
 type ProtocolOptionFunc func(protocol *Protocol)
 
+// ReceiverOptionFunc is the type of kafka_sarama.Receiver options
+type ReceiverOptionFunc func(receiver *Receiver)
+
 func WithReceiverGroupId(groupId string) ProtocolOptionFunc {
 	return func(protocol *Protocol) {
 		protocol.receiverGroupId = groupId
@@ -26,3 +48,39 @@ func WithSenderContextDecorators(decorator func(context.Context) context.Context
 		protocol.SenderContextDecorators = append(protocol.SenderContextDecorators, decorator)
 	}
 }
+
+// WithReceiverOptions sets the options of the Receiver consuming the messages.
+func WithReceiverOptions(opts ...ReceiverOptionFunc) ProtocolOptionFunc {
+	return func(protocol *Protocol) {
+		protocol.receiverOpts = append(protocol.receiverOpts, opts...)
+	}
+}
+
+// WithManualCommit disables marking the consumed messages when they are
+// acknowledged: the receiver function is responsible for committing them
+// with the Committer returned by CommitterFrom, e.g. once its downstream side
+// effects succeeded.
+func WithManualCommit() ReceiverOptionFunc {
+	return func(receiver *Receiver) {
+		receiver.manualCommit = true
+	}
+}
+
+// WithCommitInterval makes the Receiver commit the marked offsets to the
+// broker every interval, and when the consumer group session ends. It should
+// be used with sarama Config.Consumer.Offsets.AutoCommit disabled.
+func WithCommitInterval(interval time.Duration) ReceiverOptionFunc {
+	return func(receiver *Receiver) {
+		receiver.commitInterval = interval
+	}
+}
+
+// WithMetrics sets the Metrics recording the consumer lag, the partition
+// assignment changes and the commit latency of the Receiver.
+func WithMetrics(metrics observability.Metrics) ReceiverOptionFunc {
+	return func(receiver *Receiver) {
+		if metrics != nil {
+			receiver.metrics = metrics
+		}
+	}
+}
@@ -0,0 +1,27 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka_sarama
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestConfigureTLS(t *testing.T) {
+	config := sarama.NewConfig()
+	require.NoError(t, ConfigureTLS(config, protocol.WithTLSMinVersion(tls.VersionTLS13)))
+	require.True(t, config.Net.TLS.Enable)
+	require.Equal(t, uint16(tls.VersionTLS13), config.Net.TLS.Config.MinVersion)
+
+	config = sarama.NewConfig()
+	require.Error(t, ConfigureTLS(config, protocol.WithTLSRootCAs(nil)))
+	require.False(t, config.Net.TLS.Enable)
+}
@@ -8,6 +8,7 @@ package kafka_sarama
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/IBM/sarama"
@@ -40,6 +41,7 @@ type Protocol struct {
 	// Consumer options
 	receiverTopic   string
 	receiverGroupId string
+	receiverOpts    []ReceiverOptionFunc
 }
 
 // NewProtocol creates a new kafka transport.
@@ -86,7 +88,7 @@ func NewProtocolFromClient(client sarama.Client, sendToTopic string, receiveFrom
 	if p.receiverTopic == "" {
 		return nil, errors.New("you didn't specify the topic to receive from")
 	}
-	p.Consumer = NewConsumerFromClient(p.Client, p.receiverGroupId, p.receiverTopic)
+	p.Consumer = NewConsumerFromClient(p.Client, p.receiverGroupId, p.receiverTopic, p.receiverOpts...)
 
 	return p, nil
 }
@@ -121,6 +123,30 @@ func (p *Protocol) Receive(ctx context.Context) (binding.Message, error) {
 	return p.Consumer.Receive(ctx)
 }
 
+// Ping implements Pinger.Ping: it refreshes the metadata of the topics to send
+// to and to receive from, which connects and authenticates to the brokers,
+// checks that the topics exist, then finds the coordinator of the consumer
+// group. The requests are bounded by the timeouts of the sarama config rather
+// than by ctx.
+func (p *Protocol) Ping(ctx context.Context) error {
+	topics := []string{p.senderTopic}
+	if p.receiverTopic != p.senderTopic {
+		topics = append(topics, p.receiverTopic)
+	}
+	if err := p.Client.RefreshMetadata(topics...); err != nil {
+		return sendResult(fmt.Errorf("failed to refresh the metadata of %v: %w", topics, err))
+	}
+	for _, topic := range topics {
+		if _, err := p.Client.Partitions(topic); err != nil {
+			return sendResult(fmt.Errorf("topic %q unavailable: %w", topic, err))
+		}
+	}
+	if _, err := p.Client.Coordinator(p.receiverGroupId); err != nil {
+		return sendResult(fmt.Errorf("coordinator of group %q unavailable: %w", p.receiverGroupId, err))
+	}
+	return nil
+}
+
 func (p *Protocol) Close(ctx context.Context) error {
 	if p.ownsClient {
 		// Just closing the client here closes at cascade consumer and producer
@@ -136,3 +162,4 @@ func (p *Protocol) Close(ctx context.Context) error {
 var _ protocol.Sender = (*Protocol)(nil)
 var _ protocol.Receiver = (*Protocol)(nil)
 var _ protocol.Closer = (*Protocol)(nil)
+var _ protocol.Pinger = (*Protocol)(nil)
@@ -0,0 +1,45 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka_sarama
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestProtocolPing(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	metadata := sarama.NewMockMetadataResponse(t).
+		SetBroker(broker.Addr(), broker.BrokerID()).
+		SetLeader("orders", 0, broker.BrokerID())
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest":    metadata,
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, defaultGroupId, broker),
+	})
+
+	config := sarama.NewConfig()
+	config.Metadata.Retry.Max = 0
+	p, err := NewProtocol([]string{broker.Addr()}, config, "orders", "orders")
+	require.NoError(t, err)
+	defer p.Close(context.Background())
+	require.NoError(t, p.Ping(context.Background()))
+
+	// The topic to receive from does not exist.
+	other, err := NewProtocolFromClient(p.Client, "orders", "payments")
+	require.NoError(t, err)
+	err = other.Ping(context.Background())
+	require.ErrorIs(t, err, sarama.ErrUnknownTopicOrPartition)
+	_, ok := protocol.BrokerError(err)
+	require.True(t, ok)
+}
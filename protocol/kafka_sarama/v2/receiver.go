@@ -9,9 +9,11 @@ import (
 	"context"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/observability"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
@@ -25,25 +27,99 @@ type msgErr struct {
 type Receiver struct {
 	once     sync.Once
 	incoming chan msgErr
+
+	manualCommit   bool
+	commitInterval time.Duration
+	metrics        observability.Metrics
+
+	commitMu    sync.Mutex
+	stopCommits chan struct{}
+	commitsDone chan struct{}
 }
 
 // NewReceiver creates a Receiver which implements sarama.ConsumerGroupHandler
 // The sarama.ConsumerGroup must be started invoking. If you need a Receiver which also manage the ConsumerGroup, use NewConsumer
 // After the first invocation of Receiver.Receive(), the sarama.ConsumerGroup is created and started.
-func NewReceiver() *Receiver {
-	return &Receiver{
+func NewReceiver(opts ...ReceiverOptionFunc) *Receiver {
+	r := &Receiver{
 		incoming: make(chan msgErr),
 	}
+	r.applyOptions(opts...)
+	return r
+}
+
+func (r *Receiver) applyOptions(opts ...ReceiverOptionFunc) {
+	r.metrics = observability.NoopMetrics{}
+	for _, fn := range opts {
+		fn(r)
+	}
 }
 
-func (r *Receiver) Setup(sarama.ConsumerGroupSession) error {
+func (r *Receiver) Setup(session sarama.ConsumerGroupSession) error {
+	r.recordAssignment(session, true)
+	if r.commitInterval > 0 {
+		r.commitMu.Lock()
+		r.stopCommits = make(chan struct{})
+		r.commitsDone = make(chan struct{})
+		go r.commitLoop(session, r.stopCommits, r.commitsDone)
+		r.commitMu.Unlock()
+	}
 	return nil
 }
 
-func (r *Receiver) Cleanup(sarama.ConsumerGroupSession) error {
+func (r *Receiver) Cleanup(session sarama.ConsumerGroupSession) error {
+	r.recordAssignment(session, false)
+	r.commitMu.Lock()
+	defer r.commitMu.Unlock()
+	if r.stopCommits != nil {
+		close(r.stopCommits)
+		<-r.commitsDone
+		r.stopCommits, r.commitsDone = nil, nil
+		// Commit the offsets marked since the last tick.
+		r.commit(session)
+	}
 	return nil
 }
 
+// recordAssignment records the partitions assigned to the session when it
+// starts, and resets them when it ends.
+func (r *Receiver) recordAssignment(session sarama.ConsumerGroupSession, start bool) {
+	if start {
+		r.metrics.AddCounter(MetricAssignmentChanges, 1)
+	}
+	for topic, partitions := range session.Claims() {
+		assigned := int64(0)
+		if start {
+			assigned = int64(len(partitions))
+		}
+		r.metrics.SetGauge(MetricAssignedPartitions, assigned, topicAttr(topic))
+	}
+}
+
+// commit synchronously commits the marked offsets of the session.
+func (r *Receiver) commit(session sarama.ConsumerGroupSession) {
+	start := time.Now()
+	session.Commit()
+	r.metrics.RecordDuration(MetricCommitDuration, time.Since(start))
+}
+
+// commitLoop commits the marked offsets of the session every commit interval.
+func (r *Receiver) commitLoop(session sarama.ConsumerGroupSession, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(r.commitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.commit(session)
+		case <-stop:
+			return
+		case <-session.Context().Done():
+			return
+		}
+	}
+}
+
 func (r *Receiver) Close(context.Context) error {
 	r.once.Do(func() {
 		close(r.incoming)
@@ -65,13 +141,18 @@ func (r *Receiver) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 			if !ok {
 				return nil
 			}
-			m := NewMessageFromConsumerMessage(msg)
+			r.metrics.SetGauge(MetricConsumerLag, claim.HighWaterMarkOffset()-msg.Offset-1, topicAttr(msg.Topic), partitionAttr(msg.Partition))
+			committer := &Committer{receiver: r, session: session, msg: msg}
 			msgErrObj := msgErr{
-				msg: binding.WithFinish(m, func(err error) {
-					if protocol.IsACK(err) {
-						session.MarkMessage(msg, "")
-					}
-				}),
+				msg: &consumerMessage{
+					Message: NewMessageFromConsumerMessage(msg),
+					ctx:     context.WithValue(session.Context(), committerKey, committer),
+					finish: func(err error) {
+						if !r.manualCommit && protocol.IsACK(err) {
+							committer.Commit()
+						}
+					},
+				},
 			}
 
 			// Need to use select clause here, otherwise r.incoming <- msgErrObj can become a blocking operation,
@@ -123,22 +204,27 @@ type Consumer struct {
 	groupId string
 
 	cgMtx sync.Mutex
+
+	// pauseMtx guards cg and paused.
+	pauseMtx sync.Mutex
+	cg       sarama.ConsumerGroup
+	paused   bool
 }
 
-func NewConsumer(brokers []string, saramaConfig *sarama.Config, groupId string, topic string) (*Consumer, error) {
+func NewConsumer(brokers []string, saramaConfig *sarama.Config, groupId string, topic string, opts ...ReceiverOptionFunc) (*Consumer, error) {
 	client, err := sarama.NewClient(brokers, saramaConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	consumer := NewConsumerFromClient(client, groupId, topic)
+	consumer := NewConsumerFromClient(client, groupId, topic, opts...)
 	consumer.ownClient = true
 
 	return consumer, nil
 }
 
-func NewConsumerFromClient(client sarama.Client, groupId string, topic string) *Consumer {
-	return &Consumer{
+func NewConsumerFromClient(client sarama.Client, groupId string, topic string, opts ...ReceiverOptionFunc) *Consumer {
+	c := &Consumer{
 		Receiver: Receiver{
 			incoming: make(chan msgErr),
 		},
@@ -147,6 +233,8 @@ func NewConsumerFromClient(client sarama.Client, groupId string, topic string) *
 		groupId:   groupId,
 		ownClient: false,
 	}
+	c.Receiver.applyOptions(opts...)
+	return c
 }
 
 func (c *Consumer) OpenInbound(ctx context.Context) error {
@@ -156,6 +244,12 @@ func (c *Consumer) OpenInbound(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	c.pauseMtx.Lock()
+	c.cg = cg
+	if c.paused {
+		cg.PauseAll()
+	}
+	c.pauseMtx.Unlock()
 
 	errCh := make(chan error)
 
@@ -208,6 +302,29 @@ func (c *Consumer) startConsumerGroupLoop(cg sarama.ConsumerGroup, ctx context.C
 	}
 }
 
+// Pause implements protocol.Pauser.Pause: it pauses the fetching of all the
+// partitions of the consumer group, while the session is kept alive.
+func (c *Consumer) Pause(ctx context.Context) error {
+	c.pauseMtx.Lock()
+	defer c.pauseMtx.Unlock()
+	c.paused = true
+	if c.cg != nil {
+		c.cg.PauseAll()
+	}
+	return nil
+}
+
+// Resume implements protocol.Pauser.Resume.
+func (c *Consumer) Resume(ctx context.Context) error {
+	c.pauseMtx.Lock()
+	defer c.pauseMtx.Unlock()
+	c.paused = false
+	if c.cg != nil {
+		c.cg.ResumeAll()
+	}
+	return nil
+}
+
 func (c *Consumer) Close(ctx context.Context) error {
 	if c.ownClient {
 		return c.client.Close()
@@ -216,4 +333,5 @@ func (c *Consumer) Close(ctx context.Context) error {
 }
 
 var _ protocol.Opener = (*Consumer)(nil)
+var _ protocol.Pauser = (*Consumer)(nil)
 var _ protocol.Closer = (*Consumer)(nil)
@@ -0,0 +1,199 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka_sarama
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+type sessionMock struct {
+	sarama.ConsumerGroupSession
+	ctx context.Context
+
+	lock      sync.Mutex
+	marked    []int64
+	commits   int
+	committed []int64
+}
+
+func (s *sessionMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *sessionMock) Claims() map[string][]int32 {
+	return map[string][]int32{"topic": {0, 1}}
+}
+
+func (s *sessionMock) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.marked = append(s.marked, msg.Offset)
+}
+
+func (s *sessionMock) Commit() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.commits++
+	s.committed = append([]int64(nil), s.marked...)
+}
+
+func (s *sessionMock) state() (marked []int64, commits int, committed []int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]int64(nil), s.marked...), s.commits, append([]int64(nil), s.committed...)
+}
+
+type claimMock struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *claimMock) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+func (c *claimMock) HighWaterMarkOffset() int64 {
+	return 10
+}
+
+type metricsMock struct {
+	lock     sync.Mutex
+	counters map[string]int64
+	gauges   map[string]int64
+	commits  int
+}
+
+func (m *metricsMock) key(name string, attrs []observability.Attribute) string {
+	for _, a := range attrs {
+		name += "," + a.Key + "=" + a.Value
+	}
+	return name
+}
+
+func (m *metricsMock) AddCounter(name string, delta int64, attrs ...observability.Attribute) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.counters[m.key(name, attrs)] += delta
+}
+
+func (m *metricsMock) SetGauge(name string, value int64, attrs ...observability.Attribute) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.gauges[m.key(name, attrs)] = value
+}
+
+func (m *metricsMock) RecordDuration(name string, d time.Duration, attrs ...observability.Attribute) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if name == MetricCommitDuration {
+		m.commits++
+	}
+}
+
+// consume runs ConsumeClaim on r and returns the received messages.
+func consume(t *testing.T, r *Receiver, session *sessionMock, n int) []binding.Message {
+	claim := &claimMock{messages: make(chan *sarama.ConsumerMessage, n)}
+	for i := 0; i < n; i++ {
+		claim.messages <- &sarama.ConsumerMessage{Topic: "topic", Partition: 1, Offset: int64(i)}
+	}
+	close(claim.messages)
+	go func() {
+		require.NoError(t, r.ConsumeClaim(session, claim))
+	}()
+
+	var msgs []binding.Message
+	for i := 0; i < n; i++ {
+		m, err := r.Receive(context.Background())
+		require.NoError(t, err)
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func TestReceiverMarksAcknowledgedMessages(t *testing.T) {
+	session := &sessionMock{ctx: context.Background()}
+	msgs := consume(t, NewReceiver(), session, 2)
+
+	require.NoError(t, msgs[0].Finish(protocol.ResultNACK))
+	require.NoError(t, msgs[1].Finish(nil))
+	marked, _, _ := session.state()
+	require.Equal(t, []int64{1}, marked)
+}
+
+func TestReceiverManualCommit(t *testing.T) {
+	session := &sessionMock{ctx: context.Background()}
+	msgs := consume(t, NewReceiver(WithManualCommit()), session, 2)
+
+	ctx := msgs[1].(binding.MessageContext).Context()
+	committer := CommitterFrom(ctx)
+	require.NotNil(t, committer)
+	require.Equal(t, "topic", committer.Topic())
+	require.Equal(t, int32(1), committer.Partition())
+	require.Equal(t, int64(1), committer.Offset())
+
+	require.NoError(t, msgs[0].Finish(nil))
+	marked, _, _ := session.state()
+	require.Empty(t, marked)
+
+	committer.CommitSync()
+	marked, commits, committed := session.state()
+	require.Equal(t, []int64{1}, marked)
+	require.Equal(t, 1, commits)
+	require.Equal(t, []int64{1}, committed)
+
+	require.Nil(t, CommitterFrom(context.Background()))
+}
+
+func TestReceiverCommitInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	session := &sessionMock{ctx: ctx}
+	r := NewReceiver(WithCommitInterval(time.Millisecond))
+	require.NoError(t, r.Setup(session))
+
+	msgs := consume(t, r, session, 1)
+	require.NoError(t, msgs[0].Finish(nil))
+	require.Eventually(t, func() bool {
+		_, _, committed := session.state()
+		return len(committed) == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, r.Cleanup(session))
+	_, commits, _ := session.state()
+	time.Sleep(10 * time.Millisecond)
+	_, after, _ := session.state()
+	require.Equal(t, commits, after, "no commit after cleanup")
+}
+
+func TestReceiverMetrics(t *testing.T) {
+	metrics := &metricsMock{counters: map[string]int64{}, gauges: map[string]int64{}}
+	session := &sessionMock{ctx: context.Background()}
+	r := NewReceiver(WithManualCommit(), WithMetrics(metrics))
+
+	require.NoError(t, r.Setup(session))
+	require.Equal(t, int64(1), metrics.counters[MetricAssignmentChanges])
+	require.Equal(t, int64(2), metrics.gauges[MetricAssignedPartitions+","+AttrTopic+"=topic"])
+
+	msgs := consume(t, r, session, 3)
+	lag := MetricConsumerLag + "," + AttrTopic + "=topic," + AttrPartition + "=1"
+	require.Equal(t, int64(7), metrics.gauges[lag])
+
+	CommitterFrom(msgs[2].(binding.MessageContext).Context()).CommitSync()
+	require.Equal(t, 1, metrics.commits)
+
+	require.NoError(t, r.Cleanup(session))
+	require.Equal(t, int64(0), metrics.gauges[MetricAssignedPartitions+","+AttrTopic+"=topic"])
+}
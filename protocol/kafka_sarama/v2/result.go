@@ -0,0 +1,52 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka_sarama
+
+import (
+	"errors"
+
+	"github.com/IBM/sarama"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// retriableErrors are the errors of the Kafka brokers which are transient, as
+// classified by the Kafka clients.
+var retriableErrors = map[sarama.KError]bool{
+	sarama.ErrInvalidMessage:                  true,
+	sarama.ErrUnknownTopicOrPartition:         true,
+	sarama.ErrLeaderNotAvailable:              true,
+	sarama.ErrNotLeaderForPartition:           true,
+	sarama.ErrRequestTimedOut:                 true,
+	sarama.ErrBrokerNotAvailable:              true,
+	sarama.ErrReplicaNotAvailable:             true,
+	sarama.ErrNetworkException:                true,
+	sarama.ErrOffsetsLoadInProgress:           true,
+	sarama.ErrConsumerCoordinatorNotAvailable: true,
+	sarama.ErrNotCoordinatorForConsumer:       true,
+	sarama.ErrNotEnoughReplicas:               true,
+	sarama.ErrNotEnoughReplicasAfterAppend:    true,
+	sarama.ErrKafkaStorageError:               true,
+	sarama.ErrNotController:                   true,
+	sarama.ErrFencedLeaderEpoch:               true,
+	sarama.ErrUnknownLeaderEpoch:              true,
+	sarama.ErrOffsetNotAvailable:              true,
+	sarama.ErrThrottlingQuotaExceeded:         true,
+}
+
+// sendResult returns the protocol.BrokerResult of the error of a send, with
+// the Kafka error code as status code.
+func sendResult(err error) error {
+	if err == nil {
+		return nil
+	}
+	var kerr sarama.KError
+	if errors.As(err, &kerr) {
+		return protocol.NewBrokerResult(err, retriableErrors[kerr], int(kerr))
+	}
+	transient := errors.Is(err, sarama.ErrOutOfBrokers) || errors.Is(err, sarama.ErrNotConnected)
+	return protocol.NewBrokerResult(err, transient, 0)
+}
@@ -0,0 +1,40 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kafka_sarama
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestSendResult(t *testing.T) {
+	require.NoError(t, sendResult(nil))
+
+	result := sendResult(&sarama.ProducerError{Err: sarama.ErrNotEnoughReplicas})
+	require.True(t, protocol.IsRetriable(result))
+	code, ok := protocol.StatusCode(result)
+	require.True(t, ok)
+	require.Equal(t, int(sarama.ErrNotEnoughReplicas), code)
+	require.ErrorIs(t, result, sarama.ErrNotEnoughReplicas)
+
+	result = sendResult(&sarama.ProducerError{Err: sarama.ErrMessageSizeTooLarge})
+	require.False(t, protocol.IsRetriable(result))
+
+	result = sendResult(sarama.ErrOutOfBrokers)
+	require.True(t, protocol.IsRetriable(result))
+	_, ok = protocol.StatusCode(result)
+	require.False(t, ok)
+
+	result = sendResult(errors.New("invalid"))
+	require.False(t, protocol.IsRetriable(result))
+	_, ok = protocol.BrokerError(result)
+	require.True(t, ok)
+}
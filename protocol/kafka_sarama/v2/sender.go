@@ -11,6 +11,7 @@ import (
 	"github.com/IBM/sarama"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 // Sender implements binding.Sender that sends messages to a specific receiverTopic using sarama.SyncProducer
@@ -76,7 +77,33 @@ func (s *Sender) Send(ctx context.Context, m binding.Message, transformers ...bi
 	if err == sarama.ErrClosedClient {
 		return nil
 	}
-	return err
+	return sendResult(err)
+}
+
+// SendAsync writes the message and sends it from another goroutine, invoking
+// fn with the result. Concurrent sends are batched by the sarama.SyncProducer.
+func (s *Sender) SendAsync(ctx context.Context, m binding.Message, fn protocol.ResultFn, transformers ...binding.Transformer) error {
+	kafkaMessage := sarama.ProducerMessage{Topic: s.topic}
+
+	if k := ctx.Value(withMessageKey{}); k != nil {
+		kafkaMessage.Key = k.(sarama.Encoder)
+	}
+
+	if err := WriteProducerMessage(ctx, m, &kafkaMessage, transformers...); err != nil {
+		_ = m.Finish(err)
+		return err
+	}
+
+	go func() {
+		_, _, err := s.syncProducer.SendMessage(&kafkaMessage)
+		// Somebody closed the client while sending the message, so no problem here
+		if err == sarama.ErrClosedClient {
+			err = nil
+		}
+		_ = m.Finish(err)
+		fn(sendResult(err))
+	}()
+	return nil
 }
 
 func (s *Sender) Close(ctx context.Context) error {
@@ -13,6 +13,7 @@ import (
 	"github.com/IBM/sarama"
 	"github.com/stretchr/testify/require"
 
+	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/cloudevents/sdk-go/v2/test"
 )
 
@@ -105,3 +106,18 @@ func TestSenderWithKey(t *testing.T) {
 	require.Equal(t, kafkaMsg.Topic, topic)
 	require.Equal(t, kafkaMsg.Key, sarama.StringEncoder("hello"))
 }
+
+func TestSenderSendAsync(t *testing.T) {
+	syncProducerMock := &syncProducerMock{}
+	topic := "aaa"
+
+	sender := &Sender{topic: topic, syncProducer: syncProducerMock}
+	results := make(chan protocol.Result, 1)
+	require.NoError(t, sender.SendAsync(context.TODO(), test.FullMessage(), func(r protocol.Result) {
+		results <- r
+	}))
+
+	require.NoError(t, <-results)
+	require.Len(t, syncProducerMock.sent, 1)
+	require.Equal(t, syncProducerMock.sent[0].Topic, topic)
+}
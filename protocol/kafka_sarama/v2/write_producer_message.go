@@ -26,15 +26,24 @@ const (
 // Using context you can tweak the encoding processing (more details on binding.Write documentation).
 // By default, this function implements the key mapping, trying to set the key of the message based on partitionKey extension.
 // If you want to disable the Key Mapping, decorate the context with `WithSkipKeyMapping`
+// If the context has a KeyEncoder, see WithKeyEncoder, it encodes the key instead.
 func WriteProducerMessage(ctx context.Context, m binding.Message, producerMessage *sarama.ProducerMessage, transformers ...binding.Transformer) error {
 	writer := (*kafkaProducerMessageWriter)(producerMessage)
 
 	skipKey := binding.GetOrDefaultFromCtx(ctx, skipKeyKey{}, false).(bool)
+	keyEncoder, _ := binding.GetOrDefaultFromCtx(ctx, keyEncoderKey{}, nil).(KeyEncoder)
 
 	var key string
+	var encodedKey []byte
 
-	// If skipKey = false, then we add a transformer that extracts the key
-	if !skipKey {
+	if !skipKey && keyEncoder != nil {
+		transformers = append(transformers, binding.TransformerFunc(func(r binding.MessageMetadataReader, w binding.MessageMetadataWriter) error {
+			var err error
+			encodedKey, err = keyEncoder.EncodeKey(ctx, producerMessage.Topic, r)
+			return err
+		}))
+	} else if !skipKey {
+		// If skipKey = false, then we add a transformer that extracts the key
 		transformers = append(transformers, binding.TransformerFunc(func(r binding.MessageMetadataReader, w binding.MessageMetadataWriter) error {
 			ext := r.GetExtension(partitionKey)
 			if !types.IsZero(ext) {
@@ -58,6 +67,9 @@ func WriteProducerMessage(ctx context.Context, m binding.Message, producerMessag
 	if key != "" {
 		producerMessage.Key = sarama.StringEncoder(key)
 	}
+	if encodedKey != nil {
+		producerMessage.Key = sarama.ByteEncoder(encodedKey)
+	}
 	return err
 }
 
@@ -159,5 +171,21 @@ func WithSkipKeyMapping(ctx context.Context) context.Context {
 	return context.WithValue(ctx, skipKeyKey{}, true)
 }
 
+// KeyEncoder encodes the keys of the producer messages from the attributes of
+// the events they carry, e.g. as Avro records registered in a schema registry
+// (see the KeyEncoder of github.com/cloudevents/sdk-go/binding/format/avro/v2).
+type KeyEncoder interface {
+	EncodeKey(ctx context.Context, topic string, r binding.MessageMetadataReader) ([]byte, error)
+}
+
+type keyEncoderKey struct{}
+
+// WithKeyEncoder returns a context making WriteProducerMessage encode the key
+// of the messages with enc instead of mapping the partitionkey extension.
+// Use it with WithSenderContextDecorators to encode the keys of a Protocol.
+func WithKeyEncoder(ctx context.Context, enc KeyEncoder) context.Context {
+	return context.WithValue(ctx, keyEncoderKey{}, enc)
+}
+
 var _ binding.StructuredWriter = (*kafkaProducerMessageWriter)(nil) // Test it conforms to the interface
 var _ binding.BinaryWriter = (*kafkaProducerMessageWriter)(nil)     // Test it conforms to the interface
@@ -14,9 +14,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
 	. "github.com/cloudevents/sdk-go/v2/binding/test"
 	"github.com/cloudevents/sdk-go/v2/event"
 	. "github.com/cloudevents/sdk-go/v2/test"
+	"github.com/cloudevents/sdk-go/v2/types"
 )
 
 const testKey = "hello-key"
@@ -197,3 +199,34 @@ func TestEncodeKafkaProducerMessage(t *testing.T) {
 	})
 
 }
+
+type sourceKeyEncoder struct{}
+
+func (sourceKeyEncoder) EncodeKey(ctx context.Context, topic string, r binding.MessageMetadataReader) ([]byte, error) {
+	_, source := r.GetAttribute(spec.Source)
+	s, err := types.Format(source)
+	return []byte(topic + "/" + s), err
+}
+
+func TestEncodeKafkaProducerMessageKeyEncoder(t *testing.T) {
+	e := FullEvent()
+	e.SetExtension(partitionKey, testKey)
+	ctx := WithKeyEncoder(context.TODO(), sourceKeyEncoder{})
+
+	for name, m := range map[string]binding.Message{
+		"binary":     MustCreateMockBinaryMessage(e),
+		"structured": MustCreateMockStructuredMessage(t, e),
+	} {
+		t.Run(name, func(t *testing.T) {
+			producerMessage := &sarama.ProducerMessage{Topic: "orders"}
+			require.NoError(t, WriteProducerMessage(ctx, m, producerMessage))
+			key, err := producerMessage.Key.Encode()
+			require.NoError(t, err)
+			require.Equal(t, "orders/"+e.Source(), string(key))
+		})
+	}
+
+	producerMessage := &sarama.ProducerMessage{Topic: "orders"}
+	require.NoError(t, WriteProducerMessage(WithSkipKeyMapping(ctx), MustCreateMockBinaryMessage(e), producerMessage))
+	require.Nil(t, producerMessage.Key)
+}
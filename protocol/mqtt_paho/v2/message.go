@@ -13,6 +13,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/format"
 	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/eclipse/paho.golang/paho"
 )
 
@@ -34,6 +35,8 @@ type Message struct {
 var (
 	_ binding.Message               = (*Message)(nil)
 	_ binding.MessageMetadataReader = (*Message)(nil)
+
+	_ protocol.TransportMetadataReader = (*Message)(nil)
 )
 
 func NewMessage(msg *paho.Publish) *Message {
@@ -121,3 +124,16 @@ func (m *Message) GetAttribute(k spec.Kind) (spec.Attribute, interface{}) {
 func (m *Message) GetExtension(name string) interface{} {
 	return m.internal.Properties.User.Get(prefix + name)
 }
+
+// TransportMetadata implements protocol.TransportMetadataReader, it returns
+// the topic of the message, and its user properties as headers.
+func (m *Message) TransportMetadata() protocol.TransportMetadata {
+	md := protocol.TransportMetadata{Topic: m.internal.Topic}
+	if m.internal.Properties != nil && len(m.internal.Properties.User) > 0 {
+		md.Header = make(map[string][]string, len(m.internal.Properties.User))
+		for _, p := range m.internal.Properties.User {
+			md.Header[p.Key] = append(md.Header[p.Key], p.Value)
+		}
+	}
+	return md
+}
@@ -6,10 +6,20 @@
 package mqtt_paho
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
 
 	"github.com/eclipse/paho.golang/paho"
 	"github.com/eclipse/paho.golang/paho/log"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 // Option is the function signature required to be considered an mqtt_paho.Option.
@@ -48,10 +58,85 @@ func WithSubscribe(subscribeOpt *paho.Subscribe) Option {
 	}
 }
 
+// WithSharedSubscription subscribes to the topics of the paho.Subscribe
+// configuration as members of the MQTT 5 shared subscription group, i.e. to
+// $share/<group>/<topic>, so that the broker delivers each message to only one
+// of the receivers of the group, e.g. the replicas of a service.
+func WithSharedSubscription(group string) Option {
+	return func(p *Protocol) error {
+		if group == "" || strings.ContainsAny(group, "/+#") {
+			return fmt.Errorf("the shared subscription group %q must be non empty and must not contain '/', '+' or '#'", group)
+		}
+		p.shareGroup = group
+		return nil
+	}
+}
+
+// SharedTopic returns the topic filter subscribing to filter as a member of
+// the shared subscription group.
+func SharedTopic(group, filter string) string {
+	return "$share/" + group + "/" + filter
+}
+
+// WithSession configures the session state kept by the broker: with cleanStart
+// false, the connections resume the existing session, including the
+// subscriptions and the messages queued while disconnected, and the session
+// is kept for expiry after the connection is closed (rounded down to the
+// second, at most math.MaxUint32 seconds which never expires). It overrides
+// the CleanStart and SessionExpiryInterval of the paho.Connect configuration.
+// A resumed session needs a stable client ID in the paho.Connect configuration.
+func WithSession(expiry time.Duration, cleanStart bool) Option {
+	return func(p *Protocol) error {
+		if expiry < 0 {
+			return fmt.Errorf("the session expiry must not be negative, got %v", expiry)
+		}
+		seconds := expiry / time.Second
+		if seconds > math.MaxUint32 {
+			seconds = math.MaxUint32
+		}
+		interval := uint32(seconds)
+		p.sessionExpiry = &interval
+		p.cleanStart = &cleanStart
+		return nil
+	}
+}
+
+// WithLastWill sets the Last Will and Testament of the connection to the event e,
+// encoded in the structured format f (format.JSON if nil), so that the broker publishes
+// it when the client disconnects ungracefully, e.g. to notify that a device went offline.
+// The topic, QoS and retain flag are taken from will, its payload is ignored.
+// The content type of the will properties is set to the media type of f.
+func WithLastWill(will *paho.WillMessage, e event.Event, f format.Format) Option {
+	return func(p *Protocol) error {
+		if will == nil {
+			return fmt.Errorf("the paho.WillMessage option must not be nil")
+		}
+		if err := e.Validate(); err != nil {
+			return fmt.Errorf("invalid last will event: %w", err)
+		}
+		if f == nil {
+			f = format.JSON
+		}
+		payload, err := f.Marshal(&e)
+		if err != nil {
+			return fmt.Errorf("failed to encode the last will event: %w", err)
+		}
+		p.lastWill = &paho.WillMessage{
+			Retain:  will.Retain,
+			QoS:     will.QoS,
+			Topic:   will.Topic,
+			Payload: payload,
+		}
+		p.lastWillContentType = f.MediaType()
+		return nil
+	}
+}
+
 // WithDebugLogger enable the debug logger for the paho mqtt client.
 // This option is optional and can be used to enable detailed logging of paho the mqtt client.
 func WithDebugLogger(logger log.Logger) Option {
 	return func(p *Protocol) error {
+		p.debugLogger = logger
 		p.client.SetDebugLogger(logger)
 		return nil
 	}
@@ -61,7 +146,40 @@ func WithDebugLogger(logger log.Logger) Option {
 // This option is optional and can be used to enable error logging of paho the mqtt client.
 func WithErrorLogger(logger log.Logger) Option {
 	return func(p *Protocol) error {
+		p.errorLogger = logger
 		p.client.SetErrorLogger(logger)
 		return nil
 	}
 }
+
+// WithReconnect makes the protocol reconnect to the broker when the connection
+// is lost while receiving: dial opens a new network connection, used by a new
+// paho client connecting with the paho.Connect configuration, then the topics
+// are subscribed again. The attempts follow policy; once it gives up,
+// OpenInbound returns an error. Without this option, the protocol stops
+// receiving messages when the connection is lost.
+func WithReconnect(policy protocol.ReconnectPolicy, dial func(ctx context.Context) (net.Conn, error)) Option {
+	return func(p *Protocol) error {
+		if dial == nil {
+			return fmt.Errorf("the dial function must not be nil")
+		}
+		p.reconnect = &policy
+		p.dial = dial
+		return nil
+	}
+}
+
+// TLSDialer returns a function dialing a TLS connection to the broker at
+// address, e.g. "broker.example.com:8883", secured with the shared
+// protocol.TLSOption set. The connection it opens is given to New in the
+// paho.ClientConfig, and the function itself to WithReconnect.
+func TLSDialer(address string, opts ...protocol.TLSOption) (func(ctx context.Context) (net.Conn, error), error) {
+	config, err := protocol.NewTLSConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &tls.Dialer{Config: config}
+	return func(ctx context.Context) (net.Conn, error) {
+		return dialer.DialContext(ctx, "tcp", address)
+	}, nil
+}
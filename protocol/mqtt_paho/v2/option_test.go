@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The CloudEvents Authors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mqtt_paho
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestWithLastWill(t *testing.T) {
+	e := test.FullEvent()
+	delay := uint32(5)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name: "last will before connect",
+			opts: []Option{
+				WithLastWill(&paho.WillMessage{Topic: "devices/offline", QoS: 1, Retain: true, Payload: []byte("ignored")}, e, nil),
+				WithConnect(&paho.Connect{ClientID: "device", WillProperties: &paho.WillProperties{WillDelayInterval: &delay}}),
+			},
+		},
+		{
+			name: "last will after connect",
+			opts: []Option{
+				WithConnect(&paho.Connect{ClientID: "device", WillProperties: &paho.WillProperties{WillDelayInterval: &delay}}),
+				WithLastWill(&paho.WillMessage{Topic: "devices/offline", QoS: 1, Retain: true}, e, format.JSON),
+			},
+		},
+		{
+			name:    "nil will",
+			opts:    []Option{WithLastWill(nil, e, nil)},
+			wantErr: "the paho.WillMessage option must not be nil",
+		},
+		{
+			name:    "invalid event",
+			opts:    []Option{WithLastWill(&paho.WillMessage{Topic: "devices/offline"}, event.New(), nil)},
+			wantErr: "invalid last will event",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &Protocol{connOption: &paho.Connect{}}
+			err := p.applyOptions(tc.opts...)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			conn := p.connectOption()
+			require.Equal(t, "device", conn.ClientID)
+			require.Nil(t, p.connOption.WillMessage, "the configured paho.Connect is not modified")
+			require.Equal(t, "devices/offline", conn.WillMessage.Topic)
+			require.Equal(t, byte(1), conn.WillMessage.QoS)
+			require.True(t, conn.WillMessage.Retain)
+			require.Equal(t, event.ApplicationCloudEventsJSON, conn.WillProperties.ContentType)
+			require.Equal(t, &delay, conn.WillProperties.WillDelayInterval)
+
+			got := event.New()
+			require.NoError(t, format.JSON.Unmarshal(conn.WillMessage.Payload, &got))
+			require.Equal(t, e.ID(), got.ID())
+			require.Equal(t, e.Type(), got.Type())
+		})
+	}
+}
+
+func TestWithSharedSubscription(t *testing.T) {
+	p := &Protocol{}
+	require.NoError(t, p.applyOptions(
+		WithSharedSubscription("workers"),
+		WithSubscribe(&paho.Subscribe{Subscriptions: []paho.SubscribeOptions{
+			{Topic: "events/#", QoS: 1},
+			{Topic: "$share/other/alerts"},
+		}}),
+	))
+	sub := p.subscription()
+	require.Equal(t, []paho.SubscribeOptions{
+		{Topic: "$share/workers/events/#", QoS: 1},
+		{Topic: "$share/other/alerts"},
+	}, sub.Subscriptions)
+	require.Equal(t, "events/#", p.subscribeOption.Subscriptions[0].Topic, "the configured paho.Subscribe is not modified")
+
+	for _, group := range []string{"", "a/b", "a+", "#"} {
+		require.Error(t, (&Protocol{}).applyOptions(WithSharedSubscription(group)), group)
+	}
+}
+
+func TestWithSession(t *testing.T) {
+	maxPacket := uint32(1024)
+	p := &Protocol{connOption: &paho.Connect{CleanStart: true}}
+	require.NoError(t, p.applyOptions(
+		WithSession(time.Hour+time.Millisecond, false),
+		WithConnect(&paho.Connect{ClientID: "consumer-1", CleanStart: true, Properties: &paho.ConnectProperties{MaximumPacketSize: &maxPacket}}),
+		WithLastWill(&paho.WillMessage{Topic: "devices/offline"}, test.FullEvent(), nil),
+	))
+	conn := p.connectOption()
+	require.False(t, conn.CleanStart)
+	require.Equal(t, uint32(3600), *conn.Properties.SessionExpiryInterval)
+	require.Equal(t, &maxPacket, conn.Properties.MaximumPacketSize)
+	require.Equal(t, "devices/offline", conn.WillMessage.Topic)
+	require.True(t, p.connOption.CleanStart, "the configured paho.Connect is not modified")
+	require.Nil(t, p.connOption.Properties.SessionExpiryInterval)
+
+	p = &Protocol{connOption: &paho.Connect{}}
+	require.NoError(t, p.applyOptions(WithSession(math.MaxInt64, false)))
+	require.Equal(t, uint32(math.MaxUint32), *p.connectOption().Properties.SessionExpiryInterval)
+
+	require.Error(t, (&Protocol{}).applyOptions(WithSession(-time.Second, true)))
+}
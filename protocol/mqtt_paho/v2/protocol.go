@@ -7,29 +7,48 @@ package mqtt_paho
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"strings"
 	"sync"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/eclipse/paho.golang/paho"
+	"github.com/eclipse/paho.golang/paho/log"
 
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 )
 
 type Protocol struct {
-	client          *paho.Client
+	client *paho.Client
+	// clientMutex guards client, replaced when reconnecting
+	clientMutex     sync.RWMutex
+	config          paho.ClientConfig
 	connOption      *paho.Connect
 	publishOption   *paho.Publish
 	subscribeOption *paho.Subscribe
 
+	lastWill            *paho.WillMessage
+	lastWillContentType string
+
+	shareGroup    string
+	sessionExpiry *uint32
+	cleanStart    *bool
+
 	// receiver
 	incoming chan *paho.Publish
 	// inOpen
 	openerMutex sync.Mutex
 
 	closeChan chan struct{}
+
+	reconnect   *protocol.ReconnectPolicy
+	dial        func(ctx context.Context) (net.Conn, error)
+	debugLogger log.Logger
+	errorLogger log.Logger
 }
 
 var (
@@ -37,6 +56,7 @@ var (
 	_ protocol.Opener   = (*Protocol)(nil)
 	_ protocol.Receiver = (*Protocol)(nil)
 	_ protocol.Closer   = (*Protocol)(nil)
+	_ protocol.Pinger   = (*Protocol)(nil)
 )
 
 func New(ctx context.Context, config *paho.ClientConfig, opts ...Option) (*Protocol, error) {
@@ -46,6 +66,7 @@ func New(ctx context.Context, config *paho.ClientConfig, opts ...Option) (*Proto
 
 	p := &Protocol{
 		client: paho.NewClient(*config),
+		config: *config,
 		// default connect option
 		connOption: &paho.Connect{
 			KeepAlive:  30,
@@ -59,7 +80,7 @@ func New(ctx context.Context, config *paho.ClientConfig, opts ...Option) (*Proto
 	}
 
 	// Connect to the MQTT broker
-	connAck, err := p.client.Connect(ctx, p.connOption)
+	connAck, err := p.client.Connect(ctx, p.connectOption())
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +91,35 @@ func New(ctx context.Context, config *paho.ClientConfig, opts ...Option) (*Proto
 	return p, nil
 }
 
+// connectOption returns the paho.Connect configuration, including the session
+// state and the last will if set.
+func (p *Protocol) connectOption() *paho.Connect {
+	if p.lastWill == nil && p.cleanStart == nil {
+		return p.connOption
+	}
+	conn := *p.connOption
+	if p.cleanStart != nil {
+		conn.CleanStart = *p.cleanStart
+		props := paho.ConnectProperties{}
+		if conn.Properties != nil {
+			props = *conn.Properties
+		}
+		props.SessionExpiryInterval = p.sessionExpiry
+		conn.Properties = &props
+	}
+	if p.lastWill == nil {
+		return &conn
+	}
+	conn.WillMessage = p.lastWill
+	props := paho.WillProperties{}
+	if conn.WillProperties != nil {
+		props = *conn.WillProperties
+	}
+	props.ContentType = p.lastWillContentType
+	conn.WillProperties = &props
+	return &conn
+}
+
 func (p *Protocol) applyOptions(opts ...Option) error {
 	for _, fn := range opts {
 		if err := fn(p); err != nil {
@@ -98,11 +148,8 @@ func (p *Protocol) Send(ctx context.Context, m binding.Message, transformers ...
 		return err
 	}
 
-	_, err = p.client.Publish(ctx, msg)
-	if err != nil {
-		return err
-	}
-	return err
+	resp, err := p.getClient().Publish(ctx, msg)
+	return publishResult(resp, err)
 }
 
 // publishMsg generate a new paho.Publish message from the p.publishOption
@@ -125,23 +172,160 @@ func (p *Protocol) OpenInbound(ctx context.Context) error {
 
 	logger := cecontext.LoggerFrom(ctx)
 
-	p.client.AddOnPublishReceived(func(m paho.PublishReceived) (bool, error) {
-		p.incoming <- m.Packet
-		return true, nil
-	})
+	client := p.getClient()
+	client.AddOnPublishReceived(p.onPublishReceived)
 
-	logger.Infof("subscribing to topics: %v", p.subscribeOption.Subscriptions)
-	_, err := p.client.Subscribe(ctx, p.subscribeOption)
-	if err != nil {
+	sub := p.subscription()
+	logger.Infof("subscribing to topics: %v", sub.Subscriptions)
+	err := subscribe(ctx, client, sub)
+	if err != nil && (p.reconnect == nil || !errors.Is(err, paho.ErrConnectionLost)) {
 		return err
 	}
+	// If the connection has been lost while subscribing, the loop below
+	// reconnects once the client is done.
 
 	// Wait until external or internal context done
+	for {
+		var lost <-chan struct{}
+		if p.reconnect != nil {
+			lost = client.Done()
+		}
+		select {
+		case <-ctx.Done():
+		case <-p.closeChan:
+		case <-lost:
+			logger.Warnf("connection to the broker lost, reconnecting")
+			if client, err = p.reconnectClient(ctx); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			continue
+		}
+		return client.Disconnect(&paho.Disconnect{ReasonCode: 0})
+	}
+}
+
+// subscribe subscribes client to the topics, returning paho.ErrConnectionLost
+// as soon as the connection is lost, as paho may otherwise wait for the SUBACK
+// until the packet timeout.
+func subscribe(ctx context.Context, client *paho.Client, s *paho.Subscribe) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-client.Done():
+			cancel()
+		}
+	}()
+	_, err := client.Subscribe(ctx, s)
+	if err != nil {
+		select {
+		case <-client.Done():
+			return paho.ErrConnectionLost
+		default:
+		}
+	}
+	return err
+}
+
+// subscription returns the paho.Subscribe configuration, subscribing to the
+// shared topics if a shared subscription group is set.
+func (p *Protocol) subscription() *paho.Subscribe {
+	if p.shareGroup == "" {
+		return p.subscribeOption
+	}
+	sub := *p.subscribeOption
+	sub.Subscriptions = make([]paho.SubscribeOptions, len(p.subscribeOption.Subscriptions))
+	for i, s := range p.subscribeOption.Subscriptions {
+		if !strings.HasPrefix(s.Topic, "$share/") {
+			s.Topic = SharedTopic(p.shareGroup, s.Topic)
+		}
+		sub.Subscriptions[i] = s
+	}
+	return &sub
+}
+
+func (p *Protocol) onPublishReceived(m paho.PublishReceived) (bool, error) {
+	p.incoming <- m.Packet
+	return true, nil
+}
+
+// reconnectClient connects a new paho client and subscribes to the topics
+// again, following the reconnect policy.
+func (p *Protocol) reconnectClient(ctx context.Context) (*paho.Client, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-p.closeChan:
+			cancel()
+		}
+	}()
+
+	var client *paho.Client
+	err := p.reconnect.Reconnect(ctx, func(ctx context.Context) error {
+		conn, err := p.dial(ctx)
+		if err != nil {
+			return err
+		}
+		config := p.config
+		config.Conn = conn
+		c := paho.NewClient(config)
+		if p.debugLogger != nil {
+			c.SetDebugLogger(p.debugLogger)
+		}
+		if p.errorLogger != nil {
+			c.SetErrorLogger(p.errorLogger)
+		}
+		c.AddOnPublishReceived(p.onPublishReceived)
+
+		connAck, err := c.Connect(ctx, p.connectOption())
+		if err != nil {
+			_ = conn.Close()
+			return err
+		}
+		if connAck.ReasonCode != 0 {
+			_ = conn.Close()
+			return fmt.Errorf("failed to establish the connection: %s", connAck.String())
+		}
+		if err := subscribe(ctx, c, p.subscription()); err != nil {
+			_ = c.Disconnect(&paho.Disconnect{ReasonCode: 0})
+			return err
+		}
+		client = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.clientMutex.Lock()
+	p.client = client
+	p.clientMutex.Unlock()
+	return client, nil
+}
+
+// Ping implements protocol.Pinger. The paho client does not expose the MQTT
+// PINGREQ, it sends them itself to keep the connection alive: Ping reports
+// paho.ErrConnectionLost if the connection established by New, or by the last
+// reconnection, is lost.
+func (p *Protocol) Ping(ctx context.Context) error {
 	select {
-	case <-ctx.Done():
-	case <-p.closeChan:
+	case <-p.getClient().Done():
+		return paho.ErrConnectionLost
+	default:
+		return nil
 	}
-	return p.client.Disconnect(&paho.Disconnect{ReasonCode: 0})
+}
+
+func (p *Protocol) getClient() *paho.Client {
+	p.clientMutex.RLock()
+	defer p.clientMutex.RUnlock()
+	return p.client
 }
 
 // Receive implements Receiver.Receive
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The CloudEvents Authors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mqtt_paho
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.golang/packets"
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// serveBroker acknowledges the connection and the subscription received on
+// conn, then publishes payload, or closes the connection if payload is nil.
+func serveBroker(t *testing.T, conn net.Conn, payload []byte) {
+	defer conn.Close()
+	for {
+		cp, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		switch p := cp.Content.(type) {
+		case *packets.Connect:
+			_, err = packets.NewControlPacket(packets.CONNACK).WriteTo(conn)
+		case *packets.Subscribe:
+			ack := packets.NewControlPacket(packets.SUBACK)
+			ack.Content.(*packets.Suback).PacketID = p.PacketID
+			ack.Content.(*packets.Suback).Reasons = []byte{0}
+			if _, err = ack.WriteTo(conn); err != nil || payload == nil {
+				return
+			}
+			pub := packets.NewControlPacket(packets.PUBLISH)
+			pub.Content.(*packets.Publish).Topic = "events"
+			pub.Content.(*packets.Publish).Payload = payload
+			pub.Content.(*packets.Publish).Properties.ContentType = event.ApplicationCloudEventsJSON
+			_, err = pub.WriteTo(conn)
+		}
+		if err != nil {
+			t.Log(err)
+			return
+		}
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	e := test.FullEvent()
+	payload, err := format.JSON.Marshal(&e)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The first connection is closed by the broker once subscribed.
+	client, server := net.Pipe()
+	go serveBroker(t, server, nil)
+
+	var dials, reconnects atomic.Int32
+	dial := func(context.Context) (net.Conn, error) {
+		dials.Add(1)
+		client, server := net.Pipe()
+		go serveBroker(t, server, payload)
+		return client, nil
+	}
+	policy := protocol.ReconnectPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxRetries:     3,
+		OnReconnect:    []func(context.Context){func(context.Context) { reconnects.Add(1) }},
+	}
+	p, err := New(ctx, &paho.ClientConfig{Conn: client},
+		WithSubscribe(&paho.Subscribe{Subscriptions: []paho.SubscribeOptions{{Topic: "events"}}}),
+		WithReconnect(policy, dial),
+	)
+	require.NoError(t, err)
+
+	opened := make(chan error, 1)
+	go func() {
+		opened <- p.OpenInbound(ctx)
+	}()
+
+	m, err := p.Receive(ctx)
+	require.NoError(t, err)
+	got, err := binding.ToEvent(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, e.ID(), got.ID())
+	require.Equal(t, int32(1), dials.Load())
+	require.Equal(t, int32(1), reconnects.Load())
+
+	require.NoError(t, p.Close(ctx))
+	require.NoError(t, <-opened)
+}
+
+func TestReconnectGivesUp(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, server := net.Pipe()
+	go serveBroker(t, server, nil)
+
+	dial := func(context.Context) (net.Conn, error) {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: net.UnknownNetworkError("unreachable")}
+	}
+	p, err := New(ctx, &paho.ClientConfig{Conn: client},
+		WithSubscribe(&paho.Subscribe{Subscriptions: []paho.SubscribeOptions{{Topic: "events"}}}),
+		WithReconnect(protocol.ReconnectPolicy{InitialBackoff: time.Millisecond, MaxRetries: 2}, dial),
+	)
+	require.NoError(t, err)
+
+	err = p.OpenInbound(ctx)
+	require.ErrorContains(t, err, "failed to reconnect after 2 attempts")
+}
+
+func TestTLSDialer(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	dial, err := TLSDialer(srv.Listener.Addr().String(), protocol.WithTLSRootCAs(pool), protocol.WithTLSServerName("example.com"))
+	require.NoError(t, err)
+	conn, err := dial(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	_, err = TLSDialer(srv.Listener.Addr().String(), protocol.WithTLSRootCAs(nil))
+	require.Error(t, err)
+}
+
+func TestPing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, server := net.Pipe()
+	go serveBroker(t, server, nil)
+	p, err := New(ctx, &paho.ClientConfig{Conn: client})
+	require.NoError(t, err)
+	require.NoError(t, p.Ping(ctx))
+
+	// The broker closes the connection.
+	require.NoError(t, server.Close())
+	select {
+	case <-p.getClient().Done():
+	case <-ctx.Done():
+		t.Fatal("the connection was not lost")
+	}
+	require.ErrorIs(t, p.Ping(ctx), paho.ErrConnectionLost)
+}
@@ -0,0 +1,41 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package mqtt_paho
+
+import (
+	"errors"
+	"io"
+	"net"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/eclipse/paho.golang/paho"
+)
+
+// Reason codes of the PUBACKs which are transient.
+const (
+	reasonPacketIdentifierInUse = 0x91
+	reasonQuotaExceeded         = 0x97
+)
+
+// publishResult returns the protocol.BrokerResult of the error of a publish,
+// with the reason code of the PUBACK as status code, if any.
+func publishResult(resp *paho.PublishResponse, err error) error {
+	if err == nil {
+		return nil
+	}
+	if resp != nil && resp.ReasonCode >= 0x80 {
+		transient := resp.ReasonCode == reasonPacketIdentifierInUse || resp.ReasonCode == reasonQuotaExceeded
+		return protocol.NewBrokerResult(err, transient, int(resp.ReasonCode))
+	}
+	if errors.Is(err, paho.ErrInvalidArguments) {
+		return protocol.NewBrokerResult(err, false, 0)
+	}
+	// The messages of paho.ErrNetworkErrorAfterStored are sent again by the
+	// session, so they are not retriable.
+	var netErr net.Error
+	transient := errors.Is(err, paho.ErrConnectionLost) || errors.Is(err, io.EOF) || errors.As(err, &netErr)
+	return protocol.NewBrokerResult(err, transient, 0)
+}
@@ -0,0 +1,34 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package mqtt_paho
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestPublishResult(t *testing.T) {
+	require.NoError(t, publishResult(&paho.PublishResponse{}, nil))
+
+	result := publishResult(&paho.PublishResponse{ReasonCode: reasonQuotaExceeded}, errors.New("error publishing: quota exceeded"))
+	require.True(t, protocol.IsRetriable(result))
+	code, ok := protocol.StatusCode(result)
+	require.True(t, ok)
+	require.Equal(t, reasonQuotaExceeded, code)
+
+	result = publishResult(&paho.PublishResponse{ReasonCode: 0x87}, errors.New("error publishing: not authorized"))
+	require.False(t, protocol.IsRetriable(result))
+
+	require.True(t, protocol.IsRetriable(publishResult(nil, paho.ErrConnectionLost)))
+	require.False(t, protocol.IsRetriable(publishResult(nil, paho.ErrNetworkErrorAfterStored)))
+	require.False(t, protocol.IsRetriable(publishResult(nil, fmt.Errorf("%w: empty topic", paho.ErrInvalidArguments))))
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/nats-io/nats.go"
 )
 
@@ -28,6 +29,13 @@ func NewMessage(msg *nats.Msg) *Message {
 }
 
 var _ binding.Message = (*Message)(nil)
+var _ protocol.TransportMetadataReader = (*Message)(nil)
+
+// TransportMetadata implements protocol.TransportMetadataReader, it returns
+// the subject of the message as topic, and its headers.
+func (m *Message) TransportMetadata() protocol.TransportMetadata {
+	return protocol.TransportMetadata{Topic: m.Msg.Subject, Header: m.Msg.Header}
+}
 
 func (m *Message) ReadEncoding() binding.Encoding {
 	return m.encoding
@@ -9,6 +9,8 @@ import (
 	"errors"
 
 	"github.com/nats-io/nats.go"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 var ErrInvalidQueueName = errors.New("invalid queue name for QueueSubscriber")
@@ -19,6 +21,21 @@ func NatsOptions(opts ...nats.Option) []nats.Option {
 	return opts
 }
 
+// WithTLS is a nats.Option securing the connection to the NATS server with
+// the shared protocol.TLSOption set. It is given to the constructors along
+// with the other nats.Option.
+func WithTLS(opts ...protocol.TLSOption) nats.Option {
+	return func(o *nats.Options) error {
+		config, err := protocol.NewTLSConfig(opts...)
+		if err != nil {
+			return err
+		}
+		o.Secure = true
+		o.TLSConfig = config
+		return nil
+	}
+}
+
 // ProtocolOption is the function signature required to be considered an nats.ProtocolOption.
 type ProtocolOption func(*Protocol) error
 
@@ -50,3 +67,17 @@ func WithQueueSubscriber(queue string) ConsumerOption {
 		return nil
 	}
 }
+
+// WithReconnect configures how the Consumer recovers when its connection is
+// lost. The NATS client reconnects and resubscribes by itself: the
+// OnReconnect hooks of policy are invoked once it did. If the NATS client
+// gives up and closes the connection, a Consumer created with NewConsumer or
+// NewProtocol dials a new connection following policy, while a Consumer
+// created from an existing connection fails: OpenInbound returns an error
+// instead of blocking until its context is done.
+func WithReconnect(policy protocol.ReconnectPolicy) ConsumerOption {
+	return func(c *Consumer) error {
+		c.reconnect = &policy
+		return nil
+	}
+}
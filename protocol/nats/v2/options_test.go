@@ -8,6 +8,11 @@ package nats
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 func TestWithQueueSubscriber(t *testing.T) {
@@ -62,3 +67,28 @@ func TestWithQueueSubscriber(t *testing.T) {
 		})
 	}
 }
+
+func TestWithReconnect(t *testing.T) {
+	consumer := &Consumer{}
+	policy := protocol.ReconnectPolicy{InitialBackoff: time.Second, MaxRetries: 3}
+	if err := consumer.applyOptions(WithReconnect(policy)); err != nil {
+		t.Fatalf("applyOptions(WithReconnect()) = %v", err)
+	}
+	if !reflect.DeepEqual(consumer.reconnect, &policy) {
+		t.Errorf("reconnect = %v, want %v", consumer.reconnect, policy)
+	}
+}
+
+func TestWithTLS(t *testing.T) {
+	opts := nats.GetDefaultOptions()
+	if err := WithTLS(protocol.WithTLSServerName("nats.example.com"))(&opts); err != nil {
+		t.Fatalf("WithTLS() = %v", err)
+	}
+	if !opts.Secure || opts.TLSConfig == nil || opts.TLSConfig.ServerName != "nats.example.com" {
+		t.Errorf("Secure = %v, TLSConfig = %v, want a secure connection to nats.example.com", opts.Secure, opts.TLSConfig)
+	}
+
+	if err := WithTLS(protocol.WithTLSRootCAs(nil))(&opts); err == nil {
+		t.Errorf("WithTLS(WithTLSRootCAs(nil)) = nil, want an error")
+	}
+}
@@ -7,6 +7,7 @@ package nats
 
 import (
 	"context"
+	"fmt"
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 
@@ -41,6 +42,7 @@ func NewProtocol(url, sendSubject, receiveSubject string, natsOpts []nats.Option
 	}
 
 	p.connOwned = true
+	p.Consumer.dial = func() (*nats.Conn, error) { return nats.Connect(url, natsOpts...) }
 
 	return p, nil
 }
@@ -80,6 +82,14 @@ func (p *Protocol) Receive(ctx context.Context) (binding.Message, error) {
 	return p.Consumer.Receive(ctx)
 }
 
+// Ping implements Pinger.Ping: it checks the round trip to the NATS server.
+func (p *Protocol) Ping(ctx context.Context) error {
+	if err := p.Conn.FlushWithContext(ctx); err != nil {
+		return publishResult(fmt.Errorf("nats ping failed: %w", err))
+	}
+	return nil
+}
+
 // Close implements Closer.Close
 func (p *Protocol) Close(ctx context.Context) error {
 	if p.connOwned {
@@ -110,3 +120,4 @@ var _ protocol.Receiver = (*Protocol)(nil)
 var _ protocol.Sender = (*Protocol)(nil)
 var _ protocol.Opener = (*Protocol)(nil)
 var _ protocol.Closer = (*Protocol)(nil)
+var _ protocol.Pinger = (*Protocol)(nil)
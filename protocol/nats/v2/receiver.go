@@ -7,6 +7,7 @@ package nats
 
 import (
 	"context"
+	"errors"
 	"io"
 	"sync"
 
@@ -58,6 +59,11 @@ type Consumer struct {
 	subMtx        sync.Mutex
 	internalClose chan struct{}
 	connOwned     bool
+
+	reconnect *protocol.ReconnectPolicy
+	// dial opens a new connection when the current one is closed, nil if
+	// the connection was given by the caller.
+	dial func() (*nats.Conn, error)
 }
 
 func NewConsumer(url, subject string, natsOpts []nats.Option, opts ...ConsumerOption) (*Consumer, error) {
@@ -73,6 +79,7 @@ func NewConsumer(url, subject string, natsOpts []nats.Option, opts ...ConsumerOp
 	}
 
 	c.connOwned = true
+	c.dial = func() (*nats.Conn, error) { return nats.Connect(url, natsOpts...) }
 
 	return c, err
 }
@@ -104,14 +111,66 @@ func (c *Consumer) OpenInbound(ctx context.Context) error {
 		return err
 	}
 
-	// Wait until external or internal context done
-	select {
-	case <-ctx.Done():
-	case <-c.internalClose:
+	if c.reconnect == nil {
+		// Wait until external or internal context done
+		select {
+		case <-ctx.Done():
+		case <-c.internalClose:
+		}
+
+		// Finish to consume messages in the queue and close the subscription
+		return sub.Drain()
 	}
 
-	// Finish to consume messages in the queue and close the subscription
-	return sub.Drain()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-c.internalClose:
+			cancel()
+		}
+	}()
+
+	status := c.Conn.StatusChanged(nats.CONNECTED, nats.CLOSED)
+	for {
+		select {
+		case <-ctx.Done():
+			// Finish to consume messages in the queue and close the subscription
+			return sub.Drain()
+		case s := <-status:
+			if s == nats.CONNECTED {
+				// The NATS client has reconnected and resubscribed.
+				c.reconnect.Reconnected(ctx)
+				continue
+			}
+			if c.dial == nil {
+				return errors.New("nats connection closed")
+			}
+			err := c.reconnect.Reconnect(ctx, func(context.Context) error {
+				conn, err := c.dial()
+				if err != nil {
+					return err
+				}
+				if sub, err = c.Subscriber.Subscribe(conn, c.Subject, c.MsgHandler); err != nil {
+					conn.Close()
+					return err
+				}
+				c.Conn = conn
+				// The consumer owns the connection it opened, even when the
+				// closed one was shared with a sender.
+				c.connOwned = true
+				return nil
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			status = c.Conn.StatusChanged(nats.CONNECTED, nats.CLOSED)
+		}
+	}
 }
 
 func (c *Consumer) Close(ctx context.Context) error {
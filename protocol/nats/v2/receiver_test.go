@@ -0,0 +1,184 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// runServer starts a NATS server listening on port, a random port if -1.
+func runServer(t *testing.T, port int) *server.Server {
+	t.Helper()
+	s, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: port, NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Start()
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("the NATS server is not ready")
+	}
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func serverPort(s *server.Server) int {
+	return s.Addr().(*net.TCPAddr).Port
+}
+
+// waitSubscribed waits until subject is subscribed on s.
+func waitSubscribed(t *testing.T, s *server.Server, subject string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !s.GlobalAccount().SubscriptionInterest(subject) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s is not subscribed", subject)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// publish publishes data on subject with a new connection to s.
+func publish(t *testing.T, s *server.Server, subject, data string) {
+	t.Helper()
+	conn, err := nats.Connect(s.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.Publish(subject, []byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func requireReceived(t *testing.T, c *Consumer, data string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m, err := c.Receive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(m.(*Message).Msg.Data); got != data {
+		t.Fatalf("received %q, want %q", got, data)
+	}
+	_ = m.Finish(nil)
+}
+
+func waitReconnected(t *testing.T, reconnected <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the OnReconnect hook was not invoked")
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	for name, natsOpts := range map[string][]nats.Option{
+		// The NATS client reconnects and resubscribes by itself.
+		"client reconnects": {nats.ReconnectWait(10 * time.Millisecond), nats.MaxReconnects(-1)},
+		// The NATS client closes the connection, the consumer dials a new one.
+		"consumer redials": {nats.NoReconnect()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := runServer(t, -1)
+			reconnected := make(chan struct{}, 1)
+			c, err := NewConsumer(s.ClientURL(), "events", natsOpts, WithReconnect(protocol.ReconnectPolicy{
+				InitialBackoff: 10 * time.Millisecond,
+				OnReconnect:    []func(context.Context){func(context.Context) { reconnected <- struct{}{} }},
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			inbound := make(chan error, 1)
+			go func() { inbound <- c.OpenInbound(context.Background()) }()
+
+			waitSubscribed(t, s, "events")
+			publish(t, s, "events", "before")
+			requireReceived(t, c, "before")
+
+			port := serverPort(s)
+			s.Shutdown()
+			s = runServer(t, port)
+			waitReconnected(t, reconnected)
+			waitSubscribed(t, s, "events")
+			publish(t, s, "events", "after")
+			requireReceived(t, c, "after")
+
+			if err := c.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if err := <-inbound; err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestReconnectGivesUp(t *testing.T) {
+	s := runServer(t, -1)
+	c, err := NewConsumer(s.ClientURL(), "events", []nats.Option{nats.NoReconnect()}, WithReconnect(protocol.ReconnectPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxRetries:     2,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbound := make(chan error, 1)
+	go func() { inbound <- c.OpenInbound(context.Background()) }()
+	waitSubscribed(t, s, "events")
+
+	// The server is gone for good: OpenInbound fails with the error of the
+	// policy once it gave up.
+	s.Shutdown()
+	select {
+	case err = <-inbound:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OpenInbound did not fail")
+	}
+	if err == nil || !strings.Contains(err.Error(), "failed to reconnect after 2 attempts") {
+		t.Fatalf("OpenInbound returned %v", err)
+	}
+}
+
+func TestReconnectFromConn(t *testing.T) {
+	s := runServer(t, -1)
+	conn, err := nats.Connect(s.ClientURL(), nats.NoReconnect())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c, err := NewConsumerFromConn(conn, "events", WithReconnect(protocol.ReconnectPolicy{InitialBackoff: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbound := make(chan error, 1)
+	go func() { inbound <- c.OpenInbound(context.Background()) }()
+	waitSubscribed(t, s, "events")
+
+	// The consumer can not reopen a connection it was given.
+	s.Shutdown()
+	select {
+	case err = <-inbound:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OpenInbound did not fail")
+	}
+	if err == nil || err.Error() != "nats connection closed" {
+		t.Fatalf("OpenInbound returned %v", err)
+	}
+}
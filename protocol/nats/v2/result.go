@@ -0,0 +1,38 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats
+
+import (
+	"errors"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// retriableErrors are the errors of a publish which may not happen if retried,
+// e.g. once the connection is reestablished.
+var retriableErrors = []error{
+	nats.ErrReconnectBufExceeded,
+	nats.ErrConnectionReconnecting,
+	nats.ErrDisconnected,
+	nats.ErrStaleConnection,
+	nats.ErrTimeout,
+	nats.ErrNoResponders,
+}
+
+// publishResult returns the protocol.BrokerResult of the error of a publish.
+func publishResult(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, retriable := range retriableErrors {
+		if errors.Is(err, retriable) {
+			return protocol.NewBrokerResult(err, true, 0)
+		}
+	}
+	return protocol.NewBrokerResult(err, false, 0)
+}
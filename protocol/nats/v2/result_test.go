@@ -0,0 +1,36 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestPublishResult(t *testing.T) {
+	if err := publishResult(nil); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	tests := map[error]bool{
+		nats.ErrReconnectBufExceeded:           true,
+		fmt.Errorf("%w", nats.ErrNoResponders): true,
+		nats.ErrMaxPayload:                     false,
+		nats.ErrConnectionClosed:               false,
+	}
+	for err, want := range tests {
+		result := publishResult(err)
+		if got := protocol.IsRetriable(result); got != want {
+			t.Errorf("IsRetriable(%v) = %v, want %v", err, got, want)
+		}
+		if brokerErr, ok := protocol.BrokerError(result); !ok || brokerErr != err {
+			t.Errorf("BrokerError(%v) = %v, %v", err, brokerErr, ok)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats_jetstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+// ClaimCheckScheme is the scheme of the datarefs of a ClaimCheckStore:
+// nats-object://<bucket>/<object>.
+const ClaimCheckScheme = "nats-object"
+
+// ClaimCheckStore is a middleware.ClaimCheckStore backed by a JetStream
+// object store, so that large event data can be offloaded without an external
+// blob store. The object store chunks the data, so it is not limited by the
+// maximum payload of the server.
+type ClaimCheckStore struct {
+	os     jetstream.ObjectStore
+	bucket string
+}
+
+// NewClaimCheckStore returns a ClaimCheckStore storing the data in os.
+func NewClaimCheckStore(ctx context.Context, os jetstream.ObjectStore) (*ClaimCheckStore, error) {
+	if os == nil {
+		return nil, errors.New("nats_jetstream claim-check store requires an object store")
+	}
+	status, err := os.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object store status: %w", err)
+	}
+	return &ClaimCheckStore{os: os, bucket: status.Bucket()}, nil
+}
+
+// Put implements middleware.ClaimCheckStore.Put.
+func (s *ClaimCheckStore) Put(ctx context.Context, key string, data []byte) (string, error) {
+	if _, err := s.os.PutBytes(ctx, key, data); err != nil {
+		return "", err
+	}
+	u := url.URL{Scheme: ClaimCheckScheme, Host: s.bucket, Path: "/" + key}
+	return u.String(), nil
+}
+
+// Get implements middleware.ClaimCheckStore.Get.
+func (s *ClaimCheckStore) Get(ctx context.Context, dataref string) ([]byte, error) {
+	name, err := s.object(dataref)
+	if err != nil {
+		return nil, err
+	}
+	return s.os.GetBytes(ctx, name)
+}
+
+// Delete removes the data referenced by dataref, e.g. once the event has been
+// processed. Deleting missing data is not an error.
+func (s *ClaimCheckStore) Delete(ctx context.Context, dataref string) error {
+	name, err := s.object(dataref)
+	if err != nil {
+		return err
+	}
+	err = s.os.Delete(ctx, name)
+	if errors.Is(err, jetstream.ErrObjectNotFound) {
+		return nil
+	}
+	return err
+}
+
+// object returns the name of the object referenced by dataref.
+func (s *ClaimCheckStore) object(dataref string) (string, error) {
+	u, err := url.Parse(dataref)
+	if err != nil || u.Scheme != ClaimCheckScheme || u.Host != s.bucket {
+		return "", middleware.ErrForeignDataRef
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	if name == "" {
+		return "", fmt.Errorf("dataref %q has no object name", dataref)
+	}
+	return name, nil
+}
+
+var _ middleware.ClaimCheckStore = (*ClaimCheckStore)(nil)
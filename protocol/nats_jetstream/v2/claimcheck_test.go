@@ -0,0 +1,93 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats_jetstream
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+// objectStoreMock implements the subset of jetstream.ObjectStore used by
+// ClaimCheckStore.
+type objectStoreMock struct {
+	jetstream.ObjectStore
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+type objectStoreStatusMock struct {
+	jetstream.ObjectStoreStatus
+}
+
+func (objectStoreStatusMock) Bucket() string { return "payloads" }
+
+func (os *objectStoreMock) Status(ctx context.Context) (jetstream.ObjectStoreStatus, error) {
+	return objectStoreStatusMock{}, nil
+}
+
+func (os *objectStoreMock) PutBytes(ctx context.Context, name string, data []byte) (*jetstream.ObjectInfo, error) {
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	os.objects[name] = data
+	return &jetstream.ObjectInfo{ObjectMeta: jetstream.ObjectMeta{Name: name}, Bucket: "payloads"}, nil
+}
+
+func (os *objectStoreMock) GetBytes(ctx context.Context, name string, opts ...jetstream.GetObjectOpt) ([]byte, error) {
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	data, ok := os.objects[name]
+	if !ok {
+		return nil, jetstream.ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func (os *objectStoreMock) Delete(ctx context.Context, name string) error {
+	os.mu.Lock()
+	defer os.mu.Unlock()
+	if _, ok := os.objects[name]; !ok {
+		return jetstream.ErrObjectNotFound
+	}
+	delete(os.objects, name)
+	return nil
+}
+
+func TestClaimCheckStore(t *testing.T) {
+	os := &objectStoreMock{objects: map[string][]byte{}}
+	ctx := context.Background()
+	s, err := NewClaimCheckStore(ctx, os)
+	require.NoError(t, err)
+
+	dataref, err := s.Put(ctx, "abc", []byte("payload"))
+	require.NoError(t, err)
+	require.Equal(t, "nats-object://payloads/abc", dataref)
+
+	data, err := s.Get(ctx, dataref)
+	require.NoError(t, err)
+	require.Equal(t, "payload", string(data))
+
+	for _, foreign := range []string{"https://payloads/abc", "nats-object://other/abc", "://"} {
+		_, err = s.Get(ctx, foreign)
+		require.ErrorIs(t, err, middleware.ErrForeignDataRef, foreign)
+	}
+	_, err = s.Get(ctx, "nats-object://payloads/")
+	require.Error(t, err)
+
+	require.NoError(t, s.Delete(ctx, dataref))
+	require.NoError(t, s.Delete(ctx, dataref))
+	_, err = s.Get(ctx, dataref)
+	require.ErrorIs(t, err, jetstream.ErrObjectNotFound)
+
+	_, err = NewClaimCheckStore(ctx, nil)
+	require.Error(t, err)
+}
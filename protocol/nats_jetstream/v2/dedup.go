@@ -0,0 +1,73 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats_jetstream
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+// DedupStore is a middleware.DedupStore backed by a JetStream key-value
+// bucket. Keys are hashed, since event sources and ids may contain
+// characters not allowed in bucket keys.
+//
+// Per-entry TTLs require a bucket created with a LimitMarkerTTL (NATS
+// server 2.11 or later). Otherwise, pass a zero ttl to Add and rely on the
+// TTL of the bucket.
+//
+// DedupStore is not a middleware.DedupCommitStore, since updating an entry
+// would drop its TTL: the duplicates of an event being processed by another
+// instance are acknowledged.
+type DedupStore struct {
+	kv jetstream.KeyValue
+}
+
+// NewDedupStore returns a DedupStore storing the keys in kv.
+func NewDedupStore(kv jetstream.KeyValue) (*DedupStore, error) {
+	if kv == nil {
+		return nil, errors.New("nats_jetstream dedup store requires a key-value bucket")
+	}
+	return &DedupStore{kv: kv}, nil
+}
+
+// Add implements middleware.DedupStore.Add.
+func (s *DedupStore) Add(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	var opts []jetstream.KVCreateOpt
+	if ttl > 0 {
+		opts = append(opts, jetstream.KeyTTL(ttl))
+	}
+	_, err := s.kv.Create(ctx, dedupKey(key), nil, opts...)
+	if errors.Is(err, jetstream.ErrKeyExists) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Remove implements middleware.DedupStore.Remove.
+func (s *DedupStore) Remove(ctx context.Context, key string) error {
+	err := s.kv.Purge(ctx, dedupKey(key))
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil
+	}
+	return err
+}
+
+func dedupKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ middleware.DedupStore = (*DedupStore)(nil)
@@ -0,0 +1,80 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats_jetstream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/require"
+)
+
+// kvMock implements the subset of jetstream.KeyValue used by DedupStore.
+type kvMock struct {
+	jetstream.KeyValue
+
+	mu   sync.Mutex
+	keys map[string]time.Duration
+}
+
+
+func (kv *kvMock) Create(ctx context.Context, key string, value []byte, opts ...jetstream.KVCreateOpt) (uint64, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if _, ok := kv.keys[key]; ok {
+		return 0, fmt.Errorf("%w: wrong last sequence", jetstream.ErrKeyExists)
+	}
+	// Record the number of options, i.e. whether a TTL was given.
+	kv.keys[key] = time.Duration(len(opts))
+	return uint64(len(kv.keys)), nil
+}
+
+func (kv *kvMock) Purge(ctx context.Context, key string, opts ...jetstream.KVDeleteOpt) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if _, ok := kv.keys[key]; !ok {
+		return jetstream.ErrKeyNotFound
+	}
+	delete(kv.keys, key)
+	return nil
+}
+
+func TestDedupStore(t *testing.T) {
+	kv := &kvMock{keys: map[string]time.Duration{}}
+	s, err := NewDedupStore(kv)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	added, err := s.Add(ctx, "https://example.com/source 1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	added, err = s.Add(ctx, "https://example.com/source 1", time.Minute)
+	require.NoError(t, err)
+	require.False(t, added)
+
+	added, err = s.Add(ctx, "https://example.com/source 2", 0)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// Keys are valid bucket keys, with a TTL option only when a ttl is given.
+	require.Len(t, kv.keys, 2)
+	require.Equal(t, time.Duration(1), kv.keys[dedupKey("https://example.com/source 1")])
+	require.Equal(t, time.Duration(0), kv.keys[dedupKey("https://example.com/source 2")])
+
+	require.NoError(t, s.Remove(ctx, "https://example.com/source 1"))
+	require.NoError(t, s.Remove(ctx, "https://example.com/source 1"))
+	added, err = s.Add(ctx, "https://example.com/source 1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	_, err = NewDedupStore(nil)
+	require.Error(t, err)
+}
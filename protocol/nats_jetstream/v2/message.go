@@ -16,6 +16,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/format"
 	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 const (
@@ -47,6 +48,20 @@ func NewMessage(msg *nats.Msg) *Message {
 }
 
 var _ binding.Message = (*Message)(nil)
+var _ protocol.TransportMetadataReader = (*Message)(nil)
+
+// TransportMetadata implements protocol.TransportMetadataReader, it returns
+// the subject of the message as topic, its headers, and for the messages
+// received from a consumer, the number of times it has been delivered and
+// the time it has been stored in the stream as the first delivery time.
+func (m *Message) TransportMetadata() protocol.TransportMetadata {
+	md := protocol.TransportMetadata{Topic: m.Msg.Subject, Header: m.Msg.Header}
+	if meta, err := m.Msg.Metadata(); err == nil {
+		md.DeliveryAttempt = int(meta.NumDelivered)
+		md.FirstDeliveryTime = meta.Timestamp
+	}
+	return md
+}
 
 // ReadEncoding return the type of the message Encoding.
 func (m *Message) ReadEncoding() binding.Encoding {
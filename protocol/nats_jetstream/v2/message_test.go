@@ -3,7 +3,9 @@ package nats_jetstream
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/cloudevents/sdk-go/v2/binding/spec"
 	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
@@ -96,3 +98,22 @@ func TestNewMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestTransportMetadata(t *testing.T) {
+	stored := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg := &nats.Msg{
+		Subject: "hello",
+		Reply:   fmt.Sprintf("$JS.ACK.stream.consumer.3.10.7.%d.0", stored.UnixNano()),
+		Sub:     &nats.Subscription{},
+	}
+	md := NewMessage(msg).TransportMetadata()
+	if md.Topic != "hello" || md.DeliveryAttempt != 3 || !md.FirstDeliveryTime.Equal(stored) {
+		t.Errorf("unexpected transport metadata: %+v", md)
+	}
+
+	// Core NATS messages have no delivery metadata.
+	md = NewMessage(&nats.Msg{Subject: "hello"}).TransportMetadata()
+	if md.DeliveryAttempt != 0 || !md.FirstDeliveryTime.IsZero() {
+		t.Errorf("unexpected transport metadata: %+v", md)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"errors"
 
 	"github.com/nats-io/nats.go"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 var ErrInvalidQueueName = errors.New("invalid queue name for QueueSubscriber")
@@ -19,6 +21,21 @@ func NatsOptions(opts ...nats.Option) []nats.Option {
 	return opts
 }
 
+// WithTLS is a nats.Option securing the connection to the NATS server with
+// the shared protocol.TLSOption set. It is given to the constructors along
+// with the other nats.Option.
+func WithTLS(opts ...protocol.TLSOption) nats.Option {
+	return func(o *nats.Options) error {
+		config, err := protocol.NewTLSConfig(opts...)
+		if err != nil {
+			return err
+		}
+		o.Secure = true
+		o.TLSConfig = config
+		return nil
+	}
+}
+
 // ProtocolOption is the function signature required to be considered an nats.ProtocolOption.
 type ProtocolOption func(*Protocol) error
 
@@ -7,6 +7,7 @@ package nats_jetstream
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/protocol"
@@ -81,6 +82,18 @@ func (p *Protocol) Receive(ctx context.Context) (binding.Message, error) {
 	return p.Consumer.Receive(ctx)
 }
 
+// Ping implements Pinger.Ping: it checks the round trip to the NATS server,
+// then that the stream of the Sender is reachable through the JetStream API.
+func (p *Protocol) Ping(ctx context.Context) error {
+	if err := p.Conn.FlushWithContext(ctx); err != nil {
+		return publishResult(fmt.Errorf("nats ping failed: %w", err))
+	}
+	if _, err := p.Sender.Jsm.StreamInfo(p.Sender.Stream, nats.Context(ctx)); err != nil {
+		return publishResult(fmt.Errorf("stream %q unavailable: %w", p.Sender.Stream, err))
+	}
+	return nil
+}
+
 // Close implements Closer.Close
 func (p *Protocol) Close(ctx context.Context) error {
 	if p.connOwned {
@@ -111,3 +124,4 @@ var _ protocol.Receiver = (*Protocol)(nil)
 var _ protocol.Sender = (*Protocol)(nil)
 var _ protocol.Opener = (*Protocol)(nil)
 var _ protocol.Closer = (*Protocol)(nil)
+var _ protocol.Pinger = (*Protocol)(nil)
@@ -0,0 +1,47 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats_jetstream
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// retriableErrors are the errors of a publish which may not happen if retried,
+// e.g. once the connection is reestablished or the stream has a leader.
+var retriableErrors = []error{
+	nats.ErrReconnectBufExceeded,
+	nats.ErrConnectionReconnecting,
+	nats.ErrDisconnected,
+	nats.ErrStaleConnection,
+	nats.ErrTimeout,
+	nats.ErrNoResponders,
+	nats.ErrNoStreamResponse,
+}
+
+// publishResult returns the protocol.BrokerResult of the error of a publish,
+// with the code of the JetStream API error as status code.
+func publishResult(err error) error {
+	if err == nil {
+		return nil
+	}
+	var jsErr nats.JetStreamError
+	if errors.As(err, &jsErr) && jsErr.APIError() != nil {
+		code := jsErr.APIError().Code
+		transient := code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+		return protocol.NewBrokerResult(err, transient, code)
+	}
+	for _, retriable := range retriableErrors {
+		if errors.Is(err, retriable) {
+			return protocol.NewBrokerResult(err, true, 0)
+		}
+	}
+	return protocol.NewBrokerResult(err, false, 0)
+}
@@ -0,0 +1,41 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats_jetstream
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestPublishResult(t *testing.T) {
+	if err := publishResult(nil); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	tests := []struct {
+		err       error
+		retriable bool
+		code      int
+	}{
+		{err: nats.ErrNoStreamResponse, retriable: true},
+		{err: fmt.Errorf("%w", nats.ErrTimeout), retriable: true},
+		{err: nats.ErrStreamNotFound, retriable: false, code: 404},
+		{err: &nats.APIError{Code: 503, Description: "JetStream system temporarily unavailable"}, retriable: true, code: 503},
+		{err: nats.ErrMaxPayload, retriable: false},
+	}
+	for _, tc := range tests {
+		result := publishResult(tc.err)
+		if got := protocol.IsRetriable(result); got != tc.retriable {
+			t.Errorf("IsRetriable(%v) = %v, want %v", tc.err, got, tc.retriable)
+		}
+		if code, _ := protocol.StatusCode(result); code != tc.code {
+			t.Errorf("StatusCode(%v) = %d, want %d", tc.err, code, tc.code)
+		}
+	}
+}
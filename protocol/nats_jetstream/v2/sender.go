@@ -104,7 +104,7 @@ func (s *Sender) Send(ctx context.Context, in binding.Message, transformers ...b
 
 	_, err = s.Jsm.PublishMsg(natsMsg)
 
-	return err
+	return publishResult(err)
 }
 
 // Close implements Closer.Close
@@ -0,0 +1,101 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package cdc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// Operations of the changes.
+const (
+	OpInsert   = "insert"
+	OpUpdate   = "update"
+	OpDelete   = "delete"
+	OpTruncate = "truncate"
+)
+
+// TypePrefix prefixes the operation of the changes in the type of their
+// events, e.g. "org.postgresql.cdc.insert".
+const TypePrefix = "org.postgresql.cdc."
+
+// Change is a change of a row, or the truncation of a table. It is the JSON
+// data of its event.
+type Change struct {
+	// Op is the operation, OpInsert, OpUpdate, OpDelete or OpTruncate.
+	Op string `json:"op"`
+	// Schema and Table are the schema and the name of the changed table.
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	// LSN is the log sequence number of the change, e.g. "0/16B3748".
+	LSN string `json:"lsn"`
+	// XID is the ID of the transaction of the change, if known.
+	XID uint32 `json:"xid,omitempty"`
+	// Before holds the columns of the row before an update or a delete: its
+	// replica identity, the primary key by default, or all its columns with
+	// REPLICA IDENTITY FULL. It is nil for an update not changing the
+	// identity.
+	Before map[string]interface{} `json:"before,omitempty"`
+	// After holds the columns of the row after an insert or an update. The
+	// unchanged TOASTed columns are missing.
+	After map[string]interface{} `json:"after,omitempty"`
+
+	// Time is the commit time of the transaction of the change.
+	Time time.Time `json:"-"`
+	// Key are the names of the columns of the replica identity of the table.
+	Key []string `json:"-"`
+}
+
+// Subject returns the values of the key of the changed row, "<value>" for a
+// single column or "<column>=<value>" joined by "," for several, or "" for a
+// truncation or a table without key.
+func (c *Change) Subject() string {
+	row := c.After
+	if row == nil {
+		row = c.Before
+	}
+	if row == nil || len(c.Key) == 0 {
+		return ""
+	}
+	if len(c.Key) == 1 {
+		return fmt.Sprint(row[c.Key[0]])
+	}
+	key := append([]string(nil), c.Key...)
+	sort.Strings(key)
+	parts := make([]string, len(key))
+	for i, name := range key {
+		parts[i] = fmt.Sprintf("%s=%v", name, row[name])
+	}
+	return strings.Join(parts, ",")
+}
+
+// Event returns the event of the change:
+//   - its id is the LSN of the change,
+//   - its source is "<source>/<schema>/<table>",
+//   - its type is TypePrefix followed by the operation,
+//   - its subject is the key of the row, see Subject,
+//   - its time is the commit time of the transaction,
+//   - its data is the JSON encoding of the change.
+func (c *Change) Event(source string) (*event.Event, error) {
+	e := event.New()
+	e.SetID(c.LSN)
+	e.SetSource(strings.TrimSuffix(source, "/") + "/" + c.Schema + "/" + c.Table)
+	e.SetType(TypePrefix + c.Op)
+	if s := c.Subject(); s != "" {
+		e.SetSubject(s)
+	}
+	if !c.Time.IsZero() {
+		e.SetTime(c.Time)
+	}
+	if err := e.SetData(event.ApplicationJSON, c); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
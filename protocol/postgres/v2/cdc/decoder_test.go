@@ -0,0 +1,154 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package cdc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+var commitTime = time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+
+func TestWal2JSONDecoder(t *testing.T) {
+	d := &wal2jsonDecoder{}
+	decode := func(lsn, data string) []Change {
+		changes, err := d.decode(lsn, []byte(data))
+		require.NoError(t, err)
+		return changes
+	}
+
+	require.Empty(t, decode("0/1", `{"action":"B","xid":42,"timestamp":"2024-01-02 03:04:05.123456+00"}`))
+	changes := decode("0/2", `{"action":"I","schema":"public","table":"orders",
+		"columns":[{"name":"id","type":"integer","value":1},{"name":"status","type":"text","value":"new"}],
+		"pk":[{"name":"id","type":"integer"}]}`)
+	require.Equal(t, []Change{{
+		Op: OpInsert, Schema: "public", Table: "orders", LSN: "0/2", XID: 42, Time: commitTime,
+		After: map[string]interface{}{"id": json.Number("1"), "status": "new"},
+		Key:   []string{"id"},
+	}}, changes)
+
+	changes = decode("0/3", `{"action":"U","schema":"public","table":"orders",
+		"columns":[{"name":"id","type":"integer","value":1},{"name":"status","type":"text","value":"paid"}],
+		"identity":[{"name":"id","type":"integer","value":1}],
+		"pk":[{"name":"id","type":"integer"}]}`)
+	require.Equal(t, OpUpdate, changes[0].Op)
+	require.Equal(t, map[string]interface{}{"id": json.Number("1")}, changes[0].Before)
+	require.Equal(t, "1", changes[0].Subject())
+
+	changes = decode("0/4", `{"action":"D","schema":"public","table":"lines",
+		"identity":[{"name":"order_id","type":"integer","value":1},{"name":"line","type":"integer","value":2}]}`)
+	require.Equal(t, OpDelete, changes[0].Op)
+	require.Nil(t, changes[0].After)
+	require.Equal(t, "line=2,order_id=1", changes[0].Subject())
+
+	changes = decode("0/5", `{"action":"T","schema":"public","table":"lines"}`)
+	require.Equal(t, []Change{{Op: OpTruncate, Schema: "public", Table: "lines", LSN: "0/5", XID: 42, Time: commitTime}}, changes)
+
+	require.Empty(t, decode("0/6", `{"action":"M","transactional":false,"prefix":"p","content":"c"}`))
+	require.Empty(t, decode("0/7", `{"action":"C","xid":42}`))
+	require.Zero(t, decode("0/9", `{"action":"T","schema":"public","table":"lines"}`)[0].XID)
+
+	_, err := d.decode("0/8", []byte(`{`))
+	require.Error(t, err)
+}
+
+// pgoutputMessage builds the messages of the logical replication protocol.
+type pgoutputMessage []byte
+
+func (m pgoutputMessage) byte(b byte) pgoutputMessage { return append(m, b) }
+func (m pgoutputMessage) int16(v int16) pgoutputMessage {
+	return binary.BigEndian.AppendUint16(m, uint16(v))
+}
+func (m pgoutputMessage) uint32(v uint32) pgoutputMessage { return binary.BigEndian.AppendUint32(m, v) }
+func (m pgoutputMessage) int64(v int64) pgoutputMessage {
+	return binary.BigEndian.AppendUint64(m, uint64(v))
+}
+func (m pgoutputMessage) string(s string) pgoutputMessage { return append(append(m, s...), 0) }
+func (m pgoutputMessage) text(s string) pgoutputMessage {
+	return append(m.byte('t').uint32(uint32(len(s))), s...)
+}
+
+func TestPgoutputDecoder(t *testing.T) {
+	d := &pgoutputDecoder{publications: "cdc"}
+	decode := func(lsn string, data pgoutputMessage) []Change {
+		changes, err := d.decode(lsn, data)
+		require.NoError(t, err)
+		return changes
+	}
+
+	begin := pgoutputMessage{'B'}.int64(100).int64(commitTime.Sub(postgresEpoch).Microseconds()).uint32(42)
+	require.Empty(t, decode("0/1", begin))
+	relation := pgoutputMessage{'R'}.uint32(7).string("public").string("orders").byte('d').int16(4).
+		byte(1).string("id").uint32(oidInt8).uint32(0xffffffff).
+		byte(0).string("paid").uint32(oidBool).uint32(0xffffffff).
+		byte(0).string("details").uint32(oidJSONB).uint32(0xffffffff).
+		byte(0).string("note").uint32(25).uint32(0xffffffff)
+	require.Empty(t, decode("0/2", relation))
+
+	insert := pgoutputMessage{'I'}.uint32(7).byte('N').int16(4).
+		text("1").text("f").text(`{"a": 1}`).byte('n')
+	require.Equal(t, []Change{{
+		Op: OpInsert, Schema: "public", Table: "orders", LSN: "0/3", XID: 42, Time: commitTime,
+		After: map[string]interface{}{"id": json.Number("1"), "paid": false, "details": json.RawMessage(`{"a": 1}`), "note": nil},
+		Key:   []string{"id"},
+	}}, decode("0/3", insert))
+
+	update := pgoutputMessage{'U'}.uint32(7).byte('K').int16(4).text("1").byte('n').byte('n').byte('n').
+		byte('N').int16(4).text("2").text("t").byte('u').text("x")
+	changes := decode("0/4", update)
+	require.Equal(t, OpUpdate, changes[0].Op)
+	require.Equal(t, map[string]interface{}{"id": json.Number("1"), "paid": nil, "details": nil, "note": nil}, changes[0].Before)
+	require.Equal(t, map[string]interface{}{"id": json.Number("2"), "paid": true, "note": "x"}, changes[0].After)
+	require.Equal(t, "2", changes[0].Subject())
+
+	del := pgoutputMessage{'D'}.uint32(7).byte('K').int16(4).text("2").byte('n').byte('n').byte('n')
+	changes = decode("0/5", del)
+	require.Equal(t, OpDelete, changes[0].Op)
+	require.Equal(t, "2", changes[0].Subject())
+
+	truncate := pgoutputMessage{'T'}.uint32(1).byte(0).uint32(7)
+	require.Equal(t, []Change{{Op: OpTruncate, Schema: "public", Table: "orders", LSN: "0/6", XID: 42, Time: commitTime}}, decode("0/6", truncate))
+
+	require.Empty(t, decode("0/7", pgoutputMessage{'C'}.byte(0).int64(100).int64(101).int64(0)))
+
+	_, err := d.decode("0/8", pgoutputMessage{'I'}.uint32(8).byte('N').int16(0))
+	require.ErrorContains(t, err, "unknown relation")
+	_, err = d.decode("0/9", insert[:len(insert)-3])
+	require.ErrorIs(t, err, errShortMessage)
+	_, err = d.decode("0/10", pgoutputMessage{'I'}.uint32(7).byte('N').int16(1).byte('t').uint32(1<<31))
+	require.ErrorIs(t, err, errShortMessage)
+}
+
+func TestChangeEvent(t *testing.T) {
+	c := Change{
+		Op: OpUpdate, Schema: "public", Table: "orders", LSN: "0/16B3748", XID: 42, Time: commitTime,
+		After: map[string]interface{}{"id": 1, "status": "paid"},
+		Key:   []string{"id"},
+	}
+	e, err := c.Event("/postgres/shop/")
+	require.NoError(t, err)
+	require.NoError(t, e.Validate())
+	require.Equal(t, "0/16B3748", e.ID())
+	require.Equal(t, "/postgres/shop/public/orders", e.Source())
+	require.Equal(t, "org.postgresql.cdc.update", e.Type())
+	require.Equal(t, "1", e.Subject())
+	require.Equal(t, commitTime, e.Time())
+	require.Equal(t, event.ApplicationJSON, e.DataContentType())
+	require.JSONEq(t, `{"op":"update","schema":"public","table":"orders","lsn":"0/16B3748","xid":42,
+		"after":{"id":1,"status":"paid"}}`, string(e.Data()))
+
+	truncate := Change{Op: OpTruncate, Schema: "public", Table: "orders", LSN: "0/1"}
+	e, err = truncate.Event("/postgres/shop")
+	require.NoError(t, err)
+	require.Empty(t, e.Subject())
+	require.True(t, e.Time().IsZero())
+}
@@ -0,0 +1,16 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package cdc implements a CloudEvents Receiver of the changes of PostgreSQL
+// tables, consumed from a logical replication slot, so that the database
+// changes enter the event pipeline without Debezium.
+//
+// The slot is read with the SQL interface of logical decoding, through
+// database/sql: the caller registers the driver of its choice and opens the
+// *sql.DB. The changes are decoded from the output of the wal2json plugin, in
+// its format version 2, or of the built-in pgoutput plugin, see WithPlugin.
+// Each inserted, updated or deleted row, and each truncated table, is received
+// as an event, see Change for the mapping of its attributes.
+package cdc
@@ -0,0 +1,88 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package cdc
+
+import (
+	"fmt"
+	"time"
+)
+
+// Logical decoding plugins, see WithPlugin.
+const (
+	// PluginPgoutput is the built-in plugin of the logical replication.
+	PluginPgoutput = "pgoutput"
+	// PluginWal2JSON is the wal2json plugin, in its format version 2.
+	PluginWal2JSON = "wal2json"
+)
+
+const (
+	// DefaultBatchSize is the number of changes read per query when
+	// WithBatchSize is not provided.
+	DefaultBatchSize = 1000
+	// DefaultPollInterval is the interval the slot is polled at when
+	// WithPollInterval is not provided.
+	DefaultPollInterval = time.Second
+)
+
+// Option is the function signature required to be considered an cdc.Option.
+type Option func(*Receiver) error
+
+// WithPlugin sets the logical decoding plugin of the slot: PluginWal2JSON, or
+// PluginPgoutput with the publications, separated by commas, whose tables are
+// replicated. Defaults to PluginWal2JSON.
+func WithPlugin(plugin string, publications string) Option {
+	return func(r *Receiver) error {
+		switch plugin {
+		case PluginWal2JSON:
+			r.decoder = &wal2jsonDecoder{}
+		case PluginPgoutput:
+			if publications == "" {
+				return fmt.Errorf("the pgoutput plugin needs publications")
+			}
+			r.decoder = &pgoutputDecoder{publications: publications}
+		default:
+			return fmt.Errorf("unsupported logical decoding plugin %q", plugin)
+		}
+		return nil
+	}
+}
+
+// WithSource sets the prefix of the source of the events, followed by the
+// schema and the name of the changed table. Defaults to "/postgres/<slot>".
+func WithSource(source string) Option {
+	return func(r *Receiver) error {
+		if source == "" {
+			return fmt.Errorf("cdc source can not be empty")
+		}
+		r.source = source
+		return nil
+	}
+}
+
+// WithBatchSize sets the number of changes read per query. The batches end
+// with a transaction, so they may hold more changes. Defaults to
+// DefaultBatchSize.
+func WithBatchSize(size int) Option {
+	return func(r *Receiver) error {
+		if size < 1 {
+			return fmt.Errorf("cdc batch size must be positive, got %d", size)
+		}
+		r.batchSize = size
+		return nil
+	}
+}
+
+// WithPollInterval sets the interval the slot is polled at once all its
+// changes are received. Defaults to DefaultPollInterval.
+func WithPollInterval(interval time.Duration) Option {
+	return func(r *Receiver) error {
+		if interval <= 0 {
+			return fmt.Errorf("cdc poll interval must be positive, got %s", interval)
+		}
+		r.pollInterval = interval
+		return nil
+	}
+}
@@ -0,0 +1,241 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// postgresEpoch is the origin of the timestamps of the replication protocol.
+var postgresEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Type OIDs of the columns whose text values are converted to JSON values
+// other than strings.
+const (
+	oidBool    = 16
+	oidInt8    = 20
+	oidInt2    = 21
+	oidInt4    = 23
+	oidJSON    = 114
+	oidFloat4  = 700
+	oidFloat8  = 701
+	oidNumeric = 1700
+	oidJSONB   = 3802
+)
+
+// pgoutputColumn is a column of a relation.
+type pgoutputColumn struct {
+	name string
+	key  bool
+	oid  uint32
+}
+
+// pgoutputRelation is the description of a table, sent before its first
+// change.
+type pgoutputRelation struct {
+	schema  string
+	table   string
+	columns []pgoutputColumn
+}
+
+// pgoutputDecoder decodes the version 1 of the logical replication protocol,
+// the output of the pgoutput plugin.
+type pgoutputDecoder struct {
+	publications string
+	relations    map[uint32]*pgoutputRelation
+	xid          uint32
+	time         time.Time
+}
+
+func (d *pgoutputDecoder) plugin() string {
+	return PluginPgoutput
+}
+
+func (d *pgoutputDecoder) peek(ctx context.Context, db *sql.DB, slot string, n int) (*sql.Rows, error) {
+	return db.QueryContext(ctx, `SELECT lsn::text, data FROM pg_logical_slot_peek_binary_changes($1, NULL, $2,
+		'proto_version', '1', 'publication_names', $3)`, slot, n, d.publications)
+}
+
+// errShortMessage is returned for the truncated messages.
+var errShortMessage = errors.New("message is too short")
+
+// pgoutputReader reads the fields of a message.
+type pgoutputReader struct {
+	b   []byte
+	err error
+}
+
+func (r *pgoutputReader) next(n int) []byte {
+	if r.err != nil || len(r.b) < n {
+		r.err = errShortMessage
+		return make([]byte, min(n, 8))
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *pgoutputReader) byte() byte     { return r.next(1)[0] }
+func (r *pgoutputReader) int16() int16   { return int16(binary.BigEndian.Uint16(r.next(2))) }
+func (r *pgoutputReader) uint32() uint32 { return binary.BigEndian.Uint32(r.next(4)) }
+func (r *pgoutputReader) int64() int64   { return int64(binary.BigEndian.Uint64(r.next(8))) }
+
+func (r *pgoutputReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.b, 0)
+	if i < 0 {
+		r.err = errShortMessage
+		return ""
+	}
+	s := string(r.b[:i])
+	r.b = r.b[i+1:]
+	return s
+}
+
+func (r *pgoutputReader) time() time.Time {
+	return postgresEpoch.Add(time.Duration(r.int64()) * time.Microsecond)
+}
+
+func (d *pgoutputDecoder) decode(lsn string, data []byte) ([]Change, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty pgoutput message at %s", lsn)
+	}
+	r := &pgoutputReader{b: data[1:]}
+	var changes []Change
+	switch data[0] {
+	case 'B':
+		r.int64() // final LSN
+		d.time = r.time()
+		d.xid = r.uint32()
+	case 'C':
+		d.xid, d.time = 0, time.Time{}
+	case 'R':
+		id := r.uint32()
+		rel := &pgoutputRelation{schema: r.string(), table: r.string()}
+		if rel.schema == "" {
+			rel.schema = "pg_catalog"
+		}
+		r.byte() // replica identity
+		n := int(r.int16())
+		for i := 0; i < n && r.err == nil; i++ {
+			flags := r.byte()
+			col := pgoutputColumn{key: flags&1 != 0, name: r.string(), oid: r.uint32()}
+			r.uint32() // type modifier
+			rel.columns = append(rel.columns, col)
+		}
+		if d.relations == nil {
+			d.relations = map[uint32]*pgoutputRelation{}
+		}
+		d.relations[id] = rel
+	case 'I', 'U', 'D':
+		rel, ok := d.relations[r.uint32()]
+		if !ok && r.err == nil {
+			return nil, fmt.Errorf("pgoutput change of unknown relation at %s", lsn)
+		}
+		c := d.change(lsn, rel)
+		switch data[0] {
+		case 'I':
+			c.Op = OpInsert
+			r.byte() // 'N'
+			c.After = d.tuple(r, rel)
+		case 'U':
+			c.Op = OpUpdate
+			if kind := r.byte(); kind == 'K' || kind == 'O' {
+				c.Before = d.tuple(r, rel)
+				r.byte() // 'N'
+			}
+			c.After = d.tuple(r, rel)
+		case 'D':
+			c.Op = OpDelete
+			r.byte() // 'K' or 'O'
+			c.Before = d.tuple(r, rel)
+		}
+		changes = append(changes, c)
+	case 'T':
+		n := int(r.uint32())
+		r.byte() // options
+		for i := 0; i < n && r.err == nil; i++ {
+			rel, ok := d.relations[r.uint32()]
+			if !ok && r.err == nil {
+				return nil, fmt.Errorf("pgoutput truncation of unknown relation at %s", lsn)
+			}
+			c := d.change(lsn, rel)
+			c.Op = OpTruncate
+			c.Key = nil
+			changes = append(changes, c)
+		}
+	default:
+		// The origins, types and logical decoding messages are not changes.
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("invalid pgoutput message %q at %s: %w", data[0], lsn, r.err)
+	}
+	return changes, nil
+}
+
+// change returns a change of rel, without operation.
+func (d *pgoutputDecoder) change(lsn string, rel *pgoutputRelation) Change {
+	if rel == nil {
+		return Change{}
+	}
+	c := Change{Schema: rel.schema, Table: rel.table, LSN: lsn, XID: d.xid, Time: d.time}
+	for _, col := range rel.columns {
+		if col.key {
+			c.Key = append(c.Key, col.name)
+		}
+	}
+	return c
+}
+
+// tuple reads the columns of a row of rel.
+func (d *pgoutputDecoder) tuple(r *pgoutputReader, rel *pgoutputRelation) map[string]interface{} {
+	n := int(r.int16())
+	row := make(map[string]interface{}, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		var col pgoutputColumn
+		if rel != nil && i < len(rel.columns) {
+			col = rel.columns[i]
+		} else {
+			col.name = strconv.Itoa(i)
+		}
+		switch r.byte() {
+		case 'n':
+			row[col.name] = nil
+		case 'u':
+			// An unchanged TOASTed value is not sent.
+		case 't', 'b':
+			row[col.name] = textValue(col.oid, string(r.next(int(r.uint32()))))
+		}
+	}
+	return row
+}
+
+// textValue converts the text value of a column of type oid to its JSON value.
+func textValue(oid uint32, s string) interface{} {
+	switch oid {
+	case oidBool:
+		return s == "t"
+	case oidInt2, oidInt4, oidInt8, oidFloat4, oidFloat8, oidNumeric:
+		if n := json.Number(s); json.Valid([]byte(n)) {
+			return n
+		}
+	case oidJSON, oidJSONB:
+		if json.Valid([]byte(s)) {
+			return json.RawMessage(s)
+		}
+	}
+	return s
+}
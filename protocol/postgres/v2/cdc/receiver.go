@@ -0,0 +1,248 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package cdc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// decoder decodes the output of a logical decoding plugin.
+type decoder interface {
+	// plugin returns the name of the plugin.
+	plugin() string
+	// peek returns the rows of the lsn and the data of up to n changes of
+	// slot, without consuming them.
+	peek(ctx context.Context, db *sql.DB, slot string, n int) (*sql.Rows, error)
+	// decode returns the changes of the data of a row.
+	decode(lsn string, data []byte) ([]Change, error)
+}
+
+// Receiver receives the changes of a logical replication slot as events, see
+// Change.Event.
+//
+// The changes are read in batches ending with a transaction. The slot is only
+// advanced once all the events of a batch are finished successfully, so the
+// events are received at least once: the batch is received again, at the
+// next poll, when an event is NACKed, or when the Receiver stops meanwhile.
+type Receiver struct {
+	DB   *sql.DB
+	Slot string
+
+	decoder      decoder
+	source       string
+	batchSize    int
+	pollInterval time.Duration
+
+	incoming chan binding.Message
+
+	openMtx       sync.Mutex
+	internalClose chan struct{}
+	closeOnce     sync.Once
+}
+
+// NewReceiver returns a Receiver of the changes of the logical replication
+// slot of db, see CreateSlot. It does not close db.
+func NewReceiver(db *sql.DB, slot string, opts ...Option) (*Receiver, error) {
+	if db == nil {
+		return nil, errors.New("cdc db can not be nil")
+	}
+	if slot == "" {
+		return nil, errors.New("cdc slot can not be empty")
+	}
+	r := &Receiver{
+		DB:            db,
+		Slot:          slot,
+		decoder:       &wal2jsonDecoder{},
+		source:        "/postgres/" + slot,
+		batchSize:     DefaultBatchSize,
+		pollInterval:  DefaultPollInterval,
+		incoming:      make(chan binding.Message),
+		internalClose: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// CreateSlot creates the logical replication slot of the Receiver with its
+// plugin, if it does not exist. The slot retains the changes from its
+// creation until they are received.
+func (r *Receiver) CreateSlot(ctx context.Context) error {
+	var exists bool
+	err := r.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_replication_slots WHERE slot_name = $1)`, r.Slot).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("cdc: failed to look up slot %q: %w", r.Slot, err)
+	}
+	if exists {
+		return nil
+	}
+	if _, err := r.DB.ExecContext(ctx, `SELECT pg_create_logical_replication_slot($1, $2)`, r.Slot, r.decoder.plugin()); err != nil {
+		return fmt.Errorf("cdc: failed to create slot %q: %w", r.Slot, err)
+	}
+	return nil
+}
+
+// OpenInbound implements Opener.OpenInbound: it polls the slot until ctx is
+// done or the Receiver is closed.
+func (r *Receiver) OpenInbound(ctx context.Context) error {
+	r.openMtx.Lock()
+	defer r.openMtx.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-r.internalClose:
+			cancel()
+		}
+	}()
+
+	for {
+		n, err := r.receiveBatch(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if n >= r.batchSize {
+			continue
+		}
+		timer := time.NewTimer(r.pollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+	}
+}
+
+// read returns the changes of the next batch of the slot, the number of rows
+// read and the LSN of the last one.
+func (r *Receiver) read(ctx context.Context) ([]Change, int, string, error) {
+	rows, err := r.decoder.peek(ctx, r.DB, r.Slot, r.batchSize)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("cdc: failed to read slot %q: %w", r.Slot, err)
+	}
+	defer rows.Close()
+	var (
+		changes []Change
+		n       int
+		lsn     string
+	)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&lsn, &data); err != nil {
+			return nil, 0, "", fmt.Errorf("cdc: failed to read slot %q: %w", r.Slot, err)
+		}
+		n++
+		c, err := r.decoder.decode(lsn, data)
+		if err != nil {
+			return nil, 0, "", fmt.Errorf("cdc: %w", err)
+		}
+		changes = append(changes, c...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, "", fmt.Errorf("cdc: failed to read slot %q: %w", r.Slot, err)
+	}
+	return changes, n, lsn, nil
+}
+
+// receiveBatch delivers the events of the next batch of the slot, and
+// advances the slot once they are all finished successfully. It returns the
+// number of rows of the batch.
+func (r *Receiver) receiveBatch(ctx context.Context) (int, error) {
+	changes, n, lsn, err := r.read(ctx)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		nacked bool
+	)
+	for i := range changes {
+		e, err := changes[i].Event(r.source)
+		if err != nil {
+			return 0, fmt.Errorf("cdc: invalid change at %s: %w", changes[i].LSN, err)
+		}
+		wg.Add(1)
+		m := binding.WithFinish(binding.ToMessage(e), func(err error) {
+			if !protocol.IsACK(err) {
+				mu.Lock()
+				nacked = true
+				mu.Unlock()
+			}
+			wg.Done()
+		})
+		select {
+		case r.incoming <- m:
+		case <-ctx.Done():
+			return 0, nil
+		}
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		return 0, nil
+	}
+	if nacked {
+		// Received again at the next poll.
+		return 0, nil
+	}
+	if _, err := r.DB.ExecContext(ctx, `SELECT pg_replication_slot_advance($1, $2::pg_lsn)`, r.Slot, lsn); err != nil {
+		return 0, fmt.Errorf("cdc: failed to advance slot %q to %s: %w", r.Slot, lsn, err)
+	}
+	return n, nil
+}
+
+// Receive implements Receiver.Receive.
+func (r *Receiver) Receive(ctx context.Context) (binding.Message, error) {
+	select {
+	case m := <-r.incoming:
+		return m, nil
+	case <-r.internalClose:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+// Close implements Closer.Close: it stops OpenInbound, and waits for it to
+// return.
+func (r *Receiver) Close(_ context.Context) error {
+	r.closeOnce.Do(func() {
+		close(r.internalClose)
+	})
+	r.openMtx.Lock()
+	defer r.openMtx.Unlock()
+	return nil
+}
+
+var _ protocol.Receiver = (*Receiver)(nil)
+var _ protocol.Opener = (*Receiver)(nil)
+var _ protocol.Closer = (*Receiver)(nil)
@@ -0,0 +1,170 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package cdc
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test/sqltest"
+)
+
+// fakeSlot is a logical replication slot of wal2json messages, served by a
+// database/sql driver understanding the statements of the package.
+type fakeSlot struct {
+	mu        sync.Mutex
+	plugin    string
+	messages  [][2]string // lsn, data
+	confirmed int         // number of consumed messages
+}
+
+// newFakeSlot returns an empty fakeSlot and a *sql.DB connected to it.
+func newFakeSlot(t *testing.T) (*fakeSlot, *sql.DB) {
+	s := &fakeSlot{}
+	return s, sqltest.Open(t, func() sqltest.Conn { return &fakeConn{slot: s} })
+}
+
+// commit appends a transaction inserting the ids into the table orders.
+func (s *fakeSlot) commit(xid int, ids ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	add := func(data string) {
+		s.messages = append(s.messages, [2]string{fmt.Sprintf("0/%X", len(s.messages)+1), data})
+	}
+	add(fmt.Sprintf(`{"action":"B","xid":%d,"timestamp":"2024-01-02 03:04:05+00"}`, xid))
+	for _, id := range ids {
+		add(fmt.Sprintf(`{"action":"I","schema":"public","table":"orders","columns":[{"name":"id","type":"integer","value":%d}],"pk":[{"name":"id","type":"integer"}]}`, id))
+	}
+	add(fmt.Sprintf(`{"action":"C","xid":%d}`, xid))
+}
+
+type fakeConn struct {
+	slot *fakeSlot
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := c.slot
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "SELECT pg_create_logical_replication_slot"):
+		s.plugin = args[1].Value.(string)
+	case strings.HasPrefix(query, "SELECT pg_replication_slot_advance"):
+		for i, m := range s.messages {
+			if m[0] == args[1].Value.(string) {
+				s.confirmed = i + 1
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s := c.slot
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "SELECT EXISTS"):
+		return sqltest.Rows([]string{"exists"}, []driver.Value{s.plugin != ""}), nil
+	case strings.HasPrefix(query, "SELECT lsn::text, data FROM pg_logical_slot_peek_changes"):
+		// The batches end with a transaction.
+		n := int(args[1].Value.(int64))
+		var rows [][]driver.Value
+		for _, m := range s.messages[s.confirmed:] {
+			rows = append(rows, []driver.Value{m[0], m[1]})
+			if len(rows) >= n && strings.Contains(m[1], `"action":"C"`) {
+				break
+			}
+		}
+		return sqltest.Rows([]string{"lsn", "data"}, rows...), nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+func TestReceiver(t *testing.T) {
+	s, db := newFakeSlot(t)
+	r, err := NewReceiver(db, "shop", WithBatchSize(2), WithPollInterval(time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, r.CreateSlot(context.Background()))
+	require.NoError(t, r.CreateSlot(context.Background()))
+	require.Equal(t, PluginWal2JSON, s.plugin)
+
+	s.commit(1, 1, 2, 3)
+	s.commit(2, 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opened := make(chan error, 1)
+	go func() { opened <- r.OpenInbound(ctx) }()
+
+	receive := func(result protocol.Result) string {
+		m, err := r.Receive(ctx)
+		require.NoError(t, err)
+		e, err := binding.ToEvent(ctx, m)
+		require.NoError(t, err)
+		require.Equal(t, "/postgres/shop/public/orders", e.Source())
+		require.Equal(t, "org.postgresql.cdc.insert", e.Type())
+		require.NoError(t, m.Finish(result))
+		return e.Subject()
+	}
+
+	// The batch of a NACKed event is received again.
+	require.Equal(t, "1", receive(nil))
+	require.Equal(t, "2", receive(protocol.ResultNACK))
+	require.Equal(t, "3", receive(nil))
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.Equal(t, id, receive(nil))
+	}
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.confirmed == len(s.messages)
+	}, time.Second, time.Millisecond)
+
+	s.commit(3, 5)
+	require.Equal(t, "5", receive(nil))
+
+	require.NoError(t, r.Close(context.Background()))
+	require.NoError(t, <-opened)
+	_, err = r.Receive(context.Background())
+	require.Equal(t, io.EOF, err)
+}
+
+func TestReceiverOptions(t *testing.T) {
+	_, db := newFakeSlot(t)
+	r, err := NewReceiver(db, "shop", WithPlugin(PluginPgoutput, "cdc"), WithSource("/shop"))
+	require.NoError(t, err)
+	require.Equal(t, PluginPgoutput, r.decoder.plugin())
+	require.Equal(t, "/shop", r.source)
+
+	for _, opt := range []Option{
+		WithPlugin(PluginPgoutput, ""),
+		WithPlugin("test_decoding", ""),
+		WithSource(""),
+		WithBatchSize(0),
+		WithPollInterval(0),
+	} {
+		_, err := NewReceiver(db, "shop", opt)
+		require.Error(t, err)
+	}
+	_, err = NewReceiver(nil, "shop")
+	require.Error(t, err)
+	_, err = NewReceiver(db, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,133 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// timestampLayouts are the text formats of the timestamps of PostgreSQL.
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999-07",
+	"2006-01-02 15:04:05.999999-07:00",
+	"2006-01-02 15:04:05.999999-07:00:00",
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	var err error
+	for _, layout := range timestampLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// wal2jsonColumn is a column of a wal2json change.
+type wal2jsonColumn struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// wal2jsonMessage is a message of the format version 2 of wal2json.
+type wal2jsonMessage struct {
+	Action    string           `json:"action"`
+	XID       uint32           `json:"xid"`
+	Timestamp string           `json:"timestamp"`
+	Schema    string           `json:"schema"`
+	Table     string           `json:"table"`
+	Columns   []wal2jsonColumn `json:"columns"`
+	Identity  []wal2jsonColumn `json:"identity"`
+	PK        []wal2jsonColumn `json:"pk"`
+}
+
+// wal2jsonDecoder decodes the format version 2 of wal2json, where each change
+// is a message.
+type wal2jsonDecoder struct {
+	xid  uint32
+	time time.Time
+}
+
+func (d *wal2jsonDecoder) plugin() string {
+	return PluginWal2JSON
+}
+
+func (d *wal2jsonDecoder) peek(ctx context.Context, db *sql.DB, slot string, n int) (*sql.Rows, error) {
+	return db.QueryContext(ctx, `SELECT lsn::text, data FROM pg_logical_slot_peek_changes($1, NULL, $2,
+		'format-version', '2', 'include-xids', '1', 'include-timestamp', '1', 'include-pk', '1')`, slot, n)
+}
+
+func (d *wal2jsonDecoder) decode(lsn string, data []byte) ([]Change, error) {
+	var m wal2jsonMessage
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid wal2json message at %s: %w", lsn, err)
+	}
+	t := d.time
+	if m.Timestamp != "" {
+		var err error
+		if t, err = parseTimestamp(m.Timestamp); err != nil {
+			return nil, fmt.Errorf("invalid wal2json timestamp at %s: %w", lsn, err)
+		}
+	}
+
+	c := Change{Schema: m.Schema, Table: m.Table, LSN: lsn, XID: d.xid, Time: t}
+	switch m.Action {
+	case "B":
+		d.xid, d.time = m.XID, t
+		return nil, nil
+	case "C":
+		d.xid, d.time = 0, time.Time{}
+		return nil, nil
+	case "I":
+		c.Op = OpInsert
+	case "U":
+		c.Op = OpUpdate
+	case "D":
+		c.Op = OpDelete
+	case "T":
+		c.Op = OpTruncate
+		return []Change{c}, nil
+	default:
+		// The logical decoding messages are not changes.
+		return nil, nil
+	}
+	if m.XID != 0 {
+		c.XID = m.XID
+	}
+	if len(m.Columns) > 0 {
+		c.After = wal2jsonRow(m.Columns)
+	}
+	if len(m.Identity) > 0 {
+		c.Before = wal2jsonRow(m.Identity)
+	}
+	for _, pk := range m.PK {
+		c.Key = append(c.Key, pk.Name)
+	}
+	if len(c.Key) == 0 && m.Action == "D" {
+		// Without primary key, the replica identity is the key.
+		for _, col := range m.Identity {
+			c.Key = append(c.Key, col.Name)
+		}
+	}
+	return []Change{c}, nil
+}
+
+func wal2jsonRow(columns []wal2jsonColumn) map[string]interface{} {
+	row := make(map[string]interface{}, len(columns))
+	for _, col := range columns {
+		row[col.Name] = col.Value
+	}
+	return row
+}
@@ -0,0 +1,21 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package postgres implements a CloudEvents protocol storing the events in a
+// PostgreSQL table, a pragmatic event store for smaller systems: the Sender
+// upserts the events, by source and id, in batches, and the Receiver tails the
+// table, woken up by LISTEN/NOTIFY.
+//
+// The attributes are stored in columns and the data in a JSONB column, or in a
+// BYTEA column when it is not JSON, see CreateTable for the layout of the
+// table. Like the eventstore/postgres module, this package only depends on
+// database/sql: the caller registers the driver of its choice (e.g.
+// github.com/jackc/pgx/v5/stdlib or github.com/lib/pq) and opens the *sql.DB.
+// As database/sql can not LISTEN, the notifications are received through a
+// Listener, adapted by the caller from its driver, see WithListener.
+//
+// The cdc package receives the changes of any table as events, from logical
+// replication.
+package postgres
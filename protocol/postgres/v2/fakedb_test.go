@@ -0,0 +1,130 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/test/sqltest"
+)
+
+// fakeDB is an in-memory table, served by a database/sql driver understanding
+// the statements of the package.
+type fakeDB struct {
+	mu       sync.Mutex
+	seq      int64
+	rows     map[[2]string][]driver.Value // (source, id) -> seq + columns
+	upserts  int
+	notified chan struct{}
+}
+
+// newFakeDB returns an empty fakeDB and a *sql.DB connected to it.
+func newFakeDB(t *testing.T) (*fakeDB, *sql.DB) {
+	f := &fakeDB{rows: map[[2]string][]driver.Value{}, notified: make(chan struct{}, 1)}
+	return f, sqltest.Open(t, func() sqltest.Conn { return &fakeConn{db: f} })
+}
+
+// WaitForNotification makes fakeDB a Listener of the notifications of the
+// committed transactions.
+func (f *fakeDB) WaitForNotification(ctx context.Context) error {
+	select {
+	case <-f.notified:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fakeConn buffers the upserts and the notifications of its transaction.
+type fakeConn struct {
+	db      *fakeDB
+	pending [][]driver.Value
+	notify  bool
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.pending, c.notify = nil, false
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	f := c.db
+	f.mu.Lock()
+	for _, row := range c.pending {
+		f.seq++
+		f.rows[[2]string{row[1].(string), row[0].(string)}] = append([]driver.Value{f.seq}, row...)
+	}
+	f.upserts++
+	f.mu.Unlock()
+	if c.notify {
+		select {
+		case f.notified <- struct{}{}:
+		default:
+		}
+	}
+	return c.Rollback()
+}
+
+func (c *fakeConn) Rollback() error {
+	c.pending, c.notify = nil, false
+	return nil
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	v := make([]driver.Value, len(args))
+	for i, a := range args {
+		v[i] = a.Value
+	}
+	return v
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case strings.HasPrefix(query, "SELECT pg_advisory_xact_lock"):
+	case strings.HasPrefix(query, "SELECT pg_notify"):
+		c.notify = true
+	case strings.HasPrefix(query, "INSERT INTO"):
+		v := values(args)
+		for len(v) > 0 {
+			c.pending = append(c.pending, v[:len(columns)])
+			v = v[len(columns):]
+		}
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "SELECT COALESCE(MAX(seq), 0)"):
+		return sqltest.Rows([]string{"max"}, []driver.Value{f.seq}), nil
+	case strings.HasPrefix(query, "SELECT "+selectColumns):
+		after := args[0].Value.(int64)
+		var rows [][]driver.Value
+		for _, row := range f.rows {
+			if row[0].(int64) > after {
+				rows = append(rows, row)
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][0].(int64) < rows[j][0].(int64) })
+		if len(rows) > readBatchSize {
+			rows = rows[:readBatchSize]
+		}
+		return sqltest.Rows(strings.Split(selectColumns, ", "), rows...), nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
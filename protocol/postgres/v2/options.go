@@ -0,0 +1,157 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	// DefaultTable is the name of the table used when WithTable is not
+	// provided.
+	DefaultTable = "cloudevents"
+	// DefaultBatchSize is the maximum number of events written per
+	// transaction when WithBatchSize is not provided.
+	DefaultBatchSize = 100
+	// DefaultPollInterval is the interval the Receiver polls the table at
+	// when WithPollInterval is not provided.
+	DefaultPollInterval = time.Second
+)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Listener waits for the notifications of the channel the Sender notifies,
+// see WithChannel. It is usually a connection of the driver of the caller,
+// which ran LISTEN on the channel: e.g. a *pq.Listener of github.com/lib/pq,
+// or a *pgx.Conn of github.com/jackc/pgx, whose WaitForNotification methods
+// are wrapped.
+type Listener interface {
+	// WaitForNotification blocks until a notification is received or ctx is
+	// done.
+	WaitForNotification(ctx context.Context) error
+}
+
+// Option is the function signature required to be considered an
+// postgres.Option. The options are shared by the Sender and the Receiver,
+// which ignore the ones of the other.
+type Option func(*options) error
+
+type options struct {
+	table        string
+	channel      string
+	batchSize    int
+	batchLinger  time.Duration
+	listener     Listener
+	pollInterval time.Duration
+	startSeq     int64
+}
+
+func newOptions(opts ...Option) (*options, error) {
+	o := &options{
+		table:        DefaultTable,
+		batchSize:    DefaultBatchSize,
+		pollInterval: DefaultPollInterval,
+		startSeq:     -1,
+	}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// WithTable sets the name of the table storing the events, optionally
+// qualified by a schema.
+func WithTable(name string) Option {
+	return func(o *options) error {
+		if !identifier.MatchString(name) {
+			return fmt.Errorf("postgres table name %q is invalid", name)
+		}
+		o.table = name
+		return nil
+	}
+}
+
+// WithChannel makes the Sender notify the channel once the events of each
+// batch are committed, so that the Receivers listening to it wake up.
+func WithChannel(channel string) Option {
+	return func(o *options) error {
+		if channel == "" {
+			return fmt.Errorf("postgres channel can not be empty")
+		}
+		o.channel = channel
+		return nil
+	}
+}
+
+// WithBatchSize sets the maximum number of events the Sender writes per
+// transaction. The events sent concurrently are batched, each Send returning
+// once its batch is committed. Defaults to DefaultBatchSize.
+func WithBatchSize(size int) Option {
+	return func(o *options) error {
+		if size < 1 || size > maxBatchSize {
+			return fmt.Errorf("postgres batch size must be between 1 and %d, got %d", maxBatchSize, size)
+		}
+		o.batchSize = size
+		return nil
+	}
+}
+
+// WithBatchLinger makes the Sender wait up to linger for more events before
+// writing a batch which is not full, trading latency for throughput. By
+// default, the batches hold the events sent while the previous one was
+// written.
+func WithBatchLinger(linger time.Duration) Option {
+	return func(o *options) error {
+		if linger < 0 {
+			return fmt.Errorf("postgres batch linger can not be negative, got %s", linger)
+		}
+		o.batchLinger = linger
+		return nil
+	}
+}
+
+// WithListener makes the Receiver query the table as soon as the Listener
+// receives a notification, rather than at the next poll.
+func WithListener(l Listener) Option {
+	return func(o *options) error {
+		if l == nil {
+			return fmt.Errorf("postgres listener can not be nil")
+		}
+		o.listener = l
+		return nil
+	}
+}
+
+// WithPollInterval sets the interval the Receiver queries the table at when
+// it received no notification. Defaults to DefaultPollInterval.
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *options) error {
+		if interval <= 0 {
+			return fmt.Errorf("postgres poll interval must be positive, got %s", interval)
+		}
+		o.pollInterval = interval
+		return nil
+	}
+}
+
+// WithStartSequence makes the Receiver receive the events written after the
+// sequence seq, e.g. the last one it processed before a restart, see
+// Message.Sequence. 0 receives all the events of the table. By default, the Receiver
+// receives the events written once it is opened.
+func WithStartSequence(seq int64) Option {
+	return func(o *options) error {
+		if seq < 0 {
+			return fmt.Errorf("postgres start sequence can not be negative, got %d", seq)
+		}
+		o.startSeq = seq
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Protocol writes the events to a PostgreSQL table and tails it, see Sender
+// and Receiver.
+type Protocol struct {
+	DB *sql.DB
+
+	Sender   *Sender
+	Receiver *Receiver
+}
+
+// NewProtocol returns a Protocol using the table of db, see CreateTable. The
+// options configure both the Sender and the Receiver. It does not close db.
+func NewProtocol(db *sql.DB, opts ...Option) (*Protocol, error) {
+	r, err := NewReceiver(db, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewSender(db, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Protocol{DB: db, Sender: s, Receiver: r}, nil
+}
+
+// Send implements Sender.Send
+func (p *Protocol) Send(ctx context.Context, in binding.Message, transformers ...binding.Transformer) error {
+	return p.Sender.Send(ctx, in, transformers...)
+}
+
+// OpenInbound implements Opener.OpenInbound
+func (p *Protocol) OpenInbound(ctx context.Context) error {
+	return p.Receiver.OpenInbound(ctx)
+}
+
+// Receive implements Receiver.Receive
+func (p *Protocol) Receive(ctx context.Context) (binding.Message, error) {
+	return p.Receiver.Receive(ctx)
+}
+
+// Ping implements Pinger.Ping: it checks the connection to the database and
+// the table.
+func (p *Protocol) Ping(ctx context.Context) error {
+	if err := p.DB.PingContext(ctx); err != nil {
+		return sendResult(fmt.Errorf("postgres ping failed: %w", err))
+	}
+	var seq int64
+	err := p.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) FROM `+p.Sender.options.table).Scan(&seq)
+	if err != nil {
+		return sendResult(fmt.Errorf("postgres: failed to read table %s: %w", p.Sender.options.table, err))
+	}
+	return nil
+}
+
+// Close implements Closer.Close
+func (p *Protocol) Close(ctx context.Context) error {
+	if err := p.Receiver.Close(ctx); err != nil {
+		return err
+	}
+	return p.Sender.Close(ctx)
+}
+
+var _ protocol.Receiver = (*Protocol)(nil)
+var _ protocol.Sender = (*Protocol)(nil)
+var _ protocol.Opener = (*Protocol)(nil)
+var _ protocol.Closer = (*Protocol)(nil)
+var _ protocol.Pinger = (*Protocol)(nil)
@@ -0,0 +1,202 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func newTestEvent(t *testing.T, id string) event.Event {
+	e := event.New()
+	e.SetID(id)
+	e.SetSource("/source")
+	e.SetType("test.type")
+	e.SetSubject("subject")
+	e.SetTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	e.SetExtension("tenant", "acme")
+	e.SetExtension("priority", 3)
+	require.NoError(t, e.SetData(event.ApplicationJSON, map[string]string{"id": id}))
+	return e
+}
+
+func TestRowRoundTrip(t *testing.T) {
+	binary := event.New()
+	binary.SetID("2")
+	binary.SetSource("/source")
+	binary.SetType("test.type")
+	binary.SetDataSchema("https://example.com/schema")
+	require.NoError(t, binary.SetData("application/octet-stream", []byte{0, 1, 2}))
+
+	// JSON data with a JSON content type, but invalid, is stored as binary.
+	invalid := event.New()
+	invalid.SetID("3")
+	invalid.SetSource("/source")
+	invalid.SetType("test.type")
+	require.NoError(t, invalid.SetData(event.ApplicationJSON, []byte("{")))
+
+	_, db := newFakeDB(t)
+	s, err := NewSender(db)
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+	require.NoError(t, s.SendEvents(context.Background(), binary, invalid))
+
+	r, err := NewReceiver(db, WithStartSequence(0))
+	require.NoError(t, err)
+	go func() {
+		var last int64
+		_, _ = r.readBatch(context.Background(), &last)
+	}()
+	for _, want := range []event.Event{binary, invalid} {
+		m, err := r.Receive(context.Background())
+		require.NoError(t, err)
+		got, err := binding.ToEvent(context.Background(), m)
+		require.NoError(t, err)
+		require.Equal(t, want.DataSchema(), got.DataSchema())
+		require.Equal(t, want.DataContentType(), got.DataContentType())
+		require.Equal(t, want.Data(), got.Data())
+		require.True(t, got.DataBase64)
+	}
+}
+
+func TestSendReceive(t *testing.T) {
+	f, db := newFakeDB(t)
+	p, err := NewProtocol(db, WithChannel("cloudevents"), WithListener(f), WithPollInterval(time.Hour), WithStartSequence(0))
+	require.NoError(t, err)
+	defer p.Close(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opened := make(chan error, 1)
+	go func() { opened <- p.OpenInbound(ctx) }()
+
+	sent := newTestEvent(t, "1")
+	require.NoError(t, p.Send(ctx, binding.ToMessage(&sent)))
+
+	m, err := p.Receive(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), m.(*Message).Sequence)
+	got, err := binding.ToEvent(ctx, m)
+	require.NoError(t, err)
+	require.NoError(t, m.Finish(nil))
+	require.Equal(t, sent.ID(), got.ID())
+	require.Equal(t, sent.Subject(), got.Subject())
+	require.Equal(t, sent.Time(), got.Time())
+	require.Equal(t, sent.Data(), got.Data())
+	require.Equal(t, "acme", got.Extensions()["tenant"])
+	require.Equal(t, "3", got.Extensions()["priority"])
+
+	// The replaced events are received again, once notified.
+	sent.SetSubject("replaced")
+	require.NoError(t, p.Send(ctx, binding.ToMessage(&sent)))
+	m, err = p.Receive(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), m.(*Message).Sequence)
+	got, err = binding.ToEvent(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, "replaced", got.Subject())
+
+	cancel()
+	require.NoError(t, <-opened)
+}
+
+func TestSendBatches(t *testing.T) {
+	f, db := newFakeDB(t)
+	s, err := NewSender(db, WithBatchSize(10), WithBatchLinger(50*time.Millisecond))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e := newTestEvent(t, fmt.Sprint(i%15))
+			require.NoError(t, s.Send(context.Background(), binding.ToMessage(&e)))
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, s.Close(context.Background()))
+
+	// The events sent twice are upserted.
+	require.Len(t, f.rows, 15)
+	require.Less(t, f.upserts, 20)
+
+	e := newTestEvent(t, "1")
+	require.ErrorIs(t, s.Send(context.Background(), binding.ToMessage(&e)), errSenderClosed)
+}
+
+func TestSendEventsAndStartSequence(t *testing.T) {
+	_, db := newFakeDB(t)
+	s, err := NewSender(db)
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	events := make([]event.Event, readBatchSize+10)
+	for i := range events {
+		events[i] = newTestEvent(t, fmt.Sprint(i))
+	}
+	require.NoError(t, s.SendEvents(context.Background(), events...))
+
+	r, err := NewReceiver(db, WithStartSequence(5), WithPollInterval(time.Millisecond))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.OpenInbound(ctx)
+
+	for i := 5; i < len(events); i++ {
+		m, err := r.Receive(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(i+1), m.(*Message).Sequence)
+		got, err := binding.ToEvent(ctx, m)
+		require.NoError(t, err)
+		require.Equal(t, events[i].ID(), got.ID())
+	}
+	require.NoError(t, r.Close(context.Background()))
+	_, err = r.Receive(context.Background())
+	require.Error(t, err)
+}
+
+func TestOptions(t *testing.T) {
+	_, err := newOptions(WithTable("public.events"), WithBatchSize(maxBatchSize))
+	require.NoError(t, err)
+
+	for _, opt := range []Option{
+		WithTable("events; DROP TABLE events"),
+		WithChannel(""),
+		WithBatchSize(0),
+		WithBatchSize(maxBatchSize + 1),
+		WithBatchLinger(-time.Second),
+		WithListener(nil),
+		WithPollInterval(0),
+		WithStartSequence(-1),
+	} {
+		_, err := newOptions(opt)
+		require.Error(t, err)
+	}
+
+	_, err = NewSender(nil)
+	require.Error(t, err)
+	_, err = NewReceiver(nil)
+	require.Error(t, err)
+}
+
+func TestUpsertQuery(t *testing.T) {
+	require.Equal(t,
+		"INSERT INTO events (id, source, specversion, type, subject, time, datacontenttype, dataschema, extensions, data, data_binary) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11), ($12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22) "+
+			"ON CONFLICT (source, id) DO UPDATE SET seq = DEFAULT, specversion = EXCLUDED.specversion, type = EXCLUDED.type, "+
+			"subject = EXCLUDED.subject, time = EXCLUDED.time, datacontenttype = EXCLUDED.datacontenttype, "+
+			"dataschema = EXCLUDED.dataschema, extensions = EXCLUDED.extensions, data = EXCLUDED.data, data_binary = EXCLUDED.data_binary",
+		upsertQuery("events", 2))
+}
@@ -0,0 +1,200 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// readBatchSize is the number of rows fetched per query.
+const readBatchSize = 256
+
+// Message is a binding.Message of an event read from the table.
+type Message struct {
+	*binding.EventMessage
+
+	// Sequence is the sequence of the event in the table, see
+	// WithStartSequence.
+	Sequence int64
+}
+
+// GetWrappedMessage implements binding.MessageWrapper.
+func (m *Message) GetWrappedMessage() binding.Message {
+	return m.EventMessage
+}
+
+var _ binding.MessageWrapper = (*Message)(nil)
+
+// Receiver tails the table, receiving the events in the order they were
+// written, see CreateTable. The events are not removed from the table once
+// received, so several Receivers may tail it.
+type Receiver struct {
+	DB *sql.DB
+
+	options  *options
+	incoming chan *Message
+
+	openMtx       sync.Mutex
+	internalClose chan struct{}
+	closeOnce     sync.Once
+}
+
+// NewReceiver returns a Receiver reading the table of db, see CreateTable. It
+// does not close db.
+func NewReceiver(db *sql.DB, opts ...Option) (*Receiver, error) {
+	if db == nil {
+		return nil, errors.New("postgres db can not be nil")
+	}
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Receiver{
+		DB:            db,
+		options:       o,
+		incoming:      make(chan *Message),
+		internalClose: make(chan struct{}),
+	}, nil
+}
+
+// OpenInbound implements Opener.OpenInbound: it tails the table until ctx is
+// done or the Receiver is closed. The table is queried when the Listener
+// receives a notification, see WithListener, and at the poll interval.
+func (r *Receiver) OpenInbound(ctx context.Context) error {
+	r.openMtx.Lock()
+	defer r.openMtx.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-r.internalClose:
+			cancel()
+		}
+	}()
+
+	last := r.options.startSeq
+	if last < 0 {
+		err := r.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) FROM `+r.options.table).Scan(&last)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("postgres: failed to read table %s: %w", r.options.table, err)
+		}
+	}
+	for {
+		n, err := r.readBatch(ctx, &last)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if n == readBatchSize {
+			continue
+		}
+		if err := r.wait(ctx); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// readBatch delivers the events written after the sequence last, up to
+// readBatchSize, and updates last. It returns the number of events read.
+func (r *Receiver) readBatch(ctx context.Context, last *int64) (int, error) {
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT `+selectColumns+` FROM `+r.options.table+` WHERE seq > $1 ORDER BY seq LIMIT `+fmt.Sprint(readBatchSize),
+		*last)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: failed to read table %s: %w", r.options.table, err)
+	}
+	var messages []*Message
+	for rows.Next() {
+		seq, e, err := scanEvent(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("postgres: failed to read table %s: %w", r.options.table, err)
+		}
+		messages = append(messages, &Message{EventMessage: (*binding.EventMessage)(e), Sequence: seq})
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, fmt.Errorf("postgres: failed to read table %s: %w", r.options.table, err)
+	}
+
+	// The rows are closed before the events are delivered, so that slow
+	// receivers do not keep a connection busy.
+	for _, m := range messages {
+		select {
+		case r.incoming <- m:
+			*last = m.Sequence
+		case <-ctx.Done():
+			return 0, nil
+		}
+	}
+	return len(messages), nil
+}
+
+// wait returns when a notification is received, or the poll interval elapsed.
+func (r *Receiver) wait(ctx context.Context) error {
+	if r.options.listener == nil {
+		timer := time.NewTimer(r.options.pollInterval)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+		return nil
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, r.options.pollInterval)
+	defer cancel()
+	if err := r.options.listener.WaitForNotification(waitCtx); err != nil && waitCtx.Err() == nil {
+		return fmt.Errorf("postgres: failed to wait for notification: %w", err)
+	}
+	return nil
+}
+
+// Receive implements Receiver.Receive: the returned messages are *Message.
+func (r *Receiver) Receive(ctx context.Context) (binding.Message, error) {
+	select {
+	case m := <-r.incoming:
+		return m, nil
+	case <-r.internalClose:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+// Close implements Closer.Close: it stops OpenInbound, and waits for it to
+// return.
+func (r *Receiver) Close(_ context.Context) error {
+	r.closeOnce.Do(func() {
+		close(r.internalClose)
+	})
+	r.openMtx.Lock()
+	defer r.openMtx.Unlock()
+	return nil
+}
+
+var _ protocol.Receiver = (*Receiver)(nil)
+var _ protocol.Opener = (*Receiver)(nil)
+var _ protocol.Closer = (*Receiver)(nil)
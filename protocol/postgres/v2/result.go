@@ -0,0 +1,59 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// sqlStateError is implemented by the errors of the PostgreSQL drivers, such
+// as *pgconn.PgError of github.com/jackc/pgx and *pq.Error of
+// github.com/lib/pq.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// transientSQLStates are the SQLSTATE codes, or classes, of the errors which
+// may not happen if retried.
+var transientSQLStates = []string{
+	"08",    // connection_exception
+	"40001", // serialization_failure
+	"40P01", // deadlock_detected
+	"53",    // insufficient_resources
+	"57P01", // admin_shutdown
+	"57P03", // cannot_connect_now
+}
+
+// isTransient reports whether err may not happen if retried.
+func isTransient(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var serr sqlStateError
+	if !errors.As(err, &serr) {
+		return false
+	}
+	state := serr.SQLState()
+	for _, transient := range transientSQLStates {
+		if strings.HasPrefix(state, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendResult returns the protocol.BrokerResult of the error of a send.
+func sendResult(err error) error {
+	if err == nil {
+		return nil
+	}
+	return protocol.NewBrokerResult(err, isTransient(err), 0)
+}
@@ -0,0 +1,233 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// errSenderClosed is returned by Send once the Sender is closed.
+var errSenderClosed = errors.New("postgres sender is closed")
+
+// sendRequest is an event waiting for its batch to be written.
+type sendRequest struct {
+	row  []interface{}
+	key  [2]string
+	done chan error
+}
+
+// Sender upserts the events in the table, in batches: the events sent
+// concurrently are written in the same transaction.
+type Sender struct {
+	DB *sql.DB
+
+	options  *options
+	requests chan *sendRequest
+	closing  chan struct{}
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// NewSender returns a Sender writing to the table of db, see CreateTable. It
+// does not close db.
+func NewSender(db *sql.DB, opts ...Option) (*Sender, error) {
+	if db == nil {
+		return nil, errors.New("postgres db can not be nil")
+	}
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &Sender{
+		DB:       db,
+		options:  o,
+		requests: make(chan *sendRequest),
+		closing:  make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Send implements Sender.Send: it returns once the batch of the event is
+// committed.
+func (s *Sender) Send(ctx context.Context, in binding.Message, transformers ...binding.Transformer) (err error) {
+	defer func() {
+		if err2 := in.Finish(err); err2 != nil {
+			if err == nil {
+				err = err2
+			} else {
+				err = fmt.Errorf("failed to call in.Finish() when error already occurred: %s: %w", err2.Error(), err)
+			}
+		}
+	}()
+
+	e, err := binding.ToEvent(ctx, in, transformers...)
+	if err != nil {
+		return err
+	}
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	row, err := eventRow(e)
+	if err != nil {
+		return err
+	}
+	req := &sendRequest{row: row, key: [2]string{e.Source(), e.ID()}, done: make(chan error, 1)}
+	select {
+	case s.requests <- req:
+	case <-s.closing:
+		return errSenderClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-req.done:
+		return err
+	case <-ctx.Done():
+		// The batch may still be committed.
+		return ctx.Err()
+	}
+}
+
+// SendEvents upserts events in one transaction, bypassing the batching of
+// Send, e.g. to import events.
+func (s *Sender) SendEvents(ctx context.Context, events ...event.Event) error {
+	batch := make([]*sendRequest, 0, len(events))
+	for i := range events {
+		if err := events[i].Validate(); err != nil {
+			return err
+		}
+		row, err := eventRow(&events[i])
+		if err != nil {
+			return err
+		}
+		batch = append(batch, &sendRequest{row: row, key: [2]string{events[i].Source(), events[i].ID()}})
+	}
+	for len(batch) > 0 {
+		n := min(len(batch), maxBatchSize)
+		if err := s.write(ctx, batch[:n]); err != nil {
+			return err
+		}
+		batch = batch[n:]
+	}
+	return nil
+}
+
+// run writes the batches of the requests until the Sender is closed.
+func (s *Sender) run() {
+	defer close(s.closed)
+	for {
+		var batch []*sendRequest
+		select {
+		case req := <-s.requests:
+			batch = append(batch, req)
+		case <-s.closing:
+			return
+		}
+		batch = s.fill(batch)
+		err := s.write(context.Background(), batch)
+		for _, req := range batch {
+			req.done <- err
+		}
+	}
+}
+
+// fill adds the pending requests to batch, waiting up to the batch linger for
+// more, until it is full.
+func (s *Sender) fill(batch []*sendRequest) []*sendRequest {
+	var linger <-chan time.Time
+	if s.options.batchLinger > 0 {
+		timer := time.NewTimer(s.options.batchLinger)
+		defer timer.Stop()
+		linger = timer.C
+	}
+	for len(batch) < s.options.batchSize {
+		select {
+		case req := <-s.requests:
+			batch = append(batch, req)
+			continue
+		default:
+		}
+		if linger == nil {
+			return batch
+		}
+		select {
+		case req := <-s.requests:
+			batch = append(batch, req)
+		case <-linger:
+			return batch
+		case <-s.closing:
+			return batch
+		}
+	}
+	return batch
+}
+
+// write upserts the events of batch in one transaction, and notifies the
+// channel of the Sender.
+func (s *Sender) write(ctx context.Context, batch []*sendRequest) error {
+	// An event sent twice in a batch can not be upserted twice by the same
+	// statement, its last version is kept.
+	last := make(map[[2]string]int, len(batch))
+	for i, req := range batch {
+		last[req.key] = i
+	}
+	args := make([]interface{}, 0, len(last)*len(columns))
+	for i, req := range batch {
+		if last[req.key] == i {
+			args = append(args, req.row...)
+		}
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return sendResult(fmt.Errorf("postgres: failed to write events: %w", err))
+	}
+	defer tx.Rollback()
+
+	// Serialize the writes to the table until the end of the transaction, so
+	// that the sequences are committed in order and the Receivers tailing the
+	// table do not skip any.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, s.options.table); err != nil {
+		return sendResult(fmt.Errorf("postgres: failed to lock table %s: %w", s.options.table, err))
+	}
+	if _, err := tx.ExecContext(ctx, upsertQuery(s.options.table, len(last)), args...); err != nil {
+		return sendResult(fmt.Errorf("postgres: failed to write events: %w", err))
+	}
+	if s.options.channel != "" {
+		// The notification is delivered once the transaction is committed.
+		if _, err := tx.ExecContext(ctx, `SELECT pg_notify($1, '')`, s.options.channel); err != nil {
+			return sendResult(fmt.Errorf("postgres: failed to notify channel %q: %w", s.options.channel, err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return sendResult(fmt.Errorf("postgres: failed to write events: %w", err))
+	}
+	return nil
+}
+
+// Close implements Closer.Close: the batch being written is committed, the
+// pending sends fail.
+func (s *Sender) Close(_ context.Context) error {
+	s.once.Do(func() {
+		close(s.closing)
+	})
+	<-s.closed
+	return nil
+}
+
+var _ protocol.Sender = (*Sender)(nil)
+var _ protocol.Closer = (*Sender)(nil)
@@ -0,0 +1,198 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// columns are the columns written by the Sender, in the order of the values
+// of eventRow.
+var columns = []string{
+	"id", "source", "specversion", "type", "subject", "time",
+	"datacontenttype", "dataschema", "extensions", "data", "data_binary",
+}
+
+// maxBatchSize keeps the parameters of the upserts below the limit of the
+// PostgreSQL protocol, 65535.
+var maxBatchSize = 65535 / len(columns)
+
+// CreateTable creates the table storing the events, if it does not exist.
+//
+// The events are identified by their source and id: sending an event again
+// replaces it. The seq column orders the writes, so that the Receiver tails
+// the table: it is reassigned when an event is replaced, which is then
+// received again. The extensions are stored as strings in the JSONB object
+// extensions. The data is stored in the JSONB column data when it is JSON,
+// otherwise in the BYTEA column data_binary, and read as base64 data.
+func CreateTable(ctx context.Context, db *sql.DB, opts ...Option) error {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return err
+	}
+	index := strings.ReplaceAll(o.table, ".", "_") + "_seq_idx"
+	_, err = db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS `+o.table+` (
+	seq             BIGSERIAL   NOT NULL,
+	id              TEXT        NOT NULL,
+	source          TEXT        NOT NULL,
+	specversion     TEXT        NOT NULL,
+	type            TEXT        NOT NULL,
+	subject         TEXT,
+	time            TIMESTAMPTZ,
+	datacontenttype TEXT,
+	dataschema      TEXT,
+	extensions      JSONB       NOT NULL DEFAULT '{}',
+	data            JSONB,
+	data_binary     BYTEA,
+	PRIMARY KEY (source, id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `+index+` ON `+o.table+` (seq);`)
+	if err != nil {
+		return fmt.Errorf("postgres: failed to create table %s: %w", o.table, err)
+	}
+	return nil
+}
+
+// isJSON reports whether the data of e is stored in the data column: its
+// content type is JSON, and so is the data.
+func isJSON(e *event.Event) bool {
+	ct := e.DataMediaType()
+	if ct != "" && ct != event.ApplicationJSON && ct != event.TextJSON && !strings.HasSuffix(ct, "+json") {
+		return false
+	}
+	return json.Valid(e.Data())
+}
+
+// nullString returns s, or nil when empty, so that it is stored as NULL.
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// eventRow returns the values of the columns of e.
+func eventRow(e *event.Event) ([]interface{}, error) {
+	extensions := make(map[string]string, len(e.Extensions()))
+	for name, v := range e.Extensions() {
+		s, err := types.Format(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extension %q: %w", name, err)
+		}
+		extensions[name] = s
+	}
+	b, err := json.Marshal(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	var t, data, binary interface{}
+	if !e.Time().IsZero() {
+		t = e.Time().UTC()
+	}
+	if d := e.Data(); len(d) > 0 {
+		if isJSON(e) {
+			data = string(d)
+		} else {
+			binary = d
+		}
+	}
+	return []interface{}{
+		e.ID(), e.Source(), e.SpecVersion(), e.Type(), nullString(e.Subject()), t,
+		nullString(e.DataContentType()), nullString(e.DataSchema()), string(b), data, binary,
+	}, nil
+}
+
+// rowScanner is a *sql.Rows or a *sql.Row.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEvent returns the sequence and the event of the current row of the
+// query of selectColumns.
+func scanEvent(row rowScanner) (int64, *event.Event, error) {
+	var (
+		seq                                  int64
+		id, source, specversion, typ         string
+		subject, datacontenttype, dataschema sql.NullString
+		t                                    sql.NullTime
+		extensions, data, binary             []byte
+	)
+	err := row.Scan(&seq, &id, &source, &specversion, &typ, &subject, &t,
+		&datacontenttype, &dataschema, &extensions, &data, &binary)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	e := event.New(specversion)
+	e.SetID(id)
+	e.SetSource(source)
+	e.SetType(typ)
+	if subject.Valid {
+		e.SetSubject(subject.String)
+	}
+	if t.Valid {
+		e.SetTime(t.Time)
+	}
+	if dataschema.Valid {
+		e.SetDataSchema(dataschema.String)
+	}
+	if datacontenttype.Valid {
+		e.SetDataContentType(datacontenttype.String)
+	}
+	if len(extensions) > 0 {
+		var m map[string]string
+		if err := json.Unmarshal(extensions, &m); err != nil {
+			return 0, nil, fmt.Errorf("invalid extensions of event at sequence %d: %w", seq, err)
+		}
+		for name, v := range m {
+			e.SetExtension(name, v)
+		}
+	}
+	switch {
+	case binary != nil:
+		e.DataEncoded = binary
+		e.DataBase64 = true
+	case data != nil:
+		e.DataEncoded = data
+	}
+	return seq, &e, nil
+}
+
+// selectColumns are the columns read by scanEvent.
+const selectColumns = `seq, id, source, specversion, type, subject, time, datacontenttype, dataschema, extensions, data, data_binary`
+
+// upsertQuery returns the statement upserting n events.
+func upsertQuery(table string, n int) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES ")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "$%d", i*len(columns)+j+1)
+		}
+		b.WriteByte(')')
+	}
+	b.WriteString(" ON CONFLICT (source, id) DO UPDATE SET seq = DEFAULT")
+	for _, c := range columns[2:] {
+		b.WriteString(", " + c + " = EXCLUDED." + c)
+	}
+	return b.String()
+}
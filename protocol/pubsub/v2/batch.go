@@ -0,0 +1,68 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pubsub
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// DefaultBatchSize is the maximum number of messages of the batches returned
+// by ReceiveBatch, unless set with WithReceiveBatch.
+const DefaultBatchSize = 100
+
+// ReceiveBatch implements BatchReceiver.ReceiveBatch: it waits for a message,
+// then adds the messages pulled meanwhile, up to the batch size. With the
+// delay set by WithReceiveBatch, it waits for that long for the batch to fill.
+// Each message is acknowledged with its own result.
+func (t *Protocol) ReceiveBatch(ctx context.Context) ([]binding.Message, error) {
+	m, err := t.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	size := t.batchSize
+	if size == 0 {
+		size = DefaultBatchSize
+	}
+	batch := []binding.Message{m}
+
+	if t.batchDelay <= 0 {
+		for len(batch) < size {
+			select {
+			case m, ok := <-t.incoming:
+				if !ok {
+					return batch, nil
+				}
+				batch = append(batch, NewMessage(&m))
+			default:
+				return batch, nil
+			}
+		}
+		return batch, nil
+	}
+
+	timer := cecontext.ClockFrom(ctx).NewTimer(t.batchDelay)
+	defer timer.Stop()
+	for len(batch) < size {
+		select {
+		case m, ok := <-t.incoming:
+			if !ok {
+				return batch, nil
+			}
+			batch = append(batch, NewMessage(&m))
+		case <-timer.C():
+			return batch, nil
+		case <-ctx.Done():
+			return batch, nil
+		}
+	}
+	return batch, nil
+}
+
+var _ protocol.BatchReceiver = (*Protocol)(nil)
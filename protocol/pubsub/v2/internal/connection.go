@@ -66,6 +66,10 @@ type Connection struct {
 	// ReceiveSettings is used to configure Pubsub pull subscription.
 	ReceiveSettings *pubsub.ReceiveSettings
 
+	// PublishSettings is used to configure the Pubsub publisher.
+	// Default is pubsub.DefaultPublishSettings.
+	PublishSettings *pubsub.PublishSettings
+
 	// AckDeadline is Pub/Sub AckDeadline.
 	// Default is 30 seconds.
 	// This can only be set prior to first call of any function.
@@ -124,6 +128,32 @@ func subscriptionExists(ctx context.Context, client *pubsub.Client, subID string
 	return true, nil
 }
 
+// Ping checks that the subscription of the connection, or its topic if it
+// has no subscription, exists, or that the protocol is allowed to create it.
+func (c *Connection) Ping(ctx context.Context) error {
+	if c.SubscriptionID != "" {
+		ok, err := subscriptionExists(ctx, c.Client, c.SubscriptionID)
+		if err != nil || ok {
+			return err
+		}
+		if !c.AllowCreateSubscription {
+			return fmt.Errorf("subscription %q does not exist", c.SubscriptionID)
+		}
+		// The subscription is created on the topic.
+		if c.TopicID == "" {
+			return nil
+		}
+	}
+	ok, err := topicExists(ctx, c.Client, c.TopicID)
+	if err != nil || ok {
+		return err
+	}
+	if !c.AllowCreateTopic {
+		return fmt.Errorf("topic %q does not exist", c.TopicID)
+	}
+	return nil
+}
+
 func (c *Connection) getOrCreateTopicInfo(ctx context.Context, getAlreadyOpenOnly bool) (*topicInfo, error) {
 	// See if a topic has already been created or is in the process of being created.
 	// If not, start creating one.
@@ -167,6 +197,9 @@ func (c *Connection) getOrCreateTopicInfo(ctx context.Context, getAlreadyOpenOnl
 		// Pub/Sub configuration. The Pub/Sub SDK requires this to be set to accept Pub/Sub
 		// messages with an ordering key set.
 		ti.topic.EnableMessageOrdering = c.MessageOrdering
+		if c.PublishSettings != nil {
+			ti.topic.PublishSettings = *c.PublishSettings
+		}
 	})
 	if ti.topic == nil {
 		// Initialization failed, remove this attempt so that future callers
@@ -354,6 +387,16 @@ func (c *Connection) Publish(ctx context.Context, msg *pubsub.Message) (*binding
 	return nil, err
 }
 
+// PublishAsync publishes a message to the connection's topic without waiting
+// for the server to confirm it.
+func (c *Connection) PublishAsync(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+	topic, err := c.getOrCreateTopic(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return topic.Publish(ctx, msg), nil
+}
+
 // Receive begins pulling messages.
 // NOTE: This is a blocking call.
 func (c *Connection) Receive(ctx context.Context, fn func(context.Context, *pubsub.Message)) error {
@@ -59,6 +59,19 @@ func NewMessage(pm *pubsub.Message) *Message {
 // Check if pubsub.Message implements binding.Message
 var _ binding.Message = (*Message)(nil)
 var _ binding.MessageMetadataReader = (*Message)(nil)
+var _ protocol.TransportMetadataReader = (*Message)(nil)
+
+// TransportMetadata implements protocol.TransportMetadataReader, it returns
+// the delivery attempt of the message, only counted by Pub/Sub for the
+// subscriptions with a dead letter policy, and its publish time as the first
+// delivery time.
+func (m *Message) TransportMetadata() protocol.TransportMetadata {
+	md := protocol.TransportMetadata{FirstDeliveryTime: m.internal.PublishTime}
+	if m.internal.DeliveryAttempt != nil {
+		md.DeliveryAttempt = *m.internal.DeliveryAttempt
+	}
+	return md
+}
 
 func (m *Message) ReadEncoding() binding.Encoding {
 	if m.version != nil {
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/pubsub/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
@@ -102,3 +103,18 @@ func TestFinish(t *testing.T) {
 		})
 	}
 }
+
+func TestTransportMetadata(t *testing.T) {
+	published := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	attempt := 3
+	md := NewMessage(&pubsub.Message{PublishTime: published, DeliveryAttempt: &attempt}).TransportMetadata()
+	if md.DeliveryAttempt != 3 || !md.FirstDeliveryTime.Equal(published) {
+		t.Errorf("unexpected transport metadata: %+v", md)
+	}
+
+	// Without a dead letter policy, the deliveries are not counted.
+	md = NewMessage(&pubsub.Message{PublishTime: published}).TransportMetadata()
+	if md.DeliveryAttempt != 0 {
+		t.Errorf("unexpected delivery attempt: %d", md.DeliveryAttempt)
+	}
+}
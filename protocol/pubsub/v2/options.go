@@ -6,8 +6,10 @@
 package pubsub
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"cloud.google.com/go/pubsub/v2"
 )
@@ -219,6 +221,114 @@ func WithReceiveSettings(rs *pubsub.ReceiveSettings) Option {
 	}
 }
 
+// WithMaxOutstandingMessages sets the maximum number of unprocessed messages
+// of a pull subscription. A negative value means no limit. It updates the
+// ReceiveSettings set by WithReceiveSettings, if any.
+func WithMaxOutstandingMessages(n int) Option {
+	return func(t *Protocol) error {
+		t.receiveSettings().MaxOutstandingMessages = n
+		return nil
+	}
+}
+
+// WithMaxOutstandingBytes sets the maximum size of unprocessed messages of a
+// pull subscription. A negative value means no limit. It updates the
+// ReceiveSettings set by WithReceiveSettings, if any.
+func WithMaxOutstandingBytes(n int) Option {
+	return func(t *Protocol) error {
+		t.receiveSettings().MaxOutstandingBytes = n
+		return nil
+	}
+}
+
+// WithNumGoroutines sets the number of goroutines pulling messages of a pull
+// subscription. It updates the ReceiveSettings set by WithReceiveSettings, if
+// any.
+func WithNumGoroutines(n int) Option {
+	return func(t *Protocol) error {
+		if n < 1 {
+			return errors.New("number of goroutines must be positive")
+		}
+		t.receiveSettings().NumGoroutines = n
+		return nil
+	}
+}
+
+// WithMinExtensionPeriod sets the minimum duration a message ack deadline is
+// extended by (MinDurationPerAckExtension in the Pub/Sub v2 client). It updates
+// the ReceiveSettings set by WithReceiveSettings, if any.
+func WithMinExtensionPeriod(d time.Duration) Option {
+	return func(t *Protocol) error {
+		if d < 0 {
+			return errors.New("min extension period can not be negative")
+		}
+		t.receiveSettings().MinDurationPerAckExtension = d
+		return nil
+	}
+}
+
+// WithReceiveBatch sets the maximum number of messages of the batches returned
+// by ReceiveBatch, and how long it waits for a batch to fill once it received
+// its first message. Without it, batches hold the messages already pulled, up
+// to DefaultBatchSize.
+func WithReceiveBatch(size int, delay time.Duration) Option {
+	return func(t *Protocol) error {
+		if size < 1 {
+			return errors.New("batch size must be positive")
+		}
+		if delay < 0 {
+			return errors.New("batch delay must not be negative")
+		}
+		t.batchSize = size
+		t.batchDelay = delay
+		return nil
+	}
+}
+
+// WithPublishSettings sets the Pubsub PublishSettings controlling the batching
+// of the published messages.
+func WithPublishSettings(ps *pubsub.PublishSettings) Option {
+	return func(t *Protocol) error {
+		t.PublishSettings = ps
+		return nil
+	}
+}
+
+// WithPublishBatching sets the thresholds triggering the publication of a
+// batch of messages: the delay since the first message of the batch, the
+// number of messages and their size. A zero threshold keeps the default value.
+// It updates the PublishSettings set by WithPublishSettings, if any.
+func WithPublishBatching(delay time.Duration, count, bytes int) Option {
+	return func(t *Protocol) error {
+		if delay < 0 || count < 0 || bytes < 0 {
+			return errors.New("publish batching thresholds can not be negative")
+		}
+		ps := t.publishSettings()
+		if delay > 0 {
+			ps.DelayThreshold = delay
+		}
+		if count > 0 {
+			ps.CountThreshold = count
+		}
+		if bytes > 0 {
+			ps.ByteThreshold = bytes
+		}
+		return nil
+	}
+}
+
+// WithPublishNumGoroutines sets the number of goroutines publishing batches of
+// messages. It updates the PublishSettings set by WithPublishSettings, if any.
+func WithPublishNumGoroutines(n int) Option {
+	return func(t *Protocol) error {
+		if n < 1 {
+			return errors.New("number of goroutines must be positive")
+		}
+		t.publishSettings().NumGoroutines = n
+		return nil
+	}
+}
+
 // WithMessageOrdering enables message ordering for all topics and subscriptions.
 func WithMessageOrdering() Option {
 	return func(t *Protocol) error {
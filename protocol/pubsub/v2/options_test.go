@@ -0,0 +1,86 @@
+package pubsub
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowControlOptions(t *testing.T) {
+	given := &pubsub.ReceiveSettings{MaxExtension: time.Minute}
+	givenPublish := &pubsub.PublishSettings{CountThreshold: 10}
+
+	testCases := map[string]struct {
+		opts        []Option
+		wantReceive *pubsub.ReceiveSettings
+		wantPublish *pubsub.PublishSettings
+		wantErr     string
+	}{
+		"receive settings": {
+			opts: []Option{
+				WithMaxOutstandingMessages(5000),
+				WithMaxOutstandingBytes(-1),
+				WithNumGoroutines(8),
+				WithMinExtensionPeriod(time.Second),
+			},
+			wantReceive: &pubsub.ReceiveSettings{
+				MaxOutstandingMessages:     5000,
+				MaxOutstandingBytes:        -1,
+				NumGoroutines:              8,
+				MinDurationPerAckExtension: time.Second,
+			},
+		},
+		"update given receive settings": {
+			opts: []Option{WithReceiveSettings(given), WithNumGoroutines(4)},
+			wantReceive: &pubsub.ReceiveSettings{
+				MaxExtension:  time.Minute,
+				NumGoroutines: 4,
+			},
+		},
+		"invalid goroutines": {
+			opts:    []Option{WithNumGoroutines(0)},
+			wantErr: "number of goroutines must be positive",
+		},
+		"invalid extension period": {
+			opts:    []Option{WithMinExtensionPeriod(-time.Second)},
+			wantErr: "min extension period can not be negative",
+		},
+		"publish batching": {
+			opts: []Option{WithPublishBatching(time.Millisecond, 0, 1<<20), WithPublishNumGoroutines(2)},
+			wantPublish: func() *pubsub.PublishSettings {
+				ps := pubsub.DefaultPublishSettings
+				ps.DelayThreshold = time.Millisecond
+				ps.ByteThreshold = 1 << 20
+				ps.NumGoroutines = 2
+				return &ps
+			}(),
+		},
+		"update given publish settings": {
+			opts:        []Option{WithPublishSettings(givenPublish), WithPublishBatching(0, 50, 0)},
+			wantPublish: &pubsub.PublishSettings{CountThreshold: 50},
+		},
+		"invalid publish batching": {
+			opts:    []Option{WithPublishBatching(0, -1, 0)},
+			wantErr: "publish batching thresholds can not be negative",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p := &Protocol{}
+			err := p.applyOptions(tc.opts...)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantReceive, p.ReceiveSettings)
+			require.Equal(t, tc.wantPublish, p.PublishSettings)
+		})
+	}
+
+	// The settings given to the options are left untouched.
+	require.Equal(t, &pubsub.ReceiveSettings{MaxExtension: time.Minute}, given)
+	require.Equal(t, &pubsub.PublishSettings{CountThreshold: 10}, givenPublish)
+}
@@ -7,9 +7,11 @@ package pubsub
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"sync"
+	"time"
 
 	"cloud.google.com/go/pubsub/v2"
 	"github.com/cloudevents/sdk-go/protocol/pubsub/v2/internal"
@@ -36,6 +38,9 @@ type Protocol struct {
 	// ReceiveSettings is used to configure Pubsub pull subscription.
 	ReceiveSettings *pubsub.ReceiveSettings
 
+	// PublishSettings is used to configure the batching of Pubsub publishers.
+	PublishSettings *pubsub.PublishSettings
+
 	// AllowCreateTopic controls if the transport can create a topic if it does
 	// not exist.
 	AllowCreateTopic bool
@@ -59,6 +64,14 @@ type Protocol struct {
 	connectionsByTopic        map[string]*internal.Connection
 
 	incoming chan pubsub.Message
+
+	// batchSize and batchDelay configure ReceiveBatch, see WithReceiveBatch.
+	batchSize  int
+	batchDelay time.Duration
+
+	pauseMux sync.Mutex
+	// resumed is closed when the protocol resumes, nil if not paused.
+	resumed chan struct{}
 }
 
 // New creates a new pubsub transport.
@@ -98,11 +111,68 @@ func (t *Protocol) applyOptions(opts ...Option) error {
 	return nil
 }
 
+// receiveSettings returns a copy of the ReceiveSettings to update, so that
+// the settings given to WithReceiveSettings are left untouched.
+func (t *Protocol) receiveSettings() *pubsub.ReceiveSettings {
+	rs := internal.DefaultReceiveSettings
+	if t.ReceiveSettings != nil {
+		rs = *t.ReceiveSettings
+	}
+	t.ReceiveSettings = &rs
+	return t.ReceiveSettings
+}
+
+// publishSettings returns a copy of the PublishSettings to update, so that
+// the settings given to WithPublishSettings are left untouched.
+func (t *Protocol) publishSettings() *pubsub.PublishSettings {
+	ps := pubsub.DefaultPublishSettings
+	if t.PublishSettings != nil {
+		ps = *t.PublishSettings
+	}
+	t.PublishSettings = &ps
+	return t.PublishSettings
+}
+
 // Send implements Sender.Send
 func (t *Protocol) Send(ctx context.Context, in binding.Message, transformers ...binding.Transformer) error {
 	var err error
 	defer func() { _ = in.Finish(err) }()
 
+	conn, msg, err := t.outboundMessage(ctx, in, transformers...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Publish(ctx, msg); err != nil {
+		return publishResult(err)
+	}
+	return nil
+}
+
+// SendAsync implements AsyncSender.SendAsync. fn is invoked once the Pubsub
+// server confirmed the publication, or failed to.
+func (t *Protocol) SendAsync(ctx context.Context, in binding.Message, fn protocol.ResultFn, transformers ...binding.Transformer) error {
+	conn, msg, err := t.outboundMessage(ctx, in, transformers...)
+	if err != nil {
+		_ = in.Finish(err)
+		return err
+	}
+
+	r, err := conn.PublishAsync(ctx, msg)
+	if err != nil {
+		_ = in.Finish(err)
+		return err
+	}
+	go func() {
+		_, err := r.Get(context.Background())
+		_ = in.Finish(err)
+		fn(publishResult(err))
+	}()
+	return nil
+}
+
+// outboundMessage returns the connection and the Pubsub message to send in.
+func (t *Protocol) outboundMessage(ctx context.Context, in binding.Message, transformers ...binding.Transformer) (*internal.Connection, *pubsub.Message, error) {
 	topic := cecontext.TopicFrom(ctx)
 	if topic == "" {
 		topic = t.topicID
@@ -116,19 +186,15 @@ func (t *Protocol) Send(ctx context.Context, in binding.Message, transformers ..
 
 	if key, ok := ctx.Value(withOrderingKey{}).(string); ok {
 		if !t.MessageOrdering {
-			return fmt.Errorf("ordering key cannot be used when message ordering is disabled")
+			return nil, nil, fmt.Errorf("ordering key cannot be used when message ordering is disabled")
 		}
 		msg.OrderingKey = key
 	}
 
 	if err := WritePubSubMessage(ctx, in, msg, transformers...); err != nil {
-		return err
-	}
-
-	if _, err := conn.Publish(ctx, msg); err != nil {
-		return err
+		return nil, nil, err
 	}
-	return nil
+	return conn, msg, nil
 }
 
 func (t *Protocol) getConnection(ctx context.Context, topic, subscription string) *internal.Connection {
@@ -159,6 +225,7 @@ func (t *Protocol) getOrCreateConnection(ctx context.Context, topic, subscriptio
 		AllowCreateSubscription: t.AllowCreateSubscription,
 		AllowCreateTopic:        t.AllowCreateTopic,
 		ReceiveSettings:         t.ReceiveSettings,
+		PublishSettings:         t.PublishSettings,
 		Client:                  t.client,
 		ProjectID:               t.projectID,
 		MessageOrdering:         t.MessageOrdering,
@@ -203,6 +270,16 @@ func (t *Protocol) startSubscriber(ctx context.Context, sub subscriptionWithTopi
 	}
 	// Ok, ready to start pulling.
 	return conn.Receive(ctx, func(ctx context.Context, m *pubsub.Message) {
+		t.pauseMux.Lock()
+		resumed := t.resumed
+		t.pauseMux.Unlock()
+		if resumed != nil {
+			select {
+			case <-resumed:
+			case <-ctx.Done():
+				return
+			}
+		}
 		select {
 		case t.incoming <- *m:
 		case <-ctx.Done():
@@ -210,6 +287,31 @@ func (t *Protocol) startSubscriber(ctx context.Context, sub subscriptionWithTopi
 	})
 }
 
+// Pause implements protocol.Pauser.Pause: the messages pulled are held until
+// Resume is invoked. As they are outstanding, the subscriber stops pulling
+// once the limits set with WithMaxOutstandingMessages and
+// WithMaxOutstandingBytes are reached, extending the ack deadline of the
+// messages held meanwhile.
+func (t *Protocol) Pause(ctx context.Context) error {
+	t.pauseMux.Lock()
+	defer t.pauseMux.Unlock()
+	if t.resumed == nil {
+		t.resumed = make(chan struct{})
+	}
+	return nil
+}
+
+// Resume implements protocol.Pauser.Resume.
+func (t *Protocol) Resume(ctx context.Context) error {
+	t.pauseMux.Lock()
+	defer t.pauseMux.Unlock()
+	if t.resumed != nil {
+		close(t.resumed)
+		t.resumed = nil
+	}
+	return nil
+}
+
 func (t *Protocol) OpenInbound(ctx context.Context) error {
 	eg, ctx := errgroup.WithContext(ctx)
 	// Start up each subscription.
@@ -223,16 +325,38 @@ func (t *Protocol) OpenInbound(ctx context.Context) error {
 	return eg.Wait()
 }
 
+// Ping implements protocol.Pinger, it checks that the topic the events are
+// sent to and the subscriptions they are received from exist, or that the
+// protocol is allowed to create them, see AllowCreateTopic and
+// AllowCreateSubscription.
+func (t *Protocol) Ping(ctx context.Context) error {
+	var errs []error
+	if t.topicID != "" {
+		if err := t.getOrCreateConnection(ctx, t.topicID, "", "").Ping(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, sub := range t.subscriptions {
+		if err := t.getOrCreateConnection(ctx, sub.topicID, sub.subscriptionID, sub.filter).Ping(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Close implements Closer.Close
 func (t *Protocol) Close(ctx context.Context) error {
 	// TODO: Implement this.
 	return nil
 }
 
-// pubsub protocol implements Sender, Receiver, Closer, Opener
+// pubsub protocol implements Sender, Receiver, BatchReceiver, Pauser, Pinger, Closer, Opener
 var _ protocol.Opener = (*Protocol)(nil)
 var _ protocol.Sender = (*Protocol)(nil)
+var _ protocol.AsyncSender = (*Protocol)(nil)
 var _ protocol.Receiver = (*Protocol)(nil)
+var _ protocol.Pauser = (*Protocol)(nil)
+var _ protocol.Pinger = (*Protocol)(nil)
 var _ protocol.Closer = (*Protocol)(nil)
 
 type withOrderingKey struct{}
@@ -3,8 +3,10 @@ package pubsub
 import (
 	"context"
 	"fmt"
+	"io"
 	"reflect"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/pubsub/v2"
 	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
@@ -14,6 +16,8 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/test"
 )
 
@@ -141,3 +145,121 @@ func TestPublishMessageHasOrderingKey(t *testing.T) {
 	err = prot.Send(WithOrderingKey(ctx, orderingKey), test.FullMessage())
 	require.NoError(err)
 }
+
+// newSubscriber returns a protocol subscribed to a topic of a fake Pub/Sub
+// server, and the name of the topic.
+func newSubscriber(t *testing.T, ctx context.Context, opts ...Option) (*Protocol, *pstest.Server, string) {
+	srv := pstest.NewServer()
+	t.Cleanup(func() { srv.Close() })
+	conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client, err := pubsub.NewClient(ctx, "test-project", option.WithGRPCConn(conn))
+	require.NoError(t, err, "create pubsub client")
+	t.Cleanup(func() { client.Close() })
+
+	topic, sub := "projects/test-project/topics/test-topic", "projects/test-project/subscriptions/test-sub"
+	_, err = srv.GServer.CreateTopic(ctx, &pubsubpb.Topic{Name: topic})
+	require.NoError(t, err)
+	_, err = srv.GServer.CreateSubscription(ctx, &pubsubpb.Subscription{Name: sub, Topic: topic})
+	require.NoError(t, err)
+
+	prot, err := New(ctx, append([]Option{
+		WithClient(client),
+		WithProjectID("test-project"),
+		WithSubscriptionAndTopicID("test-sub", "test-topic"),
+	}, opts...)...)
+	require.NoError(t, err, "create protocol")
+	return prot, srv, topic
+}
+
+func TestPauseResume(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prot, srv, topic := newSubscriber(t, ctx)
+	require.NoError(prot.Pause(ctx))
+	go func() { _ = prot.OpenInbound(ctx) }()
+	id := srv.Publish(topic, []byte("data"), nil)
+
+	// The message pulled is held while paused.
+	receiveCtx, receiveCancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer receiveCancel()
+	_, err := prot.Receive(receiveCtx)
+	require.Equal(io.EOF, err)
+
+	require.NoError(prot.Resume(ctx))
+	receiveCtx, receiveCancel = context.WithTimeout(ctx, 5*time.Second)
+	defer receiveCancel()
+	m, err := prot.Receive(receiveCtx)
+	require.NoError(err)
+	require.Equal(id, m.(*Message).internal.ID)
+	require.NoError(m.Finish(nil))
+}
+
+func TestReceiveBatch(t *testing.T) {
+	require := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prot, srv, topic := newSubscriber(t, ctx, WithReceiveBatch(2, time.Second))
+	go func() { _ = prot.OpenInbound(ctx) }()
+	ids := map[string]bool{}
+	for _, data := range []string{"a", "b", "c"} {
+		ids[srv.Publish(topic, []byte(data), nil)] = true
+	}
+
+	received := func(batch []binding.Message) {
+		for _, m := range batch {
+			id := m.(*Message).internal.ID
+			require.True(ids[id], "unexpected message %s", id)
+			delete(ids, id)
+			require.NoError(m.Finish(nil))
+		}
+	}
+
+	// The batch is returned once full.
+	batch, err := prot.ReceiveBatch(ctx)
+	require.NoError(err)
+	require.Len(batch, 2)
+	received(batch)
+
+	// Otherwise once the delay expired.
+	clock := cecontext.NewFakeClock(time.Now())
+	batches := make(chan []binding.Message)
+	go func() {
+		batch, err := prot.ReceiveBatch(cecontext.WithClock(ctx, clock))
+		require.NoError(err)
+		batches <- batch
+	}()
+	require.Eventually(func() bool { return clock.Timers() == 1 }, 5*time.Second, time.Millisecond)
+	clock.Advance(time.Second)
+	batch = <-batches
+	require.Len(batch, 1)
+	received(batch)
+}
+
+func TestWithReceiveBatchInvalid(t *testing.T) {
+	p := &Protocol{}
+	require.EqualError(t, p.applyOptions(WithReceiveBatch(0, 0)), "batch size must be positive")
+	require.EqualError(t, p.applyOptions(WithReceiveBatch(1, -time.Second)), "batch delay must not be negative")
+}
+
+func TestPing(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	prot, srv, _ := newSubscriber(t, ctx)
+	require.NoError(prot.Ping(ctx))
+
+	// The subscription is deleted, and the protocol is not allowed to
+	// create it again.
+	_, err := srv.GServer.DeleteSubscription(ctx, &pubsubpb.DeleteSubscriptionRequest{Subscription: "projects/test-project/subscriptions/test-sub"})
+	require.NoError(err)
+	require.ErrorContains(prot.Ping(ctx), `subscription "test-sub" does not exist`)
+
+	// The topic events are sent to is missing, unless it can be created.
+	prot, _, _ = newSubscriber(t, ctx, WithTopicID("missing-topic"))
+	require.ErrorContains(prot.Ping(ctx), `topic "missing-topic" does not exist`)
+	prot, _, _ = newSubscriber(t, ctx, WithTopicID("missing-topic"))
+	prot.AllowCreateTopic = true
+	require.NoError(prot.Ping(ctx))
+}
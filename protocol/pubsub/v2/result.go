@@ -0,0 +1,37 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pubsub
+
+import (
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retriableCodes are the gRPC codes of the Pub/Sub errors retried by the
+// Pub/Sub publishers.
+var retriableCodes = map[codes.Code]bool{
+	codes.Aborted:           true,
+	codes.Canceled:          true,
+	codes.Internal:          true,
+	codes.ResourceExhausted: true,
+	codes.Unknown:           true,
+	codes.Unavailable:       true,
+	codes.DeadlineExceeded:  true,
+}
+
+// publishResult returns the protocol.BrokerResult of the error of a publish,
+// with the gRPC code as status code if err has a gRPC status.
+func publishResult(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return protocol.NewBrokerResult(err, false, 0)
+	}
+	return protocol.NewBrokerResult(err, retriableCodes[st.Code()], int(st.Code()))
+}
@@ -0,0 +1,41 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPublishResult(t *testing.T) {
+	if err := publishResult(nil); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	tests := []struct {
+		err       error
+		retriable bool
+		code      int
+	}{
+		{err: status.Error(codes.Unavailable, "unavailable"), retriable: true, code: int(codes.Unavailable)},
+		{err: fmt.Errorf("publish: %w", status.Error(codes.ResourceExhausted, "quota")), retriable: true, code: int(codes.ResourceExhausted)},
+		{err: status.Error(codes.PermissionDenied, "denied"), retriable: false, code: int(codes.PermissionDenied)},
+		{err: errors.New("message too large"), retriable: false},
+	}
+	for _, tc := range tests {
+		result := publishResult(tc.err)
+		if got := protocol.IsRetriable(result); got != tc.retriable {
+			t.Errorf("IsRetriable(%v) = %v, want %v", tc.err, got, tc.retriable)
+		}
+		if code, _ := protocol.StatusCode(result); code != tc.code {
+			t.Errorf("StatusCode(%v) = %d, want %d", tc.err, code, tc.code)
+		}
+	}
+}
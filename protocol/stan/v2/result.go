@@ -0,0 +1,23 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package stan
+
+import (
+	"errors"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+
+	"github.com/nats-io/stan.go"
+)
+
+// publishResult returns the protocol.BrokerResult of the error of a publish,
+// the publish ack timeouts being retriable.
+func publishResult(err error) error {
+	if err == nil {
+		return nil
+	}
+	return protocol.NewBrokerResult(err, errors.Is(err, stan.ErrTimeout), 0)
+}
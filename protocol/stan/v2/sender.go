@@ -73,7 +73,7 @@ func (s *Sender) Send(ctx context.Context, in binding.Message, transformers ...b
 	if err = WriteMsg(ctx, in, writer, transformers...); err != nil {
 		return err
 	}
-	return s.Conn.Publish(s.Subject, writer.Bytes())
+	return publishResult(s.Conn.Publish(s.Subject, writer.Bytes()))
 }
 
 // Close implements Closer.Close
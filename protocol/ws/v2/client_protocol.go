@@ -70,9 +70,26 @@ func NewClientProtocol(c *websocket.Conn) (*ClientProtocol, error) {
 func (c *ClientProtocol) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
 	writer, err := c.conn.Writer(ctx, c.messageType)
 	if err != nil {
+		return sendResult(err)
+	}
+	if err := utils.WriteStructured(ctx, m, writer, transformers...); err != nil {
+		if websocket.CloseStatus(err) != -1 {
+			return sendResult(err)
+		}
 		return err
 	}
-	return utils.WriteStructured(ctx, m, writer, transformers...)
+	return nil
+}
+
+// sendResult returns the protocol.BrokerResult of the error of a write, with
+// the close status code as status code if the connection was closed. As the
+// ClientProtocol does not reconnect, the errors are not retriable.
+func sendResult(err error) error {
+	code := 0
+	if status := websocket.CloseStatus(err); status != -1 {
+		code = int(status)
+	}
+	return protocol.NewBrokerResult(err, false, code)
 }
 
 func (c *ClientProtocol) Receive(ctx context.Context) (binding.Message, error) {
@@ -19,6 +19,7 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 	. "github.com/cloudevents/sdk-go/v2/test"
 )
 
@@ -79,6 +80,27 @@ func TestClientProtocolPingPongWithClient(t *testing.T) {
 	AssertEvent(t, pong, HasId("2"), HasType("pong"))
 }
 
+func TestClientProtocolSendClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		c, err := websocket.Accept(writer, request, &websocket.AcceptOptions{Subprotocols: SupportedSubprotocols})
+		require.NoError(t, err)
+		_ = c.Close(websocket.StatusGoingAway, "shutting down")
+	}))
+	defer server.Close()
+
+	p, err := Dial(context.TODO(), server.URL, nil)
+	require.NoError(t, err)
+	_, err = p.Receive(context.TODO())
+	require.Error(t, err)
+
+	ping := pingEvent()
+	result := p.Send(context.TODO(), binding.ToMessage(&ping))
+	require.Error(t, result)
+	require.False(t, protocol.IsRetriable(result))
+	_, ok := protocol.BrokerError(result)
+	require.True(t, ok)
+}
+
 func pingPongHandler(t *testing.T) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		c, err := websocket.Accept(writer, request, &websocket.AcceptOptions{Subprotocols: SupportedSubprotocols})
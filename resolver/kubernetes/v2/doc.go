@@ -0,0 +1,9 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package kubernetes implements an http.Resolver resolving the endpoints of
+// a Kubernetes Service from its EndpointSlices, so that the HTTP sender load
+// balances the deliveries over the ready pods of the Service.
+package kubernetes
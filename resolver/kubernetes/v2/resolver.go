@@ -0,0 +1,368 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// watchBackoff is the delay before watching again after the watch
+	// failed.
+	watchBackoff = time.Second
+
+	// tokenRefreshInterval is the delay after which the token file is read
+	// again, as kubelet rotates the projected service account tokens.
+	tokenRefreshInterval = time.Minute
+)
+
+// Resolver resolves the endpoints from the EndpointSlices of a
+// Kubernetes Service, so that the deliveries are load balanced over its ready
+// pods directly rather than through the Service and kube-proxy. It reads the
+// EndpointSlices with the Kubernetes API, which requires the permission to
+// list and watch the endpointslices of the discovery.k8s.io group.
+//
+// Resolve lists the EndpointSlices, unless Watch is running: it then returns
+// the endpoints kept up to date by Watch, and a short resolver interval can
+// be given to http.WithResolver.
+type Resolver struct {
+	// Namespace and Service name the Service.
+	Namespace string
+	Service   string
+	// Port is the name of the port of the Service the events are sent to,
+	// its first port if empty.
+	Port string
+	// Scheme of the endpoint URLs, "http" by default.
+	Scheme string
+	// Path of the endpoint URLs.
+	Path string
+
+	// APIServer is the URL of the Kubernetes API server.
+	APIServer string
+	// Token is the bearer token authenticating the requests to the API
+	// server, if any.
+	Token string
+	// TokenFile is the file the bearer token is read from, taking
+	// precedence over Token. It is read again every minute, so that a
+	// rotated token is used.
+	TokenFile string
+	// Client sends the requests to the API server, http.DefaultClient by
+	// default.
+	Client *http.Client
+
+	mu       sync.Mutex
+	watching bool
+	// slices are the endpoints of each EndpointSlice, kept by Watch.
+	slices map[string][]cehttp.Endpoint
+	// token is the content of TokenFile, read at tokenRead.
+	token     string
+	tokenRead time.Time
+}
+
+// NewInClusterResolver returns a Resolver for the Service, accessing the API
+// server with the service account of the pod it runs in. If namespace is
+// empty, the namespace of the pod is used.
+func NewInClusterResolver(namespace, service string) (*Resolver, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+	if _, err := os.Stat(serviceAccountDir + "/token"); err != nil {
+		return nil, fmt.Errorf("failed to read the service account token: %w", err)
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificate found in the service account CA")
+	}
+	if namespace == "" {
+		ns, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the namespace of the pod: %w", err)
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+	return &Resolver{
+		Namespace: namespace,
+		Service:   service,
+		APIServer: "https://" + net.JoinHostPort(host, port),
+		TokenFile: serviceAccountDir + "/token",
+		Client: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}},
+	}, nil
+}
+
+// endpointSliceList is the subset of a discovery.k8s.io/v1 EndpointSliceList
+// read by the resolver.
+type endpointSliceList struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Items []endpointSlice `json:"items"`
+}
+
+type endpointSlice struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Ports []struct {
+		Name string `json:"name"`
+		Port *int   `json:"port"`
+	} `json:"ports"`
+	Endpoints []struct {
+		Addresses  []string `json:"addresses"`
+		Conditions struct {
+			Ready *bool `json:"ready"`
+		} `json:"conditions"`
+	} `json:"endpoints"`
+}
+
+type watchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// endpoints returns the ready endpoints of s.
+func (r *Resolver) endpoints(s endpointSlice) []cehttp.Endpoint {
+	port := -1
+	for _, p := range s.Ports {
+		if p.Port != nil && (r.Port == "" || p.Name == r.Port) {
+			port = *p.Port
+			break
+		}
+	}
+	if port < 0 {
+		return nil
+	}
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	var endpoints []cehttp.Endpoint
+	for _, e := range s.Endpoints {
+		// An unknown readiness is interpreted as ready.
+		if e.Conditions.Ready != nil && !*e.Conditions.Ready {
+			continue
+		}
+		for _, addr := range e.Addresses {
+			u := url.URL{Scheme: scheme, Host: net.JoinHostPort(addr, strconv.Itoa(port)), Path: r.Path}
+			endpoints = append(endpoints, cehttp.Endpoint{URL: u.String()})
+		}
+	}
+	return endpoints
+}
+
+func (r *Resolver) slicesURL(query url.Values) string {
+	query.Set("labelSelector", "kubernetes.io/service-name="+r.Service)
+	return fmt.Sprintf("%s/apis/discovery.k8s.io/v1/namespaces/%s/endpointslices?%s",
+		strings.TrimSuffix(r.APIServer, "/"), url.PathEscape(r.Namespace), query.Encode())
+}
+
+func (r *Resolver) get(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := r.bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to get the endpointslices of %s/%s: %s", r.Namespace, r.Service, resp.Status)
+	}
+	return resp, nil
+}
+
+// bearerToken returns the token authenticating the requests, reading
+// TokenFile again once tokenRefreshInterval has elapsed since it was read. If
+// it can not be read, the previous token is used until it can.
+func (r *Resolver) bearerToken(ctx context.Context) (string, error) {
+	if r.TokenFile == "" {
+		return r.Token, nil
+	}
+	now := cecontext.ClockFrom(ctx).Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.token != "" && now.Sub(r.tokenRead) < tokenRefreshInterval {
+		return r.token, nil
+	}
+	b, err := os.ReadFile(r.TokenFile)
+	if err != nil {
+		if r.token != "" {
+			cecontext.LoggerFrom(ctx).Warnw("failed to read the token again, using the previous token", "error", err)
+			return r.token, nil
+		}
+		return "", fmt.Errorf("failed to read the token: %w", err)
+	}
+	r.token, r.tokenRead = strings.TrimSpace(string(b)), now
+	return r.token, nil
+}
+
+// list returns the endpoints of each EndpointSlice of the Service, and the
+// resource version of the list.
+func (r *Resolver) list(ctx context.Context) (map[string][]cehttp.Endpoint, string, error) {
+	resp, err := r.get(ctx, r.slicesURL(url.Values{}))
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	var list endpointSliceList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, "", fmt.Errorf("failed to decode the endpointslices: %w", err)
+	}
+	slices := make(map[string][]cehttp.Endpoint, len(list.Items))
+	for _, s := range list.Items {
+		slices[s.Metadata.Name] = r.endpoints(s)
+	}
+	return slices, list.Metadata.ResourceVersion, nil
+}
+
+// Resolve implements http.Resolver.Resolve.
+func (r *Resolver) Resolve(ctx context.Context) ([]cehttp.Endpoint, error) {
+	r.mu.Lock()
+	if r.watching && r.slices != nil {
+		defer r.mu.Unlock()
+		return flattenSlices(r.slices), nil
+	}
+	r.mu.Unlock()
+
+	slices, _, err := r.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return flattenSlices(slices), nil
+}
+
+// flattenSlices returns the endpoints of slices, sorted by URL so that the
+// order does not change between resolutions.
+func flattenSlices(slices map[string][]cehttp.Endpoint) []cehttp.Endpoint {
+	var endpoints []cehttp.Endpoint
+	for _, eps := range slices {
+		endpoints = append(endpoints, eps...)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].URL < endpoints[j].URL })
+	return endpoints
+}
+
+// Watch keeps the endpoints returned by Resolve up to date by watching the
+// EndpointSlices of the Service, until ctx is done. The watch is restarted
+// when it fails.
+func (r *Resolver) Watch(ctx context.Context) error {
+	r.mu.Lock()
+	if r.watching {
+		r.mu.Unlock()
+		return errors.New("the kubernetes resolver is already watching")
+	}
+	r.watching = true
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.watching = false
+		r.slices = nil
+		r.mu.Unlock()
+	}()
+
+	logger := cecontext.LoggerFrom(ctx)
+	for {
+		err := r.watch(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			logger.Warnw("watching the endpointslices failed, watching again", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(watchBackoff):
+		}
+	}
+}
+
+// watch lists the EndpointSlices, then applies their changes until the
+// watch ends.
+func (r *Resolver) watch(ctx context.Context) error {
+	slices, version, err := r.list(ctx)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.slices = slices
+	r.mu.Unlock()
+
+	resp, err := r.get(ctx, r.slicesURL(url.Values{
+		"watch":               {"true"},
+		"resourceVersion":     {version},
+		"allowWatchBookmarks": {"true"},
+	}))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	d := json.NewDecoder(resp.Body)
+	for {
+		var ev watchEvent
+		if err := d.Decode(&ev); err != nil {
+			return err
+		}
+		switch ev.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+			var s endpointSlice
+			if err := json.Unmarshal(ev.Object, &s); err != nil {
+				return fmt.Errorf("failed to decode the endpointslice: %w", err)
+			}
+			r.mu.Lock()
+			if ev.Type == "DELETED" {
+				delete(r.slices, s.Metadata.Name)
+			} else {
+				r.slices[s.Metadata.Name] = r.endpoints(s)
+			}
+			r.mu.Unlock()
+		case "ERROR":
+			// e.g. 410 Gone once the resource version is too old: the
+			// EndpointSlices are listed again.
+			return fmt.Errorf("watch error: %s", ev.Object)
+		}
+	}
+}
+
+var _ cehttp.Resolver = (*Resolver)(nil)
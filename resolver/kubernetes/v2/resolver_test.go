@@ -0,0 +1,167 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func testSlice(name string, ready map[string]bool) string {
+	endpoints := ""
+	for addr, r := range ready {
+		if endpoints != "" {
+			endpoints += ","
+		}
+		endpoints += fmt.Sprintf(`{"addresses":[%q],"conditions":{"ready":%t}}`, addr, r)
+	}
+	return fmt.Sprintf(`{"metadata":{"name":%q},"ports":[{"name":"metrics","port":9090},{"name":"events","port":8080}],"endpoints":[%s]}`, name, endpoints)
+}
+
+// fakeAPIServer serves the EndpointSlices of the "orders" Service of the
+// "shop" namespace: the list holds list, and the watch streams the events
+// sent on watch. The requests must be authenticated with the "token" bearer
+// token.
+func fakeAPIServer(t *testing.T, list string, watch <-chan string) *httptest.Server {
+	return fakeAPIServerWithTokens(t, list, watch, func(token string) {
+		require.Equal(t, "token", token)
+	})
+}
+
+// fakeAPIServerWithTokens is fakeAPIServer calling checkToken with the
+// bearer token of each request.
+func fakeAPIServerWithTokens(t *testing.T, list string, watch <-chan string, checkToken func(string)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "/apis/discovery.k8s.io/v1/namespaces/shop/endpointslices", req.URL.Path)
+		require.Equal(t, "kubernetes.io/service-name=orders", req.URL.Query().Get("labelSelector"))
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		require.True(t, ok)
+		checkToken(token)
+		if req.URL.Query().Get("watch") != "true" {
+			fmt.Fprintf(rw, `{"metadata":{"resourceVersion":"42"},"items":[%s]}`, list)
+			return
+		}
+		require.Equal(t, "42", req.URL.Query().Get("resourceVersion"))
+		rw.(http.Flusher).Flush()
+		for {
+			select {
+			case <-req.Context().Done():
+				return
+			case ev := <-watch:
+				fmt.Fprintln(rw, ev)
+				rw.(http.Flusher).Flush()
+			}
+		}
+	}))
+}
+
+func TestResolver(t *testing.T) {
+	watch := make(chan string)
+	srv := fakeAPIServer(t, testSlice("orders-a", map[string]bool{"10.0.0.1": true, "10.0.0.2": false})+","+testSlice("orders-b", map[string]bool{"fd00::1": true}), watch)
+	defer srv.Close()
+
+	r := &Resolver{Namespace: "shop", Service: "orders", Port: "events", Path: "/events", APIServer: srv.URL, Token: "token"}
+	endpoints, err := r.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []cehttp.Endpoint{{URL: "http://10.0.0.1:8080/events"}, {URL: "http://[fd00::1]:8080/events"}}, endpoints)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watched := make(chan error)
+	go func() {
+		watched <- r.Watch(ctx)
+	}()
+
+	resolved := func() []string {
+		endpoints, err := r.Resolve(context.Background())
+		require.NoError(t, err)
+		var urls []string
+		for _, e := range endpoints {
+			urls = append(urls, e.URL)
+		}
+		return urls
+	}
+
+	watch <- fmt.Sprintf(`{"type":"MODIFIED","object":%s}`, testSlice("orders-a", map[string]bool{"10.0.0.1": true, "10.0.0.2": true}))
+	require.Eventually(t, func() bool {
+		return len(resolved()) == 3
+	}, time.Second, time.Millisecond)
+
+	watch <- fmt.Sprintf(`{"type":"DELETED","object":%s}`, testSlice("orders-b", nil))
+	watch <- `{"type":"BOOKMARK","object":{"metadata":{"resourceVersion":"43"}}}`
+	require.Eventually(t, func() bool {
+		return len(resolved()) == 2
+	}, time.Second, time.Millisecond)
+	require.Equal(t, []string{"http://10.0.0.1:8080/events", "http://10.0.0.2:8080/events"}, resolved())
+
+	cancel()
+	require.ErrorIs(t, <-watched, context.Canceled)
+}
+
+func TestResolverFirstPort(t *testing.T) {
+	srv := fakeAPIServer(t, testSlice("orders-a", map[string]bool{"10.0.0.1": true}), nil)
+	defer srv.Close()
+	r := &Resolver{Namespace: "shop", Service: "orders", Scheme: "https", APIServer: srv.URL, Token: "token"}
+	endpoints, err := r.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []cehttp.Endpoint{{URL: "https://10.0.0.1:9090"}}, endpoints)
+}
+
+func TestResolverTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("token\n"), 0o600))
+	var mu sync.Mutex
+	var tokens []string
+	srv := fakeAPIServerWithTokens(t, testSlice("orders-a", map[string]bool{"10.0.0.1": true}), nil, func(token string) {
+		mu.Lock()
+		defer mu.Unlock()
+		tokens = append(tokens, token)
+	})
+	defer srv.Close()
+
+	clock := cecontext.NewFakeClock(time.Unix(1700000000, 0))
+	ctx := cecontext.WithClock(context.Background(), clock)
+	r := &Resolver{Namespace: "shop", Service: "orders", APIServer: srv.URL, Token: "ignored", TokenFile: path}
+	resolve := func() {
+		_, err := r.Resolve(ctx)
+		require.NoError(t, err)
+	}
+
+	resolve()
+	require.NoError(t, os.WriteFile(path, []byte("rotated\n"), 0o600))
+	resolve()
+	// The rotated token is read once the refresh interval has elapsed.
+	clock.Advance(tokenRefreshInterval)
+	resolve()
+
+	// The previous token is used while the file can not be read.
+	require.NoError(t, os.Remove(path))
+	clock.Advance(tokenRefreshInterval)
+	resolve()
+	require.Equal(t, []string{"token", "token", "rotated", "rotated"}, tokens)
+
+	r = &Resolver{Namespace: "shop", Service: "orders", APIServer: srv.URL, TokenFile: path}
+	_, err := r.Resolve(ctx)
+	require.ErrorContains(t, err, "failed to read the token")
+}
+
+func TestNewInClusterResolver(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	_, err := NewInClusterResolver("shop", "orders")
+	require.EqualError(t, err, "not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+}
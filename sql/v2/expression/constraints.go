@@ -0,0 +1,45 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package expression
+
+import (
+	cesql "github.com/cloudevents/sdk-go/sql/v2"
+)
+
+// EqualityConstraints returns the attributes which must be equal to a string
+// literal for expr to evaluate to true, such as type in
+// "type = 'com.example' AND subject LIKE 'a%'". Only the equalities with the
+// attribute on the left side, combined with AND, are returned.
+func EqualityConstraints(expr cesql.Expression) map[string]string {
+	constraints := map[string]string{}
+	collectEqualityConstraints(expr, constraints)
+	return constraints
+}
+
+func collectEqualityConstraints(expr cesql.Expression, constraints map[string]string) {
+	switch e := expr.(type) {
+	case logicExpression:
+		if e.verb == "AND" {
+			collectEqualityConstraints(e.left, constraints)
+			collectEqualityConstraints(e.right, constraints)
+		}
+	case equalExpression:
+		if !e.equal {
+			return
+		}
+		identifier, ok := e.left.(identifierExpression)
+		if !ok {
+			return
+		}
+		literal, ok := e.right.(literalExpression)
+		if !ok {
+			return
+		}
+		if value, ok := literal.value.(string); ok {
+			constraints[identifier.identifier] = value
+		}
+	}
+}
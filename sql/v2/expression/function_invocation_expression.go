@@ -18,10 +18,16 @@ import (
 type functionInvocationExpression struct {
 	name                string
 	argumentsExpression []cesql.Expression
+	// fn is the function resolved when the expression was created, if any.
+	fn    cesql.Function
+	table runtime.FunctionTable
 }
 
 func (expr functionInvocationExpression) Evaluate(event cloudevents.Event) (interface{}, error) {
-	fn := runtime.ResolveFunction(expr.name, len(expr.argumentsExpression))
+	fn := expr.fn
+	if fn == nil {
+		fn = expr.table.ResolveFunction(expr.name, len(expr.argumentsExpression))
+	}
 	if fn == nil {
 		return false, sqlerrors.NewMissingFunctionError(expr.name)
 	}
@@ -67,5 +73,19 @@ func NewFunctionInvocationExpression(name string, argumentsExpression []cesql.Ex
 	return functionInvocationExpression{
 		name:                name,
 		argumentsExpression: argumentsExpression,
+		table:               runtime.GlobalFunctionTable(),
+	}
+}
+
+// NewResolvedFunctionInvocationExpression returns an expression invoking the
+// function of table resolved now, so that evaluating the expression does not
+// look it up. If the function is missing, it is looked up again on each
+// evaluation, and the evaluation fails until it is added to the table.
+func NewResolvedFunctionInvocationExpression(table runtime.FunctionTable, name string, argumentsExpression []cesql.Expression) cesql.Expression {
+	return functionInvocationExpression{
+		name:                name,
+		argumentsExpression: argumentsExpression,
+		fn:                  table.ResolveFunction(name, len(argumentsExpression)),
+		table:               table,
 	}
 }
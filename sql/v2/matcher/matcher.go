@@ -0,0 +1,187 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package matcher evaluates many CloudEvents SQL expressions, such as the
+// filters of subscriptions, against each event.
+package matcher
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	cesql "github.com/cloudevents/sdk-go/sql/v2"
+	"github.com/cloudevents/sdk-go/sql/v2/expression"
+	"github.com/cloudevents/sdk-go/sql/v2/utils"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// indexPreference is the order in which the attributes constrained by an
+// expression are preferred to index it.
+var indexPreference = []string{"type", "source", "subject"}
+
+// Matcher holds expressions by id, and returns the ids of the expressions an
+// event matches. The expressions requiring an attribute to be equal to a
+// string literal, e.g. "type = 'com.example.created' AND ...", are indexed
+// by the value of the attribute, so that only the expressions which can match
+// an event are evaluated. A Matcher is safe for concurrent use.
+type Matcher struct {
+	mu          sync.RWMutex
+	expressions map[string]entry
+	// index holds the ids of the indexed expressions by attribute and value.
+	index map[string]map[string]map[string]cesql.Expression
+	// scan holds the expressions which are not indexed.
+	scan map[string]cesql.Expression
+}
+
+type entry struct {
+	expr      cesql.Expression
+	attribute string
+	value     string
+}
+
+// New returns an empty Matcher.
+func New() *Matcher {
+	return &Matcher{
+		expressions: map[string]entry{},
+		index:       map[string]map[string]map[string]cesql.Expression{},
+		scan:        map[string]cesql.Expression{},
+	}
+}
+
+// Add adds expr with the given id, replacing the expression with the same id
+// if any. expr should be parsed once, e.g. with parser.Parse, and is then
+// evaluated for each event given to Match.
+func (m *Matcher) Add(id string, expr cesql.Expression) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(id)
+
+	constraints := expression.EqualityConstraints(expr)
+	attribute := indexAttribute(constraints)
+	e := entry{expr: expr, attribute: attribute, value: constraints[attribute]}
+	m.expressions[id] = e
+	if e.attribute == "" {
+		m.scan[id] = expr
+		return
+	}
+	values := m.index[e.attribute]
+	if values == nil {
+		values = map[string]map[string]cesql.Expression{}
+		m.index[e.attribute] = values
+	}
+	ids := values[e.value]
+	if ids == nil {
+		ids = map[string]cesql.Expression{}
+		values[e.value] = ids
+	}
+	ids[id] = expr
+}
+
+// indexAttribute returns the attribute of constraints the expression is
+// indexed by, empty if none.
+func indexAttribute(constraints map[string]string) string {
+	for _, a := range indexPreference {
+		if _, ok := constraints[a]; ok {
+			return a
+		}
+	}
+	attributes := make([]string, 0, len(constraints))
+	for a := range constraints {
+		attributes = append(attributes, a)
+	}
+	sort.Strings(attributes)
+	if len(attributes) == 0 {
+		return ""
+	}
+	return attributes[0]
+}
+
+// Remove removes the expression with the given id, if any.
+func (m *Matcher) Remove(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(id)
+}
+
+func (m *Matcher) remove(id string) {
+	e, ok := m.expressions[id]
+	if !ok {
+		return
+	}
+	delete(m.expressions, id)
+	if e.attribute == "" {
+		delete(m.scan, id)
+		return
+	}
+	values := m.index[e.attribute]
+	delete(values[e.value], id)
+	if len(values[e.value]) == 0 {
+		delete(values, e.value)
+	}
+	if len(values) == 0 {
+		delete(m.index, e.attribute)
+	}
+}
+
+// Len returns the number of expressions.
+func (m *Matcher) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.expressions)
+}
+
+// Match returns the sorted ids of the expressions evaluating to true for
+// event. The expressions failing to evaluate, e.g. because they read a
+// missing attribute, do not match.
+func (m *Matcher) Match(event cloudevents.Event) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []string
+	evaluate := func(exprs map[string]cesql.Expression) {
+		for id, expr := range exprs {
+			if matches(expr, event) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	for attribute, values := range m.index {
+		v := utils.GetAttribute(event, attribute)
+		if v == nil {
+			continue
+		}
+		s, err := utils.Cast(v, cesql.StringType)
+		if err != nil {
+			continue
+		}
+		evaluate(values[s.(string)])
+	}
+	evaluate(m.scan)
+	sort.Strings(ids)
+	return ids
+}
+
+// Class returns the first of the sorted ids of the expressions evaluating to
+// true for event, or an empty string if none does. Its method value classifies
+// the events by the expressions they match, e.g. as the
+// middleware.ConcurrencyClassifier of the client middleware package, the ids
+// being the classes ordered by precedence.
+func (m *Matcher) Class(_ context.Context, event cloudevents.Event) string {
+	ids := m.Match(event)
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+func matches(expr cesql.Expression, event cloudevents.Event) bool {
+	v, err := expr.Evaluate(event)
+	if err != nil {
+		return false
+	}
+	b, ok := v.(bool)
+	return ok && b
+}
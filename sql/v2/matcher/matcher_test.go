@@ -0,0 +1,98 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package matcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/sql/v2/parser"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func newEvent(eventType, subject string) cloudevents.Event {
+	e := cloudevents.NewEvent()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType(eventType)
+	if subject != "" {
+		e.SetSubject(subject)
+	}
+	e.SetExtension("priority", 3)
+	return e
+}
+
+func TestMatcher(t *testing.T) {
+	m := New()
+	for id, sql := range map[string]string{
+		"created":          "type = 'com.example.created'",
+		"created subject":  "type = 'com.example.created' AND subject LIKE 'a%'",
+		"deleted":          "type = 'com.example.deleted' OR type = 'com.example.created'",
+		"priority":         "priority = '3'",
+		"high priority":    "priority > 5",
+		"source":           "source = '/source' AND (subject = 'b' OR subject = 'c')",
+		"missing subject":  "subject = 'a'",
+		"not boolean":      "'true'",
+		"other type first": "'com.example.created' = type",
+	} {
+		expr, err := parser.Parse(sql)
+		require.NoError(t, err)
+		m.Add(id, expr)
+	}
+	require.Equal(t, 9, m.Len())
+
+	require.Equal(t, []string{"created", "created subject", "deleted", "other type first", "priority"},
+		m.Match(newEvent("com.example.created", "abc")))
+	require.Equal(t, []string{"deleted", "priority", "source"},
+		m.Match(newEvent("com.example.deleted", "b")))
+	require.Equal(t, []string{"created", "deleted", "other type first", "priority"},
+		m.Match(newEvent("com.example.created", "")))
+
+	m.Remove("created")
+	m.Remove("unknown")
+	expr, err := parser.Parse("type = 'com.example.other'")
+	require.NoError(t, err)
+	m.Add("priority", expr)
+	require.Equal(t, 8, m.Len())
+	require.Equal(t, []string{"created subject", "deleted", "other type first"},
+		m.Match(newEvent("com.example.created", "abc")))
+	require.Equal(t, []string{"priority"}, m.Match(newEvent("com.example.other", "")))
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	m := New()
+	for i := 0; i < 10000; i++ {
+		expr, err := parser.Parse(fmt.Sprintf("type = 'com.example.%d' AND subject LIKE 'a%%'", i))
+		require.NoError(b, err)
+		m.Add(fmt.Sprint(i), expr)
+	}
+	e := newEvent("com.example.42", "abc")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if len(m.Match(e)) != 1 {
+			b.Fatal("expected a single match")
+		}
+	}
+}
+
+func TestMatcherClass(t *testing.T) {
+	m := New()
+	for id, sql := range map[string]string{
+		"1 reports": "type LIKE '%.report.%'",
+		"2 created": "type = 'com.example.created' OR type = 'com.example.report.created'",
+	} {
+		expr, err := parser.Parse(sql)
+		require.NoError(t, err)
+		m.Add(id, expr)
+	}
+	ctx := context.Background()
+	require.Equal(t, "1 reports", m.Class(ctx, newEvent("com.example.report.created", "")))
+	require.Equal(t, "2 created", m.Class(ctx, newEvent("com.example.created", "")))
+	require.Equal(t, "", m.Class(ctx, newEvent("com.example.deleted", "")))
+}
@@ -15,17 +15,19 @@ import (
 	cesql "github.com/cloudevents/sdk-go/sql/v2"
 	"github.com/cloudevents/sdk-go/sql/v2/expression"
 	"github.com/cloudevents/sdk-go/sql/v2/gen"
+	"github.com/cloudevents/sdk-go/sql/v2/runtime"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 )
 
 type expressionVisitor struct {
 	parsingErrors []error
+	functions     runtime.FunctionTable
 }
 
 var _ gen.CESQLParserVisitor = (*expressionVisitor)(nil)
 
 func NewExpressionVisitor() gen.CESQLParserVisitor {
-	return &expressionVisitor{}
+	return &expressionVisitor{functions: runtime.GlobalFunctionTable()}
 }
 
 // antlr.ParseTreeVisitor implementation
@@ -208,7 +210,7 @@ func (v *expressionVisitor) VisitFunctionInvocationExpression(ctx *gen.FunctionI
 		args = append(args, v.Visit(expr).(cesql.Expression))
 	}
 
-	return expression.NewFunctionInvocationExpression(strings.ToUpper(name), args)
+	return expression.NewResolvedFunctionInvocationExpression(v.functions, strings.ToUpper(name), args)
 }
 
 func (v *expressionVisitor) VisitBinaryMultiplicativeExpression(ctx *gen.BinaryMultiplicativeExpressionContext) interface{} {
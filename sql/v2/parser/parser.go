@@ -13,12 +13,37 @@ import (
 	v2 "github.com/cloudevents/sdk-go/sql/v2"
 	sqlerrors "github.com/cloudevents/sdk-go/sql/v2/errors"
 	"github.com/cloudevents/sdk-go/sql/v2/gen"
+	"github.com/cloudevents/sdk-go/sql/v2/runtime"
 )
 
 type Parser struct {
-	// TODO parser options
+	functions runtime.FunctionTable
 }
 
+// Option configures a Parser.
+type Option func(*Parser)
+
+// WithFunctionTable makes the expressions invoke the functions of table,
+// rather than those of the global table extended by runtime.AddFunction.
+func WithFunctionTable(table runtime.FunctionTable) Option {
+	return func(p *Parser) {
+		p.functions = table
+	}
+}
+
+// NewParser returns a Parser configured with opts.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Parse parses input into an expression. The functions invoked by the
+// expression are resolved once, when parsing, so that the expression can be
+// evaluated repeatedly without looking them up.
+
 func (p *Parser) Parse(input string) (v2.Expression, error) {
 	var is antlr.CharStream = antlr.NewInputStream(input)
 	is = NewCaseChangingStream(is, true)
@@ -34,7 +59,11 @@ func (p *Parser) Parse(input string) (v2.Expression, error) {
 	antlrParser.AddErrorListener(&collectingErrorListener)
 
 	// Finally walk the tree
-	visitor := expressionVisitor{}
+	functions := p.functions
+	if functions == nil {
+		functions = runtime.GlobalFunctionTable()
+	}
+	visitor := expressionVisitor{functions: functions}
 	result := antlrParser.Cesql().Accept(&visitor)
 
 	if result == nil {
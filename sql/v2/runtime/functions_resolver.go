@@ -7,6 +7,7 @@ package runtime
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	cesql "github.com/cloudevents/sdk-go/sql/v2"
@@ -18,9 +19,26 @@ type functionItem struct {
 	variadicFunction   cesql.Function
 }
 
-type functionTable map[string]*functionItem
+// FunctionTable holds the functions expressions can invoke, by name and
+// arity. The table used by default is global and can be extended with
+// AddFunction, while a FunctionTable given to parser.WithFunctionTable is only
+// visible to the expressions parsed with it.
+type FunctionTable map[string]*functionItem
+
+// NewFunctionTable returns a FunctionTable holding the built-in functions
+// along with the given user defined functions.
+func NewFunctionTable(fns ...cesql.Function) (FunctionTable, error) {
+	table := FunctionTable{}
+	for _, fn := range append(builtinFunctions(), fns...) {
+		if err := table.AddFunction(fn); err != nil {
+			return nil, fmt.Errorf("cannot add the function %s: %w", fn.Name(), err)
+		}
+	}
+	return table, nil
+}
 
-func (table functionTable) AddFunction(function cesql.Function) error {
+// AddFunction adds a user defined function to the table.
+func (table FunctionTable) AddFunction(function cesql.Function) error {
 	item := table[function.Name()]
 	if item == nil {
 		item = &functionItem{
@@ -63,7 +81,9 @@ func AddFunction(fn cesql.Function) error {
 	return globalFunctionTable.AddFunction(fn)
 }
 
-func (table functionTable) ResolveFunction(name string, args int) cesql.Function {
+// ResolveFunction returns the function invoked with the given name and number
+// of arguments, or nil if none.
+func (table FunctionTable) ResolveFunction(name string, args int) cesql.Function {
 	item := table[strings.ToUpper(name)]
 	if item == nil {
 		return nil
@@ -80,10 +100,10 @@ func (table functionTable) ResolveFunction(name string, args int) cesql.Function
 	return item.variadicFunction
 }
 
-var globalFunctionTable = functionTable{}
+var globalFunctionTable = FunctionTable{}
 
-func init() {
-	for _, fn := range []cesql.Function{
+func builtinFunctions() []cesql.Function {
+	return []cesql.Function{
 		function.IntFunction,
 		function.BoolFunction,
 		function.StringFunction,
@@ -100,13 +120,22 @@ func init() {
 		function.RightFunction,
 		function.SubstringFunction,
 		function.SubstringWithLengthFunction,
-	} {
+	}
+}
+
+func init() {
+	for _, fn := range builtinFunctions() {
 		if err := globalFunctionTable.AddFunction(fn); err != nil {
 			panic(err)
 		}
 	}
 }
 
+// GlobalFunctionTable returns the table extended by AddFunction.
+func GlobalFunctionTable() FunctionTable {
+	return globalFunctionTable
+}
+
 func ResolveFunction(name string, args int) cesql.Function {
 	return globalFunctionTable.ResolveFunction(name, args)
 }
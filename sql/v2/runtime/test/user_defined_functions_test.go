@@ -209,3 +209,50 @@ func TestUserFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestFunctionTable(t *testing.T) {
+	double := function.NewFunction(
+		"DOUBLE",
+		[]cesql.Type{cesql.IntegerType},
+		nil,
+		cesql.IntegerType,
+		func(event cloudevents.Event, i []interface{}) (interface{}, error) {
+			return i[0].(int32) * 2, nil
+		},
+	)
+	table, err := ceruntime.NewFunctionTable(double)
+	require.NoError(t, err)
+	_, err = ceruntime.NewFunctionTable(double, double)
+	require.Error(t, err)
+
+	p := parser.NewParser(parser.WithFunctionTable(table))
+	expr, err := p.Parse("DOUBLE(ABS(-2))")
+	require.NoError(t, err)
+	result, err := expr.Evaluate(test.MinEvent())
+	require.NoError(t, err)
+	require.Equal(t, int32(4), result)
+
+	// The functions of the table are not visible to the default parser.
+	expr, err = parser.Parse("DOUBLE(2)")
+	require.NoError(t, err)
+	_, err = expr.Evaluate(test.MinEvent())
+	require.Error(t, err)
+
+	// Functions missing when parsing are resolved once added.
+	expr, err = p.Parse("TRIPLE(2)")
+	require.NoError(t, err)
+	_, err = expr.Evaluate(test.MinEvent())
+	require.Error(t, err)
+	require.NoError(t, table.AddFunction(function.NewFunction(
+		"TRIPLE",
+		[]cesql.Type{cesql.IntegerType},
+		nil,
+		cesql.IntegerType,
+		func(event cloudevents.Event, i []interface{}) (interface{}, error) {
+			return i[0].(int32) * 3, nil
+		},
+	)))
+	result, err = expr.Evaluate(test.MinEvent())
+	require.NoError(t, err)
+	require.Equal(t, int32(6), result)
+}
@@ -0,0 +1,103 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/cloudevents/sdk-go/observability/opentelemetry/v2/metrics"
+	"github.com/cloudevents/sdk-go/v2/observability"
+)
+
+// collect returns the metrics recorded by the meter provider of reader,
+// by name.
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Metrics {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	got := map[string]metricdata.Metrics{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			got[m.Name] = m
+		}
+	}
+	return got
+}
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	m := metrics.New(provider.Meter("test"))
+
+	http := observability.Attribute{Key: "protocol", Value: "http"}
+	kafka := observability.Attribute{Key: "protocol", Value: "kafka"}
+	m.AddCounter("events.received", 2, http)
+	m.AddCounter("events.received", 1, http)
+	m.AddCounter("events.received", 5, kafka)
+	m.SetGauge("events.inflight", 4, http)
+	m.SetGauge("events.inflight", 3, http)
+	m.RecordDuration("events.duration", 1500*time.Millisecond, http)
+	m.RecordDuration("events.duration", 500*time.Millisecond, http)
+
+	got := collect(t, reader)
+	require.Len(t, got, 3)
+	metricdatatest.AssertEqual(t, metricdata.Metrics{
+		Name: "events.received",
+		Data: metricdata.Sum[int64]{
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+			DataPoints: []metricdata.DataPoint[int64]{
+				{Attributes: attribute.NewSet(attribute.String("protocol", "http")), Value: 3},
+				{Attributes: attribute.NewSet(attribute.String("protocol", "kafka")), Value: 5},
+			},
+		},
+	}, got["events.received"], metricdatatest.IgnoreTimestamp())
+	metricdatatest.AssertEqual(t, metricdata.Metrics{
+		Name: "events.inflight",
+		Data: metricdata.Gauge[int64]{
+			DataPoints: []metricdata.DataPoint[int64]{
+				{Attributes: attribute.NewSet(attribute.String("protocol", "http")), Value: 3},
+			},
+		},
+	}, got["events.inflight"], metricdatatest.IgnoreTimestamp())
+
+	// The durations are recorded in seconds.
+	duration := got["events.duration"]
+	require.Equal(t, "s", duration.Unit)
+	histogram, ok := duration.Data.(metricdata.Histogram[float64])
+	require.True(t, ok, "data: %T", duration.Data)
+	require.Len(t, histogram.DataPoints, 1)
+	point := histogram.DataPoints[0]
+	require.Equal(t, attribute.NewSet(attribute.String("protocol", "http")), point.Attributes)
+	require.Equal(t, uint64(2), point.Count)
+	require.Equal(t, 2.0, point.Sum)
+	require.Equal(t, metricdata.NewExtrema(0.5), point.Min)
+	require.Equal(t, metricdata.NewExtrema(1.5), point.Max)
+}
+
+func TestMetricsGlobalMeterProvider(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(previous)
+
+	// Without meter, the instruments are created by the global MeterProvider.
+	metrics.New(nil).AddCounter("events.sent", 1)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Equal(t, "github.com/cloudevents/sdk-go/observability/opentelemetry/v2", rm.ScopeMetrics[0].Scope.Name)
+	require.Contains(t, collect(t, reader), "events.sent")
+}
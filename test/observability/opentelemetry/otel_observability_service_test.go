@@ -16,11 +16,15 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	otelObs "github.com/cloudevents/sdk-go/observability/opentelemetry/v2/client"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	event "github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/observability"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
 )
@@ -420,3 +424,48 @@ func getSpanEventMap(evtAttrs []attribute.KeyValue) map[string]string {
 	}
 	return attr
 }
+
+func TestTraceLogger(t *testing.T) {
+	configureOtelTestSdk()
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := cecontext.WithLogger(context.Background(), zap.New(core).Sugar())
+
+	// Without span, the logger is unchanged.
+	cecontext.LoggerFrom(otelObs.ContextWithTraceLogger(ctx)).Info("no span")
+
+	os := otelObs.NewOTelObservabilityService()
+	sendCtx, cb := os.RecordSendingEvent(ctx, expectedEvent)
+	cecontext.LoggerFrom(sendCtx).Info("sending")
+	cb(nil)
+
+	entries := logs.All()
+	assert.Equal(t, 2, len(entries))
+	assert.Empty(t, entries[0].ContextMap())
+
+	sc := trace.SpanContextFromContext(sendCtx)
+	assert.Equal(t, map[string]interface{}{
+		observability.TraceIDLogField: sc.TraceID().String(),
+		observability.SpanIDLogField:  sc.SpanID().String(),
+	}, entries[1].ContextMap())
+}
+
+func TestAttributeProjection(t *testing.T) {
+	sr, _ := configureOtelTestSdk()
+	e := createCloudEvent(extensions.DistributedTracingExtension{})
+	e.SetExtension("tenant", "acme")
+
+	os := otelObs.NewOTelObservabilityService(otelObs.WithAttributeProjection(
+		observability.NewProjection([]string{"type", "tenant"}, observability.WithHashedAttribute("tenant", 16))))
+	_, cb := os.RecordSendingEvent(context.Background(), e)
+	cb(nil)
+
+	spans := sr.Ended()
+	assert.Equal(t, 1, len(spans))
+	attrs := getSpanEventMap(spans[0].Attributes())
+	assert.Equal(t, "RecordSendingEvent", attrs[string(semconv.CodeFunctionKey)])
+	assert.Equal(t, "example.type", attrs[observability.TypeAttr])
+	assert.NotContains(t, attrs, observability.IdAttr)
+	assert.NotContains(t, attrs, observability.SourceAttr)
+	assert.NotEqual(t, "acme", attrs["cloudevents.tenant"])
+	assert.Len(t, attrs, 3)
+}
@@ -0,0 +1,38 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package asyncapi describes the events a service consumes and produces as an
+AsyncAPI 3.0 document, so that API catalogs stay in sync with the code
+declaring them:
+
+	doc, err := asyncapi.Generate(asyncapi.Service{
+		Title:    "orders",
+		Version:  "1.2.0",
+		Protocol: "kafka",
+		Consumes: asyncapi.ConsumedEventTypes(validator),
+		Produces: []asyncapi.EventType{{
+			Type:            "com.example.order.shipped",
+			DataContentType: event.ApplicationJSON,
+			DataSchema:      "https://example.com/schemas/shipped.json",
+			Channel:         "shipments",
+		}},
+	})
+	b, err := yaml.Marshal(doc)
+
+Each event type is a message whose headers hold the context attributes, as
+named by the binary content mode of the protocol, and whose payload is the
+schema of the data.
+
+Conversely, Parse and Load configure a service from a document, spec first:
+the protocol of each operation, built by the ProtocolFactory of the protocol
+of its server, and a middleware.ContentValidator rejecting the events which
+do not match the messages of the receive operations:
+
+	doc, err := asyncapi.Parse(spec)
+	cfg, err := asyncapi.Load(ctx, doc, asyncapi.WithProtocolFactory("kafka", newKafkaProtocol))
+	c, err := client.New(cfg.Protocols["receiveOrders"], client.WithMiddleware(cfg.Validator.Middleware()))
+*/
+package asyncapi
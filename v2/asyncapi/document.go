@@ -0,0 +1,100 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package asyncapi
+
+// Version is the version of the AsyncAPI specification of the documents.
+const Version = "3.0.0"
+
+// Document is the subset of an AsyncAPI 3.0 document describing the events
+// of a service. It marshals to JSON and, with gopkg.in/yaml.v3, to YAML.
+type Document struct {
+	AsyncAPI   string                `json:"asyncapi" yaml:"asyncapi"`
+	Info       Info                  `json:"info" yaml:"info"`
+	Servers    map[string]*Server    `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Channels   map[string]*Channel   `json:"channels,omitempty" yaml:"channels,omitempty"`
+	Operations map[string]*Operation `json:"operations,omitempty" yaml:"operations,omitempty"`
+	Components *Components           `json:"components,omitempty" yaml:"components,omitempty"`
+
+	// DefaultContentType is the content type of the messages without one.
+	DefaultContentType string `json:"defaultContentType,omitempty" yaml:"defaultContentType,omitempty"`
+}
+
+// Info describes the service.
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Server is a broker or an endpoint the service connects to or listens on.
+type Server struct {
+	Host        string `json:"host" yaml:"host"`
+	Protocol    string `json:"protocol" yaml:"protocol"`
+	Pathname    string `json:"pathname,omitempty" yaml:"pathname,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Bindings holds the protocol specific configuration, by protocol.
+	Bindings map[string]Schema `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+}
+
+// Channel is an address the events are sent to, e.g. a topic.
+type Channel struct {
+	// Address is the address of the channel, empty if it is unknown or
+	// dynamic.
+	Address     string              `json:"address,omitempty" yaml:"address,omitempty"`
+	Description string              `json:"description,omitempty" yaml:"description,omitempty"`
+	Messages    map[string]*Message `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Servers     []Reference         `json:"servers,omitempty" yaml:"servers,omitempty"`
+	// Bindings holds the protocol specific configuration, by protocol.
+	Bindings map[string]Schema `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+}
+
+// Action is what a service does on the channel of an Operation.
+type Action string
+
+const (
+	// ActionSend is an operation producing events.
+	ActionSend Action = "send"
+	// ActionReceive is an operation consuming events.
+	ActionReceive Action = "receive"
+)
+
+// Operation is the sending or the receiving of messages on a channel.
+type Operation struct {
+	Action   Action      `json:"action" yaml:"action"`
+	Channel  Reference   `json:"channel" yaml:"channel"`
+	Messages []Reference `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Summary  string      `json:"summary,omitempty" yaml:"summary,omitempty"`
+}
+
+// Components holds the messages referenced by the channels.
+type Components struct {
+	Messages map[string]*Message `json:"messages,omitempty" yaml:"messages,omitempty"`
+	Schemas  map[string]Schema   `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+}
+
+// Message describes the events of a type, or references such a Message
+// when Ref is set.
+type Message struct {
+	Ref         string `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Title       string `json:"title,omitempty" yaml:"title,omitempty"`
+	Summary     string `json:"summary,omitempty" yaml:"summary,omitempty"`
+	ContentType string `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+	// Headers is the JSON Schema of the CloudEvents attributes in protocol
+	// headers, as the binary content mode of the protocol names them.
+	Headers Schema `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Payload is the JSON Schema of the data.
+	Payload Schema `json:"payload,omitempty" yaml:"payload,omitempty"`
+}
+
+// Reference points to an object of the document, e.g.
+// "#/channels/orders".
+type Reference struct {
+	Ref string `json:"$ref" yaml:"$ref"`
+}
+
+// Schema is a JSON Schema, or another object without a dedicated type.
+type Schema map[string]interface{}
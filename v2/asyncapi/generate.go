@@ -0,0 +1,199 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package asyncapi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// EventType declares the events of a type a service consumes or produces.
+type EventType struct {
+	// Type is the type attribute of the events.
+	Type string
+	// DataContentType is the datacontenttype attribute of the events.
+	DataContentType string
+	// DataSchema is the dataschema attribute of the events. The payload of
+	// the message references it when Schema is nil.
+	DataSchema string
+	// Schema is the JSON Schema of the data.
+	Schema Schema
+	// Summary describes the events.
+	Summary string
+	// Channel is the address the events are sent to, e.g. a topic. Empty is
+	// the endpoint of the service.
+	Channel string
+}
+
+// Service declares the events of a service.
+type Service struct {
+	Title       string
+	Version     string
+	Description string
+	// Protocol is the protocol carrying the events, e.g. "http" or
+	// "kafka", which names the headers of the attributes.
+	Protocol string
+	// Servers are the servers of the document.
+	Servers map[string]*Server
+	// Consumes are the events the service receives.
+	Consumes []EventType
+	// Produces are the events the service sends.
+	Produces []EventType
+}
+
+// headerPrefix returns the prefix of the attribute headers in the binary
+// content mode of protocol.
+func headerPrefix(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "http", "https", "ws", "wss", "nats":
+		return "ce-"
+	case "amqp", "amqps":
+		return "cloudEvents:"
+	case "mqtt", "mqtt5", "secure-mqtt":
+		return ""
+	}
+	return "ce_"
+}
+
+// componentKey returns s with the characters which can not appear in the
+// key of a component replaced with underscores.
+func componentKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// Generate returns the AsyncAPI document of s, with a channel per address,
+// a receive and a send operation per channel, and a message per event type.
+func Generate(s Service) (*Document, error) {
+	if s.Title == "" || s.Version == "" {
+		return nil, errors.New("asyncapi service requires a title and a version")
+	}
+	doc := &Document{
+		AsyncAPI:   Version,
+		Info:       Info{Title: s.Title, Version: s.Version, Description: s.Description},
+		Servers:    s.Servers,
+		Channels:   map[string]*Channel{},
+		Operations: map[string]*Operation{},
+		Components: &Components{Messages: map[string]*Message{}},
+	}
+
+	channelKeys := map[string]string{}
+	channelKey := func(address string) string {
+		if key, ok := channelKeys[address]; ok {
+			return key
+		}
+		key := componentKey(strings.Trim(address, "/"))
+		if key == "" {
+			key = "default"
+		}
+		for base, i := key, 2; doc.Channels[key] != nil; i++ {
+			key = fmt.Sprintf("%s_%d", base, i)
+		}
+		channelKeys[address] = key
+		doc.Channels[key] = &Channel{Address: address, Messages: map[string]*Message{}}
+		return key
+	}
+
+	prefix := headerPrefix(s.Protocol)
+	declare := func(action Action, types []EventType) error {
+		for _, t := range types {
+			if t.Type == "" {
+				return errors.New("asyncapi event type has no type")
+			}
+			msgKey := componentKey(t.Type)
+			msg := message(prefix, t)
+			if other, ok := doc.Components.Messages[msgKey]; ok && !reflect.DeepEqual(other, msg) {
+				return fmt.Errorf("asyncapi event type %q is declared twice with different content", t.Type)
+			}
+			doc.Components.Messages[msgKey] = msg
+
+			chKey := channelKey(t.Channel)
+			doc.Channels[chKey].Messages[msgKey] = &Message{Ref: "#/components/messages/" + msgKey}
+			opKey := string(action) + "_" + chKey
+			op := doc.Operations[opKey]
+			if op == nil {
+				op = &Operation{Action: action, Channel: Reference{Ref: "#/channels/" + chKey}}
+				doc.Operations[opKey] = op
+			}
+			ref := Reference{Ref: "#/channels/" + chKey + "/messages/" + msgKey}
+			if !containsReference(op.Messages, ref) {
+				op.Messages = append(op.Messages, ref)
+			}
+		}
+		return nil
+	}
+	if err := declare(ActionReceive, s.Consumes); err != nil {
+		return nil, err
+	}
+	if err := declare(ActionSend, s.Produces); err != nil {
+		return nil, err
+	}
+	for _, op := range doc.Operations {
+		sort.Slice(op.Messages, func(i, j int) bool { return op.Messages[i].Ref < op.Messages[j].Ref })
+	}
+	return doc, nil
+}
+
+func containsReference(refs []Reference, ref Reference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// message returns the message describing the events of t, with the
+// attribute headers named with prefix.
+func message(prefix string, t EventType) *Message {
+	properties := Schema{
+		prefix + "specversion": Schema{"type": "string", "const": event.CloudEventsVersionV1},
+		prefix + "id":          Schema{"type": "string"},
+		prefix + "source":      Schema{"type": "string", "format": "uri-reference"},
+		prefix + "type":        Schema{"type": "string", "const": t.Type},
+	}
+	if t.DataSchema != "" {
+		properties[prefix+"dataschema"] = Schema{"type": "string", "const": t.DataSchema}
+	}
+	msg := &Message{
+		Name:        t.Type,
+		Summary:     t.Summary,
+		ContentType: t.DataContentType,
+		Headers: Schema{
+			"type":       "object",
+			"properties": properties,
+			"required":   []interface{}{prefix + "specversion", prefix + "id", prefix + "source", prefix + "type"},
+		},
+		Payload: t.Schema,
+	}
+	if msg.Payload == nil && t.DataSchema != "" {
+		msg.Payload = Schema{"$ref": t.DataSchema}
+	}
+	return msg
+}
+
+// ConsumedEventTypes returns the event types registered in v, sorted by
+// type, to be declared as consumed by a service validating its events with
+// v.
+func ConsumedEventTypes(v *middleware.ContentValidator) []EventType {
+	expectations := v.Expectations()
+	types := make([]EventType, 0, len(expectations))
+	for t, e := range expectations {
+		types = append(types, EventType{Type: t, DataContentType: e.DataContentType, DataSchema: e.DataSchema})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	return types
+}
@@ -0,0 +1,175 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package asyncapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+)
+
+func TestGenerate(t *testing.T) {
+	v := middleware.NewContentValidator()
+	v.Register("com.example.order.created", middleware.ContentExpectation{
+		DataContentType: "application/json",
+		DataSchema:      "https://example.com/schemas/created.json",
+	})
+
+	doc, err := Generate(Service{
+		Title:    "orders",
+		Version:  "1.2.0",
+		Protocol: "kafka",
+		Servers:  map[string]*Server{"production": {Host: "kafka:9092", Protocol: "kafka"}},
+		Consumes: ConsumedEventTypes(v),
+		Produces: []EventType{{
+			Type:            "com.example.order.shipped",
+			DataContentType: "application/json",
+			Schema:          Schema{"type": "object", "required": []interface{}{"id"}},
+			Summary:         "An order left the warehouse.",
+			Channel:         "shipments",
+		}, {
+			Type:    "com.example.order/audit",
+			Channel: "shipments",
+		}},
+	})
+	require.NoError(t, err)
+
+	b, err := json.Marshal(doc)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"asyncapi": "3.0.0",
+		"info": {"title": "orders", "version": "1.2.0"},
+		"servers": {"production": {"host": "kafka:9092", "protocol": "kafka"}},
+		"channels": {
+			"default": {
+				"messages": {"com.example.order.created": {"$ref": "#/components/messages/com.example.order.created"}}
+			},
+			"shipments": {
+				"address": "shipments",
+				"messages": {
+					"com.example.order.shipped": {"$ref": "#/components/messages/com.example.order.shipped"},
+					"com.example.order_audit": {"$ref": "#/components/messages/com.example.order_audit"}
+				}
+			}
+		},
+		"operations": {
+			"receive_default": {
+				"action": "receive",
+				"channel": {"$ref": "#/channels/default"},
+				"messages": [{"$ref": "#/channels/default/messages/com.example.order.created"}]
+			},
+			"send_shipments": {
+				"action": "send",
+				"channel": {"$ref": "#/channels/shipments"},
+				"messages": [
+					{"$ref": "#/channels/shipments/messages/com.example.order.shipped"},
+					{"$ref": "#/channels/shipments/messages/com.example.order_audit"}
+				]
+			}
+		},
+		"components": {
+			"messages": {
+				"com.example.order.created": {
+					"name": "com.example.order.created",
+					"contentType": "application/json",
+					"headers": {
+						"type": "object",
+						"properties": {
+							"ce_specversion": {"type": "string", "const": "1.0"},
+							"ce_id": {"type": "string"},
+							"ce_source": {"type": "string", "format": "uri-reference"},
+							"ce_type": {"type": "string", "const": "com.example.order.created"},
+							"ce_dataschema": {"type": "string", "const": "https://example.com/schemas/created.json"}
+						},
+						"required": ["ce_specversion", "ce_id", "ce_source", "ce_type"]
+					},
+					"payload": {"$ref": "https://example.com/schemas/created.json"}
+				},
+				"com.example.order.shipped": {
+					"name": "com.example.order.shipped",
+					"summary": "An order left the warehouse.",
+					"contentType": "application/json",
+					"headers": {
+						"type": "object",
+						"properties": {
+							"ce_specversion": {"type": "string", "const": "1.0"},
+							"ce_id": {"type": "string"},
+							"ce_source": {"type": "string", "format": "uri-reference"},
+							"ce_type": {"type": "string", "const": "com.example.order.shipped"}
+						},
+						"required": ["ce_specversion", "ce_id", "ce_source", "ce_type"]
+					},
+					"payload": {"type": "object", "required": ["id"]}
+				},
+				"com.example.order_audit": {
+					"name": "com.example.order/audit",
+					"headers": {
+						"type": "object",
+						"properties": {
+							"ce_specversion": {"type": "string", "const": "1.0"},
+							"ce_id": {"type": "string"},
+							"ce_source": {"type": "string", "format": "uri-reference"},
+							"ce_type": {"type": "string", "const": "com.example.order/audit"}
+						},
+						"required": ["ce_specversion", "ce_id", "ce_source", "ce_type"]
+					}
+				}
+			}
+		}
+	}`, string(b))
+
+	y, err := yaml.Marshal(doc)
+	require.NoError(t, err)
+	var fromYAML, fromJSON interface{}
+	require.NoError(t, yaml.Unmarshal(y, &fromYAML))
+	require.NoError(t, json.Unmarshal(b, &fromJSON))
+	require.Equal(t, fromJSON, fromYAML)
+}
+
+func TestGenerateHeaders(t *testing.T) {
+	for protocol, header := range map[string]string{
+		"http":  "ce-type",
+		"nats":  "ce-type",
+		"kafka": "ce_type",
+		"amqp":  "cloudEvents:type",
+		"mqtt":  "type",
+	} {
+		doc, err := Generate(Service{Title: "t", Version: "1", Protocol: protocol, Consumes: []EventType{{Type: "a"}}})
+		require.NoError(t, err)
+		require.Contains(t, doc.Components.Messages["a"].Headers["properties"], header, protocol)
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	_, err := Generate(Service{Title: "t"})
+	require.Error(t, err)
+
+	_, err = Generate(Service{Title: "t", Version: "1", Consumes: []EventType{{}}})
+	require.Error(t, err)
+
+	_, err = Generate(Service{
+		Title:    "t",
+		Version:  "1",
+		Consumes: []EventType{{Type: "a", DataContentType: "application/json"}},
+		Produces: []EventType{{Type: "a", DataContentType: "application/xml"}},
+	})
+	require.ErrorContains(t, err, "declared twice")
+
+	doc, err := Generate(Service{
+		Title:    "t",
+		Version:  "1",
+		Consumes: []EventType{{Type: "a", Channel: "orders/eu"}, {Type: "a", Channel: "orders_eu"}},
+		Produces: []EventType{{Type: "a", Channel: "orders/eu"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "orders/eu", doc.Channels["orders_eu"].Address)
+	require.Equal(t, "orders_eu", doc.Channels["orders_eu_2"].Address)
+	require.Len(t, doc.Operations, 3)
+}
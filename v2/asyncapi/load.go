@@ -0,0 +1,298 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package asyncapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// Parse parses an AsyncAPI 3.0 document in JSON or YAML.
+func Parse(doc []byte) (*Document, error) {
+	d := &Document{}
+	if err := yaml.NewDecoder(bytes.NewReader(doc)).Decode(d); err != nil {
+		return nil, fmt.Errorf("failed to parse the asyncapi document: %w", err)
+	}
+	if !strings.HasPrefix(d.AsyncAPI, "3.") {
+		return nil, fmt.Errorf("unsupported asyncapi version %q", d.AsyncAPI)
+	}
+	return d, nil
+}
+
+// ProtocolFactory returns the protocol of an operation on channel through
+// server, e.g. a sender for ActionSend, configured from the server, the
+// channel and their bindings.
+type ProtocolFactory func(ctx context.Context, server *Server, channel *Channel, action Action) (interface{}, error)
+
+// Config is what Load configures from a document.
+type Config struct {
+	// Protocols holds the protocol of each operation, by operation name, to
+	// be given to client.New.
+	Protocols map[string]interface{}
+	// Validator validates the events received by the operations with
+	// ActionReceive against their messages, rejecting the other types.
+	Validator *middleware.ContentValidator
+}
+
+type loader struct {
+	factories map[string]ProtocolFactory
+	server    string
+}
+
+// LoadOption configures Load.
+type LoadOption func(*loader) error
+
+// WithProtocolFactory sets the factory of the protocols of the servers with
+// the given protocol, e.g. "kafka". The factory of "http" and "https" is
+// HTTPProtocolFactory by default.
+func WithProtocolFactory(protocol string, f ProtocolFactory) LoadOption {
+	return func(l *loader) error {
+		if f == nil {
+			return fmt.Errorf("asyncapi protocol factory option was given a nil factory for %s", protocol)
+		}
+		l.factories[strings.ToLower(protocol)] = f
+		return nil
+	}
+}
+
+// WithServer selects the server of the operations whose channel is
+// available on several servers.
+func WithServer(name string) LoadOption {
+	return func(l *loader) error {
+		if name == "" {
+			return errors.New("asyncapi server option was given an empty name")
+		}
+		l.server = name
+		return nil
+	}
+}
+
+// Load configures the protocols of the operations of doc and the validation
+// of the events they receive.
+//
+// The type of the events of a message is the const of its type header, e.g.
+// "ce_type", or its name if none. Its content type and data schema are the
+// content type of the message, or the default content type of the document,
+// and the const of its dataschema header.
+func Load(ctx context.Context, doc *Document, opts ...LoadOption) (*Config, error) {
+	l := &loader{factories: map[string]ProtocolFactory{
+		"http":  HTTPProtocolFactory,
+		"https": HTTPProtocolFactory,
+	}}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &Config{
+		Protocols: map[string]interface{}{},
+		Validator: middleware.NewContentValidator(middleware.RejectUnregisteredTypes()),
+	}
+	names := make([]string, 0, len(doc.Operations))
+	for name := range doc.Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		op := doc.Operations[name]
+		if op.Action != ActionSend && op.Action != ActionReceive {
+			return nil, fmt.Errorf("operation %s has an unknown action %q", name, op.Action)
+		}
+		channel, err := doc.channel(op.Channel.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("operation %s: %w", name, err)
+		}
+		server, err := l.selectServer(doc, channel)
+		if err != nil {
+			return nil, fmt.Errorf("operation %s: %w", name, err)
+		}
+		factory, ok := l.factories[strings.ToLower(server.Protocol)]
+		if !ok {
+			return nil, fmt.Errorf("operation %s: no protocol factory for %q", name, server.Protocol)
+		}
+		p, err := factory(ctx, server, channel, op.Action)
+		if err != nil {
+			return nil, fmt.Errorf("operation %s: %w", name, err)
+		}
+		cfg.Protocols[name] = p
+
+		if op.Action != ActionReceive {
+			continue
+		}
+		messages := op.Messages
+		if len(messages) == 0 {
+			// The operation receives all the messages of its channel.
+			for key := range channel.Messages {
+				messages = append(messages, Reference{Ref: op.Channel.Ref + "/messages/" + key})
+			}
+		}
+		for _, ref := range messages {
+			msg, err := doc.message(ref.Ref)
+			if err != nil {
+				return nil, fmt.Errorf("operation %s: %w", name, err)
+			}
+			eventType, expectation := doc.expectation(msg)
+			if eventType == "" {
+				return nil, fmt.Errorf("operation %s: message %s has no event type", name, ref.Ref)
+			}
+			cfg.Validator.Register(eventType, expectation)
+		}
+	}
+	return cfg, nil
+}
+
+// selectServer returns the server of channel.
+func (l *loader) selectServer(doc *Document, channel *Channel) (*Server, error) {
+	var names []string
+	if len(channel.Servers) == 0 {
+		for name := range doc.Servers {
+			names = append(names, name)
+		}
+	}
+	for _, ref := range channel.Servers {
+		name, ok := strings.CutPrefix(ref.Ref, "#/servers/")
+		if !ok || doc.Servers[name] == nil {
+			return nil, fmt.Errorf("unresolved server reference %q", ref.Ref)
+		}
+		names = append(names, name)
+	}
+	switch {
+	case l.server != "":
+		for _, name := range names {
+			if name == l.server {
+				return doc.Servers[name], nil
+			}
+		}
+		return nil, fmt.Errorf("the channel is not available on server %s", l.server)
+	case len(names) == 1:
+		return doc.Servers[names[0]], nil
+	case len(names) == 0:
+		return nil, errors.New("no server")
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("the channel is available on several servers %v, select one with WithServer", names)
+}
+
+// decodeRef returns the tokens of a local reference.
+func decodeRef(ref string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, false
+	}
+	tokens := strings.Split(rest, "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, true
+}
+
+func (d *Document) channel(ref string) (*Channel, error) {
+	tokens, ok := decodeRef(ref)
+	if !ok || len(tokens) != 2 || tokens[0] != "channels" || d.Channels[tokens[1]] == nil {
+		return nil, fmt.Errorf("unresolved channel reference %q", ref)
+	}
+	return d.Channels[tokens[1]], nil
+}
+
+// message resolves the chain of references of a message.
+func (d *Document) message(ref string) (*Message, error) {
+	for hops := 0; hops < 8; hops++ {
+		tokens, ok := decodeRef(ref)
+		var msg *Message
+		switch {
+		case !ok:
+		case len(tokens) == 4 && tokens[0] == "channels" && tokens[2] == "messages":
+			if c := d.Channels[tokens[1]]; c != nil {
+				msg = c.Messages[tokens[3]]
+			}
+		case len(tokens) == 3 && tokens[0] == "components" && tokens[1] == "messages":
+			if d.Components != nil {
+				msg = d.Components.Messages[tokens[2]]
+			}
+		}
+		if msg == nil {
+			return nil, fmt.Errorf("unresolved message reference %q", ref)
+		}
+		if msg.Ref == "" {
+			return msg, nil
+		}
+		ref = msg.Ref
+	}
+	return nil, fmt.Errorf("too many message references from %q", ref)
+}
+
+// headerPrefixes are the prefixes of the attribute headers of the binary
+// content modes.
+var headerPrefixes = []string{"ce-", "ce_", "cloudEvents:", "cloudEvents_", ""}
+
+// expectation returns the event type and the content expectation of msg.
+func (d *Document) expectation(msg *Message) (string, middleware.ContentExpectation) {
+	properties, _ := msg.Headers["properties"].(map[string]interface{})
+	if properties == nil {
+		properties, _ = msg.Headers["properties"].(Schema)
+	}
+	header := func(name string) string {
+		for _, prefix := range headerPrefixes {
+			var p map[string]interface{}
+			switch v := properties[prefix+name].(type) {
+			case map[string]interface{}:
+				p = v
+			case Schema:
+				p = v
+			}
+			if s, ok := p["const"].(string); ok {
+				return s
+			}
+		}
+		return ""
+	}
+
+	eventType := header("type")
+	if eventType == "" {
+		eventType = msg.Name
+	}
+	expectation := middleware.ContentExpectation{
+		DataContentType: msg.ContentType,
+		DataSchema:      header("dataschema"),
+	}
+	if expectation.DataContentType == "" {
+		expectation.DataContentType = d.DefaultContentType
+	}
+	return eventType, expectation
+}
+
+// HTTPProtocolFactory returns a *cehttp.Protocol sending the events to the
+// URL of the channel, or receiving them on its path and on the port of the
+// server, if any.
+func HTTPProtocolFactory(_ context.Context, server *Server, channel *Channel, action Action) (interface{}, error) {
+	p := path.Join("/", server.Pathname, channel.Address)
+	if action == ActionSend {
+		scheme := strings.ToLower(server.Protocol)
+		return cehttp.New(cehttp.WithTarget(scheme + "://" + server.Host + p))
+	}
+	opts := []cehttp.Option{cehttp.WithPath(p)}
+	if _, port, err := net.SplitHostPort(server.Host); err == nil {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port in server host %q", server.Host)
+		}
+		opts = append(opts, cehttp.WithPort(n))
+	}
+	return cehttp.New(opts...)
+}
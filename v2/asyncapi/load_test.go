@@ -0,0 +1,185 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package asyncapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/client/middleware"
+	"github.com/cloudevents/sdk-go/v2/event"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+const testDocument = `
+asyncapi: 3.0.0
+info:
+  title: orders
+  version: 1.2.0
+defaultContentType: application/json
+servers:
+  public:
+    host: orders.example.com
+    protocol: https
+    pathname: /v1
+  local:
+    host: 0.0.0.0:8080
+    protocol: http
+  broker:
+    host: kafka:9092
+    protocol: kafka
+channels:
+  intake:
+    address: events
+    servers:
+      - $ref: '#/servers/local'
+    messages:
+      created:
+        $ref: '#/components/messages/created'
+      cancelled:
+        name: com.example.order.cancelled
+        contentType: application/xml
+  notifications:
+    address: notify
+    servers:
+      - $ref: '#/servers/public'
+    messages:
+      shipped:
+        $ref: '#/components/messages/shipped'
+  shipments:
+    address: shipments
+    servers:
+      - $ref: '#/servers/broker'
+    bindings:
+      kafka:
+        partitions: 12
+    messages:
+      shipped:
+        $ref: '#/components/messages/shipped'
+operations:
+  receiveOrders:
+    action: receive
+    channel:
+      $ref: '#/channels/intake'
+  notify:
+    action: send
+    channel:
+      $ref: '#/channels/notifications'
+  publishShipments:
+    action: send
+    channel:
+      $ref: '#/channels/shipments'
+components:
+  messages:
+    created:
+      name: created
+      headers:
+        type: object
+        properties:
+          ce-type:
+            type: string
+            const: com.example.order.created
+          ce-dataschema:
+            type: string
+            const: urn:order
+    shipped:
+      name: com.example.order.shipped
+`
+
+func TestLoad(t *testing.T) {
+	doc, err := Parse([]byte(testDocument))
+	require.NoError(t, err)
+
+	var kafkaChannel *Channel
+	cfg, err := Load(context.Background(), doc, WithProtocolFactory("kafka", func(_ context.Context, server *Server, channel *Channel, action Action) (interface{}, error) {
+		require.Equal(t, "kafka:9092", server.Host)
+		require.Equal(t, ActionSend, action)
+		kafkaChannel = channel
+		return "kafka sender", nil
+	}))
+	require.NoError(t, err)
+	require.Len(t, cfg.Protocols, 3)
+	require.Equal(t, "kafka sender", cfg.Protocols["publishShipments"])
+	require.Equal(t, 12, kafkaChannel.Bindings["kafka"]["partitions"])
+
+	receiver := cfg.Protocols["receiveOrders"].(*cehttp.Protocol)
+	require.Equal(t, 8080, receiver.Port)
+	require.Equal(t, "/events", receiver.Path)
+	sender := cfg.Protocols["notify"].(*cehttp.Protocol)
+	require.Equal(t, "https://orders.example.com/v1/notify", sender.Target.String())
+
+	require.Equal(t, map[string]middleware.ContentExpectation{
+		"com.example.order.created":   {DataContentType: "application/json", DataSchema: "urn:order"},
+		"com.example.order.cancelled": {DataContentType: "application/xml"},
+	}, cfg.Validator.Expectations())
+
+	e := event.New()
+	e.SetType("com.example.order.shipped")
+	require.Error(t, cfg.Validator.Validate(e))
+}
+
+func TestLoadGenerated(t *testing.T) {
+	generated, err := Generate(Service{
+		Title:    "orders",
+		Version:  "1",
+		Protocol: "http",
+		Servers:  map[string]*Server{"local": {Host: "localhost:8181", Protocol: "http"}},
+		Consumes: []EventType{{Type: "com.example.order.created", DataContentType: "application/json", DataSchema: "urn:order"}},
+	})
+	require.NoError(t, err)
+	b, err := json.Marshal(generated)
+	require.NoError(t, err)
+
+	doc, err := Parse(b)
+	require.NoError(t, err)
+	cfg, err := Load(context.Background(), doc)
+	require.NoError(t, err)
+	require.Equal(t, map[string]middleware.ContentExpectation{
+		"com.example.order.created": {DataContentType: "application/json", DataSchema: "urn:order"},
+	}, cfg.Validator.Expectations())
+	require.Equal(t, 8181, cfg.Protocols["receive_default"].(*cehttp.Protocol).Port)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := Parse([]byte("asyncapi: 2.6.0\n"))
+	require.ErrorContains(t, err, "unsupported asyncapi version")
+	_, err = Parse([]byte("asyncapi: [\n"))
+	require.Error(t, err)
+
+	doc, err := Parse([]byte(testDocument))
+	require.NoError(t, err)
+	_, err = Load(context.Background(), doc)
+	require.ErrorContains(t, err, `no protocol factory for "kafka"`)
+
+	failing := WithProtocolFactory("kafka", func(context.Context, *Server, *Channel, Action) (interface{}, error) {
+		return nil, errors.New("unreachable broker")
+	})
+	_, err = Load(context.Background(), doc, failing)
+	require.ErrorContains(t, err, "operation publishShipments: unreachable broker")
+
+	_, err = Load(context.Background(), doc, WithServer("public"), failing)
+	require.ErrorContains(t, err, "not available on server public")
+
+	nopKafka := WithProtocolFactory("kafka", func(context.Context, *Server, *Channel, Action) (interface{}, error) {
+		return nil, nil
+	})
+	delete(doc.Components.Messages, "created")
+	_, err = Load(context.Background(), doc, nopKafka)
+	require.ErrorContains(t, err, `unresolved message reference "#/components/messages/created"`)
+
+	doc.Channels["intake"].Servers = nil
+	_, err = Load(context.Background(), doc, nopKafka)
+	require.ErrorContains(t, err, "several servers")
+
+	_, err = Load(context.Background(), doc, WithServer(""))
+	require.Error(t, err)
+	_, err = Load(context.Background(), doc, WithProtocolFactory("kafka", nil))
+	require.Error(t, err)
+}
@@ -0,0 +1,218 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// Extension is the extension holding the name of the codec the data of an
+// event is compressed with.
+const Extension = "compression"
+
+// Names of the built-in codecs.
+const (
+	Gzip    = "gzip"
+	Deflate = "deflate"
+)
+
+// DefaultMaxDataSize is the maximum size of the decompressed data of an event
+// by DecompressEvent, so that a small compressed event can not exhaust the
+// memory of the receiver.
+const DefaultMaxDataSize = 32 << 20
+
+// ErrDataTooLarge is returned when the decompressed data of an event exceeds
+// the maximum size.
+var ErrDataTooLarge = errors.New("decompressed data exceeds the maximum size")
+
+// Codec compresses and decompresses the data of the events.
+type Codec interface {
+	// Name is the name of the codec, as advertised by the Extension.
+	Name() string
+	// Compress returns the compressed data.
+	Compress(data []byte) ([]byte, error)
+	// NewReader returns a reader of the data decompressed from r. It may
+	// read r lazily, and report invalid data as a read error.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// registered codecs
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{}
+)
+
+func init() {
+	Add(gzipCodec{})
+	Add(deflateCodec{})
+}
+
+// Add registers the codec c. It can be retrieved by Lookup(c.Name()).
+func Add(c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[c.Name()] = c
+}
+
+// remove unregisters the codec named name.
+func remove(name string) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	delete(codecs, name)
+}
+
+// Lookup returns the codec named name, or nil if not found.
+func Lookup(name string) Codec {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	return codecs[name]
+}
+
+// Names returns the names of the registered codecs, sorted.
+func Names() []string {
+	codecsMu.RLock()
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	codecsMu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// decompress returns the data decompressed with c, failing with
+// ErrDataTooLarge if it exceeds maxSize bytes.
+func decompress(c Codec, data []byte, maxSize int64) ([]byte, error) {
+	r, err := c.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	b, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > maxSize {
+		return nil, ErrDataTooLarge
+	}
+	return b, nil
+}
+
+// CompressEvent compresses the data of e with the codec named name, and sets
+// the Extension to name. The data content type is kept, as it describes the
+// decompressed data. Events without data or already compressed are left
+// untouched.
+func CompressEvent(e *event.Event, name string) error {
+	c := Lookup(name)
+	if c == nil {
+		return fmt.Errorf("unknown compression codec %q", name)
+	}
+	if len(e.DataEncoded) == 0 || e.Extensions()[Extension] != nil {
+		return nil
+	}
+	data, err := c.Compress(e.DataEncoded)
+	if err != nil {
+		return fmt.Errorf("failed to compress data with %s: %w", name, err)
+	}
+	e.DataEncoded = data
+	e.DataBase64 = true
+	e.SetExtension(Extension, name)
+	return nil
+}
+
+// DecompressEvent decompresses the data of e with the codec named by its
+// Extension, and removes the Extension. Events without the Extension are left
+// untouched. The decompressed data is limited to DefaultMaxDataSize bytes, see
+// DecompressEventLimit.
+func DecompressEvent(e *event.Event) error {
+	return DecompressEventLimit(e, DefaultMaxDataSize)
+}
+
+// DecompressEventLimit is DecompressEvent failing with ErrDataTooLarge if the
+// decompressed data exceeds maxSize bytes.
+func DecompressEventLimit(e *event.Event, maxSize int64) error {
+	v, ok := e.Extensions()[Extension]
+	if !ok {
+		return nil
+	}
+	name, err := types.ToString(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s extension: %w", Extension, err)
+	}
+	c := Lookup(name)
+	if c == nil {
+		return fmt.Errorf("unknown compression codec %q", name)
+	}
+	data, err := decompress(c, e.DataEncoded, maxSize)
+	if err != nil {
+		return fmt.Errorf("failed to decompress data with %s: %w", name, err)
+	}
+	e.DataEncoded = data
+	e.DataBase64 = !isJSON(e.DataMediaType())
+	e.SetExtension(Extension, nil)
+	return nil
+}
+
+// isJSON reports whether the data of the media type is written as is in the
+// JSON format, rather than base64 encoded.
+func isJSON(mediaType string) bool {
+	switch mediaType {
+	case event.ApplicationJSON, event.TextJSON, "":
+		return true
+	}
+	return false
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string { return Gzip }
+
+func (gzipCodec) Compress(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// deflateCodec is the "deflate" coding of HTTP, the zlib format.
+type deflateCodec struct{}
+
+func (deflateCodec) Name() string { return Deflate }
+
+func (deflateCodec) Compress(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (deflateCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
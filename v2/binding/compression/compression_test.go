@@ -0,0 +1,151 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package compression_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	nethttp "net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func newEvent(t *testing.T) event.Event {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("/source")
+	e.SetType("test.type")
+	require.NoError(t, e.SetData(event.ApplicationJSON, map[string]string{"key": string(bytes.Repeat([]byte("value"), 100))}))
+	return e
+}
+
+func TestCompressEvent(t *testing.T) {
+	for _, name := range compression.Names() {
+		t.Run(name, func(t *testing.T) {
+			e := newEvent(t)
+			want := e.Data()
+			require.NoError(t, compression.CompressEvent(&e, name))
+			require.Equal(t, name, e.Extensions()[compression.Extension])
+			require.Less(t, len(e.Data()), len(want))
+			require.Equal(t, event.ApplicationJSON, e.DataContentType())
+
+			// Compressing twice is a no-op.
+			compressed := e.Data()
+			require.NoError(t, compression.CompressEvent(&e, name))
+			require.Equal(t, compressed, e.Data())
+
+			require.NoError(t, compression.DecompressEvent(&e))
+			require.Equal(t, want, e.Data())
+			require.NotContains(t, e.Extensions(), compression.Extension)
+			require.False(t, e.DataBase64)
+
+			// Events without the extension are left untouched.
+			require.NoError(t, compression.DecompressEvent(&e))
+			require.Equal(t, want, e.Data())
+		})
+	}
+}
+
+func TestCompressEventErrors(t *testing.T) {
+	e := newEvent(t)
+	require.ErrorContains(t, compression.CompressEvent(&e, "lz4"), "unknown compression codec")
+
+	e.SetExtension(compression.Extension, "lz4")
+	require.ErrorContains(t, compression.DecompressEvent(&e), "unknown compression codec")
+
+	e.SetExtension(compression.Extension, compression.Gzip)
+	require.ErrorContains(t, compression.DecompressEvent(&e), "failed to decompress")
+}
+
+func TestDecompressEventLimit(t *testing.T) {
+	e := newEvent(t)
+	size := int64(len(e.Data()))
+	require.NoError(t, compression.CompressEvent(&e, compression.Gzip))
+
+	tooSmall := e.Clone()
+	require.ErrorIs(t, compression.DecompressEventLimit(&tooSmall, size-1), compression.ErrDataTooLarge)
+	require.Equal(t, compression.Gzip, tooSmall.Extensions()[compression.Extension])
+
+	require.NoError(t, compression.DecompressEventLimit(&e, size))
+	require.Equal(t, size, int64(len(e.Data())))
+}
+
+func TestConcurrentRegistry(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			compression.Add(upperCodec{})
+		}
+	}()
+	t.Cleanup(func() { compression.Remove("upper") })
+	for i := 0; i < 100; i++ {
+		require.NotNil(t, compression.Lookup(compression.Gzip))
+		require.Contains(t, compression.Names(), compression.Gzip)
+	}
+	<-done
+}
+
+// upperCodec is a codec for testing, "compressing" ASCII by upper casing it.
+type upperCodec struct{}
+
+func (upperCodec) Name() string { return "upper" }
+
+func (upperCodec) Compress(data []byte) ([]byte, error) { return bytes.ToUpper(data), nil }
+
+func (upperCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	b, err := io.ReadAll(r)
+	return io.NopCloser(bytes.NewReader(bytes.ToLower(b))), err
+}
+
+func TestAdd(t *testing.T) {
+	require.Nil(t, compression.Lookup("upper"))
+	compression.Add(upperCodec{})
+	t.Cleanup(func() { compression.Remove("upper") })
+	require.Equal(t, upperCodec{}, compression.Lookup("upper"))
+	require.Contains(t, compression.Names(), "upper")
+
+	e := newEvent(t)
+	want := e.Data()
+	require.NoError(t, compression.CompressEvent(&e, "upper"))
+	require.Equal(t, bytes.ToUpper(want), e.Data())
+	require.NoError(t, compression.DecompressEvent(&e))
+	require.Equal(t, want, e.Data())
+}
+
+func TestCompressedEventAcrossBindings(t *testing.T) {
+	ctx := context.Background()
+	e := newEvent(t)
+	want := e.Data()
+	require.NoError(t, compression.CompressEvent(&e, compression.Gzip))
+
+	// Binary mode: the codec is advertised in a header.
+	req, err := nethttp.NewRequest(nethttp.MethodPost, "http://localhost", nil)
+	require.NoError(t, err)
+	require.NoError(t, http.WriteRequest(ctx, binding.ToMessage(&e), req))
+	require.Equal(t, compression.Gzip, req.Header.Get("Ce-Compression"))
+	got, err := binding.ToEvent(ctx, http.NewMessageFromHttpRequest(req))
+	require.NoError(t, err)
+	require.NoError(t, compression.DecompressEvent(got))
+	require.Equal(t, want, got.Data())
+
+	// Structured mode: the compressed data is base64 encoded.
+	b, err := format.JSON.Marshal(&e)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"data_base64"`)
+	var structured event.Event
+	require.NoError(t, format.JSON.Unmarshal(b, &structured))
+	require.NoError(t, compression.DecompressEvent(&structured))
+	require.Equal(t, want, structured.Data())
+}
@@ -0,0 +1,23 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package compression compresses the data of the events independently of the
+protocol bindings.
+
+The codec of compressed data is advertised by the compression extension, which
+every binding carries along the other extensions, e.g. as the ce-compression
+header of HTTP or the ce_compression header of Kafka, so that an event
+compressed by a Kafka producer can be decompressed after being bridged to HTTP.
+The codecs are looked up by name in a registry shared by all the bindings,
+holding gzip and deflate by default; Add registers others, e.g. zstd.
+
+The event data is compressed with CompressEvent and decompressed with
+DecompressEvent, by the receivers themselves, by the Decompress middleware of
+the client, or by a bridge configured with bridge.WithDecompression. The
+decompressed data is limited to DefaultMaxDataSize bytes, or the size given to
+DecompressEventLimit.
+*/
+package compression
@@ -0,0 +1,9 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package compression
+
+// Remove unregisters the codec named name, for the tests registering codecs.
+var Remove = remove
@@ -133,3 +133,7 @@ func assertJsonEquals(t *testing.T, want map[string]interface{}, got []byte) {
 
 	require.Equal(t, wantToCompare, gotToCompare)
 }
+
+func TestMediaTypes(t *testing.T) {
+	require.Subset(t, format.MediaTypes(), []string{event.ApplicationCloudEventsJSON, event.ApplicationCloudEventsBatchJSON})
+}
@@ -0,0 +1,65 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Decision is the result of an Authorizer.
+type Decision struct {
+	// Allowed is whether the event is passed to the receiver function.
+	Allowed bool
+	// Reason explains a denial, it is included in the result of the event.
+	Reason string
+}
+
+// Allow returns a Decision allowing the event.
+func Allow() Decision {
+	return Decision{Allowed: true}
+}
+
+// Deny returns a Decision denying the event for the given reason.
+func Deny(reason string) Decision {
+	return Decision{Reason: reason}
+}
+
+// Authorizer decides whether the received events are passed to the receiver
+// function, see WithAuthorizer. It is given the metadata of the transport the
+// event has been received with, such as the certificate of the peer, the
+// headers or the topic, if the protocol exposes them.
+// Implementations must be safe for concurrent use.
+type Authorizer interface {
+	Authorize(ctx context.Context, e event.Event, md protocol.TransportMetadata) Decision
+}
+
+// AuthorizerFunc is an Authorizer calling the function.
+type AuthorizerFunc func(ctx context.Context, e event.Event, md protocol.TransportMetadata) Decision
+
+// Authorize implements Authorizer.Authorize.
+func (f AuthorizerFunc) Authorize(ctx context.Context, e event.Event, md protocol.TransportMetadata) Decision {
+	return f(ctx, e, md)
+}
+
+// authorize returns the result NACKing e if a denies it, or nil.
+func authorize(ctx context.Context, a Authorizer, m binding.Message, e event.Event) protocol.Result {
+	var md protocol.TransportMetadata
+	if r, ok := m.(protocol.TransportMetadataReader); ok {
+		md = r.TransportMetadata()
+	}
+	d := a.Authorize(ctx, e, md)
+	if d.Allowed {
+		return nil
+	}
+	if d.Reason == "" {
+		return protocol.NewReceipt(false, "event not authorized: %w", protocol.ErrForbidden)
+	}
+	return protocol.NewReceipt(false, "event not authorized: %w: %s", protocol.ErrForbidden, d.Reason)
+}
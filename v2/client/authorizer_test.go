@@ -0,0 +1,122 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// tenantAuthorizer allows the events whose source is the path of the request
+// and whose client certificate is the given one.
+func tenantAuthorizer(cert *x509.Certificate, got *protocol.TransportMetadata) Authorizer {
+	return AuthorizerFunc(func(ctx context.Context, e event.Event, md protocol.TransportMetadata) Decision {
+		*got = md
+		if len(md.PeerCertificates) == 0 || md.PeerCertificates[0] != cert {
+			return Deny("unknown client certificate")
+		}
+		if e.Source() != md.Topic {
+			return Deny("source does not match the path")
+		}
+		return Allow()
+	})
+}
+
+func newAuthorizedRequest(t *testing.T, e event.Event, path string, cert *x509.Certificate) binding.Message {
+	req := httptest.NewRequest(http.MethodPost, "https://unittest"+path, nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	require.NoError(t, cehttp.WriteRequest(context.Background(), binding.ToMessage(&e), req))
+	return cehttp.NewMessageFromHttpRequest(req)
+}
+
+func TestAuthorizer(t *testing.T) {
+	cert := &x509.Certificate{}
+	e := test.FullEvent()
+	e.SetSource("/orders")
+
+	testCases := map[string]struct {
+		path       string
+		cert       *x509.Certificate
+		wantReason string
+	}{
+		"allowed":           {path: "/orders", cert: cert},
+		"wrong path":        {path: "/invoices", cert: cert, wantReason: "event not authorized: forbidden: source does not match the path"},
+		"wrong certificate": {path: "/orders", cert: &x509.Certificate{}, wantReason: "event not authorized: forbidden: unknown client certificate"},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var md protocol.TransportMetadata
+			c := &ceClient{}
+			require.NoError(t, c.applyOptions(WithAuthorizer(tenantAuthorizer(cert, &md))))
+
+			called := false
+			invoker, err := newReceiveInvoker(func(ctx context.Context, e event.Event) {
+				called = true
+			}, invokerConfig{authorizer: c.authorizer})
+			require.NoError(t, err)
+
+			var result error
+			m := newAuthorizedRequest(t, e, tc.path, tc.cert)
+			require.NoError(t, invoker.Invoke(context.Background(), m, func(_ context.Context, _ binding.Message, r protocol.Result, _ ...binding.Transformer) error {
+				result = r
+				return nil
+			}))
+
+			require.Equal(t, tc.path, md.Topic)
+			require.Equal(t, "/orders", md.Header["Ce-Source"][0])
+			if tc.wantReason == "" {
+				require.True(t, called)
+				require.True(t, protocol.IsACK(result))
+				return
+			}
+			require.False(t, called)
+			require.True(t, protocol.IsNACK(result))
+			require.True(t, errors.Is(result, protocol.ErrForbidden))
+			require.EqualError(t, result, tc.wantReason)
+		})
+	}
+}
+
+func TestAuthorizerBatch(t *testing.T) {
+	cert := &x509.Certificate{}
+	e := test.FullEvent()
+	e.SetSource("/orders")
+	var md protocol.TransportMetadata
+
+	c := &ceClient{}
+	require.NoError(t, c.applyOptions(WithAuthorizer(tenantAuthorizer(cert, &md))))
+	var received []event.Event
+	invoker := c.newBatchInvoker(func(ctx context.Context, events []event.Event) []protocol.Result {
+		received = append(received, events...)
+		return nil
+	})
+
+	results := invoker.results(context.Background(), []binding.Message{
+		newAuthorizedRequest(t, e, "/orders", cert),
+		newAuthorizedRequest(t, e, "/invoices", cert),
+	})
+	require.Len(t, received, 1)
+	require.True(t, protocol.IsACK(results[0]))
+	require.True(t, errors.Is(results[1], protocol.ErrForbidden))
+}
+
+func TestWithAuthorizerNil(t *testing.T) {
+	c := &ceClient{}
+	require.EqualError(t, c.applyOptions(WithAuthorizer(nil)), "client option was given an nil authorizer")
+}
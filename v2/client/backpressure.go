@@ -0,0 +1,159 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Backpressure controls the consumption of a receiving client: while paused,
+// the client stops receiving messages from the protocol and, if the protocol
+// implements protocol.Pauser, the protocol stops fetching them from the
+// transport. The receiver function and the middlewares get it with
+// BackpressureFrom, to signal an overload, e.g. of a downstream system.
+//
+// Each poll goroutine of the client may still receive the message it was
+// waiting for when the consumption is paused.
+//
+// The client also pauses itself while the number of events being handled is
+// above the high watermark set with WithInflightWatermarks.
+type Backpressure struct {
+	pauser protocol.Pauser
+
+	mu sync.Mutex
+	// manual is set by Pause, automatic by the inflight watermarks.
+	manual    bool
+	automatic bool
+	// resumed is closed when the consumption resumes.
+	resumed  chan struct{}
+	inflight int
+	high     int
+	low      int
+}
+
+func newBackpressure(pauser protocol.Pauser, high, low int) *Backpressure {
+	return &Backpressure{pauser: pauser, high: high, low: low}
+}
+
+type backpressureKey struct{}
+
+// BackpressureFrom returns the Backpressure of the client invoking the
+// receiver function with ctx, or nil if ctx does not come from a receiving
+// client. The methods of a nil Backpressure do nothing.
+func BackpressureFrom(ctx context.Context) *Backpressure {
+	b, _ := ctx.Value(backpressureKey{}).(*Backpressure)
+	return b
+}
+
+func withBackpressure(ctx context.Context, b *Backpressure) context.Context {
+	return context.WithValue(ctx, backpressureKey{}, b)
+}
+
+// Pause pauses the consumption until Resume is invoked.
+func (b *Backpressure) Pause(ctx context.Context) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.manual = true
+	b.update(ctx)
+}
+
+// Resume resumes the consumption paused by Pause. The consumption stays
+// paused while the inflight events are above the low watermark.
+func (b *Backpressure) Resume(ctx context.Context) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.manual = false
+	b.update(ctx)
+}
+
+// Paused returns whether the consumption is paused.
+func (b *Backpressure) Paused() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.resumed != nil
+}
+
+// update pauses or resumes the consumption according to the state, b.mu must
+// be held.
+func (b *Backpressure) update(ctx context.Context) {
+	paused := b.manual || b.automatic
+	switch {
+	case paused && b.resumed == nil:
+		b.resumed = make(chan struct{})
+		if b.pauser != nil {
+			if err := b.pauser.Pause(ctx); err != nil {
+				cecontext.LoggerFrom(ctx).Warnw("failed to pause the protocol", zap.Error(err))
+			}
+		}
+	case !paused && b.resumed != nil:
+		close(b.resumed)
+		b.resumed = nil
+		if b.pauser != nil {
+			if err := b.pauser.Resume(ctx); err != nil {
+				cecontext.LoggerFrom(ctx).Warnw("failed to resume the protocol", zap.Error(err))
+			}
+		}
+	}
+}
+
+// wait blocks while the consumption is paused, or until ctx is done.
+func (b *Backpressure) wait(ctx context.Context) error {
+	b.mu.Lock()
+	resumed := b.resumed
+	b.mu.Unlock()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// started records n events being handled.
+func (b *Backpressure) started(ctx context.Context, n int) {
+	if b.high <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inflight += n
+	if b.inflight >= b.high && !b.automatic {
+		b.automatic = true
+		b.update(ctx)
+	}
+}
+
+// done records the end of the handling of n events.
+func (b *Backpressure) done(ctx context.Context, n int) {
+	if b.high <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inflight -= n
+	if b.inflight <= b.low && b.automatic {
+		b.automatic = false
+		b.update(ctx)
+	}
+}
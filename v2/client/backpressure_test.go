@@ -0,0 +1,143 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// pausingReceiver records the calls to Pause and Resume.
+type pausingReceiver struct {
+	protocol.Receiver
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *pausingReceiver) Pause(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, "pause")
+	return nil
+}
+
+func (r *pausingReceiver) Resume(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, "resume")
+	return nil
+}
+
+func (r *pausingReceiver) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func sendTestEvent(ch chan<- binding.Message, id int) {
+	e := test.FullEvent()
+	e.SetID(strconv.Itoa(id))
+	ch <- binding.ToMessage(&e)
+}
+
+func TestBackpressurePauseResume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan binding.Message)
+	r := &pausingReceiver{Receiver: gochan.Receiver(ch)}
+	// The next message is not received before the handler returns.
+	c, err := New(r, WithPollGoroutines(1), WithBlockingCallback())
+	require.NoError(t, err)
+
+	received := make(chan *Backpressure, 1)
+	go func() {
+		_ = c.StartReceiver(ctx, func(ctx context.Context, e event.Event) {
+			bp := BackpressureFrom(ctx)
+			if e.ID() == "0" {
+				bp.Pause(ctx)
+			}
+			received <- bp
+		})
+	}()
+
+	sendTestEvent(ch, 0)
+	bp := <-received
+	require.True(t, bp.Paused())
+	require.Equal(t, []string{"pause"}, r.recorded())
+
+	// The client does not receive while paused.
+	select {
+	case ch <- nil:
+		t.Fatal("message received while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	bp.Resume(ctx)
+	require.False(t, bp.Paused())
+	require.Equal(t, []string{"pause", "resume"}, r.recorded())
+	sendTestEvent(ch, 1)
+	<-received
+}
+
+func TestInflightWatermarks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan binding.Message)
+	r := &pausingReceiver{Receiver: gochan.Receiver(ch)}
+	c, err := New(r, WithPollGoroutines(1), WithInflightWatermarks(3, 1))
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	go func() {
+		_ = c.StartReceiver(ctx, func(ctx context.Context, e event.Event) {
+			<-release
+		})
+	}()
+
+	for i := 0; i < 3; i++ {
+		sendTestEvent(ch, i)
+	}
+	require.Eventually(t, func() bool { return len(r.recorded()) == 1 }, time.Second, time.Millisecond)
+	require.Equal(t, []string{"pause"}, r.recorded())
+
+	// Resumed once the inflight events fall to the low watermark.
+	release <- struct{}{}
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, []string{"pause"}, r.recorded())
+	release <- struct{}{}
+	require.Eventually(t, func() bool { return len(r.recorded()) == 2 }, time.Second, time.Millisecond)
+	require.Equal(t, []string{"pause", "resume"}, r.recorded())
+	sendTestEvent(ch, 3)
+	close(release)
+}
+
+func TestBackpressureNil(t *testing.T) {
+	bp := BackpressureFrom(context.Background())
+	require.Nil(t, bp)
+	bp.Pause(context.Background())
+	bp.Resume(context.Background())
+	require.False(t, bp.Paused())
+}
+
+func TestWithInflightWatermarksInvalid(t *testing.T) {
+	c := &ceClient{}
+	require.EqualError(t, c.applyOptions(WithInflightWatermarks(0, 0)), "client option was given a non positive high watermark")
+	require.EqualError(t, c.applyOptions(WithInflightWatermarks(2, 2)), "client option was given a low watermark not between 0 and the high watermark")
+}
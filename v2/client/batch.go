@@ -0,0 +1,217 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/quarantine"
+)
+
+// ReceiveBatch is the signature of a fn to be invoked for batches of incoming
+// cloudevents. It returns the result of each event, in the same order, or nil
+// to acknowledge all of them.
+// Batches are read from protocols implementing protocol.BatchReceiver, other
+// protocols deliver batches of a single event.
+// StartReceiver returns an error if the client has middlewares or inbound
+// context decorators, which apply to single events.
+type ReceiveBatch func(context.Context, []event.Event) []protocol.Result
+
+// batchInvoker invokes a ReceiveBatch fn and maps its results back to the
+// received messages. The handler timeout applies to each invocation of fn.
+// Middlewares and inbound context decorators wrap single events, so
+// StartReceiver rejects a ReceiveBatch fn when they are configured, see
+// checkBatchOptions. The event defaulters only apply to the response events,
+// which batches do not have.
+type batchInvoker struct {
+	fn                   ReceiveBatch
+	handlerTimeout       time.Duration
+	observabilityService ObservabilityService
+	authorizer           Authorizer
+	panicHandler         PanicHandler
+	ackMalformedEvent    bool
+	redeliveryExtensions bool
+	quarantineStore      quarantine.Store
+	stats                *flowStats
+}
+
+var _ Invoker = (*batchInvoker)(nil)
+
+// Invoke handles a single message as a batch of one event.
+func (r *batchInvoker) Invoke(ctx context.Context, m binding.Message, respFn protocol.ResponseFn) (err error) {
+	defer func() {
+		err = m.Finish(err)
+	}()
+	result := r.results(ctx, []binding.Message{m})[0]
+	return respFn(ctx, nil, result)
+}
+
+// InvokeBatch handles the messages of a batch, finishing each of them with the
+// result of its event.
+func (r *batchInvoker) InvokeBatch(ctx context.Context, msgs []binding.Message) {
+	for i, result := range r.results(ctx, msgs) {
+		if err := msgs[i].Finish(result); err != nil {
+			cecontext.LoggerFrom(ctx).Warn("Error while finishing a message: ", err)
+		}
+	}
+}
+
+func (r *batchInvoker) results(ctx context.Context, msgs []binding.Message) []protocol.Result {
+	results := make([]protocol.Result, len(msgs))
+	events := make(event.Batch, 0, len(msgs))
+	indexes := make([]int, 0, len(msgs))
+	for i, m := range msgs {
+		em, entry := captureForQuarantine(ctx, r.quarantineStore, m)
+		e, err := binding.ToEvent(ctx, em)
+		if err != nil {
+			r.stats.malformed.Add(1)
+			r.observabilityService.RecordReceivedMalformedEvent(ctx, err)
+			malformed := protocol.NewReceipt(r.ackMalformedEvent, "failed to convert Message to Event: %w", err)
+			results[i] = quarantineMalformed(ctx, r.quarantineStore, entry, err, malformed)
+			continue
+		}
+		if err := e.Validate(); err != nil {
+			r.stats.malformed.Add(1)
+			r.observabilityService.RecordReceivedMalformedEvent(ctx, err)
+			malformed := protocol.NewReceipt(r.ackMalformedEvent, "validation error in incoming event: %w", err)
+			results[i] = quarantineMalformed(ctx, r.quarantineStore, entry, err, malformed)
+			continue
+		}
+		if r.redeliveryExtensions {
+			addRedeliveryExtensions(m, e)
+		}
+		if r.authorizer != nil {
+			if denied := authorize(ctx, r.authorizer, m, *e); denied != nil {
+				r.stats.denied.Add(1)
+				results[i] = denied
+				continue
+			}
+		}
+		events = append(events, *e)
+		indexes = append(indexes, i)
+	}
+	if len(events) == 0 {
+		return results
+	}
+
+	for j, result := range r.invoke(ctx, events) {
+		results[indexes[j]] = result
+	}
+	return results
+}
+
+// invoke calls fn and returns exactly one result per event.
+func (r *batchInvoker) invoke(ctx context.Context, events event.Batch) (results []protocol.Result) {
+	r.stats.invoked.Add(uint64(len(events)))
+	defer func() {
+		if rec := recover(); rec != nil {
+			r.stats.panicked.Add(uint64(len(events)))
+			var stack []byte
+			if hp, ok := rec.(*handlerPanic); ok {
+				rec, stack = hp.value, hp.stack
+			} else {
+				stack = debug.Stack()
+			}
+			err := fmt.Errorf("call to batch receiver function has panicked: %v", rec)
+			cecontext.LoggerFrom(ctx).Error(err)
+			results = make([]protocol.Result, len(events))
+			for i := range events {
+				results[i] = err
+				if r.panicHandler != nil {
+					results[i] = r.panicHandler(ctx, events[i], rec, stack)
+				}
+			}
+		}
+	}()
+
+	results = r.invokeFn(ctx, events)
+	switch {
+	case results == nil:
+		results = make([]protocol.Result, len(events))
+	case len(results) != len(events):
+		err := protocol.NewReceipt(false, "batch receiver function returned %d results for %d events", len(results), len(events))
+		cecontext.LoggerFrom(ctx).Error(err)
+		results = make([]protocol.Result, len(events))
+		for i := range results {
+			results[i] = err
+		}
+	}
+	for _, result := range results {
+		r.stats.handled(result)
+	}
+	return results
+}
+
+// invokeFn invokes fn. When a handler timeout is set, the context of fn is
+// cancelled once it expires, and invokeFn NACKs all the events without
+// waiting for fn to return.
+func (r *batchInvoker) invokeFn(ctx context.Context, events event.Batch) []protocol.Result {
+	if r.handlerTimeout <= 0 {
+		return r.fn(ctx, events)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.handlerTimeout)
+	defer cancel()
+
+	type returned struct {
+		results []protocol.Result
+		panic   *handlerPanic
+	}
+	done := make(chan returned, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- returned{panic: &handlerPanic{value: rec, stack: debug.Stack()}}
+			}
+		}()
+		done <- returned{results: r.fn(ctx, events)}
+	}()
+
+	select {
+	case ret := <-done:
+		if ret.panic != nil {
+			panic(ret.panic)
+		}
+		return ret.results
+	case <-ctx.Done():
+		err := protocol.NewReceipt(false, "batch receiver function did not return within %v: %w", r.handlerTimeout, ctx.Err())
+		results := make([]protocol.Result, len(events))
+		for i := range results {
+			results[i] = err
+		}
+		return results
+	}
+}
+
+// checkBatchOptions returns an error if the client has options which can not
+// be applied to a ReceiveBatch fn.
+func (c *ceClient) checkBatchOptions() error {
+	switch {
+	case len(c.middlewares) > 0:
+		return errors.New("middlewares are not supported with a batch receiver function")
+	case len(c.inboundContextDecorators) > 0:
+		return errors.New("inbound context decorators, including type registries, are not supported with a batch receiver function")
+	}
+	return nil
+}
+
+// IsReceiver returns false as batches can be received from both Receivers and
+// Responders.
+func (r *batchInvoker) IsReceiver() bool {
+	return false
+}
+
+func (r *batchInvoker) IsResponder() bool {
+	return false
+}
@@ -0,0 +1,228 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestBatchInvoker(t *testing.T) {
+	events := make([]event.Event, 3)
+	for i := range events {
+		events[i] = test.FullEvent()
+		events[i].SetID(strconv.Itoa(i))
+	}
+
+	testCases := map[string]struct {
+		fn       ReceiveBatch
+		messages []binding.Message
+		wantACK  []bool
+		wantIDs  []string
+	}{
+		"per event results": {
+			fn: func(ctx context.Context, events []event.Event) []protocol.Result {
+				return []protocol.Result{protocol.ResultACK, protocol.ResultNACK, nil}
+			},
+			messages: []binding.Message{binding.ToMessage(&events[0]), binding.ToMessage(&events[1]), binding.ToMessage(&events[2])},
+			wantACK:  []bool{true, false, true},
+			wantIDs:  []string{"0", "1", "2"},
+		},
+		"malformed events are skipped": {
+			fn: func(ctx context.Context, events []event.Event) []protocol.Result {
+				return []protocol.Result{protocol.ResultACK, protocol.ResultNACK}
+			},
+			messages: []binding.Message{binding.ToMessage(&events[0]), bindingtest.UnknownMessage, binding.ToMessage(&events[2])},
+			wantACK:  []bool{true, false, false},
+			wantIDs:  []string{"0", "2"},
+		},
+		"nil results": {
+			fn: func(ctx context.Context, events []event.Event) []protocol.Result {
+				return nil
+			},
+			messages: []binding.Message{binding.ToMessage(&events[0]), binding.ToMessage(&events[1])},
+			wantACK:  []bool{true, true},
+			wantIDs:  []string{"0", "1"},
+		},
+		"missing results": {
+			fn: func(ctx context.Context, events []event.Event) []protocol.Result {
+				return []protocol.Result{protocol.ResultACK}
+			},
+			messages: []binding.Message{binding.ToMessage(&events[0]), binding.ToMessage(&events[1])},
+			wantACK:  []bool{false, false},
+			wantIDs:  []string{"0", "1"},
+		},
+		"panic": {
+			fn: func(ctx context.Context, events []event.Event) []protocol.Result {
+				panic("boom")
+			},
+			messages: []binding.Message{binding.ToMessage(&events[0])},
+			wantACK:  []bool{false},
+			wantIDs:  []string{"0"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var ids []string
+			invoker := (&ceClient{observabilityService: noopObservabilityService{}}).newBatchInvoker(func(ctx context.Context, events []event.Event) []protocol.Result {
+				for _, e := range events {
+					ids = append(ids, e.ID())
+				}
+				return tc.fn(ctx, events)
+			})
+
+			results := make([]error, len(tc.messages))
+			msgs := make([]binding.Message, len(tc.messages))
+			for i, m := range tc.messages {
+				msgs[i] = binding.WithFinish(m, func(err error) { results[i] = err })
+			}
+			invoker.InvokeBatch(context.Background(), msgs)
+
+			require.Equal(t, tc.wantIDs, ids)
+			for i, result := range results {
+				require.Equal(t, tc.wantACK[i], protocol.IsACK(result), "result %d: %v", i, result)
+			}
+		})
+	}
+}
+
+func TestStartReceiverBatchHTTP(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p, err := cehttp.New(cehttp.WithListener(listener))
+	require.NoError(t, err)
+	c, err := New(p)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var batches [][]string
+	go func() {
+		_ = c.StartReceiver(ctx, func(ctx context.Context, events []event.Event) []protocol.Result {
+			mu.Lock()
+			defer mu.Unlock()
+			var ids []string
+			results := make([]protocol.Result, len(events))
+			for i, e := range events {
+				ids = append(ids, e.ID())
+				if e.ID() == "nack" {
+					results[i] = protocol.ResultNACK
+				}
+			}
+			batches = append(batches, ids)
+			return results
+		})
+	}()
+
+	target := "http://" + listener.Addr().String()
+	send := func(ids ...string) int {
+		events := make([]event.Event, len(ids))
+		for i, id := range ids {
+			events[i] = test.FullEvent()
+			events[i].SetID(id)
+		}
+		req, err := cehttp.NewHTTPRequestFromEvents(ctx, target, events)
+		require.NoError(t, err)
+		var resp *http.Response
+		require.Eventually(t, func() bool {
+			resp, err = http.DefaultClient.Do(req)
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, send("a", "b", "c"))
+	require.Equal(t, http.StatusInternalServerError, send("d", "nack"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, [][]string{{"a", "b", "c"}, {"d", "nack"}}, batches)
+}
+
+func TestStartReceiverBatchSingleEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan binding.Message)
+	c, err := New(gochan.Receiver(ch))
+	require.NoError(t, err)
+
+	got := make(chan []event.Event, 1)
+	go func() {
+		_ = c.StartReceiver(ctx, ReceiveBatch(func(ctx context.Context, events []event.Event) []protocol.Result {
+			got <- events
+			return []protocol.Result{protocol.ResultNACK}
+		}))
+	}()
+
+	e := test.FullEvent()
+	result := make(chan error, 1)
+	ch <- binding.WithFinish(binding.ToMessage(&e), func(err error) { result <- err })
+	require.Len(t, <-got, 1)
+	require.True(t, protocol.IsNACK(<-result))
+}
+
+func TestBatchInvokerHandlerTimeout(t *testing.T) {
+	e := test.FullEvent()
+	release := make(chan struct{})
+	defer close(release)
+	c := &ceClient{observabilityService: noopObservabilityService{}, handlerTimeout: 10 * time.Millisecond}
+	invoker := c.newBatchInvoker(func(ctx context.Context, events []event.Event) []protocol.Result {
+		<-ctx.Done()
+		<-release
+		return nil
+	})
+
+	var result error
+	invoker.InvokeBatch(context.Background(), []binding.Message{binding.WithFinish(binding.ToMessage(&e), func(err error) { result = err })})
+	require.True(t, protocol.IsNACK(result))
+	require.ErrorIs(t, result, context.DeadlineExceeded)
+
+	// A panic of fn is still handled.
+	invoker = c.newBatchInvoker(func(ctx context.Context, events []event.Event) []protocol.Result {
+		panic("boom")
+	})
+	invoker.InvokeBatch(context.Background(), []binding.Message{binding.WithFinish(binding.ToMessage(&e), func(err error) { result = err })})
+	require.ErrorContains(t, result, "has panicked: boom")
+}
+
+func TestStartReceiverBatchUnsupportedOptions(t *testing.T) {
+	passThrough := func(next Handler) Handler { return next }
+	for name, opt := range map[string]Option{
+		"middleware":    WithMiddleware(passThrough),
+		"type registry": WithTypeRegistry(NewTypeRegistry()),
+		"decorator": WithInboundContextDecorator(func(ctx context.Context, _ binding.Message) context.Context {
+			return ctx
+		}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(gochan.Receiver(make(chan binding.Message)), opt)
+			require.NoError(t, err)
+			err = c.StartReceiver(context.Background(), func(ctx context.Context, events []event.Event) []protocol.Result {
+				return nil
+			})
+			require.ErrorContains(t, err, "not supported with a batch receiver function")
+		})
+	}
+}
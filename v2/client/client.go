@@ -12,6 +12,7 @@ import (
 	"io"
 	"runtime"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -19,6 +20,7 @@ import (
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/quarantine"
 )
 
 // Client interface defines the runtime contract the CloudEvents client supports.
@@ -47,6 +49,7 @@ type Client interface {
 	// * func(event.Event) (*event.Event, error)
 	// * func(context.Context, event.Event) *event.Event
 	// * func(context.Context, event.Event) (*event.Event, error)
+	// * func(context.Context, []event.Event) []protocol.Result (see ReceiveBatch)
 	// The error returned may impact the messages processing made by the protocol
 	// used (example: message acknowledgement). Please refer to each protocol's
 	// package documentation of the function "Finish(err error) error".
@@ -60,6 +63,7 @@ func New(obj interface{}, opts ...Option) (Client, error) {
 		// Running runtime.GOMAXPROCS(0) doesn't update the value, just returns the current one
 		pollGoroutines:       runtime.GOMAXPROCS(0),
 		observabilityService: noopObservabilityService{},
+		protocolType:         fmt.Sprintf("%T", obj),
 	}
 
 	if p, ok := obj.(protocol.Sender); ok {
@@ -74,9 +78,15 @@ func New(obj interface{}, opts ...Option) (Client, error) {
 	if p, ok := obj.(protocol.Receiver); ok {
 		c.receiver = p
 	}
+	if p, ok := obj.(protocol.BatchReceiver); ok {
+		c.batchReceiver = p
+	}
 	if p, ok := obj.(protocol.Opener); ok {
 		c.opener = p
 	}
+	if p, ok := obj.(protocol.Pinger); ok {
+		c.pinger = p
+	}
 
 	if err := c.applyOptions(opts...); err != nil {
 		return nil, err
@@ -90,7 +100,9 @@ type ceClient struct {
 	receiver  protocol.Receiver
 	responder protocol.Responder
 	// Optional.
-	opener protocol.Opener
+	batchReceiver protocol.BatchReceiver
+	opener        protocol.Opener
+	pinger        protocol.Pinger
 
 	observabilityService ObservabilityService
 
@@ -99,9 +111,30 @@ type ceClient struct {
 	invoker                   Invoker
 	receiverMu                sync.Mutex
 	eventDefaulterFns         []EventDefaulter
+	middlewares               []Middleware
+	authorizer                Authorizer
+	outboundQueue             *OutboundQueue
+	outboundWorkers           int
+	panicHandler              PanicHandler
+	encodingSelector          EncodingSelector
+	contentTypeEncodings      []contentTypeEncoding
+	handlerTimeout            time.Duration
+	orderedDispatchWorkers    int
+	priorityDispatchWorkers   int
+	shardAttribute            string
+	shards                    int
+	shardConfig               ShardConfig
+	inflightHigh              int
+	inflightLow               int
 	pollGoroutines            int
 	blockingCallback          bool
 	ackMalformedEvent         bool
+	redeliveryExtensions      bool
+	quarantineStore           quarantine.Store
+
+	// protocolType and stats are reported by DescribeFlow.
+	protocolType string
+	stats        flowStats
 }
 
 func (c *ceClient) applyOptions(opts ...Option) error {
@@ -119,6 +152,7 @@ func (c *ceClient) Send(ctx context.Context, e event.Event) protocol.Result {
 		err = errors.New("sender not set")
 		return err
 	}
+	c.stats.sent.Add(1)
 
 	for _, f := range c.outboundContextDecorators {
 		ctx = f(ctx)
@@ -130,16 +164,60 @@ func (c *ceClient) Send(ctx context.Context, e event.Event) protocol.Result {
 		}
 	}
 	if err = e.Validate(); err != nil {
+		c.stats.invalid.Add(1)
 		return err
 	}
+	ctx, o := c.applySendOptions(ctx, e)
 
 	// Event has been defaulted and validated, record we are going to perform send.
 	ctx, cb := c.observabilityService.RecordSendingEvent(ctx, e)
+	if c.outboundQueue != nil {
+		c.outboundQueue.run(c.outboundWorkers, c.sendQueued)
+		ev := queuedEvent{ctx: context.WithoutCancel(ctx), e: e, cb: cb, fn: o.resultFn}
+		if err = c.outboundQueue.enqueue(ctx, ev); err != nil {
+			c.stats.queueRejected.Add(1)
+			cb(err)
+			return err
+		}
+		c.stats.enqueued.Add(1)
+		return nil
+	}
+	if o.resultFn != nil {
+		return c.sendAsync(ctx, e, cb, o.resultFn)
+	}
 	err = c.sender.Send(ctx, (*binding.EventMessage)(&e))
+	c.stats.delivery(err)
 	defer cb(err)
 	return err
 }
 
+// sendAsync sends e with the AsyncSender of the protocol if any, invoking fn
+// with the result of the delivery.
+func (c *ceClient) sendAsync(ctx context.Context, e event.Event, cb func(error), fn protocol.ResultFn) error {
+	done := func(result protocol.Result) {
+		c.stats.delivery(result)
+		cb(result)
+		fn(result)
+	}
+	if s, ok := c.sender.(protocol.AsyncSender); ok {
+		if err := s.SendAsync(ctx, (*binding.EventMessage)(&e), done); err != nil {
+			c.stats.failed.Add(1)
+			cb(err)
+			return err
+		}
+		return nil
+	}
+	done(c.sender.Send(ctx, (*binding.EventMessage)(&e)))
+	return nil
+}
+
+// sendQueued sends an event of the outbound queue.
+func (c *ceClient) sendQueued(ev queuedEvent) error {
+	err := c.sender.Send(ev.ctx, (*binding.EventMessage)(&ev.e))
+	c.stats.delivery(err)
+	return err
+}
+
 func (c *ceClient) Request(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
 	var resp *event.Event
 	var err error
@@ -148,6 +226,7 @@ func (c *ceClient) Request(ctx context.Context, e event.Event) (*event.Event, pr
 		err = errors.New("requester not set")
 		return nil, err
 	}
+	c.stats.requested.Add(1)
 	for _, f := range c.outboundContextDecorators {
 		ctx = f(ctx)
 	}
@@ -159,8 +238,10 @@ func (c *ceClient) Request(ctx context.Context, e event.Event) (*event.Event, pr
 	}
 
 	if err = e.Validate(); err != nil {
+		c.stats.invalid.Add(1)
 		return nil, err
 	}
+	ctx, _ = c.applySendOptions(ctx, e)
 
 	// Event has been defaulted and validated, record we are going to perform request.
 	ctx, cb := c.observabilityService.RecordRequestEvent(ctx, e)
@@ -168,6 +249,7 @@ func (c *ceClient) Request(ctx context.Context, e event.Event) (*event.Event, pr
 	// If provided a requester, use it to do request/response.
 	var msg binding.Message
 	msg, err = c.requester.Request(ctx, (*binding.EventMessage)(&e))
+	c.stats.delivery(err)
 	if msg != nil {
 		defer func() {
 			if err := msg.Finish(err); err != nil {
@@ -206,15 +288,36 @@ func (c *ceClient) StartReceiver(ctx context.Context, fn interface{}) error {
 		return fmt.Errorf("client already has a receiver")
 	}
 
-	invoker, err := newReceiveInvoker(
-		fn,
-		c.observabilityService,
-		c.inboundContextDecorators,
-		c.eventDefaulterFns,
-		c.ackMalformedEvent,
-	)
-	if err != nil {
-		return err
+	var invoker Invoker
+	var err error
+	switch fn := fn.(type) {
+	case ReceiveBatch:
+		if err := c.checkBatchOptions(); err != nil {
+			return err
+		}
+		invoker = c.newBatchInvoker(fn)
+	case func(context.Context, []event.Event) []protocol.Result:
+		if err := c.checkBatchOptions(); err != nil {
+			return err
+		}
+		invoker = c.newBatchInvoker(fn)
+	default:
+		invoker, err = newReceiveInvoker(fn, invokerConfig{
+			observabilityService:     c.observabilityService,
+			inboundContextDecorators: c.inboundContextDecorators,
+			eventDefaulterFns:        c.eventDefaulterFns,
+			middlewares:              c.middlewares,
+			authorizer:               c.authorizer,
+			panicHandler:             c.panicHandler,
+			handlerTimeout:           c.handlerTimeout,
+			ackMalformedEvent:        c.ackMalformedEvent,
+			redeliveryExtensions:     c.redeliveryExtensions,
+			quarantineStore:          c.quarantineStore,
+			stats:                    &c.stats,
+		})
+		if err != nil {
+			return err
+		}
 	}
 	if invoker.IsReceiver() && c.receiver == nil {
 		return fmt.Errorf("mismatched receiver callback without protocol.Receiver supported by protocol")
@@ -228,17 +331,50 @@ func (c *ceClient) StartReceiver(ctx context.Context, fn interface{}) error {
 		return errors.New("responder nor receiver set")
 	}
 
+	c.stats.setHandler(fmt.Sprintf("%T", fn))
 	defer func() {
 		c.invoker = nil
+		c.stats.setHandler("")
 	}()
 
+	var dispatcher *orderedDispatcher
+	if c.orderedDispatchWorkers > 0 {
+		dispatcher = newOrderedDispatcher(c.orderedDispatchWorkers)
+	}
+	var prioritizer *priorityDispatcher
+	if c.priorityDispatchWorkers > 0 {
+		prioritizer = newPriorityDispatcher(c.priorityDispatchWorkers)
+	}
+	var sharder *shardedDispatcher
+	if c.shards > 0 {
+		sharder = newShardedDispatcher(c.shardAttribute, c.shards, c.shardConfig)
+	}
+
+	var pauser protocol.Pauser
+	if c.responder != nil {
+		pauser, _ = c.responder.(protocol.Pauser)
+	} else if c.receiver != nil {
+		pauser, _ = c.receiver.(protocol.Pauser)
+	}
+	bp := newBackpressure(pauser, c.inflightHigh, c.inflightLow)
+	ctx = withBackpressure(ctx, bp)
+
 	// Start Polling.
 	wg := sync.WaitGroup{}
+	batchInvoker, batch := invoker.(*batchInvoker)
 	for i := 0; i < c.pollGoroutines; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			if batch && c.batchReceiver != nil {
+				c.receiveBatches(ctx, &wg, batchInvoker, bp)
+				return
+			}
 			for {
+				if err := bp.wait(ctx); err != nil {
+					return
+				}
+
 				var msg binding.Message
 				var respFn protocol.ResponseFn
 				var err error
@@ -255,17 +391,48 @@ func (c *ceClient) StartReceiver(ctx context.Context, fn interface{}) error {
 				}
 
 				if err != nil {
+					c.stats.receiveErrors.Add(1)
 					cecontext.LoggerFrom(ctx).Warn("Error while receiving a message: ", err)
 					continue
 				}
+				c.stats.received.Add(1)
 
+				var key string
+				ordered := false
+				if dispatcher != nil {
+					key, msg, ordered = partitionKey(ctx, msg)
+				}
+				var shardKey string
+				if sharder != nil && !ordered {
+					shardKey, msg = sharder.shardKey(ctx, msg)
+				}
+				var p int32
+				if prioritizer != nil && !ordered && sharder == nil {
+					p, msg = priority(ctx, msg)
+				}
+
+				bp.started(ctx, 1)
 				callback := func() {
+					defer bp.done(ctx, 1)
 					if err := c.invoker.Invoke(ctx, msg, respFn); err != nil {
 						cecontext.LoggerFrom(ctx).Warn("Error while handling a message: ", err)
 					}
 				}
 
-				if c.blockingCallback {
+				if ordered {
+					dispatcher.dispatch(key, callback)
+				} else if sharder != nil {
+					if !sharder.dispatch(shardKey, callback) {
+						bp.done(ctx, 1)
+						c.stats.shardRejected.Add(1)
+						rejected := protocol.NewReceipt(false, "shard of %s %q is full", c.shardAttribute, shardKey)
+						if err := msg.Finish(respFn(ctx, nil, rejected)); err != nil {
+							cecontext.LoggerFrom(ctx).Warn("Error while rejecting a message: ", err)
+						}
+					}
+				} else if prioritizer != nil {
+					prioritizer.dispatch(p, callback)
+				} else if c.blockingCallback {
 					callback()
 				} else {
 					// Do not block on the invoker.
@@ -288,10 +455,70 @@ func (c *ceClient) StartReceiver(ctx context.Context, fn interface{}) error {
 	}
 
 	wg.Wait()
+	if dispatcher != nil {
+		dispatcher.close()
+	}
+	if prioritizer != nil {
+		prioritizer.close()
+	}
+	if sharder != nil {
+		sharder.close()
+	}
 
 	return err
 }
 
+// receiveBatches invokes the batch invoker with the batches of the
+// BatchReceiver until it is closed.
+func (c *ceClient) receiveBatches(ctx context.Context, wg *sync.WaitGroup, invoker *batchInvoker, bp *Backpressure) {
+	for {
+		if err := bp.wait(ctx); err != nil {
+			return
+		}
+		msgs, err := c.batchReceiver.ReceiveBatch(ctx)
+		if err == io.EOF { // Normal close
+			return
+		}
+		if err != nil {
+			c.stats.receiveErrors.Add(1)
+			cecontext.LoggerFrom(ctx).Warn("Error while receiving a batch: ", err)
+			continue
+		}
+		c.stats.batches.Add(1)
+		c.stats.received.Add(uint64(len(msgs)))
+
+		bp.started(ctx, len(msgs))
+		callback := func() {
+			defer bp.done(ctx, len(msgs))
+			invoker.InvokeBatch(ctx, msgs)
+		}
+		if c.blockingCallback {
+			callback()
+		} else {
+			// Do not block on the invoker.
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				callback()
+			}()
+		}
+	}
+}
+
+func (c *ceClient) newBatchInvoker(fn ReceiveBatch) *batchInvoker {
+	return &batchInvoker{
+		fn:                   fn,
+		handlerTimeout:       c.handlerTimeout,
+		observabilityService: c.observabilityService,
+		authorizer:           c.authorizer,
+		panicHandler:         c.panicHandler,
+		ackMalformedEvent:    c.ackMalformedEvent,
+		redeliveryExtensions: c.redeliveryExtensions,
+		quarantineStore:      c.quarantineStore,
+		stats:                &c.stats,
+	}
+}
+
 // noRespFn is used to simply forward the protocol.Result for receivers that aren't responders
 func noRespFn(_ context.Context, _ binding.Message, r protocol.Result, _ ...binding.Transformer) error {
 	return r
@@ -6,6 +6,8 @@
 package client
 
 import (
+	"errors"
+
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
 )
 
@@ -33,3 +35,17 @@ func NewHTTP(opts ...http.Option) (Client, error) {
 // the observability service passed as an option, or client.NewClientHTTP from
 // package github.com/cloudevents/sdk-go/observability/opencensus/v2/client
 var NewDefault = NewHTTP
+
+// OpenAPI returns the OpenAPI 3 description of the endpoints of a client
+// receiving with an HTTP Protocol, see http.Protocol.OpenAPI.
+func OpenAPI(c Client, info http.OpenAPIInfo) (map[string]interface{}, error) {
+	cc, ok := c.(*ceClient)
+	if !ok {
+		return nil, errors.New("client is not a client returned by New")
+	}
+	p, ok := cc.responder.(*http.Protocol)
+	if !ok {
+		return nil, errors.New("client does not receive with an http protocol")
+	}
+	return p.OpenAPI(info), nil
+}
@@ -0,0 +1,28 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func TestOpenAPI(t *testing.T) {
+	c, err := NewHTTP(http.WithPath("/events"))
+	require.NoError(t, err)
+	doc, err := OpenAPI(c, http.OpenAPIInfo{Title: "orders", Version: "1.0.0"})
+	require.NoError(t, err)
+	require.Contains(t, doc["paths"], "/events")
+
+	c, err = New(gochan.New())
+	require.NoError(t, err)
+	_, err = OpenAPI(c, http.OpenAPIInfo{Title: "orders", Version: "1.0.0"})
+	require.Error(t, err)
+}
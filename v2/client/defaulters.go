@@ -7,8 +7,8 @@ package client
 
 import (
 	"context"
-	"time"
 
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
 
 	"github.com/google/uuid"
@@ -31,12 +31,13 @@ func DefaultIDToUUIDIfNotSet(ctx context.Context, event event.Event) event.Event
 }
 
 // DefaultTimeToNowIfNotSet will inspect the provided event and assign a new
-// Timestamp to context.Time if it is found to be nil or zero.
+// Timestamp to context.Time if it is found to be nil or zero, from the clock
+// of ctx.
 func DefaultTimeToNowIfNotSet(ctx context.Context, event event.Event) event.Event {
 	if event.Context != nil {
 		if event.Time().IsZero() {
 			event.Context = event.Context.Clone()
-			event.SetTime(time.Now())
+			event.SetTime(cecontext.ClockFrom(ctx).Now())
 		}
 	}
 	return event
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
 )
 
@@ -76,6 +77,17 @@ func TestDefaultTimeToNowIfNotSet_empty(t *testing.T) {
 	}
 }
 
+func TestDefaultTimeToNowIfNotSet_clock(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := cecontext.WithClock(context.TODO(), cecontext.NewFakeClock(now))
+
+	got := DefaultTimeToNowIfNotSet(ctx, event.New())
+
+	if !got.Time().Equal(now) {
+		t.Errorf("expected time %v, got %v", now, got.Time())
+	}
+}
+
 func TestDefaultTimeToNowIfNotSet_set(t *testing.T) {
 	for _, tc := range versions {
 		t.Run(tc, func(t *testing.T) {
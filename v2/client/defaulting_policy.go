@@ -0,0 +1,179 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// DefaultingPolicy configures a chain of event defaulters applied to the
+// outbound events before they are validated, see WithDefaultingPolicy. The
+// zero value of each field disables its defaulter.
+type DefaultingPolicy struct {
+	// IDGenerator generates the ID of the events without ID.
+	IDGenerator IDGenerator
+	// TimePrecision enables setting the time of the events without time to
+	// the time of the clock of the context, truncated to the precision.
+	TimePrecision time.Duration
+	// Source is the source of the events without source, e.g. the Source of
+	// the ServiceIdentity of the service.
+	Source string
+	// TypePrefix is prepended to the types of the events not starting with
+	// it, e.g. "com.example.orders.".
+	TypePrefix string
+	// SubjectTemplate is a text/template executed with the event to set the
+	// subject of the events without subject, e.g. "{{.Extensions.tenant}}".
+	SubjectTemplate string
+	// DataContentType is the datacontenttype of the events with data and
+	// without datacontenttype.
+	DataContentType string
+}
+
+// Defaulters returns the event defaulters of p, in the order they apply: the
+// ID, the time, the source, the type, the datacontenttype and then the
+// subject, so that the template of the subject sees the other attributes.
+func (p DefaultingPolicy) Defaulters() ([]EventDefaulter, error) {
+	var fns []EventDefaulter
+	if p.IDGenerator != nil {
+		fns = append(fns, NewDefaultIDIfNotSet(p.IDGenerator))
+	}
+	if p.TimePrecision > 0 {
+		fns = append(fns, NewDefaultTimeIfNotSet(p.TimePrecision))
+	}
+	if p.Source != "" {
+		fns = append(fns, NewDefaultSourceIfNotSet(p.Source))
+	}
+	if p.TypePrefix != "" {
+		fns = append(fns, NewTypePrefixer(p.TypePrefix))
+	}
+	if p.DataContentType != "" {
+		fns = append(fns, NewDefaultDataContentTypeIfNotSet(p.DataContentType))
+	}
+	if p.SubjectTemplate != "" {
+		fn, err := NewDefaultSubjectFromTemplate(p.SubjectTemplate)
+		if err != nil {
+			return nil, err
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+// NewDefaultTimeIfNotSet returns a defaulter setting the time of the events
+// found without time to the time of the clock of ctx, truncated to the given
+// precision, e.g. time.Millisecond.
+func NewDefaultTimeIfNotSet(precision time.Duration) EventDefaulter {
+	return func(ctx context.Context, event event.Event) event.Event {
+		if event.Context != nil {
+			if event.Time().IsZero() {
+				event.Context = event.Context.Clone()
+				event.SetTime(cecontext.ClockFrom(ctx).Now().Truncate(precision))
+			}
+		}
+		return event
+	}
+}
+
+// NewDefaultSourceIfNotSet returns a defaulter setting the source of the
+// events found without source.
+func NewDefaultSourceIfNotSet(source string) EventDefaulter {
+	return func(ctx context.Context, event event.Event) event.Event {
+		if event.Context != nil {
+			if event.Source() == "" {
+				event.Context = event.Context.Clone()
+				event.SetSource(source)
+			}
+		}
+		return event
+	}
+}
+
+// NewTypePrefixer returns a defaulter prepending prefix to the types of the
+// events not starting with it. Events without type are left untouched.
+func NewTypePrefixer(prefix string) EventDefaulter {
+	return func(ctx context.Context, event event.Event) event.Event {
+		if event.Context != nil {
+			if t := event.Type(); t != "" && !strings.HasPrefix(t, prefix) {
+				event.Context = event.Context.Clone()
+				event.SetType(prefix + t)
+			}
+		}
+		return event
+	}
+}
+
+// NewDefaultSubjectFromTemplate returns a defaulter setting the subject of
+// the events found without subject to the result of the text/template text,
+// executed with the event. Events whose template fails or results in an empty
+// subject are left untouched.
+func NewDefaultSubjectFromTemplate(text string) (EventDefaulter, error) {
+	tmpl, err := template.New("subject").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subject template: %w", err)
+	}
+	return func(ctx context.Context, event event.Event) event.Event {
+		if event.Context != nil {
+			if event.Subject() == "" {
+				var b bytes.Buffer
+				if err := tmpl.Execute(&b, event); err != nil {
+					cecontext.LoggerFrom(ctx).Warnf("failed to execute subject template: %v", err)
+					return event
+				}
+				if subject := b.String(); subject != "" && subject != "<no value>" {
+					event.Context = event.Context.Clone()
+					event.SetSubject(subject)
+				}
+			}
+		}
+		return event
+	}, nil
+}
+
+// ServiceIdentity identifies the service sending the events.
+type ServiceIdentity struct {
+	Namespace string
+	Name      string
+}
+
+// ServiceIdentityFromEnv returns the identity of the service from the
+// environment: the name is OTEL_SERVICE_NAME, or K_SERVICE on Knative, and
+// the namespace is OTEL_SERVICE_NAMESPACE, or POD_NAMESPACE on Kubernetes.
+func ServiceIdentityFromEnv() ServiceIdentity {
+	return ServiceIdentity{
+		Namespace: firstEnv("OTEL_SERVICE_NAMESPACE", "POD_NAMESPACE"),
+		Name:      firstEnv("OTEL_SERVICE_NAME", "K_SERVICE"),
+	}
+}
+
+// Source returns the URI-reference /namespace/name identifying the service as
+// the source of its events, empty if the service has no name.
+func (s ServiceIdentity) Source() string {
+	switch {
+	case s.Name == "":
+		return ""
+	case s.Namespace == "":
+		return "/" + s.Name
+	}
+	return "/" + s.Namespace + "/" + s.Name
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
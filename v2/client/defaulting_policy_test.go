@@ -0,0 +1,87 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestDefaultingPolicy(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 678901234, time.UTC)
+	ctx := cecontext.WithClock(context.Background(), cecontext.NewFakeClock(now))
+
+	fns, err := DefaultingPolicy{
+		IDGenerator:     IDGeneratorFunc(func() string { return "generated" }),
+		TimePrecision:   time.Millisecond,
+		Source:          ServiceIdentity{Namespace: "shop", Name: "orders"}.Source(),
+		TypePrefix:      "com.example.",
+		SubjectTemplate: "{{.Type}}/{{.Extensions.tenant}}",
+		DataContentType: event.ApplicationJSON,
+	}.Defaulters()
+	require.NoError(t, err)
+
+	e := event.New()
+	e.SetType("order.created")
+	e.SetExtension("tenant", "acme")
+	for _, fn := range fns {
+		e = fn(ctx, e)
+	}
+	require.NoError(t, e.Validate())
+	require.Equal(t, "generated", e.ID())
+	require.Equal(t, now.Truncate(time.Millisecond), e.Time())
+	require.Equal(t, "/shop/orders", e.Source())
+	require.Equal(t, "com.example.order.created", e.Type())
+	require.Equal(t, "com.example.order.created/acme", e.Subject())
+	require.Equal(t, event.ApplicationJSON, e.DataContentType())
+
+	// Set attributes are kept, and types are prefixed once.
+	set := event.New()
+	set.SetID("id")
+	set.SetTime(now)
+	set.SetSource("/other")
+	set.SetType("com.example.order.created")
+	set.SetSubject("subject")
+	for _, fn := range fns {
+		set = fn(ctx, set)
+	}
+	require.Equal(t, "id", set.ID())
+	require.Equal(t, now, set.Time())
+	require.Equal(t, "/other", set.Source())
+	require.Equal(t, "com.example.order.created", set.Type())
+	require.Equal(t, "subject", set.Subject())
+}
+
+func TestDefaultSubjectFromTemplateMissingKey(t *testing.T) {
+	fn, err := NewDefaultSubjectFromTemplate("{{.Extensions.tenant}}")
+	require.NoError(t, err)
+	e := fn(context.Background(), event.New())
+	require.Equal(t, "", e.Subject())
+
+	_, err = NewDefaultSubjectFromTemplate("{{")
+	require.Error(t, err)
+	require.Error(t, (&ceClient{}).applyOptions(WithDefaultingPolicy(DefaultingPolicy{SubjectTemplate: "{{"})))
+}
+
+func TestServiceIdentity(t *testing.T) {
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("OTEL_SERVICE_NAMESPACE", "")
+	t.Setenv("K_SERVICE", "orders")
+	t.Setenv("POD_NAMESPACE", "shop")
+	require.Equal(t, ServiceIdentity{Namespace: "shop", Name: "orders"}, ServiceIdentityFromEnv())
+
+	t.Setenv("OTEL_SERVICE_NAME", "billing")
+	require.Equal(t, "/shop/billing", ServiceIdentityFromEnv().Source())
+
+	require.Equal(t, "/orders", ServiceIdentity{Name: "orders"}.Source())
+	require.Equal(t, "", ServiceIdentity{Namespace: "shop"}.Source())
+}
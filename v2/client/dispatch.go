@@ -0,0 +1,332 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"container/heap"
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// PartitionKeyExtension is the name of the extension holding the key used to
+// order the events, see WithOrderedDispatch.
+const PartitionKeyExtension = "partitionkey"
+
+// orderedDispatchQueueSize is the number of callbacks waiting in each worker
+// queue before the receiving blocks.
+const orderedDispatchQueueSize = 64
+
+// orderedDispatcher runs the callbacks dispatched with the same key one after
+// the other, in dispatch order, while the callbacks of other keys run
+// concurrently on the other workers.
+type orderedDispatcher struct {
+	queues []chan func()
+	wg     sync.WaitGroup
+}
+
+func newOrderedDispatcher(workers int) *orderedDispatcher {
+	d := &orderedDispatcher{queues: make([]chan func(), workers)}
+	for i := range d.queues {
+		q := make(chan func(), orderedDispatchQueueSize)
+		d.queues[i] = q
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for fn := range q {
+				fn()
+			}
+		}()
+	}
+	return d
+}
+
+// dispatch queues fn on the worker of key.
+func (d *orderedDispatcher) dispatch(key string, fn func()) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	d.queues[h.Sum32()%uint32(len(d.queues))] <- fn
+}
+
+// close waits for the queued callbacks to complete. dispatch must not be
+// called afterwards.
+func (d *orderedDispatcher) close() {
+	for _, q := range d.queues {
+		close(q)
+	}
+	d.wg.Wait()
+}
+
+// decodeStructured decodes m if it is a structured message, so that its
+// metadata can be read, and returns the message to invoke.
+func decodeStructured(ctx context.Context, m binding.Message) binding.Message {
+	if m.ReadEncoding() != binding.EncodingStructured {
+		return m
+	}
+	e, err := binding.ToEvent(ctx, m)
+	if err != nil {
+		// Let the invoker handle the malformed message.
+		return m
+	}
+	return binding.WithFinish((*binding.EventMessage)(e), func(err error) {
+		_ = m.Finish(err)
+	})
+}
+
+// partitionKey returns the partitionkey of m, if any, along with the message
+// to invoke. Structured messages are decoded to read their partitionkey.
+func partitionKey(ctx context.Context, m binding.Message) (string, binding.Message, bool) {
+	m = decodeStructured(ctx, m)
+	r, ok := m.(binding.MessageMetadataReader)
+	if !ok {
+		return "", m, false
+	}
+	v := r.GetExtension(PartitionKeyExtension)
+	if v == nil {
+		return "", m, false
+	}
+	key, err := types.Format(v)
+	if err != nil {
+		return "", m, false
+	}
+	return key, m, true
+}
+
+// priorityDispatchQueueSize is the number of callbacks waiting for a worker
+// before the receiving blocks.
+const priorityDispatchQueueSize = 256
+
+// priorityDispatcher runs the dispatched callbacks on a pool of workers,
+// starting with the callbacks of highest priority and, for equal priorities,
+// in dispatch order.
+type priorityDispatcher struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  priorityQueue
+	seq    uint64
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func newPriorityDispatcher(workers int) *priorityDispatcher {
+	d := &priorityDispatcher{}
+	d.cond = sync.NewCond(&d.mu)
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for {
+				fn, ok := d.next()
+				if !ok {
+					return
+				}
+				fn()
+			}
+		}()
+	}
+	return d
+}
+
+// dispatch queues fn with the given priority, blocking while the queue is
+// full.
+func (d *priorityDispatcher) dispatch(priority int32, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for len(d.queue) >= priorityDispatchQueueSize {
+		d.cond.Wait()
+	}
+	heap.Push(&d.queue, &prioritizedCallback{priority: priority, seq: d.seq, fn: fn})
+	d.seq++
+	d.cond.Broadcast()
+}
+
+// next blocks until a callback is queued, or returns false once the
+// dispatcher is closed and drained.
+func (d *priorityDispatcher) next() (func(), bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for len(d.queue) == 0 {
+		if d.closed {
+			return nil, false
+		}
+		d.cond.Wait()
+	}
+	c := heap.Pop(&d.queue).(*prioritizedCallback)
+	d.cond.Broadcast()
+	return c.fn, true
+}
+
+// close waits for the queued callbacks to complete. dispatch must not be
+// called afterwards.
+func (d *priorityDispatcher) close() {
+	d.mu.Lock()
+	d.closed = true
+	d.cond.Broadcast()
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+type prioritizedCallback struct {
+	priority int32
+	seq      uint64
+	fn       func()
+}
+
+// priorityQueue implements heap.Interface.
+type priorityQueue []*prioritizedCallback
+
+func (q priorityQueue) Len() int { return len(q) }
+
+func (q priorityQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q priorityQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *priorityQueue) Push(x interface{}) { *q = append(*q, x.(*prioritizedCallback)) }
+
+func (q *priorityQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	c := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return c
+}
+
+// priority returns the priority extension of m, along with the message to
+// invoke. Structured messages are decoded to read their priority.
+func priority(ctx context.Context, m binding.Message) (int32, binding.Message) {
+	m = decodeStructured(ctx, m)
+	p, _ := extensions.GetPriorityFromMessage(m)
+	return p, m
+}
+
+// ShardConfig configures each worker group of WithShardedDispatch.
+type ShardConfig struct {
+	// Workers is the number of callbacks of the shard running concurrently.
+	Workers int
+	// QueueSize is the number of callbacks waiting for a worker of the
+	// shard, defaults to 64.
+	QueueSize int
+	// RejectWhenFull NACKs the events of a shard whose queue is full
+	// instead of blocking the receiving of the events of all the shards.
+	RejectWhenFull bool
+}
+
+// defaultShardQueueSize is the default ShardConfig.QueueSize.
+const defaultShardQueueSize = 64
+
+// shardedDispatcher runs the dispatched callbacks on independent groups of
+// workers, selected by a hash of their key.
+type shardedDispatcher struct {
+	attribute      string
+	queues         []chan func()
+	rejectWhenFull bool
+	wg             sync.WaitGroup
+}
+
+func newShardedDispatcher(attribute string, shards int, config ShardConfig) *shardedDispatcher {
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultShardQueueSize
+	}
+	d := &shardedDispatcher{
+		attribute:      attribute,
+		queues:         make([]chan func(), shards),
+		rejectWhenFull: config.RejectWhenFull,
+	}
+	for i := range d.queues {
+		q := make(chan func(), queueSize)
+		d.queues[i] = q
+		for j := 0; j < config.Workers; j++ {
+			d.wg.Add(1)
+			go func() {
+				defer d.wg.Done()
+				for fn := range q {
+					fn()
+				}
+			}()
+		}
+	}
+	return d
+}
+
+// shard returns the index of the shard of key.
+func (d *shardedDispatcher) shard(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(d.queues)))
+}
+
+// dispatch queues fn on the shard of key. It returns false without queuing
+// fn if the queue of the shard is full and full shards reject their events,
+// otherwise it blocks while the queue is full.
+func (d *shardedDispatcher) dispatch(key string, fn func()) bool {
+	q := d.queues[d.shard(key)]
+	if !d.rejectWhenFull {
+		q <- fn
+		return true
+	}
+	select {
+	case q <- fn:
+		return true
+	default:
+		return false
+	}
+}
+
+// close waits for the queued callbacks to complete. dispatch must not be
+// called afterwards.
+func (d *shardedDispatcher) close() {
+	for _, q := range d.queues {
+		close(q)
+	}
+	d.wg.Wait()
+}
+
+// shardKey returns the value of the attribute of m the shards are selected
+// with, empty if m has none, along with the message to invoke. Structured
+// messages are decoded to read their attribute.
+func (d *shardedDispatcher) shardKey(ctx context.Context, m binding.Message) (string, binding.Message) {
+	m = decodeStructured(ctx, m)
+	r, ok := m.(binding.MessageMetadataReader)
+	if !ok {
+		return "", m
+	}
+	var v interface{}
+	if sv := specVersion(r); sv != nil && sv.Attribute(d.attribute) != nil {
+		_, v = r.GetAttribute(sv.Attribute(d.attribute).Kind())
+	} else {
+		v = r.GetExtension(d.attribute)
+	}
+	if v == nil {
+		return "", m
+	}
+	key, err := types.Format(v)
+	if err != nil {
+		return "", m
+	}
+	return key, m
+}
+
+// specVersion returns the spec version of the attributes of r, if known.
+func specVersion(r binding.MessageMetadataReader) spec.Version {
+	_, v := r.GetAttribute(spec.SpecVersion)
+	s, err := types.ToString(v)
+	if err != nil {
+		return nil
+	}
+	return spec.VS.Version(s)
+}
@@ -0,0 +1,261 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestPartitionKey(t *testing.T) {
+	e := test.FullEvent()
+	e.SetExtension(PartitionKeyExtension, "key")
+
+	testCases := map[string]struct {
+		message binding.Message
+		wantKey string
+		wantOK  bool
+	}{
+		"event": {
+			message: binding.ToMessage(&e),
+			wantKey: "key",
+			wantOK:  true,
+		},
+		"binary": {
+			message: bindingtest.MustCreateMockBinaryMessage(e),
+			wantKey: "key",
+			wantOK:  true,
+		},
+		"structured": {
+			message: bindingtest.MustCreateMockStructuredMessage(t, e),
+			wantKey: "key",
+			wantOK:  true,
+		},
+		"no partitionkey": {
+			message: bindingtest.MustCreateMockBinaryMessage(test.FullEvent()),
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			key, m, ok := partitionKey(context.Background(), tc.message)
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.wantKey, key)
+
+			got, err := binding.ToEvent(context.Background(), m)
+			require.NoError(t, err)
+			require.Equal(t, e.ID(), got.ID())
+		})
+	}
+}
+
+func TestOrderedDispatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan binding.Message)
+	c, err := New(gochan.Receiver(ch), WithPollGoroutines(1), WithOrderedDispatch(4))
+	require.NoError(t, err)
+
+	const perKey = 10
+	keys := []string{"a", "b", "c"}
+
+	var mu sync.Mutex
+	got := map[string][]int{}
+	var wg sync.WaitGroup
+	wg.Add(perKey * len(keys))
+	go func() {
+		_ = c.StartReceiver(ctx, func(e event.Event) {
+			defer wg.Done()
+			// Delay the first events, so that they would be overtaken
+			// without ordering.
+			seq, _ := strconv.Atoi(e.ID())
+			time.Sleep(time.Duration(perKey-seq) * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			key := e.Extensions()[PartitionKeyExtension].(string)
+			got[key] = append(got[key], seq)
+		})
+	}()
+
+	for i := 0; i < perKey; i++ {
+		for _, key := range keys {
+			e := test.FullEvent()
+			e.SetID(strconv.Itoa(i))
+			e.SetExtension(PartitionKeyExtension, key)
+			ch <- binding.ToMessage(&e)
+		}
+	}
+	wg.Wait()
+
+	want := make([]int, perKey)
+	for i := range want {
+		want[i] = i
+	}
+	for _, key := range keys {
+		require.Equal(t, want, got[key], key)
+	}
+}
+
+func TestPriorityDispatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan binding.Message)
+	c, err := New(gochan.Receiver(ch), WithPollGoroutines(1), WithPriorityDispatch(1))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	got := make(chan string, 6)
+	go func() {
+		_ = c.StartReceiver(ctx, func(e event.Event) {
+			if e.ID() == "blocker" {
+				close(started)
+				<-release
+			}
+			got <- e.ID()
+		})
+	}()
+
+	send := func(id string, priority int32, setPriority bool) {
+		e := test.FullEvent()
+		e.SetID(id)
+		if setPriority {
+			extensions.AddPriorityExtension(&e, priority)
+		}
+		ch <- binding.ToMessage(&e)
+	}
+
+	// Saturate the only worker, then queue events of various priorities.
+	send("blocker", 0, false)
+	<-started
+	send("low", extensions.PriorityLow, true)
+	send("normal", 0, false)
+	send("high", extensions.PriorityHigh, true)
+	send("normal2", 0, false)
+	// The receiving is sequential, so once the last message is accepted the
+	// previous ones are queued.
+	send("last", extensions.PriorityLow-1, true)
+	close(release)
+
+	want := []string{"blocker", "high", "normal", "normal2", "low", "last"}
+	for _, id := range want {
+		require.Equal(t, id, <-got)
+	}
+}
+
+func TestWithPriorityDispatchNonPositive(t *testing.T) {
+	c := &ceClient{}
+	require.EqualError(t, c.applyOptions(WithPriorityDispatch(-1)), "client option was given a non positive number of priority dispatch workers")
+}
+
+func TestWithOrderedDispatchNonPositive(t *testing.T) {
+	c := &ceClient{}
+	require.EqualError(t, c.applyOptions(WithOrderedDispatch(0)), "client option was given a non positive number of ordered dispatch workers")
+}
+
+func TestShardKey(t *testing.T) {
+	e := test.FullEvent()
+	e.SetExtension("tenant", "acme")
+
+	for name, tc := range map[string]struct {
+		attribute string
+		message   binding.Message
+		want      string
+	}{
+		"attribute":         {attribute: "type", message: bindingtest.MustCreateMockBinaryMessage(e), want: e.Type()},
+		"extension":         {attribute: "tenant", message: bindingtest.MustCreateMockBinaryMessage(e), want: "acme"},
+		"structured":        {attribute: "tenant", message: bindingtest.MustCreateMockStructuredMessage(t, e), want: "acme"},
+		"event":             {attribute: "source", message: binding.ToMessage(&e), want: e.Source()},
+		"missing extension": {attribute: "region", message: binding.ToMessage(&e)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := newShardedDispatcher(tc.attribute, 1, ShardConfig{Workers: 1})
+			defer d.close()
+			key, m := d.shardKey(context.Background(), tc.message)
+			require.Equal(t, tc.want, key)
+
+			got, err := binding.ToEvent(context.Background(), m)
+			require.NoError(t, err)
+			require.Equal(t, e.ID(), got.ID())
+		})
+	}
+}
+
+func TestShardedDispatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Find types of distinct shards.
+	d := newShardedDispatcher("type", 2, ShardConfig{Workers: 1})
+	d.close()
+	noisy, quiet := "noisy", "quiet0"
+	for i := 1; d.shard(noisy) == d.shard(quiet); i++ {
+		quiet = "quiet" + strconv.Itoa(i)
+	}
+
+	ch := make(chan binding.Message)
+	c, err := New(gochan.Receiver(ch), WithPollGoroutines(1), WithShardedDispatch("type", 2, ShardConfig{Workers: 1, QueueSize: 1, RejectWhenFull: true}))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	got := make(chan string, 4)
+	go func() {
+		_ = c.StartReceiver(ctx, func(e event.Event) {
+			if e.ID() == "blocker" {
+				close(started)
+				<-release
+			}
+			got <- e.ID()
+		})
+	}()
+
+	send := func(id, eventType string) chan error {
+		e := test.FullEvent()
+		e.SetID(id)
+		e.SetType(eventType)
+		finished := make(chan error, 1)
+		ch <- binding.WithFinish(binding.ToMessage(&e), func(err error) { finished <- err })
+		return finished
+	}
+
+	// Saturate the worker and the queue of the noisy shard.
+	send("blocker", noisy)
+	<-started
+	send("queued", noisy)
+	rejected := send("rejected", noisy)
+	require.True(t, protocol.IsNACK(<-rejected))
+
+	// The quiet shard is not affected.
+	send("quiet", quiet)
+	require.Equal(t, "quiet", <-got)
+
+	close(release)
+	require.Equal(t, "blocker", <-got)
+	require.Equal(t, "queued", <-got)
+}
+
+func TestWithShardedDispatchInvalid(t *testing.T) {
+	c := &ceClient{}
+	require.EqualError(t, c.applyOptions(WithShardedDispatch("", 1, ShardConfig{Workers: 1})), "client option was given an empty shard attribute")
+	require.EqualError(t, c.applyOptions(WithShardedDispatch("type", 0, ShardConfig{Workers: 1})), "client option was given a non positive number of shards")
+	require.EqualError(t, c.applyOptions(WithShardedDispatch("type", 1, ShardConfig{})), "client option was given a non positive number of workers per shard")
+}
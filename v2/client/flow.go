@@ -0,0 +1,327 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Flow describes the pipeline of a client returned by New: the stages its
+// outbound and inbound events go through, in order, with what they are
+// configured with and how many events they have seen so far.
+type Flow struct {
+	// Protocol is the Go type of the protocol of the client.
+	Protocol string `json:"protocol"`
+	// Capabilities are the protocol interfaces the protocol implements,
+	// e.g. "Sender" or "Receiver".
+	Capabilities []string `json:"capabilities"`
+	// Receiving is true while StartReceiver runs.
+	Receiving bool `json:"receiving"`
+	// Outbound are the stages of Send and Request.
+	Outbound []FlowStage `json:"outbound"`
+	// Inbound are the stages of the events received.
+	Inbound []FlowStage `json:"inbound"`
+}
+
+// FlowStage is a stage of a Flow.
+type FlowStage struct {
+	Name string `json:"name"`
+	// Components are the functions and types making up the stage, in the
+	// order they are applied, e.g. the middlewares.
+	Components []string `json:"components,omitempty"`
+	// Config is the configuration of the stage.
+	Config map[string]interface{} `json:"config,omitempty"`
+	// Counters are the number of events the stage has seen, by outcome,
+	// since the client was created.
+	Counters map[string]uint64 `json:"counters,omitempty"`
+}
+
+// flowStats are the counters of the stages of a client.
+type flowStats struct {
+	sent          atomic.Uint64
+	requested     atomic.Uint64
+	invalid       atomic.Uint64
+	enqueued      atomic.Uint64
+	queueRejected atomic.Uint64
+	delivered     atomic.Uint64
+	failed        atomic.Uint64
+
+	received      atomic.Uint64
+	receiveErrors atomic.Uint64
+	batches       atomic.Uint64
+	shardRejected atomic.Uint64
+	malformed     atomic.Uint64
+	denied        atomic.Uint64
+	invoked       atomic.Uint64
+	acked         atomic.Uint64
+	nacked        atomic.Uint64
+	panicked      atomic.Uint64
+	responses     atomic.Uint64
+
+	// mu guards handler, the type of the fn of the running receiver.
+	mu      sync.Mutex
+	handler string
+}
+
+// delivery counts the result of an outbound event.
+func (s *flowStats) delivery(result protocol.Result) {
+	if protocol.IsACK(result) {
+		s.delivered.Add(1)
+	} else {
+		s.failed.Add(1)
+	}
+}
+
+// handled counts the result of the receiver function.
+func (s *flowStats) handled(result protocol.Result) {
+	if protocol.IsACK(result) {
+		s.acked.Add(1)
+	} else {
+		s.nacked.Add(1)
+	}
+}
+
+func (s *flowStats) setHandler(handler string) {
+	s.mu.Lock()
+	s.handler = handler
+	s.mu.Unlock()
+}
+
+func (s *flowStats) getHandler() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handler
+}
+
+// DescribeFlow returns the Flow of the client c, returned by New. It is safe
+// to call while c sends and receives events, the counters being read one by
+// one rather than as a consistent snapshot.
+func DescribeFlow(c Client) (Flow, error) {
+	cc, ok := c.(*ceClient)
+	if !ok {
+		return Flow{}, errors.New("client is not a client returned by New")
+	}
+	s := &cc.stats
+	handler := s.getHandler()
+	f := Flow{
+		Protocol:     cc.protocolType,
+		Capabilities: cc.capabilities(),
+		Receiving:    handler != "",
+	}
+
+	f.Outbound = append(f.Outbound,
+		FlowStage{Name: "decorate", Components: funcNames(cc.outboundContextDecorators)},
+		FlowStage{Name: "default", Components: funcNames(cc.eventDefaulterFns)},
+		FlowStage{Name: "validate", Counters: map[string]uint64{
+			"sent":      s.sent.Load(),
+			"requested": s.requested.Load(),
+			"invalid":   s.invalid.Load(),
+		}},
+	)
+	if q := cc.outboundQueue; q != nil {
+		f.Outbound = append(f.Outbound, FlowStage{
+			Name:       "queue",
+			Components: []string{fmt.Sprintf("%T", q)},
+			Config: map[string]interface{}{
+				"capacity": cap(q.queue),
+				"workers":  cc.outboundWorkers,
+				"policy":   overflowPolicyNames[q.policy],
+			},
+			Counters: map[string]uint64{
+				"depth":    uint64(len(q.queue)),
+				"enqueued": s.enqueued.Load(),
+				"rejected": s.queueRejected.Load(),
+			},
+		})
+	}
+	f.Outbound = append(f.Outbound, FlowStage{
+		Name:       "send",
+		Components: typeNames(cc.sender, cc.requester),
+		Counters: map[string]uint64{
+			"delivered": s.delivered.Load(),
+			"failed":    s.failed.Load(),
+		},
+	})
+
+	receive := FlowStage{
+		Name:       "receive",
+		Components: typeNames(cc.receiver, cc.responder, cc.batchReceiver),
+		Config:     map[string]interface{}{"pollGoroutines": cc.pollGoroutines},
+		Counters: map[string]uint64{
+			"received": s.received.Load(),
+			"errors":   s.receiveErrors.Load(),
+			"batches":  s.batches.Load(),
+		},
+	}
+	if cc.inflightHigh > 0 {
+		receive.Config["inflightHigh"] = cc.inflightHigh
+		receive.Config["inflightLow"] = cc.inflightLow
+	}
+	dispatch := FlowStage{Name: "dispatch", Config: map[string]interface{}{"mode": cc.dispatchMode()}}
+	switch {
+	case cc.orderedDispatchWorkers > 0:
+		dispatch.Config["workers"] = cc.orderedDispatchWorkers
+	case cc.shards > 0:
+		dispatch.Config["attribute"] = cc.shardAttribute
+		dispatch.Config["shards"] = cc.shards
+		dispatch.Config["workers"] = cc.shardConfig.Workers
+		dispatch.Counters = map[string]uint64{"rejected": s.shardRejected.Load()}
+	case cc.priorityDispatchWorkers > 0:
+		dispatch.Config["workers"] = cc.priorityDispatchWorkers
+	}
+	convert := FlowStage{
+		Name:     "convert",
+		Config:   map[string]interface{}{"ackMalformed": cc.ackMalformedEvent},
+		Counters: map[string]uint64{"malformed": s.malformed.Load()},
+	}
+	if cc.quarantineStore != nil {
+		convert.Components = typeNames(cc.quarantineStore)
+	}
+	f.Inbound = append(f.Inbound,
+		receive,
+		dispatch,
+		FlowStage{Name: "decorate", Components: funcNames(cc.inboundContextDecorators)},
+		convert,
+	)
+	if cc.authorizer != nil {
+		f.Inbound = append(f.Inbound, FlowStage{
+			Name:       "authorize",
+			Components: typeNames(cc.authorizer),
+			Counters:   map[string]uint64{"denied": s.denied.Load()},
+		})
+	}
+	if len(cc.middlewares) > 0 {
+		f.Inbound = append(f.Inbound, FlowStage{Name: "middleware", Components: funcNames(cc.middlewares)})
+	}
+	handle := FlowStage{
+		Name: "handle",
+		Counters: map[string]uint64{
+			"invoked":   s.invoked.Load(),
+			"acked":     s.acked.Load(),
+			"nacked":    s.nacked.Load(),
+			"panicked":  s.panicked.Load(),
+			"responses": s.responses.Load(),
+		},
+	}
+	if handler != "" {
+		handle.Components = []string{handler}
+	}
+	if cc.handlerTimeout > 0 {
+		handle.Config = map[string]interface{}{"timeout": cc.handlerTimeout.String()}
+	}
+	f.Inbound = append(f.Inbound, handle)
+	return f, nil
+}
+
+// FlowHandler returns an http.Handler serving the Flow of the client c,
+// returned by New, as JSON, to be mounted on a debug endpoint, e.g.
+// /debug/cloudevents/flow. It is not mounted by default as the flow discloses
+// the configuration of the client.
+func FlowHandler(c Client) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		f, err := DescribeFlow(c)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(f)
+	})
+}
+
+// overflowPolicyNames are the names of the OverflowPolicy of the queue stage.
+var overflowPolicyNames = map[OverflowPolicy]string{
+	OverflowBlock:      "block",
+	OverflowDropOldest: "dropOldest",
+	OverflowError:      "error",
+}
+
+// capabilities returns the names of the protocol interfaces of the client.
+func (c *ceClient) capabilities() []string {
+	var caps []string
+	for _, capability := range []struct {
+		name string
+		set  bool
+	}{
+		{"Sender", c.sender != nil},
+		{"Requester", c.requester != nil},
+		{"Receiver", c.receiver != nil},
+		{"Responder", c.responder != nil},
+		{"BatchReceiver", c.batchReceiver != nil},
+		{"Opener", c.opener != nil},
+		{"Pinger", c.pinger != nil},
+	} {
+		if capability.set {
+			caps = append(caps, capability.name)
+		}
+	}
+	return caps
+}
+
+// dispatchMode returns how StartReceiver dispatches the received events to
+// the invoker.
+func (c *ceClient) dispatchMode() string {
+	switch {
+	case c.orderedDispatchWorkers > 0:
+		return "ordered"
+	case c.shards > 0:
+		return "sharded"
+	case c.priorityDispatchWorkers > 0:
+		return "priority"
+	case c.blockingCallback:
+		return "blocking"
+	default:
+		return "concurrent"
+	}
+}
+
+// funcNames returns the names of the functions of fns, a slice of functions.
+func funcNames(fns interface{}) []string {
+	v := reflect.ValueOf(fns)
+	names := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		name := "<nil>"
+		if fn := v.Index(i); !fn.IsNil() {
+			name = runtime.FuncForPC(fn.Pointer()).Name()
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// typeNames returns the distinct types of the non-nil values, in order.
+func typeNames(values ...interface{}) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, v := range values {
+		rv := reflect.ValueOf(v)
+		if v == nil || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+			continue
+		}
+		name := fmt.Sprintf("%T", v)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
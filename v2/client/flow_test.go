@@ -0,0 +1,150 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func flowTestMiddleware(next Handler) Handler {
+	return next
+}
+
+func stage(t *testing.T, stages []FlowStage, name string) FlowStage {
+	t.Helper()
+	for _, s := range stages {
+		if s.Name == name {
+			return s
+		}
+	}
+	require.Failf(t, "missing stage", "no stage %q in %v", name, stages)
+	return FlowStage{}
+}
+
+func TestDescribeFlow(t *testing.T) {
+	c, err := New(gochan.New(), WithMiddleware(flowTestMiddleware), WithBlockingCallback())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f, err := DescribeFlow(c)
+	require.NoError(t, err)
+	require.Equal(t, "*gochan.SendReceiver", f.Protocol)
+	require.Equal(t, []string{"Sender", "Receiver"}, f.Capabilities)
+	require.False(t, f.Receiving)
+	require.Equal(t, []string{"decorate", "default", "validate", "send"}, stageNames(f.Outbound))
+	require.Equal(t, []string{"receive", "dispatch", "decorate", "convert", "middleware", "handle"}, stageNames(f.Inbound))
+	require.Equal(t, "blocking", stage(t, f.Inbound, "dispatch").Config["mode"])
+	require.Equal(t, []string{"github.com/cloudevents/sdk-go/v2/client.flowTestMiddleware"}, stage(t, f.Inbound, "middleware").Components)
+
+	for _, id := range []string{"1", "nack", "3"} {
+		e := test.FullEvent()
+		e.SetID(id)
+		require.NoError(t, c.Send(ctx, e))
+	}
+	require.Error(t, c.Send(ctx, event.New()))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = c.StartReceiver(ctx, func(e event.Event) protocol.Result {
+			if e.ID() == "nack" {
+				return protocol.ResultNACK
+			}
+			return nil
+		})
+	}()
+	require.Eventually(t, func() bool {
+		f, err = DescribeFlow(c)
+		require.NoError(t, err)
+		return stage(t, f.Inbound, "handle").Counters["invoked"] == 3
+	}, time.Second, 10*time.Millisecond)
+
+	require.True(t, f.Receiving)
+	require.Equal(t, map[string]uint64{"sent": 4, "requested": 0, "invalid": 1}, stage(t, f.Outbound, "validate").Counters)
+	require.Equal(t, map[string]uint64{"delivered": 3, "failed": 0}, stage(t, f.Outbound, "send").Counters)
+	require.Equal(t, uint64(3), stage(t, f.Inbound, "receive").Counters["received"])
+	handle := stage(t, f.Inbound, "handle")
+	require.Equal(t, []string{"func(event.Event) protocol.Result"}, handle.Components)
+	require.Equal(t, map[string]uint64{"invoked": 3, "acked": 2, "nacked": 1, "panicked": 0, "responses": 0}, handle.Counters)
+
+	cancel()
+	wg.Wait()
+	f, err = DescribeFlow(c)
+	require.NoError(t, err)
+	require.False(t, f.Receiving)
+	require.Empty(t, stage(t, f.Inbound, "handle").Components)
+}
+
+func TestDescribeFlowOptions(t *testing.T) {
+	q, err := NewOutboundQueue(10, OverflowError)
+	require.NoError(t, err)
+	c, err := New(gochan.New(),
+		WithOutboundQueue(q, 2),
+		WithShardedDispatch("subject", 4, ShardConfig{Workers: 1}),
+		WithAuthorizer(AuthorizerFunc(func(context.Context, event.Event, protocol.TransportMetadata) Decision { return Allow() })),
+		WithHandlerTimeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	f, err := DescribeFlow(c)
+	require.NoError(t, err)
+	queue := stage(t, f.Outbound, "queue")
+	require.Equal(t, map[string]interface{}{"capacity": 10, "workers": 2, "policy": "error"}, queue.Config)
+	dispatch := stage(t, f.Inbound, "dispatch")
+	require.Equal(t, map[string]interface{}{"mode": "sharded", "attribute": "subject", "shards": 4, "workers": 1}, dispatch.Config)
+	require.Equal(t, []string{"client.AuthorizerFunc"}, stage(t, f.Inbound, "authorize").Components)
+	require.Equal(t, "1s", stage(t, f.Inbound, "handle").Config["timeout"])
+}
+
+func TestDescribeFlowNotCeClient(t *testing.T) {
+	_, err := DescribeFlow(nil)
+	require.Error(t, err)
+}
+
+func TestFlowHandler(t *testing.T) {
+	c, err := New(gochan.New())
+	require.NoError(t, err)
+	server := httptest.NewServer(FlowHandler(c))
+	defer server.Close()
+
+	resp, err := nethttp.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, nethttp.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var f Flow
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&f))
+	require.Equal(t, "*gochan.SendReceiver", f.Protocol)
+	require.Equal(t, uint64(0), stage(t, f.Outbound, "send").Counters["delivered"])
+
+	resp, err = nethttp.Post(server.URL, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, nethttp.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func stageNames(stages []FlowStage) []string {
+	names := make([]string, 0, len(stages))
+	for _, s := range stages {
+		names = append(names, s.Name)
+	}
+	return names
+}
@@ -14,7 +14,7 @@ import (
 )
 
 func NewHTTPReceiveHandler(ctx context.Context, p *thttp.Protocol, fn interface{}) (*EventReceiver, error) {
-	invoker, err := newReceiveInvoker(fn, noopObservabilityService{}, nil, nil, false) //TODO(slinkydeveloper) maybe not nil?
+	invoker, err := newReceiveInvoker(fn, invokerConfig{}) //TODO(slinkydeveloper) maybe not nil?
 	if err != nil {
 		return nil, err
 	}
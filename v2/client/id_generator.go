@@ -0,0 +1,192 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// IDGenerator generates the IDs of the outbound events without ID, see
+// WithIDGenerator. Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc is an IDGenerator calling the function.
+type IDGeneratorFunc func() string
+
+// NewID implements IDGenerator.NewID.
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// NewDefaultIDIfNotSet returns a defaulter setting the ID of the events found
+// without ID to an ID of gen.
+func NewDefaultIDIfNotSet(gen IDGenerator) EventDefaulter {
+	return func(ctx context.Context, event event.Event) event.Event {
+		if event.Context != nil {
+			if event.ID() == "" {
+				event.Context = event.Context.Clone()
+				event.SetID(gen.NewID())
+			}
+		}
+		return event
+	}
+}
+
+// UUIDv4Generator generates random UUIDs, as WithUUIDs does.
+type UUIDv4Generator struct{}
+
+// NewID implements IDGenerator.NewID.
+func (UUIDv4Generator) NewID() string {
+	return uuid.New().String()
+}
+
+// UUIDv7Generator generates time-ordered UUIDs, as defined by RFC 9562: the
+// IDs generated by a process sort in generation order.
+type UUIDv7Generator struct{}
+
+// NewID implements IDGenerator.NewID.
+func (UUIDv7Generator) NewID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// crockford is the alphabet of the ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator generates ULIDs (https://github.com/ulid/spec): 26 characters
+// sorting in generation order, made of a millisecond timestamp and 80 random
+// bits. The IDs generated within the same millisecond increment the random
+// bits, so that they are ordered too.
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	entropy [10]byte
+	now     func() time.Time
+}
+
+// NewULIDGenerator returns a ULIDGenerator.
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{now: time.Now}
+}
+
+// NewID implements IDGenerator.NewID.
+func (g *ULIDGenerator) NewID() string {
+	g.mu.Lock()
+	ms := uint64(g.now().UnixMilli())
+	if ms > g.lastMs {
+		g.lastMs = ms
+		if _, err := rand.Read(g.entropy[:]); err != nil {
+			panic(fmt.Sprintf("failed to read random bytes: %v", err))
+		}
+	} else {
+		// Increment the entropy, a clock going backwards keeps the last
+		// timestamp to preserve the ordering.
+		for i := len(g.entropy) - 1; i >= 0; i-- {
+			g.entropy[i]++
+			if g.entropy[i] != 0 {
+				break
+			}
+		}
+	}
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[0:2], uint16(g.lastMs>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(g.lastMs))
+	copy(id[6:], g.entropy[:])
+	g.mu.Unlock()
+	return encodeULID(id)
+}
+
+// encodeULID encodes the 128 bits of id in 26 base32 characters, the first
+// one holding the 3 most significant bits.
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[0:8])
+	lo := binary.BigEndian.Uint64(id[8:16])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+const (
+	// SnowflakeEpoch is the epoch of the timestamps of the snowflake IDs,
+	// 2010-11-04T01:42:54.657Z as in the original implementation.
+	SnowflakeEpoch int64 = 1288834974657
+
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	// SnowflakeMaxNode is the highest node of a SnowflakeGenerator.
+	SnowflakeMaxNode = 1<<snowflakeNodeBits - 1
+)
+
+// SnowflakeGenerator generates snowflake IDs: 64-bit integers, formatted in
+// decimal, made of a millisecond timestamp, the node generating the ID and a
+// sequence number. The IDs sort in generation order, provided each process
+// uses a distinct node. Up to 4096 IDs are generated per millisecond.
+type SnowflakeGenerator struct {
+	node int64
+
+	mu       sync.Mutex
+	lastMs   int64
+	sequence int64
+	now      func() time.Time
+}
+
+// NewSnowflakeGenerator returns a SnowflakeGenerator for the given node,
+// between 0 and SnowflakeMaxNode.
+func NewSnowflakeGenerator(node int64) (*SnowflakeGenerator, error) {
+	if node < 0 || node > SnowflakeMaxNode {
+		return nil, fmt.Errorf("snowflake node must be between 0 and %d, got %d", SnowflakeMaxNode, node)
+	}
+	return &SnowflakeGenerator{node: node, now: time.Now}, nil
+}
+
+// NewID implements IDGenerator.NewID.
+func (g *SnowflakeGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().UnixMilli() - SnowflakeEpoch
+	if ms < g.lastMs {
+		// The clock went backwards, keep the last timestamp.
+		ms = g.lastMs
+	}
+	if ms == g.lastMs {
+		g.sequence = (g.sequence + 1) & (1<<snowflakeSequenceBits - 1)
+		if g.sequence == 0 {
+			// The sequence is exhausted, wait for the next millisecond.
+			for ms <= g.lastMs {
+				time.Sleep(100 * time.Microsecond)
+				ms = g.now().UnixMilli() - SnowflakeEpoch
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMs = ms
+	id := ms<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | g.sequence
+	return strconv.FormatInt(id, 10)
+}
+
+var (
+	_ IDGenerator = UUIDv4Generator{}
+	_ IDGenerator = UUIDv7Generator{}
+	_ IDGenerator = (*ULIDGenerator)(nil)
+	_ IDGenerator = (*SnowflakeGenerator)(nil)
+)
@@ -0,0 +1,116 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func generate(gen IDGenerator, n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = gen.NewID()
+	}
+	return ids
+}
+
+func requireSortedUnique(t *testing.T, ids []string) {
+	require.True(t, sort.StringsAreSorted(ids), ids)
+	seen := map[string]struct{}{}
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	require.Len(t, seen, len(ids))
+}
+
+func TestUUIDGenerators(t *testing.T) {
+	v4, err := uuid.Parse(UUIDv4Generator{}.NewID())
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(4), v4.Version())
+
+	ids := generate(UUIDv7Generator{}, 100)
+	v7, err := uuid.Parse(ids[0])
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(7), v7.Version())
+	requireSortedUnique(t, ids)
+}
+
+func TestULIDGenerator(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	g := NewULIDGenerator()
+	g.now = func() time.Time { return now }
+
+	// Within the same millisecond, and when the clock goes backwards.
+	ids := generate(g, 50)
+	now = now.Add(-time.Second)
+	ids = append(ids, generate(g, 50)...)
+	now = now.Add(time.Hour)
+	ids = append(ids, generate(g, 50)...)
+
+	for _, id := range ids {
+		require.Len(t, id, 26)
+	}
+	requireSortedUnique(t, ids)
+	require.Equal(t, "01HF7YAT00", ids[0][:10])
+}
+
+func TestEncodeULID(t *testing.T) {
+	var max [16]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	require.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeULID(max))
+	require.Equal(t, "00000000000000000000000000", encodeULID([16]byte{}))
+}
+
+func TestSnowflakeGenerator(t *testing.T) {
+	var elapsed atomic.Int64
+	g, err := NewSnowflakeGenerator(5)
+	require.NoError(t, err)
+	g.now = func() time.Time { return time.UnixMilli(SnowflakeEpoch + 1000 + elapsed.Load()) }
+
+	id, err := strconv.ParseInt(g.NewID(), 10, 64)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000)<<22|5<<12, id)
+
+	// The sequence is exhausted, the next millisecond is awaited.
+	ids := generate(g, 4095)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		elapsed.Store(1)
+	}()
+	next := g.NewID()
+	id, err = strconv.ParseInt(next, 10, 64)
+	require.NoError(t, err)
+	require.Equal(t, int64(1001)<<22|5<<12, id)
+	requireSortedUnique(t, append(ids, next))
+
+	_, err = NewSnowflakeGenerator(SnowflakeMaxNode + 1)
+	require.EqualError(t, err, "snowflake node must be between 0 and 1023, got 1024")
+}
+
+func TestWithIDGenerator(t *testing.T) {
+	c := &ceClient{}
+	require.NoError(t, c.applyOptions(WithIDGenerator(IDGeneratorFunc(func() string { return "generated" }))))
+	require.Len(t, c.eventDefaulterFns, 1)
+
+	e := event.New()
+	require.Equal(t, "generated", c.eventDefaulterFns[0](context.Background(), e).ID())
+	e.SetID("set")
+	require.Equal(t, "set", c.eventDefaulterFns[0](context.Background(), e).ID())
+
+	require.EqualError(t, c.applyOptions(WithIDGenerator(nil)), "client option was given an nil id generator")
+}
@@ -8,11 +8,14 @@ package client
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
+	"time"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/quarantine"
 )
 
 type Invoker interface {
@@ -23,35 +26,57 @@ type Invoker interface {
 
 var _ Invoker = (*receiveInvoker)(nil)
 
-func newReceiveInvoker(
-	fn interface{},
-	observabilityService ObservabilityService,
-	inboundContextDecorators []func(context.Context, binding.Message) context.Context,
-	fns []EventDefaulter,
-	ackMalformedEvent bool,
-) (Invoker, error) {
-	r := &receiveInvoker{
-		eventDefaulterFns:        fns,
-		observabilityService:     observabilityService,
-		inboundContextDecorators: inboundContextDecorators,
-		ackMalformedEvent:        ackMalformedEvent,
+// PanicHandler is invoked when the receiver function (or one of its
+// middlewares) panics, with the recovered value and the stack trace of the
+// panicking goroutine. The returned result is reported to the protocol in
+// place of the receiver result: for example protocol.ResultNACK to have the
+// message redelivered, or protocol.ResultACK after dead-lettering the event.
+// The event is empty if the message could not be converted to an event.
+type PanicHandler func(ctx context.Context, e event.Event, recovered interface{}, stack []byte) protocol.Result
+
+// invokerConfig configures a receiveInvoker with the options of the client.
+// Its zero value is valid.
+type invokerConfig struct {
+	observabilityService     ObservabilityService
+	inboundContextDecorators []func(context.Context, binding.Message) context.Context
+	eventDefaulterFns        []EventDefaulter
+	middlewares              []Middleware
+	authorizer               Authorizer
+	panicHandler             PanicHandler
+	handlerTimeout           time.Duration
+	ackMalformedEvent        bool
+	redeliveryExtensions     bool
+	quarantineStore          quarantine.Store
+	stats                    *flowStats
+}
+
+func newReceiveInvoker(fn interface{}, config invokerConfig) (Invoker, error) {
+	if config.observabilityService == nil {
+		config.observabilityService = noopObservabilityService{}
 	}
+	if config.stats == nil {
+		config.stats = &flowStats{}
+	}
+	r := &receiveInvoker{invokerConfig: config}
 
 	if fn, err := receiver(fn); err != nil {
 		return nil, err
 	} else {
 		r.fn = fn
 	}
+	if len(config.middlewares) > 0 {
+		r.handler = Chain(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			return r.invokeFn(ctx, &e)
+		}, config.middlewares...)
+	}
 
 	return r, nil
 }
 
 type receiveInvoker struct {
-	fn                       *receiverFn
-	observabilityService     ObservabilityService
-	eventDefaulterFns        []EventDefaulter
-	inboundContextDecorators []func(context.Context, binding.Message) context.Context
-	ackMalformedEvent        bool
+	invokerConfig
+	fn      *receiverFn
+	handler Handler
 }
 
 func (r *receiveInvoker) Invoke(ctx context.Context, m binding.Message, respFn protocol.ResponseFn) (err error) {
@@ -62,17 +87,25 @@ func (r *receiveInvoker) Invoke(ctx context.Context, m binding.Message, respFn p
 	var respMsg binding.Message
 	var result protocol.Result
 
-	e, eventErr := binding.ToEvent(ctx, m)
+	em, entry := captureForQuarantine(ctx, r.quarantineStore, m)
+	e, eventErr := binding.ToEvent(ctx, em)
 	switch {
-	case eventErr != nil && r.fn.hasEventIn:
+	case eventErr != nil && (r.fn.hasEventIn || r.handler != nil || r.authorizer != nil):
+		r.stats.malformed.Add(1)
 		r.observabilityService.RecordReceivedMalformedEvent(ctx, eventErr)
-		return respFn(ctx, nil, protocol.NewReceipt(r.ackMalformedEvent, "failed to convert Message to Event: %w", eventErr))
+		malformed := protocol.NewReceipt(r.ackMalformedEvent, "failed to convert Message to Event: %w", eventErr)
+		return respFn(ctx, nil, quarantineMalformed(ctx, r.quarantineStore, entry, eventErr, malformed))
 	case r.fn != nil:
 		// Check if event is valid before invoking the receiver function
 		if e != nil {
 			if validationErr := e.Validate(); validationErr != nil {
+				r.stats.malformed.Add(1)
 				r.observabilityService.RecordReceivedMalformedEvent(ctx, validationErr)
-				return respFn(ctx, nil, protocol.NewReceipt(r.ackMalformedEvent, "validation error in incoming event: %w", validationErr))
+				malformed := protocol.NewReceipt(r.ackMalformedEvent, "validation error in incoming event: %w", validationErr)
+				return respFn(ctx, nil, quarantineMalformed(ctx, r.quarantineStore, entry, validationErr, malformed))
+			}
+			if r.redeliveryExtensions {
+				addRedeliveryExtensions(m, e)
 			}
 		}
 
@@ -80,17 +113,47 @@ func (r *receiveInvoker) Invoke(ctx context.Context, m binding.Message, respFn p
 		var resp *event.Event
 		resp, result = func() (resp *event.Event, result protocol.Result) {
 			defer func() {
-				if r := recover(); r != nil {
-					result = fmt.Errorf("call to Invoker.Invoke(...) has panicked: %v", r)
+				if rec := recover(); rec != nil {
+					r.stats.panicked.Add(1)
+					var stack []byte
+					if hp, ok := rec.(*handlerPanic); ok {
+						rec, stack = hp.value, hp.stack
+					} else {
+						stack = debug.Stack()
+					}
+					result = fmt.Errorf("call to Invoker.Invoke(...) has panicked: %v", rec)
 					cecontext.LoggerFrom(ctx).Error(result)
+					if r.panicHandler != nil {
+						var panicked event.Event
+						if e != nil {
+							panicked = *e
+						}
+						result = r.panicHandler(ctx, panicked, rec, stack)
+					}
 				}
 			}()
 			ctx = computeInboundContext(m, ctx, r.inboundContextDecorators)
 
+			if r.authorizer != nil {
+				if denied := authorize(ctx, r.authorizer, m, *e); denied != nil {
+					r.stats.denied.Add(1)
+					return nil, denied
+				}
+			}
+
 			var cb func(error)
 			ctx, cb = r.observabilityService.RecordCallingInvoker(ctx, e)
 
-			resp, result = r.fn.invoke(ctx, e)
+			r.stats.invoked.Add(1)
+			if r.handler != nil {
+				resp, result = r.handler(ctx, *e)
+			} else {
+				resp, result = r.invokeFn(ctx, e)
+			}
+			r.stats.handled(result)
+			if resp != nil {
+				r.stats.responses.Add(1)
+			}
 			defer cb(result)
 			return
 		}()
@@ -125,6 +188,51 @@ func (r *receiveInvoker) Invoke(ctx context.Context, m binding.Message, respFn p
 	return respFn(ctx, respMsg, result)
 }
 
+// handlerPanic carries a panic of a receiver function invoked with a timeout
+// back to the invoking goroutine, along with the stack where it happened.
+type handlerPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// invokeFn invokes the receiver function. When a handler timeout is set, the
+// function context is cancelled once it expires, and invokeFn returns a NACK
+// without waiting for the function to return.
+func (r *receiveInvoker) invokeFn(ctx context.Context, e *event.Event) (*event.Event, protocol.Result) {
+	if r.handlerTimeout <= 0 {
+		return r.fn.invoke(ctx, e)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.handlerTimeout)
+	defer cancel()
+
+	type returned struct {
+		resp   *event.Event
+		result protocol.Result
+		panic  *handlerPanic
+	}
+	done := make(chan returned, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- returned{panic: &handlerPanic{value: rec, stack: debug.Stack()}}
+			}
+		}()
+		resp, result := r.fn.invoke(ctx, e)
+		done <- returned{resp: resp, result: result}
+	}()
+
+	select {
+	case ret := <-done:
+		if ret.panic != nil {
+			panic(ret.panic)
+		}
+		return ret.resp, ret.result
+	case <-ctx.Done():
+		return nil, protocol.NewReceipt(false, "receiver function did not return within %v: %w", r.handlerTimeout, ctx.Err())
+	}
+}
+
 func (r *receiveInvoker) IsReceiver() bool {
 	return !r.fn.hasEventOut
 }
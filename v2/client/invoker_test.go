@@ -0,0 +1,164 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestPanicHandler(t *testing.T) {
+	e := test.FullEvent()
+	panicking := func(ctx context.Context, e event.Event) {
+		panic("boom")
+	}
+
+	testCases := map[string]struct {
+		handler   PanicHandler
+		wantACK   bool
+		wantNACK  bool
+		wantPanic bool
+	}{
+		"no handler": {},
+		"nack": {
+			handler: func(ctx context.Context, e event.Event, recovered interface{}, stack []byte) protocol.Result {
+				return protocol.ResultNACK
+			},
+			wantNACK:  true,
+			wantPanic: true,
+		},
+		"ack": {
+			handler: func(ctx context.Context, e event.Event, recovered interface{}, stack []byte) protocol.Result {
+				return protocol.ResultACK
+			},
+			wantACK:   true,
+			wantPanic: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var gotEvent event.Event
+			var gotPanic interface{}
+			var gotStack []byte
+			c := &ceClient{}
+			if tc.handler != nil {
+				require.NoError(t, c.applyOptions(WithPanicHandler(func(ctx context.Context, e event.Event, recovered interface{}, stack []byte) protocol.Result {
+					gotEvent, gotPanic, gotStack = e, recovered, stack
+					return tc.handler(ctx, e, recovered, stack)
+				})))
+			}
+
+			invoker, err := newReceiveInvoker(panicking, invokerConfig{panicHandler: c.panicHandler})
+			require.NoError(t, err)
+
+			var result error
+			m := binding.WithFinish(binding.ToMessage(&e), func(err error) { result = err })
+			require.NoError(t, invoker.Invoke(context.Background(), m, noRespFn))
+			require.Error(t, result)
+			require.Equal(t, tc.wantACK, protocol.IsACK(result), "result: %v", result)
+			require.Equal(t, tc.wantNACK, protocol.IsNACK(result), "result: %v", result)
+			if tc.wantPanic {
+				require.Equal(t, "boom", gotPanic)
+				require.Equal(t, e.ID(), gotEvent.ID())
+				require.Contains(t, string(gotStack), "panic")
+			}
+		})
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	e := test.FullEvent()
+
+	testCases := map[string]struct {
+		fn          func(ctx context.Context, e event.Event) protocol.Result
+		middlewares []Middleware
+		wantACK     bool
+		wantTimeout bool
+		wantPanic   bool
+	}{
+		"returns in time": {
+			fn: func(ctx context.Context, e event.Event) protocol.Result {
+				return protocol.ResultACK
+			},
+			wantACK: true,
+		},
+		"expires": {
+			fn: func(ctx context.Context, e event.Event) protocol.Result {
+				<-ctx.Done()
+				time.Sleep(time.Second)
+				return protocol.ResultACK
+			},
+			wantTimeout: true,
+		},
+		"middleware sees expiry": {
+			fn: func(ctx context.Context, e event.Event) protocol.Result {
+				<-ctx.Done()
+				return protocol.ResultACK
+			},
+			middlewares: []Middleware{func(next Handler) Handler {
+				return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+					resp, result := next(ctx, e)
+					if errors.Is(result, context.DeadlineExceeded) {
+						// Dead-lettered.
+						return nil, protocol.ResultACK
+					}
+					return resp, result
+				}
+			}},
+			wantACK: true,
+		},
+		"panics": {
+			fn: func(ctx context.Context, e event.Event) protocol.Result {
+				panic("boom")
+			},
+			wantPanic: true,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var gotStack []byte
+			panicHandler := func(ctx context.Context, e event.Event, recovered interface{}, stack []byte) protocol.Result {
+				gotStack = stack
+				return protocol.ResultNACK
+			}
+			invoker, err := newReceiveInvoker(tc.fn, invokerConfig{middlewares: tc.middlewares, panicHandler: panicHandler, handlerTimeout: 10 * time.Millisecond})
+			require.NoError(t, err)
+
+			var result error
+			m := binding.WithFinish(binding.ToMessage(&e), func(err error) { result = err })
+			start := time.Now()
+			require.NoError(t, invoker.Invoke(context.Background(), m, noRespFn))
+			require.Less(t, time.Since(start), 500*time.Millisecond)
+			require.Equal(t, tc.wantACK, protocol.IsACK(result), "result: %v", result)
+			require.Equal(t, tc.wantTimeout, errors.Is(result, context.DeadlineExceeded), "result: %v", result)
+			if tc.wantPanic {
+				// The stack is the one of the receiver function.
+				require.Contains(t, string(gotStack), "TestHandlerTimeout")
+			}
+		})
+	}
+}
+
+func TestWithHandlerTimeout(t *testing.T) {
+	c := &ceClient{}
+	require.EqualError(t, c.applyOptions(WithHandlerTimeout(0)), "client option was given a non positive handler timeout")
+	require.NoError(t, c.applyOptions(WithHandlerTimeout(time.Second)))
+	require.Equal(t, time.Second, c.handlerTimeout)
+}
+
+func TestWithPanicHandlerNil(t *testing.T) {
+	c := &ceClient{}
+	require.EqualError(t, c.applyOptions(WithPanicHandler(nil)), "client option was given an nil panic handler")
+}
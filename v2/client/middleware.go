@@ -0,0 +1,30 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Handler is the receiver function given to Client.StartReceiver, adapted to
+// a single signature. The returned event is nil if there is no response.
+type Handler func(ctx context.Context, e event.Event) (*event.Event, protocol.Result)
+
+// Middleware wraps a Handler with additional behavior. A Middleware can
+// short-circuit the chain by returning without invoking next.
+type Middleware func(next Handler) Handler
+
+// Chain wraps h with the given middlewares. The first middleware is the
+// outermost one, i.e. it is invoked first.
+func Chain(h Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
@@ -0,0 +1,103 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// ErrForeignDataRef is returned by ClaimCheckStore.Get for a dataref which
+// does not reference data of the store.
+var ErrForeignDataRef = errors.New("dataref does not reference data of the claim-check store")
+
+// ClaimCheckStore stores the data offloaded from events, referenced by the
+// dataref extension of the events (claim-check pattern). Implementations must
+// be safe for concurrent use. The github.com/cloudevents/sdk-go/blobstore/v2
+// module provides implementations backed by S3, GCS and Azure Blob Storage.
+type ClaimCheckStore interface {
+	// Put stores data under key and returns the URI referencing it.
+	Put(ctx context.Context, key string, data []byte) (string, error)
+	// Get returns the data referenced by dataref, or ErrForeignDataRef if
+	// dataref does not reference data of the store.
+	Get(ctx context.Context, dataref string) ([]byte, error)
+}
+
+// ClaimCheckKey returns the key the data of e is stored under:
+// <yyyy>/<mm>/<dd>/<hash>, where the date is the time of e, or the current
+// date if e has none, and the hash is a hash of its DedupKey. The date
+// prefixes let stores expire the data with lifecycle rules, and the hash
+// makes the key valid in any store.
+func ClaimCheckKey(e event.Event) string {
+	t := e.Time()
+	if t.IsZero() {
+		t = time.Now()
+	}
+	sum := sha256.Sum256([]byte(DedupKey(e)))
+	return t.UTC().Format("2006/01/02/") + hex.EncodeToString(sum[:])
+}
+
+// OffloadData moves the data of e to store if it is larger than threshold
+// bytes, replacing it with a dataref extension. The datacontenttype of e is
+// kept, so that the data can be decoded once resolved. It reports whether the
+// data was offloaded.
+func OffloadData(ctx context.Context, store ClaimCheckStore, e *event.Event, threshold int) (bool, error) {
+	if len(e.Data()) <= threshold {
+		return false, nil
+	}
+	dataref, err := store.Put(ctx, ClaimCheckKey(*e), e.Data())
+	if err != nil {
+		return false, fmt.Errorf("failed to offload event data: %w", err)
+	}
+	if err := extensions.AddDataRefExtension(e, dataref); err != nil {
+		return false, err
+	}
+	e.DataEncoded = nil
+	e.DataBase64 = false
+	return true, nil
+}
+
+// ClaimCheck returns a middleware resolving the dataref of the received events
+// without data, so that the receiver function gets the offloaded data. Events
+// whose dataref does not reference data of store are passed as-is. If the data
+// cannot be fetched, the event is not acknowledged.
+func ClaimCheck(store ClaimCheckStore) client.Middleware {
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			v, ok := e.Extensions()[extensions.DataRefExtensionKey]
+			if !ok || len(e.Data()) > 0 {
+				return next(ctx, e)
+			}
+			dataref, err := types.ToString(v)
+			if err != nil {
+				return nil, protocol.NewReceipt(false, "invalid dataref: %w", err)
+			}
+			data, err := store.Get(ctx, dataref)
+			if errors.Is(err, ErrForeignDataRef) {
+				return next(ctx, e)
+			}
+			if err != nil {
+				return nil, protocol.NewReceipt(false, "failed to fetch data from claim-check store: %w", err)
+			}
+			cecontext.LoggerFrom(ctx).Debugw("resolved claim-check data", zap.String("dataref", dataref), zap.Int("size", len(data)))
+			e.DataEncoded = data
+			return next(ctx, e)
+		}
+	}
+}
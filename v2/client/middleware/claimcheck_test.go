@@ -0,0 +1,119 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+type mapClaimCheckStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	err  error
+}
+
+func (s *mapClaimCheckStore) Put(ctx context.Context, key string, data []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return "", s.err
+	}
+	s.data[key] = data
+	return "mem://" + key, nil
+}
+
+func (s *mapClaimCheckStore) Get(ctx context.Context, dataref string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	if !strings.HasPrefix(dataref, "mem://") {
+		return nil, ErrForeignDataRef
+	}
+	return s.data[strings.TrimPrefix(dataref, "mem://")], nil
+}
+
+func TestOffloadData(t *testing.T) {
+	store := &mapClaimCheckStore{data: map[string][]byte{}}
+	ctx := context.Background()
+
+	e := newEvent("order", "", "")
+	require.NoError(t, e.SetData("text/plain", "small"))
+	offloaded, err := OffloadData(ctx, store, &e, 16)
+	require.NoError(t, err)
+	require.False(t, offloaded)
+	require.Equal(t, "small", string(e.Data()))
+
+	require.NoError(t, e.SetData("text/plain", "a payload larger than the threshold"))
+	offloaded, err = OffloadData(ctx, store, &e, 16)
+	require.NoError(t, err)
+	require.True(t, offloaded)
+	require.Empty(t, e.Data())
+	require.Equal(t, "text/plain", e.DataContentType())
+	require.Equal(t, "mem://"+ClaimCheckKey(e), e.Extensions()["dataref"])
+	require.Equal(t, "a payload larger than the threshold", string(store.data[ClaimCheckKey(e)]))
+
+	store.err = errors.New("unavailable")
+	e = newEvent("order", "", "")
+	require.NoError(t, e.SetData("text/plain", "a payload larger than the threshold"))
+	_, err = OffloadData(ctx, store, &e, 16)
+	require.Error(t, err)
+	require.NotEmpty(t, e.Data())
+}
+
+func TestClaimCheck(t *testing.T) {
+	store := &mapClaimCheckStore{data: map[string][]byte{"1": []byte("payload")}}
+	var got event.Event
+	h := ClaimCheck(store)(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		got = e
+		return nil, protocol.ResultACK
+	})
+	ctx := context.Background()
+
+	e := newEvent("order", "text/plain", "")
+	e.SetExtension("dataref", "mem://1")
+	_, result := h(ctx, e)
+	require.True(t, protocol.IsACK(result))
+	require.Equal(t, "payload", string(got.Data()))
+
+	// Foreign datarefs are passed as-is.
+	e.SetExtension("dataref", "https://example.com/data/1")
+	_, result = h(ctx, e)
+	require.True(t, protocol.IsACK(result))
+	require.Empty(t, got.Data())
+
+	store.err = errors.New("unavailable")
+	e.SetExtension("dataref", "mem://1")
+	_, result = h(ctx, e)
+	require.True(t, protocol.IsNACK(result))
+
+	// Events with data are not resolved.
+	require.NoError(t, e.SetData("text/plain", "inline"))
+	_, result = h(ctx, e)
+	require.True(t, protocol.IsACK(result))
+	require.Equal(t, "inline", string(got.Data()))
+}
+
+func TestClaimCheckKey(t *testing.T) {
+	e := newEvent("order", "", "")
+	e.SetTime(time.Date(2024, 5, 1, 23, 0, 0, 0, time.FixedZone("", -2*3600)))
+	require.Regexp(t, `^2024/05/02/[0-9a-f]{64}$`, ClaimCheckKey(e))
+
+	other := e.Clone()
+	other.SetID("2")
+	require.NotEqual(t, ClaimCheckKey(e), ClaimCheckKey(other))
+}
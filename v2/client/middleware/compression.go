@@ -0,0 +1,30 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Decompress returns a middleware decompressing the data of the received
+// events with the codec named by their compression extension, whatever the
+// binding they were received with, see the compression package. The events
+// whose data can not be decompressed are not acknowledged.
+func Decompress() client.Middleware {
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			if err := compression.DecompressEvent(&e); err != nil {
+				return nil, protocol.NewReceipt(false, "%w", err)
+			}
+			return next(ctx, e)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestDecompress(t *testing.T) {
+	var got event.Event
+	h := Decompress()(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		got = e
+		return nil, protocol.ResultACK
+	})
+	ctx := context.Background()
+
+	e := newEvent("order", "text/plain", "")
+	require.NoError(t, e.SetData("text/plain", "payload"))
+	require.NoError(t, compression.CompressEvent(&e, compression.Deflate))
+	_, result := h(ctx, e)
+	require.True(t, protocol.IsACK(result))
+	require.Equal(t, "payload", string(got.Data()))
+	require.NotContains(t, got.Extensions(), compression.Extension)
+
+	// Uncompressed events are passed as-is.
+	require.NoError(t, e.SetData("text/plain", "plain"))
+	e.SetExtension(compression.Extension, nil)
+	_, result = h(ctx, e)
+	require.True(t, protocol.IsACK(result))
+	require.Equal(t, "plain", string(got.Data()))
+
+	e.SetExtension(compression.Extension, compression.Gzip)
+	_, result = h(ctx, e)
+	require.True(t, protocol.IsNACK(result))
+}
@@ -0,0 +1,151 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Metrics emitted by the ConcurrencyLimit middleware, see
+// WithConcurrencyMetrics.
+const (
+	// MetricConcurrencyActive is a gauge of the invocations of the receiver
+	// function running, per class.
+	MetricConcurrencyActive = "cloudevents.concurrency.active"
+	// MetricConcurrencyWait is a histogram of the time the events waited for
+	// the limit of their class, per class.
+	MetricConcurrencyWait = "cloudevents.concurrency.wait"
+
+	// ConcurrencyClassAttr is the metric attribute holding the class.
+	ConcurrencyClassAttr = "cloudevents.concurrency.class"
+)
+
+// ConcurrencyClassifier returns the class of an event its concurrency is
+// limited by, see ConcurrencyLimit. The events of the empty class are not
+// limited, unless a default limit is set.
+//
+// The Class method of the Matcher of the sql module classifies the events by
+// the CloudEvents SQL expressions they match.
+type ConcurrencyClassifier func(ctx context.Context, e event.Event) string
+
+// ClassifyByType classifies the events by type.
+func ClassifyByType(_ context.Context, e event.Event) string {
+	return e.Type()
+}
+
+// ConcurrencyOption configures the ConcurrencyLimit middleware.
+type ConcurrencyOption func(*concurrencyConfig)
+
+type concurrencyConfig struct {
+	classify     ConcurrencyClassifier
+	defaultLimit int
+	metrics      observability.Metrics
+}
+
+// WithConcurrencyClassifier sets the classifier of the events. Defaults to
+// ClassifyByType.
+func WithConcurrencyClassifier(classify ConcurrencyClassifier) ConcurrencyOption {
+	return func(c *concurrencyConfig) {
+		c.classify = classify
+	}
+}
+
+// WithDefaultConcurrencyLimit limits the concurrency of each class without
+// limit of its own to limit. By default, they are not limited. The number of
+// classes should then be bounded.
+func WithDefaultConcurrencyLimit(limit int) ConcurrencyOption {
+	return func(c *concurrencyConfig) {
+		c.defaultLimit = limit
+	}
+}
+
+// WithConcurrencyMetrics records the MetricConcurrencyActive and
+// MetricConcurrencyWait metrics of the limited classes, partitioned by class
+// with the ConcurrencyClassAttr attribute.
+func WithConcurrencyMetrics(metrics observability.Metrics) ConcurrencyOption {
+	return func(c *concurrencyConfig) {
+		c.metrics = metrics
+	}
+}
+
+// concurrencySlots limits the concurrency of a class.
+type concurrencySlots struct {
+	sem    chan struct{}
+	active atomic.Int64
+}
+
+// ConcurrencyLimit returns a middleware running at most limits[class]
+// invocations of the receiver function at once for the events of each class,
+// so that the expensive events are throttled independently of the cheap ones
+// received along them. The events over the limit of their class wait for an
+// invocation of the class to complete, and are NACKed if the context is done
+// meanwhile. The limits lower than 1 are ignored.
+//
+// The waiting events hold the goroutine invoking the receiver function, so the
+// classes are independent with the default dispatch of the client, which
+// invokes it in a goroutine per event, bounded by
+// client.WithInflightWatermarks. With client.WithBlockingCallback, the waiting
+// events block the receiving of all the events.
+func ConcurrencyLimit(limits map[string]int, opts ...ConcurrencyOption) client.Middleware {
+	config := concurrencyConfig{
+		classify: ClassifyByType,
+		metrics:  observability.NoopMetrics{},
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	var (
+		mu      sync.Mutex
+		classes = map[string]*concurrencySlots{}
+	)
+	for class, limit := range limits {
+		if limit > 0 {
+			classes[class] = &concurrencySlots{sem: make(chan struct{}, limit)}
+		}
+	}
+	slotsOf := func(class string) *concurrencySlots {
+		mu.Lock()
+		defer mu.Unlock()
+		s, ok := classes[class]
+		if !ok && config.defaultLimit > 0 {
+			s = &concurrencySlots{sem: make(chan struct{}, config.defaultLimit)}
+			classes[class] = s
+		}
+		return s
+	}
+
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			class := config.classify(ctx, e)
+			s := slotsOf(class)
+			if s == nil {
+				return next(ctx, e)
+			}
+			attr := observability.Attribute{Key: ConcurrencyClassAttr, Value: class}
+			start := time.Now()
+			select {
+			case s.sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil, protocol.NewReceipt(false, "concurrency limit of class %q: %w", class, ctx.Err())
+			}
+			config.metrics.RecordDuration(MetricConcurrencyWait, time.Since(start), attr)
+			config.metrics.SetGauge(MetricConcurrencyActive, s.active.Add(1), attr)
+			defer func() {
+				config.metrics.SetGauge(MetricConcurrencyActive, s.active.Add(-1), attr)
+				<-s.sem
+			}()
+			return next(ctx, e)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// gaugeMetrics records the maximal value of the gauges, by name and first
+// attribute.
+type gaugeMetrics struct {
+	durationMetrics
+	max map[string]int64
+}
+
+func (m *gaugeMetrics) SetGauge(name string, value int64, attrs ...observability.Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := name + " " + attrs[0].Value
+	m.max[key] = max(m.max[key], value)
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	metrics := &gaugeMetrics{
+		durationMetrics: durationMetrics{durations: map[string][]time.Duration{}},
+		max:             map[string]int64{},
+	}
+	var (
+		running = map[string]*atomic.Int64{"report": {}, "click": {}}
+		peak    sync.Map
+		release = make(chan struct{})
+	)
+	h := ConcurrencyLimit(map[string]int{"report": 2}, WithConcurrencyMetrics(metrics))(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		n := running[e.Type()].Add(1)
+		defer running[e.Type()].Add(-1)
+		for {
+			p, _ := peak.LoadOrStore(e.Type(), int64(0))
+			if n <= p.(int64) || peak.CompareAndSwap(e.Type(), p, n) {
+				break
+			}
+		}
+		if e.Type() == "report" {
+			<-release
+		}
+		return nil, protocol.ResultACK
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, result := h(context.Background(), newEvent("report", "", ""))
+			require.True(t, protocol.IsACK(result))
+		}()
+	}
+	// The clicks are not limited, nor delayed by the reports.
+	for i := 0; i < 5; i++ {
+		_, result := h(context.Background(), newEvent("click", "", ""))
+		require.True(t, protocol.IsACK(result))
+	}
+	require.Eventually(t, func() bool { return running["report"].Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, int64(2), running["report"].Load())
+	close(release)
+	wg.Wait()
+
+	p, _ := peak.Load("report")
+	require.Equal(t, int64(2), p)
+	require.Equal(t, map[string]int64{MetricConcurrencyActive + " report": 2}, metrics.max)
+	require.Len(t, metrics.durations[MetricConcurrencyWait+" report"], 5)
+}
+
+func TestConcurrencyLimitCanceled(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := ConcurrencyLimit(nil, WithDefaultConcurrencyLimit(1), WithConcurrencyClassifier(func(_ context.Context, e event.Event) string {
+		return e.Source()
+	}))(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		started <- struct{}{}
+		<-release
+		return nil, protocol.ResultACK
+	})
+
+	done := make(chan protocol.Result)
+	go func() {
+		_, result := h(context.Background(), newEvent("order", "", ""))
+		done <- result
+	}()
+	<-started
+
+	// The events of the same source wait for the running one.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, result := h(ctx, newEvent("payment", "", ""))
+	require.True(t, protocol.IsNACK(result))
+	require.ErrorIs(t, result, context.DeadlineExceeded)
+
+	close(release)
+	require.True(t, protocol.IsACK(<-done))
+}
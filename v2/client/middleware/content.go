@@ -0,0 +1,149 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// ContentExpectation describes the content of the events of a given type.
+type ContentExpectation struct {
+	// DataContentType is the expected media type of the datacontenttype
+	// attribute. Parameters (e.g. charset) are ignored. Empty accepts any.
+	DataContentType string
+	// DataSchema is the expected dataschema attribute. Empty accepts any.
+	DataSchema string
+}
+
+// ContentMismatchError is returned when an event does not match the
+// expectation registered for its type.
+type ContentMismatchError struct {
+	Type      string
+	Attribute string
+	Expected  string
+	Actual    string
+}
+
+func (e *ContentMismatchError) Error() string {
+	if e.Attribute == "" {
+		return fmt.Sprintf("no content expectation registered for event type %q", e.Type)
+	}
+	return fmt.Sprintf("event type %q expects %s %q, got %q", e.Type, e.Attribute, e.Expected, e.Actual)
+}
+
+// ContentValidator checks that the datacontenttype and dataschema of the
+// events match the expectation registered for their type, in order to reject
+// producers silently changing the format of their payloads.
+type ContentValidator struct {
+	mu            sync.RWMutex
+	expectations  map[string]ContentExpectation
+	rejectUnknown bool
+}
+
+// ContentValidatorOption configures a ContentValidator.
+type ContentValidatorOption func(*ContentValidator)
+
+// RejectUnregisteredTypes makes the ContentValidator reject the events whose
+// type has no registered expectation. By default they are accepted.
+func RejectUnregisteredTypes() ContentValidatorOption {
+	return func(v *ContentValidator) {
+		v.rejectUnknown = true
+	}
+}
+
+// NewContentValidator returns a ContentValidator without expectations.
+func NewContentValidator(opts ...ContentValidatorOption) *ContentValidator {
+	v := &ContentValidator{expectations: make(map[string]ContentExpectation)}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Register sets the expectation for the events of the given type, replacing
+// any previous one.
+func (v *ContentValidator) Register(eventType string, expectation ContentExpectation) {
+	expectation.DataContentType = mediaType(expectation.DataContentType)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.expectations[eventType] = expectation
+}
+
+// Expectations returns a copy of the registered expectations, by event type.
+func (v *ContentValidator) Expectations() map[string]ContentExpectation {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	expectations := make(map[string]ContentExpectation, len(v.expectations))
+	for t, e := range v.expectations {
+		expectations[t] = e
+	}
+	return expectations
+}
+
+// Validate returns a *ContentMismatchError if e does not match the
+// expectation registered for its type.
+func (v *ContentValidator) Validate(e event.Event) error {
+	v.mu.RLock()
+	expectation, ok := v.expectations[e.Type()]
+	v.mu.RUnlock()
+	if !ok {
+		if v.rejectUnknown {
+			return &ContentMismatchError{Type: e.Type()}
+		}
+		return nil
+	}
+
+	if expectation.DataContentType != "" {
+		if actual := mediaType(e.DataContentType()); actual != expectation.DataContentType {
+			return &ContentMismatchError{
+				Type:      e.Type(),
+				Attribute: "datacontenttype",
+				Expected:  expectation.DataContentType,
+				Actual:    e.DataContentType(),
+			}
+		}
+	}
+	if expectation.DataSchema != "" && e.DataSchema() != expectation.DataSchema {
+		return &ContentMismatchError{
+			Type:      e.Type(),
+			Attribute: "dataschema",
+			Expected:  expectation.DataSchema,
+			Actual:    e.DataSchema(),
+		}
+	}
+	return nil
+}
+
+// Middleware returns a client.Middleware NACKing the events rejected by
+// Validate without invoking the receiver function.
+func (v *ContentValidator) Middleware() client.Middleware {
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			if err := v.Validate(e); err != nil {
+				return nil, protocol.NewReceipt(false, "content validation error in incoming event: %w", err)
+			}
+			return next(ctx, e)
+		}
+	}
+}
+
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
@@ -0,0 +1,183 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// Redacted replaces the redacted values in the logged events.
+const Redacted = "[REDACTED]"
+
+// ContentLoggerOption configures the ContentLogger middleware.
+type ContentLoggerOption func(*contentLogger) error
+
+// WithLogSampleRate sets the fraction, between 0 and 1, of the events logged
+// by ContentLogger. Defaults to 0: only the events matching a filter are
+// logged.
+func WithLogSampleRate(rate float64) ContentLoggerOption {
+	return func(l *contentLogger) error {
+		if rate < 0 || rate > 1 {
+			return errors.New("log sample rate must be between 0 and 1")
+		}
+		l.rate = rate
+		return nil
+	}
+}
+
+// WithLogFilter makes ContentLogger log the events for which filter returns
+// true, regardless of the sample rate. It can be given several times.
+func WithLogFilter(filter func(event.Event) bool) ContentLoggerOption {
+	return func(l *contentLogger) error {
+		if filter == nil {
+			return errors.New("log filter can not be nil")
+		}
+		l.filters = append(l.filters, filter)
+		return nil
+	}
+}
+
+// WithLogRedaction replaces by Redacted, in the logged events, the values of
+// the extensions with the given names and, for JSON data, of the fields with
+// the given names at any depth.
+func WithLogRedaction(names ...string) ContentLoggerOption {
+	return func(l *contentLogger) error {
+		for _, name := range names {
+			l.redacted[name] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// MatchType returns a filter for WithLogFilter matching the events of the
+// given types.
+func MatchType(types ...string) func(event.Event) bool {
+	return func(e event.Event) bool {
+		for _, t := range types {
+			if e.Type() == t {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchExtension returns a filter for WithLogFilter matching the events with
+// the given extension, set to value if not empty.
+func MatchExtension(name, value string) func(event.Event) bool {
+	return func(e event.Event) bool {
+		v, err := e.Context.GetExtension(name)
+		if err != nil {
+			return false
+		}
+		if value == "" {
+			return true
+		}
+		s, err := types.Format(v)
+		return err == nil && s == value
+	}
+}
+
+type contentLogger struct {
+	rate     float64
+	filters  []func(event.Event) bool
+	redacted map[string]struct{}
+	random   func() float64
+}
+
+// ContentLogger returns a middleware logging at the info level, with the
+// logger of the context, the full content of a sample of the events and of
+// the events matching a filter, in order to debug production issues without
+// logging every payload. Sensitive values are hidden with WithLogRedaction.
+func ContentLogger(opts ...ContentLoggerOption) (client.Middleware, error) {
+	l := &contentLogger{
+		redacted: make(map[string]struct{}),
+		random:   rand.Float64,
+	}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			if l.sampled(e) {
+				cecontext.LoggerFrom(ctx).Infow("event content", zap.Stringer("event", l.redact(e)))
+			}
+			return next(ctx, e)
+		}
+	}, nil
+}
+
+func (l *contentLogger) sampled(e event.Event) bool {
+	for _, filter := range l.filters {
+		if filter(e) {
+			return true
+		}
+	}
+	return l.rate > 0 && l.random() < l.rate
+}
+
+// redact returns a copy of e with the redacted values replaced.
+func (l *contentLogger) redact(e event.Event) event.Event {
+	if len(l.redacted) == 0 {
+		return e
+	}
+	e = e.Clone()
+	for name := range e.Extensions() {
+		if _, ok := l.redacted[name]; ok {
+			_ = e.Context.SetExtension(name, Redacted)
+		}
+	}
+	if !isJSON(e.DataMediaType()) || len(e.Data()) == 0 {
+		return e
+	}
+	var data interface{}
+	if err := json.Unmarshal(e.Data(), &data); err != nil {
+		// Do not log data which can not be redacted.
+		e.DataEncoded = []byte(`"` + Redacted + `"`)
+		return e
+	}
+	if redacted, err := json.Marshal(l.redactJSON(data)); err == nil {
+		e.DataEncoded = redacted
+	}
+	return e
+}
+
+func (l *contentLogger) redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if _, ok := l.redacted[k]; ok {
+				v[k] = Redacted
+			} else {
+				v[k] = l.redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = l.redactJSON(item)
+		}
+	}
+	return v
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "" || mediaType == event.ApplicationJSON || mediaType == event.TextJSON ||
+		strings.HasSuffix(mediaType, "+json")
+}
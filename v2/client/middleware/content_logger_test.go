@@ -0,0 +1,77 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func logContent(t *testing.T, e event.Event, opts ...ContentLoggerOption) []observer.LoggedEntry {
+	m, err := ContentLogger(opts...)
+	require.NoError(t, err)
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := cecontext.WithLogger(context.Background(), zap.New(core).Sugar())
+
+	called := false
+	_, result := m(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		called = true
+		return nil, protocol.ResultACK
+	})(ctx, e)
+	require.True(t, called)
+	require.True(t, protocol.IsACK(result))
+	return logs.All()
+}
+
+func TestContentLoggerSampling(t *testing.T) {
+	e := newEvent("order", "", "")
+
+	require.Empty(t, logContent(t, e))
+	require.Len(t, logContent(t, e, WithLogSampleRate(1)), 1)
+	require.Empty(t, logContent(t, e, WithLogFilter(MatchType("other"))))
+	require.Len(t, logContent(t, e, WithLogFilter(MatchType("other", "order"))), 1)
+
+	e.SetExtension("tenant", "acme")
+	require.Len(t, logContent(t, e, WithLogFilter(MatchExtension("tenant", ""))), 1)
+	require.Len(t, logContent(t, e, WithLogFilter(MatchExtension("tenant", "acme"))), 1)
+	require.Empty(t, logContent(t, e, WithLogFilter(MatchExtension("tenant", "other"))))
+}
+
+func TestContentLoggerRedaction(t *testing.T) {
+	e := newEvent("order", "", "")
+	e.SetExtension("token", "secret")
+	require.NoError(t, e.SetData(event.ApplicationJSON, map[string]interface{}{
+		"id":       "1",
+		"password": "secret",
+		"items":    []interface{}{map[string]interface{}{"password": "secret"}},
+	}))
+
+	logs := logContent(t, e, WithLogSampleRate(1), WithLogRedaction("token", "password"))
+	require.Len(t, logs, 1)
+	logged := logs[0].ContextMap()["event"].(string)
+	require.NotContains(t, logged, "secret")
+	require.Contains(t, logged, Redacted)
+	require.Contains(t, logged, `"id": "1"`)
+
+	// The event given to the handler is not modified.
+	require.Equal(t, "secret", e.Extensions()["token"])
+	require.Contains(t, string(e.Data()), "secret")
+}
+
+func TestContentLoggerOptions(t *testing.T) {
+	_, err := ContentLogger(WithLogSampleRate(2))
+	require.EqualError(t, err, "log sample rate must be between 0 and 1")
+	_, err = ContentLogger(WithLogFilter(nil))
+	require.EqualError(t, err, "log filter can not be nil")
+}
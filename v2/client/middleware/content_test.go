@@ -0,0 +1,131 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func newEvent(eventType, contentType, schema string) event.Event {
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType(eventType)
+	if contentType != "" {
+		e.SetDataContentType(contentType)
+	}
+	if schema != "" {
+		e.SetDataSchema(schema)
+	}
+	return e
+}
+
+func TestContentValidator(t *testing.T) {
+	avro := ContentExpectation{DataContentType: "application/avro", DataSchema: "https://example.com/schemas/order/v1"}
+
+	testCases := map[string]struct {
+		opts    []ContentValidatorOption
+		event   event.Event
+		wantErr *ContentMismatchError
+	}{
+		"match": {
+			event: newEvent("order", "application/avro", "https://example.com/schemas/order/v1"),
+		},
+		"content type parameters and case are ignored": {
+			event: newEvent("order", "Application/Avro; charset=binary", "https://example.com/schemas/order/v1"),
+		},
+		"content type drift": {
+			event: newEvent("order", "application/json", "https://example.com/schemas/order/v1"),
+			wantErr: &ContentMismatchError{
+				Type:      "order",
+				Attribute: "datacontenttype",
+				Expected:  "application/avro",
+				Actual:    "application/json",
+			},
+		},
+		"schema drift": {
+			event: newEvent("order", "application/avro", "https://example.com/schemas/order/v2"),
+			wantErr: &ContentMismatchError{
+				Type:      "order",
+				Attribute: "dataschema",
+				Expected:  "https://example.com/schemas/order/v1",
+				Actual:    "https://example.com/schemas/order/v2",
+			},
+		},
+		"missing schema": {
+			event: newEvent("order", "application/avro", ""),
+			wantErr: &ContentMismatchError{
+				Type:      "order",
+				Attribute: "dataschema",
+				Expected:  "https://example.com/schemas/order/v1",
+			},
+		},
+		"any content for json type": {
+			event: newEvent("ping", "text/plain", "https://example.com/anything"),
+		},
+		"unregistered type": {
+			event: newEvent("unknown", "text/plain", ""),
+		},
+		"unregistered type rejected": {
+			opts:    []ContentValidatorOption{RejectUnregisteredTypes()},
+			event:   newEvent("unknown", "text/plain", ""),
+			wantErr: &ContentMismatchError{Type: "unknown"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			v := NewContentValidator(tc.opts...)
+			v.Register("order", avro)
+			v.Register("ping", ContentExpectation{})
+
+			err := v.Validate(tc.event)
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestContentValidatorMiddleware(t *testing.T) {
+	v := NewContentValidator()
+	v.Register("order", ContentExpectation{DataContentType: "application/avro"})
+
+	called := false
+	h := v.Middleware()(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		called = true
+		return nil, nil
+	})
+
+	_, result := h(context.Background(), newEvent("order", "application/json", ""))
+	require.True(t, protocol.IsNACK(result))
+	require.ErrorAs(t, result, new(*ContentMismatchError))
+	require.False(t, called)
+
+	_, result = h(context.Background(), newEvent("order", "application/avro", ""))
+	require.NoError(t, result)
+	require.True(t, called)
+}
+
+func TestContentValidatorExpectations(t *testing.T) {
+	v := NewContentValidator()
+	v.Register("order", ContentExpectation{DataContentType: "application/JSON; charset=utf-8", DataSchema: "urn:order"})
+
+	expectations := v.Expectations()
+	require.Equal(t, map[string]ContentExpectation{
+		"order": {DataContentType: "application/json", DataSchema: "urn:order"},
+	}, expectations)
+
+	delete(expectations, "order")
+	require.Len(t, v.Expectations(), 1)
+}
@@ -0,0 +1,83 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// DeadlineExtension is the extension holding the time the producer of an
+// event stops waiting for it to be processed.
+const DeadlineExtension = "deadline"
+
+// DefaultDeadline is a client.EventDefaulter setting the DeadlineExtension of
+// the events sent with a context with a deadline, unless they already have
+// one, to be installed with client.WithEventDefaulter. The deadline is
+// absolute, so the time the event spends in the broker counts against it.
+func DefaultDeadline(ctx context.Context, e event.Event) event.Event {
+	deadline, ok := ctx.Deadline()
+	if !ok || e.Context == nil {
+		return e
+	}
+	if _, ok := e.Extensions()[DeadlineExtension]; ok {
+		return e
+	}
+	e.Context = e.Context.Clone()
+	e.SetExtension(DeadlineExtension, types.Timestamp{Time: deadline.UTC()})
+	return e
+}
+
+// DeadlineFrom returns the deadline of e, and false if it has none. It fails
+// if the DeadlineExtension of e is not a timestamp.
+func DeadlineFrom(e event.Event) (time.Time, bool, error) {
+	v, ok := e.Extensions()[DeadlineExtension]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	deadline, err := types.ToTime(v)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s extension: %w", DeadlineExtension, err)
+	}
+	return deadline, true, nil
+}
+
+// Deadline returns a middleware invoking the receiver function with a
+// context derived from the DeadlineExtension of the event, so that the
+// timeout of the producer is honored end to end. The events whose deadline
+// has passed on the clock of the context are acknowledged without invoking
+// the receiver function, their producer having given up on them, and the
+// events with an invalid deadline are NACKed.
+func Deadline() client.Middleware {
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			deadline, ok, err := DeadlineFrom(e)
+			if err != nil {
+				return nil, protocol.NewReceipt(false, "deadline error in incoming event: %w", err)
+			}
+			if !ok {
+				return next(ctx, e)
+			}
+			if !cecontext.ClockFrom(ctx).Now().Before(deadline) {
+				cecontext.LoggerFrom(ctx).Infow("skipping event past its deadline",
+					zap.String("source", e.Source()), zap.String("id", e.ID()), zap.Time("deadline", deadline))
+				return nil, protocol.ResultACK
+			}
+			ctx, cancel := context.WithDeadline(ctx, deadline)
+			defer cancel()
+			return next(ctx, e)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestDefaultDeadline(t *testing.T) {
+	deadline := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	e := newEvent("order", "", "")
+	got := DefaultDeadline(ctx, e)
+	_, ok := e.Extensions()[DeadlineExtension]
+	require.False(t, ok, "modified the original event")
+	d, ok, err := DeadlineFrom(got)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, deadline.Equal(d))
+
+	// An existing deadline is kept.
+	earlier := deadline.Add(-time.Minute)
+	e.SetExtension(DeadlineExtension, earlier)
+	d, _, _ = DeadlineFrom(DefaultDeadline(ctx, e))
+	require.True(t, earlier.Equal(d))
+
+	// A context without deadline sets none.
+	_, ok, _ = DeadlineFrom(DefaultDeadline(context.Background(), newEvent("order", "", "")))
+	require.False(t, ok)
+}
+
+func TestDeadline(t *testing.T) {
+	now := time.Now()
+	clock := cecontext.NewFakeClock(now)
+	ctx := cecontext.WithClock(context.Background(), clock)
+
+	var gotDeadline time.Time
+	var called bool
+	h := Deadline()(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		called = true
+		gotDeadline, _ = ctx.Deadline()
+		return nil, protocol.ResultACK
+	})
+
+	e := newEvent("order", "", "")
+	e.SetExtension(DeadlineExtension, now.Add(time.Hour))
+	_, result := h(ctx, e)
+	require.True(t, protocol.IsACK(result))
+	require.True(t, called)
+	require.True(t, now.Add(time.Hour).Equal(gotDeadline))
+
+	// An event past its deadline is acknowledged without being processed.
+	called = false
+	clock.Advance(time.Hour)
+	_, result = h(ctx, e)
+	require.True(t, protocol.IsACK(result))
+	require.False(t, called)
+
+	e.SetExtension(DeadlineExtension, "tomorrow")
+	_, result = h(ctx, e)
+	require.True(t, protocol.IsNACK(result))
+	require.False(t, called)
+
+	_, result = h(ctx, newEvent("order", "", ""))
+	require.True(t, protocol.IsACK(result))
+	require.True(t, called)
+}
@@ -0,0 +1,225 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Metrics emitted by the Dedup middleware, see WithDuplicateMetrics.
+const (
+	// MetricDedupDuplicateDelay is a histogram of the time elapsed between
+	// the first delivery of an event and each of its duplicates, per source.
+	MetricDedupDuplicateDelay = "cloudevents.dedup.duplicate_delay"
+	// MetricDedupSourceDuplicates is a counter of the duplicates, per
+	// source.
+	MetricDedupSourceDuplicates = "cloudevents.dedup.source_duplicates"
+)
+
+// DedupStore records the keys of the events being or already processed.
+// Implementations must be safe for concurrent use.
+type DedupStore interface {
+	// Add records key for ttl, or forever if ttl is not positive. It returns
+	// false if key is already recorded.
+	Add(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Remove forgets key.
+	Remove(ctx context.Context, key string) error
+}
+
+// DedupResponseStore is a DedupStore also keeping the response of the
+// processed events, so that the duplicates of a request get the response of
+// the original request. Dedup uses it when implemented by its store.
+type DedupResponseStore interface {
+	DedupStore
+	// SetResponse records resp as the response of the processing of key,
+	// for the remaining ttl of key.
+	SetResponse(ctx context.Context, key string, resp *event.Event) error
+	// GetResponse returns the response recorded for key, or nil if there is
+	// none.
+	GetResponse(ctx context.Context, key string) (*event.Event, error)
+}
+
+// DedupArrival is the arrival metadata of a key of a DedupArrivalStore.
+type DedupArrival struct {
+	// FirstSeen is when the key was recorded.
+	FirstSeen time.Time
+	// Duplicates is the number of times the key was found already recorded
+	// since.
+	Duplicates int
+}
+
+// DedupArrivalStore is a DedupStore also keeping when the keys were first
+// recorded, so that the Dedup middleware can measure how late the
+// duplicates arrive. Dedup uses it when implemented by its store.
+type DedupArrivalStore interface {
+	DedupStore
+	// Arrival returns the arrival metadata of key, and false if key is not
+	// recorded.
+	Arrival(ctx context.Context, key string) (DedupArrival, bool, error)
+}
+
+// DedupCommitStore is a DedupStore telling the keys of the events being
+// processed from the keys of the events already processed, so that the
+// duplicates of an event arriving on any instance while the event is being
+// processed are not acknowledged. Dedup uses it when implemented by its
+// store.
+type DedupCommitStore interface {
+	DedupStore
+	// Commit records that the processing of key succeeded. A key is pending
+	// from Add until it is committed.
+	Commit(ctx context.Context, key string) error
+	// Committed returns whether key is recorded and committed.
+	Committed(ctx context.Context, key string) (bool, error)
+}
+
+// DedupOption configures the Dedup middleware.
+type DedupOption func(*dedupConfig)
+
+type dedupConfig struct {
+	metrics observability.Metrics
+}
+
+// WithDuplicateMetrics records the MetricDedupSourceDuplicates metric and,
+// if the store is a DedupArrivalStore, the MetricDedupDuplicateDelay metric,
+// partitioned by source with the observability.SourceAttr attribute, to tune
+// the redelivery settings of the broker or of the producers. The number of
+// sources should be bounded.
+func WithDuplicateMetrics(metrics observability.Metrics) DedupOption {
+	return func(c *dedupConfig) {
+		c.metrics = metrics
+	}
+}
+
+// DedupKey returns the key identifying e: the source and id attributes are
+// unique for each distinct event.
+func DedupKey(e event.Event) string {
+	return e.Source() + " " + e.ID()
+}
+
+// Dedup returns a middleware acknowledging the events already processed
+// without invoking the receiver function again. The key of an event is
+// recorded in store for ttl before it is processed, and removed if the
+// processing is not acknowledged so that a redelivery is processed.
+// If the store fails, the event is not acknowledged.
+//
+// The duplicates arriving while the original event is being processed are
+// not acknowledged either, so that they are redelivered once the processing
+// completes, or processed if it fails. If store is a DedupCommitStore, the
+// key is committed once the processing is acknowledged, and the duplicates
+// of a pending key are not acknowledged. Otherwise, only the duplicates
+// arriving on this middleware are detected while pending. A key left pending
+// by an instance stopping before committing it delays its duplicates until
+// its ttl elapses.
+// If store is a DedupResponseStore, the duplicates get the response of the
+// original event once it has been processed.
+// If store is nil, a MemoryDedupStore with the default options is used.
+func Dedup(store DedupStore, ttl time.Duration, opts ...DedupOption) client.Middleware {
+	if store == nil {
+		// The default options are valid.
+		store, _ = NewMemoryDedupStore()
+	}
+	config := dedupConfig{metrics: observability.NoopMetrics{}}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	arrivals, _ := store.(DedupArrivalStore)
+	commits, _ := store.(DedupCommitStore)
+	// pending is the set of the keys being processed by this middleware,
+	// used when store does not keep the pending keys.
+	var pending sync.Map
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			key := DedupKey(e)
+			if commits == nil {
+				// The key is reserved before being added, so that a duplicate
+				// never finds it added but not pending.
+				if _, loaded := pending.LoadOrStore(key, struct{}{}); loaded {
+					config.recordDuplicate(ctx, e, arrivals, key)
+					return nil, pendingDuplicate(ctx, e, key)
+				}
+				defer pending.Delete(key)
+			}
+			added, err := store.Add(ctx, key, ttl)
+			if err != nil {
+				return nil, protocol.NewReceipt(false, "failed to record event in dedup store: %w", err)
+			}
+			responses, cacheResponses := store.(DedupResponseStore)
+			if !added {
+				config.recordDuplicate(ctx, e, arrivals, key)
+				if commits != nil {
+					committed, err := commits.Committed(ctx, key)
+					if err != nil {
+						return nil, protocol.NewReceipt(false, "failed to get event state from dedup store: %w", err)
+					}
+					if !committed {
+						return nil, pendingDuplicate(ctx, e, key)
+					}
+				}
+				cecontext.LoggerFrom(ctx).Debugw("skipping duplicate event", zap.String("source", e.Source()), zap.String("id", e.ID()))
+				if cacheResponses {
+					resp, err := responses.GetResponse(ctx, key)
+					if err != nil {
+						return nil, protocol.NewReceipt(false, "failed to get response from dedup store: %w", err)
+					}
+					return resp, protocol.ResultACK
+				}
+				return nil, protocol.ResultACK
+			}
+
+			resp, result := next(ctx, e)
+			if !protocol.IsACK(result) {
+				if err := store.Remove(ctx, key); err != nil {
+					cecontext.LoggerFrom(ctx).Warnw("failed to remove event from dedup store", zap.String("key", key), zap.Error(err))
+				}
+				return resp, result
+			}
+			// The response is recorded before the key is committed, so that
+			// the duplicates of a committed key get it.
+			if cacheResponses && resp != nil {
+				if err := responses.SetResponse(ctx, key, resp); err != nil {
+					cecontext.LoggerFrom(ctx).Warnw("failed to record response in dedup store", zap.String("key", key), zap.Error(err))
+				}
+			}
+			if commits != nil {
+				if err := commits.Commit(ctx, key); err != nil {
+					cecontext.LoggerFrom(ctx).Warnw("failed to commit event in dedup store", zap.String("key", key), zap.Error(err))
+				}
+			}
+			return resp, result
+		}
+	}
+}
+
+// recordDuplicate records the metrics of a duplicate of the event of key.
+func (c *dedupConfig) recordDuplicate(ctx context.Context, e event.Event, arrivals DedupArrivalStore, key string) {
+	source := observability.Attribute{Key: observability.SourceAttr, Value: e.Source()}
+	c.metrics.AddCounter(MetricDedupSourceDuplicates, 1, source)
+	if arrivals == nil {
+		return
+	}
+	if arrival, ok, err := arrivals.Arrival(ctx, key); err != nil {
+		cecontext.LoggerFrom(ctx).Warnw("failed to get arrival from dedup store", zap.String("key", key), zap.Error(err))
+	} else if ok {
+		c.metrics.RecordDuration(MetricDedupDuplicateDelay, cecontext.ClockFrom(ctx).Now().Sub(arrival.FirstSeen), source)
+	}
+}
+
+// pendingDuplicate returns the result of a duplicate of an event being
+// processed: it is not acknowledged, so that it is redelivered.
+func pendingDuplicate(ctx context.Context, e event.Event, key string) protocol.Result {
+	cecontext.LoggerFrom(ctx).Debugw("delaying duplicate of event being processed", zap.String("source", e.Source()), zap.String("id", e.ID()))
+	return protocol.NewReceipt(false, "duplicate of event %q being processed", key)
+}
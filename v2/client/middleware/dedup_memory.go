@@ -0,0 +1,344 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/observability"
+)
+
+// Metrics emitted by the MemoryDedupStore, see WithDedupMetrics.
+const (
+	// MetricDedupAdded is a counter of the keys recorded in the store.
+	MetricDedupAdded = "cloudevents.dedup.added"
+	// MetricDedupDuplicates is a counter of the keys found already recorded.
+	MetricDedupDuplicates = "cloudevents.dedup.duplicates"
+	// MetricDedupExpired is a counter of the keys forgotten once their ttl
+	// elapsed.
+	MetricDedupExpired = "cloudevents.dedup.expired"
+	// MetricDedupEvicted is a counter of the keys forgotten before their ttl
+	// elapsed, to keep the store under its maximum number of entries.
+	MetricDedupEvicted = "cloudevents.dedup.evicted"
+	// MetricDedupEntries is a gauge of the number of keys in the store.
+	MetricDedupEntries = "cloudevents.dedup.entries"
+)
+
+const (
+	defaultDedupShards       = 16
+	defaultDedupMaxEntries   = 100000
+	defaultDedupTickInterval = time.Second
+	// dedupWheelSize is the number of slots of the expiration wheel: a key
+	// expiring later than dedupWheelSize ticks stays in its slot for more
+	// than one turn of the wheel.
+	dedupWheelSize = 512
+)
+
+// MemoryDedupOption configures a MemoryDedupStore.
+type MemoryDedupOption func(*MemoryDedupStore) error
+
+// WithDedupShards sets the number of shards of the store, each guarded by its
+// own lock. Defaults to 16.
+func WithDedupShards(shards int) MemoryDedupOption {
+	return func(s *MemoryDedupStore) error {
+		if shards <= 0 {
+			return errors.New("dedup shards must be positive")
+		}
+		s.shards = make([]*dedupShard, shards)
+		return nil
+	}
+}
+
+// WithDedupMaxEntries sets the maximum number of keys in the store: once
+// reached, the oldest keys of a shard are evicted before their ttl elapses.
+// Defaults to 100000, 0 means unbounded.
+func WithDedupMaxEntries(maxEntries int) MemoryDedupOption {
+	return func(s *MemoryDedupStore) error {
+		if maxEntries < 0 {
+			return errors.New("dedup max entries can not be negative")
+		}
+		s.maxEntries = maxEntries
+		return nil
+	}
+}
+
+// WithDedupTickInterval sets the resolution of the expiration of the keys.
+// Expired keys are never reported as recorded, but they are released from
+// memory by a background sweep running at this interval. Defaults to 1s.
+func WithDedupTickInterval(interval time.Duration) MemoryDedupOption {
+	return func(s *MemoryDedupStore) error {
+		if interval <= 0 {
+			return errors.New("dedup tick interval must be positive")
+		}
+		s.tick = interval
+		return nil
+	}
+}
+
+// WithDedupMetrics sets the Metrics recording the activity of the store.
+func WithDedupMetrics(metrics observability.Metrics) MemoryDedupOption {
+	return func(s *MemoryDedupStore) error {
+		if metrics == nil {
+			return errors.New("dedup metrics can not be nil")
+		}
+		s.metrics = metrics
+		return nil
+	}
+}
+
+// WithDedupClock sets the clock the ttl of the keys is measured on,
+// cecontext.SystemClock by default.
+func WithDedupClock(clock cecontext.Clock) MemoryDedupOption {
+	return func(s *MemoryDedupStore) error {
+		if clock == nil {
+			return errors.New("dedup clock can not be nil")
+		}
+		s.now = clock.Now
+		return nil
+	}
+}
+
+// MemoryDedupStore is a DedupStore keeping the keys in memory, suitable for
+// a single instance consumer. Keys are spread over shards to limit lock
+// contention, and expired keys are released by a timing wheel.
+// Close must be called to stop the background sweep.
+type MemoryDedupStore struct {
+	shards     []*dedupShard
+	maxEntries int
+	tick       time.Duration
+	metrics    observability.Metrics
+	now        func() time.Time
+
+	entries  atomic.Int64
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewMemoryDedupStore returns an empty MemoryDedupStore and starts its
+// background sweep.
+func NewMemoryDedupStore(opts ...MemoryDedupOption) (*MemoryDedupStore, error) {
+	s := &MemoryDedupStore{
+		shards:     make([]*dedupShard, defaultDedupShards),
+		maxEntries: defaultDedupMaxEntries,
+		tick:       defaultDedupTickInterval,
+		metrics:    observability.NoopMetrics{},
+		now:        time.Now,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	maxPerShard := 0
+	if s.maxEntries > 0 {
+		maxPerShard = (s.maxEntries + len(s.shards) - 1) / len(s.shards)
+	}
+	lastTick := s.tickOf(s.now())
+	for i := range s.shards {
+		s.shards[i] = &dedupShard{
+			keys:       make(map[string]*dedupEntry),
+			order:      list.New(),
+			maxEntries: maxPerShard,
+			lastTick:   lastTick,
+		}
+	}
+
+	go s.run()
+	return s, nil
+}
+
+type dedupShard struct {
+	mu         sync.Mutex
+	keys       map[string]*dedupEntry
+	order      *list.List // of keys, oldest first
+	wheel      [dedupWheelSize]map[string]struct{}
+	maxEntries int
+	lastTick   int64
+}
+
+type dedupEntry struct {
+	// expires is the zero time if the key never expires.
+	expires    time.Time
+	elem       *list.Element
+	added      time.Time
+	duplicates int
+	committed  bool
+}
+
+// Add implements DedupStore.Add.
+func (s *MemoryDedupStore) Add(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	now := s.now()
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if e, ok := sh.keys[key]; ok {
+		if e.expires.IsZero() || now.Before(e.expires) {
+			e.duplicates++
+			s.metrics.AddCounter(MetricDedupDuplicates, 1)
+			return false, nil
+		}
+		s.remove(sh, key, e)
+		s.metrics.AddCounter(MetricDedupExpired, 1)
+	}
+
+	if sh.maxEntries > 0 && len(sh.keys) >= sh.maxEntries {
+		oldest := sh.order.Front().Value.(string)
+		s.remove(sh, oldest, sh.keys[oldest])
+		s.metrics.AddCounter(MetricDedupEvicted, 1)
+	}
+
+	e := &dedupEntry{elem: sh.order.PushBack(key), added: now}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+		// The key is swept on the tick following its expiration.
+		slot := (s.tickOf(e.expires) + 1) % dedupWheelSize
+		if sh.wheel[slot] == nil {
+			sh.wheel[slot] = make(map[string]struct{})
+		}
+		sh.wheel[slot][key] = struct{}{}
+	}
+	sh.keys[key] = e
+	s.metrics.AddCounter(MetricDedupAdded, 1)
+	s.metrics.SetGauge(MetricDedupEntries, s.entries.Add(1))
+	return true, nil
+}
+
+// Remove implements DedupStore.Remove.
+func (s *MemoryDedupStore) Remove(ctx context.Context, key string) error {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if e, ok := sh.keys[key]; ok {
+		s.remove(sh, key, e)
+	}
+	return nil
+}
+
+// Commit implements DedupCommitStore.Commit.
+func (s *MemoryDedupStore) Commit(ctx context.Context, key string) error {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if e, ok := sh.keys[key]; ok {
+		e.committed = true
+	}
+	return nil
+}
+
+// Committed implements DedupCommitStore.Committed. An expired key not swept
+// yet is not recorded.
+func (s *MemoryDedupStore) Committed(ctx context.Context, key string) (bool, error) {
+	now := s.now()
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	e, ok := sh.keys[key]
+	if !ok || (!e.expires.IsZero() && !now.Before(e.expires)) {
+		return false, nil
+	}
+	return e.committed, nil
+}
+
+// Arrival implements DedupArrivalStore.Arrival. An expired key not swept
+// yet is not recorded.
+func (s *MemoryDedupStore) Arrival(ctx context.Context, key string) (DedupArrival, bool, error) {
+	now := s.now()
+	sh := s.shard(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	e, ok := sh.keys[key]
+	if !ok || (!e.expires.IsZero() && !now.Before(e.expires)) {
+		return DedupArrival{}, false, nil
+	}
+	return DedupArrival{FirstSeen: e.added, Duplicates: e.duplicates}, true, nil
+}
+
+// Len returns the number of keys in the store, including the expired keys
+// not swept yet.
+func (s *MemoryDedupStore) Len() int {
+	return int(s.entries.Load())
+}
+
+// Close stops the background sweep. The store must not be used afterwards.
+func (s *MemoryDedupStore) Close() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+	return nil
+}
+
+func (s *MemoryDedupStore) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.sweep(s.now())
+		}
+	}
+}
+
+// sweep releases the keys expired at now, from the slots of the ticks elapsed
+// since the previous sweep.
+func (s *MemoryDedupStore) sweep(now time.Time) {
+	current := s.tickOf(now)
+	for _, sh := range s.shards {
+		sh.mu.Lock()
+		from := sh.lastTick + 1
+		if current-from >= dedupWheelSize {
+			from = current - dedupWheelSize + 1
+		}
+		for t := from; t <= current; t++ {
+			for key := range sh.wheel[t%dedupWheelSize] {
+				if e := sh.keys[key]; !now.Before(e.expires) {
+					s.remove(sh, key, e)
+					s.metrics.AddCounter(MetricDedupExpired, 1)
+				}
+			}
+		}
+		sh.lastTick = current
+		sh.mu.Unlock()
+	}
+}
+
+// remove forgets key, the shard lock must be held.
+func (s *MemoryDedupStore) remove(sh *dedupShard, key string, e *dedupEntry) {
+	if !e.expires.IsZero() {
+		delete(sh.wheel[(s.tickOf(e.expires)+1)%dedupWheelSize], key)
+	}
+	sh.order.Remove(e.elem)
+	delete(sh.keys, key)
+	s.metrics.SetGauge(MetricDedupEntries, s.entries.Add(-1))
+}
+
+func (s *MemoryDedupStore) shard(key string) *dedupShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return s.shards[h.Sum32()%uint32(len(s.shards))]
+}
+
+func (s *MemoryDedupStore) tickOf(t time.Time) int64 {
+	return t.UnixNano() / int64(s.tick)
+}
+
+var (
+	_ DedupArrivalStore = (*MemoryDedupStore)(nil)
+	_ DedupCommitStore  = (*MemoryDedupStore)(nil)
+)
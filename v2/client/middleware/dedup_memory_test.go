@@ -0,0 +1,171 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/observability"
+)
+
+type counterMetrics struct {
+	observability.NoopMetrics
+	mu       sync.Mutex
+	counters map[string]int64
+	gauges   map[string]int64
+}
+
+func (m *counterMetrics) AddCounter(name string, delta int64, _ ...observability.Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name] += delta
+}
+
+func (m *counterMetrics) SetGauge(name string, value int64, _ ...observability.Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gauges[name] = value
+}
+
+func newTestMemoryDedupStore(t *testing.T, opts ...MemoryDedupOption) (*MemoryDedupStore, *cecontext.FakeClock) {
+	clock := cecontext.NewFakeClock(time.Unix(1700000000, 0))
+	s, err := NewMemoryDedupStore(append(opts, WithDedupClock(clock))...)
+	require.NoError(t, err)
+	// Stop the background sweep, the tests sweep explicitly.
+	require.NoError(t, s.Close())
+	return s, clock
+}
+
+func TestMemoryDedupStore(t *testing.T) {
+	metrics := &counterMetrics{counters: map[string]int64{}, gauges: map[string]int64{}}
+	s, clock := newTestMemoryDedupStore(t, WithDedupMetrics(metrics))
+	ctx := context.Background()
+
+	added, err := s.Add(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = s.Add(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.False(t, added)
+
+	// Removed keys can be added again.
+	require.NoError(t, s.Remove(ctx, "a"))
+	added, _ = s.Add(ctx, "a", time.Minute)
+	require.True(t, added)
+
+	// Keys without ttl never expire.
+	added, _ = s.Add(ctx, "forever", 0)
+	require.True(t, added)
+	require.Equal(t, 2, s.Len())
+
+	// Expired keys are not reported as recorded, even before the sweep.
+	clock.Advance(time.Minute)
+	added, _ = s.Add(ctx, "a", time.Hour)
+	require.True(t, added)
+	added, _ = s.Add(ctx, "forever", 0)
+	require.False(t, added)
+
+	require.Equal(t, map[string]int64{
+		MetricDedupAdded:      4,
+		MetricDedupDuplicates: 2,
+		MetricDedupExpired:    1,
+	}, metrics.counters)
+	require.Equal(t, int64(2), metrics.gauges[MetricDedupEntries])
+}
+
+func TestMemoryDedupStoreCommit(t *testing.T) {
+	s, clock := newTestMemoryDedupStore(t)
+	ctx := context.Background()
+
+	_, err := s.Add(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	committed, err := s.Committed(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, committed)
+
+	require.NoError(t, s.Commit(ctx, "a"))
+	committed, err = s.Committed(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, committed)
+
+	// Unknown and expired keys are not committed.
+	require.NoError(t, s.Commit(ctx, "b"))
+	committed, err = s.Committed(ctx, "b")
+	require.NoError(t, err)
+	require.False(t, committed)
+	clock.Advance(time.Minute)
+	committed, err = s.Committed(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, committed)
+}
+
+func TestMemoryDedupStoreSweep(t *testing.T) {
+	s, clock := newTestMemoryDedupStore(t, WithDedupShards(2))
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		_, _ = s.Add(ctx, "short"+strconv.Itoa(i), 2*time.Second)
+	}
+	// Longer than a turn of the wheel.
+	_, _ = s.Add(ctx, "long", (dedupWheelSize+10)*time.Second)
+	_, _ = s.Add(ctx, "forever", 0)
+	require.Equal(t, 12, s.Len())
+
+	clock.Advance(time.Second)
+	s.sweep(clock.Now())
+	require.Equal(t, 12, s.Len())
+
+	clock.Advance(2 * time.Second)
+	s.sweep(clock.Now())
+	require.Equal(t, 2, s.Len())
+
+	clock.Advance(dedupWheelSize * time.Second)
+	s.sweep(clock.Now())
+	require.Equal(t, 2, s.Len())
+
+	clock.Advance(10 * time.Second)
+	s.sweep(clock.Now())
+	require.Equal(t, 1, s.Len())
+	added, _ := s.Add(ctx, "forever", 0)
+	require.False(t, added)
+}
+
+func TestMemoryDedupStoreMaxEntries(t *testing.T) {
+	metrics := &counterMetrics{counters: map[string]int64{}, gauges: map[string]int64{}}
+	s, _ := newTestMemoryDedupStore(t, WithDedupShards(1), WithDedupMaxEntries(3), WithDedupMetrics(metrics))
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		added, _ := s.Add(ctx, key, time.Hour)
+		require.True(t, added)
+	}
+	require.Equal(t, 3, s.Len())
+	require.Equal(t, int64(1), metrics.counters[MetricDedupEvicted])
+
+	// The oldest key has been evicted.
+	added, _ := s.Add(ctx, "a", time.Hour)
+	require.True(t, added)
+	added, _ = s.Add(ctx, "d", time.Hour)
+	require.False(t, added)
+}
+
+func TestMemoryDedupStoreOptions(t *testing.T) {
+	_, err := NewMemoryDedupStore(WithDedupShards(0))
+	require.EqualError(t, err, "dedup shards must be positive")
+	_, err = NewMemoryDedupStore(WithDedupMaxEntries(-1))
+	require.EqualError(t, err, "dedup max entries can not be negative")
+	_, err = NewMemoryDedupStore(WithDedupTickInterval(0))
+	require.EqualError(t, err, "dedup tick interval must be positive")
+	_, err = NewMemoryDedupStore(WithDedupMetrics(nil))
+	require.EqualError(t, err, "dedup metrics can not be nil")
+}
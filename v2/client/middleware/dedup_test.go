@@ -0,0 +1,235 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+type mapDedupStore struct {
+	mu   sync.Mutex
+	keys map[string]time.Duration
+	err  error
+}
+
+func (s *mapDedupStore) Add(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false, s.err
+	}
+	if _, ok := s.keys[key]; ok {
+		return false, nil
+	}
+	s.keys[key] = ttl
+	return true, nil
+}
+
+func (s *mapDedupStore) Remove(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+	return nil
+}
+
+func TestDedup(t *testing.T) {
+	store := &mapDedupStore{keys: map[string]time.Duration{}}
+	var calls int
+	var result protocol.Result
+	h := Dedup(store, time.Hour)(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		calls++
+		return nil, result
+	})
+	ctx := context.Background()
+	e := newEvent("order", "", "")
+
+	// A failed processing is retried.
+	result = protocol.NewReceipt(false, "failed")
+	_, got := h(ctx, e)
+	require.True(t, protocol.IsNACK(got))
+	require.Empty(t, store.keys)
+
+	result = nil
+	_, got = h(ctx, e)
+	require.True(t, protocol.IsACK(got))
+	require.Equal(t, map[string]time.Duration{"/source 1": time.Hour}, store.keys)
+
+	// Duplicates are acknowledged without being processed.
+	_, got = h(ctx, e)
+	require.True(t, protocol.IsACK(got))
+	require.Equal(t, 2, calls)
+
+	// Other events are processed.
+	e.SetID("2")
+	_, got = h(ctx, e)
+	require.True(t, protocol.IsACK(got))
+	require.Equal(t, 3, calls)
+
+	// Store failures are not acknowledged.
+	store.err = errors.New("unavailable")
+	e.SetID("3")
+	_, got = h(ctx, e)
+	require.True(t, protocol.IsNACK(got))
+	require.ErrorIs(t, got, store.err)
+	require.Equal(t, 3, calls)
+}
+
+// inFlightHandler returns a handler blocking until release is closed, and a
+// channel receiving a value once it is invoked.
+func inFlightHandler(release chan struct{}) (func(context.Context, event.Event) (*event.Event, protocol.Result), chan struct{}) {
+	started := make(chan struct{}, 1)
+	return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	}, started
+}
+
+func TestDedupInFlight(t *testing.T) {
+	memory, _ := newTestMemoryDedupStore(t)
+	for name, store := range map[string]DedupStore{
+		"pending":   &mapDedupStore{keys: map[string]time.Duration{}},
+		"committed": memory,
+	} {
+		t.Run(name, func(t *testing.T) {
+			release := make(chan struct{})
+			handler, started := inFlightHandler(release)
+			h := Dedup(store, time.Hour)(handler)
+			ctx := context.Background()
+			e := newEvent("order", "", "")
+
+			done := make(chan protocol.Result)
+			go func() {
+				_, result := h(ctx, e)
+				done <- result
+			}()
+			<-started
+
+			// The duplicates of an event being processed are redelivered.
+			_, got := h(ctx, e)
+			require.True(t, protocol.IsNACK(got))
+			require.ErrorContains(t, got, "being processed")
+
+			close(release)
+			require.True(t, protocol.IsACK(<-done))
+
+			// Once processed, they are acknowledged.
+			_, got = h(ctx, e)
+			require.True(t, protocol.IsACK(got))
+			require.Len(t, started, 0)
+		})
+	}
+}
+
+type mapDedupResponseStore struct {
+	mapDedupStore
+	responses map[string]*event.Event
+}
+
+func (s *mapDedupResponseStore) SetResponse(ctx context.Context, key string, resp *event.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[key] = resp
+	return nil
+}
+
+func (s *mapDedupResponseStore) GetResponse(ctx context.Context, key string) (*event.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.responses[key], nil
+}
+
+func TestDedupResponses(t *testing.T) {
+	store := &mapDedupResponseStore{
+		mapDedupStore: mapDedupStore{keys: map[string]time.Duration{}},
+		responses:     map[string]*event.Event{},
+	}
+	var calls int
+	h := Dedup(store, time.Hour)(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		calls++
+		resp := newEvent("reply", "", "")
+		return &resp, nil
+	})
+	ctx := context.Background()
+	e := newEvent("order", "", "")
+
+	resp, got := h(ctx, e)
+	require.True(t, protocol.IsACK(got))
+	require.Equal(t, "reply", resp.Type())
+
+	// Duplicates get the response of the original event.
+	dup, got := h(ctx, e)
+	require.True(t, protocol.IsACK(got))
+	require.Equal(t, resp, dup)
+	require.Equal(t, 1, calls)
+}
+
+type durationMetrics struct {
+	observability.NoopMetrics
+	mu        sync.Mutex
+	counters  map[string]int64
+	durations map[string][]time.Duration
+}
+
+func (m *durationMetrics) AddCounter(name string, delta int64, attrs ...observability.Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name+" "+attrs[0].Value] += delta
+}
+
+func (m *durationMetrics) RecordDuration(name string, d time.Duration, attrs ...observability.Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.durations[name+" "+attrs[0].Value] = append(m.durations[name+" "+attrs[0].Value], d)
+}
+
+func TestDedupDuplicateMetrics(t *testing.T) {
+	store, clock := newTestMemoryDedupStore(t)
+	ctx := cecontext.WithClock(context.Background(), clock)
+	metrics := &durationMetrics{counters: map[string]int64{}, durations: map[string][]time.Duration{}}
+	h := Dedup(store, time.Hour, WithDuplicateMetrics(metrics))(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		return nil, nil
+	})
+	e := newEvent("order", "", "")
+
+	h(ctx, e)
+	clock.Advance(30 * time.Second)
+	h(ctx, e)
+	clock.Advance(time.Minute)
+	h(ctx, e)
+
+	require.Equal(t, map[string]int64{MetricDedupSourceDuplicates + " /source": 2}, metrics.counters)
+	require.Equal(t, map[string][]time.Duration{
+		MetricDedupDuplicateDelay + " /source": {30 * time.Second, 90 * time.Second},
+	}, metrics.durations)
+
+	arrival, ok, err := store.Arrival(ctx, DedupKey(e))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 2, arrival.Duplicates)
+	require.Equal(t, clock.Now().Add(-90*time.Second), arrival.FirstSeen)
+
+	// Without arrival metadata, only the duplicates are counted.
+	metrics = &durationMetrics{counters: map[string]int64{}, durations: map[string][]time.Duration{}}
+	h = Dedup(&mapDedupStore{keys: map[string]time.Duration{}}, time.Hour, WithDuplicateMetrics(metrics))(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		return nil, nil
+	})
+	h(ctx, e)
+	h(ctx, e)
+	require.Equal(t, map[string]int64{MetricDedupSourceDuplicates + " /source": 1}, metrics.counters)
+	require.Empty(t, metrics.durations)
+}
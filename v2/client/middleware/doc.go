@@ -0,0 +1,8 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package middleware provides client.Middleware implementations to be
+// installed with client.WithMiddleware.
+package middleware
@@ -0,0 +1,246 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+const (
+	// MetricEnrichmentFailures counts the failed enrichments, per provider.
+	MetricEnrichmentFailures = "cloudevents.enrichment.failures"
+
+	// EnrichmentProviderAttr is the metric attribute holding the name of the
+	// provider.
+	EnrichmentProviderAttr = "cloudevents.enrichment.provider"
+)
+
+// EnrichFunc returns the extensions to add to e.
+type EnrichFunc func(ctx context.Context, e event.Event) (map[string]interface{}, error)
+
+// EnrichmentFailurePolicy is what an Enricher does with an event when a
+// provider fails or times out.
+type EnrichmentFailurePolicy int
+
+const (
+	// EnrichmentSkip logs the failure and passes the event on without the
+	// extensions of the provider.
+	EnrichmentSkip EnrichmentFailurePolicy = iota
+	// EnrichmentReject rejects the event.
+	EnrichmentReject
+)
+
+// EnrichmentProvider adds extensions to events, e.g. the location of the
+// source IP, the metadata of the deployment or feature flags.
+type EnrichmentProvider struct {
+	// Name identifies the provider in logs, metrics and errors.
+	Name string
+	// Enrich returns the extensions to add.
+	Enrich EnrichFunc
+	// Timeout bounds each call to Enrich, unbounded if zero. A provider
+	// which times out has failed.
+	Timeout time.Duration
+	// FailurePolicy applies when Enrich fails, EnrichmentSkip by default.
+	FailurePolicy EnrichmentFailurePolicy
+	// Overwrite lets the provider replace the extensions the event already
+	// has. By default they are kept.
+	Overwrite bool
+}
+
+// Enricher adds the extensions of its providers to events. The providers
+// are called concurrently with the event as received or sent, and their
+// extensions are added in the order of the providers.
+type Enricher struct {
+	providers []EnrichmentProvider
+	metrics   observability.Metrics
+}
+
+// EnricherOption configures an Enricher.
+type EnricherOption func(*Enricher)
+
+// WithEnrichmentMetrics records the MetricEnrichmentFailures metric,
+// partitioned by provider with the EnrichmentProviderAttr attribute.
+func WithEnrichmentMetrics(metrics observability.Metrics) EnricherOption {
+	return func(en *Enricher) {
+		en.metrics = metrics
+	}
+}
+
+// NewEnricher returns an Enricher with the given providers.
+func NewEnricher(providers []EnrichmentProvider, opts ...EnricherOption) (*Enricher, error) {
+	for i, p := range providers {
+		if p.Name == "" {
+			return nil, fmt.Errorf("enrichment provider %d has no name", i)
+		}
+		if p.Enrich == nil {
+			return nil, fmt.Errorf("enrichment provider %s has a nil Enrich function", p.Name)
+		}
+		if p.Timeout < 0 {
+			return nil, fmt.Errorf("enrichment provider %s has a negative timeout: %v", p.Name, p.Timeout)
+		}
+	}
+	en := &Enricher{
+		providers: providers,
+		metrics:   observability.NoopMetrics{},
+	}
+	for _, opt := range opts {
+		opt(en)
+	}
+	return en, nil
+}
+
+// Enrich returns a copy of e with the extensions of the providers. It fails
+// if a provider with the EnrichmentReject policy fails.
+func (en *Enricher) Enrich(ctx context.Context, e event.Event) (event.Event, error) {
+	results := make([]map[string]interface{}, len(en.providers))
+	errs := make([]error, len(en.providers))
+	var wg sync.WaitGroup
+	for i, p := range en.providers {
+		wg.Add(1)
+		go func(i int, p EnrichmentProvider) {
+			defer wg.Done()
+			results[i], errs[i] = p.call(ctx, e)
+		}(i, p)
+	}
+	wg.Wait()
+
+	for i, p := range en.providers {
+		err := errs[i]
+		if err == nil {
+			err = p.apply(&e, results[i])
+		}
+		if err == nil {
+			continue
+		}
+		en.metrics.AddCounter(MetricEnrichmentFailures, 1, observability.Attribute{Key: EnrichmentProviderAttr, Value: p.Name})
+		if p.FailurePolicy == EnrichmentReject {
+			return e, fmt.Errorf("enrichment provider %s failed: %w", p.Name, err)
+		}
+		cecontext.LoggerFrom(ctx).Warnw("enrichment provider failed, skipping it",
+			zap.String("provider", p.Name), zap.String("id", e.ID()), zap.Error(err))
+	}
+	return e, nil
+}
+
+// call calls Enrich within the timeout. A provider ignoring the cancellation
+// of its context is abandoned once the timeout elapses.
+func (p EnrichmentProvider) call(ctx context.Context, e event.Event) (map[string]interface{}, error) {
+	if p.Timeout == 0 {
+		return p.Enrich(ctx, e)
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	type result struct {
+		extensions map[string]interface{}
+		err        error
+	}
+	done := make(chan result, 1)
+	go func() {
+		extensions, err := p.Enrich(ctx, e)
+		done <- result{extensions, err}
+	}()
+	select {
+	case r := <-done:
+		return r.extensions, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// apply sets the extensions on e, all or none.
+func (p EnrichmentProvider) apply(e *event.Event, extensions map[string]interface{}) error {
+	existing := e.Extensions()
+	enriched := e.Clone()
+	for name, v := range extensions {
+		if _, ok := existing[name]; ok && !p.Overwrite {
+			continue
+		}
+		if err := enriched.Context.SetExtension(name, v); err != nil {
+			return err
+		}
+	}
+	*e = enriched
+	return nil
+}
+
+// Middleware returns a client.Middleware enriching the events before invoking
+// the receiver function, and NACKing those rejected by Enrich.
+func (en *Enricher) Middleware() client.Middleware {
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			e, err := en.Enrich(ctx, e)
+			if err != nil {
+				return nil, protocol.NewReceipt(false, "enrichment error in incoming event: %w", err)
+			}
+			return next(ctx, e)
+		}
+	}
+}
+
+// Defaulter returns a client.EventDefaulter enriching the events sent by a
+// client, to be installed with client.WithEventDefaulter. As a defaulter can
+// not fail Send, the events a provider with the EnrichmentReject policy fails
+// to enrich are sent as they are; call Enrich before Send to reject them
+// instead.
+func (en *Enricher) Defaulter() client.EventDefaulter {
+	return func(ctx context.Context, e event.Event) event.Event {
+		enriched, err := en.Enrich(ctx, e)
+		if err != nil {
+			cecontext.LoggerFrom(ctx).Warnw("failed to enrich the outgoing event", zap.String("id", e.ID()), zap.Error(err))
+			return e
+		}
+		return enriched
+	}
+}
+
+// StaticEnrichment returns an EnrichFunc adding the same extensions to every
+// event, e.g. the metadata of the deployment.
+func StaticEnrichment(extensions map[string]interface{}) EnrichFunc {
+	return func(context.Context, event.Event) (map[string]interface{}, error) {
+		return extensions, nil
+	}
+}
+
+// errNoRemoteIP is returned by RemoteIPEnrichment when the IP of the sender
+// is unknown.
+var errNoRemoteIP = errors.New("no remote IP address in the context")
+
+// RemoteIPEnrichment returns an EnrichFunc calling lookup with the IP
+// address of the sender of the HTTP request carrying the event, e.g. to add
+// its geographical location. The HTTP protocol must be configured with
+// cehttp.WithRequestDataAtContextMiddleware.
+func RemoteIPEnrichment(lookup func(ctx context.Context, ip net.IP) (map[string]interface{}, error)) EnrichFunc {
+	return func(ctx context.Context, _ event.Event) (map[string]interface{}, error) {
+		req := cehttp.RequestDataFromContext(ctx)
+		if req == nil {
+			return nil, errNoRemoteIP
+		}
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return nil, errNoRemoteIP
+		}
+		return lookup(ctx, ip)
+	}
+}
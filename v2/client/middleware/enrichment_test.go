@@ -0,0 +1,102 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func failingEnrichment(context.Context, event.Event) (map[string]interface{}, error) {
+	return nil, errors.New("unavailable")
+}
+
+func blockingEnrichment(ctx context.Context, _ event.Event) (map[string]interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestEnricher(t *testing.T) {
+	metrics := &counterMetrics{counters: map[string]int64{}}
+	en, err := NewEnricher([]EnrichmentProvider{
+		{Name: "deployment", Enrich: StaticEnrichment(map[string]interface{}{"region": "eu-west-1", "tenant": "other"})},
+		{Name: "flags", Enrich: StaticEnrichment(map[string]interface{}{"tenant": "acme", "beta": true}), Overwrite: true},
+		{Name: "failing", Enrich: failingEnrichment},
+		{Name: "slow", Enrich: blockingEnrichment, Timeout: 10 * time.Millisecond},
+		{Name: "invalid", Enrich: StaticEnrichment(map[string]interface{}{"valid": "a", "in-valid": "b"})},
+	}, WithEnrichmentMetrics(metrics))
+	require.NoError(t, err)
+
+	e := newEvent("order", "", "")
+	e.SetExtension("tenant", "initech")
+	enriched, err := en.Enrich(context.Background(), e)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"region": "eu-west-1", "tenant": "acme", "beta": true}, enriched.Extensions())
+	require.Equal(t, map[string]interface{}{"tenant": "initech"}, e.Extensions())
+	require.Equal(t, int64(3), metrics.counters[MetricEnrichmentFailures])
+}
+
+func TestEnricherReject(t *testing.T) {
+	en, err := NewEnricher([]EnrichmentProvider{
+		{Name: "deployment", Enrich: StaticEnrichment(map[string]interface{}{"region": "eu-west-1"})},
+		{Name: "slow", Enrich: func(context.Context, event.Event) (map[string]interface{}, error) {
+			time.Sleep(time.Second)
+			return nil, nil
+		}, Timeout: 10 * time.Millisecond, FailurePolicy: EnrichmentReject},
+	})
+	require.NoError(t, err)
+
+	var called bool
+	handler := en.Middleware()(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		called = true
+		return nil, nil
+	})
+	start := time.Now()
+	_, result := handler(context.Background(), newEvent("order", "", ""))
+	require.True(t, protocol.IsNACK(result))
+	require.ErrorIs(t, result, context.DeadlineExceeded)
+	require.False(t, called)
+	require.Less(t, time.Since(start), time.Second)
+
+	// The defaulter sends the events it fails to enrich as they are.
+	e := en.Defaulter()(context.Background(), newEvent("order", "", ""))
+	require.Empty(t, e.Extensions())
+}
+
+func TestNewEnricherInvalid(t *testing.T) {
+	_, err := NewEnricher([]EnrichmentProvider{{Enrich: failingEnrichment}})
+	require.Error(t, err)
+	_, err = NewEnricher([]EnrichmentProvider{{Name: "nil"}})
+	require.Error(t, err)
+	_, err = NewEnricher([]EnrichmentProvider{{Name: "negative", Enrich: failingEnrichment, Timeout: -1}})
+	require.Error(t, err)
+}
+
+func TestRemoteIPEnrichment(t *testing.T) {
+	enrich := RemoteIPEnrichment(func(_ context.Context, ip net.IP) (map[string]interface{}, error) {
+		return map[string]interface{}{"country": "ip " + ip.String()}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	extensions, err := enrich(cehttp.WithRequestDataAtContext(context.Background(), req), newEvent("order", "", ""))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"country": "ip 192.0.2.1"}, extensions)
+
+	_, err = enrich(context.Background(), newEvent("order", "", ""))
+	require.Error(t, err)
+}
@@ -0,0 +1,35 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// LogAttributes returns a middleware adding the attributes of the received
+// events selected by p to the logger of the context, so that the log lines of
+// the receiver function and of the following middlewares are correlated with
+// the events. A nil p adds all the attributes, observability.DefaultProjection
+// leaves the id out.
+func LogAttributes(p *observability.Projection) client.Middleware {
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			attrs := p.Attributes(e)
+			fields := make([]interface{}, 0, 2*len(attrs))
+			for _, a := range attrs {
+				fields = append(fields, a.Key, a.Value)
+			}
+			ctx = cecontext.WithLogger(ctx, cecontext.LoggerFrom(ctx).With(fields...))
+			return next(ctx, e)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestLogAttributes(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := cecontext.WithLogger(context.Background(), zap.New(core).Sugar())
+	h := LogAttributes(observability.DefaultProjection)(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		cecontext.LoggerFrom(ctx).Info("handling")
+		return nil, protocol.ResultACK
+	})
+
+	e := newEvent("order", "text/plain", "")
+	e.SetID("1")
+	_, result := h(ctx, e)
+	require.True(t, protocol.IsACK(result))
+	require.Equal(t, 1, logs.Len())
+	require.Equal(t, map[string]interface{}{
+		observability.SpecversionAttr:     "1.0",
+		observability.TypeAttr:            "order",
+		observability.SourceAttr:          "/source",
+		observability.DatacontenttypeAttr: "text/plain",
+	}, logs.All()[0].ContextMap())
+}
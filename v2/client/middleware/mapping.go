@@ -0,0 +1,308 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// MappingRule is a step of a Mapping. Exactly one of Set, Rename and Delete
+// is given:
+//
+//	# set an attribute or extension to a constant
+//	- set: source
+//	  value: /gateway
+//	# set it to the value of another attribute or extension
+//	- set: subject
+//	  from: orderid
+//	# set it to the value at a JSON pointer into the JSON data
+//	- set: subject
+//	  fromData: /order/id
+//	# rename an extension
+//	- rename: tenant
+//	  to: tenantid
+//	# delete an attribute or extension
+//	- delete: traceparent
+type MappingRule struct {
+	// Set is the attribute or extension to set, from Value, From or FromData.
+	Set string `json:"set,omitempty" yaml:"set,omitempty"`
+	// Value is a constant: a string, a boolean or an integer.
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	// From is the attribute or extension whose value is copied.
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+	// FromData is a JSON pointer (RFC 6901) into the data of the event,
+	// which must be JSON, to a string, a boolean or a number.
+	FromData string `json:"fromData,omitempty" yaml:"fromData,omitempty"`
+
+	// Rename is the extension renamed To.
+	Rename string `json:"rename,omitempty" yaml:"rename,omitempty"`
+	To     string `json:"to,omitempty" yaml:"to,omitempty"`
+
+	// Delete is the attribute or extension to delete.
+	Delete string `json:"delete,omitempty" yaml:"delete,omitempty"`
+}
+
+// Mapping transforms the attributes and extensions of events with rules
+// applied in order, so that gateways can be configured rather than coded.
+// The rules whose source attribute, extension or JSON pointer is missing
+// from an event are skipped.
+type Mapping struct {
+	rules []MappingRule
+}
+
+// mappingSpec is the document parsed by ParseMapping.
+type mappingSpec struct {
+	Rules []MappingRule `json:"rules" yaml:"rules"`
+}
+
+// NewMapping returns a Mapping applying rules in order.
+func NewMapping(rules ...MappingRule) (*Mapping, error) {
+	for i, r := range rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("invalid mapping rule %d: %w", i, err)
+		}
+	}
+	return &Mapping{rules: rules}, nil
+}
+
+// ParseMapping parses a Mapping from a JSON or YAML document holding its
+// rules, e.g.:
+//
+//	rules:
+//	  - set: subject
+//	    fromData: /order/id
+//	  - rename: tenant
+//	    to: tenantid
+func ParseMapping(doc []byte) (*Mapping, error) {
+	var s mappingSpec
+	d := yaml.NewDecoder(bytes.NewReader(doc))
+	d.KnownFields(true)
+	if err := d.Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to parse the mapping: %w", err)
+	}
+	return NewMapping(s.Rules...)
+}
+
+func (r MappingRule) validate() error {
+	switch {
+	case r.Set != "":
+		if r.Rename != "" || r.Delete != "" {
+			return errors.New("set can not be combined with rename or delete")
+		}
+		sources := 0
+		if r.Value != nil {
+			sources++
+			if _, err := normalizeMappingValue(r.Value); err != nil {
+				return err
+			}
+		}
+		if r.From != "" {
+			sources++
+		}
+		if r.FromData != "" {
+			sources++
+			if !strings.HasPrefix(r.FromData, "/") {
+				return fmt.Errorf("fromData %q is not a JSON pointer", r.FromData)
+			}
+		}
+		if sources != 1 {
+			return errors.New("set requires exactly one of value, from and fromData")
+		}
+		if r.Set == "specversion" {
+			return errors.New("specversion can not be set")
+		}
+	case r.Rename != "":
+		if r.Delete != "" {
+			return errors.New("rename can not be combined with delete")
+		}
+		if r.To == "" {
+			return errors.New("rename requires to")
+		}
+		if spec.V1.Attribute(r.Rename) != nil || spec.V1.Attribute(r.To) != nil {
+			return errors.New("only extensions can be renamed")
+		}
+	case r.Delete != "":
+		if r.Delete == "specversion" {
+			return errors.New("specversion can not be deleted")
+		}
+	default:
+		return errors.New("one of set, rename and delete is required")
+	}
+	if r.Set == "" && (r.Value != nil || r.From != "" || r.FromData != "") {
+		return errors.New("value, from and fromData require set")
+	}
+	if r.Rename == "" && r.To != "" {
+		return errors.New("to requires rename")
+	}
+	return nil
+}
+
+// Apply transforms e with the rules of the mapping.
+func (m *Mapping) Apply(e *event.Event) error {
+	var data interface{}
+	dataDecoded := false
+	for _, r := range m.rules {
+		switch {
+		case r.Set != "":
+			var v interface{}
+			switch {
+			case r.Value != nil:
+				v, _ = normalizeMappingValue(r.Value)
+			case r.From != "":
+				v = getAttribute(e, r.From)
+			default:
+				if !dataDecoded {
+					var err error
+					if data, err = decodeMappingData(e); err != nil {
+						return err
+					}
+					dataDecoded = true
+				}
+				found, ok := resolveJSONPointer(data, r.FromData)
+				if !ok {
+					continue
+				}
+				var err error
+				if v, err = normalizeMappingValue(found); err != nil {
+					return fmt.Errorf("data at %s: %w", r.FromData, err)
+				}
+			}
+			if v == nil {
+				continue
+			}
+			if err := setAttribute(e, r.Set, v); err != nil {
+				return err
+			}
+		case r.Rename != "":
+			v, ok := e.Extensions()[r.Rename]
+			if !ok {
+				continue
+			}
+			if err := e.Context.SetExtension(r.To, v); err != nil {
+				return err
+			}
+			if err := e.Context.SetExtension(r.Rename, nil); err != nil {
+				return err
+			}
+		default:
+			if attr := spec.V1.Attribute(r.Delete); attr != nil {
+				if err := attr.Delete(e.Context); err != nil {
+					return err
+				}
+			} else if err := e.Context.SetExtension(r.Delete, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Middleware returns a client.Middleware applying the mapping to the events
+// before invoking the receiver function, and NACKing the events it fails to
+// transform.
+func (m *Mapping) Middleware() client.Middleware {
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			e = e.Clone()
+			if err := m.Apply(&e); err != nil {
+				return nil, protocol.NewReceipt(false, "mapping error in incoming event: %w", err)
+			}
+			return next(ctx, e)
+		}
+	}
+}
+
+func getAttribute(e *event.Event, name string) interface{} {
+	if attr := spec.V1.Attribute(name); attr != nil {
+		return attr.Get(e.Context)
+	}
+	return e.Extensions()[name]
+}
+
+func setAttribute(e *event.Event, name string, v interface{}) error {
+	attr := spec.V1.Attribute(name)
+	if attr == nil {
+		return e.Context.SetExtension(name, v)
+	}
+	// The value is formatted, so that e.g. a string can be set to time.
+	s, err := types.Format(v)
+	if err != nil {
+		return err
+	}
+	return attr.Set(e.Context, s)
+}
+
+// normalizeMappingValue converts a constant of a rule or a JSON value to a
+// CloudEvents attribute value.
+func normalizeMappingValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string, bool:
+		return v, nil
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 32); err == nil {
+			return int32(i), nil
+		}
+		return string(v), nil
+	case int:
+		return types.Validate(v)
+	case float64:
+		if v == float64(int32(v)) {
+			return int32(v), nil
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	}
+	return nil, fmt.Errorf("value %#v is not a string, a boolean or a number", v)
+}
+
+func decodeMappingData(e *event.Event) (interface{}, error) {
+	if len(e.Data()) == 0 {
+		return nil, nil
+	}
+	d := json.NewDecoder(bytes.NewReader(e.Data()))
+	d.UseNumber()
+	var data interface{}
+	if err := d.Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode the data as JSON: %w", err)
+	}
+	return data, nil
+}
+
+// resolveJSONPointer returns the value of doc at pointer.
+func resolveJSONPointer(doc interface{}, pointer string) (interface{}, bool) {
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if doc, ok = v[token]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			doc = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return doc, doc != nil
+}
@@ -0,0 +1,113 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+const testMapping = `
+rules:
+  - set: source
+    value: /gateway
+  - set: subject
+    fromData: /order/id
+  - set: priority
+    fromData: /order/items/1/quantity
+  - set: origin
+    from: type
+  - set: subject
+    fromData: /missing
+  - rename: tenant
+    to: tenantid
+  - delete: dataschema
+  - set: time
+    value: "2024-01-02T03:04:05Z"
+  - set: replayed
+    value: true
+`
+
+func TestMapping(t *testing.T) {
+	m, err := ParseMapping([]byte(testMapping))
+	require.NoError(t, err)
+
+	e := newEvent("order.created", "application/json", "https://schemas/order")
+	e.SetExtension("tenant", "acme")
+	require.NoError(t, e.SetData("application/json", map[string]interface{}{
+		"order": map[string]interface{}{
+			"id":    "o-42",
+			"items": []interface{}{map[string]interface{}{"quantity": 1}, map[string]interface{}{"quantity": 7}},
+		},
+	}))
+	require.NoError(t, m.Apply(&e))
+
+	require.Equal(t, "/gateway", e.Source())
+	require.Equal(t, "o-42", e.Subject())
+	require.Equal(t, "", e.DataSchema())
+	require.Equal(t, "2024-01-02T03:04:05Z", e.Time().UTC().Format("2006-01-02T15:04:05Z"))
+	require.Equal(t, map[string]interface{}{
+		"priority": int32(7),
+		"origin":   "order.created",
+		"tenantid": "acme",
+		"replayed": true,
+	}, e.Extensions())
+}
+
+func TestMappingJSON(t *testing.T) {
+	m, err := ParseMapping([]byte(`{"rules": [{"set": "subject", "from": "tenant"}]}`))
+	require.NoError(t, err)
+	e := newEvent("order.created", "", "")
+	e.SetExtension("tenant", "acme")
+	require.NoError(t, m.Apply(&e))
+	require.Equal(t, "acme", e.Subject())
+}
+
+func TestMappingInvalid(t *testing.T) {
+	for name, doc := range map[string]string{
+		"unknown field":     `{"rules": [{"set": "subject", "valeu": "x"}]}`,
+		"no action":         `{"rules": [{"value": "x"}]}`,
+		"no source":         `{"rules": [{"set": "subject"}]}`,
+		"two sources":       `{"rules": [{"set": "subject", "value": "x", "from": "type"}]}`,
+		"invalid pointer":   `{"rules": [{"set": "subject", "fromData": "order"}]}`,
+		"rename without to": `{"rules": [{"rename": "tenant"}]}`,
+		"rename attribute":  `{"rules": [{"rename": "subject", "to": "topic"}]}`,
+		"set specversion":   `{"rules": [{"set": "specversion", "value": "0.3"}]}`,
+		"invalid value":     `{"rules": [{"set": "subject", "value": [1]}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseMapping([]byte(doc))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestMappingMiddleware(t *testing.T) {
+	m, err := NewMapping(MappingRule{Set: "subject", FromData: "/id"})
+	require.NoError(t, err)
+
+	var got event.Event
+	handler := m.Middleware()(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		got = e
+		return nil, nil
+	})
+
+	e := newEvent("order.created", "application/json", "")
+	require.NoError(t, e.SetData("application/json", map[string]string{"id": "o-1"}))
+	_, result := handler(context.Background(), e)
+	require.True(t, protocol.IsACK(result))
+	require.Equal(t, "o-1", got.Subject())
+	require.Equal(t, "", e.Subject())
+
+	require.NoError(t, e.SetData("text/plain", "not json"))
+	_, result = handler(context.Background(), e)
+	require.True(t, protocol.IsNACK(result))
+}
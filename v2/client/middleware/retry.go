@@ -0,0 +1,90 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Metrics emitted by the Retry middleware, see WithRetryMetrics.
+const (
+	// MetricRetryAttempts is a counter of the retries of the receiver
+	// function, per event type.
+	MetricRetryAttempts = "cloudevents.retry.attempts"
+	// MetricRetryExhausted is a counter of the events still failing once
+	// the retries are exhausted, per event type.
+	MetricRetryExhausted = "cloudevents.retry.exhausted"
+)
+
+// RetryOption configures the Retry middleware.
+type RetryOption func(*retryConfig)
+
+type retryConfig struct {
+	retriable func(protocol.Result) bool
+	metrics   observability.Metrics
+}
+
+// WithRetryIf sets the function deciding whether a result of the receiver
+// function, which is not an ACK, is retried, e.g. protocol.IsRetriable. By
+// default, all are retried.
+func WithRetryIf(retriable func(result protocol.Result) bool) RetryOption {
+	return func(c *retryConfig) {
+		c.retriable = retriable
+	}
+}
+
+// WithRetryMetrics records the MetricRetryAttempts and MetricRetryExhausted
+// metrics, partitioned by event type with the observability.TypeAttr
+// attribute.
+func WithRetryMetrics(metrics observability.Metrics) RetryOption {
+	return func(c *retryConfig) {
+		c.metrics = metrics
+	}
+}
+
+// Retry returns a middleware invoking the receiver function again, with the
+// backoff of params, while it does not acknowledge the event, up to
+// params.MaxTries retries. The last result is then returned, so that only the
+// events failing all the retries are not acknowledged to the protocol. This
+// avoids the redelivery of the events failing on transient errors, e.g. a
+// database timeout.
+//
+// Each try gets a copy of the event. The retries stop when the context is
+// done, so the handler timeout, if any, should allow for them.
+func Retry(params cecontext.RetryParams, opts ...RetryOption) client.Middleware {
+	config := retryConfig{
+		retriable: func(protocol.Result) bool { return true },
+		metrics:   observability.NoopMetrics{},
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			resp, result := next(ctx, e.Clone())
+			for tries := 1; !protocol.IsACK(result) && config.retriable(result); tries++ {
+				eventType := observability.Attribute{Key: observability.TypeAttr, Value: e.Type()}
+				if err := params.Backoff(ctx, tries); err != nil {
+					cecontext.LoggerFrom(ctx).Debugw("not retrying event", zap.String("source", e.Source()), zap.String("id", e.ID()),
+						zap.Int("retries", tries-1), zap.Error(err))
+					config.metrics.AddCounter(MetricRetryExhausted, 1, eventType)
+					break
+				}
+				config.metrics.AddCounter(MetricRetryAttempts, 1, eventType)
+				resp, result = next(ctx, e.Clone())
+			}
+			return resp, result
+		}
+	}
+}
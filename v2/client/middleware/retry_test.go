@@ -0,0 +1,83 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestRetry(t *testing.T) {
+	params := cecontext.RetryParams{Strategy: cecontext.BackoffStrategyConstant, MaxTries: 3, Period: time.Millisecond}
+	ctx := context.Background()
+	e := newEvent("order", "", "")
+
+	// The handler succeeds on the third try.
+	var calls int
+	metrics := &durationMetrics{counters: map[string]int64{}}
+	h := Retry(params, WithRetryMetrics(metrics))(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		calls++
+		e.SetExtension("tries", calls)
+		if calls < 3 {
+			return nil, errors.New("database timeout")
+		}
+		return nil, protocol.ResultACK
+	})
+	_, result := h(ctx, e)
+	require.True(t, protocol.IsACK(result))
+	require.Equal(t, 3, calls)
+	require.Equal(t, int64(2), metrics.counters[MetricRetryAttempts+" order"])
+	// The tries get copies of the event.
+	require.Empty(t, e.Extensions())
+
+	// The last result is returned once the retries are exhausted.
+	calls = 0
+	metrics = &durationMetrics{counters: map[string]int64{}}
+	h = Retry(params, WithRetryMetrics(metrics))(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		calls++
+		return nil, protocol.NewReceipt(false, "try %d", calls)
+	})
+	_, result = h(ctx, e)
+	require.True(t, protocol.IsNACK(result))
+	require.EqualError(t, result, "try 4")
+	require.Equal(t, 4, calls)
+	require.Equal(t, int64(1), metrics.counters[MetricRetryExhausted+" order"])
+
+	// The results which are not retriable are returned right away.
+	calls = 0
+	permanent := errors.New("invalid order")
+	h = Retry(params, WithRetryIf(func(result protocol.Result) bool {
+		return !errors.Is(result, permanent)
+	}))(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		calls++
+		return nil, permanent
+	})
+	_, result = h(ctx, e)
+	require.ErrorIs(t, result, permanent)
+	require.Equal(t, 1, calls)
+}
+
+func TestRetryCancelled(t *testing.T) {
+	params := cecontext.RetryParams{Strategy: cecontext.BackoffStrategyConstant, MaxTries: 3, Period: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	h := Retry(params)(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		calls++
+		cancel()
+		return nil, protocol.ResultNACK
+	})
+	_, result := h(ctx, newEvent("order", "", ""))
+	require.True(t, protocol.IsNACK(result))
+	require.Equal(t, 1, calls)
+}
@@ -0,0 +1,210 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+const (
+	// MetricTenantEvents counts the events received, per tenant.
+	MetricTenantEvents = "cloudevents.tenant.events"
+	// MetricTenantRateLimited counts the events rejected because the rate
+	// limit of their tenant is exceeded, per tenant.
+	MetricTenantRateLimited = "cloudevents.tenant.rate_limited"
+
+	// TenantAttr is the metric attribute holding the tenant.
+	TenantAttr = "cloudevents.tenant"
+)
+
+// ErrNoTenant is returned for the events whose tenant can not be derived by
+// a Tenancy configured with RequireTenant.
+var ErrNoTenant = errors.New("event has no tenant")
+
+// TenantExtractor derives the tenant of an event, returning an empty string
+// if the event has none.
+type TenantExtractor func(ctx context.Context, e event.Event) string
+
+// TenantFromAttribute derives the tenant from a context attribute or an
+// extension of the events, e.g. "source" or "tenantid".
+func TenantFromAttribute(name string) TenantExtractor {
+	return func(_ context.Context, e event.Event) string {
+		if e.Context == nil {
+			return ""
+		}
+		v := e.Extensions()[name]
+		if sv := spec.VS.Version(e.SpecVersion()); sv != nil {
+			if attr := sv.Attribute(name); attr != nil {
+				v = attr.Get(e.Context)
+			}
+		}
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	}
+}
+
+// TenantFromHeader derives the tenant from a header of the HTTP request
+// carrying the events, e.g. "X-Tenant-ID". The HTTP protocol must be
+// configured with cehttp.WithRequestDataAtContextMiddleware.
+func TenantFromHeader(name string) TenantExtractor {
+	return func(ctx context.Context, _ event.Event) string {
+		if req := cehttp.RequestDataFromContext(ctx); req != nil {
+			return req.Header.Get(name)
+		}
+		return ""
+	}
+}
+
+// TenantFromBasicAuth derives the tenant from the user name of the basic
+// authentication of the HTTP request carrying the events. The HTTP protocol
+// must be configured with cehttp.WithRequestDataAtContextMiddleware.
+func TenantFromBasicAuth() TenantExtractor {
+	return func(ctx context.Context, _ event.Event) string {
+		req := cehttp.RequestDataFromContext(ctx)
+		if req == nil {
+			return ""
+		}
+		user, _, ok := (&http.Request{Header: req.Header}).BasicAuth()
+		if !ok {
+			return ""
+		}
+		return user
+	}
+}
+
+// Tenancy derives the tenant of the received events, places it in the
+// context of the receiver function, see TenantFrom, and isolates the tenants
+// from each other with per-tenant rate limits.
+type Tenancy struct {
+	extractors []TenantExtractor
+	required   bool
+	limit      rate.Limit
+	burst      int
+	metrics    observability.Metrics
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// TenancyOption configures a Tenancy.
+type TenancyOption func(*Tenancy)
+
+// RequireTenant makes the Tenancy reject the events without tenant. By
+// default they are passed to the receiver function with an empty tenant.
+func RequireTenant() TenancyOption {
+	return func(t *Tenancy) {
+		t.required = true
+	}
+}
+
+// WithTenantRateLimit limits the events of each tenant to perSecond events
+// per second, with bursts of burst events. The events exceeding the limit
+// are NACKed with a result wrapping protocol.ErrRateLimited, so that the HTTP
+// protocol responds with 429 Too Many Requests.
+func WithTenantRateLimit(perSecond float64, burst int) TenancyOption {
+	return func(t *Tenancy) {
+		t.limit = rate.Limit(perSecond)
+		t.burst = burst
+	}
+}
+
+// WithTenantMetrics records the MetricTenantEvents and
+// MetricTenantRateLimited metrics, partitioned by tenant with the TenantAttr
+// attribute. The number of tenants should be bounded.
+func WithTenantMetrics(metrics observability.Metrics) TenancyOption {
+	return func(t *Tenancy) {
+		t.metrics = metrics
+	}
+}
+
+// NewTenancy returns a Tenancy deriving the tenant of the events with the
+// first extractor returning one.
+func NewTenancy(extractors []TenantExtractor, opts ...TenancyOption) *Tenancy {
+	t := &Tenancy{
+		extractors: extractors,
+		metrics:    observability.NoopMetrics{},
+		limiters:   make(map[string]*rate.Limiter),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Tenant returns the tenant of e.
+func (t *Tenancy) Tenant(ctx context.Context, e event.Event) string {
+	for _, extract := range t.extractors {
+		if tenant := extract(ctx, e); tenant != "" {
+			return tenant
+		}
+	}
+	return ""
+}
+
+// Allow reports whether an event of tenant is allowed by its rate limit.
+func (t *Tenancy) Allow(tenant string) bool {
+	if t.limit == 0 {
+		return true
+	}
+	t.mu.Lock()
+	l, ok := t.limiters[tenant]
+	if !ok {
+		l = rate.NewLimiter(t.limit, t.burst)
+		t.limiters[tenant] = l
+	}
+	t.mu.Unlock()
+	return l.Allow()
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context holding tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom returns the tenant of the event being received, or an empty
+// string.
+func TenantFrom(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// Middleware returns a client.Middleware placing the tenant of the events in
+// the context, and NACKing the events without tenant if RequireTenant is set
+// or exceeding the rate limit of their tenant.
+func (t *Tenancy) Middleware() client.Middleware {
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			tenant := t.Tenant(ctx, e)
+			if tenant == "" && t.required {
+				return nil, protocol.NewReceipt(false, "tenant isolation error in incoming event: %w", ErrNoTenant)
+			}
+			attr := observability.Attribute{Key: TenantAttr, Value: tenant}
+			t.metrics.AddCounter(MetricTenantEvents, 1, attr)
+			if !t.Allow(tenant) {
+				t.metrics.AddCounter(MetricTenantRateLimited, 1, attr)
+				return nil, protocol.NewReceipt(false, "tenant %q: %w", tenant, protocol.ErrRateLimited)
+			}
+			return next(WithTenant(ctx, tenant), e)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func TestTenantExtractors(t *testing.T) {
+	e := newEvent("order", "", "")
+	e.SetExtension("tenantid", "acme")
+
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	req.Header.Set("X-Tenant-ID", "globex")
+	req.SetBasicAuth("initech", "secret")
+	ctx := cehttp.WithRequestDataAtContext(context.Background(), req)
+
+	require.Equal(t, "acme", TenantFromAttribute("tenantid")(ctx, e))
+	require.Equal(t, "/source", TenantFromAttribute("source")(ctx, e))
+	require.Equal(t, "", TenantFromAttribute("missing")(ctx, e))
+	require.Equal(t, "globex", TenantFromHeader("X-Tenant-ID")(ctx, e))
+	require.Equal(t, "initech", TenantFromBasicAuth()(ctx, e))
+	require.Equal(t, "", TenantFromHeader("X-Tenant-ID")(context.Background(), e))
+
+	tenancy := NewTenancy([]TenantExtractor{TenantFromHeader("X-Missing"), TenantFromAttribute("tenantid")})
+	require.Equal(t, "acme", tenancy.Tenant(ctx, e))
+}
+
+func TestTenancyMiddleware(t *testing.T) {
+	metrics := &counterMetrics{counters: map[string]int64{}}
+	tenancy := NewTenancy([]TenantExtractor{TenantFromAttribute("tenantid")},
+		RequireTenant(), WithTenantRateLimit(1, 2), WithTenantMetrics(metrics))
+
+	var got []string
+	handler := tenancy.Middleware()(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		got = append(got, TenantFrom(ctx))
+		return nil, nil
+	})
+
+	acme := newEvent("order", "", "")
+	acme.SetExtension("tenantid", "acme")
+	globex := newEvent("order", "", "")
+	globex.SetExtension("tenantid", "globex")
+
+	for i := 0; i < 2; i++ {
+		_, result := handler(context.Background(), acme)
+		require.True(t, protocol.IsACK(result))
+	}
+	_, result := handler(context.Background(), acme)
+	require.True(t, protocol.IsNACK(result))
+	require.True(t, errors.Is(result, protocol.ErrRateLimited))
+
+	// The tenants are limited independently.
+	_, result = handler(context.Background(), globex)
+	require.True(t, protocol.IsACK(result))
+
+	_, result = handler(context.Background(), newEvent("order", "", ""))
+	require.True(t, protocol.IsNACK(result))
+	require.True(t, errors.Is(result, ErrNoTenant))
+
+	require.Equal(t, []string{"acme", "acme", "globex"}, got)
+	require.Equal(t, int64(4), metrics.counters[MetricTenantEvents])
+	require.Equal(t, int64(1), metrics.counters[MetricTenantRateLimited])
+}
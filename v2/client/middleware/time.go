@@ -0,0 +1,128 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/client"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// TimeSkewError is returned when the time attribute of an event is outside of
+// the window accepted by a TimeValidator.
+type TimeSkewError struct {
+	// Time is the time attribute of the event, zero if it is missing.
+	Time time.Time
+	// Now is the local time the event has been validated at.
+	Now time.Time
+}
+
+func (e *TimeSkewError) Error() string {
+	if e.Time.IsZero() {
+		return "event has no time attribute"
+	}
+	if skew := e.Time.Sub(e.Now); skew > 0 {
+		return fmt.Sprintf("event time %s is %s in the future", e.Time.Format(time.RFC3339Nano), skew)
+	}
+	return fmt.Sprintf("event time %s is %s in the past", e.Time.Format(time.RFC3339Nano), e.Now.Sub(e.Time))
+}
+
+// TimeValidator checks the time attribute of the events against the local
+// clock, in order to detect misconfigured producers and replayed events.
+type TimeValidator struct {
+	maxPast     time.Duration
+	maxFuture   time.Duration
+	requireTime bool
+	flagOnly    bool
+	now         func() time.Time
+}
+
+// TimeValidatorOption configures a TimeValidator.
+type TimeValidatorOption func(*TimeValidator)
+
+// RequireTime makes the TimeValidator reject the events without time
+// attribute. By default they are accepted.
+func RequireTime() TimeValidatorOption {
+	return func(v *TimeValidator) {
+		v.requireTime = true
+	}
+}
+
+// FlagTimeSkew makes the middleware of the TimeValidator pass the events
+// outside of the window to the receiver function, logging a warning and
+// recording the error in the context, see TimeSkewFrom, instead of NACKing
+// them.
+func FlagTimeSkew() TimeValidatorOption {
+	return func(v *TimeValidator) {
+		v.flagOnly = true
+	}
+}
+
+// NewTimeValidator returns a TimeValidator accepting the events whose time is
+// at most maxPast before and maxFuture after the local clock. The window
+// tolerates the clock skew between the producers and the consumer: maxFuture
+// is usually a few seconds, and maxPast bounds the delivery delay, including
+// the retries. A zero duration means unbounded.
+func NewTimeValidator(maxPast, maxFuture time.Duration, opts ...TimeValidatorOption) *TimeValidator {
+	v := &TimeValidator{maxPast: maxPast, maxFuture: maxFuture, now: time.Now}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Validate returns a *TimeSkewError if the time of e is outside of the
+// window.
+func (v *TimeValidator) Validate(e event.Event) error {
+	now := v.now()
+	t := e.Time()
+	if t.IsZero() {
+		if v.requireTime {
+			return &TimeSkewError{Now: now}
+		}
+		return nil
+	}
+	if (v.maxFuture > 0 && t.Sub(now) > v.maxFuture) || (v.maxPast > 0 && now.Sub(t) > v.maxPast) {
+		return &TimeSkewError{Time: t, Now: now}
+	}
+	return nil
+}
+
+type timeSkewKey struct{}
+
+// TimeSkewFrom returns the error of the events passed to the receiver function
+// despite being outside of the window of a TimeValidator configured with
+// FlagTimeSkew, or nil.
+func TimeSkewFrom(ctx context.Context) *TimeSkewError {
+	err, _ := ctx.Value(timeSkewKey{}).(*TimeSkewError)
+	return err
+}
+
+// Middleware returns a client.Middleware NACKing the events rejected by
+// Validate without invoking the receiver function, or flagging them if
+// FlagTimeSkew is set.
+func (v *TimeValidator) Middleware() client.Middleware {
+	return func(next client.Handler) client.Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			if err := v.Validate(e); err != nil {
+				if !v.flagOnly {
+					return nil, protocol.NewReceipt(false, "time validation error in incoming event: %w", err)
+				}
+				cecontext.LoggerFrom(ctx).Warnw("event time outside of the accepted window",
+					zap.String("source", e.Source()), zap.String("id", e.ID()), zap.Error(err))
+				ctx = context.WithValue(ctx, timeSkewKey{}, err.(*TimeSkewError))
+			}
+			return next(ctx, e)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestTimeValidator(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		time    time.Time
+		opts    []TimeValidatorOption
+		wantErr string
+	}{
+		"now":            {time: now},
+		"within past":    {time: now.Add(-time.Hour)},
+		"within future":  {time: now.Add(5 * time.Second)},
+		"too far past":   {time: now.Add(-2 * time.Hour), wantErr: "event time 2024-01-01T10:00:00Z is 2h0m0s in the past"},
+		"too far future": {time: now.Add(time.Minute), wantErr: "event time 2024-01-01T12:01:00Z is 1m0s in the future"},
+		"no time":        {},
+		"required time":  {opts: []TimeValidatorOption{RequireTime()}, wantErr: "event has no time attribute"},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			v := NewTimeValidator(time.Hour, 10*time.Second, tc.opts...)
+			v.now = func() time.Time { return now }
+			e := newEvent("order", "", "")
+			if !tc.time.IsZero() {
+				e.SetTime(tc.time)
+			}
+			err := v.Validate(e)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.wantErr)
+			var skewErr *TimeSkewError
+			require.True(t, errors.As(err, &skewErr))
+		})
+	}
+}
+
+func TestTimeValidatorUnbounded(t *testing.T) {
+	v := NewTimeValidator(0, 0)
+	e := newEvent("order", "", "")
+	e.SetTime(time.Unix(0, 0))
+	require.NoError(t, v.Validate(e))
+	e.SetTime(time.Now().Add(24 * time.Hour))
+	require.NoError(t, v.Validate(e))
+}
+
+func TestTimeValidatorMiddleware(t *testing.T) {
+	e := newEvent("order", "", "")
+	e.SetTime(time.Now().Add(time.Hour))
+
+	var called bool
+	var flagged *TimeSkewError
+	handler := func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		called = true
+		flagged = TimeSkewFrom(ctx)
+		return nil, protocol.ResultACK
+	}
+
+	_, result := NewTimeValidator(0, time.Second).Middleware()(handler)(context.Background(), e)
+	require.False(t, called)
+	require.True(t, protocol.IsNACK(result))
+	var skewErr *TimeSkewError
+	require.True(t, errors.As(result, &skewErr))
+
+	_, result = NewTimeValidator(0, time.Second, FlagTimeSkew()).Middleware()(handler)(context.Background(), e)
+	require.True(t, called)
+	require.True(t, protocol.IsACK(result))
+	require.NotNil(t, flagged)
+	require.Equal(t, e.Time(), flagged.Time)
+}
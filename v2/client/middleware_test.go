@@ -0,0 +1,101 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			*calls = append(*calls, name)
+			return next(ctx, e)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	h := Chain(func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+		calls = append(calls, "handler")
+		return nil, nil
+	}, recordingMiddleware("a", &calls), recordingMiddleware("b", &calls))
+
+	_, result := h(context.Background(), event.New())
+	require.NoError(t, result)
+	require.Equal(t, []string{"a", "b", "handler"}, calls)
+}
+
+func TestWithMiddleware(t *testing.T) {
+	e := test.FullEvent()
+	rejecting := func(next Handler) Handler {
+		return func(ctx context.Context, e event.Event) (*event.Event, protocol.Result) {
+			return nil, protocol.NewReceipt(false, "rejected")
+		}
+	}
+
+	testCases := map[string]struct {
+		middlewares func(calls *[]string) []Middleware
+		message     binding.Message
+		wantCalls   []string
+		wantACK     bool
+	}{
+		"in order": {
+			middlewares: func(calls *[]string) []Middleware {
+				return []Middleware{recordingMiddleware("a", calls), recordingMiddleware("b", calls)}
+			},
+			message:   binding.ToMessage(&e),
+			wantCalls: []string{"a", "b", "fn"},
+			wantACK:   true,
+		},
+		"short-circuit": {
+			middlewares: func(calls *[]string) []Middleware {
+				return []Middleware{recordingMiddleware("a", calls), rejecting, recordingMiddleware("b", calls)}
+			},
+			message:   binding.ToMessage(&e),
+			wantCalls: []string{"a"},
+		},
+		"malformed event": {
+			middlewares: func(calls *[]string) []Middleware {
+				return []Middleware{recordingMiddleware("a", calls)}
+			},
+			message: bindingtest.UnknownMessage,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var calls []string
+			c := &ceClient{}
+			require.NoError(t, c.applyOptions(WithMiddleware(tc.middlewares(&calls)...)))
+
+			invoker, err := newReceiveInvoker(func(ctx context.Context) {
+				calls = append(calls, "fn")
+			}, invokerConfig{middlewares: c.middlewares})
+			require.NoError(t, err)
+
+			var result error
+			m := binding.WithFinish(tc.message, func(err error) { result = err })
+			require.NoError(t, invoker.Invoke(context.Background(), m, noRespFn))
+			require.Equal(t, tc.wantACK, protocol.IsACK(result), "result: %v", result)
+			require.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
+
+func TestWithMiddlewareNil(t *testing.T) {
+	c := &ceClient{}
+	require.EqualError(t, c.applyOptions(WithMiddleware(nil)), "client option was given an nil middleware")
+}
@@ -8,8 +8,13 @@ package client
 import (
 	"context"
 	"fmt"
+	"mime"
+	"time"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/quarantine"
 )
 
 // Option is the function signature required to be considered an client.Option.
@@ -46,6 +51,88 @@ func WithForceStructured() Option {
 	}
 }
 
+// WithEncodingSelector sets the policy choosing the encoding of each event
+// sent by the client. It takes precedence over WithForceBinary and
+// WithForceStructured, while the send options set with WithSendOptions take
+// precedence over it.
+func WithEncodingSelector(fn EncodingSelector) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if fn == nil {
+				return fmt.Errorf("client option was given an nil encoding selector")
+			}
+			c.encodingSelector = fn
+		}
+		return nil
+	}
+}
+
+// WithContentTypeEncoding sends the events whose datacontenttype matches
+// contentType with the given encoding and, for binding.EncodingStructured,
+// marshalled with f, or format.JSON if nil. contentType is a media type, e.g.
+// "application/avro", or a range such as "application/*"; the parameters of
+// the datacontenttype are ignored. It may be given several times, the first
+// matching content type applies. It takes precedence over the encoding
+// selector and the client wide options, while the options given to Send take
+// precedence over it.
+func WithContentTypeEncoding(contentType string, encoding binding.Encoding, f format.Format) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil {
+				return fmt.Errorf("client option was given an invalid content type %q: %w", contentType, err)
+			}
+			if encoding != binding.EncodingBinary && encoding != binding.EncodingStructured {
+				return fmt.Errorf("client option was given an encoding %s which is not binary or structured", encoding)
+			}
+			c.contentTypeEncodings = append(c.contentTypeEncodings, contentTypeEncoding{
+				mediaType: mediaType,
+				encoding:  encoding,
+				format:    f,
+			})
+		}
+		return nil
+	}
+}
+
+// WithRetryBudget limits the retries of the events sent by the client to the
+// given budget, shared by all its sends and requests. The retries are
+// configured as usual, e.g. with context.WithRetriesExponentialBackoff.
+func WithRetryBudget(budget *cecontext.RetryBudget) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if budget == nil {
+				return fmt.Errorf("client option was given an nil retry budget")
+			}
+			c.outboundContextDecorators = append(c.outboundContextDecorators, func(ctx context.Context) context.Context {
+				return cecontext.WithRetryBudget(ctx, budget)
+			})
+		}
+		return nil
+	}
+}
+
+// WithRetryParams retries the events sent by the client with params, unless
+// the context of the send has its own retries, e.g. set with
+// context.WithRetriesExponentialBackoff. Given with WithRetryBudget, it must
+// be given first.
+func WithRetryParams(params cecontext.RetryParams) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if params.MaxTries < 0 {
+				return fmt.Errorf("client option was given a negative number of retries")
+			}
+			c.outboundContextDecorators = append(c.outboundContextDecorators, func(ctx context.Context) context.Context {
+				if cecontext.RetriesFrom(ctx) != &cecontext.DefaultRetryParams {
+					return ctx
+				}
+				return cecontext.WithRetryParams(ctx, &params)
+			})
+		}
+		return nil
+	}
+}
+
 // WithUUIDs adds DefaultIDToUUIDIfNotSet event defaulter to the end of the
 // defaulter chain.
 func WithUUIDs() Option {
@@ -57,6 +144,21 @@ func WithUUIDs() Option {
 	}
 }
 
+// WithIDGenerator adds an event defaulter setting the ID of the events without
+// ID with gen to the end of the defaulter chain. WithUUIDs is equivalent to
+// WithIDGenerator(UUIDv4Generator{}).
+func WithIDGenerator(gen IDGenerator) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if gen == nil {
+				return fmt.Errorf("client option was given an nil id generator")
+			}
+			c.eventDefaulterFns = append(c.eventDefaulterFns, NewDefaultIDIfNotSet(gen))
+		}
+		return nil
+	}
+}
+
 // WithTimeNow adds DefaultTimeToNowIfNotSet event defaulter to the end of the
 // defaulter chain.
 func WithTimeNow() Option {
@@ -68,6 +170,21 @@ func WithTimeNow() Option {
 	}
 }
 
+// WithDefaultingPolicy adds the event defaulters of p to the end of the
+// defaulter chain.
+func WithDefaultingPolicy(p DefaultingPolicy) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			fns, err := p.Defaulters()
+			if err != nil {
+				return fmt.Errorf("client option was given an invalid defaulting policy: %w", err)
+			}
+			c.eventDefaulterFns = append(c.eventDefaulterFns, fns...)
+		}
+		return nil
+	}
+}
+
 // WithTracePropagation enables trace propagation via the distributed tracing
 // extension.
 // Deprecated: this is now noop and will be removed in future releases.
@@ -115,6 +232,22 @@ func WithInboundContextDecorator(dec func(context.Context, binding.Message) cont
 	}
 }
 
+// WithTypeRegistry sets the TypeRegistry the receiver functions decode the
+// data of the received events with, see Typed and DecodeData.
+func WithTypeRegistry(r *TypeRegistry) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if r == nil {
+				return fmt.Errorf("client option was given a nil type registry")
+			}
+			c.inboundContextDecorators = append(c.inboundContextDecorators, func(ctx context.Context, _ binding.Message) context.Context {
+				return withTypeRegistry(ctx, r)
+			})
+		}
+		return nil
+	}
+}
+
 // WithBlockingCallback makes the callback passed into StartReceiver is executed as a blocking call,
 // i.e. in each poll go routine, the next event will not be received until the callback on current event completes.
 // To make event processing serialized (no concurrency), use this option along with WithPollGoroutines(1)
@@ -127,6 +260,99 @@ func WithBlockingCallback() Option {
 	}
 }
 
+// WithInflightWatermarks bounds the number of events received but not handled
+// yet, waiting for a dispatch worker or being handled: when it reaches high,
+// the client pauses the consumption, see Backpressure, and resumes it once it
+// falls to low. Without it, events are received as fast as the protocol
+// delivers them.
+func WithInflightWatermarks(high, low int) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if high <= 0 {
+				return fmt.Errorf("client option was given a non positive high watermark")
+			}
+			if low < 0 || low >= high {
+				return fmt.Errorf("client option was given a low watermark not between 0 and the high watermark")
+			}
+			c.inflightHigh = high
+			c.inflightLow = low
+		}
+		return nil
+	}
+}
+
+// WithOrderedDispatch serializes the invocations of the receiver function given
+// to StartReceiver per partitionkey extension: events with the same
+// partitionkey are handled one after the other, in the order they are
+// received, while events with different keys are handled concurrently by the
+// given number of workers. Events without partitionkey are dispatched as usual.
+// The order is the one of each poll goroutine, so use this option along with
+// WithPollGoroutines(1) to keep the order of the protocol.
+// Structured messages are decoded before being dispatched, so inbound context
+// decorators receive them as binding.EventMessage.
+func WithOrderedDispatch(workers int) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if workers <= 0 {
+				return fmt.Errorf("client option was given a non positive number of ordered dispatch workers")
+			}
+			c.orderedDispatchWorkers = workers
+		}
+		return nil
+	}
+}
+
+// WithPriorityDispatch invokes the receiver function given to StartReceiver on
+// the given number of workers, handling first the events with the highest
+// priority extension (see extensions.AddPriorityExtension) when all the
+// workers are busy. Events without priority have extensions.PriorityNormal.
+// Events dispatched per partitionkey by WithOrderedDispatch keep their order
+// and do not use the priority workers.
+// Structured messages are decoded before being dispatched, so inbound context
+// decorators receive them as binding.EventMessage.
+func WithPriorityDispatch(workers int) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if workers <= 0 {
+				return fmt.Errorf("client option was given a non positive number of priority dispatch workers")
+			}
+			c.priorityDispatchWorkers = workers
+		}
+		return nil
+	}
+}
+
+// WithShardedDispatch invokes the receiver function given to StartReceiver on
+// the given number of independent groups of workers, each with its own
+// workers and queue configured by config. Events are assigned to a group by a
+// hash of the value of the given attribute, e.g. "type" or an extension name,
+// so that a burst of events with some value only delays the events sharing
+// its group. Events without the attribute share the group of the empty value.
+// Events dispatched per partitionkey by WithOrderedDispatch keep their order
+// and do not use the shards, and the shards take precedence over
+// WithPriorityDispatch.
+// Structured messages are decoded before being dispatched, so inbound context
+// decorators receive them as binding.EventMessage.
+func WithShardedDispatch(attribute string, shards int, config ShardConfig) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if attribute == "" {
+				return fmt.Errorf("client option was given an empty shard attribute")
+			}
+			if shards <= 0 {
+				return fmt.Errorf("client option was given a non positive number of shards")
+			}
+			if config.Workers <= 0 {
+				return fmt.Errorf("client option was given a non positive number of workers per shard")
+			}
+			c.shardAttribute = attribute
+			c.shards = shards
+			c.shardConfig = config
+		}
+		return nil
+	}
+}
+
 // WithAckMalformedevents causes malformed events received within StartReceiver to be acknowledged
 // rather than being permanently not-acknowledged. This can be useful when a protocol does not
 // provide a responder implementation and would otherwise cause the receiver to be partially or
@@ -139,3 +365,126 @@ func WithAckMalformedEvent() Option {
 		return nil
 	}
 }
+
+// WithRedeliveryExtensions makes the client set the deliveryattempt and
+// firstdeliverytime extensions of the received events from the redelivery
+// state of the protocols exposing it in their protocol.TransportMetadata,
+// e.g. Pub/Sub and JetStream, so that the receiver functions can implement
+// attempt-aware logic portably, see extensions.GetDeliveryAttempt.
+func WithRedeliveryExtensions() Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			c.redeliveryExtensions = true
+		}
+		return nil
+	}
+}
+
+// WithQuarantine makes the client put the received messages which can not be
+// converted to valid events in store, with their raw content and transport
+// metadata, and acknowledge them once stored instead of dropping them.
+// Stored messages can be fixed and sent again with quarantine.Reinject. The
+// content of every received message is copied to be quarantined if needed.
+func WithQuarantine(store quarantine.Store) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if store == nil {
+				return fmt.Errorf("client option was given a nil quarantine store")
+			}
+			c.quarantineStore = store
+		}
+		return nil
+	}
+}
+
+// WithPanicHandler sets the handler invoked when the receiver function given to
+// StartReceiver panics. Panics are always recovered: without a handler, the
+// panic is reported to the protocol as an error.
+func WithPanicHandler(fn PanicHandler) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if fn == nil {
+				return fmt.Errorf("client option was given an nil panic handler")
+			}
+			c.panicHandler = fn
+		}
+		return nil
+	}
+}
+
+// WithHandlerTimeout sets the maximum duration of each invocation of the
+// receiver function given to StartReceiver. Once it expires, the context given
+// to the function is cancelled and the event is not acknowledged, without
+// waiting for the function to return. Middlewares see this result, so that
+// they can dead-letter the event for example.
+func WithHandlerTimeout(timeout time.Duration) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if timeout <= 0 {
+				return fmt.Errorf("client option was given a non positive handler timeout")
+			}
+			c.handlerTimeout = timeout
+		}
+		return nil
+	}
+}
+
+// WithMiddleware appends middlewares wrapping the receiver function given to
+// StartReceiver. Middlewares are invoked in the order they are added, after
+// the incoming event has been validated.
+// When a middleware is configured, malformed events are rejected even if the
+// receiver function does not take the event as a parameter.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			for _, mw := range middlewares {
+				if mw == nil {
+					return fmt.Errorf("client option was given an nil middleware")
+				}
+			}
+			c.middlewares = append(c.middlewares, middlewares...)
+		}
+		return nil
+	}
+}
+
+// WithAuthorizer sets the Authorizer deciding whether the received events are
+// passed to the receiver function, batch receiver functions included. It is
+// invoked before the middlewares, once the event has been validated. The
+// denied events are NACKed with a result wrapping protocol.ErrForbidden and
+// the reason of the denial, so that the HTTP protocol responds with 403
+// Forbidden.
+// When an Authorizer is configured, malformed events are rejected even if
+// the receiver function does not take the event as a parameter.
+func WithAuthorizer(a Authorizer) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if a == nil {
+				return fmt.Errorf("client option was given an nil authorizer")
+			}
+			c.authorizer = a
+		}
+		return nil
+	}
+}
+
+// WithOutboundQueue makes Send queue the events in q instead of sending them,
+// and return once they are queued: workers goroutines send the queued
+// events. When q is full, Send follows the OverflowPolicy of q. The results
+// of the deliveries are given to the function set with the Async send
+// option, if any. Close q to send the remaining events before stopping.
+func WithOutboundQueue(q *OutboundQueue, workers int) Option {
+	return func(i interface{}) error {
+		if c, ok := i.(*ceClient); ok {
+			if q == nil {
+				return fmt.Errorf("client option was given an nil outbound queue")
+			}
+			if workers <= 0 {
+				return fmt.Errorf("client option was given a non positive number of outbound queue workers")
+			}
+			c.outboundQueue = q
+			c.outboundWorkers = workers
+		}
+		return nil
+	}
+}
@@ -8,7 +8,9 @@ package client
 import (
 	"context"
 	"testing"
+	"time"
 
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
 
 	"github.com/google/go-cmp/cmp"
@@ -164,3 +166,38 @@ func TestWithAckMalformedEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestWithRetryParams(t *testing.T) {
+	params := cecontext.RetryParams{Strategy: cecontext.BackoffStrategyConstant, MaxTries: 3, Period: time.Second}
+	budget, err := cecontext.NewRetryBudget(0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &ceClient{}
+	if err := client.applyOptions(WithRetryParams(params), WithRetryBudget(budget)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, f := range client.outboundContextDecorators {
+		ctx = f(ctx)
+	}
+	want := params
+	want.Budget = budget
+	if got := *cecontext.RetriesFrom(ctx); got != want {
+		t.Errorf("unexpected retry params; want: %v; got: %v", want, got)
+	}
+
+	// The retries of the context of the send take precedence.
+	ctx = cecontext.WithRetriesLinearBackoff(context.Background(), time.Millisecond, 1)
+	for _, f := range client.outboundContextDecorators[:1] {
+		ctx = f(ctx)
+	}
+	if got := cecontext.RetriesFrom(ctx); got.Strategy != cecontext.BackoffStrategyLinear {
+		t.Errorf("unexpected retry strategy; want: %s; got: %s", cecontext.BackoffStrategyLinear, got.Strategy)
+	}
+
+	if err := client.applyOptions(WithRetryParams(cecontext.RetryParams{MaxTries: -1})); err == nil {
+		t.Error("expected an error for a negative number of retries")
+	}
+}
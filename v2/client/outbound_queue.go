@@ -0,0 +1,221 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/observability"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+const (
+	// MetricOutboundQueueDepth is the number of events waiting in the
+	// outbound queue.
+	MetricOutboundQueueDepth = "cloudevents.client.outbound_queue.depth"
+	// MetricOutboundQueueDropped counts the events dropped or rejected
+	// because the outbound queue was full.
+	MetricOutboundQueueDropped = "cloudevents.client.outbound_queue.dropped"
+)
+
+var (
+	// ErrQueueFull is returned by Send when the outbound queue is full and
+	// its policy is OverflowError.
+	ErrQueueFull = errors.New("outbound queue is full")
+	// ErrEventDropped is the result of the events dropped from the outbound
+	// queue by the OverflowDropOldest policy.
+	ErrEventDropped = errors.New("event dropped from the outbound queue")
+	// ErrQueueClosed is returned by Send once the outbound queue is closed.
+	ErrQueueClosed = errors.New("outbound queue is closed")
+)
+
+// OverflowPolicy is what an OutboundQueue does with an event sent while it
+// is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes Send wait until the queue has room for the event,
+	// or its context is done.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest event of the queue to make room
+	// for the event. The result of the dropped event is ErrEventDropped.
+	OverflowDropOldest
+	// OverflowError makes Send fail with ErrQueueFull.
+	OverflowError
+)
+
+// OutboundQueue is a bounded in-memory queue of the events sent by a client
+// configured with WithOutboundQueue: Send returns once the event is queued,
+// and workers send the queued events with the protocol. The result of the
+// delivery is given to the function set with the Async send option, if any.
+//
+// The events queued are lost if the process stops before they are sent, see
+// Close.
+type OutboundQueue struct {
+	policy   OverflowPolicy
+	metrics  observability.Metrics
+	callback func(depth, capacity int)
+
+	// mu guards closed, and is held for reading while queueing so that the
+	// queue is not closed meanwhile.
+	mu     sync.RWMutex
+	closed bool
+	queue  chan queuedEvent
+	start  sync.Once
+	wg     sync.WaitGroup
+}
+
+type queuedEvent struct {
+	ctx context.Context
+	e   event.Event
+	// cb records the result with the observability service.
+	cb func(error)
+	fn protocol.ResultFn
+}
+
+// OutboundQueueOption configures an OutboundQueue.
+type OutboundQueueOption func(*OutboundQueue)
+
+// WithOutboundQueueMetrics records the MetricOutboundQueueDepth and
+// MetricOutboundQueueDropped metrics.
+func WithOutboundQueueMetrics(metrics observability.Metrics) OutboundQueueOption {
+	return func(q *OutboundQueue) {
+		q.metrics = metrics
+	}
+}
+
+// WithOutboundQueueCallback invokes fn with the depth of the queue each time
+// it changes, e.g. to shed load before the queue is full. fn must not block.
+func WithOutboundQueueCallback(fn func(depth, capacity int)) OutboundQueueOption {
+	return func(q *OutboundQueue) {
+		q.callback = fn
+	}
+}
+
+// NewOutboundQueue returns an OutboundQueue holding at most size events.
+func NewOutboundQueue(size int, policy OverflowPolicy, opts ...OutboundQueueOption) (*OutboundQueue, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("outbound queue was given a non positive size: %d", size)
+	}
+	if policy < OverflowBlock || policy > OverflowError {
+		return nil, fmt.Errorf("outbound queue was given an unknown overflow policy %d", policy)
+	}
+	q := &OutboundQueue{
+		policy:  policy,
+		metrics: observability.NoopMetrics{},
+		queue:   make(chan queuedEvent, size),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q, nil
+}
+
+// Len returns the number of events waiting in the queue.
+func (q *OutboundQueue) Len() int {
+	return len(q.queue)
+}
+
+// Cap returns the maximum number of events of the queue.
+func (q *OutboundQueue) Cap() int {
+	return cap(q.queue)
+}
+
+func (q *OutboundQueue) depthChanged() {
+	depth := len(q.queue)
+	q.metrics.SetGauge(MetricOutboundQueueDepth, int64(depth))
+	if q.callback != nil {
+		q.callback(depth, cap(q.queue))
+	}
+}
+
+// enqueue queues ev following the overflow policy.
+func (q *OutboundQueue) enqueue(ctx context.Context, ev queuedEvent) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	for {
+		select {
+		case q.queue <- ev:
+			q.depthChanged()
+			return nil
+		default:
+		}
+		switch q.policy {
+		case OverflowError:
+			q.metrics.AddCounter(MetricOutboundQueueDropped, 1)
+			return ErrQueueFull
+		case OverflowDropOldest:
+			select {
+			case dropped := <-q.queue:
+				q.metrics.AddCounter(MetricOutboundQueueDropped, 1)
+				dropped.done(ErrEventDropped)
+			default:
+			}
+		default:
+			select {
+			case q.queue <- ev:
+				q.depthChanged()
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// run starts the workers sending the queued events with send, once.
+func (q *OutboundQueue) run(workers int, send func(queuedEvent) error) {
+	q.start.Do(func() {
+		q.wg.Add(workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				defer q.wg.Done()
+				for ev := range q.queue {
+					q.depthChanged()
+					ev.done(send(ev))
+				}
+			}()
+		}
+	})
+}
+
+func (ev queuedEvent) done(result error) {
+	ev.cb(result)
+	if ev.fn != nil {
+		ev.fn(result)
+	}
+}
+
+// Close stops accepting events, and waits until the queued events are sent
+// or ctx is done.
+func (q *OutboundQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
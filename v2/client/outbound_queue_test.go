@@ -0,0 +1,167 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// gatedSender sends the events once the gate is open.
+type gatedSender struct {
+	gate chan struct{}
+
+	mu   sync.Mutex
+	sent []string
+}
+
+func (s *gatedSender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	<-s.gate
+	e, err := binding.ToEvent(ctx, m)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, e.ID())
+	return nil
+}
+
+func (s *gatedSender) Close(context.Context) error { return nil }
+
+func testQueuedEvent(id string) event.Event {
+	e := event.New()
+	e.SetID(id)
+	e.SetSource("/source")
+	e.SetType("type")
+	return e
+}
+
+type queueGauge struct {
+	mu     sync.Mutex
+	depths []int
+}
+
+func (g *queueGauge) record(depth, capacity int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.depths = append(g.depths, depth)
+}
+
+func TestOutboundQueue(t *testing.T) {
+	testCases := map[string]struct {
+		policy      OverflowPolicy
+		wantErr     error
+		wantSent    []string
+		wantDropped []string
+	}{
+		"block": {
+			policy:  OverflowBlock,
+			wantErr: context.DeadlineExceeded,
+			// The first event is being sent, the second and third are queued.
+			wantSent: []string{"1", "2", "3"},
+		},
+		"drop oldest": {
+			policy:      OverflowDropOldest,
+			wantSent:    []string{"1", "3", "4"},
+			wantDropped: []string{"2"},
+		},
+		"error": {
+			policy:   OverflowError,
+			wantErr:  ErrQueueFull,
+			wantSent: []string{"1", "2", "3"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			sender := &gatedSender{gate: make(chan struct{})}
+			var gauge queueGauge
+			q, err := NewOutboundQueue(2, tc.policy, WithOutboundQueueCallback(gauge.record))
+			require.NoError(t, err)
+			c, err := New(sender, WithOutboundQueue(q, 1))
+			require.NoError(t, err)
+
+			var mu sync.Mutex
+			var dropped []string
+			send := func(ctx context.Context, id string) error {
+				ctx = WithSendOptions(ctx, Async(func(result protocol.Result) {
+					if errors.Is(result, ErrEventDropped) {
+						mu.Lock()
+						dropped = append(dropped, id)
+						mu.Unlock()
+					}
+				}))
+				return c.Send(ctx, testQueuedEvent(id))
+			}
+
+			require.NoError(t, send(context.Background(), "1"))
+			// Wait for the worker to take the first event.
+			require.Eventually(t, func() bool { return q.Len() == 0 }, time.Second, time.Millisecond)
+			require.NoError(t, send(context.Background(), "2"))
+			require.NoError(t, send(context.Background(), "3"))
+			require.Equal(t, 2, q.Len())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			err = send(ctx, "4")
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			close(sender.gate)
+			require.NoError(t, q.Close(context.Background()))
+			require.Equal(t, tc.wantSent, sender.sent)
+			require.Equal(t, tc.wantDropped, dropped)
+			require.ErrorIs(t, send(context.Background(), "5"), ErrQueueClosed)
+
+			gauge.mu.Lock()
+			defer gauge.mu.Unlock()
+			require.Contains(t, gauge.depths, 2)
+			require.Equal(t, 0, gauge.depths[len(gauge.depths)-1])
+		})
+	}
+}
+
+func TestOutboundQueueDetachesContext(t *testing.T) {
+	sender := &gatedSender{gate: make(chan struct{})}
+	q, err := NewOutboundQueue(1, OverflowError)
+	require.NoError(t, err)
+	c, err := New(sender, WithOutboundQueue(q, 1))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan protocol.Result, 1)
+	require.NoError(t, c.Send(WithSendOptions(ctx, Async(func(result protocol.Result) { results <- result })), testQueuedEvent("1")))
+	cancel()
+	close(sender.gate)
+	require.NoError(t, <-results)
+	require.NoError(t, q.Close(context.Background()))
+}
+
+func TestNewOutboundQueueInvalid(t *testing.T) {
+	_, err := NewOutboundQueue(0, OverflowBlock)
+	require.EqualError(t, err, "outbound queue was given a non positive size: 0")
+	_, err = NewOutboundQueue(1, OverflowPolicy(42))
+	require.EqualError(t, err, "outbound queue was given an unknown overflow policy 42")
+
+	q, err := NewOutboundQueue(1, OverflowBlock)
+	require.NoError(t, err)
+	_, err = New(&gatedSender{}, WithOutboundQueue(nil, 1))
+	require.EqualError(t, err, "client option was given an nil outbound queue")
+	_, err = New(&gatedSender{}, WithOutboundQueue(q, 0))
+	require.EqualError(t, err, "client option was given a non positive number of outbound queue workers")
+}
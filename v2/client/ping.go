@@ -0,0 +1,72 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// PingOption configures Ping.
+type PingOption func(*pingConfig)
+
+type pingCheck struct {
+	name  string
+	check func(context.Context) error
+}
+
+type pingConfig struct {
+	checks []pingCheck
+}
+
+// WithPingCheck adds a check named name to the ones of Ping, e.g. resolving
+// the schemas of the events sent or received up front, so that a misconfigured
+// schema registry is reported at startup too.
+func WithPingCheck(name string, check func(ctx context.Context) error) PingOption {
+	return func(c *pingConfig) {
+		c.checks = append(c.checks, pingCheck{name: name, check: check})
+	}
+}
+
+// Ping checks that the client c is ready to send and receive events, so that
+// deployments fail fast at startup rather than on the first event. If the
+// protocol of c implements protocol.Pinger, it establishes its connections to
+// the broker and authenticates, then the checks added with WithPingCheck are
+// run, in order. A client which was not returned by New is pinged if it
+// implements protocol.Pinger itself, e.g. a wrapper forwarding to the client
+// it wraps.
+//
+// Ping returns the failures of the protocol and of all the checks joined, each
+// wrapped with the name of what failed, so that errors.As finds the details,
+// e.g. the *http.Result of a rejected authentication.
+func Ping(ctx context.Context, c Client, opts ...PingOption) error {
+	config := pingConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var pinger protocol.Pinger
+	if cc, ok := c.(*ceClient); ok {
+		pinger = cc.pinger
+	} else if p, ok := c.(protocol.Pinger); ok {
+		pinger = p
+	}
+	var errs []error
+	if pinger != nil {
+		if err := pinger.Ping(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("protocol: %w", err))
+		}
+	}
+	for _, check := range config.checks {
+		if err := check.check(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", check.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
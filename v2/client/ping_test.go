@@ -0,0 +1,69 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func TestPing(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.WriteHeader(nethttp.StatusForbidden)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	c, err := NewHTTP(http.WithTarget(server.URL))
+	require.NoError(t, err)
+	errSchemas := errors.New("schema not found")
+	var checked []string
+	err = Ping(ctx, c,
+		WithPingCheck("schemas", func(context.Context) error {
+			checked = append(checked, "schemas")
+			return errSchemas
+		}),
+		WithPingCheck("database", func(context.Context) error {
+			checked = append(checked, "database")
+			return nil
+		}),
+	)
+	require.Equal(t, []string{"schemas", "database"}, checked)
+	require.ErrorIs(t, err, errSchemas)
+	var result *http.Result
+	require.ErrorAs(t, err, &result)
+	require.Equal(t, nethttp.StatusForbidden, result.StatusCode)
+	require.Contains(t, err.Error(), "protocol: ")
+	require.Contains(t, err.Error(), "schemas: schema not found")
+
+	// The protocols which can not check their connectivity only run the checks.
+	c, err = New(gochan.New())
+	require.NoError(t, err)
+	require.NoError(t, Ping(ctx, c))
+
+	// A client wrapping another one forwards the ping.
+	errDown := errors.New("broker down")
+	require.ErrorIs(t, Ping(ctx, pingingClient{Client: c, err: errDown}), errDown)
+}
+
+// pingingClient is a client which is not returned by New, implementing
+// protocol.Pinger.
+type pingingClient struct {
+	Client
+	err error
+}
+
+func (c pingingClient) Ping(context.Context) error {
+	return c.err
+}
@@ -0,0 +1,48 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/quarantine"
+)
+
+// captureForQuarantine captures the content of m when the client has a
+// quarantine store, see WithQuarantine. It returns the message to read the
+// event from, as the content of m may only be readable once, and the entry
+// to quarantine if the event is malformed.
+func captureForQuarantine(ctx context.Context, store quarantine.Store, m binding.Message) (binding.Message, *quarantine.Entry) {
+	if store == nil {
+		return m, nil
+	}
+	entry, err := quarantine.Capture(ctx, m)
+	if err != nil {
+		cecontext.LoggerFrom(ctx).Warnw("failed to capture message for quarantine", zap.Error(err))
+		return m, nil
+	}
+	return entry.Message(), entry
+}
+
+// quarantineMalformed puts the entry of a malformed event in store, and
+// returns the ACK once it is persisted, or malformed if it can not be.
+func quarantineMalformed(ctx context.Context, store quarantine.Store, entry *quarantine.Entry, cause error, malformed protocol.Result) protocol.Result {
+	if entry == nil {
+		return malformed
+	}
+	entry.Reason = cause.Error()
+	if err := store.Put(ctx, entry); err != nil {
+		cecontext.LoggerFrom(ctx).Errorw("failed to quarantine malformed event", zap.Error(err), zap.NamedError("cause", cause))
+		return malformed
+	}
+	cecontext.LoggerFrom(ctx).Warnw("quarantined malformed event", zap.String("id", entry.ID), zap.NamedError("cause", cause))
+	return protocol.ResultACK
+}
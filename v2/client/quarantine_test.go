@@ -0,0 +1,101 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/quarantine"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+type failingStore struct {
+	*quarantine.MemoryStore
+}
+
+func (failingStore) Put(context.Context, *quarantine.Entry) error {
+	return errors.New("store down")
+}
+
+func TestQuarantine(t *testing.T) {
+	invalid := test.FullEvent()
+	invalid.Context.(*event.EventContextV1).ID = ""
+
+	for name, tc := range map[string]struct {
+		store  func() quarantine.Store
+		event  event.Event
+		called bool
+		ack    bool
+		stored int
+	}{
+		"valid": {
+			store:  func() quarantine.Store { return quarantine.NewMemoryStore() },
+			event:  test.FullEvent(),
+			called: true,
+			ack:    true,
+		},
+		"invalid": {
+			store:  func() quarantine.Store { return quarantine.NewMemoryStore() },
+			event:  invalid,
+			ack:    true,
+			stored: 1,
+		},
+		"store failed": {
+			store: func() quarantine.Store { return failingStore{quarantine.NewMemoryStore()} },
+			event: invalid,
+		},
+		"disabled": {
+			store: func() quarantine.Store { return nil },
+			event: invalid,
+		},
+	} {
+		for _, batch := range []bool{false, true} {
+			t.Run(name, func(t *testing.T) {
+				store := tc.store()
+				called := false
+				var invoker Invoker
+				if batch {
+					invoker = &batchInvoker{fn: func(ctx context.Context, events []event.Event) []protocol.Result {
+						called = true
+						return nil
+					}, observabilityService: noopObservabilityService{}, quarantineStore: store, stats: &flowStats{}}
+				} else {
+					var err error
+					invoker, err = newReceiveInvoker(func(ctx context.Context, e event.Event) {
+						called = true
+					}, invokerConfig{quarantineStore: store})
+					require.NoError(t, err)
+				}
+
+				var result protocol.Result
+				require.NoError(t, invoker.Invoke(context.Background(), bindingtest.MustCreateMockBinaryMessage(tc.event), func(ctx context.Context, m binding.Message, r protocol.Result, transformers ...binding.Transformer) error {
+					result = r
+					return nil
+				}))
+				require.Equal(t, tc.called, called)
+				require.Equal(t, tc.ack, protocol.IsACK(result), "result: %v", result)
+
+				if ms, ok := store.(*quarantine.MemoryStore); ok {
+					entries, err := ms.List(context.Background())
+					require.NoError(t, err)
+					require.Len(t, entries, tc.stored)
+					if tc.stored > 0 {
+						require.Contains(t, entries[0].Reason, "id")
+						require.Equal(t, "", entries[0].Attributes["id"])
+					}
+				}
+			})
+		}
+	}
+}
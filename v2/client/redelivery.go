@@ -0,0 +1,21 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// addRedeliveryExtensions sets the redelivery extensions of e from the
+// transport metadata of m, if m exposes it, see WithRedeliveryExtensions.
+func addRedeliveryExtensions(m binding.Message, e *event.Event) {
+	if r, ok := m.(protocol.TransportMetadataReader); ok {
+		extensions.AddRedeliveryExtensions(e, r.TransportMetadata())
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/extensions"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// redeliveredMessage is a message redelivered by its transport.
+type redeliveredMessage struct {
+	*binding.EventMessage
+	md protocol.TransportMetadata
+}
+
+func (m redeliveredMessage) GetWrappedMessage() binding.Message {
+	return m.EventMessage
+}
+
+func (m redeliveredMessage) TransportMetadata() protocol.TransportMetadata {
+	return m.md
+}
+
+func TestRedeliveryExtensions(t *testing.T) {
+	first := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	md := protocol.TransportMetadata{DeliveryAttempt: 2, FirstDeliveryTime: first}
+
+	for name, enabled := range map[string]bool{"enabled": true, "disabled": false} {
+		t.Run(name, func(t *testing.T) {
+			var got event.Event
+			invoker, err := newReceiveInvoker(func(ctx context.Context, e event.Event) {
+				got = e
+			}, invokerConfig{redeliveryExtensions: enabled})
+			require.NoError(t, err)
+
+			e := test.FullEvent()
+			require.NoError(t, invoker.Invoke(context.Background(), redeliveredMessage{(*binding.EventMessage)(&e), md}, noRespFn))
+			attempt, ok := extensions.GetDeliveryAttempt(got)
+			require.Equal(t, enabled, ok)
+			firstDelivery, _ := extensions.GetFirstDeliveryTime(got)
+			if enabled {
+				require.Equal(t, int32(2), attempt)
+				require.True(t, first.Equal(firstDelivery))
+			}
+		})
+	}
+}
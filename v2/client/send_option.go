@@ -0,0 +1,132 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"mime"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// EncodingSelector chooses the encoding of an event sent by the client, once
+// defaulted and validated. Returning binding.EncodingUnknown keeps the
+// encoding chosen by the protocol.
+type EncodingSelector func(e event.Event) binding.Encoding
+
+// SendOption configures a single Send or Request, see WithSendOptions.
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	encoding binding.Encoding
+	format   format.Format
+	resultFn protocol.ResultFn
+}
+
+// ForceBinary sends the event in binary mode.
+func ForceBinary() SendOption {
+	return func(o *sendOptions) {
+		o.encoding = binding.EncodingBinary
+	}
+}
+
+// ForceStructured sends the event in structured mode, marshalled with the
+// given format, or format.JSON if nil.
+func ForceStructured(f format.Format) SendOption {
+	return func(o *sendOptions) {
+		o.encoding = binding.EncodingStructured
+		o.format = f
+	}
+}
+
+// Async makes Send return as soon as the event has been handed off to the
+// protocol, without waiting for the delivery confirmation. fn is then invoked
+// exactly once with the result of the delivery, possibly from another
+// goroutine, while Send returns nil. If the event can not be handed off, Send
+// returns the error and fn is not invoked.
+// Protocols that are not protocol.AsyncSender send the event synchronously
+// before invoking fn. Async is ignored by Request.
+func Async(fn protocol.ResultFn) SendOption {
+	return func(o *sendOptions) {
+		o.resultFn = fn
+	}
+}
+
+type sendOptionsKey struct{}
+
+// WithSendOptions returns a context applying the given options to the Send or
+// Request it is passed to, after the options already set in ctx, e.g.
+//
+//	c.Send(client.WithSendOptions(ctx, client.ForceBinary()), e)
+func WithSendOptions(ctx context.Context, opts ...SendOption) context.Context {
+	prev := SendOptionsFrom(ctx)
+	all := make([]SendOption, 0, len(prev)+len(opts))
+	all = append(append(all, prev...), opts...)
+	return context.WithValue(ctx, sendOptionsKey{}, all)
+}
+
+// SendOptionsFrom returns the options set in ctx with WithSendOptions.
+func SendOptionsFrom(ctx context.Context) []SendOption {
+	opts, _ := ctx.Value(sendOptionsKey{}).([]SendOption)
+	return opts
+}
+
+// applySendOptions returns the context used to send e, encoded according to
+// the send options of ctx or, if they do not force an encoding, to the
+// encoding selector, along with the applied options.
+func (c *ceClient) applySendOptions(ctx context.Context, e event.Event) (context.Context, sendOptions) {
+	o := sendOptions{encoding: binding.EncodingUnknown}
+	if c.encodingSelector != nil {
+		o.encoding = c.encodingSelector(e)
+	}
+	if rule, ok := c.contentTypeEncoding(e.DataContentType()); ok {
+		o.encoding = rule.encoding
+		o.format = rule.format
+	}
+	for _, opt := range SendOptionsFrom(ctx) {
+		opt(&o)
+	}
+
+	switch o.encoding {
+	case binding.EncodingBinary:
+		ctx = binding.WithForceBinary(ctx)
+	case binding.EncodingStructured:
+		ctx = binding.WithForceStructured(ctx)
+		if o.format != nil {
+			ctx = binding.UseFormatForEvent(ctx, o.format)
+		}
+	}
+	return ctx, o
+}
+
+// contentTypeEncoding is an encoding rule set with WithContentTypeEncoding.
+type contentTypeEncoding struct {
+	mediaType string
+	encoding  binding.Encoding
+	format    format.Format
+}
+
+// contentTypeEncoding returns the first rule matching contentType.
+func (c *ceClient) contentTypeEncoding(contentType string) (contentTypeEncoding, bool) {
+	if len(c.contentTypeEncodings) == 0 || contentType == "" {
+		return contentTypeEncoding{}, false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentTypeEncoding{}, false
+	}
+	for _, rule := range c.contentTypeEncodings {
+		if rule.mediaType == mediaType || rule.mediaType == "*/*" ||
+			(strings.HasSuffix(rule.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(rule.mediaType, "*"))) {
+			return rule, true
+		}
+	}
+	return contentTypeEncoding{}, false
+}
@@ -0,0 +1,204 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// testFormat is format.JSON with another media type.
+type testFormat struct{}
+
+func (testFormat) MediaType() string                        { return "application/cloudevents+test" }
+func (testFormat) Marshal(e *event.Event) ([]byte, error)   { return format.JSON.Marshal(e) }
+func (testFormat) Unmarshal(b []byte, e *event.Event) error { return format.JSON.Unmarshal(b, e) }
+
+func TestSendOptions(t *testing.T) {
+	selector := func(e event.Event) binding.Encoding {
+		if e.Type() == "structured" {
+			return binding.EncodingStructured
+		}
+		return binding.EncodingUnknown
+	}
+
+	testCases := map[string]struct {
+		clientOpts      []Option
+		sendOpts        []SendOption
+		eventType       string
+		dataContentType string
+		wantContentType string
+	}{
+		"default": {
+			wantContentType: "text/json",
+		},
+		"force structured": {
+			sendOpts:        []SendOption{ForceStructured(nil)},
+			wantContentType: "application/cloudevents+json",
+		},
+		"force structured with format": {
+			sendOpts:        []SendOption{ForceStructured(testFormat{})},
+			wantContentType: "application/cloudevents+test",
+		},
+		"force binary over client option": {
+			clientOpts:      []Option{WithForceStructured()},
+			sendOpts:        []SendOption{ForceBinary()},
+			wantContentType: "text/json",
+		},
+		"selector": {
+			clientOpts:      []Option{WithEncodingSelector(selector)},
+			eventType:       "structured",
+			wantContentType: "application/cloudevents+json",
+		},
+		"selector keeps default": {
+			clientOpts:      []Option{WithEncodingSelector(selector)},
+			eventType:       "binary",
+			wantContentType: "text/json",
+		},
+		"send option over selector": {
+			clientOpts:      []Option{WithEncodingSelector(selector)},
+			sendOpts:        []SendOption{ForceBinary()},
+			eventType:       "structured",
+			wantContentType: "text/json",
+		},
+		"content type": {
+			clientOpts:      []Option{WithContentTypeEncoding("text/json", binding.EncodingStructured, testFormat{})},
+			wantContentType: "application/cloudevents+test",
+		},
+		"content type with parameters": {
+			clientOpts:      []Option{WithContentTypeEncoding("Text/JSON", binding.EncodingStructured, nil)},
+			dataContentType: "text/json; charset=utf-8",
+			wantContentType: "application/cloudevents+json",
+		},
+		"content type range": {
+			clientOpts:      []Option{WithContentTypeEncoding("text/*", binding.EncodingStructured, nil)},
+			wantContentType: "application/cloudevents+json",
+		},
+		"other content type": {
+			clientOpts:      []Option{WithContentTypeEncoding("application/avro", binding.EncodingStructured, nil)},
+			wantContentType: "text/json",
+		},
+		"first matching content type": {
+			clientOpts: []Option{
+				WithContentTypeEncoding("text/json", binding.EncodingStructured, testFormat{}),
+				WithContentTypeEncoding("text/*", binding.EncodingStructured, nil),
+			},
+			wantContentType: "application/cloudevents+test",
+		},
+		"content type over selector": {
+			clientOpts: []Option{
+				WithEncodingSelector(selector),
+				WithContentTypeEncoding("text/json", binding.EncodingBinary, nil),
+			},
+			eventType:       "structured",
+			wantContentType: "text/json",
+		},
+		"send option over content type": {
+			clientOpts:      []Option{WithContentTypeEncoding("text/json", binding.EncodingStructured, testFormat{})},
+			sendOpts:        []SendOption{ForceStructured(nil)},
+			wantContentType: "application/cloudevents+json",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			var contentType string
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				contentType = req.Header.Get("Content-Type")
+			}))
+			defer srv.Close()
+
+			p, err := cehttp.New(cehttp.WithTarget(srv.URL))
+			require.NoError(t, err)
+			c, err := New(p, tc.clientOpts...)
+			require.NoError(t, err)
+
+			e := test.FullEvent()
+			if tc.eventType != "" {
+				e.SetType(tc.eventType)
+			}
+			if tc.dataContentType != "" {
+				e.SetDataContentType(tc.dataContentType)
+			}
+			require.True(t, protocol.IsACK(c.Send(WithSendOptions(context.Background(), tc.sendOpts...), e)))
+			require.Equal(t, tc.wantContentType, contentType)
+		})
+	}
+}
+
+func TestWithEncodingSelectorNil(t *testing.T) {
+	c := &ceClient{}
+	require.EqualError(t, c.applyOptions(WithEncodingSelector(nil)), "client option was given an nil encoding selector")
+}
+
+func TestWithContentTypeEncodingInvalid(t *testing.T) {
+	c := &ceClient{}
+	require.Error(t, c.applyOptions(WithContentTypeEncoding("", binding.EncodingStructured, nil)))
+	require.EqualError(t, c.applyOptions(WithContentTypeEncoding("application/avro", binding.EncodingUnknown, nil)),
+		"client option was given an encoding unknown which is not binary or structured")
+}
+
+// asyncSender confirms the deliveries with the results pushed in its channel.
+type asyncSender struct {
+	results chan protocol.Result
+}
+
+func (s *asyncSender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	return errors.New("unexpected synchronous send")
+}
+
+func (s *asyncSender) SendAsync(ctx context.Context, m binding.Message, fn protocol.ResultFn, transformers ...binding.Transformer) error {
+	go func() {
+		fn(<-s.results)
+	}()
+	return m.Finish(nil)
+}
+
+func TestSendAsync(t *testing.T) {
+	s := &asyncSender{results: make(chan protocol.Result)}
+	c, err := New(s)
+	require.NoError(t, err)
+
+	results := make(chan protocol.Result, 1)
+	ctx := WithSendOptions(context.Background(), Async(func(r protocol.Result) {
+		results <- r
+	}))
+	require.NoError(t, c.Send(ctx, test.FullEvent()))
+
+	nack := protocol.NewReceipt(false, "nope")
+	s.results <- nack
+	require.Equal(t, nack, <-results)
+}
+
+func TestSendAsyncFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p, err := cehttp.New(cehttp.WithTarget(srv.URL))
+	require.NoError(t, err)
+	c, err := New(p)
+	require.NoError(t, err)
+
+	var result protocol.Result
+	ctx := WithSendOptions(context.Background(), Async(func(r protocol.Result) {
+		result = r
+	}))
+	require.NoError(t, c.Send(ctx, test.FullEvent()))
+	require.True(t, protocol.IsACK(result))
+}
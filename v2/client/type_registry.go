@@ -0,0 +1,152 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// ErrUnregisteredType is returned when no Go type is registered for the data
+// of an event.
+var ErrUnregisteredType = errors.New("no Go type registered for the data of event")
+
+// TypeRegistry maps the event types, and optionally their dataschema, to the
+// Go types their data is decoded into, so that the receiver functions get
+// their data without calling event.DataAs themselves, see WithTypeRegistry
+// and Typed.
+// It is safe for concurrent use.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[typeKey]reflect.Type
+}
+
+type typeKey struct {
+	eventType  string
+	dataSchema string
+}
+
+// NewTypeRegistry returns a TypeRegistry without types.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{types: make(map[typeKey]reflect.Type)}
+}
+
+// Register sets the Go type the data of the events of the given type is
+// decoded into, replacing any previous one. v is a value of that type, or a
+// pointer to one, e.g. OrderCreated{} or (*OrderCreated)(nil). It panics if
+// v is nil.
+func (r *TypeRegistry) Register(eventType string, v interface{}) {
+	r.RegisterSchema(eventType, "", v)
+}
+
+// RegisterSchema is like Register for the events of the given type with the
+// given dataschema, taking precedence over the Go type registered for all the
+// events of the type.
+func (r *TypeRegistry) RegisterSchema(eventType, dataSchema string, v interface{}) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		panic("client: TypeRegistry.Register of a nil value for event type " + eventType)
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[typeKey{eventType: eventType, dataSchema: dataSchema}] = t
+}
+
+// Resolve returns the Go type registered for the data of e.
+func (r *TypeRegistry) Resolve(e event.Event) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if schema := e.DataSchema(); schema != "" {
+		if t, ok := r.types[typeKey{eventType: e.Type(), dataSchema: schema}]; ok {
+			return t, true
+		}
+	}
+	t, ok := r.types[typeKey{eventType: e.Type()}]
+	return t, ok
+}
+
+// Decode decodes the data of e into a new value of the Go type registered for
+// it, and returns a pointer to that value. It returns an error wrapping
+// ErrUnregisteredType if no Go type is registered for e.
+func (r *TypeRegistry) Decode(e event.Event) (interface{}, error) {
+	t, ok := r.Resolve(e)
+	if !ok {
+		return nil, fmt.Errorf("%w type %q", ErrUnregisteredType, e.Type())
+	}
+	v := reflect.New(t)
+	if err := e.DataAs(v.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to decode data of event type %q as %s: %w", e.Type(), t, err)
+	}
+	return v.Interface(), nil
+}
+
+type typeRegistryKeyType struct{}
+
+var typeRegistryKey = typeRegistryKeyType{}
+
+// withTypeRegistry returns a context with the registry the receiver functions
+// decode the event data with.
+func withTypeRegistry(ctx context.Context, r *TypeRegistry) context.Context {
+	return context.WithValue(ctx, typeRegistryKey, r)
+}
+
+// TypeRegistryFrom returns the TypeRegistry of the client the context of a
+// received event comes from, or nil, see WithTypeRegistry.
+func TypeRegistryFrom(ctx context.Context) *TypeRegistry {
+	if r, ok := ctx.Value(typeRegistryKey).(*TypeRegistry); ok {
+		return r
+	}
+	return nil
+}
+
+// DecodeData decodes the data of a received event with the TypeRegistry of
+// ctx, e.g. for a router to switch on the type of the data. It returns an
+// error wrapping ErrUnregisteredType if the client has no TypeRegistry or no
+// Go type is registered for e.
+func DecodeData(ctx context.Context, e event.Event) (interface{}, error) {
+	r := TypeRegistryFrom(ctx)
+	if r == nil {
+		return nil, fmt.Errorf("%w type %q", ErrUnregisteredType, e.Type())
+	}
+	return r.Decode(e)
+}
+
+// Typed adapts a function taking the decoded data of the events into a
+// receiver function. The data is decoded with the TypeRegistry of the client,
+// and must then be a T or a pointer to a T, or decoded into a new T when no
+// Go type is registered for the event. The events whose data can not be
+// decoded are NACKed without invoking fn.
+func Typed[T any](fn func(ctx context.Context, e event.Event, data T) protocol.Result) ReceiveFull {
+	return func(ctx context.Context, e event.Event) protocol.Result {
+		var data T
+		decoded, err := DecodeData(ctx, e)
+		switch {
+		case errors.Is(err, ErrUnregisteredType):
+			if err := e.DataAs(&data); err != nil {
+				return protocol.NewReceipt(false, "failed to decode data of event type %q as %T: %w", e.Type(), data, err)
+			}
+		case err != nil:
+			return protocol.NewReceipt(false, "%w", err)
+		default:
+			var ok bool
+			if data, ok = decoded.(T); !ok {
+				if data, ok = reflect.ValueOf(decoded).Elem().Interface().(T); !ok {
+					return protocol.NewReceipt(false, "data of event type %q is a %T, not a %T", e.Type(), decoded, data)
+				}
+			}
+		}
+		return fn(ctx, e, data)
+	}
+}
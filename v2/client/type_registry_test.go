@@ -0,0 +1,134 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+type orderCreated struct {
+	ID string `json:"id"`
+}
+
+type orderCreatedV2 struct {
+	ID    string `json:"id"`
+	Total int    `json:"total"`
+}
+
+func newOrderEvent(t *testing.T, schema string, data interface{}) event.Event {
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/orders")
+	e.SetType("order.created")
+	e.SetDataSchema(schema)
+	require.NoError(t, e.SetData(event.ApplicationJSON, data))
+	return e
+}
+
+func TestTypeRegistryDecode(t *testing.T) {
+	r := NewTypeRegistry()
+	r.Register("order.created", orderCreated{})
+	r.RegisterSchema("order.created", "https://example.com/v2", (*orderCreatedV2)(nil))
+
+	data, err := r.Decode(newOrderEvent(t, "", map[string]interface{}{"id": "a", "total": 3}))
+	require.NoError(t, err)
+	require.Equal(t, &orderCreated{ID: "a"}, data)
+
+	data, err = r.Decode(newOrderEvent(t, "https://example.com/v2", map[string]interface{}{"id": "a", "total": 3}))
+	require.NoError(t, err)
+	require.Equal(t, &orderCreatedV2{ID: "a", Total: 3}, data)
+
+	// Unknown schemas fall back to the type.
+	data, err = r.Decode(newOrderEvent(t, "https://example.com/v3", map[string]interface{}{"id": "a"}))
+	require.NoError(t, err)
+	require.Equal(t, &orderCreated{ID: "a"}, data)
+
+	unknown := newOrderEvent(t, "", nil)
+	unknown.SetType("order.deleted")
+	_, err = r.Decode(unknown)
+	require.True(t, errors.Is(err, ErrUnregisteredType))
+
+	_, err = r.Decode(newOrderEvent(t, "", "not an object"))
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrUnregisteredType))
+
+	_, err = DecodeData(context.Background(), newOrderEvent(t, "", nil))
+	require.True(t, errors.Is(err, ErrUnregisteredType))
+}
+
+func TestTyped(t *testing.T) {
+	r := NewTypeRegistry()
+	r.Register("order.created", orderCreated{})
+	withRegistry := withTypeRegistry(context.Background(), r)
+	e := newOrderEvent(t, "", map[string]interface{}{"id": "a"})
+
+	var ptr *orderCreated
+	result := Typed(func(ctx context.Context, e event.Event, data *orderCreated) protocol.Result {
+		ptr = data
+		return nil
+	})(withRegistry, e)
+	require.Nil(t, result)
+	require.Equal(t, &orderCreated{ID: "a"}, ptr)
+
+	var value orderCreated
+	result = Typed(func(ctx context.Context, e event.Event, data orderCreated) protocol.Result {
+		value = data
+		return nil
+	})(withRegistry, e)
+	require.Nil(t, result)
+	require.Equal(t, orderCreated{ID: "a"}, value)
+
+	// Without a registered type, the data is decoded into T.
+	var unregistered orderCreatedV2
+	result = Typed(func(ctx context.Context, e event.Event, data orderCreatedV2) protocol.Result {
+		unregistered = data
+		return nil
+	})(context.Background(), e)
+	require.Nil(t, result)
+	require.Equal(t, orderCreatedV2{ID: "a"}, unregistered)
+
+	called := false
+	result = Typed(func(ctx context.Context, e event.Event, data orderCreatedV2) protocol.Result {
+		called = true
+		return nil
+	})(withRegistry, e)
+	require.False(t, called)
+	require.True(t, protocol.IsNACK(result))
+
+	result = Typed(func(ctx context.Context, e event.Event, data orderCreated) protocol.Result {
+		called = true
+		return nil
+	})(withRegistry, newOrderEvent(t, "", "not an object"))
+	require.False(t, called)
+	require.True(t, protocol.IsNACK(result))
+}
+
+func TestWithTypeRegistry(t *testing.T) {
+	r := NewTypeRegistry()
+	r.Register("order.created", orderCreated{})
+	c := &ceClient{}
+	require.NoError(t, c.applyOptions(WithTypeRegistry(r)))
+	require.Error(t, (&ceClient{}).applyOptions(WithTypeRegistry(nil)))
+
+	var got interface{}
+	invoker, err := newReceiveInvoker(Typed(func(ctx context.Context, e event.Event, data interface{}) protocol.Result {
+		got = data
+		return nil
+	}), invokerConfig{inboundContextDecorators: c.inboundContextDecorators})
+	require.NoError(t, err)
+
+	e := newOrderEvent(t, "", map[string]interface{}{"id": "a"})
+	require.NoError(t, invoker.Invoke(context.Background(), (*binding.EventMessage)(&e), noRespFn))
+	require.Equal(t, &orderCreated{ID: "a"}, got)
+}
@@ -0,0 +1,92 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package context
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClockFrom(t *testing.T) {
+	if got := ClockFrom(context.Background()); got != SystemClock {
+		t.Errorf("expected the system clock, got %v", got)
+	}
+	clock := NewFakeClock(time.Unix(0, 0))
+	if got := ClockFrom(WithClock(context.Background(), clock)); got != clock {
+		t.Errorf("expected the fake clock, got %v", got)
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	second := clock.NewTimer(time.Second)
+	minute := clock.NewTimer(time.Minute)
+	stopped := clock.NewTimer(time.Second)
+	if !stopped.Stop() {
+		t.Error("expected the timer to be stopped")
+	}
+	select {
+	case <-clock.NewTimer(0).C():
+	default:
+		t.Error("expected a timer of no duration to fire")
+	}
+	if got := clock.Timers(); got != 2 {
+		t.Errorf("expected 2 timers, got %d", got)
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case got := <-second.C():
+		if want := start.Add(30 * time.Second); !got.Equal(want) {
+			t.Errorf("expected the timer to fire at %v, got %v", want, got)
+		}
+	default:
+		t.Error("expected the timer of a second to fire")
+	}
+	select {
+	case <-minute.C():
+		t.Error("expected the timer of a minute not to fire")
+	default:
+	}
+	if second.Stop() {
+		t.Error("expected the fired timer not to be stopped")
+	}
+
+	clock.Set(start.Add(time.Minute))
+	select {
+	case <-minute.C():
+	default:
+		t.Error("expected the timer of a minute to fire")
+	}
+	if got := clock.Timers(); got != 0 {
+		t.Errorf("expected no timers, got %d", got)
+	}
+}
+
+func TestRetryParams_BackoffClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	ctx := WithClock(context.Background(), clock)
+	rp := &RetryParams{Strategy: BackoffStrategyExponential, MaxTries: 10, Period: time.Second}
+
+	done := make(chan error)
+	go func() { done <- rp.Backoff(ctx, 3) }()
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(7 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("expected the backoff to wait for 8s")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -97,6 +97,14 @@ func WithRetryParams(ctx context.Context, rp *RetryParams) context.Context {
 	return context.WithValue(ctx, retriesKey, rp)
 }
 
+// WithRetryBudget returns back a new context with the retries parameters of
+// ctx limited by budget.
+func WithRetryBudget(ctx context.Context, budget *RetryBudget) context.Context {
+	rp := *RetriesFrom(ctx)
+	rp.Budget = budget
+	return WithRetryParams(ctx, &rp)
+}
+
 // RetriesFrom looks in the given context and returns the retries parameters if found.
 // Otherwise returns the default retries configuration (ie. no retries).
 func RetriesFrom(ctx context.Context) *RetryParams {
@@ -37,6 +37,10 @@ type RetryParams struct {
 	// - for linear strategy: interval between retries = Period * retries
 	// - for exponential strategy: interval between retries = Period * retries^2
 	Period time.Duration
+
+	// Budget, if set, limits the retries to a ratio of the requests made with
+	// it. The senders deposit each request in the budget.
+	Budget *RetryBudget
 }
 
 // BackoffFor tries will return the time duration that should be used for this
@@ -58,19 +62,22 @@ func (r *RetryParams) BackoffFor(tries int) time.Duration {
 	}
 }
 
-// Backoff is a blocking call to wait for the correct amount of time for the retry.
+// Backoff is a blocking call to wait for the correct amount of time for the retry,
+// on the clock of ctx.
 // `tries` is assumed to be the number of times the caller has already retried.
 func (r *RetryParams) Backoff(ctx context.Context, tries int) error {
 	if tries > r.MaxTries {
 		return errors.New("too many retries")
 	}
-	ticker := time.NewTicker(r.BackoffFor(tries))
+	if !r.Budget.Withdraw() {
+		return errors.New("retry budget exhausted")
+	}
+	timer := ClockFrom(ctx).NewTimer(r.BackoffFor(tries))
 	select {
 	case <-ctx.Done():
-		ticker.Stop()
+		timer.Stop()
 		return errors.New("context has been cancelled")
-	case <-ticker.C:
-		ticker.Stop()
+	case <-timer.C():
 	}
 	return nil
 }
@@ -0,0 +1,70 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package context
+
+import (
+	"errors"
+	"sync"
+)
+
+// RetryBudget limits the retries to a ratio of the requests, shared by all
+// the requests using it, so that retries do not amplify the load of a
+// struggling recipient.
+//
+// It is a token bucket: each request deposits ratio tokens and each retry
+// withdraws one, so that with a ratio of 0.2 the retries are at most 20% of
+// the requests once the initial tokens are consumed. A nil RetryBudget allows
+// all the retries.
+type RetryBudget struct {
+	mu       sync.Mutex
+	ratio    float64
+	capacity float64
+	tokens   float64
+}
+
+// NewRetryBudget returns a RetryBudget allowing ratio retries per request,
+// with ratio in (0, 1]. The bucket holds at most burst tokens, and is full
+// initially, so that burst retries are allowed before any request.
+func NewRetryBudget(ratio float64, burst int) (*RetryBudget, error) {
+	if ratio <= 0 || ratio > 1 {
+		return nil, errors.New("retry budget ratio must be in (0, 1]")
+	}
+	if burst < 1 {
+		return nil, errors.New("retry budget burst must be positive")
+	}
+	return &RetryBudget{
+		ratio:    ratio,
+		capacity: float64(burst),
+		tokens:   float64(burst),
+	}, nil
+}
+
+// Deposit records a request.
+func (b *RetryBudget) Deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+}
+
+// Withdraw records a retry and returns true if the budget allows it.
+func (b *RetryBudget) Withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,72 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package context
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget(t *testing.T) {
+	b, err := NewRetryBudget(0.5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The initial tokens allow burst retries.
+	if !b.Withdraw() || !b.Withdraw() {
+		t.Fatal("expected the burst retries to be allowed")
+	}
+	if b.Withdraw() {
+		t.Fatal("expected the budget to be exhausted")
+	}
+
+	// Two requests allow one retry.
+	b.Deposit()
+	if b.Withdraw() {
+		t.Fatal("expected the budget to be exhausted after one request")
+	}
+	b.Deposit()
+	if !b.Withdraw() {
+		t.Fatal("expected a retry to be allowed after two requests")
+	}
+
+	// Tokens do not exceed the burst.
+	for i := 0; i < 10; i++ {
+		b.Deposit()
+	}
+	if !b.Withdraw() || !b.Withdraw() || b.Withdraw() {
+		t.Fatal("expected the tokens to be capped by the burst")
+	}
+}
+
+func TestNewRetryBudgetInvalid(t *testing.T) {
+	if _, err := NewRetryBudget(0, 1); err == nil {
+		t.Error("expected an error for a zero ratio")
+	}
+	if _, err := NewRetryBudget(1.5, 1); err == nil {
+		t.Error("expected an error for a ratio above 1")
+	}
+	if _, err := NewRetryBudget(0.2, 0); err == nil {
+		t.Error("expected an error for a zero burst")
+	}
+}
+
+func TestRetryParams_BackoffBudget(t *testing.T) {
+	b, _ := NewRetryBudget(0.1, 1)
+	ctx := WithRetryBudget(WithRetriesConstantBackoff(context.Background(), time.Nanosecond, 10), b)
+	rp := RetriesFrom(ctx)
+	if rp.Strategy != BackoffStrategyConstant || rp.MaxTries != 10 {
+		t.Fatalf("expected the retry parameters to be kept, got %+v", rp)
+	}
+	if err := rp.Backoff(ctx, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := rp.Backoff(ctx, 2); err == nil || err.Error() != "retry budget exhausted" {
+		t.Fatalf("expected the budget to be exhausted, got %v", err)
+	}
+}
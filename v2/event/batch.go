@@ -0,0 +1,153 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Batch is an ordered set of events, as carried by the batched content mode
+// of the protocols and handled by the batch receivers and senders. It is a
+// plain slice, iterated with range.
+type Batch []Event
+
+// BatchValidationError holds the validation errors of the events of a Batch,
+// by index.
+type BatchValidationError map[int]error
+
+func (e BatchValidationError) Error() string {
+	indexes := make([]int, 0, len(e))
+	for i := range e {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	b := strings.Builder{}
+	for _, i := range indexes {
+		fmt.Fprintf(&b, "event %d: %s", i, strings.TrimSuffix(e[i].Error(), "\n"))
+		b.WriteRune('\n')
+	}
+	return b.String()
+}
+
+// Validate validates each event of b, and checks that no two events share
+// the same source and id, as they would be the same event.
+func (b Batch) Validate() error {
+	errs := BatchValidationError{}
+	seen := make(map[[2]string]int, len(b))
+	for i, e := range b {
+		if err := e.Validate(); err != nil {
+			errs[i] = err
+			continue
+		}
+		key := [2]string{e.Source(), e.ID()}
+		if j, ok := seen[key]; ok {
+			errs[i] = fmt.Errorf("duplicate of event %d with source %q and id %q", j, e.Source(), e.ID())
+			continue
+		}
+		seen[key] = i
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Each calls fn with each event of b, in order, stopping at the first error
+// returned by fn.
+func (b Batch) Each(fn func(i int, e Event) error) error {
+	for i, e := range b {
+		if err := fn(i, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Size returns the number of bytes of b in the JSON batch format.
+func (b Batch) Size() (int, error) {
+	size := 2 // []
+	for i, e := range b {
+		n, err := eventSize(e)
+		if err != nil {
+			return 0, fmt.Errorf("event %d: %w", i, err)
+		}
+		size += n
+		if i > 0 {
+			size++ // ,
+		}
+	}
+	return size, nil
+}
+
+// SplitByCount splits b into batches of at most n events, in order.
+func (b Batch) SplitByCount(n int) ([]Batch, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("batch count must be positive, got %d", n)
+	}
+	batches := make([]Batch, 0, (len(b)+n-1)/n)
+	for len(b) > n {
+		batches = append(batches, b[:n:n])
+		b = b[n:]
+	}
+	if len(b) > 0 {
+		batches = append(batches, b)
+	}
+	return batches, nil
+}
+
+// SplitBySize splits b into batches of at most maxBytes bytes in the JSON
+// batch format, in order. It returns an error if an event alone exceeds
+// maxBytes.
+func (b Batch) SplitBySize(maxBytes int) ([]Batch, error) {
+	var batches []Batch
+	start, size := 0, 2
+	for i, e := range b {
+		n, err := eventSize(e)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		if n+2 > maxBytes {
+			return nil, fmt.Errorf("event %d of %d bytes exceeds the batch size of %d bytes", i, n, maxBytes)
+		}
+		added := n
+		if i > start {
+			added++
+		}
+		if size+added > maxBytes {
+			batches = append(batches, b[start:i:i])
+			start, size, added = i, 2, n
+		}
+		size += added
+	}
+	if start < len(b) {
+		batches = append(batches, b[start:])
+	}
+	return batches, nil
+}
+
+// MergeBatches returns a new Batch with the events of batches, in order.
+func MergeBatches(batches ...Batch) Batch {
+	n := 0
+	for _, b := range batches {
+		n += len(b)
+	}
+	merged := make(Batch, 0, n)
+	for _, b := range batches {
+		merged = append(merged, b...)
+	}
+	return merged
+}
+
+func eventSize(e Event) (int, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
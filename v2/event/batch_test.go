@@ -0,0 +1,116 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package event_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func batchOf(n int) event.Batch {
+	b := make(event.Batch, n)
+	for i := range b {
+		b[i] = event.New()
+		b[i].SetID(strconv.Itoa(i))
+		b[i].SetSource("/source")
+		b[i].SetType("type")
+	}
+	return b
+}
+
+func TestBatchValidate(t *testing.T) {
+	require.NoError(t, batchOf(3).Validate())
+	require.NoError(t, event.Batch{}.Validate())
+
+	b := batchOf(4)
+	b[1].SetType("")
+	b[3].SetID("0")
+	err := b.Validate()
+	var verr event.BatchValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Len(t, verr, 2)
+	require.Contains(t, verr[1].Error(), "type")
+	require.Contains(t, verr[3].Error(), "duplicate of event 0")
+	require.Contains(t, err.Error(), "event 1: type")
+}
+
+func TestBatchEach(t *testing.T) {
+	var ids []string
+	stop := errors.New("stop")
+	err := batchOf(3).Each(func(i int, e event.Event) error {
+		ids = append(ids, e.ID())
+		if i == 1 {
+			return stop
+		}
+		return nil
+	})
+	require.Equal(t, stop, err)
+	require.Equal(t, []string{"0", "1"}, ids)
+}
+
+func TestBatchSize(t *testing.T) {
+	b := batchOf(3)
+	data, err := json.Marshal(b)
+	require.NoError(t, err)
+	size, err := b.Size()
+	require.NoError(t, err)
+	require.Equal(t, len(data), size)
+
+	size, err = event.Batch{}.Size()
+	require.NoError(t, err)
+	require.Equal(t, 2, size)
+}
+
+func TestBatchSplitByCount(t *testing.T) {
+	batches, err := batchOf(5).SplitByCount(2)
+	require.NoError(t, err)
+	require.Len(t, batches, 3)
+	require.Len(t, batches[0], 2)
+	require.Len(t, batches[2], 1)
+	require.Equal(t, batchOf(5), event.MergeBatches(batches...))
+
+	// Appending to a split batch must not overwrite the next one.
+	_ = append(batches[0], event.New())
+	require.Equal(t, "2", batches[1][0].ID())
+
+	batches, err = event.Batch{}.SplitByCount(2)
+	require.NoError(t, err)
+	require.Empty(t, batches)
+
+	_, err = batchOf(1).SplitByCount(0)
+	require.Error(t, err)
+}
+
+func TestBatchSplitBySize(t *testing.T) {
+	b := batchOf(5)
+	one, err := b[:1].Size()
+	require.NoError(t, err)
+	two, err := b[:2].Size()
+	require.NoError(t, err)
+
+	batches, err := b.SplitBySize(two)
+	require.NoError(t, err)
+	require.Len(t, batches, 3)
+	for _, batch := range batches {
+		size, err := batch.Size()
+		require.NoError(t, err)
+		require.LessOrEqual(t, size, two)
+	}
+	require.Equal(t, b, event.MergeBatches(batches...))
+
+	batches, err = b.SplitBySize(one)
+	require.NoError(t, err)
+	require.Len(t, batches, 5)
+
+	_, err = b.SplitBySize(one - 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,17 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package eventstore defines the interface of an append-only store of events
+organized in streams, and the helpers to replay a stream into a
+protocol.Sender.
+
+This package provides an in-memory Store, mostly useful for testing. Durable
+implementations are provided as separate modules:
+
+* github.com/cloudevents/sdk-go/eventstore/bbolt/v2 (embedded file store)
+* github.com/cloudevents/sdk-go/eventstore/postgres/v2 (PostgreSQL)
+*/
+package eventstore
@@ -0,0 +1,101 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// MemoryStore is a Store keeping the events in memory.
+// The events are lost when the process exits.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	streams map[string][]Record
+	closed  bool
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{streams: make(map[string][]Record)}
+}
+
+// Append implements Store.Append, on the clock of ctx.
+func (s *MemoryStore) Append(ctx context.Context, stream string, events ...event.Event) (uint64, error) {
+	if stream == "" {
+		return 0, errors.New("eventstore: stream name can not be empty")
+	}
+	for _, e := range events {
+		if err := e.Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, errors.New("eventstore: store closed")
+	}
+
+	records := s.streams[stream]
+	now := cecontext.ClockFrom(ctx).Now()
+	for _, e := range events {
+		records = append(records, Record{
+			Stream:   stream,
+			Sequence: uint64(len(records)) + 1,
+			Appended: now,
+			Event:    e.Clone(),
+		})
+	}
+	s.streams[stream] = records
+	return uint64(len(records)), nil
+}
+
+// Read implements Store.Read.
+func (s *MemoryStore) Read(ctx context.Context, stream string, q Query, fn ReadFunc) error {
+	s.mu.RLock()
+	records := s.streams[stream]
+	s.mu.RUnlock()
+
+	// Sequences start at 1 and have no gaps.
+	start := sort.Search(len(records), func(i int) bool { return records[i].Sequence >= q.FromSequence })
+	read := 0
+	for _, r := range records[start:] {
+		if q.Limit > 0 && read >= q.Limit {
+			return nil
+		}
+		if !q.Matches(r) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r.Event = r.Event.Clone()
+		if err := fn(ctx, r); err != nil {
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
+			return err
+		}
+		read++
+	}
+	return nil
+}
+
+// Close implements Store.Close.
+func (s *MemoryStore) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+var _ Store = (*MemoryStore)(nil)
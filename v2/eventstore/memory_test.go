@@ -0,0 +1,19 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package eventstore_test
+
+import (
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/eventstore"
+	"github.com/cloudevents/sdk-go/v2/eventstore/test"
+)
+
+func TestMemoryStore(t *testing.T) {
+	test.RunStoreTests(t, func(t *testing.T) eventstore.Store {
+		return eventstore.NewMemoryStore()
+	})
+}
@@ -0,0 +1,119 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// ErrStop can be returned by a ReadFunc to stop reading without error.
+var ErrStop = errors.New("eventstore: stop reading")
+
+// Record is an event stored in a stream.
+type Record struct {
+	// Stream is the name of the stream holding the event.
+	Stream string
+	// Sequence is the position of the event in the stream, starting at 1.
+	Sequence uint64
+	// Appended is the time the event has been appended to the store, read
+	// from the clock of the context of Append, see cecontext.WithClock.
+	Appended time.Time
+	// Event is the stored event.
+	Event event.Event
+}
+
+// Time returns the time used to filter the record in a time range: the event
+// time attribute if set, the append time otherwise.
+func (r Record) Time() time.Time {
+	if t := r.Event.Time(); !t.IsZero() {
+		return t
+	}
+	return r.Appended
+}
+
+// Query selects the records of a stream to read.
+// The zero value selects the whole stream.
+type Query struct {
+	// FromSequence is the sequence of the first record to read.
+	FromSequence uint64
+	// Since excludes the records with a Time before it, if not zero.
+	Since time.Time
+	// Until excludes the records with a Time equal or after it, if not zero.
+	Until time.Time
+	// Limit is the maximum number of records to read, if positive.
+	Limit int
+}
+
+// Matches returns true if the record is selected by the time range of q.
+// Store implementations are responsible for FromSequence and Limit.
+func (q Query) Matches(r Record) bool {
+	t := r.Time()
+	if !q.Since.IsZero() && t.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !t.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// ReadFunc is invoked for each record read from a stream, in sequence order.
+// Returning an error stops reading: ErrStop stops it without error.
+type ReadFunc func(ctx context.Context, r Record) error
+
+// Store is an append-only store of events organized in streams.
+type Store interface {
+	// Append validates and appends the events at the end of the stream,
+	// atomically, and returns the sequence of the last appended event.
+	Append(ctx context.Context, stream string, events ...event.Event) (uint64, error)
+
+	// Read invokes fn for each record of the stream selected by q, in
+	// sequence order. Reading a stream that does not exist is not an error.
+	Read(ctx context.Context, stream string, q Query, fn ReadFunc) error
+
+	// Close releases the resources held by the store.
+	Close(ctx context.Context) error
+}
+
+// ReplayError is returned by Replay when an event could not be sent.
+type ReplayError struct {
+	// Sequence is the sequence of the event that failed, replaying from it
+	// resumes the replay.
+	Sequence uint64
+	// Result is the result of the failed send.
+	Result protocol.Result
+}
+
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("eventstore: failed to replay event at sequence %d: %v", e.Sequence, e.Result)
+}
+
+func (e *ReplayError) Unwrap() error {
+	return e.Result
+}
+
+// Replay sends the records of the stream selected by q to sender, in
+// sequence order, and returns the number of events sent. It stops at the
+// first event that is not acknowledged, returning a *ReplayError.
+func Replay(ctx context.Context, store Store, stream string, q Query, sender protocol.Sender) (int, error) {
+	sent := 0
+	err := store.Read(ctx, stream, q, func(ctx context.Context, r Record) error {
+		e := r.Event
+		if result := sender.Send(ctx, binding.ToMessage(&e)); !protocol.IsACK(result) {
+			return &ReplayError{Sequence: r.Sequence, Result: result}
+		}
+		sent++
+		return nil
+	})
+	return sent, err
+}
@@ -0,0 +1,206 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package test provides re-usable functions for eventstore.Store tests.
+package test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/eventstore"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// RunStoreTests verifies that the Store returned by newStore complies with
+// the eventstore.Store contract. newStore must return an empty store.
+func RunStoreTests(t *testing.T, newStore func(t *testing.T) eventstore.Store) {
+	t.Run("append and read", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(context.Background())
+		testAppendRead(t, store)
+	})
+	t.Run("query", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(context.Background())
+		testQuery(t, store)
+	})
+	t.Run("invalid event", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(context.Background())
+		_, err := store.Append(context.Background(), "stream", event.New())
+		require.Error(t, err)
+		require.Empty(t, readAll(t, store, "stream", eventstore.Query{}))
+	})
+	t.Run("replay", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(context.Background())
+		testReplay(t, store)
+	})
+	t.Run("clock", func(t *testing.T) {
+		store := newStore(t)
+		defer store.Close(context.Background())
+		testClock(t, store)
+	})
+}
+
+// Events returns n valid events with ids from 0 to n-1, one second apart.
+func Events(n int) []event.Event {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := make([]event.Event, n)
+	for i := range events {
+		e := event.New()
+		e.SetID(strconv.Itoa(i))
+		e.SetType("com.example.test")
+		e.SetSource("example/eventstore")
+		e.SetTime(base.Add(time.Duration(i) * time.Second))
+		_ = e.SetData(event.ApplicationJSON, map[string]int{"n": i})
+		events[i] = e
+	}
+	return events
+}
+
+func readAll(t *testing.T, store eventstore.Store, stream string, q eventstore.Query) []eventstore.Record {
+	var records []eventstore.Record
+	require.NoError(t, store.Read(context.Background(), stream, q, func(ctx context.Context, r eventstore.Record) error {
+		records = append(records, r)
+		return nil
+	}))
+	return records
+}
+
+func ids(records []eventstore.Record) []string {
+	out := make([]string, 0, len(records))
+	for _, r := range records {
+		out = append(out, r.Event.ID())
+	}
+	return out
+}
+
+func testAppendRead(t *testing.T, store eventstore.Store) {
+	ctx := context.Background()
+	events := Events(5)
+
+	last, err := store.Append(ctx, "a", events[:3]...)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), last)
+	last, err = store.Append(ctx, "a", events[3:]...)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), last)
+	last, err = store.Append(ctx, "b", events[0])
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), last)
+
+	records := readAll(t, store, "a", eventstore.Query{})
+	require.Equal(t, []string{"0", "1", "2", "3", "4"}, ids(records))
+	for i, r := range records {
+		require.Equal(t, "a", r.Stream)
+		require.Equal(t, uint64(i+1), r.Sequence)
+		require.False(t, r.Appended.IsZero())
+		require.Equal(t, events[i].Data(), r.Event.Data())
+		require.True(t, events[i].Time().Equal(r.Event.Time()))
+	}
+	require.Len(t, readAll(t, store, "b", eventstore.Query{}), 1)
+	require.Empty(t, readAll(t, store, "missing", eventstore.Query{}))
+}
+
+// testClock checks that the append time is read from the clock of the
+// context, and used as the time of the events without time.
+func testClock(t *testing.T, store eventstore.Store) {
+	start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	clock := cecontext.NewFakeClock(start)
+	ctx := cecontext.WithClock(context.Background(), clock)
+	events := Events(2)
+	for i := range events {
+		events[i].SetTime(time.Time{})
+	}
+
+	_, err := store.Append(ctx, "s", events[0])
+	require.NoError(t, err)
+	clock.Advance(time.Hour)
+	_, err = store.Append(ctx, "s", events[1])
+	require.NoError(t, err)
+
+	records := readAll(t, store, "s", eventstore.Query{})
+	require.Len(t, records, 2)
+	require.True(t, start.Equal(records[0].Appended), records[0].Appended)
+	require.True(t, start.Add(time.Hour).Equal(records[1].Appended), records[1].Appended)
+	require.Equal(t, []string{"1"}, ids(readAll(t, store, "s", eventstore.Query{Since: start.Add(time.Minute)})))
+}
+
+func testQuery(t *testing.T, store eventstore.Store) {
+	ctx := context.Background()
+	events := Events(10)
+	_, err := store.Append(ctx, "s", events...)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"5", "6", "7", "8", "9"}, ids(readAll(t, store, "s", eventstore.Query{FromSequence: 6})))
+	require.Equal(t, []string{"0", "1"}, ids(readAll(t, store, "s", eventstore.Query{Limit: 2})))
+	require.Equal(t, []string{"2", "3"}, ids(readAll(t, store, "s", eventstore.Query{
+		Since: events[2].Time(),
+		Until: events[4].Time(),
+	})))
+	require.Equal(t, []string{"7"}, ids(readAll(t, store, "s", eventstore.Query{
+		FromSequence: 4,
+		Since:        events[7].Time(),
+		Limit:        1,
+	})))
+
+	// ErrStop stops reading without error.
+	var read int
+	require.NoError(t, store.Read(ctx, "s", eventstore.Query{}, func(ctx context.Context, r eventstore.Record) error {
+		read++
+		if read == 3 {
+			return eventstore.ErrStop
+		}
+		return nil
+	}))
+	require.Equal(t, 3, read)
+}
+
+type failingSender struct {
+	failAt string
+	sent   []string
+}
+
+func (s *failingSender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	if e.ID() == s.failAt {
+		return protocol.NewReceipt(false, "unavailable")
+	}
+	s.sent = append(s.sent, e.ID())
+	return nil
+}
+
+func testReplay(t *testing.T, store eventstore.Store) {
+	ctx := context.Background()
+	_, err := store.Append(ctx, "s", Events(5)...)
+	require.NoError(t, err)
+
+	sender := &failingSender{failAt: "3"}
+	sent, err := eventstore.Replay(ctx, store, "s", eventstore.Query{}, sender)
+	require.Equal(t, 3, sent)
+	var replayErr *eventstore.ReplayError
+	require.ErrorAs(t, err, &replayErr)
+	require.Equal(t, uint64(4), replayErr.Sequence)
+	require.True(t, protocol.IsNACK(err))
+
+	// Resume from the failed event.
+	sender.failAt = ""
+	sent, err = eventstore.Replay(ctx, store, "s", eventstore.Query{FromSequence: replayErr.Sequence}, sender)
+	require.NoError(t, err)
+	require.Equal(t, 2, sent)
+	require.Equal(t, []string{"0", "1", "2", "3", "4"}, sender.sent)
+}
@@ -0,0 +1,310 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bigquery encodes batches of events as BigQuery rows, laid out in
+// columns with export.Flatten, either as newline-delimited JSON for load jobs
+// or through a Sender appending them to a table with a RowAppender.
+//
+// The package does not talk to BigQuery itself: the Storage Write API is a
+// gRPC API of the BigQuery client, cloud.google.com/go/bigquery, which the
+// SDK does not depend on. The application appends the rows with its own
+// client, see RowAppender for a managed stream of the Storage Write API.
+//
+// The names of the fields are valid BigQuery column names: the extensions
+// are prefixed, "ext_" by default, so that they can not clash with the
+// context attributes, and the characters BigQuery does not allow are
+// replaced with underscores. Timestamps are encoded with microseconds, the
+// precision of BigQuery, and binary values in base64.
+package bigquery
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/export"
+)
+
+// DefaultExtensionPrefix prefixes the names of the extension fields.
+const DefaultExtensionPrefix = "ext_"
+
+// timestampLayout is the layout of the TIMESTAMP fields.
+const timestampLayout = "2006-01-02T15:04:05.999999Z07:00"
+
+// DataMode is how the data of the events is encoded.
+type DataMode int
+
+const (
+	// DataOmitted leaves the data out of the rows.
+	DataOmitted DataMode = iota
+	// DataJSON encodes the data in a JSON field: JSON data as it is, other
+	// data as a JSON string, in base64 if it is not valid UTF-8.
+	DataJSON
+	// DataString encodes the data in a STRING field, in base64 if it is not
+	// valid UTF-8.
+	DataString
+)
+
+// Field is a column of the schema of a table, as the TableFieldSchema of
+// the BigQuery API.
+type Field struct {
+	Name string `json:"name"`
+	// Type is STRING, INT64, FLOAT64, BOOL, TIMESTAMP, BYTES or JSON.
+	Type string `json:"type"`
+	// Mode is always NULLABLE.
+	Mode string `json:"mode"`
+}
+
+// Encoder encodes batches of events as BigQuery rows.
+type Encoder struct {
+	extensionPrefix string
+	dataMode        DataMode
+	flatten         []export.FlattenOption
+}
+
+// Option configures an Encoder.
+type Option func(*Encoder) error
+
+// WithExtensionPrefix sets the prefix of the extension fields,
+// DefaultExtensionPrefix by default.
+func WithExtensionPrefix(prefix string) Option {
+	return func(enc *Encoder) error {
+		if prefix == "" {
+			return errors.New("bigquery extension prefix option was given an empty prefix")
+		}
+		if FieldName(prefix) != prefix {
+			return fmt.Errorf("bigquery extension prefix option was given an invalid prefix %q", prefix)
+		}
+		enc.extensionPrefix = prefix
+		return nil
+	}
+}
+
+// WithDataMode sets how the data is encoded in the "data" field, omitted by
+// default.
+func WithDataMode(mode DataMode) Option {
+	return func(enc *Encoder) error {
+		if mode < DataOmitted || mode > DataString {
+			return fmt.Errorf("bigquery data mode option was given an unknown mode %d", mode)
+		}
+		enc.dataMode = mode
+		return nil
+	}
+}
+
+// WithFlattenOptions sets the options laying the events out in columns, e.g.
+// export.WithDataPath. export.WithDataColumn is superseded by WithDataMode.
+func WithFlattenOptions(opts ...export.FlattenOption) Option {
+	return func(enc *Encoder) error {
+		enc.flatten = append(enc.flatten, opts...)
+		return nil
+	}
+}
+
+// NewEncoder returns an Encoder.
+func NewEncoder(opts ...Option) (*Encoder, error) {
+	enc := &Encoder{extensionPrefix: DefaultExtensionPrefix}
+	for _, opt := range opts {
+		if err := opt(enc); err != nil {
+			return nil, err
+		}
+	}
+	return enc, nil
+}
+
+// FieldName returns name as a valid BigQuery column name: the characters
+// other than letters, digits and underscores are replaced with underscores,
+// and an underscore is prepended if it starts with a digit.
+func FieldName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+		default:
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// field is a column of the table being encoded.
+type field struct {
+	Field
+	column *export.Column
+}
+
+// table lays out the events in fields.
+func (enc *Encoder) table(events []event.Event) ([]field, int, error) {
+	t, err := export.Flatten(events, enc.flatten...)
+	if err != nil {
+		return nil, 0, err
+	}
+	var fields []field
+	names := map[string]string{}
+	for _, c := range t.Columns {
+		if c.Kind == export.DataColumn {
+			continue
+		}
+		name := FieldName(c.Name)
+		if c.Kind == export.ExtensionColumn {
+			name = enc.extensionPrefix + name
+		}
+		// BigQuery column names are case insensitive.
+		if other, ok := names[strings.ToLower(name)]; ok {
+			return nil, 0, fmt.Errorf("columns %q and %q have the same field name %s", other, c.Name, name)
+		}
+		names[strings.ToLower(name)] = c.Name
+		fields = append(fields, field{Field: Field{Name: name, Type: fieldType(c.Type), Mode: "NULLABLE"}, column: c})
+	}
+
+	if enc.dataMode != DataOmitted {
+		if other, ok := names["data"]; ok {
+			return nil, 0, fmt.Errorf("column %q has the field name of the data", other)
+		}
+		c := &export.Column{Name: "data", Kind: export.DataColumn, Type: export.ColumnString, Values: make([]interface{}, len(events))}
+		if enc.dataMode == DataJSON {
+			c.Type = export.ColumnJSON
+		}
+		for i, e := range events {
+			c.Values[i] = enc.data(e)
+		}
+		fields = append(fields, field{Field: Field{Name: "data", Type: fieldType(c.Type), Mode: "NULLABLE"}, column: c})
+	}
+	return fields, t.Rows, nil
+}
+
+// data returns the value of the data field of e, nil if it has no data.
+func (enc *Encoder) data(e event.Event) interface{} {
+	data := e.Data()
+	if len(data) == 0 {
+		return nil
+	}
+	if enc.dataMode == DataJSON && isJSON(e) && json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	s := string(data)
+	if !utf8.Valid(data) {
+		s = base64.StdEncoding.EncodeToString(data)
+	}
+	if enc.dataMode == DataJSON {
+		b, _ := json.Marshal(s)
+		return json.RawMessage(b)
+	}
+	return s
+}
+
+func isJSON(e event.Event) bool {
+	ct := e.DataMediaType()
+	return ct == "" || ct == event.ApplicationJSON || ct == event.TextJSON || strings.HasSuffix(ct, "+json")
+}
+
+// fieldType returns the BigQuery type of a column type.
+func fieldType(t export.ColumnType) string {
+	switch t {
+	case export.ColumnInt64:
+		return "INT64"
+	case export.ColumnFloat64:
+		return "FLOAT64"
+	case export.ColumnBoolean:
+		return "BOOL"
+	case export.ColumnTimestamp:
+		return "TIMESTAMP"
+	case export.ColumnBinary:
+		return "BYTES"
+	case export.ColumnJSON:
+		return "JSON"
+	}
+	return "STRING"
+}
+
+// Schema returns the schema of the rows of events.
+func (enc *Encoder) Schema(events []event.Event) ([]Field, error) {
+	fields, _, err := enc.table(events)
+	if err != nil {
+		return nil, err
+	}
+	schema := make([]Field, len(fields))
+	for i, f := range fields {
+		schema[i] = f.Field
+	}
+	return schema, nil
+}
+
+// Rows returns the events as JSON objects, without the null fields.
+func (enc *Encoder) Rows(events []event.Event) ([]json.RawMessage, error) {
+	fields, n, err := enc.table(events)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]json.RawMessage, n)
+	for i := range rows {
+		var b bytes.Buffer
+		b.WriteByte('{')
+		first := true
+		for _, f := range fields {
+			v := f.column.Values[i]
+			if v == nil {
+				continue
+			}
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			name, _ := json.Marshal(f.Name)
+			b.Write(name)
+			b.WriteByte(':')
+			value, err := fieldValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("field %s of event %s: %w", f.Name, events[i].ID(), err)
+			}
+			b.Write(value)
+		}
+		b.WriteByte('}')
+		rows[i] = b.Bytes()
+	}
+	return rows, nil
+}
+
+// fieldValue encodes a value of a column as BigQuery expects it in JSON.
+func fieldValue(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case json.RawMessage:
+		return v, nil
+	case time.Time:
+		return json.Marshal(v.UTC().Format(timestampLayout))
+	}
+	// []byte is encoded in base64.
+	return json.Marshal(v)
+}
+
+// Encode writes the events to w as newline-delimited JSON, the format of
+// BigQuery load jobs.
+func (enc *Encoder) Encode(w io.Writer, events []event.Event) error {
+	rows, err := enc.Rows(events)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, err := w.Write(append(row, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
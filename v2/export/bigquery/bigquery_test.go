@@ -0,0 +1,192 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package bigquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/export"
+)
+
+func testEvents(t *testing.T) []event.Event {
+	a := event.New()
+	a.SetID("a")
+	a.SetSource("/orders")
+	a.SetType("order.created")
+	a.SetTime(time.Date(2024, 1, 2, 3, 4, 5, 6007, time.UTC))
+	a.SetExtension("tenantid", "acme")
+	a.SetExtension("priority", 3)
+	require.NoError(t, a.SetData(event.ApplicationJSON, map[string]interface{}{"order": map[string]interface{}{"id": "o-1"}}))
+
+	b := event.New()
+	b.SetID("b")
+	b.SetSource("/orders")
+	b.SetType("order.note")
+	require.NoError(t, b.SetData(event.TextPlain, "hello"))
+
+	c := event.New()
+	c.SetID("c")
+	c.SetSource("/orders")
+	c.SetType("order.scan")
+	require.NoError(t, c.SetData("application/octet-stream", []byte{0xff, 0x00}))
+	return []event.Event{a, b, c}
+}
+
+func TestFieldName(t *testing.T) {
+	for name, want := range map[string]string{
+		"tenantid":   "tenantid",
+		"tenant-id":  "tenant_id",
+		"3d":         "_3d",
+		"a.b/c":      "a_b_c",
+		"":           "_",
+		"Order_ID_2": "Order_ID_2",
+		"héllo":      "h_llo",
+	} {
+		require.Equal(t, want, FieldName(name), name)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts []Option
+		rows []string
+	}{
+		"without data": {
+			rows: []string{
+				`{"specversion":"1.0","id":"a","source":"/orders","type":"order.created","datacontenttype":"application/json","time":"2024-01-02T03:04:05.000006Z","ext_priority":3,"ext_tenantid":"acme"}`,
+				`{"specversion":"1.0","id":"b","source":"/orders","type":"order.note","datacontenttype":"text/plain"}`,
+				`{"specversion":"1.0","id":"c","source":"/orders","type":"order.scan","datacontenttype":"application/octet-stream"}`,
+			},
+		},
+		"json data": {
+			opts: []Option{WithDataMode(DataJSON), WithExtensionPrefix("x_"), WithFlattenOptions(export.WithExtensionColumns([]string{}...), export.WithDataPath("order.id", "/order/id"))},
+			rows: []string{
+				`{"specversion":"1.0","id":"a","source":"/orders","type":"order.created","datacontenttype":"application/json","time":"2024-01-02T03:04:05.000006Z","order_id":"o-1","data":{"order":{"id":"o-1"}}}`,
+				`{"specversion":"1.0","id":"b","source":"/orders","type":"order.note","datacontenttype":"text/plain","data":"hello"}`,
+				`{"specversion":"1.0","id":"c","source":"/orders","type":"order.scan","datacontenttype":"application/octet-stream","data":"/wA="}`,
+			},
+		},
+		"string data": {
+			opts: []Option{WithDataMode(DataString), WithFlattenOptions(export.WithExtensionColumns([]string{}...))},
+			rows: []string{
+				`{"specversion":"1.0","id":"a","source":"/orders","type":"order.created","datacontenttype":"application/json","time":"2024-01-02T03:04:05.000006Z","data":"{\"order\":{\"id\":\"o-1\"}}"}`,
+				`{"specversion":"1.0","id":"b","source":"/orders","type":"order.note","datacontenttype":"text/plain","data":"hello"}`,
+				`{"specversion":"1.0","id":"c","source":"/orders","type":"order.scan","datacontenttype":"application/octet-stream","data":"/wA="}`,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			enc, err := NewEncoder(tc.opts...)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			require.NoError(t, enc.Encode(&buf, testEvents(t)))
+			require.Equal(t, strings.Join(tc.rows, "\n")+"\n", buf.String())
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	enc, err := NewEncoder(WithDataMode(DataJSON))
+	require.NoError(t, err)
+	schema, err := enc.Schema(testEvents(t))
+	require.NoError(t, err)
+	b, err := json.Marshal(schema[7:])
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"name": "time", "type": "TIMESTAMP", "mode": "NULLABLE"},
+		{"name": "ext_priority", "type": "INT64", "mode": "NULLABLE"},
+		{"name": "ext_tenantid", "type": "STRING", "mode": "NULLABLE"},
+		{"name": "data", "type": "JSON", "mode": "NULLABLE"}
+	]`, string(b))
+}
+
+func TestEncodeConflicts(t *testing.T) {
+	e := event.New()
+	e.SetID("a")
+	e.SetExtension("tenantid", "acme")
+	e.SetExtension("tenant", "acme")
+
+	enc, err := NewEncoder(WithFlattenOptions(export.WithDataPath("ext_tenantid", "/tenant")))
+	require.NoError(t, err)
+	_, err = enc.Rows([]event.Event{e})
+	require.ErrorContains(t, err, "same field name")
+
+	enc, err = NewEncoder(WithDataMode(DataString), WithFlattenOptions(export.WithDataPath("DATA", "/data")))
+	require.NoError(t, err)
+	_, err = enc.Rows([]event.Event{e})
+	require.ErrorContains(t, err, "field name of the data")
+}
+
+func TestOptionsInvalid(t *testing.T) {
+	for _, opt := range []Option{
+		WithExtensionPrefix(""),
+		WithExtensionPrefix("ext-"),
+		WithDataMode(DataMode(7)),
+	} {
+		_, err := NewEncoder(opt)
+		require.Error(t, err)
+	}
+}
+
+type closingAppender struct {
+	RowAppenderFunc
+	closed bool
+}
+
+func (a *closingAppender) Close(context.Context) error {
+	a.closed = true
+	return nil
+}
+
+func TestSender(t *testing.T) {
+	enc, err := NewEncoder()
+	require.NoError(t, err)
+
+	var appended [][]json.RawMessage
+	appender := &closingAppender{RowAppenderFunc: func(_ context.Context, rows []json.RawMessage) error {
+		if len(rows) == 0 {
+			return errors.New("empty append")
+		}
+		appended = append(appended, rows)
+		return nil
+	}}
+	s, err := NewSender(enc, appender)
+	require.NoError(t, err)
+
+	events := testEvents(t)
+	require.NoError(t, s.Send(context.Background(), binding.ToMessage(&events[0])))
+	require.NoError(t, s.SendBatch(context.Background(), events[1:]))
+	require.NoError(t, s.SendBatch(context.Background(), nil))
+	require.Len(t, appended, 2)
+	require.Len(t, appended[0], 1)
+	require.Len(t, appended[1], 2)
+	require.Contains(t, string(appended[0][0]), `"ext_tenantid":"acme"`)
+
+	require.NoError(t, s.Close(context.Background()))
+	require.True(t, appender.closed)
+
+	s, err = NewSender(enc, RowAppenderFunc(func(context.Context, []json.RawMessage) error {
+		return errors.New("quota exceeded")
+	}))
+	require.NoError(t, err)
+	err = s.Send(context.Background(), binding.ToMessage(&events[0]))
+	require.ErrorContains(t, err, "failed to append 1 rows: quota exceeded")
+
+	_, err = NewSender(nil, appender)
+	require.Error(t, err)
+	_, err = NewSender(enc, nil)
+	require.Error(t, err)
+}
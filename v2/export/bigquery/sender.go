@@ -0,0 +1,118 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package bigquery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// RowAppender appends rows, JSON objects as returned by Encoder.Rows, to a
+// table. No implementation is provided, so that the SDK does not depend on
+// the BigQuery client. With the Storage Write API, it is typically a managed
+// stream of cloud.google.com/go/bigquery/storage/managedwriter, appending the
+// rows converted to protocol buffers with the descriptor of the table schema:
+//
+//	bigquery.RowAppenderFunc(func(ctx context.Context, rows []json.RawMessage) error {
+//		data := make([][]byte, len(rows))
+//		for i, row := range rows {
+//			msg := dynamicpb.NewMessage(descriptor)
+//			if err := protojson.Unmarshal(row, msg); err != nil {
+//				return err
+//			}
+//			if data[i], err = proto.Marshal(msg); err != nil {
+//				return err
+//			}
+//		}
+//		result, err := stream.AppendRows(ctx, data)
+//		if err != nil {
+//			return err
+//		}
+//		_, err = result.GetResult(ctx)
+//		return err
+//	})
+//
+// The rows of a call must be appended all or none.
+type RowAppender interface {
+	AppendRows(ctx context.Context, rows []json.RawMessage) error
+}
+
+// RowAppenderFunc is a function implementing RowAppender.
+type RowAppenderFunc func(ctx context.Context, rows []json.RawMessage) error
+
+// AppendRows calls f.
+func (f RowAppenderFunc) AppendRows(ctx context.Context, rows []json.RawMessage) error {
+	return f(ctx, rows)
+}
+
+// Sender is a protocol.Sender streaming the events to BigQuery as rows
+// appended by a RowAppender, so that a client can send its events straight
+// to a table.
+type Sender struct {
+	encoder  *Encoder
+	appender RowAppender
+}
+
+// NewSender returns a Sender encoding the events with encoder and appending
+// them with appender.
+func NewSender(encoder *Encoder, appender RowAppender) (*Sender, error) {
+	if encoder == nil {
+		return nil, errors.New("bigquery sender was given a nil encoder")
+	}
+	if appender == nil {
+		return nil, errors.New("bigquery sender was given a nil row appender")
+	}
+	return &Sender{encoder: encoder, appender: appender}, nil
+}
+
+// Send appends the event of m as a row.
+func (s *Sender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (err error) {
+	if m == nil {
+		return errors.New("nil Message")
+	}
+	defer func() {
+		err2 := m.Finish(err)
+		if err == nil {
+			err = err2
+		}
+	}()
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	return s.SendBatch(ctx, []event.Event{*e})
+}
+
+// SendBatch appends the events as rows in a single call to the RowAppender.
+func (s *Sender) SendBatch(ctx context.Context, events []event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	rows, err := s.encoder.Rows(events)
+	if err != nil {
+		return err
+	}
+	if err := s.appender.AppendRows(ctx, rows); err != nil {
+		return fmt.Errorf("failed to append %d rows: %w", len(rows), err)
+	}
+	return nil
+}
+
+// Close closes the RowAppender if it implements protocol.Closer.
+func (s *Sender) Close(ctx context.Context) error {
+	if c, ok := s.appender.(protocol.Closer); ok {
+		return c.Close(ctx)
+	}
+	return nil
+}
+
+var _ protocol.SendCloser = (*Sender)(nil)
@@ -0,0 +1,447 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// ColumnType is the type of the values of a Column.
+type ColumnType int
+
+const (
+	// ColumnString values are string.
+	ColumnString ColumnType = iota
+	// ColumnInt64 values are int64.
+	ColumnInt64
+	// ColumnFloat64 values are float64.
+	ColumnFloat64
+	// ColumnBoolean values are bool.
+	ColumnBoolean
+	// ColumnTimestamp values are time.Time, in UTC.
+	ColumnTimestamp
+	// ColumnBinary values are []byte.
+	ColumnBinary
+	// ColumnJSON values are json.RawMessage.
+	ColumnJSON
+)
+
+func (t ColumnType) String() string {
+	switch t {
+	case ColumnString:
+		return "string"
+	case ColumnInt64:
+		return "int64"
+	case ColumnFloat64:
+		return "float64"
+	case ColumnBoolean:
+		return "boolean"
+	case ColumnTimestamp:
+		return "timestamp"
+	case ColumnBinary:
+		return "binary"
+	case ColumnJSON:
+		return "json"
+	}
+	return "unknown"
+}
+
+// ColumnKind tells where the values of a Column come from.
+type ColumnKind int
+
+const (
+	// AttributeColumn holds a context attribute.
+	AttributeColumn ColumnKind = iota
+	// ExtensionColumn holds an extension.
+	ExtensionColumn
+	// DataPathColumn holds the value at a path into the JSON data.
+	DataPathColumn
+	// DataColumn holds the data.
+	DataColumn
+)
+
+// Column holds the values of a field of the events of a Table.
+type Column struct {
+	Name string
+	Kind ColumnKind
+	Type ColumnType
+	// Values holds the value of each event, nil if the event has none.
+	Values []interface{}
+}
+
+// Table is a batch of events laid out in columns, see Flatten.
+type Table struct {
+	// Columns are the context attributes in the order of the specification,
+	// then the extensions sorted by name, the data paths in the order they
+	// were given, and the data.
+	Columns []*Column
+	// Rows is the number of events.
+	Rows int
+}
+
+// Column returns the column with the given name, nil if none.
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Row returns the non nil values of the event at index i, by column name.
+func (t *Table) Row(i int) map[string]interface{} {
+	row := make(map[string]interface{}, len(t.Columns))
+	for _, c := range t.Columns {
+		if v := c.Values[i]; v != nil {
+			row[c.Name] = v
+		}
+	}
+	return row
+}
+
+// Map returns the values of the columns by name.
+func (t *Table) Map() map[string][]interface{} {
+	m := make(map[string][]interface{}, len(t.Columns))
+	for _, c := range t.Columns {
+		m[c.Name] = c.Values
+	}
+	return m
+}
+
+// attributeColumns are the context attributes of the specification 1.0, in
+// its order, with their type.
+var attributeColumns = []struct {
+	name string
+	typ  ColumnType
+	get  func(e event.Event) interface{}
+}{
+	{"specversion", ColumnString, func(e event.Event) interface{} { return nonEmpty(e.SpecVersion()) }},
+	{"id", ColumnString, func(e event.Event) interface{} { return nonEmpty(e.ID()) }},
+	{"source", ColumnString, func(e event.Event) interface{} { return nonEmpty(e.Source()) }},
+	{"type", ColumnString, func(e event.Event) interface{} { return nonEmpty(e.Type()) }},
+	{"datacontenttype", ColumnString, func(e event.Event) interface{} { return nonEmpty(e.DataContentType()) }},
+	{"dataschema", ColumnString, func(e event.Event) interface{} { return nonEmpty(e.DataSchema()) }},
+	{"subject", ColumnString, func(e event.Event) interface{} { return nonEmpty(e.Subject()) }},
+	{"time", ColumnTimestamp, func(e event.Event) interface{} {
+		if t := e.Time(); !t.IsZero() {
+			return t.UTC()
+		}
+		return nil
+	}},
+}
+
+func nonEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+type dataPath struct {
+	column  string
+	pointer string
+}
+
+type flattener struct {
+	extensions []string
+	dataPaths  []dataPath
+	data       bool
+}
+
+// FlattenOption configures Flatten.
+type FlattenOption func(*flattener) error
+
+// WithExtensionColumns sets the extension columns, so that batches get the
+// same columns whatever their extensions. By default, there is a column per
+// extension found in the batch.
+func WithExtensionColumns(names ...string) FlattenOption {
+	return func(f *flattener) error {
+		f.extensions = names
+		return nil
+	}
+}
+
+// WithDataPath adds a column holding the value at the JSON pointer (RFC 6901)
+// into the JSON data of the events, e.g. "/order/id". The type of the column
+// is inferred from the values: objects and arrays are JSON.
+func WithDataPath(column, pointer string) FlattenOption {
+	return func(f *flattener) error {
+		if column == "" {
+			return errors.New("flatten data path option was given an empty column name")
+		}
+		if !strings.HasPrefix(pointer, "/") {
+			return fmt.Errorf("flatten data path option was given an invalid JSON pointer %q", pointer)
+		}
+		f.dataPaths = append(f.dataPaths, dataPath{column: column, pointer: pointer})
+		return nil
+	}
+}
+
+// WithDataColumn adds a "data" column holding the data of the events: JSON
+// if the data of every event is JSON, binary otherwise.
+func WithDataColumn() FlattenOption {
+	return func(f *flattener) error {
+		f.data = true
+		return nil
+	}
+}
+
+// Flatten lays the events out in columns. The type of an extension column
+// is the type of its values, or string if they are of different types.
+func Flatten(events []event.Event, opts ...FlattenOption) (*Table, error) {
+	f := &flattener{}
+	for _, opt := range opts {
+		if err := opt(f); err != nil {
+			return nil, err
+		}
+	}
+
+	t := &Table{Rows: len(events)}
+	names := map[string]bool{}
+	addColumn := func(c *Column) error {
+		if names[c.Name] {
+			return fmt.Errorf("duplicate column %q", c.Name)
+		}
+		names[c.Name] = true
+		t.Columns = append(t.Columns, c)
+		return nil
+	}
+
+	for _, a := range attributeColumns {
+		c := &Column{Name: a.name, Kind: AttributeColumn, Type: a.typ, Values: make([]interface{}, len(events))}
+		for i, e := range events {
+			c.Values[i] = a.get(e)
+		}
+		_ = addColumn(c)
+	}
+
+	extensions := f.extensions
+	if extensions == nil {
+		found := map[string]bool{}
+		for _, e := range events {
+			for name := range e.Extensions() {
+				if !found[name] {
+					found[name] = true
+					extensions = append(extensions, name)
+				}
+			}
+		}
+		sort.Strings(extensions)
+	}
+	for _, name := range extensions {
+		values := make([]interface{}, len(events))
+		for i, e := range events {
+			v, err := extensionValue(e.Extensions()[name])
+			if err != nil {
+				return nil, fmt.Errorf("extension %s of event %s: %w", name, e.ID(), err)
+			}
+			values[i] = v
+		}
+		if err := addColumn(typedColumn(name, ExtensionColumn, values)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(f.dataPaths) > 0 {
+		docs := make([]interface{}, len(events))
+		for i, e := range events {
+			doc, err := decodeData(e)
+			if err != nil {
+				return nil, fmt.Errorf("data of event %s: %w", e.ID(), err)
+			}
+			docs[i] = doc
+		}
+		for _, p := range f.dataPaths {
+			values := make([]interface{}, len(events))
+			for i, doc := range docs {
+				values[i] = jsonValue(resolvePointer(doc, p.pointer))
+			}
+			if err := addColumn(typedColumn(p.column, DataPathColumn, values)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if f.data {
+		c := &Column{Name: "data", Kind: DataColumn, Type: ColumnJSON, Values: make([]interface{}, len(events))}
+		for i, e := range events {
+			if len(e.Data()) == 0 {
+				continue
+			}
+			if c.Type == ColumnJSON && !(isJSON(e) && json.Valid(e.Data())) {
+				c.Type = ColumnBinary
+			}
+			c.Values[i] = e.Data()
+		}
+		for i, v := range c.Values {
+			if v == nil {
+				continue
+			}
+			if c.Type == ColumnJSON {
+				c.Values[i] = json.RawMessage(v.([]byte))
+			}
+		}
+		if err := addColumn(c); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// extensionValue converts the value of an extension to the value of a
+// column.
+func extensionValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	v, err := types.Validate(v)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case []byte:
+		return v, nil
+	case types.Timestamp:
+		return v.Time.UTC(), nil
+	}
+	return types.Format(v)
+}
+
+// jsonValue converts a JSON value decoded with UseNumber to the value of a
+// column.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, string, bool:
+		return v
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	raw, _ := json.Marshal(v)
+	return json.RawMessage(raw)
+}
+
+func columnTypeOf(v interface{}) ColumnType {
+	switch v.(type) {
+	case int64:
+		return ColumnInt64
+	case float64:
+		return ColumnFloat64
+	case bool:
+		return ColumnBoolean
+	case time.Time:
+		return ColumnTimestamp
+	case []byte:
+		return ColumnBinary
+	case json.RawMessage:
+		return ColumnJSON
+	}
+	return ColumnString
+}
+
+// typedColumn returns a column of the type of values. Integers are widened
+// to floats, and the values of other mixed types are formatted as strings.
+func typedColumn(name string, kind ColumnKind, values []interface{}) *Column {
+	c := &Column{Name: name, Kind: kind, Type: ColumnString, Values: values}
+	first := true
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		t := columnTypeOf(v)
+		switch {
+		case first:
+			c.Type, first = t, false
+		case c.Type == t:
+		case (c.Type == ColumnInt64 && t == ColumnFloat64) || (c.Type == ColumnFloat64 && t == ColumnInt64):
+			c.Type = ColumnFloat64
+		default:
+			c.Type = ColumnString
+		}
+	}
+	for i, v := range values {
+		if v == nil || columnTypeOf(v) == c.Type {
+			continue
+		}
+		switch v := v.(type) {
+		case int64:
+			if c.Type == ColumnFloat64 {
+				values[i] = float64(v)
+				continue
+			}
+			values[i] = strconv.FormatInt(v, 10)
+		case float64:
+			values[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		case bool:
+			values[i] = strconv.FormatBool(v)
+		case time.Time:
+			values[i] = types.FormatTime(v)
+		case []byte:
+			values[i] = types.FormatBinary(v)
+		case json.RawMessage:
+			values[i] = string(v)
+		}
+	}
+	return c
+}
+
+func isJSON(e event.Event) bool {
+	ct := e.DataMediaType()
+	return ct == "" || ct == event.ApplicationJSON || ct == event.TextJSON || strings.HasSuffix(ct, "+json")
+}
+
+// decodeData returns the JSON data of e decoded with UseNumber, nil if e has
+// no JSON data.
+func decodeData(e event.Event) (interface{}, error) {
+	if len(e.Data()) == 0 || !isJSON(e) {
+		return nil, nil
+	}
+	d := json.NewDecoder(bytes.NewReader(e.Data()))
+	d.UseNumber()
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// resolvePointer returns the value of doc at pointer, nil if none.
+func resolvePointer(doc interface{}, pointer string) interface{} {
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			doc = v[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			doc = v[i]
+		default:
+			return nil
+		}
+	}
+	return doc
+}
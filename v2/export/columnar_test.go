@@ -0,0 +1,117 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func testEvents(t *testing.T) []event.Event {
+	e1 := event.New()
+	e1.SetID("1")
+	e1.SetSource("/orders")
+	e1.SetType("order.created")
+	e1.SetTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600)))
+	e1.SetExtension("priority", 3)
+	e1.SetExtension("mixed", true)
+	require.NoError(t, e1.SetData(event.ApplicationJSON, map[string]interface{}{
+		"id":    "o-1",
+		"total": 12,
+		"items": []string{"a", "b"},
+	}))
+
+	e2 := event.New()
+	e2.SetID("2")
+	e2.SetSource("/orders")
+	e2.SetType("order.deleted")
+	e2.SetSubject("o-2")
+	e2.SetExtension("mixed", "yes")
+	require.NoError(t, e2.SetData(event.ApplicationJSON, map[string]interface{}{
+		"id":    "o-2",
+		"total": 1.5,
+	}))
+	return []event.Event{e1, e2}
+}
+
+func TestFlatten(t *testing.T) {
+	table, err := Flatten(testEvents(t),
+		WithDataPath("order_id", "/id"),
+		WithDataPath("total", "/total"),
+		WithDataPath("items", "/items"),
+		WithDataPath("missing", "/missing/path"),
+		WithDataColumn(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, 2, table.Rows)
+
+	var names []string
+	for _, c := range table.Columns {
+		names = append(names, c.Name)
+	}
+	require.Equal(t, []string{
+		"specversion", "id", "source", "type", "datacontenttype", "dataschema", "subject", "time",
+		"mixed", "priority",
+		"order_id", "total", "items", "missing",
+		"data",
+	}, names)
+
+	check := func(name string, typ ColumnType, values ...interface{}) {
+		t.Helper()
+		c := table.Column(name)
+		require.NotNil(t, c, name)
+		require.Equal(t, typ, c.Type, name)
+		require.Equal(t, values, c.Values, name)
+	}
+	check("id", ColumnString, "1", "2")
+	check("subject", ColumnString, nil, "o-2")
+	check("dataschema", ColumnString, nil, nil)
+	check("time", ColumnTimestamp, time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC), nil)
+	check("priority", ColumnInt64, int64(3), nil)
+	check("mixed", ColumnString, "true", "yes")
+	check("order_id", ColumnString, "o-1", "o-2")
+	check("total", ColumnFloat64, float64(12), 1.5)
+	check("items", ColumnJSON, json.RawMessage(`["a","b"]`), nil)
+	check("missing", ColumnString, nil, nil)
+	require.Equal(t, ColumnJSON, table.Column("data").Type)
+	require.JSONEq(t, `{"id": "o-2", "total": 1.5}`, string(table.Column("data").Values[1].(json.RawMessage)))
+
+	require.Equal(t, map[string]interface{}{
+		"specversion":     "1.0",
+		"id":              "2",
+		"source":          "/orders",
+		"type":            "order.deleted",
+		"datacontenttype": "application/json",
+		"subject":         "o-2",
+		"mixed":           "yes",
+		"order_id":        "o-2",
+		"total":           1.5,
+		"data":            table.Column("data").Values[1],
+	}, table.Row(1))
+	require.Len(t, table.Map(), len(table.Columns))
+}
+
+func TestFlattenOptions(t *testing.T) {
+	events := testEvents(t)
+	require.NoError(t, events[1].SetData("text/plain", "not json"))
+
+	table, err := Flatten(events, WithExtensionColumns("priority", "region"), WithDataColumn())
+	require.NoError(t, err)
+	require.Nil(t, table.Column("mixed"))
+	require.Equal(t, []interface{}{nil, nil}, table.Column("region").Values)
+	require.Equal(t, ColumnBinary, table.Column("data").Type)
+	require.Equal(t, []byte("not json"), table.Column("data").Values[1])
+
+	_, err = Flatten(events, WithDataPath("id", "/id"))
+	require.EqualError(t, err, `duplicate column "id"`)
+	_, err = Flatten(events, WithDataPath("order_id", "id"))
+	require.Error(t, err)
+}
@@ -0,0 +1,14 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package export converts batches of events to the representations of
+analytical stores, starting with Flatten, which lays a batch out in columns:
+one per attribute and extension, and optionally per path into the JSON data.
+
+The parquet subpackage writes the flattened batches as Parquet files, and the
+bigquery subpackage encodes them as BigQuery rows.
+*/
+package export
@@ -0,0 +1,436 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package parquet writes batches of events as Parquet files, laid out in
+// columns with export.Flatten, so that archived events can be queried by
+// Spark, Trino or DuckDB.
+//
+// The files have a flat schema of optional columns, written as a single
+// PLAIN encoded data page per column and row group. Strings and JSON are
+// BYTE_ARRAY columns annotated UTF8 and JSON, and timestamps are INT64
+// columns annotated TIMESTAMP_MICROS.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/export"
+)
+
+const magic = "PAR1"
+
+const createdBy = "github.com/cloudevents/sdk-go/v2/export/parquet"
+
+// Physical types, converted types, encodings and page types of the Parquet
+// format.
+const (
+	typeBoolean   = 0
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+
+	repetitionOptional = 1
+
+	convertedUTF8            = 0
+	convertedTimestampMicros = 10
+	convertedJSON            = 19
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageData = 0
+)
+
+// Compression is the codec compressing the pages.
+type Compression int
+
+const (
+	// Uncompressed pages.
+	Uncompressed Compression = 0
+	// Gzip compressed pages.
+	Gzip Compression = 2
+)
+
+type config struct {
+	compression  Compression
+	columns      []string
+	rowGroupSize int
+	flatten      []export.FlattenOption
+}
+
+// Option configures the files written by Write and PartitionedWriter.
+type Option func(*config) error
+
+// WithCompression sets the codec compressing the pages, Uncompressed by
+// default.
+func WithCompression(c Compression) Option {
+	return func(cfg *config) error {
+		if c != Uncompressed && c != Gzip {
+			return fmt.Errorf("parquet compression option was given an unknown codec %d", c)
+		}
+		cfg.compression = c
+		return nil
+	}
+}
+
+// WithColumns projects the schema to the given columns, in this order. The
+// columns missing from a batch are written as string columns holding nulls,
+// so that the files share the same columns.
+func WithColumns(names ...string) Option {
+	return func(cfg *config) error {
+		if len(names) == 0 {
+			return errors.New("parquet columns option was given no column")
+		}
+		cfg.columns = names
+		return nil
+	}
+}
+
+// WithRowGroupSize sets the maximum number of rows of a row group. By
+// default, a file has a single row group.
+func WithRowGroupSize(rows int) Option {
+	return func(cfg *config) error {
+		if rows <= 0 {
+			return fmt.Errorf("parquet row group size option was given a non positive size: %d", rows)
+		}
+		cfg.rowGroupSize = rows
+		return nil
+	}
+}
+
+// WithFlattenOptions sets the options laying the events out in columns, e.g.
+// export.WithDataPath.
+func WithFlattenOptions(opts ...export.FlattenOption) Option {
+	return func(cfg *config) error {
+		cfg.flatten = append(cfg.flatten, opts...)
+		return nil
+	}
+}
+
+func newConfig(opts []Option) (*config, error) {
+	cfg := &config{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// Write writes events to w as a Parquet file.
+func Write(w io.Writer, events []event.Event, opts ...Option) error {
+	cfg, err := newConfig(opts)
+	if err != nil {
+		return err
+	}
+	return cfg.write(w, events)
+}
+
+func (cfg *config) write(w io.Writer, events []event.Event) error {
+	table, err := export.Flatten(events, cfg.flatten...)
+	if err != nil {
+		return err
+	}
+	if cfg.columns != nil {
+		table = project(table, cfg.columns)
+	}
+	fw := &fileWriter{w: w, compression: cfg.compression}
+	return fw.write(table, cfg.rowGroupSize)
+}
+
+// project returns the columns of t with the given names.
+func project(t *export.Table, names []string) *export.Table {
+	projected := &export.Table{Rows: t.Rows}
+	for _, name := range names {
+		c := t.Column(name)
+		if c == nil {
+			c = &export.Column{Name: name, Type: export.ColumnString, Values: make([]interface{}, t.Rows)}
+		}
+		projected.Columns = append(projected.Columns, c)
+	}
+	return projected
+}
+
+type columnChunk struct {
+	typ              int32
+	codec            Compression
+	offset           int64
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+type rowGroup struct {
+	chunks []columnChunk
+	rows   int64
+	size   int64
+}
+
+type fileWriter struct {
+	w           io.Writer
+	compression Compression
+	offset      int64
+	err         error
+}
+
+func (fw *fileWriter) writeBytes(b []byte) {
+	if fw.err != nil {
+		return
+	}
+	n, err := fw.w.Write(b)
+	fw.offset += int64(n)
+	fw.err = err
+}
+
+func (fw *fileWriter) write(t *export.Table, rowGroupSize int) error {
+	if len(t.Columns) == 0 {
+		return errors.New("no column to write")
+	}
+	if rowGroupSize == 0 {
+		rowGroupSize = t.Rows
+	}
+
+	fw.writeBytes([]byte(magic))
+	var groups []rowGroup
+	for start := 0; start < t.Rows; start += rowGroupSize {
+		end := min(start+rowGroupSize, t.Rows)
+		g := rowGroup{rows: int64(end - start)}
+		for _, c := range t.Columns {
+			chunk, err := fw.writeChunk(c, c.Values[start:end])
+			if err != nil {
+				return err
+			}
+			g.chunks = append(g.chunks, chunk)
+			g.size += chunk.uncompressedSize
+		}
+		groups = append(groups, g)
+	}
+
+	footer := fileMetadata(t, groups)
+	fw.writeBytes(footer)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	fw.writeBytes(length[:])
+	fw.writeBytes([]byte(magic))
+	return fw.err
+}
+
+// writeChunk writes the values of a column chunk as a single data page.
+func (fw *fileWriter) writeChunk(c *export.Column, values []interface{}) (columnChunk, error) {
+	typ, _ := physicalType(c.Type)
+
+	var page bytes.Buffer
+	levels := definitionLevels(values)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(levels)))
+	page.Write(length[:])
+	page.Write(levels)
+	if err := plainValues(&page, c, values); err != nil {
+		return columnChunk{}, err
+	}
+
+	data := page.Bytes()
+	if fw.compression == Gzip {
+		var compressed bytes.Buffer
+		zw := gzip.NewWriter(&compressed)
+		_, _ = zw.Write(data)
+		if err := zw.Close(); err != nil {
+			return columnChunk{}, err
+		}
+		data = compressed.Bytes()
+	}
+
+	var header thriftWriter
+	header.structValue(func() {
+		header.i32(1, pageData)
+		header.i32(2, int32(page.Len()))
+		header.i32(3, int32(len(data)))
+		header.structField(5, func() {
+			header.i32(1, int32(len(values)))
+			header.i32(2, encodingPlain)
+			header.i32(3, encodingRLE)
+			header.i32(4, encodingRLE)
+		})
+	})
+
+	chunk := columnChunk{
+		typ:              typ,
+		codec:            fw.compression,
+		offset:           fw.offset,
+		numValues:        int64(len(values)),
+		uncompressedSize: int64(header.buf.Len() + page.Len()),
+		compressedSize:   int64(header.buf.Len() + len(data)),
+	}
+	fw.writeBytes(header.buf.Bytes())
+	fw.writeBytes(data)
+	return chunk, fw.err
+}
+
+// physicalType returns the physical and converted types of a column type,
+// the converted type being -1 if none.
+func physicalType(t export.ColumnType) (int32, int32) {
+	switch t {
+	case export.ColumnInt64:
+		return typeInt64, -1
+	case export.ColumnFloat64:
+		return typeDouble, -1
+	case export.ColumnBoolean:
+		return typeBoolean, -1
+	case export.ColumnTimestamp:
+		return typeInt64, convertedTimestampMicros
+	case export.ColumnBinary:
+		return typeByteArray, -1
+	case export.ColumnJSON:
+		return typeByteArray, convertedJSON
+	}
+	return typeByteArray, convertedUTF8
+}
+
+// definitionLevels encodes the definition levels of values, 1 if a value is
+// present and 0 if null, with the RLE/bit-packing hybrid encoding of bit
+// width 1, as runs of repeated levels.
+func definitionLevels(values []interface{}) []byte {
+	var b []byte
+	for i := 0; i < len(values); {
+		level := byte(0)
+		if values[i] != nil {
+			level = 1
+		}
+		run := 1
+		for i+run < len(values) && (values[i+run] != nil) == (level == 1) {
+			run++
+		}
+		b = binary.AppendUvarint(b, uint64(run)<<1)
+		b = append(b, level)
+		i += run
+	}
+	return b
+}
+
+// plainValues writes the non null values with the PLAIN encoding.
+func plainValues(buf *bytes.Buffer, c *export.Column, values []interface{}) error {
+	var bits, nbits byte
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		var err error
+		switch c.Type {
+		case export.ColumnBoolean:
+			b, ok := v.(bool)
+			if !ok {
+				err = typeError(c, v)
+				break
+			}
+			if b {
+				bits |= 1 << nbits
+			}
+			if nbits++; nbits == 8 {
+				buf.WriteByte(bits)
+				bits, nbits = 0, 0
+			}
+		case export.ColumnInt64:
+			i, ok := v.(int64)
+			if !ok {
+				err = typeError(c, v)
+				break
+			}
+			_ = binary.Write(buf, binary.LittleEndian, i)
+		case export.ColumnFloat64:
+			f, ok := v.(float64)
+			if !ok {
+				err = typeError(c, v)
+				break
+			}
+			_ = binary.Write(buf, binary.LittleEndian, math.Float64bits(f))
+		case export.ColumnTimestamp:
+			t, ok := v.(time.Time)
+			if !ok {
+				err = typeError(c, v)
+				break
+			}
+			_ = binary.Write(buf, binary.LittleEndian, t.UnixMicro())
+		default:
+			var b []byte
+			switch v := v.(type) {
+			case string:
+				b = []byte(v)
+			case []byte:
+				b = v
+			case json.RawMessage:
+				b = v
+			default:
+				err = typeError(c, v)
+			}
+			_ = binary.Write(buf, binary.LittleEndian, uint32(len(b)))
+			buf.Write(b)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if nbits > 0 {
+		buf.WriteByte(bits)
+	}
+	return nil
+}
+
+func typeError(c *export.Column, v interface{}) error {
+	return fmt.Errorf("column %s of type %s has a value of type %T", c.Name, c.Type, v)
+}
+
+// fileMetadata encodes the FileMetaData of the footer.
+func fileMetadata(t *export.Table, groups []rowGroup) []byte {
+	var w thriftWriter
+	w.structValue(func() {
+		w.i32(1, 1)
+		w.structList(2, len(t.Columns)+1, func(i int) {
+			if i == 0 {
+				w.string(4, "schema")
+				w.i32(5, int32(len(t.Columns)))
+				return
+			}
+			c := t.Columns[i-1]
+			typ, converted := physicalType(c.Type)
+			w.i32(1, typ)
+			w.i32(3, repetitionOptional)
+			w.string(4, c.Name)
+			if converted >= 0 {
+				w.i32(6, converted)
+			}
+		})
+		w.i64(3, int64(t.Rows))
+		w.structList(4, len(groups), func(i int) {
+			g := groups[i]
+			w.structList(1, len(g.chunks), func(j int) {
+				chunk := g.chunks[j]
+				w.i64(2, chunk.offset)
+				w.structField(3, func() {
+					w.i32(1, chunk.typ)
+					w.i32List(2, encodingPlain, encodingRLE)
+					w.stringList(3, t.Columns[j].Name)
+					w.i32(4, int32(chunk.codec))
+					w.i64(5, chunk.numValues)
+					w.i64(6, chunk.uncompressedSize)
+					w.i64(7, chunk.compressedSize)
+					w.i64(9, chunk.offset)
+				})
+			})
+			w.i64(2, g.size)
+			w.i64(3, g.rows)
+		})
+		w.string(6, createdBy)
+	})
+	return w.buf.Bytes()
+}
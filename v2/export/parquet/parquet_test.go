@@ -0,0 +1,383 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/export"
+)
+
+// thriftReader decodes Thrift compact structs into maps by field id, with
+// integers as int64, binaries as []byte and lists as []interface{}.
+type thriftReader struct {
+	t *testing.T
+	r *bytes.Reader
+}
+
+func (r thriftReader) uvarint() uint64 {
+	v, err := binary.ReadUvarint(r.r)
+	require.NoError(r.t, err)
+	return v
+}
+
+func (r thriftReader) varint() int64 {
+	u := r.uvarint()
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r thriftReader) byte() byte {
+	b, err := r.r.ReadByte()
+	require.NoError(r.t, err)
+	return b
+}
+
+func (r thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		b := make([]byte, r.uvarint())
+		_, err := io.ReadFull(r.r, b)
+		require.NoError(r.t, err)
+		return b
+	case thriftList:
+		h := r.byte()
+		size := int(h >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(h & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.structValue()
+	}
+	r.t.Fatalf("unexpected thrift type %d", typ)
+	return nil
+}
+
+func (r thriftReader) structValue() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var last int16
+	for {
+		h := r.byte()
+		if h == 0 {
+			return fields
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(h & 0x0f)
+		last = id
+	}
+}
+
+type column struct {
+	name      string
+	typ       int64
+	converted interface{}
+	values    []interface{}
+}
+
+// readFile decodes a file written by Write.
+func readFile(t *testing.T, b []byte) ([]column, int64) {
+	require.Equal(t, magic, string(b[:4]))
+	require.Equal(t, magic, string(b[len(b)-4:]))
+	footerLen := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	meta := thriftReader{t, bytes.NewReader(b[len(b)-8-footerLen : len(b)-8])}.structValue()
+	require.Equal(t, int64(1), meta[1])
+
+	schema := meta[2].([]interface{})
+	root := schema[0].(map[int16]interface{})
+	require.Equal(t, int64(len(schema)-1), root[5])
+	columns := make([]column, len(schema)-1)
+	for i, s := range schema[1:] {
+		s := s.(map[int16]interface{})
+		require.Equal(t, int64(repetitionOptional), s[3])
+		columns[i] = column{name: string(s[4].([]byte)), typ: s[1].(int64), converted: s[6]}
+	}
+
+	var rows int64
+	for _, g := range meta[4].([]interface{}) {
+		g := g.(map[int16]interface{})
+		rows += g[3].(int64)
+		for i, c := range g[1].([]interface{}) {
+			md := c.(map[int16]interface{})[3].(map[int16]interface{})
+			require.Equal(t, columns[i].typ, md[1])
+			require.Equal(t, columns[i].name, string(md[3].([]interface{})[0].([]byte)))
+			require.Equal(t, g[3], md[5])
+			offset := md[9].(int64)
+
+			pr := bytes.NewReader(b[offset:])
+			header := thriftReader{t, pr}.structValue()
+			require.Equal(t, int64(pageData), header[1])
+			headerLen := int64(len(b[offset:]) - pr.Len())
+			require.Equal(t, md[7], headerLen+header[3].(int64))
+			page := make([]byte, header[3].(int64))
+			_, err := io.ReadFull(pr, page)
+			require.NoError(t, err)
+			if md[4] == int64(Gzip) {
+				zr, err := gzip.NewReader(bytes.NewReader(page))
+				require.NoError(t, err)
+				page, err = io.ReadAll(zr)
+				require.NoError(t, err)
+			}
+			require.Equal(t, header[2], int64(len(page)))
+			numValues := int(header[5].(map[int16]interface{})[1].(int64))
+			columns[i].values = append(columns[i].values, decodePage(t, columns[i].typ, page, numValues)...)
+		}
+	}
+	require.Equal(t, meta[3], rows)
+	return columns, rows
+}
+
+// decodePage decodes the definition levels and the PLAIN values of a page.
+func decodePage(t *testing.T, typ int64, page []byte, numValues int) []interface{} {
+	levelsLen := binary.LittleEndian.Uint32(page)
+	levels := thriftReader{t, bytes.NewReader(page[4 : 4+levelsLen])}
+	present := make([]bool, 0, numValues)
+	for len(present) < numValues {
+		run := int(levels.uvarint() >> 1)
+		level := levels.byte()
+		for i := 0; i < run; i++ {
+			present = append(present, level == 1)
+		}
+	}
+
+	r := bytes.NewReader(page[4+levelsLen:])
+	values := make([]interface{}, numValues)
+	bit := 0
+	var bits byte
+	for i, ok := range present {
+		if !ok {
+			continue
+		}
+		switch typ {
+		case typeBoolean:
+			if bit%8 == 0 {
+				bits, _ = r.ReadByte()
+			}
+			values[i] = bits&(1<<(bit%8)) != 0
+			bit++
+		case typeInt64:
+			var v int64
+			require.NoError(t, binary.Read(r, binary.LittleEndian, &v))
+			values[i] = v
+		case typeDouble:
+			var v uint64
+			require.NoError(t, binary.Read(r, binary.LittleEndian, &v))
+			values[i] = math.Float64frombits(v)
+		case typeByteArray:
+			var n uint32
+			require.NoError(t, binary.Read(r, binary.LittleEndian, &n))
+			v := make([]byte, n)
+			_, err := io.ReadFull(r, v)
+			require.NoError(t, err)
+			values[i] = string(v)
+		}
+	}
+	require.Zero(t, r.Len())
+	return values
+}
+
+func testEvents(t *testing.T, n int) []event.Event {
+	events := make([]event.Event, n)
+	for i := range events {
+		e := event.New()
+		e.SetID(string(rune('a' + i)))
+		e.SetSource("/orders")
+		e.SetType("order.created")
+		if i%2 == 0 {
+			e.SetType("order.deleted")
+			e.SetTime(time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC))
+			e.SetExtension("priority", i)
+		}
+		e.SetExtension("replayed", i%3 == 0)
+		require.NoError(t, e.SetData(event.ApplicationJSON, map[string]interface{}{"total": float64(i) + 0.5}))
+		events[i] = e
+	}
+	return events
+}
+
+func TestWrite(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"uncompressed":   nil,
+		"gzip":           {WithCompression(Gzip)},
+		"row groups":     {WithRowGroupSize(3)},
+		"gzip row group": {WithCompression(Gzip), WithRowGroupSize(4)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append(opts, WithFlattenOptions(export.WithDataPath("total", "/total"), export.WithDataColumn()))
+			require.NoError(t, Write(&buf, testEvents(t, 10), opts...))
+
+			columns, rows := readFile(t, buf.Bytes())
+			require.Equal(t, int64(10), rows)
+			byName := map[string]column{}
+			var names []string
+			for _, c := range columns {
+				byName[c.name] = c
+				names = append(names, c.name)
+			}
+			require.Equal(t, []string{
+				"specversion", "id", "source", "type", "datacontenttype", "dataschema", "subject", "time",
+				"priority", "replayed", "total", "data",
+			}, names)
+
+			require.Equal(t, int64(convertedUTF8), byName["id"].converted)
+			require.Equal(t, []interface{}{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}, byName["id"].values)
+			require.Equal(t, make([]interface{}, 10), byName["dataschema"].values)
+
+			ts := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC).UnixMicro()
+			require.Equal(t, int64(convertedTimestampMicros), byName["time"].converted)
+			require.Equal(t, []interface{}{ts, nil, ts, nil, ts, nil, ts, nil, ts, nil}, byName["time"].values)
+
+			require.Equal(t, int64(typeInt64), byName["priority"].typ)
+			require.Nil(t, byName["priority"].converted)
+			require.Equal(t, []interface{}{int64(0), nil, int64(2), nil, int64(4), nil, int64(6), nil, int64(8), nil}, byName["priority"].values)
+
+			require.Equal(t, int64(typeBoolean), byName["replayed"].typ)
+			require.Equal(t, []interface{}{true, false, false, true, false, false, true, false, false, true}, byName["replayed"].values)
+
+			require.Equal(t, int64(typeDouble), byName["total"].typ)
+			require.Equal(t, 3.5, byName["total"].values[3])
+
+			require.Equal(t, int64(convertedJSON), byName["data"].converted)
+			require.JSONEq(t, `{"total": 9.5}`, byName["data"].values[9].(string))
+		})
+	}
+}
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// goldenFiles are the options of the files of testdata, read by
+// hack/parquet-test.sh with pyarrow to check them against
+// testdata/events.json.
+var goldenFiles = map[string][]Option{
+	"events.parquet":      nil,
+	"events_gzip.parquet": {WithCompression(Gzip), WithRowGroupSize(4)},
+}
+
+// goldenTable is the layout of testdata/events.json: the values of the
+// columns, timestamps being formatted in RFC 3339 with microseconds.
+type goldenTable struct {
+	Rows    int                      `json:"rows"`
+	Columns []string                 `json:"columns"`
+	Values  map[string][]interface{} `json:"values"`
+}
+
+func TestGolden(t *testing.T) {
+	events := testEvents(t, 10)
+	flatten := []export.FlattenOption{export.WithDataPath("total", "/total"), export.WithDataColumn()}
+	for name, opts := range goldenFiles {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, events, append(opts, WithFlattenOptions(flatten...))...))
+			path := filepath.Join("testdata", name)
+			if *update {
+				require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+			}
+			want, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, want, buf.Bytes(), "run go test -update to update the golden files")
+		})
+	}
+
+	table, err := export.Flatten(events, flatten...)
+	require.NoError(t, err)
+	golden := goldenTable{Rows: table.Rows, Values: map[string][]interface{}{}}
+	for _, c := range table.Columns {
+		golden.Columns = append(golden.Columns, c.Name)
+		values := make([]interface{}, len(c.Values))
+		for i, v := range c.Values {
+			switch v := v.(type) {
+			case time.Time:
+				values[i] = v.UTC().Format("2006-01-02T15:04:05.000000Z")
+			case json.RawMessage:
+				values[i] = string(v)
+			default:
+				values[i] = v
+			}
+		}
+		golden.Values[c.Name] = values
+	}
+	b, err := json.MarshalIndent(golden, "", "  ")
+	require.NoError(t, err)
+	path := filepath.Join("testdata", "events.json")
+	if *update {
+		require.NoError(t, os.WriteFile(path, append(b, '\n'), 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.JSONEq(t, string(want), string(b))
+}
+
+func TestWriteProjection(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testEvents(t, 2), WithColumns("type", "region", "id")))
+	columns, _ := readFile(t, buf.Bytes())
+	require.Len(t, columns, 3)
+	require.Equal(t, "type", columns[0].name)
+	require.Equal(t, "region", columns[1].name)
+	require.Equal(t, []interface{}{nil, nil}, columns[1].values)
+	require.Equal(t, []interface{}{"a", "b"}, columns[2].values)
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, nil, WithColumns("id")))
+	_, rows := readFile(t, buf.Bytes())
+	require.Zero(t, rows)
+}
+
+func TestPartitionedWriter(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewPartitionedWriter(DirSink(dir), PartitionBy(PartitionByType(), PartitionByTime("date=2006-01-02")))
+	require.NoError(t, err)
+	w.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	events := testEvents(t, 5)
+	events[4].SetType("order/archived")
+	paths, err := w.Write(context.Background(), events)
+	require.NoError(t, err)
+	require.Len(t, paths, 3)
+	require.Regexp(t, `^type=order%2Farchived/date=2024-01-02/part-`, paths[0])
+	require.Regexp(t, `^type=order.created/__HIVE_DEFAULT_PARTITION__/part-1700000000000000000-[0-9a-f-]{36}\.parquet$`, paths[1])
+	require.Regexp(t, `^type=order.deleted/date=2024-01-02/part-`, paths[2])
+
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(paths[2])))
+	require.NoError(t, err)
+	_, rows := readFile(t, b)
+	require.Equal(t, int64(2), rows)
+}
+
+func TestOptionsInvalid(t *testing.T) {
+	require.Error(t, Write(io.Discard, nil, WithCompression(1)))
+	require.Error(t, Write(io.Discard, nil, WithRowGroupSize(0)))
+	require.Error(t, Write(io.Discard, nil, WithColumns()))
+	_, err := NewPartitionedWriter(nil, PartitionByType())
+	require.Error(t, err)
+}
@@ -0,0 +1,169 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package parquet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// DefaultPartition is the partition of the events without the value a
+// Partitioner partitions by, as named by Hive.
+const DefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
+// Partitioner returns the directory of the partition of an event, relative to
+// the root of the dataset, e.g. "type=order.created/date=2024-01-02".
+type Partitioner func(e event.Event) string
+
+// PartitionByAttribute partitions the events by the value of a context
+// attribute or an extension, in a name=value directory.
+func PartitionByAttribute(name string) Partitioner {
+	return func(e event.Event) string {
+		var v interface{}
+		if attr := spec.V1.Attribute(name); attr != nil && e.Context != nil {
+			v = attr.Get(e.Context)
+		} else {
+			v = e.Extensions()[name]
+		}
+		value := DefaultPartition
+		if v != nil {
+			if s, err := types.Format(v); err == nil && s != "" {
+				value = escapePartitionValue(s)
+			}
+		}
+		return name + "=" + value
+	}
+}
+
+// PartitionByType partitions the events by type.
+func PartitionByType() Partitioner {
+	return PartitionByAttribute("type")
+}
+
+// PartitionByTime partitions the events by their time attribute in UTC,
+// formatted with layout, e.g. "date=2006-01-02/hour=15".
+func PartitionByTime(layout string) Partitioner {
+	return func(e event.Event) string {
+		t := e.Time()
+		if t.IsZero() {
+			return DefaultPartition
+		}
+		return t.UTC().Format(layout)
+	}
+}
+
+// PartitionBy combines partitioners, nesting the partitions of each in those
+// of the previous one.
+func PartitionBy(partitioners ...Partitioner) Partitioner {
+	return func(e event.Event) string {
+		dirs := make([]string, len(partitioners))
+		for i, p := range partitioners {
+			dirs[i] = p(e)
+		}
+		return path.Join(dirs...)
+	}
+}
+
+// escapePartitionValue escapes the characters which can not appear in a
+// directory name, as Hive does.
+func escapePartitionValue(v string) string {
+	var b strings.Builder
+	for _, r := range v {
+		if r < 0x20 || strings.ContainsRune("\"#%'*/:=?\\\x7f{[]^", r) {
+			fmt.Fprintf(&b, "%%%02X", r)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FileSink creates the file at path, relative to the root of the dataset.
+type FileSink func(ctx context.Context, path string) (io.WriteCloser, error)
+
+// DirSink returns a FileSink creating the files under the directory root.
+func DirSink(root string) FileSink {
+	return func(_ context.Context, name string) (io.WriteCloser, error) {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return nil, err
+		}
+		return os.Create(p)
+	}
+}
+
+// PartitionedWriter writes batches of events as Parquet files, one per
+// partition of the batch, e.g. to build a dataset partitioned by type and
+// date.
+type PartitionedWriter struct {
+	sink        FileSink
+	partitioner Partitioner
+	cfg         *config
+	now         func() time.Time
+}
+
+// NewPartitionedWriter returns a PartitionedWriter creating the files with
+// sink in the directories returned by partitioner.
+func NewPartitionedWriter(sink FileSink, partitioner Partitioner, opts ...Option) (*PartitionedWriter, error) {
+	if sink == nil {
+		return nil, errors.New("parquet partitioned writer was given a nil sink")
+	}
+	if partitioner == nil {
+		return nil, errors.New("parquet partitioned writer was given a nil partitioner")
+	}
+	cfg, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &PartitionedWriter{sink: sink, partitioner: partitioner, cfg: cfg, now: time.Now}, nil
+}
+
+// Write writes the events of each partition to a new file, named after the
+// time of the write and a random UUID, and returns the paths of the files.
+func (w *PartitionedWriter) Write(ctx context.Context, events []event.Event) ([]string, error) {
+	partitions := map[string][]event.Event{}
+	for _, e := range events {
+		p := w.partitioner(e)
+		partitions[p] = append(partitions[p], e)
+	}
+	dirs := make([]string, 0, len(partitions))
+	for dir := range partitions {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var paths []string
+	for _, dir := range dirs {
+		name := path.Join(dir, fmt.Sprintf("part-%d-%s.parquet", w.now().UnixNano(), uuid.New()))
+		f, err := w.sink(ctx, name)
+		if err != nil {
+			return paths, fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		err = w.cfg.write(f, partitions[dir])
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		paths = append(paths, name)
+	}
+	return paths, nil
+}
@@ -0,0 +1,115 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Types of the Thrift compact protocol.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the Parquet metadata with the Thrift compact protocol.
+type thriftWriter struct {
+	buf bytes.Buffer
+	// last is the id of the last field of the current struct, and lasts
+	// those of the enclosing structs.
+	last  int16
+	lasts []int16
+}
+
+func (w *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	w.buf.Write(b[:n])
+}
+
+func (w *thriftWriter) varint(v int64) {
+	w.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(int64(id))
+	}
+	w.last = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) binaryValue(v string) {
+	w.uvarint(uint64(len(v)))
+	w.buf.WriteString(v)
+}
+
+func (w *thriftWriter) string(id int16, v string) {
+	w.fieldHeader(id, thriftBinary)
+	w.binaryValue(v)
+}
+
+func (w *thriftWriter) listHeader(id int16, elemType byte, size int) {
+	w.fieldHeader(id, thriftList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		w.buf.WriteByte(0xf0 | elemType)
+		w.uvarint(uint64(size))
+	}
+}
+
+func (w *thriftWriter) i32List(id int16, values ...int32) {
+	w.listHeader(id, thriftI32, len(values))
+	for _, v := range values {
+		w.varint(int64(v))
+	}
+}
+
+func (w *thriftWriter) stringList(id int16, values ...string) {
+	w.listHeader(id, thriftBinary, len(values))
+	for _, v := range values {
+		w.binaryValue(v)
+	}
+}
+
+// structValue writes a struct whose fields are written by fields.
+func (w *thriftWriter) structValue(fields func()) {
+	w.lasts = append(w.lasts, w.last)
+	w.last = 0
+	fields()
+	w.buf.WriteByte(0) // stop
+	w.last = w.lasts[len(w.lasts)-1]
+	w.lasts = w.lasts[:len(w.lasts)-1]
+}
+
+func (w *thriftWriter) structField(id int16, fields func()) {
+	w.fieldHeader(id, thriftStruct)
+	w.structValue(fields)
+}
+
+func (w *thriftWriter) structList(id int16, size int, elem func(i int)) {
+	w.listHeader(id, thriftStruct, size)
+	for i := 0; i < size; i++ {
+		w.structValue(func() { elem(i) })
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+const PriorityExtensionKey = "priority"
+
+// Common priorities. Any other integer can be used: the higher the priority,
+// the more urgent the event.
+const (
+	PriorityLow    int32 = -10
+	PriorityNormal int32 = 0
+	PriorityHigh   int32 = 10
+)
+
+// AddPriorityExtension adds the priority attribute to the cloudevents context
+func AddPriorityExtension(e *event.Event, priority int32) {
+	e.SetExtension(PriorityExtensionKey, priority)
+}
+
+// GetPriorityExtension returns the priority attribute present in the
+// cloudevent event/context and a bool to indicate if it was found.
+// If not found or not an integer, the priority is PriorityNormal.
+func GetPriorityExtension(e event.Event) (int32, bool) {
+	if v, ok := e.Extensions()[PriorityExtensionKey]; ok {
+		if priority, err := types.ToInteger(v); err == nil {
+			return priority, true
+		}
+	}
+	return PriorityNormal, false
+}
+
+// GetPriorityFromMessage reads the priority attribute from the message
+// metadata, with the same semantics as GetPriorityExtension.
+// Messages in structured mode don't expose their metadata, so their priority
+// is never found.
+func GetPriorityFromMessage(m binding.Message) (int32, bool) {
+	if r, ok := m.(binding.MessageMetadataReader); ok {
+		if v := r.GetExtension(PriorityExtensionKey); v != nil {
+			if priority, err := types.ToInteger(v); err == nil {
+				return priority, true
+			}
+		}
+	}
+	return PriorityNormal, false
+}
@@ -0,0 +1,55 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"testing"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestPriorityExtension(t *testing.T) {
+	e := event.New()
+	if p, ok := GetPriorityExtension(e); ok || p != PriorityNormal {
+		t.Fatalf("Expected no priority, got %d", p)
+	}
+
+	AddPriorityExtension(&e, PriorityHigh)
+	if p, ok := GetPriorityExtension(e); !ok || p != PriorityHigh {
+		t.Fatalf("Expected priority %d, got %d", PriorityHigh, p)
+	}
+
+	// Binary protocols carry the extension as a string.
+	e.SetExtension(PriorityExtensionKey, "-3")
+	if p, ok := GetPriorityExtension(e); !ok || p != -3 {
+		t.Fatalf("Expected priority -3, got %d", p)
+	}
+
+	e.SetExtension(PriorityExtensionKey, "urgent")
+	if p, ok := GetPriorityExtension(e); ok || p != PriorityNormal {
+		t.Fatalf("Expected invalid priority to be ignored, got %d", p)
+	}
+}
+
+func TestGetPriorityFromMessage(t *testing.T) {
+	e := event.New()
+	e.SetID("id")
+	e.SetSource("source")
+	e.SetType("type")
+	AddPriorityExtension(&e, PriorityLow)
+
+	if p, ok := GetPriorityFromMessage(binding.ToMessage(&e)); !ok || p != PriorityLow {
+		t.Fatalf("Expected priority %d, got %d", PriorityLow, p)
+	}
+	if p, ok := GetPriorityFromMessage(bindingtest.MustCreateMockBinaryMessage(e)); !ok || p != PriorityLow {
+		t.Fatalf("Expected priority %d, got %d", PriorityLow, p)
+	}
+	if _, ok := GetPriorityFromMessage(bindingtest.MustCreateMockStructuredMessage(t, e)); ok {
+		t.Fatalf("Expected no priority in structured message")
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+const (
+	// DeliveryAttemptExtensionKey is the extension holding the number of
+	// times the transport delivered the event, 1 the first time.
+	DeliveryAttemptExtensionKey = "deliveryattempt"
+	// FirstDeliveryTimeExtensionKey is the extension holding the time the
+	// transport first delivered the event.
+	FirstDeliveryTimeExtensionKey = "firstdeliverytime"
+)
+
+// AddRedeliveryExtensions sets the delivery attempt and the first delivery
+// time attributes of the event from the metadata of the transport it has
+// been received with, leaving unset those the transport does not expose.
+func AddRedeliveryExtensions(e *event.Event, md protocol.TransportMetadata) {
+	if md.DeliveryAttempt > 0 {
+		e.SetExtension(DeliveryAttemptExtensionKey, int32(md.DeliveryAttempt))
+	}
+	if !md.FirstDeliveryTime.IsZero() {
+		e.SetExtension(FirstDeliveryTimeExtensionKey, types.Timestamp{Time: md.FirstDeliveryTime.UTC()})
+	}
+}
+
+// GetDeliveryAttempt returns the delivery attempt attribute present in the
+// cloudevent event/context and a bool to indicate if it was found.
+func GetDeliveryAttempt(e event.Event) (int32, bool) {
+	if v, ok := e.Extensions()[DeliveryAttemptExtensionKey]; ok {
+		if attempt, err := types.ToInteger(v); err == nil {
+			return attempt, true
+		}
+	}
+	return 0, false
+}
+
+// GetFirstDeliveryTime returns the first delivery time attribute present in
+// the cloudevent event/context and a bool to indicate if it was found.
+func GetFirstDeliveryTime(e event.Event) (time.Time, bool) {
+	if v, ok := e.Extensions()[FirstDeliveryTimeExtensionKey]; ok {
+		if t, err := types.ToTime(v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,41 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package extensions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestRedeliveryExtensions(t *testing.T) {
+	e := event.New()
+	AddRedeliveryExtensions(&e, protocol.TransportMetadata{})
+	if len(e.Extensions()) != 0 {
+		t.Fatalf("Expected no extensions, got %v", e.Extensions())
+	}
+
+	first := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	AddRedeliveryExtensions(&e, protocol.TransportMetadata{DeliveryAttempt: 3, FirstDeliveryTime: first})
+	if attempt, ok := GetDeliveryAttempt(e); !ok || attempt != 3 {
+		t.Fatalf("Expected delivery attempt 3, got %d", attempt)
+	}
+	if got, ok := GetFirstDeliveryTime(e); !ok || !got.Equal(first) {
+		t.Fatalf("Expected first delivery time %v, got %v", first, got)
+	}
+
+	// Binary protocols carry the extensions as strings.
+	e.SetExtension(DeliveryAttemptExtensionKey, "2")
+	e.SetExtension(FirstDeliveryTimeExtensionKey, "2024-01-02T03:04:05Z")
+	if attempt, ok := GetDeliveryAttempt(e); !ok || attempt != 2 {
+		t.Fatalf("Expected delivery attempt 2, got %d", attempt)
+	}
+	if got, ok := GetFirstDeliveryTime(e); !ok || !got.Equal(first) {
+		t.Fatalf("Expected first delivery time %v, got %v", first, got)
+	}
+}
@@ -4,6 +4,10 @@
 */
 
 /*
-Package observability holds metrics and tracing common keys.
+Package observability holds metrics and tracing common keys, and the Metrics
+interface used by the protocol bindings to emit their metrics.
+
+A Projection selects the attributes of the events attached to the logs, the
+metrics and the spans, in order to control their cardinality.
 */
 package observability
@@ -16,4 +16,8 @@ const (
 	SourceAttr          = "cloudevents.source"
 	SubjectAttr         = "cloudevents.subject"
 	DatacontenttypeAttr = "cloudevents.datacontenttype"
+
+	// log fields correlating the log lines with the traces
+	TraceIDLogField = "trace_id"
+	SpanIDLogField  = "span_id"
 )
@@ -0,0 +1,36 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package observability
+
+import "time"
+
+// Attribute is a key/value pair qualifying a metric measurement.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Metrics is implemented by the metrics backends (e.g. OpenTelemetry) to
+// record the metrics emitted by the protocol bindings, independently of the
+// backend. Instruments are identified by name and created on first use.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// AddCounter adds delta to the monotonic counter name.
+	AddCounter(name string, delta int64, attrs ...Attribute)
+	// SetGauge sets the current value of the gauge name.
+	SetGauge(name string, value int64, attrs ...Attribute)
+	// RecordDuration records d in the histogram name.
+	RecordDuration(name string, d time.Duration, attrs ...Attribute)
+}
+
+// NoopMetrics is a Metrics discarding all the measurements.
+type NoopMetrics struct{}
+
+func (NoopMetrics) AddCounter(string, int64, ...Attribute)             {}
+func (NoopMetrics) SetGauge(string, int64, ...Attribute)               {}
+func (NoopMetrics) RecordDuration(string, time.Duration, ...Attribute) {}
+
+var _ Metrics = NoopMetrics{}
@@ -0,0 +1,209 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package observability
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// attrPrefix prefixes the names of the event attributes in the keys of the
+// observability attributes, as in TypeAttr.
+const attrPrefix = "cloudevents."
+
+// specAttributes are the names of the context attributes of the events.
+var specAttributes = map[string]struct{}{
+	"specversion": {}, "id": {}, "type": {}, "source": {}, "subject": {},
+	"datacontenttype": {}, "dataschema": {}, "time": {},
+}
+
+// Projection selects the attributes and extensions of the events qualifying
+// the logs, the metric measurements and the spans, so that their cardinality
+// stays bounded: an attribute with a value per event, such as the id, turns
+// each measurement into a new time series. The values of the high-cardinality
+// attributes worth keeping, such as a tenant extension, can be hashed into a
+// bounded number of buckets.
+//
+// A nil *Projection keeps all the attributes.
+type Projection struct {
+	names  []string
+	hashed map[string]uint64
+}
+
+// ProjectionOption configures a Projection.
+type ProjectionOption func(*Projection)
+
+// WithHashedAttribute replaces the values of the attribute or extension name,
+// which must be projected, with their FNV-1a hash modulo buckets, so that it
+// has at most buckets values. With buckets lower than 1, the values are
+// replaced with their full hash, hiding them without bounding their
+// cardinality.
+func WithHashedAttribute(name string, buckets int) ProjectionOption {
+	return func(p *Projection) {
+		if buckets < 1 {
+			buckets = 0
+		}
+		p.hashed[strings.ToLower(name)] = uint64(buckets)
+	}
+}
+
+// NewProjection returns a Projection keeping the attributes and extensions
+// with the given names, e.g. "type" or "tenant", in that order.
+func NewProjection(names []string, opts ...ProjectionOption) *Projection {
+	p := &Projection{hashed: map[string]uint64{}}
+	for _, name := range names {
+		p.names = append(p.names, strings.ToLower(name))
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// DefaultProjection keeps the attributes of the events with a bounded
+// cardinality in most applications: specversion, type, source and
+// datacontenttype.
+var DefaultProjection = NewProjection([]string{"specversion", "type", "source", "datacontenttype"})
+
+// Attributes returns the projected attributes of e, keyed by their name
+// prefixed with "cloudevents.", as TypeAttr. The attributes e does not have
+// are omitted.
+func (p *Projection) Attributes(e event.Event) []Attribute {
+	var names []string
+	if p != nil {
+		names = p.names
+	} else {
+		names = make([]string, 0, len(specAttributes)+len(e.Extensions()))
+		for name := range specAttributes {
+			names = append(names, name)
+		}
+		for name := range e.Extensions() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	attrs := make([]Attribute, 0, len(names))
+	for _, name := range names {
+		if v, ok := attributeValue(e, name); ok {
+			attrs = append(attrs, Attribute{Key: attrPrefix + name, Value: p.project(name, v)})
+		}
+	}
+	return attrs
+}
+
+// Filter returns the attributes of attrs qualifying a measurement or a span
+// with the projection applied: the attributes keyed by an event attribute
+// which is not projected are dropped, the hashed ones are hashed, the others
+// are kept, e.g. the attributes specific to a metric.
+func (p *Projection) Filter(attrs []Attribute) []Attribute {
+	if p == nil {
+		return attrs
+	}
+	filtered := make([]Attribute, 0, len(attrs))
+	for _, a := range attrs {
+		name, ok := strings.CutPrefix(a.Key, attrPrefix)
+		if !ok {
+			filtered = append(filtered, a)
+			continue
+		}
+		if _, spec := specAttributes[name]; spec && !p.projects(name) {
+			continue
+		}
+		filtered = append(filtered, Attribute{Key: a.Key, Value: p.project(name, a.Value)})
+	}
+	return filtered
+}
+
+func (p *Projection) projects(name string) bool {
+	for _, n := range p.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// project returns the projected value of the attribute name.
+func (p *Projection) project(name, value string) string {
+	if p == nil {
+		return value
+	}
+	buckets, ok := p.hashed[name]
+	if !ok {
+		return value
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(value))
+	if buckets == 0 {
+		return fmt.Sprintf("%016x", h.Sum64())
+	}
+	return strconv.FormatUint(h.Sum64()%buckets, 10)
+}
+
+// attributeValue returns the value of the attribute or extension name of e,
+// formatted as a string.
+func attributeValue(e event.Event, name string) (string, bool) {
+	var v interface{}
+	switch name {
+	case "specversion":
+		v = e.SpecVersion()
+	case "id":
+		v = e.ID()
+	case "type":
+		v = e.Type()
+	case "source":
+		v = e.Source()
+	case "subject":
+		v = e.Subject()
+	case "datacontenttype":
+		v = e.DataContentType()
+	case "dataschema":
+		v = e.DataSchema()
+	case "time":
+		if t := e.Time(); !t.IsZero() {
+			v = t
+		}
+	default:
+		v = e.Extensions()[name]
+	}
+	if v == nil || v == "" {
+		return "", false
+	}
+	s, err := types.Format(v)
+	return s, err == nil
+}
+
+// projectedMetrics filters the attributes of the measurements of Metrics.
+type projectedMetrics struct {
+	Metrics
+	projection *Projection
+}
+
+// ProjectMetrics returns a Metrics recording the measurements of m with their
+// attributes filtered by p, see Projection.Filter, e.g. to drop the source of
+// the events from the metrics of the middlewares of the client.
+func ProjectMetrics(m Metrics, p *Projection) Metrics {
+	return projectedMetrics{Metrics: m, projection: p}
+}
+
+func (m projectedMetrics) AddCounter(name string, delta int64, attrs ...Attribute) {
+	m.Metrics.AddCounter(name, delta, m.projection.Filter(attrs)...)
+}
+
+func (m projectedMetrics) SetGauge(name string, value int64, attrs ...Attribute) {
+	m.Metrics.SetGauge(name, value, m.projection.Filter(attrs)...)
+}
+
+func (m projectedMetrics) RecordDuration(name string, d time.Duration, attrs ...Attribute) {
+	m.Metrics.RecordDuration(name, d, m.projection.Filter(attrs)...)
+}
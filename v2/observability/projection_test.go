@@ -0,0 +1,102 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package observability
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func projectionEvent(id string) event.Event {
+	e := event.New()
+	e.SetID(id)
+	e.SetSource("/source")
+	e.SetType("order.created")
+	e.SetExtension("tenant", "acme")
+	return e
+}
+
+func TestProjectionAttributes(t *testing.T) {
+	e := projectionEvent("1")
+	require.Equal(t, []Attribute{
+		{Key: SpecversionAttr, Value: "1.0"},
+		{Key: TypeAttr, Value: "order.created"},
+		{Key: SourceAttr, Value: "/source"},
+	}, DefaultProjection.Attributes(e))
+
+	p := NewProjection([]string{"Type", "subject", "tenant"})
+	require.Equal(t, []Attribute{
+		{Key: TypeAttr, Value: "order.created"},
+		{Key: "cloudevents.tenant", Value: "acme"},
+	}, p.Attributes(e))
+
+	var all *Projection
+	require.Equal(t, []Attribute{
+		{Key: IdAttr, Value: "1"},
+		{Key: SourceAttr, Value: "/source"},
+		{Key: SpecversionAttr, Value: "1.0"},
+		{Key: "cloudevents.tenant", Value: "acme"},
+		{Key: TypeAttr, Value: "order.created"},
+	}, all.Attributes(e))
+}
+
+func TestProjectionHashing(t *testing.T) {
+	p := NewProjection([]string{"id", "tenant"}, WithHashedAttribute("id", 4), WithHashedAttribute("tenant", 0))
+	ids := map[string]struct{}{}
+	for i := 0; i < 100; i++ {
+		attrs := p.Attributes(projectionEvent(strconv.Itoa(i)))
+		require.Len(t, attrs, 2)
+		ids[attrs[0].Value] = struct{}{}
+		require.NotEqual(t, "acme", attrs[1].Value)
+		require.Len(t, attrs[1].Value, 16)
+	}
+	require.Len(t, ids, 4)
+
+	// The hashes are stable.
+	require.Equal(t, p.Attributes(projectionEvent("1")), p.Attributes(projectionEvent("1")))
+}
+
+func TestProjectionFilter(t *testing.T) {
+	p := NewProjection([]string{"type", "tenant"}, WithHashedAttribute("tenant", 8))
+	filtered := p.Filter([]Attribute{
+		{Key: TypeAttr, Value: "order.created"},
+		{Key: SourceAttr, Value: "/source"},
+		{Key: "cloudevents.tenant", Value: "acme"},
+		{Key: "cloudevents.concurrency.class", Value: "slow"},
+		{Key: "reason", Value: "timeout"},
+	})
+	require.Len(t, filtered, 4)
+	require.Equal(t, Attribute{Key: TypeAttr, Value: "order.created"}, filtered[0])
+	require.Equal(t, "cloudevents.tenant", filtered[1].Key)
+	require.NotEqual(t, "acme", filtered[1].Value)
+	require.Equal(t, Attribute{Key: "cloudevents.concurrency.class", Value: "slow"}, filtered[2])
+	require.Equal(t, Attribute{Key: "reason", Value: "timeout"}, filtered[3])
+
+	var all *Projection
+	require.Len(t, all.Filter(filtered), 4)
+}
+
+// recordedMetrics records the attributes of the last measurement.
+type recordedMetrics struct {
+	NoopMetrics
+	attrs []Attribute
+}
+
+func (m *recordedMetrics) RecordDuration(_ string, _ time.Duration, attrs ...Attribute) {
+	m.attrs = attrs
+}
+
+func TestProjectMetrics(t *testing.T) {
+	m := &recordedMetrics{}
+	ProjectMetrics(m, DefaultProjection).RecordDuration("latency", time.Second,
+		Attribute{Key: IdAttr, Value: "1"}, Attribute{Key: TypeAttr, Value: "order.created"})
+	require.Equal(t, []Attribute{{Key: TypeAttr, Value: "order.created"}}, m.attrs)
+}
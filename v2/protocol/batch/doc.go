@@ -0,0 +1,21 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package batch implements a protocol.Sender gathering the events sent
+concurrently into batches, delivered with a single call to a
+protocol.BatchSender, e.g. SQS SendMessageBatch or EventBridge PutEvents.
+
+A batch is sent once it holds the configured maximum number of events, or
+once its first event has waited for the configured delay. Send blocks until
+the batch of its event has been delivered, and returns the result of that
+event.
+
+The batch APIs partially fail: the target reports the outcome of each event
+with a protocol.BatchResult, and only the events whose delivery can be
+retried are sent again, in a smaller batch, following the retry parameters
+of the Sender.
+*/
+package batch
@@ -0,0 +1,60 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"fmt"
+	"time"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+const (
+	// DefaultMaxSize is the default maximum number of events of a batch.
+	DefaultMaxSize = 10
+	// DefaultMaxDelay is the default time the first event of a batch waits
+	// for the batch to fill up.
+	DefaultMaxDelay = 10 * time.Millisecond
+)
+
+// Option is the function signature required to be considered a batch.Option.
+type Option func(*Sender) error
+
+// WithMaxSize sets the maximum number of events of a batch, as limited by
+// the batch API of the target, DefaultMaxSize by default.
+func WithMaxSize(size int) Option {
+	return func(s *Sender) error {
+		if size <= 0 {
+			return fmt.Errorf("batch max size must be positive, got %d", size)
+		}
+		s.maxSize = size
+		return nil
+	}
+}
+
+// WithMaxDelay sets the time the first event of a batch waits for the batch
+// to fill up, DefaultMaxDelay by default.
+func WithMaxDelay(delay time.Duration) Option {
+	return func(s *Sender) error {
+		if delay < 0 {
+			return fmt.Errorf("batch max delay can not be negative, got %v", delay)
+		}
+		s.maxDelay = delay
+		return nil
+	}
+}
+
+// WithRetry sets the retries of the events whose delivery failed with a
+// NACK. By default, they are not retried.
+func WithRetry(params cecontext.RetryParams) Option {
+	return func(s *Sender) error {
+		if params.MaxTries < 0 {
+			return fmt.Errorf("batch retry max tries can not be negative, got %d", params.MaxTries)
+		}
+		s.retry = params
+		return nil
+	}
+}
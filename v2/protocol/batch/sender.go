@@ -0,0 +1,252 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// ErrClosed is returned by Send once the Sender is closed.
+var ErrClosed = errors.New("batch sender closed")
+
+// Sender is a protocol.Sender gathering the events into batches delivered
+// to a protocol.BatchSender.
+type Sender struct {
+	target   protocol.BatchSender
+	maxSize  int
+	maxDelay time.Duration
+	retry    cecontext.RetryParams
+
+	pending   atomic.Int64
+	requests  chan *request
+	closing   chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+type request struct {
+	e      *event.Event
+	result chan protocol.Result
+}
+
+// New returns a Sender delivering the events in batches to target, and
+// starts gathering them. Close must be called to deliver the last batch.
+func New(target protocol.BatchSender, opts ...Option) (*Sender, error) {
+	if target == nil {
+		return nil, errors.New("batch target can not be nil")
+	}
+	s := &Sender{
+		target:   target,
+		maxSize:  DefaultMaxSize,
+		maxDelay: DefaultMaxDelay,
+		retry:    cecontext.DefaultRetryParams,
+		requests: make(chan *request),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	go s.run()
+	return s, nil
+}
+
+// Send adds the event to the current batch and blocks until the batch has
+// been delivered, returning the result of the event. If ctx is done first,
+// Send returns the error of ctx but the event may still be delivered.
+func (s *Sender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (err error) {
+	if ctx == nil {
+		return fmt.Errorf("nil Context")
+	} else if m == nil {
+		return fmt.Errorf("nil Message")
+	}
+	defer func() {
+		if err2 := m.Finish(err); err == nil {
+			err = err2
+		}
+	}()
+
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	req := &request{e: e, result: make(chan protocol.Result, 1)}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.closing:
+		return ErrClosed
+	case s.requests <- req:
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case result := <-req.result:
+		return result
+	}
+}
+
+// SendBatch delivers the messages right away as a single batch, or more if
+// they exceed the maximum size, retrying the failed ones. It implements
+// protocol.BatchSender, returning a *protocol.BatchResult if some messages
+// failed.
+func (s *Sender) SendBatch(ctx context.Context, msgs []binding.Message, transformers ...binding.Transformer) error {
+	results := make([]protocol.Result, len(msgs))
+	events := make([]*event.Event, 0, len(msgs))
+	indexes := make([]int, 0, len(msgs))
+	for i, m := range msgs {
+		e, err := binding.ToEvent(ctx, m, transformers...)
+		if err != nil {
+			results[i] = err
+			continue
+		}
+		events = append(events, e)
+		indexes = append(indexes, i)
+	}
+	for start := 0; start < len(events); start += s.maxSize {
+		end := start + s.maxSize
+		if end > len(events) {
+			end = len(events)
+		}
+		for i, result := range s.send(ctx, events[start:end]) {
+			results[indexes[start+i]] = result
+		}
+	}
+	for i, m := range msgs {
+		if err := m.Finish(results[i]); err != nil && protocol.IsACK(results[i]) {
+			results[i] = err
+		}
+	}
+	return protocol.NewBatchResult(results)
+}
+
+func (s *Sender) run() {
+	defer close(s.done)
+	ctx := context.Background()
+	var batch []*request
+	var timer *time.Timer
+	var expired <-chan time.Time
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, expired = nil, nil
+		}
+		if len(batch) == 0 {
+			return
+		}
+		events := make([]*event.Event, len(batch))
+		for i, req := range batch {
+			events[i] = req.e
+		}
+		for i, result := range s.send(ctx, events) {
+			batch[i].result <- result
+		}
+		batch = nil
+		s.pending.Store(0)
+	}
+	for {
+		select {
+		case req := <-s.requests:
+			batch = append(batch, req)
+			s.pending.Store(int64(len(batch)))
+			if len(batch) >= s.maxSize {
+				flush()
+			} else if timer == nil {
+				timer = time.NewTimer(s.maxDelay)
+				expired = timer.C
+			}
+		case <-expired:
+			timer, expired = nil, nil
+			flush()
+		case <-s.closing:
+			flush()
+			return
+		}
+	}
+}
+
+// send delivers the events to the target, sending again those whose result
+// is retriable until the retries are exhausted, and returns the result of
+// each event.
+func (s *Sender) send(ctx context.Context, events []*event.Event) []protocol.Result {
+	results := make([]protocol.Result, len(events))
+	pending := make([]int, len(events))
+	for i := range pending {
+		pending[i] = i
+	}
+	for tries := 1; ; tries++ {
+		msgs := make([]binding.Message, len(pending))
+		for i, index := range pending {
+			msgs[i] = binding.ToMessage(events[index])
+		}
+		err := s.target.SendBatch(ctx, msgs)
+		var batchResult *protocol.BatchResult
+		if errors.As(err, &batchResult) && len(batchResult.Results) != len(pending) {
+			err = fmt.Errorf("batch target returned %d results for %d messages", len(batchResult.Results), len(pending))
+			batchResult = nil
+		}
+
+		var retriable []int
+		for i, index := range pending {
+			result := protocol.Result(err)
+			if batchResult != nil {
+				result = batchResult.Results[i]
+			}
+			results[index] = result
+			if protocol.OutcomeOf(result) == protocol.OutcomeRetriable {
+				retriable = append(retriable, index)
+			}
+		}
+		if len(retriable) == 0 {
+			return results
+		}
+		if err := s.retry.Backoff(ctx, tries); err != nil {
+			cecontext.LoggerFrom(ctx).Debugw("batch backoff error, will not try again", zap.Error(err), zap.Int("events", len(retriable)))
+			return results
+		}
+		cecontext.LoggerFrom(ctx).Debugw("retrying failed events of the batch", zap.Int("events", len(retriable)), zap.Int("tries", tries))
+		pending = retriable
+	}
+}
+
+// Pending returns the number of events of the batch being gathered.
+func (s *Sender) Pending() int {
+	return int(s.pending.Load())
+}
+
+// Close delivers the last batch and closes the target if it is a
+// protocol.Closer. The events sent after Close fail with ErrClosed.
+func (s *Sender) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.closing) })
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+	}
+	if c, ok := s.target.(protocol.Closer); ok {
+		return c.Close(ctx)
+	}
+	return nil
+}
+
+var (
+	_ protocol.SendCloser  = (*Sender)(nil)
+	_ protocol.BatchSender = (*Sender)(nil)
+)
@@ -0,0 +1,156 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+var errInvalid = errors.New("invalid event")
+
+// batchTarget records the ids of the events of each batch, and fails the
+// events with the results set for their id, one per attempt.
+type batchTarget struct {
+	mu      sync.Mutex
+	batches [][]string
+	results map[string][]protocol.Result
+	closed  bool
+}
+
+func (s *batchTarget) SendBatch(ctx context.Context, msgs []binding.Message, transformers ...binding.Transformer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	results := make([]protocol.Result, len(msgs))
+	for i, m := range msgs {
+		e, err := binding.ToEvent(ctx, m)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, e.ID())
+		if r := s.results[e.ID()]; len(r) > 0 {
+			results[i], s.results[e.ID()] = r[0], r[1:]
+		}
+	}
+	s.batches = append(s.batches, ids)
+	return protocol.NewBatchResult(results)
+}
+
+func (s *batchTarget) Close(ctx context.Context) error {
+	s.closed = true
+	return nil
+}
+
+func newMessage(id string) binding.Message {
+	e := event.New()
+	e.SetID(id)
+	e.SetSource("/orders")
+	e.SetType("order.created")
+	return binding.ToMessage(&e)
+}
+
+func TestSendGathersBatches(t *testing.T) {
+	target := &batchTarget{}
+	s, err := New(target, WithMaxSize(3), WithMaxDelay(time.Hour))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			require.NoError(t, s.Send(context.Background(), newMessage(id)))
+		}(strconv.Itoa(i))
+	}
+	wg.Wait()
+	require.Len(t, target.batches, 1)
+	ids := target.batches[0]
+	sort.Strings(ids)
+	require.Equal(t, []string{"0", "1", "2"}, ids)
+
+	// The last batch is delivered on Close.
+	done := make(chan error)
+	go func() { done <- s.Send(context.Background(), newMessage("3")) }()
+	require.Eventually(t, func() bool { return s.Pending() == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, s.Close(context.Background()))
+	require.NoError(t, <-done)
+	require.True(t, target.closed)
+	require.ErrorIs(t, s.Send(context.Background(), newMessage("4")), ErrClosed)
+}
+
+func TestSendMaxDelay(t *testing.T) {
+	target := &batchTarget{results: map[string][]protocol.Result{"1": {errInvalid}}}
+	s, err := New(target, WithMaxDelay(time.Millisecond))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	require.NoError(t, s.Send(context.Background(), newMessage("0")))
+	require.ErrorIs(t, s.Send(context.Background(), newMessage("1")), errInvalid)
+	require.Equal(t, [][]string{{"0"}, {"1"}}, target.batches)
+}
+
+func TestSendBatchRetriesFailedEvents(t *testing.T) {
+	target := &batchTarget{results: map[string][]protocol.Result{
+		"1": {protocol.ResultNACK, protocol.ResultNACK},
+		"2": {errInvalid},
+		"3": {protocol.ResultNACK, protocol.ResultNACK, protocol.ResultNACK},
+	}}
+	s, err := New(target, WithRetry(cecontext.RetryParams{
+		Strategy: cecontext.BackoffStrategyConstant,
+		MaxTries: 2,
+		Period:   time.Millisecond,
+	}))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	msgs := []binding.Message{newMessage("0"), newMessage("1"), newMessage("2"), newMessage("3")}
+	result := s.SendBatch(context.Background(), msgs)
+
+	// Only the retriable events are sent again.
+	require.Equal(t, [][]string{{"0", "1", "2", "3"}, {"1", "3"}, {"1", "3"}}, target.batches)
+	var batchResult *protocol.BatchResult
+	require.True(t, errors.As(result, &batchResult))
+	require.Equal(t, []int{0, 1}, batchResult.Indexes(protocol.OutcomeSucceeded))
+	require.Equal(t, []int{2}, batchResult.Indexes(protocol.OutcomePermanent))
+	require.Equal(t, []int{3}, batchResult.Indexes(protocol.OutcomeRetriable))
+}
+
+func TestSendBatchSplitsBatches(t *testing.T) {
+	target := &batchTarget{}
+	s, err := New(target, WithMaxSize(2))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	msgs := []binding.Message{newMessage("0"), newMessage("1"), newMessage("2")}
+	require.NoError(t, s.SendBatch(context.Background(), msgs))
+	require.Equal(t, [][]string{{"0", "1"}, {"2"}}, target.batches)
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New(nil)
+	require.Error(t, err)
+	for _, opt := range []Option{
+		WithMaxSize(0),
+		WithMaxDelay(-time.Second),
+		WithRetry(cecontext.RetryParams{MaxTries: -1}),
+	} {
+		_, err := New(&batchTarget{}, opt)
+		require.Error(t, err)
+	}
+}
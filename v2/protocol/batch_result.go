@@ -0,0 +1,123 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import "fmt"
+
+// Outcome classifies the Result of the delivery of a message.
+type Outcome int
+
+const (
+	// OutcomeSucceeded is the outcome of the acknowledged messages.
+	OutcomeSucceeded Outcome = iota
+	// OutcomeRetriable is the outcome of the messages not acknowledged by
+	// the recipient, which may accept them later.
+	OutcomeRetriable
+	// OutcomePermanent is the outcome of the messages which can not be
+	// delivered, e.g. invalid or too large ones.
+	OutcomePermanent
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSucceeded:
+		return "succeeded"
+	case OutcomeRetriable:
+		return "retriable"
+	case OutcomePermanent:
+		return "permanent"
+	}
+	return fmt.Sprintf("Outcome(%d)", int(o))
+}
+
+// OutcomeOf returns the outcome of the delivery with the given Result: ACK
+// succeeded, NACK is retriable and the undelivered results are permanent.
+func OutcomeOf(result Result) Outcome {
+	switch {
+	case IsACK(result):
+		return OutcomeSucceeded
+	case IsNACK(result):
+		return OutcomeRetriable
+	default:
+		return OutcomePermanent
+	}
+}
+
+// BatchResult is the Result of a batch send some messages of which were not
+// acknowledged, holding the Result of each message, see BatchSender.
+type BatchResult struct {
+	// Results are the results of the messages, in the order of the batch.
+	// A nil Result is an ACK.
+	Results []Result
+}
+
+// NewBatchResult returns nil if all the results are ACKs, and a
+// *BatchResult holding them otherwise.
+func NewBatchResult(results []Result) Result {
+	for _, r := range results {
+		if !IsACK(r) {
+			return &BatchResult{Results: results}
+		}
+	}
+	return nil
+}
+
+// make sure BatchResult implements error.
+var _ error = (*BatchResult)(nil)
+
+// Outcome returns the outcome of the i-th message of the batch.
+func (r *BatchResult) Outcome(i int) Outcome {
+	return OutcomeOf(r.Results[i])
+}
+
+// Indexes returns the positions in the batch of the messages with the given
+// outcome, e.g. to send again the retriable ones.
+func (r *BatchResult) Indexes(outcome Outcome) []int {
+	var indexes []int
+	for i := range r.Results {
+		if r.Outcome(i) == outcome {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// Is reports whether the batch is acknowledged, if target is an ACK, or has
+// retriable messages, if target is a NACK. Other targets match the result of
+// any message.
+func (r *BatchResult) Is(target error) bool {
+	if receipt, ok := target.(*Receipt); ok {
+		if receipt.ACK {
+			return len(r.Indexes(OutcomeSucceeded)) == len(r.Results)
+		}
+		return len(r.Indexes(OutcomeRetriable)) > 0
+	}
+	for _, result := range r.Results {
+		if result != nil && ResultIs(result, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Error returns the number of messages failed and the first of their
+// results.
+func (r *BatchResult) Error() string {
+	failed := 0
+	var first Result
+	for _, result := range r.Results {
+		if !IsACK(result) {
+			if first == nil {
+				first = result
+			}
+			failed++
+		}
+	}
+	if first == nil {
+		return fmt.Sprintf("%d messages sent", len(r.Results))
+	}
+	return fmt.Sprintf("%d of %d messages failed, first: %v", failed, len(r.Results), first)
+}
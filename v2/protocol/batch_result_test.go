@@ -0,0 +1,48 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBatchResult(t *testing.T) {
+	require.Nil(t, NewBatchResult([]Result{nil, ResultACK}))
+
+	invalid := errors.New("invalid event")
+	result := NewBatchResult([]Result{
+		nil,
+		NewReceipt(false, "throttled: %w", ErrRateLimited),
+		invalid,
+	})
+	var batchResult *BatchResult
+	require.True(t, errors.As(result, &batchResult))
+	require.Equal(t, OutcomeSucceeded, batchResult.Outcome(0))
+	require.Equal(t, []int{1}, batchResult.Indexes(OutcomeRetriable))
+	require.Equal(t, []int{2}, batchResult.Indexes(OutcomePermanent))
+	require.EqualError(t, result, "2 of 3 messages failed, first: throttled: rate limit exceeded")
+
+	require.False(t, IsACK(result))
+	require.True(t, IsNACK(result))
+	require.True(t, ResultIs(result, ErrRateLimited))
+	require.True(t, ResultIs(result, invalid))
+
+	// A batch whose failures are all permanent is undelivered.
+	result = NewBatchResult([]Result{nil, invalid})
+	require.False(t, IsNACK(result))
+	require.True(t, IsUndelivered(result))
+}
+
+func TestOutcomeOf(t *testing.T) {
+	require.Equal(t, OutcomeSucceeded, OutcomeOf(nil))
+	require.Equal(t, OutcomeSucceeded, OutcomeOf(ResultACK))
+	require.Equal(t, OutcomeRetriable, OutcomeOf(ResultNACK))
+	require.Equal(t, OutcomePermanent, OutcomeOf(errors.New("invalid")))
+	require.Equal(t, "retriable", OutcomeRetriable.String())
+}
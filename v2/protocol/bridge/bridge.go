@@ -0,0 +1,196 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/buffering"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/binding/transformer"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// DeadLetterReasonExtension is the extension set on dead-lettered messages,
+// holding the error of the last delivery attempt.
+const DeadLetterReasonExtension = "deadletterreason"
+
+// Bridge forwards the messages received from a protocol.Receiver to a
+// protocol.Sender. The result of the forwarding is propagated to the source
+// message through Finish, so the source protocol acknowledges a message only
+// once it has been accepted by the target (or by the dead-letter Sender).
+type Bridge struct {
+	source protocol.Receiver
+	target protocol.Sender
+
+	concurrency       int
+	retryParams       *cecontext.RetryParams
+	deadLetter        protocol.Sender
+	transformers      []binding.Transformer
+	eventTransformers []EventTransformer
+}
+
+// New creates a Bridge forwarding messages from source to target.
+func New(source protocol.Receiver, target protocol.Sender, opts ...Option) (*Bridge, error) {
+	if source == nil {
+		return nil, errors.New("bridge source can not be nil")
+	}
+	if target == nil {
+		return nil, errors.New("bridge target can not be nil")
+	}
+	b := &Bridge{
+		source:      source,
+		target:      target,
+		concurrency: 1,
+	}
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Run forwards messages until ctx is done or the source is closed.
+// If the source is a protocol.Opener, Run opens it as well.
+// This call is blocking.
+func (b *Bridge) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < b.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				m, err := b.source.Receive(ctx)
+				if err == io.EOF { // Normal close
+					return
+				}
+				if err != nil {
+					cecontext.LoggerFrom(ctx).Warnw("Error while receiving a message", zap.Error(err))
+					continue
+				}
+				if err := b.Forward(ctx, m); err != nil {
+					cecontext.LoggerFrom(ctx).Warnw("Error while forwarding a message", zap.Error(err))
+				}
+			}
+		}()
+	}
+
+	var err error
+	if opener, ok := b.source.(protocol.Opener); ok {
+		if err = opener.OpenInbound(ctx); err != nil {
+			err = fmt.Errorf("error while opening the inbound connection: %w", err)
+			cancel()
+		}
+	}
+
+	wg.Wait()
+	return err
+}
+
+// Forward sends a single message to the target, applying the configured
+// transformations, retries and dead-lettering, then finishes m with the
+// outcome. The returned error is the one used to finish m.
+func (b *Bridge) Forward(ctx context.Context, m binding.Message) (err error) {
+	defer func() {
+		if finishErr := m.Finish(err); finishErr != nil {
+			cecontext.LoggerFrom(ctx).Warnw("failed calling message.Finish", zap.Error(finishErr))
+		}
+	}()
+
+	var out binding.Message
+	switch {
+	case len(b.eventTransformers) > 0:
+		e, err := binding.ToEvent(ctx, m)
+		if err != nil {
+			return err
+		}
+		for _, fn := range b.eventTransformers {
+			if err := fn(ctx, e); err != nil {
+				return b.deadLetterOrFail(ctx, (*binding.EventMessage)(e), fmt.Errorf("event transformer failed: %w", err))
+			}
+		}
+		out = (*binding.EventMessage)(e)
+	case b.retryParams != nil || b.deadLetter != nil:
+		// The message may be read more than once, buffer it.
+		copied, err := buffering.CopyMessage(ctx, m)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = copied.Finish(nil) }()
+		out = copied
+	default:
+		// Fast path: the message is read exactly once.
+		return b.target.Send(ctx, nonFinishing{m}, b.transformers...)
+	}
+
+	result := b.send(ctx, out)
+	if protocol.IsACK(result) {
+		return result
+	}
+	return b.deadLetterOrFail(ctx, out, result)
+}
+
+func (b *Bridge) send(ctx context.Context, m binding.Message) error {
+	if b.retryParams != nil {
+		b.retryParams.Budget.Deposit()
+	}
+	for retry := 0; ; retry++ {
+		result := b.target.Send(ctx, nonFinishing{m}, b.transformers...)
+		if protocol.IsACK(result) || protocol.IsPermanent(result) || b.retryParams == nil {
+			return result
+		}
+		// total tries = retry + 1
+		if err := b.retryParams.Backoff(ctx, retry+1); err != nil {
+			cecontext.LoggerFrom(ctx).Debugw("backoff error, will not try again", zap.Error(err))
+			return result
+		}
+	}
+}
+
+func (b *Bridge) deadLetterOrFail(ctx context.Context, m binding.Message, cause error) error {
+	if b.deadLetter == nil {
+		return cause
+	}
+	reason := transformer.AddExtension(DeadLetterReasonExtension, cause.Error())
+	if err := b.deadLetter.Send(ctx, nonFinishing{m}, reason); !protocol.IsACK(err) {
+		return fmt.Errorf("failed to dead-letter message: %w (delivery error: %v)", err, cause)
+	}
+	return nil
+}
+
+// nonFinishing prevents the Sender from finishing a message the bridge
+// still needs, either to retry it or to finish it with the final outcome.
+type nonFinishing struct {
+	binding.Message
+}
+
+func (nonFinishing) Finish(error) error { return nil }
+
+func (m nonFinishing) GetAttribute(k spec.Kind) (spec.Attribute, interface{}) {
+	return m.Message.(binding.MessageMetadataReader).GetAttribute(k)
+}
+
+func (m nonFinishing) GetExtension(name string) interface{} {
+	return m.Message.(binding.MessageMetadataReader).GetExtension(name)
+}
+
+func (m nonFinishing) GetWrappedMessage() binding.Message {
+	return m.Message
+}
+
+var _ binding.MessageWrapper = nonFinishing{}
@@ -0,0 +1,220 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package bridge
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// mockSender fails the first failures sends with a NACK and records the
+// events of the following ones.
+type mockSender struct {
+	mu       sync.Mutex
+	failures int
+	result   error
+	sent     []event.Event
+}
+
+func (s *mockSender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return s.result
+	}
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	s.sent = append(s.sent, *e)
+	return nil
+}
+
+func (s *mockSender) events() []event.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sent
+}
+
+// finishRecorder wraps a message to record its Finish error.
+func finishRecorder(m binding.Message) (binding.Message, *error) {
+	var finishErr error
+	return binding.WithFinish(m, func(err error) { finishErr = err }), &finishErr
+}
+
+func TestBridgeForward(t *testing.T) {
+	nack := protocol.NewReceipt(false, "target unavailable")
+	retries := WithRetry(&cecontext.RetryParams{Strategy: cecontext.BackoffStrategyConstant, Period: time.Millisecond, MaxTries: 3})
+	testCases := map[string]struct {
+		failures       int
+		result         error
+		opts           []Option
+		withDeadLetter bool
+		wantSent       int
+		wantDeadLetter int
+		wantErr        bool
+	}{
+		"no retries, ACK": {
+			wantSent: 1,
+		},
+		"no retries, NACK": {
+			failures: 1,
+			wantErr:  true,
+		},
+		"retries, ACK": {
+			failures: 2,
+			opts:     []Option{WithRetry(&cecontext.RetryParams{Strategy: cecontext.BackoffStrategyConstant, Period: time.Millisecond, MaxTries: 3})},
+			wantSent: 1,
+		},
+		"retries exhausted, NACK": {
+			failures: 5,
+			opts:     []Option{WithRetry(&cecontext.RetryParams{Strategy: cecontext.BackoffStrategyConstant, Period: time.Millisecond, MaxTries: 3})},
+			wantErr:  true,
+		},
+		"retries exhausted, dead-lettered": {
+			failures:       5,
+			opts:           []Option{WithRetry(&cecontext.RetryParams{Strategy: cecontext.BackoffStrategyConstant, Period: time.Millisecond, MaxTries: 3})},
+			withDeadLetter: true,
+			wantDeadLetter: 1,
+		},
+		"transient error, retried": {
+			failures: 2,
+			result:   errors.New("target unavailable"),
+			opts:     []Option{retries},
+			wantSent: 1,
+		},
+		"transient error, dead-lettered": {
+			failures:       5,
+			result:         protocol.NewBrokerResult(errors.New("target unavailable"), true, 0),
+			opts:           []Option{retries},
+			withDeadLetter: true,
+			wantDeadLetter: 1,
+		},
+		"permanent error, not retried": {
+			failures:       2,
+			result:         protocol.NewBrokerResult(errors.New("target unavailable"), false, 0),
+			opts:           []Option{retries},
+			withDeadLetter: true,
+			wantDeadLetter: 1,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			result := tc.result
+			if result == nil {
+				result = nack
+			}
+			target := &mockSender{failures: tc.failures, result: result}
+			deadLetter := &mockSender{}
+			opts := tc.opts
+			if tc.withDeadLetter {
+				opts = append(opts, WithDeadLetter(deadLetter))
+			}
+			b, err := New(gochan.New(), target, opts...)
+			require.NoError(t, err)
+
+			m, finishErr := finishRecorder(test.FullMessage())
+			err = b.Forward(context.Background(), m)
+			if tc.wantErr {
+				require.Error(t, err)
+				require.ErrorIs(t, *finishErr, result)
+			} else {
+				require.NoError(t, err)
+				require.NoError(t, *finishErr)
+			}
+			require.Len(t, target.events(), tc.wantSent)
+			require.Len(t, deadLetter.events(), tc.wantDeadLetter)
+			if tc.wantDeadLetter > 0 {
+				test.AssertEvent(t, deadLetter.events()[0], test.HasExtension(DeadLetterReasonExtension, "target unavailable"))
+			}
+		})
+	}
+}
+
+func TestBridgeEventTransformer(t *testing.T) {
+	target := &mockSender{}
+	b, err := New(gochan.New(), target, WithEventTransformer(func(ctx context.Context, e *event.Event) error {
+		e.SetType("bridged." + e.Type())
+		return nil
+	}))
+	require.NoError(t, err)
+
+	in := test.FullEvent()
+	wantType := "bridged." + in.Type()
+	require.NoError(t, b.Forward(context.Background(), binding.ToMessage(&in)))
+	require.Len(t, target.events(), 1)
+	test.AssertEvent(t, target.events()[0], test.HasType(wantType))
+}
+
+func TestBridgeEventTransformerError(t *testing.T) {
+	target := &mockSender{}
+	b, err := New(gochan.New(), target, WithEventTransformer(func(ctx context.Context, e *event.Event) error {
+		return errors.New("boom")
+	}))
+	require.NoError(t, err)
+
+	in := test.FullEvent()
+	require.Error(t, b.Forward(context.Background(), binding.ToMessage(&in)))
+	require.Empty(t, target.events())
+}
+
+func TestBridgeRun(t *testing.T) {
+	source := gochan.New()
+	target := &mockSender{}
+	b, err := New(source, target, WithConcurrency(4))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- b.Run(ctx)
+	}()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, source.Send(ctx, test.FullMessage()))
+	}
+	require.Eventually(t, func() bool { return len(target.events()) == 10 }, time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestNewValidation(t *testing.T) {
+	_, err := New(nil, &mockSender{})
+	require.Error(t, err)
+	_, err = New(gochan.New(), nil)
+	require.Error(t, err)
+	_, err = New(gochan.New(), &mockSender{}, WithConcurrency(0))
+	require.Error(t, err)
+}
+
+func TestBridgeDecompression(t *testing.T) {
+	target := &mockSender{}
+	b, err := New(gochan.New(), target, WithDecompression())
+	require.NoError(t, err)
+
+	in := test.FullEvent()
+	want := in.Data()
+	require.NoError(t, compression.CompressEvent(&in, compression.Gzip))
+	require.NoError(t, b.Forward(context.Background(), binding.ToMessage(&in)))
+	require.Len(t, target.events(), 1)
+	require.Equal(t, want, target.events()[0].Data())
+	require.NotContains(t, target.events()[0].Extensions(), compression.Extension)
+}
@@ -0,0 +1,14 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package bridge implements a component forwarding messages received from one
+protocol to the Sender of another one (e.g. Kafka to HTTP, HTTP to NATS),
+with configurable concurrency, retries, dead-lettering and transformations.
+
+Messages are forwarded without being converted to events whenever possible,
+so the bridge preserves the message encoding across protocols.
+*/
+package bridge
@@ -0,0 +1,97 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Option is the function signature required to be considered a bridge.Option.
+type Option func(*Bridge) error
+
+// EventTransformer modifies the event before it is forwarded to the target.
+// Returning an error causes the message to be handled as a failed delivery.
+type EventTransformer func(ctx context.Context, e *event.Event) error
+
+// WithConcurrency sets the number of messages forwarded concurrently.
+// Default value is 1, which preserves the order of the source.
+func WithConcurrency(concurrency int) Option {
+	return func(b *Bridge) error {
+		if concurrency < 1 {
+			return fmt.Errorf("bridge concurrency must be positive, got %d", concurrency)
+		}
+		b.concurrency = concurrency
+		return nil
+	}
+}
+
+// WithRetry configures the retries performed when the target fails to
+// accept a message. The failures are retried, including the plain errors of
+// an unreachable broker, except the permanent ones (e.g. an HTTP 4xx response
+// or an invalid message) which fail immediately, see protocol.IsPermanent.
+func WithRetry(params *cecontext.RetryParams) Option {
+	return func(b *Bridge) error {
+		if params == nil {
+			return fmt.Errorf("bridge retry params can not be nil")
+		}
+		b.retryParams = params
+		return nil
+	}
+}
+
+// WithDeadLetter sets the Sender receiving the messages that could not be
+// delivered to the target. Dead-lettered messages carry the
+// DeadLetterReasonExtension extension with the last delivery error, and are
+// acknowledged to the source once the dead-letter Sender accepted them.
+func WithDeadLetter(sender protocol.Sender) Option {
+	return func(b *Bridge) error {
+		if sender == nil {
+			return fmt.Errorf("bridge dead letter sender can not be nil")
+		}
+		b.deadLetter = sender
+		return nil
+	}
+}
+
+// WithTransformers adds transformers applied when the message is written to
+// the target.
+func WithTransformers(transformers ...binding.Transformer) Option {
+	return func(b *Bridge) error {
+		b.transformers = append(b.transformers, transformers...)
+		return nil
+	}
+}
+
+// WithEventTransformer adds a transformer operating on the event. Setting an
+// EventTransformer makes the bridge convert every message to an event before
+// forwarding it.
+func WithEventTransformer(fn EventTransformer) Option {
+	return func(b *Bridge) error {
+		if fn == nil {
+			return fmt.Errorf("bridge event transformer can not be nil")
+		}
+		b.eventTransformers = append(b.eventTransformers, fn)
+		return nil
+	}
+}
+
+// WithDecompression makes the bridge decompress the data of the events with
+// the codec named by their compression extension, e.g. to forward the events
+// compressed by Kafka producers to HTTP consumers unaware of it, see the
+// compression package. As an EventTransformer, it makes the bridge convert
+// every message to an event.
+func WithDecompression() Option {
+	return WithEventTransformer(func(_ context.Context, e *event.Event) error {
+		return compression.DecompressEvent(e)
+	})
+}
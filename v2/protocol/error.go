@@ -5,7 +5,20 @@
 
 package protocol
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRateLimited is wrapped by the results NACKing the events rejected
+// because a rate limit is exceeded. The HTTP protocol responds to them with
+// 429 Too Many Requests.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// ErrForbidden is wrapped by the results NACKing the events denied by an
+// authorization policy. The HTTP protocol responds to them with 403
+// Forbidden.
+var ErrForbidden = errors.New("forbidden")
 
 // ErrTransportMessageConversion is an error produced when the transport
 // message can not be converted.
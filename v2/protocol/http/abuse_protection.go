@@ -8,7 +8,6 @@ package http
 import (
 	"context"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
-	"go.uber.org/zap"
 	"net/http"
 	"strconv"
 	"strings"
@@ -73,7 +72,7 @@ func (p *Protocol) OptionsHandler(rw http.ResponseWriter, req *http.Request) {
 			go func() {
 				reqAck, err := http.NewRequest(http.MethodPost, cb, nil)
 				if err != nil {
-					cecontext.LoggerFrom(req.Context()).Errorw("OPTIONS handler failed to create http request attempting to ack callback.", zap.Error(err), zap.String("callback", cb))
+					cecontext.LoggerFrom(req.Context()).Errorw("OPTIONS handler failed to create http request attempting to ack callback.", "error", err, "callback", cb)
 					return
 				}
 
@@ -84,7 +83,7 @@ func (p *Protocol) OptionsHandler(rw http.ResponseWriter, req *http.Request) {
 
 				_, err = http.DefaultClient.Do(reqAck)
 				if err != nil {
-					cecontext.LoggerFrom(req.Context()).Errorw("OPTIONS handler failed to ack callback.", zap.Error(err), zap.String("callback", cb))
+					cecontext.LoggerFrom(req.Context()).Errorw("OPTIONS handler failed to ack callback.", "error", err, "callback", cb)
 					return
 				}
 			}()
@@ -111,7 +110,7 @@ func (p *Protocol) ValidateOrigin(req *http.Request) (string, bool) {
 }
 
 func (p *Protocol) validateOrigin(ro string) (string, bool) {
-	cecontext.LoggerFrom(context.TODO()).Infow("Validating origin.", zap.String("origin", ro))
+	cecontext.LoggerFrom(context.TODO()).Infow("Validating origin.", "origin", ro)
 
 	for _, ao := range p.WebhookConfig.AllowedOrigins {
 		if ao == "*" {
@@ -0,0 +1,252 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+)
+
+const (
+	encodingGzip     = "gzip"
+	encodingIdentity = "identity"
+)
+
+// responseCompression configures the compression of the response events,
+// see WithResponseCompression.
+type responseCompression struct {
+	level int
+	// mediaTypes are the media types of the responses to compress, all of
+	// them if empty.
+	mediaTypes map[string]struct{}
+}
+
+// WithResponseCompression makes the receiver compress the response events
+// with gzip when the request advertises the support of gzip in its
+// Accept-Encoding header. If media types are given, only the responses with
+// one of them as content type are compressed: binary data, such as images, is
+// often already compressed. See WithRequestDecompression to decode the
+// compressed requests.
+func WithResponseCompression(level int, mediaTypes ...string) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http response compression option can not set nil protocol")
+		}
+		if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+			return fmt.Errorf("http response compression option was given an invalid level: %d", level)
+		}
+		c := &responseCompression{level: level, mediaTypes: make(map[string]struct{}, len(mediaTypes))}
+		for _, mt := range mediaTypes {
+			c.mediaTypes[mediaTypeOf(mt)] = struct{}{}
+		}
+		p.compression = c
+		return nil
+	}
+}
+
+// WithRequestDecompression makes the receiver decode the requests with a
+// Content-Encoding among codings, with the codecs of the compression package
+// registered under these names, e.g. compression.Gzip and
+// compression.Deflate. The requests with another coding are rejected with
+// 415 Unsupported Media Type, advertising codings in the Accept-Encoding
+// header. The size of a decoded body is limited by WithMaxRequestBytes, or
+// by compression.DefaultMaxDataSize by default.
+//
+// Without this option, the Content-Encoding of the requests is ignored.
+func WithRequestDecompression(codings ...string) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http request decompression option can not set nil protocol")
+		}
+		if len(codings) == 0 {
+			return fmt.Errorf("http request decompression option was given no coding")
+		}
+		for _, coding := range codings {
+			if compression.Lookup(coding) == nil {
+				return fmt.Errorf("http request decompression option was given an unknown coding %q", coding)
+			}
+		}
+		p.requestCodings = codings
+		return nil
+	}
+}
+
+// decodeContentEncoding replaces the body of req by its decoded content,
+// according to the Content-Encoding header. It returns whether the body is
+// encoded, and an error if a coding is not among codings.
+func decodeContentEncoding(req *http.Request, codings []string) (bool, error) {
+	var applied []string
+	for _, v := range req.Header.Values("Content-Encoding") {
+		for _, coding := range strings.Split(v, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "x-gzip" {
+				coding = encodingGzip
+			}
+			if coding != "" && coding != encodingIdentity {
+				applied = append(applied, coding)
+			}
+		}
+	}
+	if len(applied) == 0 {
+		return false, nil
+	}
+
+	body := req.Body
+	// The codings are listed in the order they were applied.
+	for i := len(applied) - 1; i >= 0; i-- {
+		var codec compression.Codec
+		for _, coding := range codings {
+			if coding == applied[i] {
+				codec = compression.Lookup(coding)
+				break
+			}
+		}
+		if codec == nil {
+			return true, fmt.Errorf("unsupported content encoding %q", applied[i])
+		}
+		body = &lazyDecoder{r: body, open: codec.NewReader}
+	}
+	req.Body = &decodedBody{Reader: body, closer: req.Body}
+	req.Header.Del("Content-Encoding")
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	return true, nil
+}
+
+// lazyDecoder opens the decoder of r on the first read, so that an invalid
+// body is reported as a read error.
+type lazyDecoder struct {
+	r       io.Reader
+	open    func(io.Reader) (io.ReadCloser, error)
+	decoder io.ReadCloser
+}
+
+func (d *lazyDecoder) Read(p []byte) (int, error) {
+	if d.decoder == nil {
+		decoder, err := d.open(d.r)
+		if err != nil {
+			return 0, err
+		}
+		d.decoder = decoder
+	}
+	return d.decoder.Read(p)
+}
+
+func (d *lazyDecoder) Close() error {
+	if d.decoder == nil {
+		return nil
+	}
+	return d.decoder.Close()
+}
+
+// decodedBody reads the decoded body and closes the original one.
+type decodedBody struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	return b.closer.Close()
+}
+
+// acceptsGzip returns whether the Accept-Encoding header values allow a gzip
+// response.
+func acceptsGzip(values []string) bool {
+	accepted := false
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			coding, q := parseQuality(item)
+			switch coding {
+			case encodingGzip, "x-gzip":
+				// An explicit coding takes precedence over the wildcard.
+				return q > 0
+			case "*":
+				accepted = q > 0
+			}
+		}
+	}
+	return accepted
+}
+
+// parseQuality parses an Accept-Encoding item, such as "gzip;q=0.5".
+func parseQuality(item string) (string, float64) {
+	parts := strings.Split(item, ";")
+	coding := strings.ToLower(strings.TrimSpace(parts[0]))
+	q := 1.0
+	for _, param := range parts[1:] {
+		param = strings.TrimSpace(param)
+		if v, ok := strings.CutPrefix(param, "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return coding, q
+}
+
+func mediaTypeOf(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// compressingResponseWriter compresses the response with gzip if its content
+// type is configured for compression. Close must be called once the response
+// is written.
+type compressingResponseWriter struct {
+	http.ResponseWriter
+	compression *responseCompression
+	gz          *gzip.Writer
+}
+
+// newCompressingResponseWriter returns rw wrapped to compress the response
+// if req accepts it, or rw if compression is not configured.
+func (p *Protocol) newCompressingResponseWriter(rw http.ResponseWriter, req *http.Request) http.ResponseWriter {
+	if p.compression == nil {
+		return rw
+	}
+	rw.Header().Add("Vary", "Accept-Encoding")
+	if !acceptsGzip(req.Header.Values("Accept-Encoding")) {
+		return rw
+	}
+	return &compressingResponseWriter{ResponseWriter: rw, compression: p.compression}
+}
+
+func (w *compressingResponseWriter) WriteHeader(status int) {
+	h := w.Header()
+	_, compress := w.compression.mediaTypes[mediaTypeOf(h.Get(ContentType))]
+	compress = compress || len(w.compression.mediaTypes) == 0
+	if compress && status != http.StatusNoContent && status != http.StatusNotModified && h.Get("Content-Encoding") == "" {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", encodingGzip)
+		// The level has been validated.
+		w.gz, _ = gzip.NewWriterLevel(w.ResponseWriter, w.compression.level)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *compressingResponseWriter) Write(b []byte) (int, error) {
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Close flushes the compressed response.
+func (w *compressingResponseWriter) Close() error {
+	if w.gz == nil {
+		return nil
+	}
+	return w.gz.Close()
+}
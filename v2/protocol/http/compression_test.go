@@ -0,0 +1,237 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	testCases := map[string]struct {
+		values []string
+		want   bool
+	}{
+		"none":           {want: false},
+		"gzip":           {values: []string{"gzip"}, want: true},
+		"list":           {values: []string{"br, gzip;q=0.8"}, want: true},
+		"several values": {values: []string{"br", "gzip"}, want: true},
+		"refused":        {values: []string{"gzip;q=0"}, want: false},
+		"wildcard":       {values: []string{"*"}, want: true},
+		"wildcard but":   {values: []string{"*, gzip;q=0"}, want: false},
+		"other":          {values: []string{"br"}, want: false},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			require.Equal(t, tc.want, acceptsGzip(tc.values))
+		})
+	}
+}
+
+func compressedEventRequest(t *testing.T, encoding string, e event.Event) *http.Request {
+	b, err := e.MarshalJSON()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	}
+	_, err = w.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", &buf)
+	req.Header.Set(ContentType, event.ApplicationCloudEventsJSON)
+	req.Header.Set("Content-Encoding", encoding)
+	req.Header.Set("Accept-Encoding", "gzip")
+	return req
+}
+
+func TestServeHTTPContentEncoding(t *testing.T) {
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	require.NoError(t, e.SetData(event.ApplicationJSON, map[string]string{"hello": "world"}))
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			p, err := New(WithResponseCompression(gzip.DefaultCompression, event.ApplicationJSON), WithRequestDecompression(compression.Gzip, compression.Deflate))
+			require.NoError(t, err)
+			rec := httptest.NewRecorder()
+			go p.ServeHTTP(rec, compressedEventRequest(t, encoding, e))
+
+			m, fn, err := p.Respond(context.Background())
+			require.NoError(t, err)
+			got, err := binding.ToEvent(context.Background(), m)
+			require.NoError(t, err)
+			require.Equal(t, e.Data(), got.Data())
+			require.NoError(t, fn(context.Background(), binding.ToMessage(&e), nil))
+
+			res := rec.Result()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+			require.Equal(t, "Accept-Encoding", res.Header.Get("Vary"))
+			gz, err := gzip.NewReader(res.Body)
+			require.NoError(t, err)
+			body, err := io.ReadAll(gz)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"hello":"world"}`, string(body))
+		})
+	}
+}
+
+func TestServeHTTPResponseCompressionMediaTypes(t *testing.T) {
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	require.NoError(t, e.SetData("image/png", []byte{1, 2, 3}))
+
+	p, err := New(WithResponseCompression(gzip.BestSpeed, event.ApplicationJSON), WithRequestDecompression(compression.Gzip))
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	go p.ServeHTTP(rec, compressedEventRequest(t, "gzip", e))
+
+	_, fn, err := p.Respond(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, fn(context.Background(), binding.ToMessage(&e), nil))
+
+	res := rec.Result()
+	require.Empty(t, res.Header.Get("Content-Encoding"))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, body)
+}
+
+func TestServeHTTPUnsupportedContentEncoding(t *testing.T) {
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	for name, req := range map[string]*http.Request{
+		"unknown":        httptest.NewRequest(http.MethodPost, "http://unittest", bytes.NewReader([]byte("data"))),
+		"not configured": compressedEventRequest(t, "deflate", e),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if req.Header.Get("Content-Encoding") == "" {
+				req.Header.Set("Content-Encoding", "br")
+			}
+			p, err := New(WithRequestDecompression(compression.Gzip))
+			require.NoError(t, err)
+			rec := httptest.NewRecorder()
+			p.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			require.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+			require.Equal(t, "gzip", res.Header.Get("Accept-Encoding"))
+		})
+	}
+}
+
+func TestServeHTTPContentEncodingIgnored(t *testing.T) {
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	p, err := New()
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", bytes.NewReader([]byte("data")))
+	req.Header.Set(ContentType, "text/plain")
+	req.Header.Set("Content-Encoding", "br")
+	for k, v := range map[string]string{"ce-specversion": "1.0", "ce-id": "1", "ce-source": "/source", "ce-type": "type"} {
+		req.Header.Set(k, v)
+	}
+	go p.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Without WithRequestDecompression, the body is received as is.
+	m, fn, err := p.Respond(context.Background())
+	require.NoError(t, err)
+	got, err := binding.ToEvent(context.Background(), m)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), got.Data())
+	require.NoError(t, fn(context.Background(), nil, nil))
+}
+
+func TestServeHTTPDecodedSizeLimit(t *testing.T) {
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	require.NoError(t, e.SetData(event.TextPlain, strings.Repeat("a", 4096)))
+	p, err := New(WithRequestDecompression(compression.Gzip), WithMaxRequestBytes(1024))
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	go p.ServeHTTP(rec, compressedEventRequest(t, "gzip", e))
+
+	// The limit applies to the decoded body.
+	m, fn, err := p.Respond(context.Background())
+	require.NoError(t, err)
+	_, err = binding.ToEvent(context.Background(), m)
+	require.Error(t, err)
+	require.NoError(t, fn(context.Background(), nil, protocol.NewReceipt(false, "failed to convert Message to Event: %w", err)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestWithRequestDecompressionInvalid(t *testing.T) {
+	_, err := New(WithRequestDecompression())
+	require.EqualError(t, err, "http request decompression option was given no coding")
+	_, err = New(WithRequestDecompression(compression.Gzip, "br"))
+	require.EqualError(t, err, `http request decompression option was given an unknown coding "br"`)
+}
+
+func TestSendWithCompressedResponse(t *testing.T) {
+	p, err := New(WithResponseCompression(gzip.DefaultCompression))
+	require.NoError(t, err)
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	require.NoError(t, e.SetData(event.ApplicationJSON, map[string]string{"hello": "world"}))
+
+	go func() {
+		m, fn, err := p.Respond(context.Background())
+		if err != nil {
+			return
+		}
+		_ = m.Finish(nil)
+		_ = fn(context.Background(), binding.ToMessage(&e), nil)
+	}()
+
+	// The transport of the sender advertises and decodes gzip.
+	sender, err := New(WithTarget(server.URL))
+	require.NoError(t, err)
+	resp, result := sender.Request(context.Background(), binding.ToMessage(&e))
+	require.True(t, protocol.IsACK(result))
+	got, err := binding.ToEvent(context.Background(), resp)
+	require.NoError(t, err)
+	require.Equal(t, e.Data(), got.Data())
+}
+
+func TestWithResponseCompressionInvalidLevel(t *testing.T) {
+	_, err := New(WithResponseCompression(42))
+	require.EqualError(t, err, "http response compression option was given an invalid level: 42")
+}
@@ -5,5 +5,10 @@
 
 /*
 Package http implements an HTTP binding using net/http module
+
+Compiled to WebAssembly for the browsers (GOOS=js), the requests of the Sender
+are made with the Fetch API by the net/http transport, configured with
+WithFetchMode, WithFetchCredentials and WithFetchRedirect. The browsers can
+not listen for the requests of the Receiver.
 */
 package http
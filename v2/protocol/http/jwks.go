@@ -0,0 +1,193 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	nethttp "net/http"
+	"sync"
+	"time"
+)
+
+// jwk is a JSON Web Key, as defined by RFC 7517. Only the public key members
+// are decoded.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jsonWebKey is a parsed public key of a JWKS.
+type jsonWebKey struct {
+	key crypto.PublicKey
+	alg string
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	b64 := base64.RawURLEncoding
+	switch k.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := b64.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := b64.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return key, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// jwksCache fetches the JSON Web Key Set published at url and caches it for
+// ttl. An unknown key id refreshes the set, at most once per minRefresh, so
+// that the rotation of the keys of the issuer is picked up.
+type jwksCache struct {
+	url        string
+	client     *nethttp.Client
+	ttl        time.Duration
+	minRefresh time.Duration
+	now        func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]jsonWebKey
+	fetchedAt time.Time
+}
+
+var errUnknownKey = errors.New("unknown signing key")
+
+// key returns the key identified by kid, or the only key of the set if kid
+// is empty.
+func (c *jwksCache) key(ctx context.Context, kid string) (jsonWebKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.keys == nil || now.Sub(c.fetchedAt) >= c.ttl {
+		if err := c.refresh(ctx, now); err != nil {
+			return jsonWebKey{}, err
+		}
+	}
+	if k, ok := c.lookup(kid); ok {
+		return k, nil
+	}
+	if now.Sub(c.fetchedAt) < c.minRefresh {
+		return jsonWebKey{}, errUnknownKey
+	}
+	if err := c.refresh(ctx, now); err != nil {
+		return jsonWebKey{}, err
+	}
+	if k, ok := c.lookup(kid); ok {
+		return k, nil
+	}
+	return jsonWebKey{}, errUnknownKey
+}
+
+func (c *jwksCache) lookup(kid string) (jsonWebKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, k := range c.keys {
+			return k, true
+		}
+	}
+	k, ok := c.keys[kid]
+	return k, ok
+}
+
+// refresh fetches the key set. The keys which can not be parsed, or which
+// are not meant for signatures, are skipped.
+func (c *jwksCache) refresh(ctx context.Context, now time.Time) error {
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusOK {
+		return fmt.Errorf("failed to fetch the JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode the JWKS: %w", err)
+	}
+	keys := make(map[string]jsonWebKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = jsonWebKey{key: pub, alg: k.Alg}
+	}
+	c.keys = keys
+	c.fetchedAt = now
+	return nil
+}
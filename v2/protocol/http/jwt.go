@@ -0,0 +1,368 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	nethttp "net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultJWKSCacheTTL is the default duration the JWKS is cached for.
+	DefaultJWKSCacheTTL = time.Hour
+	// DefaultJWTClockSkew is the default tolerance applied when checking the
+	// expiration and the not before time of the tokens.
+	DefaultJWTClockSkew = time.Minute
+
+	// jwksMinRefresh bounds the refreshes of the JWKS triggered by tokens
+	// signed with an unknown key.
+	jwksMinRefresh = 30 * time.Second
+)
+
+var (
+	// ErrInvalidToken is returned by JWTValidator.Validate for the tokens
+	// which are malformed, not correctly signed or whose claims are not valid.
+	ErrInvalidToken = errors.New("invalid token")
+
+	errJWKSUnavailable = errors.New("signing keys unavailable")
+)
+
+// Claims are the claims of a validated JSON Web Token. Numbers are decoded
+// as json.Number.
+type Claims map[string]interface{}
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Issuer returns the "iss" claim.
+func (c Claims) Issuer() string {
+	s, _ := c["iss"].(string)
+	return s
+}
+
+// Audience returns the "aud" claim, which may be a single string or a list.
+func (c Claims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		var auds []string
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				auds = append(auds, s)
+			}
+		}
+		return auds
+	}
+	return nil
+}
+
+// time returns the time of the NumericDate claim name, and whether it is
+// present.
+func (c Claims) time(name string) (time.Time, bool, error) {
+	v, ok := c[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("%q claim is not a number", name)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q claim is not a number", name)
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), true, nil
+}
+
+type claimsKey struct{}
+
+// WithClaimsAtContext returns a context holding claims.
+func WithClaimsAtContext(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the bearer token validated by
+// WithJWTValidation for the request carrying the event being received.
+// If not set nil is returned.
+func ClaimsFromContext(ctx context.Context) Claims {
+	claims, _ := ctx.Value(claimsKey{}).(Claims)
+	return claims
+}
+
+// JWTValidator validates the JSON Web Tokens signed with the keys published
+// by the issuer as a JSON Web Key Set (JWKS). The RS, PS, ES and EdDSA
+// families of algorithms are supported.
+type JWTValidator struct {
+	jwks      *jwksCache
+	issuer    string
+	audiences []string
+	skew      time.Duration
+	now       func() time.Time
+}
+
+// JWTOption configures a JWTValidator.
+type JWTOption func(*JWTValidator)
+
+// WithJWTIssuer requires the "iss" claim of the tokens to be issuer.
+func WithJWTIssuer(issuer string) JWTOption {
+	return func(v *JWTValidator) {
+		v.issuer = issuer
+	}
+}
+
+// WithJWTAudience requires the "aud" claim of the tokens to contain one of
+// audiences.
+func WithJWTAudience(audiences ...string) JWTOption {
+	return func(v *JWTValidator) {
+		v.audiences = audiences
+	}
+}
+
+// WithJWTClockSkew sets the tolerance applied when checking the expiration
+// and the not before time of the tokens, DefaultJWTClockSkew by default.
+func WithJWTClockSkew(skew time.Duration) JWTOption {
+	return func(v *JWTValidator) {
+		v.skew = skew
+	}
+}
+
+// WithJWKSCacheTTL sets how long the JWKS is cached for, DefaultJWKSCacheTTL
+// by default. Regardless of it, the JWKS is fetched again when a token is
+// signed with an unknown key.
+func WithJWKSCacheTTL(ttl time.Duration) JWTOption {
+	return func(v *JWTValidator) {
+		v.jwks.ttl = ttl
+	}
+}
+
+// WithJWKSClient sets the HTTP client fetching the JWKS,
+// http.DefaultClient by default.
+func WithJWKSClient(client *nethttp.Client) JWTOption {
+	return func(v *JWTValidator) {
+		v.jwks.client = client
+	}
+}
+
+// NewJWTValidator returns a JWTValidator verifying the signature of the
+// tokens with the JWKS published at jwksURL.
+func NewJWTValidator(jwksURL string, opts ...JWTOption) *JWTValidator {
+	v := &JWTValidator{
+		jwks: &jwksCache{
+			url:        jwksURL,
+			client:     nethttp.DefaultClient,
+			ttl:        DefaultJWKSCacheTTL,
+			minRefresh: jwksMinRefresh,
+			now:        time.Now,
+		},
+		skew: DefaultJWTClockSkew,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Validate verifies the signature and the claims of token, and returns its
+// claims. The errors of invalid tokens wrap ErrInvalidToken.
+func (v *JWTValidator) Validate(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %v", ErrInvalidToken, err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature: %v", ErrInvalidToken, err)
+	}
+
+	key, err := v.jwks.key(ctx, header.Kid)
+	if errors.Is(err, errUnknownKey) {
+		return nil, fmt.Errorf("%w: %v %q", ErrInvalidToken, err, header.Kid)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %v", errJWKSUnavailable, err)
+	}
+	if key.alg != "" && key.alg != header.Alg {
+		return nil, fmt.Errorf("%w: algorithm %q does not match the key", ErrInvalidToken, header.Alg)
+	}
+	if err := verifySignature(header.Alg, key.key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+func (v *JWTValidator) validateClaims(claims Claims) error {
+	now := v.now()
+	exp, ok, err := claims.time("exp")
+	if err != nil {
+		return err
+	}
+	if ok && !now.Before(exp.Add(v.skew)) {
+		return fmt.Errorf("token expired at %s", exp.UTC().Format(time.RFC3339))
+	}
+	nbf, ok, err := claims.time("nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(v.skew).Before(nbf) {
+		return fmt.Errorf("token not valid before %s", nbf.UTC().Format(time.RFC3339))
+	}
+	if v.issuer != "" && claims.Issuer() != v.issuer {
+		return fmt.Errorf("unexpected issuer %q", claims.Issuer())
+	}
+	if len(v.audiences) > 0 && !containsAny(claims.Audience(), v.audiences) {
+		return fmt.Errorf("unexpected audience %q", claims.Audience())
+	}
+	return nil
+}
+
+// Middleware returns a Middleware rejecting the requests without a valid
+// bearer token with 401 Unauthorized, or 503 Service Unavailable if the JWKS
+// can not be fetched. The claims of the valid tokens are placed in the
+// context of the request, see ClaimsFromContext.
+func (v *JWTValidator) Middleware() Middleware {
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			auth := r.Header.Get("Authorization")
+			if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				nethttp.Error(w, "missing bearer token", nethttp.StatusUnauthorized)
+				return
+			}
+			claims, err := v.Validate(r.Context(), strings.TrimSpace(auth[7:]))
+			if errors.Is(err, errJWKSUnavailable) {
+				nethttp.Error(w, err.Error(), nethttp.StatusServiceUnavailable)
+				return
+			}
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				nethttp.Error(w, err.Error(), nethttp.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithClaimsAtContext(r.Context(), claims)))
+		})
+	}
+}
+
+// WithJWTValidation requires the requests received by the protocol to carry
+// a bearer token valid for validator. The claims of the token are available
+// to the receiver function with ClaimsFromContext.
+func WithJWTValidation(validator *JWTValidator) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http jwt validation option can not set nil protocol")
+		}
+		if validator == nil {
+			return fmt.Errorf("http jwt validation option was given a nil validator")
+		}
+		p.middleware = append(p.middleware, validator.Middleware())
+		return nil
+	}
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+// jwtHashes are the hash functions of the supported algorithms, except
+// EdDSA.
+var jwtHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// esCurveBits are the sizes of the curves of the ECDSA algorithms.
+var esCurveBits = map[string]int{"ES256": 256, "ES384": 384, "ES512": 521}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	if k, ok := key.(ed25519.PublicKey); ok && alg == "EdDSA" {
+		if !ed25519.Verify(k, []byte(signed), sig) {
+			return fmt.Errorf("invalid %s signature", alg)
+		}
+		return nil
+	}
+	hash, ok := jwtHashes[alg]
+	if !ok {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(k, hash, digest, sig)
+		case "PS":
+			return rsa.VerifyPSS(k, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case *ecdsa.PublicKey:
+		bits := k.Curve.Params().BitSize
+		if bits != esCurveBits[alg] {
+			break
+		}
+		size := (bits + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid %s signature", alg)
+		}
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return fmt.Errorf("invalid %s signature", alg)
+		}
+		return nil
+	}
+	return fmt.Errorf("algorithm %q does not match the key", alg)
+}
+
+func containsAny(values, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testSigner struct {
+	kid string
+	alg string
+	key crypto.Signer
+}
+
+func (s testSigner) jwk() map[string]string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch k := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": s.kid, "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		return map[string]string{"kty": "EC", "kid": s.kid, "crv": "P-256", "x": b64(k.X.FillBytes(make([]byte, 32))), "y": b64(k.Y.FillBytes(make([]byte, 32)))}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": s.kid, "crv": "Ed25519", "x": b64(k)}
+	}
+	return nil
+}
+
+func (s testSigner) sign(t *testing.T, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": s.alg, "kid": s.kid})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	var sig []byte
+	switch k := s.key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(signed))
+	}
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// jwksServer serves the keys of signers and counts the requests.
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	signers []testSigner
+	fetches atomic.Int32
+}
+
+func newJWKSServer(signers ...testSigner) *jwksServer {
+	s := &jwksServer{signers: signers}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var keys []map[string]string
+		for _, signer := range s.signers {
+			keys = append(keys, signer.jwk())
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	return s
+}
+
+func newTestSigners(t *testing.T) (testSigner, testSigner, testSigner) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testSigner{kid: "rsa", alg: "RS256", key: rsaKey},
+		testSigner{kid: "ec", alg: "ES256", key: ecKey},
+		testSigner{kid: "ed", alg: "EdDSA", key: edKey}
+}
+
+func TestJWTValidator(t *testing.T) {
+	rsaSigner, ecSigner, edSigner := newTestSigners(t)
+	srv := newJWKSServer(rsaSigner, ecSigner, edSigner)
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	v := NewJWTValidator(srv.URL, WithJWTIssuer("https://issuer"), WithJWTAudience("webhooks"), WithJWTClockSkew(time.Minute))
+	v.now = func() time.Time { return now }
+
+	valid := func(overrides map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{
+			"iss": "https://issuer",
+			"aud": []string{"other", "webhooks"},
+			"sub": "producer",
+			"exp": now.Add(time.Minute).Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(claims, k)
+				continue
+			}
+			claims[k] = v
+		}
+		return claims
+	}
+
+	otherKey, _, _ := newTestSigners(t)
+	otherKey.kid = "rsa"
+	testCases := map[string]struct {
+		token   string
+		wantErr string
+	}{
+		"RS256":               {token: rsaSigner.sign(t, valid(nil))},
+		"ES256":               {token: ecSigner.sign(t, valid(nil))},
+		"EdDSA":               {token: edSigner.sign(t, valid(map[string]interface{}{"aud": "webhooks"}))},
+		"expired within skew": {token: rsaSigner.sign(t, valid(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}))},
+		"no expiration":       {token: rsaSigner.sign(t, valid(map[string]interface{}{"exp": nil}))},
+		"expired": {
+			token:   rsaSigner.sign(t, valid(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()})),
+			wantErr: "invalid token: token expired at 2023-11-14T22:11:20Z",
+		},
+		"not yet valid": {
+			token:   rsaSigner.sign(t, valid(map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()})),
+			wantErr: "invalid token: token not valid before 2023-11-14T22:15:20Z",
+		},
+		"wrong issuer": {
+			token:   rsaSigner.sign(t, valid(map[string]interface{}{"iss": "https://evil"})),
+			wantErr: `invalid token: unexpected issuer "https://evil"`,
+		},
+		"wrong audience": {
+			token:   rsaSigner.sign(t, valid(map[string]interface{}{"aud": "other"})),
+			wantErr: `invalid token: unexpected audience ["other"]`,
+		},
+		"wrong key": {
+			token:   otherKey.sign(t, valid(nil)),
+			wantErr: "invalid token: crypto/rsa: verification error",
+		},
+		"algorithm mismatch": {
+			token:   testSigner{kid: "ec", alg: "RS256", key: ecSigner.key}.sign(t, valid(nil)),
+			wantErr: `invalid token: algorithm "RS256" does not match the key`,
+		},
+		"none": {
+			token:   testSigner{kid: "rsa", alg: "none", key: edSigner.key}.sign(t, valid(nil)),
+			wantErr: `invalid token: unsupported algorithm "none"`,
+		},
+		"malformed": {
+			token:   "not a token",
+			wantErr: "invalid token: malformed token",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			claims, err := v.Validate(context.Background(), tc.token)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				require.True(t, errors.Is(err, ErrInvalidToken))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "producer", claims.Subject())
+			require.Equal(t, "https://issuer", claims.Issuer())
+		})
+	}
+	// The key set is fetched once for all the tokens.
+	require.Equal(t, int32(1), srv.fetches.Load())
+}
+
+func TestJWTValidatorKeyRotation(t *testing.T) {
+	rsaSigner, ecSigner, _ := newTestSigners(t)
+	srv := newJWKSServer(rsaSigner)
+	defer srv.Close()
+
+	now := time.Unix(1700000000, 0)
+	v := NewJWTValidator(srv.URL, WithJWKSCacheTTL(time.Hour))
+	v.jwks.now = func() time.Time { return now }
+
+	_, err := v.Validate(context.Background(), rsaSigner.sign(t, map[string]interface{}{}))
+	require.NoError(t, err)
+
+	srv.mu.Lock()
+	srv.signers = []testSigner{ecSigner}
+	srv.mu.Unlock()
+
+	// The rotation is not looked up before the minimum refresh interval.
+	_, err = v.Validate(context.Background(), ecSigner.sign(t, map[string]interface{}{}))
+	require.EqualError(t, err, `invalid token: unknown signing key "ec"`)
+	require.Equal(t, int32(1), srv.fetches.Load())
+
+	now = now.Add(jwksMinRefresh)
+	_, err = v.Validate(context.Background(), ecSigner.sign(t, map[string]interface{}{}))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), srv.fetches.Load())
+
+	// The set is cached until the TTL expires.
+	now = now.Add(time.Hour)
+	_, err = v.Validate(context.Background(), rsaSigner.sign(t, map[string]interface{}{}))
+	require.EqualError(t, err, `invalid token: unknown signing key "rsa"`)
+	require.Equal(t, int32(3), srv.fetches.Load())
+}
+
+func TestJWTValidatorMiddleware(t *testing.T) {
+	rsaSigner, _, _ := newTestSigners(t)
+	srv := newJWKSServer(rsaSigner)
+	defer srv.Close()
+
+	var got Claims
+	handler := NewJWTValidator(srv.URL).Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ClaimsFromContext(r.Context())
+	}))
+
+	testCases := map[string]struct {
+		auth          string
+		wantStatus    int
+		wantChallenge string
+		wantSubject   string
+	}{
+		"valid":   {auth: "Bearer " + rsaSigner.sign(t, map[string]interface{}{"sub": "producer"}), wantStatus: http.StatusOK, wantSubject: "producer"},
+		"missing": {wantStatus: http.StatusUnauthorized, wantChallenge: "Bearer"},
+		"basic":   {auth: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized, wantChallenge: "Bearer"},
+		"invalid": {auth: "Bearer abc.def.ghi", wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer error="invalid_token"`},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+			require.Equal(t, tc.wantStatus, rw.Code)
+			require.Equal(t, tc.wantChallenge, rw.Header().Get("WWW-Authenticate"))
+			require.Equal(t, tc.wantSubject, got.Subject())
+		})
+	}
+
+	srv.Close()
+	unavailable := NewJWTValidator(srv.URL).Middleware()(handler)
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+	req.Header.Set("Authorization", "Bearer "+rsaSigner.sign(t, map[string]interface{}{}))
+	rw := httptest.NewRecorder()
+	unavailable.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusServiceUnavailable, rw.Code)
+}
+
+func TestWithJWTValidation(t *testing.T) {
+	p := &Protocol{}
+	require.EqualError(t, WithJWTValidation(nil)(p), "http jwt validation option was given a nil validator")
+	require.NoError(t, WithJWTValidation(NewJWTValidator("http://unittest"))(p))
+	require.Len(t, p.middleware, 1)
+}
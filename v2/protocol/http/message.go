@@ -16,6 +16,7 @@ import (
 	"github.com/cloudevents/sdk-go/v2/binding"
 	"github.com/cloudevents/sdk-go/v2/binding/format"
 	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 const prefix = "Ce-"
@@ -43,6 +44,8 @@ type Message struct {
 	OnFinish   func(error) error
 
 	ctx context.Context
+	// transport is set for the messages of the incoming requests.
+	transport *protocol.TransportMetadata
 
 	format  format.Format
 	version spec.Version
@@ -52,6 +55,7 @@ type Message struct {
 var _ binding.Message = (*Message)(nil)
 var _ binding.MessageContext = (*Message)(nil)
 var _ binding.MessageMetadataReader = (*Message)(nil)
+var _ protocol.TransportMetadataReader = (*Message)(nil)
 
 // NewMessage returns a binding.Message with header and data.
 // The returned binding.Message *cannot* be read several times. In order to read it more times, buffer it using binding/buffering methods
@@ -74,9 +78,30 @@ func NewMessageFromHttpRequest(req *nethttp.Request) *Message {
 	}
 	message := NewMessage(req.Header, req.Body)
 	message.ctx = req.Context()
+	message.transport = &protocol.TransportMetadata{
+		Header:     req.Header,
+		RemoteAddr: req.RemoteAddr,
+	}
+	if req.URL != nil {
+		message.transport.Topic = req.URL.Path
+	}
+	if req.TLS != nil {
+		message.transport.PeerCertificates = req.TLS.PeerCertificates
+	}
 	return message
 }
 
+// TransportMetadata implements protocol.TransportMetadataReader, it returns
+// the headers of the message and, for the messages of the incoming requests,
+// the path of the request as topic, the remote address and the certificates
+// of the client.
+func (m *Message) TransportMetadata() protocol.TransportMetadata {
+	if m.transport == nil {
+		return protocol.TransportMetadata{Header: m.Header}
+	}
+	return *m.transport
+}
+
 // NewMessageFromHttpResponse returns a binding.Message with header and data.
 // The returned binding.Message *cannot* be read several times. In order to read it more times, buffer it using binding/buffering methods
 func NewMessageFromHttpResponse(resp *nethttp.Response) *Message {
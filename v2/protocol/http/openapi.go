@@ -0,0 +1,218 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification of the
+// documents returned by OpenAPI.
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPIInfo describes the API in the documents returned by OpenAPI.
+type OpenAPIInfo struct {
+	Title       string
+	Version     string
+	Description string
+	// Servers are the URLs the receiver is reachable at, e.g. behind a
+	// gateway.
+	Servers []string
+}
+
+// OpenAPI returns an OpenAPI 3 description of the endpoints p serves as a
+// receiver, to be marshaled in JSON or YAML for gateways and client
+// generators: the POST of the events on its path, in the binary content
+// mode or in any registered event format, the validation handshake of the
+// abuse protection and the GET and DELETE handlers if configured, and the
+// error responses of the configured limits.
+func (p *Protocol) OpenAPI(info OpenAPIInfo) map[string]interface{} {
+	path := p.Path
+	if path == "" {
+		path = "/"
+	}
+	operations := map[string]interface{}{
+		"post": p.openAPIPost(),
+	}
+	if p.OptionsHandlerFn != nil {
+		operations["options"] = openAPIHandshake()
+	}
+	if p.GetHandlerFn != nil {
+		operations["get"] = map[string]interface{}{
+			"operationId": "get",
+			"responses":   map[string]interface{}{"default": map[string]interface{}{"description": "Response of the GET handler."}},
+		}
+	}
+	if p.DeleteHandlerFn != nil {
+		operations["delete"] = map[string]interface{}{
+			"operationId": "delete",
+			"responses":   map[string]interface{}{"default": map[string]interface{}{"description": "Response of the DELETE handler."}},
+		}
+	}
+
+	apiInfo := map[string]interface{}{"title": info.Title, "version": info.Version}
+	if info.Description != "" {
+		apiInfo["description"] = info.Description
+	}
+	doc := map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info":    apiInfo,
+		"paths":   map[string]interface{}{path: operations},
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{"CloudEvent": cloudEventSchema()},
+		},
+	}
+	if len(info.Servers) > 0 {
+		servers := make([]interface{}, len(info.Servers))
+		for i, url := range info.Servers {
+			servers[i] = map[string]interface{}{"url": url}
+		}
+		doc["servers"] = servers
+	}
+	return doc
+}
+
+func (p *Protocol) openAPIPost() map[string]interface{} {
+	content := map[string]interface{}{
+		// The binary content mode carries the data in any media type.
+		"*/*": map[string]interface{}{"schema": map[string]interface{}{}},
+	}
+	for _, mt := range format.MediaTypes() {
+		schema := map[string]interface{}{}
+		switch {
+		case mt == event.ApplicationCloudEventsJSON:
+			schema = openAPIRef("CloudEvent")
+		case mt == event.ApplicationCloudEventsBatchJSON:
+			schema = map[string]interface{}{"type": "array", "items": openAPIRef("CloudEvent")}
+		case !strings.HasSuffix(mt, "json"):
+			schema = map[string]interface{}{"type": "string", "format": "binary"}
+		}
+		content[mt] = map[string]interface{}{"schema": schema}
+	}
+
+	parameters := []interface{}{
+		openAPIHeader("ce-specversion", "The specversion attribute, in the binary content mode.", false),
+		openAPIHeader("ce-id", "The id attribute, in the binary content mode.", false),
+		openAPIHeader("ce-source", "The source attribute, in the binary content mode.", false),
+		openAPIHeader("ce-type", "The type attribute, in the binary content mode.", false),
+		openAPIHeader("ce-subject", "The subject attribute, in the binary content mode.", false),
+		openAPIHeader("ce-time", "The time attribute, in the binary content mode.", false),
+		openAPIHeader("ce-dataschema", "The dataschema attribute, in the binary content mode.", false),
+		map[string]interface{}{
+			"name":        "Content-Encoding",
+			"in":          "header",
+			"description": "The compression of the body.",
+			"schema":      map[string]interface{}{"type": "string", "enum": []interface{}{"gzip", "deflate", "identity"}},
+		},
+	}
+
+	responses := map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "The event is accepted. The body, if any, is the response event, in the binary or a structured content mode.",
+			"content":     map[string]interface{}{"*/*": map[string]interface{}{"schema": map[string]interface{}{}}},
+		},
+		"202": map[string]interface{}{"description": "The event is accepted for processing."},
+		"400": openAPIErrorResponse("The event is invalid."),
+		"403": openAPIErrorResponse("The event is not authorized."),
+		"415": openAPIErrorResponse("The content type or the content encoding of the request is not supported."),
+		"429": map[string]interface{}{
+			"description": "The rate limit is exceeded.",
+			"headers": map[string]interface{}{
+				"Retry-After": map[string]interface{}{
+					"description": "The number of seconds to wait before retrying.",
+					"schema":      map[string]interface{}{"type": "integer"},
+				},
+			},
+		},
+		"500": openAPIErrorResponse("The event could not be processed."),
+	}
+	if p.maxRequestBytes > 0 || p.requestCodings != nil {
+		responses["413"] = openAPIErrorResponse("The request body is larger than the limit of the receiver.")
+	}
+
+	return map[string]interface{}{
+		"operationId": "receiveEvent",
+		"summary":     "Receive a CloudEvent.",
+		"parameters":  parameters,
+		"requestBody": map[string]interface{}{"required": true, "content": content},
+		"responses":   responses,
+	}
+}
+
+func openAPIHandshake() map[string]interface{} {
+	return map[string]interface{}{
+		"operationId": "validateWebhook",
+		"summary":     "Validation handshake of the HTTP WebHook abuse protection.",
+		"parameters": []interface{}{
+			openAPIHeader(headerRequestOrigin, "The origin of the deliveries.", true),
+			openAPIHeader(headerRequestRate, "The number of requests per minute the origin asks for.", false),
+			openAPIHeader("WebHook-Request-Callback", "The URL to confirm the subscription asynchronously.", false),
+		},
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "The deliveries of the origin are allowed.",
+				"headers": map[string]interface{}{
+					"WebHook-Allowed-Origin": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+					headerAllowedRate:        map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+					"Allow":                  map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+				},
+			},
+			"403": map[string]interface{}{"description": "The deliveries of the origin are not allowed."},
+		},
+	}
+}
+
+func openAPIHeader(name, description string, required bool) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "header",
+		"description": description,
+		"required":    required,
+		"schema":      map[string]interface{}{"type": "string"},
+	}
+}
+
+func openAPIErrorResponse(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content":     map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}},
+	}
+}
+
+func openAPIRef(schema string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + schema}
+}
+
+// cloudEventSchema returns the schema of an event in the JSON format.
+func cloudEventSchema() map[string]interface{} {
+	str := func(f string) map[string]interface{} {
+		s := map[string]interface{}{"type": "string"}
+		if f != "" {
+			s["format"] = f
+		}
+		return s
+	}
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"specversion", "id", "source", "type"},
+		"properties": map[string]interface{}{
+			"specversion":     map[string]interface{}{"type": "string", "enum": []interface{}{event.CloudEventsVersionV1, event.CloudEventsVersionV03}},
+			"id":              str(""),
+			"source":          str("uri-reference"),
+			"type":            str(""),
+			"datacontenttype": str(""),
+			"dataschema":      str("uri"),
+			"subject":         str(""),
+			"time":            str("date-time"),
+			"data":            map[string]interface{}{},
+			"data_base64":     str("byte"),
+		},
+		"additionalProperties": true,
+	}
+}
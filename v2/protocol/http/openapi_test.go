@@ -0,0 +1,83 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI(t *testing.T) {
+	p, err := New(
+		WithPath("/events"),
+		WithMaxRequestBytes(1024),
+		WithDefaultOptionsHandlerFunc([]string{http.MethodPost}, 100, []string{"*"}, true),
+	)
+	require.NoError(t, err)
+
+	b, err := json.Marshal(p.OpenAPI(OpenAPIInfo{Title: "orders", Version: "1.0.0", Servers: []string{"https://orders.example.com"}}))
+	require.NoError(t, err)
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title   string `json:"title"`
+			Version string `json:"version"`
+		} `json:"info"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Parameters  []struct {
+				Name     string `json:"name"`
+				Required bool   `json:"required"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema map[string]interface{} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]json.RawMessage `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	require.Equal(t, OpenAPIVersion, doc.OpenAPI)
+	require.Equal(t, "orders", doc.Info.Title)
+	require.Equal(t, "https://orders.example.com", doc.Servers[0].URL)
+	require.Contains(t, doc.Components.Schemas, "CloudEvent")
+
+	operations := doc.Paths["/events"]
+	require.Len(t, operations, 2)
+	post := operations["post"]
+	require.Equal(t, "receiveEvent", post.OperationID)
+	require.Equal(t, "#/components/schemas/CloudEvent", post.RequestBody.Content["application/cloudevents+json"].Schema["$ref"])
+	require.Equal(t, "array", post.RequestBody.Content["application/cloudevents-batch+json"].Schema["type"])
+	require.Contains(t, post.RequestBody.Content, "*/*")
+	for _, status := range []string{"200", "400", "413", "415", "429", "500"} {
+		require.Contains(t, post.Responses, status)
+	}
+
+	options := operations["options"]
+	require.Equal(t, "validateWebhook", options.OperationID)
+	require.Equal(t, "WebHook-Request-Origin", options.Parameters[0].Name)
+	require.True(t, options.Parameters[0].Required)
+
+	p, err = New()
+	require.NoError(t, err)
+	doc.Paths = nil
+	b, err = json.Marshal(p.OpenAPI(OpenAPIInfo{Title: "orders", Version: "1.0.0"}))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &doc))
+	require.Len(t, doc.Paths["/"], 1)
+	require.NotContains(t, doc.Paths["/"]["post"].Responses, "413")
+}
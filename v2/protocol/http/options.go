@@ -12,6 +12,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 // Option is the function signature required to be considered an http.Option.
@@ -256,6 +258,38 @@ func WithRoundTripperDecorator(decorator func(roundTripper nethttp.RoundTripper)
 	}
 }
 
+// WithTLS configures the TLS connections of the requests sent with the
+// shared protocol.TLSOption set, e.g. to trust a private certificate
+// authority or present a client certificate. It applies to the default
+// transport, or to the *http.Transport set with WithRoundTripper or
+// WithClient, and must be given after them.
+func WithTLS(opts ...protocol.TLSOption) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http tls option can not set nil protocol")
+		}
+		config, err := protocol.NewTLSConfig(opts...)
+		if err != nil {
+			return err
+		}
+		rt := p.roundTripper
+		if rt == nil && p.Client != nil {
+			rt = p.Client.Transport
+		}
+		if rt == nil {
+			rt = nethttp.DefaultTransport
+		}
+		transport, ok := rt.(*nethttp.Transport)
+		if !ok {
+			return fmt.Errorf("http tls option can not configure a %T round tripper", rt)
+		}
+		transport = transport.Clone()
+		transport.TLSClientConfig = config
+		p.roundTripper = transport
+		return nil
+	}
+}
+
 // WithClient sets the protocol client
 func WithClient(client nethttp.Client) Option {
 	return func(p *Protocol) error {
@@ -350,3 +384,154 @@ func WithRequestDataAtContextMiddleware() Option {
 		})
 	})
 }
+
+// WithRetryAfter makes retries honor the Retry-After header sent by the
+// recipient along with a retriable status code (e.g. 429 or 503), waiting at
+// most maxDelay before the next attempt. Retries are still bounded by the
+// retry parameters set in the context.
+func WithRetryAfter(maxDelay time.Duration) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http retry after option can not set nil protocol")
+		}
+		if maxDelay <= 0 {
+			return fmt.Errorf("http retry after max delay must be positive")
+		}
+		p.maxRetryAfter = maxDelay
+		return nil
+	}
+}
+
+// WithUnsubscribeHandler sets the callback invoked when a delivery target
+// answers with 410 Gone, signaling that the subscription has been retired
+// and that the sender should stop sending events to it.
+func WithUnsubscribeHandler(fn UnsubscribeHandler) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http unsubscribe handler option can not set nil protocol")
+		}
+		if fn == nil {
+			return fmt.Errorf("http unsubscribe handler can not be nil")
+		}
+		p.unsubscribeHandler = fn
+		return nil
+	}
+}
+
+// WithWebhookRequestRate sets the number of requests per minute requested
+// from the delivery target in the webhook handshake (see Protocol.Handshake)
+// and paces the deliveries to this rate until the target allows another one.
+func WithWebhookRequestRate(rate int) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http webhook request rate option can not set nil protocol")
+		}
+		if rate <= 0 {
+			return fmt.Errorf("http webhook request rate must be positive")
+		}
+		p.requestRate = rate
+		p.pacer.setRate(rate)
+		return nil
+	}
+}
+
+// WithHedging enables hedged requests: if a request has not completed after
+// delay, a second copy of the request is sent to target, or to the same
+// target if empty, and the first response wins while the other request is
+// cancelled. This trims the tail latency of the deliveries, at the cost of
+// additional requests.
+//
+// The recipient may receive and process both copies of the event, even when
+// one of the requests is cancelled: only enable hedging for recipients
+// processing the events idempotently, e.g. deduplicating them by source and
+// id attributes.
+func WithHedging(delay time.Duration, target string) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http hedging option can not set nil protocol")
+		}
+		if delay <= 0 {
+			return fmt.Errorf("http hedging delay must be positive")
+		}
+		h := &hedging{delay: delay}
+		if target = strings.TrimSpace(target); target != "" {
+			targetURL, err := url.Parse(target)
+			if err != nil {
+				return fmt.Errorf("http hedging target option failed to parse target url: %s", err.Error())
+			}
+			h.target = targetURL
+		}
+		p.hedging = h
+		return nil
+	}
+}
+
+// WithTargets sets several outbound recipients of cloudevents, the endpoint
+// of each delivery being selected by strategy among the healthy endpoints.
+// Each retry selects an endpoint again, so that a delivery can be retried on
+// another endpoint. A target set in the context overrides the endpoints.
+//
+// An endpoint becomes unhealthy for a cooldown once deliveries in a row fail
+// to reach it with a transport error or a 5xx status code, see
+// WithTargetEjection, or when Protocol.RunHealthChecks finds it unhealthy.
+// When no endpoint is healthy, all of them are used.
+func WithTargets(strategy TargetStrategy, endpoints ...Endpoint) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http targets option can not set nil protocol")
+		}
+		if len(endpoints) == 0 {
+			return fmt.Errorf("http targets option was given no endpoint")
+		}
+		if strategy < RoundRobin || strategy > ConsistentHash {
+			return fmt.Errorf("http targets option was given an unknown strategy %d", strategy)
+		}
+		eps, err := newEndpoints(endpoints)
+		if err != nil {
+			return fmt.Errorf("http targets option %w", err)
+		}
+
+		p.targets = newTargets(strategy, eps)
+		p.Target = eps[0].url
+		return nil
+	}
+}
+
+// WithTargetEjection sets the number of deliveries in a row failing to reach
+// an endpoint configured with WithTargets after which it is unhealthy, and the
+// cooldown after which it is tried again. Defaults to 3 failures and 30s.
+func WithTargetEjection(maxFailures int, cooldown time.Duration) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http target ejection option can not set nil protocol")
+		}
+		if p.targets == nil {
+			return fmt.Errorf("http target ejection option requires WithTargets")
+		}
+		if maxFailures <= 0 || cooldown <= 0 {
+			return fmt.Errorf("http target ejection failures and cooldown must be positive")
+		}
+		p.targets.maxFailures = maxFailures
+		p.targets.cooldown = cooldown
+		return nil
+	}
+}
+
+// WithMaxRequestBytes limits the size of the request bodies accepted by the
+// receiver, after decoding their Content-Encoding, see
+// WithRequestDecompression. Requests announcing a
+// larger Content-Length are rejected with 413 Request Entity Too Large
+// before their body is read. Otherwise, reading the body fails once the limit
+// is exceeded, the message is not acknowledged and 413 is returned.
+func WithMaxRequestBytes(size int64) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http max request bytes option can not set nil protocol")
+		}
+		if size <= 0 {
+			return fmt.Errorf("http max request bytes option was given a non positive size: %d", size)
+		}
+		p.maxRequestBytes = size
+		return nil
+	}
+}
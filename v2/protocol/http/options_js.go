@@ -0,0 +1,55 @@
+//go:build js
+
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"fmt"
+)
+
+// The request headers turned into the options of the Fetch API by the
+// net/http transport of GOOS=js, see net/http.RoundTripper.
+const (
+	fetchModeHeader        = "js.fetch:mode"
+	fetchCredentialsHeader = "js.fetch:credentials"
+	fetchRedirectHeader    = "js.fetch:redirect"
+)
+
+// WithFetchMode sets the mode of the Fetch API requests sending the events
+// from a browser: "cors", "no-cors", "same-origin" or "navigate". The browser
+// defaults to "same-origin".
+func WithFetchMode(mode string) Option {
+	return withFetchOption(fetchModeHeader, mode, "cors", "no-cors", "same-origin", "navigate")
+}
+
+// WithFetchCredentials sets whether the Fetch API requests sending the events
+// from a browser carry its cookies and credentials: "omit", "same-origin" or
+// "include". The browser defaults to "same-origin".
+func WithFetchCredentials(credentials string) Option {
+	return withFetchOption(fetchCredentialsHeader, credentials, "omit", "same-origin", "include")
+}
+
+// WithFetchRedirect sets how the Fetch API requests sending the events from a
+// browser follow the redirects: "follow", "error" or "manual". The browser
+// defaults to "follow".
+func WithFetchRedirect(redirect string) Option {
+	return withFetchOption(fetchRedirectHeader, redirect, "follow", "error", "manual")
+}
+
+func withFetchOption(header, value string, valid ...string) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http fetch option can not set nil protocol")
+		}
+		for _, v := range valid {
+			if value == v {
+				return WithHeader(header, value)(p)
+			}
+		}
+		return fmt.Errorf("http fetch option %q can not be %q", header, value)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build js
+
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFetchOptions(t *testing.T) {
+	p, err := New(WithFetchMode("cors"), WithFetchCredentials("include"), WithFetchRedirect("error"))
+	require.NoError(t, err)
+	require.Equal(t, "cors", p.RequestTemplate.Header.Get(fetchModeHeader))
+	require.Equal(t, "include", p.RequestTemplate.Header.Get(fetchCredentialsHeader))
+	require.Equal(t, "error", p.RequestTemplate.Header.Get(fetchRedirectHeader))
+
+	for _, opt := range []Option{
+		WithFetchMode("any"),
+		WithFetchCredentials(""),
+		WithFetchRedirect("Follow"),
+	} {
+		_, err := New(opt)
+		require.Error(t, err)
+	}
+	require.Error(t, WithFetchMode("cors")(nil))
+}
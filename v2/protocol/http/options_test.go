@@ -7,8 +7,10 @@ package http
 
 import (
 	"context"
+	"crypto/x509"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"sync/atomic"
 	"testing"
@@ -17,6 +19,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
 func TestWithTarget(t *testing.T) {
@@ -998,3 +1002,38 @@ type mockOptionsServer struct {
 func (m mockOptionsServer) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	m.handler(res, req)
 }
+
+func TestWithMaxRequestBytes(t *testing.T) {
+	p, err := New(WithMaxRequestBytes(1024))
+	require.NoError(t, err)
+	require.Equal(t, int64(1024), p.maxRequestBytes)
+
+	_, err = New(WithMaxRequestBytes(0))
+	require.EqualError(t, err, "http max request bytes option was given a non positive size: 0")
+}
+
+func TestWithTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	p, err := New(WithTarget(srv.URL), WithTLS(protocol.WithTLSRootCAs(pool)))
+	require.NoError(t, err)
+	resp, err := p.Client.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	// The default transport is not modified.
+	if config := http.DefaultTransport.(*http.Transport).TLSClientConfig; config != nil {
+		require.Nil(t, config.RootCAs)
+	}
+
+	_, err = New(WithRoundTripper(roundTripperFunc(nil)), WithTLS())
+	require.EqualError(t, err, "http tls option can not configure a http.roundTripperFunc round tripper")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
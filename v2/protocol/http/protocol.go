@@ -14,11 +14,13 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/protocol"
@@ -102,6 +104,31 @@ type Protocol struct {
 	limiter           RateLimiter
 
 	isRetriableFunc IsRetriable
+
+	// maxRetryAfter caps the delay honored from Retry-After response headers
+	// while retrying. Zero means Retry-After is ignored.
+	maxRetryAfter time.Duration
+	// unsubscribeHandler is invoked when the target answers with 410 Gone.
+	unsubscribeHandler UnsubscribeHandler
+
+	// requestRate is the number of requests per minute sent in the webhook
+	// handshake, and pacer paces the deliveries to the allowed rate.
+	requestRate int
+	pacer       webhookPacer
+
+	// hedging, if set, sends a second request when the first one is slow.
+	hedging *hedging
+	// targets, if set, are the endpoints the deliveries are spread over.
+	targets *targets
+	// resolution, if set, discovers the targets.
+	resolution *resolution
+	// compression, if set, compresses the response events.
+	compression *responseCompression
+	// maxRequestBytes, if positive, limits the size of the request bodies.
+	maxRequestBytes int64
+	// requestCodings are the content codings of the requests decoded by the
+	// receiver, see WithRequestDecompression.
+	requestCodings []string
 }
 
 func New(opts ...Option) (*Protocol, error) {
@@ -208,9 +235,17 @@ func (p *Protocol) Request(ctx context.Context, m binding.Message, transformers
 	var err error
 	defer func() { _ = m.Finish(err) }()
 
+	if r, ok := m.(binding.MessageMetadataReader); ok {
+		ctx = p.withPartitionKey(ctx, r)
+	}
+	if p.resolution != nil && cecontext.TargetFrom(ctx) == nil {
+		if err = p.resolution.refresh(ctx, p.targets); err != nil {
+			return nil, err
+		}
+	}
 	req := p.makeRequest(ctx)
 
-	if p.Client == nil || req == nil || req.URL == nil {
+	if p.Client == nil || req == nil || (req.URL == nil && p.resolution == nil) {
 		return nil, fmt.Errorf("not initialized: %#v", p)
 	}
 
@@ -218,7 +253,11 @@ func (p *Protocol) Request(ctx context.Context, m binding.Message, transformers
 		return nil, err
 	}
 
-	return p.do(ctx, req)
+	msg, result := p.do(ctx, req)
+	if p.unsubscribeHandler != nil && IsGone(result) {
+		p.unsubscribeHandler(ctx, req.URL)
+	}
+	return msg, result
 }
 
 func (p *Protocol) makeRequest(ctx context.Context) *http.Request {
@@ -288,6 +327,88 @@ func (p *Protocol) Receive(ctx context.Context) (binding.Message, error) {
 	}
 }
 
+// ReceiveBatch receives the next incoming HTTP request. A request in batched
+// mode is split into one message per event and answered once all of them are
+// finished: with 200 OK if all of them are acknowledged, or according to the
+// result of the first one (in the batch order) that is not. Other requests are returned as a single message
+// and answered according to its result.
+// Returns io.EOF if the receiver is closed.
+func (p *Protocol) ReceiveBatch(ctx context.Context) ([]binding.Message, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("nil Context")
+	}
+
+	for {
+		msg, fn, err := p.Respond(ctx)
+		if err != nil {
+			if fn != nil {
+				_ = fn(ctx, nil, err)
+			}
+			return nil, err
+		}
+
+		if msg.ReadEncoding() != binding.EncodingBatch {
+			return []binding.Message{binding.WithFinish(msg, func(err error) {
+				_ = fn(ctx, nil, err)
+			})}, nil
+		}
+
+		var events event.Batch
+		if m, ok := msg.(*Message); ok {
+			events, err = binding.ToEvents(ctx, m, m.BodyReader)
+		} else {
+			err = binding.ErrCannotConvertToEvents
+		}
+		if err != nil || len(events) == 0 {
+			_ = msg.Finish(nil)
+			if err != nil {
+				_ = fn(ctx, nil, NewResult(http.StatusBadRequest, "failed to read batch: %w", err))
+			} else {
+				_ = fn(ctx, nil, nil)
+			}
+			continue
+		}
+		return splitBatch(ctx, msg, fn, events), nil
+	}
+}
+
+// splitBatch returns a message per event, finishing msg and responding with
+// fn once all of them are finished.
+func splitBatch(ctx context.Context, msg binding.Message, fn protocol.ResponseFn, events event.Batch) []binding.Message {
+	var mu sync.Mutex
+	pending := len(events)
+	results := make([]protocol.Result, len(events))
+	finish := func(i int, err error) {
+		mu.Lock()
+		results[i] = err
+		pending--
+		done := pending == 0
+		mu.Unlock()
+		if !done {
+			return
+		}
+		var result protocol.Result
+		for _, r := range results {
+			if !protocol.IsACK(r) {
+				result = r
+				break
+			}
+		}
+		_ = msg.Finish(nil)
+		_ = fn(ctx, nil, result)
+	}
+
+	messages := make([]binding.Message, len(events))
+	for i := range events {
+		messages[i] = binding.WithFinish((*binding.EventMessage)(&events[i]), func(err error) {
+			finish(i, err)
+		})
+	}
+	return messages
+}
+
+var _ protocol.BatchReceiver = (*Protocol)(nil)
+
 // Respond receives the next incoming HTTP request as a CloudEvent and waits
 // for the response callback to invoked before continuing.
 // Returns non-nil error if the incoming HTTP request fails to parse as a CloudEvent
@@ -357,6 +478,26 @@ func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	maxRequestBytes := p.maxRequestBytes
+	if p.requestCodings != nil {
+		decoded, err := decodeContentEncoding(req, p.requestCodings)
+		if err != nil {
+			rw.Header().Set("Accept-Encoding", strings.Join(p.requestCodings, ", "))
+			http.Error(rw, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		if decoded && maxRequestBytes <= 0 {
+			maxRequestBytes = compression.DefaultMaxDataSize
+		}
+	}
+	if maxRequestBytes > 0 {
+		if req.ContentLength > maxRequestBytes {
+			http.Error(rw, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		req.Body = http.MaxBytesReader(rw, req.Body, maxRequestBytes)
+	}
+
 	m := NewMessageFromHttpRequest(req)
 	if m == nil {
 		// Should never get here unless ServeHTTP is called directly.
@@ -397,6 +538,7 @@ func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			case !protocol.IsACK(res):
 				// Map client errors to http status code
 				validationError := event.ValidationError{}
+				var maxBytesErr *http.MaxBytesError
 				if errors.As(res, &validationError) {
 					status = http.StatusBadRequest
 					rw.Header().Set("content-type", "text/plain")
@@ -405,6 +547,12 @@ func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 					return validationError
 				} else if errors.Is(res, binding.ErrUnknownEncoding) {
 					status = http.StatusUnsupportedMediaType
+				} else if errors.As(res, &maxBytesErr) {
+					status = http.StatusRequestEntityTooLarge
+				} else if errors.Is(res, protocol.ErrRateLimited) {
+					status = http.StatusTooManyRequests
+				} else if errors.Is(res, protocol.ErrForbidden) {
+					status = http.StatusForbidden
 				} else {
 					status = http.StatusInternalServerError
 				}
@@ -412,7 +560,13 @@ func (p *Protocol) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		}
 
 		if respMsg != nil {
-			err := WriteResponseWriter(ctx, respMsg, status, rw, transformers...)
+			w := p.newCompressingResponseWriter(rw, req)
+			err := WriteResponseWriter(ctx, respMsg, status, w, transformers...)
+			if cw, ok := w.(*compressingResponseWriter); ok {
+				if closeErr := cw.Close(); err == nil {
+					err = closeErr
+				}
+			}
 			return respMsg.Finish(err)
 		}
 
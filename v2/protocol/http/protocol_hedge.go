@@ -0,0 +1,161 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+// hedging configures the hedged requests, see WithHedging.
+type hedging struct {
+	delay  time.Duration
+	target *url.URL
+}
+
+type hedgedResponse struct {
+	resp *http.Response
+	err  error
+	// attempt is the index of the request.
+	attempt int
+}
+
+// roundTrip sends req, hedging it if configured.
+func (p *Protocol) roundTrip(req *http.Request) (*http.Response, error) {
+	if p.hedging == nil {
+		if err := p.pacer.wait(req.Context()); err != nil {
+			return nil, err
+		}
+		return p.Client.Do(req)
+	}
+	return p.doHedged(req)
+}
+
+// doHedged sends req and, if it has not completed within the hedging delay, a
+// copy of req to the hedging target. The first response wins and the other
+// request is cancelled. If a request fails, the response of the other one is
+// awaited.
+func (p *Protocol) doHedged(req *http.Request) (*http.Response, error) {
+	getBody, err := bodyGetter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make(chan hedgedResponse, 2)
+	var cancels []context.CancelFunc
+	send := func(target *url.URL) error {
+		ctx, cancel := context.WithCancel(req.Context())
+		r := req.Clone(ctx)
+		if target != nil {
+			r.URL = target
+			r.Host = ""
+		}
+		if getBody != nil {
+			body, err := getBody()
+			if err != nil {
+				cancel()
+				return err
+			}
+			r.Body = body
+		}
+		if err := p.pacer.wait(ctx); err != nil {
+			cancel()
+			return err
+		}
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := p.Client.Do(r)
+			responses <- hedgedResponse{resp: resp, err: err, attempt: attempt}
+		}()
+		return nil
+	}
+
+	if err := send(nil); err != nil {
+		return nil, err
+	}
+
+	timer := cecontext.ClockFrom(req.Context()).NewTimer(p.hedging.delay)
+	defer timer.Stop()
+
+	var winner hedgedResponse
+	received := 0
+	for received < len(cancels) {
+		select {
+		case <-timer.C():
+			// If the hedge can not be sent, the first request is still
+			// awaited.
+			_ = send(p.hedging.target)
+			continue
+		case winner = <-responses:
+			received++
+		}
+		if winner.err == nil {
+			break
+		}
+	}
+
+	// Cancel the other requests, and release their responses.
+	for i, cancel := range cancels {
+		if winner.err != nil || i != winner.attempt {
+			cancel()
+		}
+	}
+	if pending := len(cancels) - received; pending > 0 {
+		go func() {
+			for i := 0; i < pending; i++ {
+				if r := <-responses; r.resp != nil {
+					_ = r.resp.Body.Close()
+				}
+			}
+		}()
+	}
+
+	if winner.err != nil {
+		return nil, winner.err
+	}
+	// The request context must live until the response body is read.
+	winner.resp.Body = &cancelOnClose{ReadCloser: winner.resp.Body, cancel: cancels[winner.attempt]}
+	return winner.resp, nil
+}
+
+// bodyGetter returns a function returning a new reader of the body of req,
+// buffering it if needed, or nil if req has no body.
+func bodyGetter(req *http.Request) (func() (io.ReadCloser, error), error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		return req.GetBody, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resetBody(req, body)
+	return req.GetBody, nil
+}
+
+// cancelOnClose cancels the context of a request once its response body is
+// closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+var _ io.ReadCloser = (*cancelOnClose)(nil)
@@ -0,0 +1,125 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestHedging(t *testing.T) {
+	var requests atomic.Int32
+	arrived := make(chan struct{})
+	cancelled := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		require.Equal(t, `"hello"`, string(body))
+		if requests.Add(1) == 1 {
+			// The first request is slow, until cancelled.
+			close(arrived)
+			<-req.Context().Done()
+			close(cancelled)
+			return
+		}
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		require.Equal(t, `"hello"`, string(body))
+		rw.WriteHeader(http.StatusCreated)
+	}))
+	defer fast.Close()
+
+	testCases := map[string]struct {
+		target     string
+		wantStatus int
+	}{
+		"same target": {
+			wantStatus: http.StatusAccepted,
+		},
+		"alternate target": {
+			target:     fast.URL,
+			wantStatus: http.StatusCreated,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			requests.Store(0)
+			arrived = make(chan struct{})
+			cancelled = make(chan struct{})
+
+			p, err := New(WithTarget(slow.URL), WithHedging(time.Minute, tc.target))
+			require.NoError(t, err)
+
+			e := event.New()
+			e.SetID("1")
+			e.SetSource("/source")
+			e.SetType("type")
+			require.NoError(t, e.SetData(event.ApplicationJSON, "hello"))
+
+			// The hedge is sent once the delay expired on the clock of
+			// the context.
+			clock := cecontext.NewFakeClock(time.Now())
+			results := make(chan protocol.Result)
+			go func() {
+				results <- p.Send(cecontext.WithClock(context.Background(), clock), binding.ToMessage(&e))
+			}()
+			<-arrived
+			require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+			clock.Advance(time.Minute)
+
+			result := <-results
+			require.True(t, protocol.IsACK(result))
+			var httpResult *Result
+			require.True(t, protocol.ResultAs(result, &httpResult))
+			require.Equal(t, tc.wantStatus, httpResult.StatusCode)
+
+			// The slow request is cancelled.
+			select {
+			case <-cancelled:
+			case <-time.After(time.Second):
+				t.Fatal("the slow request has not been cancelled")
+			}
+		})
+	}
+}
+
+func TestHedgingFastRequest(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	p, err := New(WithTarget(srv.URL), WithHedging(time.Second, ""))
+	require.NoError(t, err)
+
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	require.True(t, protocol.IsACK(p.Send(context.Background(), binding.ToMessage(&e))))
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestWithHedgingInvalid(t *testing.T) {
+	_, err := New(WithHedging(0, ""))
+	require.EqualError(t, err, "http hedging delay must be positive")
+}
@@ -7,6 +7,7 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -15,7 +16,10 @@ import (
 	"github.com/cloudevents/sdk-go/v2/protocol"
 )
 
-var _ protocol.Opener = (*Protocol)(nil)
+var (
+	_ protocol.Opener = (*Protocol)(nil)
+	_ protocol.Pinger = (*Protocol)(nil)
+)
 
 func (p *Protocol) OpenInbound(ctx context.Context) error {
 	p.reMu.Lock()
@@ -90,6 +94,56 @@ func (p *Protocol) OpenInbound(ctx context.Context) error {
 	}
 }
 
+// Ping checks that the delivery target, or each of the endpoints configured
+// with WithTargets, is reachable by sending it an OPTIONS request, as for the
+// HTTP WebHook validation handshake, with the headers of the request template
+// and of ctx. Any response is accepted but 401 Unauthorized and 403
+// Forbidden, which are returned as a *Result. With WithResolver, the
+// endpoints are resolved first if they are due, and Ping fails if none is
+// resolved. Ping does nothing without a delivery target.
+func (p *Protocol) Ping(ctx context.Context) error {
+	if p.resolution != nil {
+		if err := p.resolution.refresh(ctx, p.targets); err != nil {
+			return fmt.Errorf("ping failed: no targets are resolved: %w", err)
+		}
+	}
+	if p.targets != nil {
+		endpoints := p.targets.snapshot()
+		if len(endpoints) == 0 {
+			return errors.New("ping failed: no targets are resolved")
+		}
+		for _, e := range endpoints {
+			req := p.makeRequest(ctx)
+			req.URL = e.url
+			if err := p.ping(req); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	req := p.makeRequest(ctx)
+	if req.URL == nil {
+		return nil
+	}
+	return p.ping(req)
+}
+
+func (p *Protocol) ping(req *http.Request) error {
+	if p.Client == nil {
+		return fmt.Errorf("not initialized: %#v", p)
+	}
+	req.Method = http.MethodOptions
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ping of %s failed: %w", req.URL.Redacted(), err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return NewResult(resp.StatusCode, "ping of %s rejected", req.URL.Redacted())
+	}
+	return nil
+}
+
 // GetListeningPort returns the listening port.
 // Returns -1 if it's not listening.
 func (p *Protocol) GetListeningPort() int {
@@ -13,8 +13,6 @@ import (
 	"net/http"
 	"time"
 
-	"go.uber.org/zap"
-
 	"github.com/cloudevents/sdk-go/v2/binding"
 	cecontext "github.com/cloudevents/sdk-go/v2/context"
 	"github.com/cloudevents/sdk-go/v2/protocol"
@@ -22,6 +20,7 @@ import (
 
 func (p *Protocol) do(ctx context.Context, req *http.Request) (binding.Message, error) {
 	params := cecontext.RetriesFrom(ctx)
+	params.Budget.Deposit()
 
 	switch params.Strategy {
 	case cecontext.BackoffStrategyConstant, cecontext.BackoffStrategyLinear, cecontext.BackoffStrategyExponential:
@@ -34,7 +33,9 @@ func (p *Protocol) do(ctx context.Context, req *http.Request) (binding.Message,
 }
 
 func (p *Protocol) doOnce(req *http.Request) (binding.Message, protocol.Result) {
-	resp, err := p.Client.Do(req)
+	e := p.selectEndpoint(req)
+	resp, err := p.roundTrip(req)
+	p.reportEndpoint(req.Context(), e, resp, err)
 	if err != nil {
 		return nil, protocol.NewReceipt(false, "%w", err)
 	}
@@ -46,11 +47,18 @@ func (p *Protocol) doOnce(req *http.Request) (binding.Message, protocol.Result)
 		result = protocol.ResultNACK
 	}
 
-	return NewMessage(resp.Header, resp.Body), NewResult(resp.StatusCode, "%w", result)
+	res := &Result{
+		StatusCode: resp.StatusCode,
+		Format:     "%w",
+		Args:       []interface{}{result},
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), cecontext.ClockFrom(req.Context()).Now()),
+	}
+	return NewMessage(resp.Header, resp.Body), res
 }
 
 func (p *Protocol) doWithRetry(ctx context.Context, params *cecontext.RetryParams, req *http.Request) (binding.Message, error) {
-	start := time.Now()
+	clock := cecontext.ClockFrom(ctx)
+	start := clock.Now()
 	retry := 0
 	results := make([]protocol.Result, 0)
 
@@ -62,7 +70,7 @@ func (p *Protocol) doWithRetry(ctx context.Context, params *cecontext.RetryParam
 	if req != nil && req.Body != nil {
 		defer func() {
 			if err = req.Body.Close(); err != nil {
-				cecontext.LoggerFrom(ctx).Warnw("could not close request body", zap.Error(err))
+				cecontext.LoggerFrom(ctx).Warnw("could not close request body", "error", err)
 			}
 		}()
 		body, err = io.ReadAll(req.Body)
@@ -77,7 +85,7 @@ func (p *Protocol) doWithRetry(ctx context.Context, params *cecontext.RetryParam
 
 		// Fast track common case.
 		if protocol.IsACK(result) {
-			return msg, NewRetriesResult(result, retry, start, results)
+			return msg, newRetriesResult(result, retry, clock.Now().Sub(start), results)
 		}
 
 		var httpResult *Result
@@ -85,17 +93,23 @@ func (p *Protocol) doWithRetry(ctx context.Context, params *cecontext.RetryParam
 			sc := httpResult.StatusCode
 			if !p.isRetriableFunc(sc) {
 				cecontext.LoggerFrom(ctx).Debugw("status code not retryable, will not try again",
-					zap.Error(httpResult),
-					zap.Int("statusCode", sc))
-				return msg, NewRetriesResult(result, retry, start, results)
+					"error", httpResult,
+					"statusCode", sc)
+				return msg, newRetriesResult(result, retry, clock.Now().Sub(start), results)
 			}
 		}
 
 		// total tries = retry + 1
 		if err = params.Backoff(ctx, retry+1); err != nil {
 			// do not try again.
-			cecontext.LoggerFrom(ctx).Debugw("backoff error, will not try again", zap.Error(err))
-			return msg, NewRetriesResult(result, retry, start, results)
+			cecontext.LoggerFrom(ctx).Debugw("backoff error, will not try again", "error", err)
+			return msg, newRetriesResult(result, retry, clock.Now().Sub(start), results)
+		}
+
+		// The recipient may ask for a longer delay than the backoff strategy.
+		if err = p.waitRetryAfter(ctx, result, params.BackoffFor(retry+1)); err != nil {
+			cecontext.LoggerFrom(ctx).Debugw("retry-after wait error, will not try again", "error", err)
+			return msg, newRetriesResult(result, retry, clock.Now().Sub(start), results)
 		}
 
 		retry++
@@ -108,6 +122,33 @@ func (p *Protocol) doWithRetry(ctx context.Context, params *cecontext.RetryParam
 	}
 }
 
+// waitRetryAfter blocks for the remaining delay the recipient requested with
+// the Retry-After header, once the backoff period already waited has elapsed.
+// It is a no-op unless WithRetryAfter has been configured.
+func (p *Protocol) waitRetryAfter(ctx context.Context, result protocol.Result, waited time.Duration) error {
+	if p.maxRetryAfter <= 0 {
+		return nil
+	}
+	retryAfter, ok := RetryAfterFrom(result)
+	if !ok {
+		return nil
+	}
+	if retryAfter > p.maxRetryAfter {
+		retryAfter = p.maxRetryAfter
+	}
+	if retryAfter <= waited {
+		return nil
+	}
+	timer := cecontext.ClockFrom(ctx).NewTimer(retryAfter - waited)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return errors.New("context has been cancelled")
+	case <-timer.C():
+		return nil
+	}
+}
+
 // reset body to allow it to be read multiple times, e.g. when retrying http
 // requests
 func resetBody(req *http.Request, body []byte) {
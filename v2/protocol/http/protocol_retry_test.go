@@ -224,3 +224,35 @@ func newEvent(t *testing.T, encoding string, body interface{}) event.Event {
 
 	return e
 }
+
+func TestRequestWithRetryBudget(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	p, err := New(WithTarget(srv.URL))
+	require.NoError(t, err)
+
+	budget, err := cecontext.NewRetryBudget(0.5, 1)
+	require.NoError(t, err)
+	ctx := cecontext.WithRetryBudget(cecontext.WithRetriesConstantBackoff(context.Background(), time.Nanosecond, 5), budget)
+
+	// The initial token allows a single retry.
+	e := newEvent(t, "", nil)
+	result := p.Send(ctx, binding.ToMessage(&e))
+	require.True(t, protocol.IsNACK(result))
+	require.Equal(t, 2, requests)
+
+	// A single send does not deposit enough for a retry.
+	result = p.Send(ctx, binding.ToMessage(&e))
+	require.True(t, protocol.IsNACK(result))
+	require.Equal(t, 3, requests)
+
+	// Two sends do.
+	result = p.Send(ctx, binding.ToMessage(&e))
+	require.True(t, protocol.IsNACK(result))
+	require.Equal(t, 5, requests)
+}
@@ -6,10 +6,12 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -21,7 +23,10 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/compression"
+	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
 )
 
 func TestNew(t *testing.T) {
@@ -415,3 +420,197 @@ func TestDefaultIsRetriable(t *testing.T) {
 		})
 	}
 }
+
+func TestReceiveBatch(t *testing.T) {
+	ctx := context.Background()
+	events := []event.Event{test.MinEvent(), test.FullEvent(), test.MinEvent()}
+	for i := range events {
+		events[i].SetID(strconv.Itoa(i))
+	}
+
+	testCases := map[string]struct {
+		results    []error
+		wantStatus int
+	}{
+		"all acknowledged": {
+			results:    []error{nil, protocol.ResultACK, nil},
+			wantStatus: http.StatusOK,
+		},
+		"one rejected": {
+			results:    []error{nil, NewResult(http.StatusBadRequest, "invalid"), protocol.ResultNACK},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := New()
+			require.NoError(t, err)
+
+			req, err := NewHTTPRequestFromEvents(ctx, "http://unittest", events)
+			require.NoError(t, err)
+			rec := httptest.NewRecorder()
+			served := make(chan struct{})
+			go func() {
+				p.ServeHTTP(rec, req)
+				close(served)
+			}()
+
+			msgs, err := p.ReceiveBatch(ctx)
+			require.NoError(t, err)
+			require.Len(t, msgs, len(events))
+			for i, m := range msgs {
+				e, err := binding.ToEvent(ctx, m)
+				require.NoError(t, err)
+				require.Equal(t, events[i].ID(), e.ID())
+			}
+
+			// The request is answered once all the messages are finished.
+			for i := len(msgs) - 1; i >= 0; i-- {
+				select {
+				case <-served:
+					t.Fatal("request answered before all the messages are finished")
+				default:
+				}
+				require.NoError(t, msgs[i].Finish(tc.results[i]))
+			}
+			<-served
+			require.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestReceiveBatchSingleEvent(t *testing.T) {
+	ctx := context.Background()
+	p, err := New()
+	require.NoError(t, err)
+
+	e := test.FullEvent()
+	req := httptest.NewRequest("POST", "http://unittest", nil)
+	require.NoError(t, WriteRequest(ctx, binding.ToMessage(&e), req))
+	rec := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		p.ServeHTTP(rec, req)
+		close(served)
+	}()
+
+	msgs, err := p.ReceiveBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	got, err := binding.ToEvent(ctx, msgs[0])
+	require.NoError(t, err)
+	require.Equal(t, e.ID(), got.ID())
+	require.NoError(t, msgs[0].Finish(protocol.ResultNACK))
+	<-served
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestServeHTTPMaxRequestBytes(t *testing.T) {
+	p, err := New(WithMaxRequestBytes(100), WithRequestDecompression(compression.Gzip))
+	require.NoError(t, err)
+
+	// The Content-Length is checked before reading the body.
+	req := httptest.NewRequest(http.MethodPost, "http://unittest", bytes.NewReader(make([]byte, 101)))
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// Compressed bodies are limited once decoded.
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	require.NoError(t, e.SetData(event.TextPlain, strings.Repeat("a", 1000)))
+	req = compressedEventRequest(t, "gzip", e)
+	rec = httptest.NewRecorder()
+	go p.ServeHTTP(rec, req)
+
+	m, fn, err := p.Respond(context.Background())
+	require.NoError(t, err)
+	_, err = binding.ToEvent(context.Background(), m)
+	require.Error(t, err)
+	require.NoError(t, fn(context.Background(), nil, protocol.NewReceipt(false, "failed to convert Message to Event: %w", err)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestServeHTTPResultStatus(t *testing.T) {
+	testCases := map[string]struct {
+		result protocol.Result
+		want   int
+	}{
+		"rate limited": {result: protocol.NewReceipt(false, "tenant %q: %w", "acme", protocol.ErrRateLimited), want: http.StatusTooManyRequests},
+		"forbidden":    {result: protocol.NewReceipt(false, "event not authorized: %w", protocol.ErrForbidden), want: http.StatusForbidden},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			p, err := New()
+			require.NoError(t, err)
+
+			e := event.New()
+			e.SetID("1")
+			e.SetSource("/source")
+			e.SetType("type")
+			req := httptest.NewRequest(http.MethodPost, "http://unittest", nil)
+			require.NoError(t, WriteRequest(context.Background(), binding.ToMessage(&e), req))
+			rec := httptest.NewRecorder()
+			go p.ServeHTTP(rec, req)
+
+			_, fn, err := p.Respond(context.Background())
+			require.NoError(t, err)
+			require.NoError(t, fn(context.Background(), nil, tc.result))
+			require.Equal(t, tc.want, rec.Code)
+		})
+	}
+}
+
+func TestPing(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer server.Close()
+
+	p, err := New(WithTarget(server.URL), WithHeader("Authorization", "Bearer token"))
+	require.NoError(t, err)
+	require.NoError(t, p.Ping(context.Background()))
+	require.Equal(t, []string{http.MethodOptions}, methods)
+
+	p, err = New(WithTarget(server.URL))
+	require.NoError(t, err)
+	err = p.Ping(context.Background())
+	var result *Result
+	require.ErrorAs(t, err, &result)
+	require.Equal(t, http.StatusUnauthorized, result.StatusCode)
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	p, err = New(WithTargets(RoundRobin, Endpoint{URL: down.URL}, Endpoint{URL: server.URL}))
+	require.NoError(t, err)
+	require.Error(t, p.Ping(context.Background()))
+
+	// The resolved endpoints are checked, and none is an error.
+	var resolved []Endpoint
+	p, err = New(WithHeader("Authorization", "Bearer token"), WithResolver(RoundRobin, ResolverFunc(func(ctx context.Context) ([]Endpoint, error) {
+		return resolved, nil
+	}), time.Minute))
+	require.NoError(t, err)
+	require.ErrorContains(t, p.Ping(context.Background()), "no targets are resolved")
+	resolved = []Endpoint{{URL: server.URL}}
+	p, err = New(WithHeader("Authorization", "Bearer token"), WithResolver(RoundRobin, ResolverFunc(func(ctx context.Context) ([]Endpoint, error) {
+		return resolved, nil
+	}), time.Minute))
+	require.NoError(t, err)
+	methods = nil
+	require.NoError(t, p.Ping(context.Background()))
+	require.Equal(t, []string{http.MethodOptions}, methods)
+
+	// Without delivery target, there is nothing to check.
+	p, err = New()
+	require.NoError(t, err)
+	require.NoError(t, p.Ping(context.Background()))
+}
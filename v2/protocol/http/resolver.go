@@ -0,0 +1,153 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+// Resolver discovers the endpoints the deliveries are spread over, see
+// WithResolver.
+type Resolver interface {
+	// Resolve returns the current endpoints.
+	Resolve(ctx context.Context) ([]Endpoint, error)
+}
+
+// ResolverFunc is a Resolver calling the function, e.g. to discover the
+// endpoints with a service registry.
+type ResolverFunc func(ctx context.Context) ([]Endpoint, error)
+
+// Resolve implements Resolver.Resolve.
+func (f ResolverFunc) Resolve(ctx context.Context) ([]Endpoint, error) {
+	return f(ctx)
+}
+
+// SRVResolver resolves the endpoints from the DNS SRV records of a service,
+// as defined by RFC 2782. The priority and the weight of the records are
+// those of the endpoints, for the Failover and Weighted strategies.
+type SRVResolver struct {
+	// Service, Proto and Name are looked up as _Service._Proto.Name, e.g.
+	// _events._tcp.example.com. If Service and Proto are empty, Name is
+	// looked up directly.
+	Service string
+	Proto   string
+	Name    string
+	// Scheme of the endpoint URLs, "https" by default.
+	Scheme string
+	// Path of the endpoint URLs.
+	Path string
+	// Resolver looks up the records, net.DefaultResolver by default.
+	Resolver *net.Resolver
+}
+
+// Resolve implements Resolver.Resolve.
+func (r *SRVResolver) Resolve(ctx context.Context) ([]Endpoint, error) {
+	resolver := r.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	_, records, err := resolver.LookupSRV(ctx, r.Service, r.Proto, r.Name)
+	if err != nil {
+		return nil, err
+	}
+	return r.endpoints(records), nil
+}
+
+func (r *SRVResolver) endpoints(records []*net.SRV) []Endpoint {
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	endpoints := make([]Endpoint, 0, len(records))
+	for _, srv := range records {
+		host := strings.TrimSuffix(srv.Target, ".")
+		if host == "" {
+			// A "." target means the service is not available.
+			continue
+		}
+		u := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(int(srv.Port))), Path: r.Path}
+		endpoints = append(endpoints, Endpoint{URL: u.String(), Weight: int(srv.Weight), Priority: int(srv.Priority)})
+	}
+	return endpoints
+}
+
+// resolution refreshes the targets with a Resolver.
+type resolution struct {
+	resolver Resolver
+	interval time.Duration
+
+	mu         sync.Mutex
+	resolvedAt time.Time
+}
+
+// refresh resolves the endpoints of t if they were never resolved, or were
+// resolved more than interval ago. Once they have been resolved, a failed
+// resolution is logged and the previous endpoints are kept until the next
+// interval. The interval is timed with the clock of ctx.
+func (r *resolution) refresh(ctx context.Context, t *targets) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := cecontext.ClockFrom(ctx).Now()
+	if !r.resolvedAt.IsZero() && now.Sub(r.resolvedAt) < r.interval {
+		return nil
+	}
+
+	endpoints, err := r.resolver.Resolve(ctx)
+	if err == nil && len(endpoints) == 0 {
+		err = errors.New("no endpoint resolved")
+	}
+	var eps []*endpoint
+	if err == nil {
+		eps, err = newEndpoints(endpoints)
+	}
+	if err != nil {
+		if r.resolvedAt.IsZero() {
+			return fmt.Errorf("failed to resolve the targets: %w", err)
+		}
+		cecontext.LoggerFrom(ctx).Warnw("failed to resolve the targets, keeping the previous ones", "error", err)
+		r.resolvedAt = now
+		return nil
+	}
+	t.update(eps)
+	r.resolvedAt = now
+	return nil
+}
+
+// WithResolver spreads the deliveries over the endpoints discovered by
+// resolver, e.g. a SRVResolver, as WithTargets does for a fixed set of
+// endpoints. The endpoints are resolved on the first delivery, then again by
+// the first delivery following each interval. The sends fail until the
+// endpoints have been resolved once; later failures keep the previous ones.
+// The endpoints which are resolved again keep their health.
+func WithResolver(strategy TargetStrategy, resolver Resolver, interval time.Duration) Option {
+	return func(p *Protocol) error {
+		if p == nil {
+			return fmt.Errorf("http resolver option can not set nil protocol")
+		}
+		if resolver == nil {
+			return fmt.Errorf("http resolver option was given a nil resolver")
+		}
+		if interval <= 0 {
+			return fmt.Errorf("http resolver option was given a non positive interval: %v", interval)
+		}
+		if strategy < RoundRobin || strategy > ConsistentHash {
+			return fmt.Errorf("http resolver option was given an unknown strategy %d", strategy)
+		}
+		p.targets = newTargets(strategy, nil)
+		p.resolution = &resolution{resolver: resolver, interval: interval}
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestSRVResolverEndpoints(t *testing.T) {
+	r := &SRVResolver{Service: "events", Proto: "tcp", Name: "example.com", Path: "/events"}
+	got := r.endpoints([]*net.SRV{
+		{Target: "a.example.com.", Port: 8443, Priority: 10, Weight: 60},
+		{Target: "b.example.com.", Port: 8443, Priority: 20, Weight: 0},
+		{Target: ".", Port: 0},
+	})
+	require.Equal(t, []Endpoint{
+		{URL: "https://a.example.com:8443/events", Weight: 60, Priority: 10},
+		{URL: "https://b.example.com:8443/events", Weight: 0, Priority: 20},
+	}, got)
+
+	r.Scheme = "http"
+	require.Equal(t, "http://a.example.com:80/events", r.endpoints([]*net.SRV{{Target: "a.example.com", Port: 80}})[0].URL)
+}
+
+func TestSendWithResolver(t *testing.T) {
+	var hitsA, hitsB atomic.Int32
+	a := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) { hitsA.Add(1) }))
+	defer a.Close()
+	b := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) { hitsB.Add(1) }))
+	defer b.Close()
+
+	var resolved []Endpoint
+	var resolveErr error
+	var resolutions int
+	resolver := ResolverFunc(func(ctx context.Context) ([]Endpoint, error) {
+		resolutions++
+		return resolved, resolveErr
+	})
+	p, err := New(WithResolver(RoundRobin, resolver, time.Minute))
+	require.NoError(t, err)
+	clock := cecontext.NewFakeClock(time.Unix(1700000000, 0))
+	ctx := cecontext.WithClock(context.Background(), clock)
+
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	send := func() error {
+		return p.Send(ctx, binding.ToMessage(&e))
+	}
+
+	// The sends fail until the endpoints are resolved.
+	resolveErr = errors.New("dns failure")
+	require.EqualError(t, send(), "failed to resolve the targets: dns failure")
+	resolveErr = nil
+	require.EqualError(t, send(), "failed to resolve the targets: no endpoint resolved")
+
+	resolved = []Endpoint{{URL: a.URL}, {URL: b.URL}}
+	for i := 0; i < 4; i++ {
+		require.True(t, protocol.IsACK(send()))
+	}
+	require.Equal(t, int32(2), hitsA.Load())
+	require.Equal(t, int32(2), hitsB.Load())
+	require.Equal(t, 3, resolutions)
+
+	// A failed resolution keeps the previous endpoints until the next
+	// interval.
+	clock.Advance(time.Minute)
+	resolveErr = errors.New("dns failure")
+	require.True(t, protocol.IsACK(send()))
+	require.Equal(t, 4, resolutions)
+	resolveErr = nil
+	resolved = []Endpoint{{URL: b.URL}}
+	require.True(t, protocol.IsACK(send()))
+	require.Equal(t, 4, resolutions)
+
+	clock.Advance(time.Minute)
+	for i := 0; i < 2; i++ {
+		require.True(t, protocol.IsACK(send()))
+	}
+	require.Equal(t, 5, resolutions)
+	require.Equal(t, int32(5), hitsB.Load())
+}
+
+func TestTargetsUpdateKeepsHealth(t *testing.T) {
+	ts := newTargets(RoundRobin, testEndpoints(Endpoint{URL: "a"}, Endpoint{URL: "b"}))
+	ts.markUnhealthy(context.Background(), ts.endpoints[0])
+
+	ts.update(testEndpoints(Endpoint{URL: "a"}, Endpoint{URL: "b"}, Endpoint{URL: "c"}))
+	require.Equal(t, []string{"b", "c", "b"}, pickN(context.Background(), ts, 3))
+}
+
+func TestWithResolverInvalid(t *testing.T) {
+	resolver := ResolverFunc(func(ctx context.Context) ([]Endpoint, error) { return nil, nil })
+	_, err := New(WithResolver(RoundRobin, nil, time.Minute))
+	require.EqualError(t, err, "http resolver option was given a nil resolver")
+	_, err = New(WithResolver(RoundRobin, resolver, 0))
+	require.EqualError(t, err, "http resolver option was given a non positive interval: 0s")
+	_, err = New(WithResolver(TargetStrategy(42), resolver, time.Minute))
+	require.EqualError(t, err, "http resolver option was given an unknown strategy 42")
+}
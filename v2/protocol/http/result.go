@@ -8,6 +8,7 @@ package http
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/cloudevents/sdk-go/v2/protocol"
 )
@@ -27,6 +28,10 @@ type Result struct {
 	StatusCode int
 	Format     string
 	Args       []interface{}
+
+	// RetryAfter is the delay requested by the recipient through the
+	// Retry-After response header. Zero if the header was absent or invalid.
+	RetryAfter time.Duration
 }
 
 // make sure Result implements error.
@@ -53,6 +58,32 @@ func (e *Result) Is(target error) bool {
 	return false
 }
 
+// Retriable implements protocol.RetriableResult: the status codes retried by
+// default by the Protocol are retriable, see WithIsRetriableFunc.
+func (e *Result) Retriable() bool {
+	if e == nil || e.StatusCode/100 == 2 {
+		return false
+	}
+	return defaultIsRetriableFunc(e.StatusCode)
+}
+
+// RetryDelay implements protocol.RetryAfterResult with the Retry-After
+// response header.
+func (e *Result) RetryDelay() time.Duration {
+	if e == nil {
+		return 0
+	}
+	return e.RetryAfter
+}
+
+// Status implements protocol.StatusCodeResult with the HTTP status code.
+func (e *Result) Status() int {
+	if e == nil {
+		return 0
+	}
+	return e.StatusCode
+}
+
 // Error returns the string that is formed by using the format string with the
 // provided args.
 func (e *Result) Error() string {
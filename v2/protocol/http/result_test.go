@@ -9,6 +9,7 @@ import (
 	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -185,3 +186,36 @@ func TestNew_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestResult_Details(t *testing.T) {
+	{
+		result := NewRetriesResult(&Result{StatusCode: 503, Format: "%w", Args: []interface{}{protocol.ResultNACK}, RetryAfter: 2 * time.Second}, 1, time.Now(), nil)
+		if !protocol.IsRetriable(result) {
+			t.Error("Expected 503 to be retriable")
+		}
+		if d, ok := protocol.RetryAfter(result); !ok || d != 2*time.Second {
+			t.Errorf("Expected Retry-After 2s, got %v", d)
+		}
+		if code, ok := protocol.StatusCode(result); !ok || code != 503 {
+			t.Errorf("Expected status code 503, got %d", code)
+		}
+	}
+	{
+		result := NewResult(400, "%w", protocol.ResultNACK)
+		if protocol.IsRetriable(result) {
+			t.Error("Expected 400 not to be retriable")
+		}
+		if _, ok := protocol.RetryAfter(result); ok {
+			t.Error("Expected no Retry-After")
+		}
+	}
+	{
+		var result *Result
+		if protocol.IsRetriable(result) {
+			t.Error("Expected nil result not to be retriable")
+		}
+		if _, ok := protocol.StatusCode(result); ok {
+			t.Error("Expected nil result to have no status code")
+		}
+	}
+}
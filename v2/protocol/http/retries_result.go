@@ -15,10 +15,14 @@ import (
 // NewRetriesResult returns a http RetriesResult that should be used as
 // a transport.Result without retries
 func NewRetriesResult(result protocol.Result, retries int, startTime time.Time, attempts []protocol.Result) protocol.Result {
+	return newRetriesResult(result, retries, time.Since(startTime), attempts)
+}
+
+func newRetriesResult(result protocol.Result, retries int, duration time.Duration, attempts []protocol.Result) protocol.Result {
 	rr := &RetriesResult{
 		Result:   result,
 		Retries:  retries,
-		Duration: time.Since(startTime),
+		Duration: duration,
 	}
 	if len(attempts) > 0 {
 		rr.Attempts = attempts
@@ -57,3 +61,9 @@ func (e *RetriesResult) Error() string {
 	}
 	return fmt.Sprintf("%s (%dx)", e.Result.Error(), e.Retries)
 }
+
+// Unwrap returns the last result, so that its details are found by
+// protocol.ResultAs, e.g. its status code.
+func (e *RetriesResult) Unwrap() error {
+	return e.Result
+}
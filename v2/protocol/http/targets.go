@@ -0,0 +1,365 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// TargetStrategy selects the endpoint of each delivery among the targets
+// configured with WithTargets.
+type TargetStrategy int
+
+const (
+	// RoundRobin sends the deliveries to each healthy endpoint in turn.
+	RoundRobin TargetStrategy = iota
+	// Weighted spreads the deliveries over the healthy endpoints in
+	// proportion to their weight.
+	Weighted
+	// Failover sends the deliveries to the healthy endpoints of the lowest
+	// priority, in turn, and only to the endpoints of the next priority when
+	// none is healthy.
+	Failover
+	// ConsistentHash sends the events with the same partitionkey extension to
+	// the same endpoint, as long as it is healthy, and moves only the keys of
+	// an endpoint when it becomes unhealthy. Endpoints get a share of the keys
+	// in proportion to their weight. Events without partitionkey are sent to
+	// each healthy endpoint in turn.
+	ConsistentHash
+)
+
+// partitionKeyExtension is the extension read by the ConsistentHash strategy.
+const partitionKeyExtension = "partitionkey"
+
+// hashRingReplicas is the number of points of an endpoint of weight 1 on the
+// hash ring of the ConsistentHash strategy.
+const hashRingReplicas = 128
+
+const (
+	defaultTargetMaxFailures = 3
+	defaultTargetCooldown    = 30 * time.Second
+)
+
+// Endpoint is a delivery target, see WithTargets.
+type Endpoint struct {
+	// URL of the endpoint.
+	URL string
+	// Weight of the endpoint for the Weighted and ConsistentHash strategies.
+	// Defaults to 1.
+	Weight int
+	// Priority of the endpoint for the Failover strategy, the lowest first.
+	Priority int
+}
+
+// targets holds the endpoints of a Protocol and their health.
+//
+// An endpoint is unhealthy once maxFailures deliveries in a row failed to
+// reach it, or a health check failed, and is tried again after cooldown.
+// When no endpoint is healthy, all of them are used. The health is timed
+// with the clock of the context of the deliveries, see cecontext.WithClock.
+type targets struct {
+	strategy    TargetStrategy
+	maxFailures int
+	cooldown    time.Duration
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+
+	// ring is the sorted hash ring of the ConsistentHash strategy.
+	ring []ringPoint
+}
+
+type ringPoint struct {
+	hash     uint32
+	endpoint *endpoint
+}
+
+type endpoint struct {
+	url      *url.URL
+	weight   int
+	priority int
+
+	// Guarded by targets.mu.
+	failures       int
+	unhealthyUntil time.Time
+	// current is the running weight of the smooth weighted round robin.
+	current int
+}
+
+func newTargets(strategy TargetStrategy, endpoints []*endpoint) *targets {
+	t := &targets{
+		strategy:    strategy,
+		maxFailures: defaultTargetMaxFailures,
+		cooldown:    defaultTargetCooldown,
+	}
+	t.setEndpoints(endpoints)
+	return t
+}
+
+// newEndpoints parses endpoints.
+func newEndpoints(endpoints []Endpoint) ([]*endpoint, error) {
+	eps := make([]*endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		targetURL, err := url.Parse(strings.TrimSpace(e.URL))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse target url: %s", err.Error())
+		}
+		if e.Weight < 0 {
+			return nil, fmt.Errorf("was given a negative weight for %s", e.URL)
+		}
+		weight := e.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		eps = append(eps, &endpoint{url: targetURL, weight: weight, priority: e.Priority})
+	}
+	return eps, nil
+}
+
+// setEndpoints replaces the endpoints, and builds the hash ring of the
+// ConsistentHash strategy. t.mu must be held once t is shared.
+func (t *targets) setEndpoints(endpoints []*endpoint) {
+	t.endpoints = endpoints
+	t.ring = nil
+	if t.strategy == ConsistentHash {
+		for _, e := range endpoints {
+			for i := 0; i < hashRingReplicas*e.weight; i++ {
+				t.ring = append(t.ring, ringPoint{hash: hashKey(e.url.String() + "#" + strconv.Itoa(i)), endpoint: e})
+			}
+		}
+		sort.Slice(t.ring, func(i, j int) bool { return t.ring[i].hash < t.ring[j].hash })
+	}
+}
+
+// update replaces the endpoints with the resolved ones. The endpoints which
+// are kept, with the same URL, weight and priority, keep their health.
+func (t *targets) update(endpoints []*endpoint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current := make(map[string]*endpoint, len(t.endpoints))
+	for _, e := range t.endpoints {
+		current[e.url.String()] = e
+	}
+	for i, e := range endpoints {
+		if c, ok := current[e.url.String()]; ok && c.weight == e.weight && c.priority == e.priority {
+			endpoints[i] = c
+		}
+	}
+	t.setEndpoints(endpoints)
+}
+
+// snapshot returns the current endpoints.
+func (t *targets) snapshot() []*endpoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.endpoints
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// pick returns the endpoint of the next delivery of an event with the given
+// partitionkey, empty if none.
+func (t *targets) pick(ctx context.Context, key string) *endpoint {
+	now := cecontext.ClockFrom(ctx).Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.strategy == ConsistentHash && key != "" {
+		return t.pickHashed(key, now)
+	}
+
+	candidates := t.healthy(now)
+	switch t.strategy {
+	case Weighted:
+		return pickWeighted(candidates)
+	case Failover:
+		best := candidates[:0:0]
+		for _, e := range candidates {
+			if len(best) > 0 && e.priority > best[0].priority {
+				continue
+			}
+			if len(best) > 0 && e.priority < best[0].priority {
+				best = best[:0]
+			}
+			best = append(best, e)
+		}
+		candidates = best
+	}
+	e := candidates[t.next%len(candidates)]
+	t.next++
+	return e
+}
+
+// pickHashed returns the first healthy endpoint following the hash of key on
+// the ring, or the first endpoint if none is healthy. t.mu must be held.
+func (t *targets) pickHashed(key string, now time.Time) *endpoint {
+	h := hashKey(key)
+	start := sort.Search(len(t.ring), func(i int) bool { return t.ring[i].hash >= h })
+	for i := 0; i < len(t.ring); i++ {
+		p := t.ring[(start+i)%len(t.ring)]
+		if !now.Before(p.endpoint.unhealthyUntil) {
+			return p.endpoint
+		}
+	}
+	return t.ring[start%len(t.ring)].endpoint
+}
+
+// healthy returns the endpoints healthy at now, or all of them if none is
+// healthy. t.mu must be held.
+func (t *targets) healthy(now time.Time) []*endpoint {
+	healthy := make([]*endpoint, 0, len(t.endpoints))
+	for _, e := range t.endpoints {
+		if !now.Before(e.unhealthyUntil) {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) == 0 {
+		return t.endpoints
+	}
+	return healthy
+}
+
+// pickWeighted implements the smooth weighted round robin: every endpoint
+// gains its weight, and the one with the highest running weight is picked
+// and loses the total weight.
+func pickWeighted(candidates []*endpoint) *endpoint {
+	var best *endpoint
+	total := 0
+	for _, e := range candidates {
+		e.current += e.weight
+		total += e.weight
+		if best == nil || e.current > best.current {
+			best = e
+		}
+	}
+	best.current -= total
+	return best
+}
+
+// report records the outcome of a delivery to e.
+func (t *targets) report(ctx context.Context, e *endpoint, ok bool) {
+	now := cecontext.ClockFrom(ctx).Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if ok {
+		e.failures = 0
+		e.unhealthyUntil = time.Time{}
+		return
+	}
+	e.failures++
+	if e.failures >= t.maxFailures {
+		e.unhealthyUntil = now.Add(t.cooldown)
+	}
+}
+
+// markUnhealthy makes e unhealthy for the cooldown.
+func (t *targets) markUnhealthy(ctx context.Context, e *endpoint) {
+	now := cecontext.ClockFrom(ctx).Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e.failures = t.maxFailures
+	e.unhealthyUntil = now.Add(t.cooldown)
+}
+
+// selectEndpoint sets the URL of req to the endpoint of the delivery, unless
+// the target is set in the context of req or no targets are configured.
+func (p *Protocol) selectEndpoint(req *http.Request) *endpoint {
+	if p.targets == nil || cecontext.TargetFrom(req.Context()) != nil {
+		return nil
+	}
+	key, _ := req.Context().Value(partitionKeyContextKey{}).(string)
+	e := p.targets.pick(req.Context(), key)
+	req.URL = e.url
+	return e
+}
+
+type partitionKeyContextKey struct{}
+
+// withPartitionKey returns a context holding the partitionkey of m for the
+// ConsistentHash strategy, if any.
+func (p *Protocol) withPartitionKey(ctx context.Context, m binding.MessageMetadataReader) context.Context {
+	if p.targets == nil || p.targets.strategy != ConsistentHash {
+		return ctx
+	}
+	v := m.GetExtension(partitionKeyExtension)
+	if v == nil {
+		return ctx
+	}
+	key, err := types.Format(v)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, partitionKeyContextKey{}, key)
+}
+
+// reportEndpoint records the outcome of a delivery to e, if any: transport
+// errors and 5xx responses are failures.
+func (p *Protocol) reportEndpoint(ctx context.Context, e *endpoint, resp *http.Response, err error) {
+	if e == nil {
+		return
+	}
+	p.targets.report(ctx, e, err == nil && resp.StatusCode < 500)
+}
+
+// RunHealthChecks checks the health of the endpoints configured with
+// WithTargets every interval, until ctx is done. An endpoint is healthy if it
+// answers an OPTIONS request, as sent for the HTTP WebHook validation
+// handshake, with a 2xx status code. Unhealthy endpoints are not used until
+// their cooldown elapses, or a later check succeeds. The checks are timed
+// with the clock of ctx, see cecontext.WithClock.
+func (p *Protocol) RunHealthChecks(ctx context.Context, interval time.Duration) error {
+	if p.targets == nil {
+		return nil
+	}
+	clock := cecontext.ClockFrom(ctx)
+	for {
+		for _, e := range p.targets.snapshot() {
+			if p.checkEndpoint(ctx, e) {
+				p.targets.report(ctx, e, true)
+			} else if ctx.Err() == nil {
+				p.targets.markUnhealthy(ctx, e)
+			}
+		}
+		timer := clock.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+func (p *Protocol) checkEndpoint(ctx context.Context, e *endpoint) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodOptions, e.url.String(), nil)
+	if err != nil {
+		return false
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode/100 == 2
+}
@@ -0,0 +1,269 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func testEndpoints(specs ...Endpoint) []*endpoint {
+	eps := make([]*endpoint, 0, len(specs))
+	for _, s := range specs {
+		u, _ := url.Parse(s.URL)
+		if s.Weight == 0 {
+			s.Weight = 1
+		}
+		eps = append(eps, &endpoint{url: u, weight: s.Weight, priority: s.Priority})
+	}
+	return eps
+}
+
+func pickN(ctx context.Context, t *targets, n int) []string {
+	picked := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		picked = append(picked, t.pick(ctx, "").url.String())
+	}
+	return picked
+}
+
+func TestTargetsPick(t *testing.T) {
+	testCases := map[string]struct {
+		strategy  TargetStrategy
+		endpoints []Endpoint
+		unhealthy []int
+		want      []string
+	}{
+		"round robin": {
+			strategy:  RoundRobin,
+			endpoints: []Endpoint{{URL: "a"}, {URL: "b"}, {URL: "c"}},
+			want:      []string{"a", "b", "c", "a"},
+		},
+		"round robin skips unhealthy": {
+			strategy:  RoundRobin,
+			endpoints: []Endpoint{{URL: "a"}, {URL: "b"}, {URL: "c"}},
+			unhealthy: []int{1},
+			want:      []string{"a", "c", "a", "c"},
+		},
+		"all unhealthy": {
+			strategy:  RoundRobin,
+			endpoints: []Endpoint{{URL: "a"}, {URL: "b"}},
+			unhealthy: []int{0, 1},
+			want:      []string{"a", "b", "a"},
+		},
+		"weighted": {
+			strategy:  Weighted,
+			endpoints: []Endpoint{{URL: "a", Weight: 3}, {URL: "b"}},
+			want:      []string{"a", "a", "b", "a", "a", "a", "b", "a"},
+		},
+		"failover": {
+			strategy:  Failover,
+			endpoints: []Endpoint{{URL: "backup", Priority: 1}, {URL: "a"}, {URL: "b"}},
+			want:      []string{"a", "b", "a"},
+		},
+		"failover to backup": {
+			strategy:  Failover,
+			endpoints: []Endpoint{{URL: "backup", Priority: 1}, {URL: "a"}, {URL: "b"}},
+			unhealthy: []int{1, 2},
+			want:      []string{"backup", "backup"},
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			ts := newTargets(tc.strategy, testEndpoints(tc.endpoints...))
+			for _, i := range tc.unhealthy {
+				ts.markUnhealthy(context.Background(), ts.endpoints[i])
+			}
+			require.Equal(t, tc.want, pickN(context.Background(), ts, len(tc.want)))
+		})
+	}
+}
+
+func TestTargetsEjection(t *testing.T) {
+	clock := cecontext.NewFakeClock(time.Unix(1700000000, 0))
+	ctx := cecontext.WithClock(context.Background(), clock)
+	ts := newTargets(RoundRobin, testEndpoints(Endpoint{URL: "a"}, Endpoint{URL: "b"}))
+	ts.maxFailures = 2
+	ts.cooldown = time.Minute
+	a := ts.endpoints[0]
+
+	ts.report(ctx, a, false)
+	require.Equal(t, []string{"a", "b"}, pickN(ctx, ts, 2))
+
+	// A success resets the failures.
+	ts.report(ctx, a, true)
+	ts.report(ctx, a, false)
+	require.Equal(t, []string{"a", "b"}, pickN(ctx, ts, 2))
+
+	ts.report(ctx, a, false)
+	require.Equal(t, []string{"b", "b"}, pickN(ctx, ts, 2))
+	clock.Advance(time.Minute - time.Second)
+	require.Equal(t, []string{"b", "b"}, pickN(ctx, ts, 2))
+
+	// The endpoint is tried again after the cooldown.
+	clock.Advance(time.Second)
+	require.ElementsMatch(t, []string{"a", "b"}, pickN(ctx, ts, 2))
+}
+
+func TestSendWithTargets(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer up.Close()
+
+	p, err := New(
+		WithTargets(RoundRobin, Endpoint{URL: down.URL}, Endpoint{URL: up.URL}),
+		WithTargetEjection(1, time.Hour),
+	)
+	require.NoError(t, err)
+
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+
+	// The retry goes to the other endpoint.
+	ctx := cecontext.WithRetriesConstantBackoff(context.Background(), time.Nanosecond, 1)
+	require.True(t, protocol.IsACK(p.Send(ctx, binding.ToMessage(&e))))
+
+	// The failing endpoint is not used anymore.
+	for i := 0; i < 3; i++ {
+		require.True(t, protocol.IsACK(p.Send(context.Background(), binding.ToMessage(&e))))
+	}
+}
+
+func TestRunHealthChecks(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer healthy.Close()
+	var unhealthyStatus atomic.Int32
+	unhealthyStatus.Store(http.StatusMethodNotAllowed)
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(int(unhealthyStatus.Load()))
+	}))
+	defer unhealthy.Close()
+
+	p, err := New(WithTargets(RoundRobin, Endpoint{URL: unhealthy.URL}, Endpoint{URL: healthy.URL}))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, p.RunHealthChecks(ctx, time.Hour), context.Canceled)
+
+	// Checks cancelled by the context do not change the health.
+	require.Equal(t, []string{unhealthy.URL, healthy.URL}, pickN(context.Background(), p.targets, 2))
+
+	// The checks run every interval of the clock of the context.
+	clock := cecontext.NewFakeClock(time.Unix(1700000000, 0))
+	ctx, cancel = context.WithCancel(cecontext.WithClock(context.Background(), clock))
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- p.RunHealthChecks(ctx, time.Second) }()
+	require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	require.Equal(t, []string{healthy.URL, healthy.URL}, pickN(ctx, p.targets, 2))
+
+	// A later successful check makes the endpoint healthy again, before the
+	// end of its cooldown.
+	unhealthyStatus.Store(http.StatusOK)
+	clock.Advance(time.Second)
+	require.Eventually(t, func() bool {
+		p.targets.mu.Lock()
+		defer p.targets.mu.Unlock()
+		return p.targets.endpoints[0].failures == 0
+	}, time.Second, time.Millisecond)
+	require.ElementsMatch(t, []string{unhealthy.URL, healthy.URL}, pickN(ctx, p.targets, 2))
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWithTargetsInvalid(t *testing.T) {
+	_, err := New(WithTargets(RoundRobin))
+	require.EqualError(t, err, "http targets option was given no endpoint")
+	_, err = New(WithTargets(TargetStrategy(42), Endpoint{URL: "http://a"}))
+	require.EqualError(t, err, "http targets option was given an unknown strategy 42")
+	_, err = New(WithTargets(Weighted, Endpoint{URL: "http://a", Weight: -1}))
+	require.EqualError(t, err, "http targets option was given a negative weight for http://a")
+	_, err = New(WithTargetEjection(1, time.Second))
+	require.EqualError(t, err, "http target ejection option requires WithTargets")
+}
+
+func TestTargetsConsistentHash(t *testing.T) {
+	ts := newTargets(ConsistentHash, testEndpoints(Endpoint{URL: "a"}, Endpoint{URL: "b"}, Endpoint{URL: "c"}))
+
+	keys := make([]string, 100)
+	assigned := map[string]string{}
+	counts := map[string]int{}
+	for i := range keys {
+		keys[i] = "entity-" + strconv.Itoa(i)
+		e := ts.pick(context.Background(), keys[i]).url.String()
+		assigned[keys[i]] = e
+		counts[e]++
+	}
+	// The keys are spread over all the endpoints, and stick to them.
+	require.Len(t, counts, 3)
+	for _, key := range keys {
+		require.Equal(t, assigned[key], ts.pick(context.Background(), key).url.String())
+	}
+
+	// Only the keys of an unhealthy endpoint move.
+	ts.markUnhealthy(context.Background(), ts.endpoints[0])
+	for _, key := range keys {
+		got := ts.pick(context.Background(), key).url.String()
+		if assigned[key] == "a" {
+			require.NotEqual(t, "a", got)
+		} else {
+			require.Equal(t, assigned[key], got)
+		}
+	}
+
+	// Events without key are sent in turn to the healthy endpoints.
+	require.Equal(t, []string{"b", "c", "b"}, pickN(context.Background(), ts, 3))
+}
+
+func TestSendWithConsistentHash(t *testing.T) {
+	received := make(chan string, 10)
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			received <- name
+		}))
+	}
+	a := newServer("a")
+	defer a.Close()
+	b := newServer("b")
+	defer b.Close()
+
+	p, err := New(WithTargets(ConsistentHash, Endpoint{URL: a.URL}, Endpoint{URL: b.URL}))
+	require.NoError(t, err)
+
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/source")
+	e.SetType("type")
+	e.SetExtension("partitionkey", "entity")
+
+	require.True(t, protocol.IsACK(p.Send(context.Background(), binding.ToMessage(&e))))
+	first := <-received
+	for i := 0; i < 5; i++ {
+		require.True(t, protocol.IsACK(p.Send(context.Background(), binding.ToMessage(&e))))
+		require.Equal(t, first, <-received)
+	}
+}
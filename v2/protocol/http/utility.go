@@ -61,10 +61,8 @@ func NewHTTPRequestFromEvent(ctx context.Context, url string, event event.Event)
 // a batched set of events. This is an HTTP POST action to the provided url.
 func NewHTTPRequestFromEvents(ctx context.Context, url string, events []event.Event) (*nethttp.Request, error) {
 	// Sending batch events is quite straightforward, as there is only JSON format, so a simple implementation.
-	for _, e := range events {
-		if err := e.Validate(); err != nil {
-			return nil, err
-		}
+	if err := event.Batch(events).Validate(); err != nil {
+		return nil, err
 	}
 	var buffer bytes.Buffer
 	err := json.NewEncoder(&buffer).Encode(events)
@@ -0,0 +1,125 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore is a Store keeping the subscriptions in memory and in a JSON
+// file, rewritten atomically on each change, for the single instance
+// producers which must keep them on restart.
+type FileStore struct {
+	path string
+
+	// mu serializes the writes of the file.
+	mu     sync.Mutex
+	memory *MemoryStore
+}
+
+// NewFileStore returns a FileStore persisting the subscriptions in the file at
+// path, loading those it holds, if it exists.
+func NewFileStore(path string) (*FileStore, error) {
+	f := &FileStore{path: path, memory: NewMemoryStore()}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("webhook: failed to read the subscriptions: %w", err)
+	}
+	var subscriptions []Subscription
+	if err := json.Unmarshal(b, &subscriptions); err != nil {
+		return nil, fmt.Errorf("webhook: failed to decode the subscriptions of %s: %w", path, err)
+	}
+	for _, s := range subscriptions {
+		_ = f.memory.Put(context.Background(), s)
+	}
+	return f, nil
+}
+
+// Put implements Store.
+func (f *FileStore) Put(ctx context.Context, s Subscription) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	previous, getErr := f.memory.Get(ctx, s.ID)
+	_ = f.memory.Put(ctx, s)
+	if err := f.save(ctx); err != nil {
+		// Restore the subscriptions of the file.
+		if getErr != nil {
+			_ = f.memory.Delete(ctx, s.ID)
+		} else {
+			_ = f.memory.Put(ctx, previous)
+		}
+		return err
+	}
+	return nil
+}
+
+// Get implements Store.
+func (f *FileStore) Get(ctx context.Context, id string) (Subscription, error) {
+	return f.memory.Get(ctx, id)
+}
+
+// Delete implements Store.
+func (f *FileStore) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	previous, err := f.memory.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	_ = f.memory.Delete(ctx, id)
+	if err := f.save(ctx); err != nil {
+		_ = f.memory.Put(ctx, previous)
+		return err
+	}
+	return nil
+}
+
+// List implements Store, sorting the subscriptions by creation time.
+func (f *FileStore) List(ctx context.Context) ([]Subscription, error) {
+	return f.memory.List(ctx)
+}
+
+// save writes the subscriptions to a temporary file renamed to the file of
+// the store, so that it is never left half written.
+func (f *FileStore) save(ctx context.Context) error {
+	subscriptions, _ := f.memory.List(ctx)
+	b, err := json.MarshalIndent(subscriptions, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("webhook: failed to save the subscriptions: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("webhook: failed to save the subscriptions: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("webhook: failed to save the subscriptions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("webhook: failed to save the subscriptions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("webhook: failed to save the subscriptions: %w", err)
+	}
+	return nil
+}
+
+var _ Store = (*FileStore)(nil)
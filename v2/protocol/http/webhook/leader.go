@@ -0,0 +1,50 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+)
+
+// LeaderElector elects the leader of the replicas of a producer sharing the
+// Store of their Managers, so that they run highly available: all of them
+// manage the subscriptions and deliver their events, but only the leader
+// sweeps them, see Manager.Sweep.
+type LeaderElector interface {
+	// IsLeader returns true if this replica is the leader. It is called
+	// before each sweep, and may campaign for the leadership.
+	IsLeader(ctx context.Context) (bool, error)
+}
+
+// LeaderElectorFunc is a function implementing LeaderElector, e.g. the hook
+// of the leadership of a Kubernetes lease.
+type LeaderElectorFunc func(ctx context.Context) (bool, error)
+
+// IsLeader implements LeaderElector.
+func (f LeaderElectorFunc) IsLeader(ctx context.Context) (bool, error) {
+	return f(ctx)
+}
+
+// WithLeaderElector makes the Manager sweep the subscriptions only while it
+// is the leader elected by e. By default, it always sweeps them.
+func WithLeaderElector(e LeaderElector) Option {
+	return func(m *Manager) error {
+		if e == nil {
+			return errors.New("webhook leader elector option was given a nil elector")
+		}
+		m.elector = e
+		return nil
+	}
+}
+
+// isLeader returns true if the Manager is the leader, or has no elector.
+func (m *Manager) isLeader(ctx context.Context) (bool, error) {
+	if m.elector == nil {
+		return true, nil
+	}
+	return m.elector.IsLeader(ctx)
+}
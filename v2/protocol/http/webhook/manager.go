@@ -0,0 +1,399 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package webhook implements the producer side of the HTTP WebHook spec:
+// https://github.com/cloudevents/spec/blob/v1.0/http-webhook.md
+//
+// A Manager registers the endpoints of the subscribers after the validation
+// handshake of the abuse protection, persists them in a Store, delivers the
+// events to each of them with retries, at the rate they allowed, and
+// disables those which are gone, fail for too long or have expired.
+//
+// The subscriptions are kept in memory by a MemoryStore, or also in a file by
+// a FileStore. The replicas of a highly available producer share a Store,
+// e.g. the one of github.com/cloudevents/sdk-go/webhook/postgres/v2, and
+// elect the one sweeping the subscriptions with a LeaderElector.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+const (
+	// DefaultMaxFailures is the number of consecutive failed deliveries after
+	// which a subscription can be disabled.
+	DefaultMaxFailures = 10
+	// DefaultMaxFailureDuration is how long the deliveries to a subscription
+	// must have been failing before it can be disabled.
+	DefaultMaxFailureDuration = 24 * time.Hour
+)
+
+// Manager manages the webhook subscriptions of a producer and delivers its
+// events to them.
+type Manager struct {
+	store              Store
+	origin             string
+	httpOptions        []cehttp.Option
+	retry              cecontext.RetryParams
+	maxFailures        int
+	maxFailureDuration time.Duration
+	ttl                time.Duration
+	clock              cecontext.Clock
+	elector            LeaderElector
+
+	// mu serializes the updates of the subscriptions.
+	mu        sync.Mutex
+	protocols sync.Map
+}
+
+// Option configures a Manager.
+type Option func(*Manager) error
+
+// WithHTTPOptions sets the options of the HTTP protocols delivering the
+// events to the endpoints, e.g. cehttp.WithClient.
+func WithHTTPOptions(opts ...cehttp.Option) Option {
+	return func(m *Manager) error {
+		m.httpOptions = append(m.httpOptions, opts...)
+		return nil
+	}
+}
+
+// WithRetry sets the retries of each delivery. By default, deliveries are
+// not retried.
+func WithRetry(params cecontext.RetryParams) Option {
+	return func(m *Manager) error {
+		if params.MaxTries < 0 {
+			return fmt.Errorf("webhook retry option was given a negative number of tries: %d", params.MaxTries)
+		}
+		m.retry = params
+		return nil
+	}
+}
+
+// WithDisableAfter disables the subscriptions once maxFailures consecutive
+// deliveries failed and the first of them is older than maxDuration,
+// DefaultMaxFailures and DefaultMaxFailureDuration by default.
+func WithDisableAfter(maxFailures int, maxDuration time.Duration) Option {
+	return func(m *Manager) error {
+		if maxFailures <= 0 {
+			return fmt.Errorf("webhook disable option was given a non positive number of failures: %d", maxFailures)
+		}
+		if maxDuration < 0 {
+			return fmt.Errorf("webhook disable option was given a negative duration: %v", maxDuration)
+		}
+		m.maxFailures = maxFailures
+		m.maxFailureDuration = maxDuration
+		return nil
+	}
+}
+
+// WithSubscriptionTTL makes the subscriptions expire ttl after they are
+// created or renewed. By default, they do not expire.
+func WithSubscriptionTTL(ttl time.Duration) Option {
+	return func(m *Manager) error {
+		if ttl <= 0 {
+			return fmt.Errorf("webhook subscription ttl option was given a non positive ttl: %v", ttl)
+		}
+		m.ttl = ttl
+		return nil
+	}
+}
+
+// WithClock sets the clock of the expiry, the failure tracking and the
+// retries of the deliveries, cecontext.SystemClock by default.
+func WithClock(clock cecontext.Clock) Option {
+	return func(m *Manager) error {
+		if clock == nil {
+			return errors.New("webhook clock option was given a nil clock")
+		}
+		m.clock = clock
+		return nil
+	}
+}
+
+// NewManager returns a Manager persisting the subscriptions in store, and
+// validating the endpoints on behalf of origin, the WebHook-Request-Origin
+// of the handshakes, e.g. the host name of the producer.
+func NewManager(store Store, origin string, opts ...Option) (*Manager, error) {
+	if store == nil {
+		return nil, errors.New("webhook manager was given a nil store")
+	}
+	if origin == "" {
+		return nil, errors.New("webhook manager was given an empty origin")
+	}
+	m := &Manager{
+		store:              store,
+		origin:             origin,
+		retry:              cecontext.DefaultRetryParams,
+		maxFailures:        DefaultMaxFailures,
+		maxFailureDuration: DefaultMaxFailureDuration,
+		clock:              cecontext.SystemClock,
+	}
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Manager) newProtocol(sink string) (*cehttp.Protocol, error) {
+	opts := append([]cehttp.Option{cehttp.WithTarget(sink)}, m.httpOptions...)
+	return cehttp.New(opts...)
+}
+
+// protocol returns the protocol delivering the events to s, paced to its
+// allowed rate.
+func (m *Manager) protocol(s Subscription) (*cehttp.Protocol, error) {
+	if p, ok := m.protocols.Load(s.ID); ok {
+		return p.(*cehttp.Protocol), nil
+	}
+	p, err := m.newProtocol(s.Sink)
+	if err != nil {
+		return nil, err
+	}
+	p.SetAllowedRate(s.AllowedRate)
+	actual, _ := m.protocols.LoadOrStore(s.ID, p)
+	return actual.(*cehttp.Protocol), nil
+}
+
+// Subscribe validates the endpoint at sink with the handshake of the abuse
+// protection and registers it for the events of the given types, or all if
+// none.
+func (m *Manager) Subscribe(ctx context.Context, sink string, types ...string) (Subscription, error) {
+	u, err := url.Parse(sink)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, fmt.Errorf("invalid webhook sink %q", sink)
+	}
+	p, err := m.newProtocol(sink)
+	if err != nil {
+		return Subscription{}, err
+	}
+	rate, err := p.Handshake(ctx, m.origin)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("webhook %s failed the validation handshake: %w", sink, err)
+	}
+
+	now := m.clock.Now()
+	s := Subscription{
+		ID:          uuid.New().String(),
+		Sink:        sink,
+		Types:       types,
+		Status:      StatusActive,
+		AllowedRate: rate,
+		CreatedAt:   now,
+	}
+	if m.ttl > 0 {
+		s.ExpiresAt = now.Add(m.ttl)
+	}
+	if err := m.store.Put(ctx, s); err != nil {
+		return Subscription{}, err
+	}
+	m.protocols.Store(s.ID, p)
+	return s, nil
+}
+
+// Unsubscribe deletes the subscription with the given ID.
+func (m *Manager) Unsubscribe(ctx context.Context, id string) error {
+	if err := m.store.Delete(ctx, id); err != nil {
+		return err
+	}
+	m.protocols.Delete(id)
+	return nil
+}
+
+// Renew extends the subscription with the given ID by the subscription
+// TTL.
+func (m *Manager) Renew(ctx context.Context, id string) (Subscription, error) {
+	return m.update(ctx, id, func(s *Subscription) {
+		if m.ttl > 0 {
+			s.ExpiresAt = m.clock.Now().Add(m.ttl)
+		}
+	})
+}
+
+// Enable validates the endpoint of a disabled subscription again and
+// reactivates it.
+func (m *Manager) Enable(ctx context.Context, id string) (Subscription, error) {
+	s, err := m.store.Get(ctx, id)
+	if err != nil {
+		return Subscription{}, err
+	}
+	p, err := m.newProtocol(s.Sink)
+	if err != nil {
+		return Subscription{}, err
+	}
+	rate, err := p.Handshake(ctx, m.origin)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("webhook %s failed the validation handshake: %w", s.Sink, err)
+	}
+	s, err = m.update(ctx, id, func(s *Subscription) {
+		s.Status = StatusActive
+		s.Reason = ""
+		s.DisabledAt = time.Time{}
+		s.AllowedRate = rate
+		s.Failures = 0
+		s.FailingSince = time.Time{}
+		if m.ttl > 0 {
+			s.ExpiresAt = m.clock.Now().Add(m.ttl)
+		}
+	})
+	if err == nil {
+		m.protocols.Store(id, p)
+	}
+	return s, err
+}
+
+// update applies fn to the stored subscription with the given ID.
+func (m *Manager) update(ctx context.Context, id string, fn func(s *Subscription)) (Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, err := m.store.Get(ctx, id)
+	if err != nil {
+		return Subscription{}, err
+	}
+	fn(&s)
+	if err := m.store.Put(ctx, s); err != nil {
+		return Subscription{}, err
+	}
+	return s, nil
+}
+
+// Delivery is the result of the delivery of an event to a subscription.
+type Delivery struct {
+	Subscription Subscription
+	// Result is the result of the last attempt.
+	Result protocol.Result
+}
+
+// Deliver sends e to the active subscriptions matching its type,
+// concurrently, and returns the deliveries once all are done. The
+// subscriptions whose endpoint answered 410 Gone are disabled, as are those
+// failing for too long, and the expired ones.
+func (m *Manager) Deliver(ctx context.Context, e event.Event) ([]Delivery, error) {
+	subscriptions, err := m.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.clock.Now()
+	var targets []Subscription
+	for _, s := range subscriptions {
+		if s.Status != StatusActive || !s.Matches(e.Type()) {
+			continue
+		}
+		if s.Expired(now) {
+			m.disable(ctx, s.ID, "expired")
+			continue
+		}
+		targets = append(targets, s)
+	}
+
+	ctx = cecontext.WithClock(cecontext.WithRetryParams(ctx, &m.retry), m.clock)
+	deliveries := make([]Delivery, len(targets))
+	var wg sync.WaitGroup
+	for i, s := range targets {
+		wg.Add(1)
+		go func(i int, s Subscription) {
+			defer wg.Done()
+			deliveries[i] = m.deliver(ctx, s, e)
+		}(i, s)
+	}
+	wg.Wait()
+	return deliveries, nil
+}
+
+func (m *Manager) deliver(ctx context.Context, s Subscription, e event.Event) Delivery {
+	p, err := m.protocol(s)
+	if err != nil {
+		return Delivery{Subscription: s, Result: err}
+	}
+	result := p.Send(ctx, binding.ToMessage(&e))
+
+	updated, err := m.update(ctx, s.ID, func(s *Subscription) {
+		now := m.clock.Now()
+		switch {
+		case protocol.IsACK(result):
+			s.Failures = 0
+			s.FailingSince = time.Time{}
+			s.LastDelivery = now
+			return
+		case cehttp.IsGone(result):
+			s.disable("gone", now)
+		}
+		if s.Failures == 0 {
+			s.FailingSince = now
+		}
+		s.Failures++
+		if s.Status == StatusActive && s.Failures >= m.maxFailures && now.Sub(s.FailingSince) >= m.maxFailureDuration {
+			s.disable(fmt.Sprintf("%d consecutive failed deliveries since %s", s.Failures, s.FailingSince.Format(time.RFC3339)), now)
+		}
+	})
+	switch {
+	case errors.Is(err, ErrNotFound):
+		// Unsubscribed during the delivery.
+		m.protocols.Delete(s.ID)
+	case err != nil:
+		cecontext.LoggerFrom(ctx).Warnw("failed to update the webhook subscription", zap.String("id", s.ID), zap.Error(err))
+	default:
+		s = updated
+	}
+	if s.Status == StatusDisabled {
+		m.protocols.Delete(s.ID)
+		cecontext.LoggerFrom(ctx).Infow("disabled the webhook subscription", zap.String("id", s.ID), zap.String("sink", s.Sink), zap.String("reason", s.Reason))
+	}
+	return Delivery{Subscription: s, Result: result}
+}
+
+func (m *Manager) disable(ctx context.Context, id, reason string) {
+	_, err := m.update(ctx, id, func(s *Subscription) {
+		s.disable(reason, m.clock.Now())
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		cecontext.LoggerFrom(ctx).Warnw("failed to disable the webhook subscription", zap.String("id", id), zap.Error(err))
+	}
+	m.protocols.Delete(id)
+}
+
+// Sweep deletes the subscriptions which expired or have been disabled for
+// longer than retention, and returns their number. With a LeaderElector, only
+// the leader sweeps them, the others return zero.
+func (m *Manager) Sweep(ctx context.Context, retention time.Duration) (int, error) {
+	if leader, err := m.isLeader(ctx); err != nil || !leader {
+		return 0, err
+	}
+	subscriptions, err := m.store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := m.clock.Now()
+	deleted := 0
+	for _, s := range subscriptions {
+		expired := s.Expired(now.Add(-retention))
+		disabled := s.Status == StatusDisabled && now.Sub(s.DisabledAt) >= retention
+		if !expired && !disabled {
+			continue
+		}
+		if err := m.Unsubscribe(ctx, s.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
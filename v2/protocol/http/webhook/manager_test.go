@@ -0,0 +1,291 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// endpoint is a webhook endpoint answering the handshakes with its allowed
+// rate and the deliveries with the next of its statuses.
+type endpoint struct {
+	*httptest.Server
+	mu         sync.Mutex
+	rate       string
+	statuses   []int
+	origins    []string
+	deliveries int32
+}
+
+func newEndpoint(t *testing.T, rate string, statuses ...int) *endpoint {
+	ep := &endpoint{rate: rate, statuses: statuses}
+	ep.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ep.mu.Lock()
+		defer ep.mu.Unlock()
+		if req.Method == http.MethodOptions {
+			ep.origins = append(ep.origins, req.Header.Get("WebHook-Request-Origin"))
+			if ep.rate == "reject" {
+				rw.WriteHeader(http.StatusForbidden)
+				return
+			}
+			if ep.rate == "no origin" {
+				// Any server answering OPTIONS, not a webhook.
+				return
+			}
+			rw.Header().Set("WebHook-Allowed-Origin", req.Header.Get("WebHook-Request-Origin"))
+			rw.Header().Set("WebHook-Allowed-Rate", ep.rate)
+			return
+		}
+		atomic.AddInt32(&ep.deliveries, 1)
+		status := http.StatusAccepted
+		if len(ep.statuses) > 0 {
+			status, ep.statuses = ep.statuses[0], ep.statuses[1:]
+		}
+		rw.WriteHeader(status)
+	}))
+	t.Cleanup(ep.Close)
+	return ep
+}
+
+func newManager(t *testing.T, opts ...Option) (*Manager, *MemoryStore, *cecontext.FakeClock) {
+	store := NewMemoryStore()
+	c := cecontext.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	m, err := NewManager(store, "producer.example.com", append([]Option{WithClock(c)}, opts...)...)
+	require.NoError(t, err)
+	return m, store, c
+}
+
+func testEvent(eventType string) event.Event {
+	e := event.New()
+	e.SetID("1")
+	e.SetSource("/orders")
+	e.SetType(eventType)
+	return e
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+	m, store, _ := newManager(t)
+
+	ep := newEndpoint(t, "120")
+	s, err := m.Subscribe(ctx, ep.URL, "order.created")
+	require.NoError(t, err)
+	require.Equal(t, []string{"producer.example.com"}, ep.origins)
+	require.Equal(t, StatusActive, s.Status)
+	require.Equal(t, 120, s.AllowedRate)
+	require.True(t, s.ExpiresAt.IsZero())
+	stored, err := store.Get(ctx, s.ID)
+	require.NoError(t, err)
+	require.Equal(t, s, stored)
+
+	_, err = m.Subscribe(ctx, newEndpoint(t, "reject").URL)
+	require.ErrorContains(t, err, "failed the validation handshake")
+	_, err = m.Subscribe(ctx, newEndpoint(t, "no origin").URL)
+	require.ErrorContains(t, err, "failed the validation handshake")
+	_, err = m.Subscribe(ctx, "ftp://example.com")
+	require.ErrorContains(t, err, "invalid webhook sink")
+	list, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, m.Unsubscribe(ctx, s.ID))
+	require.ErrorIs(t, m.Unsubscribe(ctx, s.ID), ErrNotFound)
+}
+
+func TestDeliver(t *testing.T) {
+	ctx := context.Background()
+	m, _, c := newManager(t)
+
+	created := newEndpoint(t, "*")
+	all := newEndpoint(t, "*", http.StatusGone)
+	sCreated, err := m.Subscribe(ctx, created.URL, "order.created")
+	require.NoError(t, err)
+	c.Advance(time.Second)
+	sAll, err := m.Subscribe(ctx, all.URL)
+	require.NoError(t, err)
+
+	c.Advance(time.Minute)
+	deliveries, err := m.Deliver(ctx, testEvent("order.created"))
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	require.Equal(t, sCreated.ID, deliveries[0].Subscription.ID)
+	require.True(t, protocol.IsACK(deliveries[0].Result))
+	require.Equal(t, c.Now(), deliveries[0].Subscription.LastDelivery)
+
+	// The endpoint answering 410 Gone is disabled.
+	require.Equal(t, sAll.ID, deliveries[1].Subscription.ID)
+	require.Equal(t, StatusDisabled, deliveries[1].Subscription.Status)
+	require.Equal(t, "gone", deliveries[1].Subscription.Reason)
+
+	deliveries, err = m.Deliver(ctx, testEvent("order.deleted"))
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+	require.Equal(t, int32(1), atomic.LoadInt32(&created.deliveries))
+	require.Equal(t, int32(1), atomic.LoadInt32(&all.deliveries))
+
+	s, err := m.Enable(ctx, sAll.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusActive, s.Status)
+	require.Zero(t, s.Failures)
+	deliveries, err = m.Deliver(ctx, testEvent("order.deleted"))
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.True(t, protocol.IsACK(deliveries[0].Result))
+}
+
+func TestDeliverRetries(t *testing.T) {
+	ctx := context.Background()
+	m, _, c := newManager(t, WithRetry(cecontext.RetryParams{
+		Strategy: cecontext.BackoffStrategyConstant,
+		MaxTries: 2,
+		Period:   time.Minute,
+	}))
+	ep := newEndpoint(t, "*", http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	_, err := m.Subscribe(ctx, ep.URL)
+	require.NoError(t, err)
+
+	// The backoff waits on the clock of the manager.
+	var deliveries []Delivery
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		deliveries, err = m.Deliver(ctx, testEvent("order.created"))
+	}()
+	for i := 0; i < 2; i++ {
+		require.Eventually(t, func() bool { return c.Timers() == 1 }, time.Second, time.Millisecond)
+		c.Advance(time.Minute)
+	}
+	<-done
+	require.NoError(t, err)
+	require.True(t, protocol.IsACK(deliveries[0].Result))
+	require.Zero(t, deliveries[0].Subscription.Failures)
+	require.Equal(t, int32(3), atomic.LoadInt32(&ep.deliveries))
+}
+
+func TestDeliverDisablesFailingEndpoints(t *testing.T) {
+	ctx := context.Background()
+	m, _, c := newManager(t, WithDisableAfter(2, time.Hour))
+	ep := newEndpoint(t, "*", 500, 500, 500, 500)
+	_, err := m.Subscribe(ctx, ep.URL)
+	require.NoError(t, err)
+
+	failingSince := c.Now()
+	for i := 1; i <= 3; i++ {
+		deliveries, err := m.Deliver(ctx, testEvent("order.created"))
+		require.NoError(t, err)
+		s := deliveries[0].Subscription
+		require.Equal(t, i, s.Failures)
+		require.Equal(t, failingSince, s.FailingSince)
+		require.Equal(t, StatusActive, s.Status)
+		c.Advance(20 * time.Minute)
+	}
+
+	deliveries, err := m.Deliver(ctx, testEvent("order.created"))
+	require.NoError(t, err)
+	s := deliveries[0].Subscription
+	require.Equal(t, StatusDisabled, s.Status)
+	require.Equal(t, "4 consecutive failed deliveries since 2024-01-02T03:04:05Z", s.Reason)
+	require.Equal(t, c.Now(), s.DisabledAt)
+
+	// Disabled subscriptions are deleted once retained long enough.
+	n, err := m.Sweep(ctx, time.Hour)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	c.Advance(time.Hour)
+	n, err = m.Sweep(ctx, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestSubscriptionExpiry(t *testing.T) {
+	ctx := context.Background()
+	m, store, c := newManager(t, WithSubscriptionTTL(time.Hour))
+	ep := newEndpoint(t, "*")
+	s, err := m.Subscribe(ctx, ep.URL)
+	require.NoError(t, err)
+	require.Equal(t, c.Now().Add(time.Hour), s.ExpiresAt)
+
+	c.Advance(30 * time.Minute)
+	s, err = m.Renew(ctx, s.ID)
+	require.NoError(t, err)
+	require.Equal(t, c.Now().Add(time.Hour), s.ExpiresAt)
+
+	c.Advance(time.Hour)
+	deliveries, err := m.Deliver(ctx, testEvent("order.created"))
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+	s, err = store.Get(ctx, s.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusDisabled, s.Status)
+	require.Equal(t, "expired", s.Reason)
+	require.Zero(t, atomic.LoadInt32(&ep.deliveries))
+
+	n, err := m.Sweep(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	_, err = m.Renew(ctx, s.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSweepLeader(t *testing.T) {
+	ctx := context.Background()
+	var leader atomic.Bool
+	elected := LeaderElectorFunc(func(context.Context) (bool, error) { return leader.Load(), nil })
+	m, store, c := newManager(t, WithSubscriptionTTL(time.Hour), WithLeaderElector(elected))
+	ep := newEndpoint(t, "*")
+	_, err := m.Subscribe(ctx, ep.URL)
+	require.NoError(t, err)
+	c.Advance(time.Hour)
+
+	// Only the leader sweeps the subscriptions.
+	n, err := m.Sweep(ctx, 0)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	subscriptions, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+
+	leader.Store(true)
+	n, err = m.Sweep(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	failure := errors.New("lease unavailable")
+	m, _, _ = newManager(t, WithLeaderElector(LeaderElectorFunc(func(context.Context) (bool, error) { return false, failure })))
+	_, err = m.Sweep(ctx, 0)
+	require.ErrorIs(t, err, failure)
+}
+
+func TestNewManagerInvalid(t *testing.T) {
+	_, err := NewManager(nil, "origin")
+	require.Error(t, err)
+	_, err = NewManager(NewMemoryStore(), "")
+	require.Error(t, err)
+	for _, opt := range []Option{
+		WithRetry(cecontext.RetryParams{MaxTries: -1}),
+		WithDisableAfter(0, time.Hour),
+		WithDisableAfter(1, -time.Hour),
+		WithSubscriptionTTL(0),
+		WithClock(nil),
+		WithLeaderElector(nil),
+	} {
+		_, err := NewManager(NewMemoryStore(), "origin", opt)
+		require.Error(t, err)
+	}
+}
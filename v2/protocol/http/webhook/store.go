@@ -0,0 +1,155 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by a Store when the subscription does not exist.
+var ErrNotFound = errors.New("webhook subscription not found")
+
+// Status is the state of a Subscription.
+type Status string
+
+const (
+	// StatusActive subscriptions get the events.
+	StatusActive Status = "active"
+	// StatusDisabled subscriptions are kept but no longer get the events,
+	// their endpoint being gone or failing for too long.
+	StatusDisabled Status = "disabled"
+)
+
+// Subscription is an endpoint receiving the events delivered by a Manager.
+type Subscription struct {
+	ID string `json:"id"`
+	// Sink is the URL of the endpoint.
+	Sink string `json:"sink"`
+	// Types are the types of the events delivered to the endpoint, all if
+	// empty.
+	Types  []string `json:"types,omitempty"`
+	Status Status   `json:"status"`
+	// Reason is why the subscription is disabled, since DisabledAt.
+	Reason     string    `json:"reason,omitempty"`
+	DisabledAt time.Time `json:"disabledAt,omitempty"`
+	// AllowedRate is the number of requests per minute the endpoint allowed
+	// in the validation handshake, zero meaning no limit.
+	AllowedRate int       `json:"allowedRate,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// ExpiresAt is when the subscription expires, never if zero.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+	// Failures is the number of consecutive failed deliveries, since
+	// FailingSince.
+	Failures     int       `json:"failures,omitempty"`
+	FailingSince time.Time `json:"failingSince,omitempty"`
+	// LastDelivery is the time of the last successful delivery.
+	LastDelivery time.Time `json:"lastDelivery,omitempty"`
+}
+
+// Matches returns true if the events of the given type are delivered to the
+// subscription.
+func (s *Subscription) Matches(eventType string) bool {
+	if len(s.Types) == 0 {
+		return true
+	}
+	for _, t := range s.Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired returns true if the subscription has expired at now.
+func (s *Subscription) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+func (s *Subscription) disable(reason string, now time.Time) {
+	s.Status = StatusDisabled
+	s.Reason = reason
+	s.DisabledAt = now
+}
+
+// Store persists the subscriptions of a Manager. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Put creates or replaces the subscription with the ID of s.
+	Put(ctx context.Context, s Subscription) error
+	// Get returns the subscription with the given ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (Subscription, error)
+	// Delete deletes the subscription with the given ID, or returns
+	// ErrNotFound.
+	Delete(ctx context.Context, id string) error
+	// List returns all the subscriptions.
+	List(ctx context.Context) ([]Subscription, error)
+}
+
+// MemoryStore is a Store keeping the subscriptions in memory, for tests and
+// single instance producers which can lose them on restart.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	subscriptions map[string]Subscription
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{subscriptions: map[string]Subscription{}}
+}
+
+// Put implements Store.
+func (m *MemoryStore) Put(_ context.Context, s Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.Types = append([]string(nil), s.Types...)
+	m.subscriptions[s.ID] = s
+	return nil
+}
+
+// Get implements Store.
+func (m *MemoryStore) Get(_ context.Context, id string) (Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.subscriptions[id]
+	if !ok {
+		return Subscription{}, ErrNotFound
+	}
+	return s, nil
+}
+
+// Delete implements Store.
+func (m *MemoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.subscriptions[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.subscriptions, id)
+	return nil
+}
+
+// List implements Store, sorting the subscriptions by creation time.
+func (m *MemoryStore) List(_ context.Context) ([]Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	subscriptions := make([]Subscription, 0, len(m.subscriptions))
+	for _, s := range m.subscriptions {
+		subscriptions = append(subscriptions, s)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions, nil
+}
+
+var _ Store = (*MemoryStore)(nil)
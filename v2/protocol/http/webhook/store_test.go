@@ -0,0 +1,65 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package webhook_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol/http/webhook"
+	"github.com/cloudevents/sdk-go/v2/protocol/http/webhook/test"
+)
+
+func TestMemoryStore(t *testing.T) {
+	test.RunStoreTests(t, func(t *testing.T) webhook.Store {
+		return webhook.NewMemoryStore()
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	test.RunStoreTests(t, func(t *testing.T) webhook.Store {
+		store, err := webhook.NewFileStore(filepath.Join(t.TempDir(), "subscriptions.json"))
+		require.NoError(t, err)
+		return store
+	})
+}
+
+func TestFileStoreReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "subscriptions.json")
+	store, err := webhook.NewFileStore(path)
+	require.NoError(t, err)
+	subscriptions := test.Subscriptions(3)
+	for _, s := range subscriptions {
+		require.NoError(t, store.Put(ctx, s))
+	}
+	require.NoError(t, store.Delete(ctx, "1"))
+
+	reloaded, err := webhook.NewFileStore(path)
+	require.NoError(t, err)
+	got, err := reloaded.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []webhook.Subscription{subscriptions[2], subscriptions[0]}, got)
+
+	// The failed writes are not applied
+	require.NoError(t, os.Chmod(filepath.Dir(path), 0o500))
+	defer os.Chmod(filepath.Dir(path), 0o700)
+	if os.Getuid() != 0 {
+		require.Error(t, reloaded.Put(ctx, subscriptions[1]))
+		require.Error(t, reloaded.Delete(ctx, "0"))
+		got, err = reloaded.List(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []webhook.Subscription{subscriptions[2], subscriptions[0]}, got)
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = webhook.NewFileStore(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,107 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package test provides re-usable functions for webhook.Store tests.
+package test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol/http/webhook"
+)
+
+// RunStoreTests verifies that the Store returned by newStore complies with
+// the webhook.Store contract. newStore must return an empty store.
+func RunStoreTests(t *testing.T, newStore func(t *testing.T) webhook.Store) {
+	t.Run("put and get", func(t *testing.T) {
+		testPutGet(t, newStore(t))
+	})
+	t.Run("list", func(t *testing.T) {
+		testList(t, newStore(t))
+	})
+	t.Run("delete", func(t *testing.T) {
+		testDelete(t, newStore(t))
+	})
+}
+
+// Subscriptions returns n active subscriptions with ids from 0 to n-1,
+// created one second apart, in reverse order of their ids.
+func Subscriptions(n int) []webhook.Subscription {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	subscriptions := make([]webhook.Subscription, n)
+	for i := range subscriptions {
+		subscriptions[i] = webhook.Subscription{
+			ID:          strconv.Itoa(i),
+			Sink:        "https://example.com/" + strconv.Itoa(i),
+			Types:       []string{"com.example.created", "com.example.deleted"},
+			Status:      webhook.StatusActive,
+			AllowedRate: 60,
+			CreatedAt:   created.Add(time.Duration(n-i) * time.Second),
+		}
+	}
+	return subscriptions
+}
+
+func testPutGet(t *testing.T, store webhook.Store) {
+	ctx := context.Background()
+	_, err := store.Get(ctx, "0")
+	require.ErrorIs(t, err, webhook.ErrNotFound)
+
+	s := Subscriptions(1)[0]
+	require.NoError(t, store.Put(ctx, s))
+	// The store keeps its own copy.
+	s.Types[0] = "changed"
+	got, err := store.Get(ctx, "0")
+	require.NoError(t, err)
+	require.Equal(t, Subscriptions(1)[0], got)
+
+	s = Subscriptions(1)[0]
+	s.Status = webhook.StatusDisabled
+	s.Reason = "gone"
+	s.DisabledAt = s.CreatedAt.Add(time.Hour)
+	s.Failures = 3
+	s.FailingSince = s.CreatedAt.Add(time.Minute)
+	s.LastDelivery = s.CreatedAt.Add(time.Second)
+	s.ExpiresAt = s.CreatedAt.Add(24 * time.Hour)
+	require.NoError(t, store.Put(ctx, s))
+	got, err = store.Get(ctx, "0")
+	require.NoError(t, err)
+	require.Equal(t, s, got)
+}
+
+func testList(t *testing.T, store webhook.Store) {
+	ctx := context.Background()
+	got, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	subscriptions := Subscriptions(3)
+	for _, s := range subscriptions {
+		require.NoError(t, store.Put(ctx, s))
+	}
+	got, err = store.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []webhook.Subscription{subscriptions[2], subscriptions[1], subscriptions[0]}, got)
+}
+
+func testDelete(t *testing.T, store webhook.Store) {
+	ctx := context.Background()
+	require.ErrorIs(t, store.Delete(ctx, "0"), webhook.ErrNotFound)
+	for _, s := range Subscriptions(2) {
+		require.NoError(t, store.Put(ctx, s))
+	}
+	require.NoError(t, store.Delete(ctx, "0"))
+	_, err := store.Get(ctx, "0")
+	require.ErrorIs(t, err, webhook.ErrNotFound)
+	require.ErrorIs(t, store.Delete(ctx, "0"), webhook.ErrNotFound)
+	got, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, Subscriptions(2)[1:], got)
+}
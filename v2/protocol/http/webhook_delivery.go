@@ -0,0 +1,109 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// DeliveryOutcome categorizes the response of a delivery target following the
+// HTTP WebHook spec:
+// https://github.com/cloudevents/spec/blob/v1.0/http-webhook.md#22-delivery-response
+type DeliveryOutcome string
+
+const (
+	// DeliveryAccepted means the delivery target accepted the event (2xx).
+	DeliveryAccepted DeliveryOutcome = "accepted"
+	// DeliveryRejected means the delivery target rejected the event and the
+	// delivery must not be retried (e.g. 400, 410, 415).
+	DeliveryRejected DeliveryOutcome = "rejected"
+	// DeliveryRetriable means the delivery failed but may succeed if it is
+	// attempted again later (e.g. 429, 5xx or a network error).
+	DeliveryRetriable DeliveryOutcome = "retriable"
+)
+
+// UnsubscribeHandler is invoked when a delivery target answers with 410 Gone,
+// meaning the target has been retired and the sender should stop sending to it.
+type UnsubscribeHandler func(ctx context.Context, target *url.URL)
+
+// Outcome returns the delivery outcome category of the given http Result.
+func (e *Result) Outcome() DeliveryOutcome {
+	if e == nil {
+		return DeliveryAccepted
+	}
+	switch sc := e.StatusCode; {
+	case sc/100 == 2:
+		return DeliveryAccepted
+	case sc == http.StatusRequestTimeout, sc == http.StatusTooEarly, sc == http.StatusTooManyRequests:
+		return DeliveryRetriable
+	case sc == http.StatusNotImplemented, sc == http.StatusHTTPVersionNotSupported:
+		return DeliveryRejected
+	case sc/100 == 5:
+		return DeliveryRetriable
+	default:
+		return DeliveryRejected
+	}
+}
+
+// DeliveryOutcomeOf returns the delivery outcome category of the result of a
+// Send or Request. Results that are not http Results are categorized by their
+// ACK/NACK status: a NACK without status code, such as a network error, is
+// retriable, while an undelivered result (e.g. an invalid event) is rejected.
+func DeliveryOutcomeOf(result protocol.Result) DeliveryOutcome {
+	var res *Result
+	switch {
+	case protocol.ResultAs(result, &res):
+		return res.Outcome()
+	case protocol.IsACK(result):
+		return DeliveryAccepted
+	case protocol.IsNACK(result):
+		return DeliveryRetriable
+	default:
+		return DeliveryRejected
+	}
+}
+
+// IsGone returns true if the delivery target answered with 410 Gone.
+func IsGone(result protocol.Result) bool {
+	var res *Result
+	return protocol.ResultAs(result, &res) && res.StatusCode == http.StatusGone
+}
+
+// RetryAfterFrom returns the delay requested by the delivery target through
+// the Retry-After header, if any.
+func RetryAfterFrom(result protocol.Result) (time.Duration, bool) {
+	var res *Result
+	if protocol.ResultAs(result, &res) && res.RetryAfter > 0 {
+		return res.RetryAfter, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses the Retry-After header, expressed either as delay
+// in seconds or as an HTTP date.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
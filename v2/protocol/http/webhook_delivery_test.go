@@ -0,0 +1,157 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func TestDeliveryOutcomeOf(t *testing.T) {
+	testCases := map[string]struct {
+		result protocol.Result
+		want   DeliveryOutcome
+	}{
+		"nil": {
+			result: nil,
+			want:   DeliveryAccepted,
+		},
+		"200": {
+			result: NewResult(200, "%w", protocol.ResultACK),
+			want:   DeliveryAccepted,
+		},
+		"202 with retries": {
+			result: NewRetriesResult(NewResult(202, "%w", protocol.ResultACK), 1, time.Now(), nil),
+			want:   DeliveryAccepted,
+		},
+		"400": {
+			result: NewResult(400, "%w", protocol.ResultNACK),
+			want:   DeliveryRejected,
+		},
+		"410": {
+			result: NewResult(410, "%w", protocol.ResultNACK),
+			want:   DeliveryRejected,
+		},
+		"415": {
+			result: NewResult(415, "%w", protocol.ResultNACK),
+			want:   DeliveryRejected,
+		},
+		"429": {
+			result: NewResult(429, "%w", protocol.ResultNACK),
+			want:   DeliveryRetriable,
+		},
+		"503 with retries": {
+			result: NewRetriesResult(NewResult(503, "%w", protocol.ResultNACK), 3, time.Now(), nil),
+			want:   DeliveryRetriable,
+		},
+		"501": {
+			result: NewResult(501, "%w", protocol.ResultNACK),
+			want:   DeliveryRejected,
+		},
+		"network error": {
+			result: protocol.NewReceipt(false, "%w", errors.New("connection refused")),
+			want:   DeliveryRetriable,
+		},
+		"undelivered": {
+			result: errors.New("invalid event"),
+			want:   DeliveryRejected,
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			require.Equal(t, tc.want, DeliveryOutcomeOf(tc.result))
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		header string
+		want   time.Duration
+	}{
+		"empty":    {header: "", want: 0},
+		"seconds":  {header: "120", want: 2 * time.Minute},
+		"negative": {header: "-1", want: 0},
+		"date":     {header: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		"past":     {header: now.Add(-time.Hour).Format(http.TimeFormat), want: 0},
+		"invalid":  {header: "soon", want: 0},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			require.Equal(t, tc.want, parseRetryAfter(tc.header, now))
+		})
+	}
+}
+
+func TestRequest_Gone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+
+	var gone *url.URL
+	p, err := New(WithTarget(srv.URL), WithUnsubscribeHandler(func(ctx context.Context, target *url.URL) {
+		gone = target
+	}))
+	require.NoError(t, err)
+
+	e := newEvent(t, event.TextPlain, "hello")
+	err = p.Send(context.Background(), binding.ToMessage(&e))
+	require.True(t, IsGone(err))
+	require.Equal(t, DeliveryRejected, DeliveryOutcomeOf(err))
+	require.NotNil(t, gone)
+	require.Equal(t, srv.URL, gone.String())
+}
+
+func TestRequestWithRetries_RetryAfter(t *testing.T) {
+	var count int
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		count++
+		if count == 1 {
+			rw.Header().Set("Retry-After", "1")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	p, err := New(WithTarget(srv.URL), WithRetryAfter(time.Minute))
+	require.NoError(t, err)
+
+	ctx := cecontext.WithRetriesConstantBackoff(context.Background(), time.Millisecond, 3)
+	e := newEvent(t, event.TextPlain, "hello")
+	start := time.Now()
+	_, res := p.Request(ctx, binding.ToMessage(&e))
+	require.True(t, protocol.IsACK(res))
+	require.Equal(t, 2, count)
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	var rr *RetriesResult
+	require.True(t, protocol.ResultAs(res, &rr))
+	require.Len(t, rr.Attempts, 1)
+	retryAfter, ok := RetryAfterFrom(rr.Attempts[0])
+	require.True(t, ok)
+	require.Equal(t, time.Second, retryAfter)
+}
+
+func TestWithRetryAfter_Invalid(t *testing.T) {
+	_, err := New(WithRetryAfter(0))
+	require.Error(t, err)
+}
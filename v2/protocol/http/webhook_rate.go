@@ -0,0 +1,152 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerRequestOrigin = "WebHook-Request-Origin"
+	headerAllowedOrigin = "WebHook-Allowed-Origin"
+	headerRequestRate   = "WebHook-Request-Rate"
+	headerAllowedRate   = "WebHook-Allowed-Rate"
+)
+
+// Handshake performs the validation handshake of the HTTP WebHook spec with
+// the delivery target, sending an OPTIONS request on behalf of origin along
+// with the rate requested by WithWebhookRequestRate, if any:
+// https://github.com/cloudevents/spec/blob/v1.0/http-webhook.md#42-validation-request
+//
+// The delivery target must answer with a 2xx status code and a
+// WebHook-Allowed-Origin header set to origin or "*", otherwise the
+// handshake fails with a *Result.
+//
+// It returns the number of requests per minute allowed by the delivery
+// target, zero meaning no limit, and paces the following deliveries
+// accordingly. When the target does not advertise an allowed rate, the
+// requested rate is kept.
+func (p *Protocol) Handshake(ctx context.Context, origin string) (int, error) {
+	if origin == "" {
+		return 0, fmt.Errorf("webhook request origin can not be empty")
+	}
+	req := p.makeRequest(ctx)
+	if p.Client == nil || req.URL == nil {
+		return 0, fmt.Errorf("not initialized: %#v", p)
+	}
+	req.Method = http.MethodOptions
+	req.Header = req.Header.Clone()
+	req.Header.Set(headerRequestOrigin, origin)
+	if p.requestRate > 0 {
+		req.Header.Set(headerRequestRate, strconv.Itoa(p.requestRate))
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook handshake failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return 0, NewResult(resp.StatusCode, "webhook handshake rejected")
+	}
+	if allowed := strings.TrimSpace(resp.Header.Get(headerAllowedOrigin)); allowed != origin && allowed != "*" {
+		return 0, NewResult(resp.StatusCode, "webhook handshake rejected: %s %q does not allow origin %q", headerAllowedOrigin, allowed, origin)
+	}
+
+	if v := resp.Header.Get(headerAllowedRate); v != "" {
+		rate, err := parseAllowedRate(v)
+		if err != nil {
+			return 0, err
+		}
+		p.SetAllowedRate(rate)
+	}
+	return p.AllowedRate(), nil
+}
+
+// AllowedRate returns the number of requests per minute the deliveries are
+// currently paced to, zero meaning no limit.
+func (p *Protocol) AllowedRate() int {
+	return p.pacer.getRate()
+}
+
+// SetAllowedRate changes the number of requests per minute the deliveries
+// are paced to. Zero or a negative rate disables pacing.
+func (p *Protocol) SetAllowedRate(rate int) {
+	p.pacer.setRate(rate)
+}
+
+// parseAllowedRate parses the WebHook-Allowed-Rate header, either a number
+// of requests per minute or "*" when the rate is not limited.
+func parseAllowedRate(v string) (int, error) {
+	v = strings.TrimSpace(v)
+	if v == "*" {
+		return 0, nil
+	}
+	rate, err := strconv.Atoi(v)
+	if err != nil || rate < 0 {
+		return 0, fmt.Errorf("invalid %s header %q", headerAllowedRate, v)
+	}
+	return rate, nil
+}
+
+// webhookPacer spaces out requests to respect a number of requests per
+// minute. The zero value does not limit the requests.
+type webhookPacer struct {
+	mu   sync.Mutex
+	rate int
+	next time.Time
+}
+
+func (w *webhookPacer) getRate() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rate
+}
+
+func (w *webhookPacer) setRate(rate int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if rate < 0 {
+		rate = 0
+	}
+	w.rate = rate
+	w.next = time.Time{}
+}
+
+// wait blocks until a request can be sent, or ctx is done.
+func (w *webhookPacer) wait(ctx context.Context) error {
+	w.mu.Lock()
+	if w.rate == 0 {
+		w.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	at := w.next
+	if at.Before(now) {
+		at = now
+	}
+	w.next = at.Add(time.Minute / time.Duration(w.rate))
+	w.mu.Unlock()
+
+	d := at.Sub(now)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestHandshake(t *testing.T) {
+	testCases := map[string]struct {
+		receiverOpts []Option
+		senderOpts   []Option
+		wantRate     int
+		wantErr      string
+	}{
+		"allowed rate": {
+			receiverOpts: []Option{WithDefaultOptionsHandlerFunc(nil, 120, []string{"*"}, false)},
+			senderOpts:   []Option{WithWebhookRequestRate(600)},
+			wantRate:     120,
+		},
+		"default allowed rate": {
+			receiverOpts: []Option{WithOptionsHandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				p := &Protocol{WebhookConfig: &WebhookConfig{AllowedOrigins: []string{"*"}}}
+				p.OptionsHandler(rw, req)
+			})},
+			senderOpts: []Option{WithWebhookRequestRate(600)},
+			wantRate:   DefaultAllowedRate,
+		},
+		"no allowed rate keeps requested rate": {
+			receiverOpts: []Option{WithOptionsHandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("WebHook-Allowed-Origin", "http://example.com")
+			})},
+			senderOpts:   []Option{WithWebhookRequestRate(600)},
+			wantRate:     600,
+		},
+		"unlimited": {
+			receiverOpts: []Option{WithOptionsHandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("WebHook-Allowed-Origin", "*")
+				rw.Header().Set("WebHook-Allowed-Rate", "*")
+			})},
+			wantRate: 0,
+		},
+		"invalid allowed rate": {
+			receiverOpts: []Option{WithOptionsHandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("WebHook-Allowed-Origin", "*")
+				rw.Header().Set("WebHook-Allowed-Rate", "fast")
+			})},
+			wantErr: `invalid WebHook-Allowed-Rate header "fast"`,
+		},
+		"no allowed origin": {
+			receiverOpts: []Option{WithOptionsHandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})},
+			wantErr:      `200: webhook handshake rejected: WebHook-Allowed-Origin "" does not allow origin "http://example.com"`,
+		},
+		"other allowed origin": {
+			receiverOpts: []Option{WithOptionsHandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("WebHook-Allowed-Origin", "http://other.example.com")
+			})},
+			wantErr: `200: webhook handshake rejected: WebHook-Allowed-Origin "http://other.example.com" does not allow origin "http://example.com"`,
+		},
+		"rejected": {
+			wantErr: "405: webhook handshake rejected",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			receiver, err := New(tc.receiverOpts...)
+			require.NoError(t, err)
+			srv := httptest.NewServer(receiver)
+			defer srv.Close()
+
+			sender, err := New(append([]Option{WithTarget(srv.URL)}, tc.senderOpts...)...)
+			require.NoError(t, err)
+
+			rate, err := sender.Handshake(context.Background(), "http://example.com")
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantRate, rate)
+			require.Equal(t, tc.wantRate, sender.AllowedRate())
+		})
+	}
+}
+
+func TestHandshakeSendsRequestRate(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = req.Header
+		rw.Header().Set("WebHook-Allowed-Origin", req.Header.Get("WebHook-Request-Origin"))
+	}))
+	defer srv.Close()
+
+	p, err := New(WithTarget(srv.URL), WithWebhookRequestRate(60))
+	require.NoError(t, err)
+	_, err = p.Handshake(context.Background(), "http://example.com")
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com", got.Get("WebHook-Request-Origin"))
+	require.Equal(t, "60", got.Get("WebHook-Request-Rate"))
+}
+
+func TestAllowedRatePacesDeliveries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer srv.Close()
+
+	p, err := New(WithTarget(srv.URL))
+	require.NoError(t, err)
+	require.Zero(t, p.AllowedRate())
+
+	// 6000 requests per minute, one request every 10ms.
+	p.SetAllowedRate(6000)
+	require.Equal(t, 6000, p.AllowedRate())
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		e := test.FullEvent()
+		require.True(t, protocol.IsACK(p.Send(context.Background(), binding.ToMessage(&e))))
+	}
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	// A delivery waiting for its turn gives up with the context.
+	p.SetAllowedRate(1)
+	e := test.FullEvent()
+	require.True(t, protocol.IsACK(p.Send(context.Background(), binding.ToMessage(&e))))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, p.Send(ctx, binding.ToMessage(&e)), context.DeadlineExceeded)
+}
+
+func TestWithWebhookRequestRate(t *testing.T) {
+	_, err := New(WithWebhookRequestRate(0))
+	require.EqualError(t, err, "http webhook request rate must be positive")
+}
@@ -28,6 +28,33 @@ type ReceiveCloser interface {
 	Closer
 }
 
+// BatchReceiver receives messages in batches, for protocols able to deliver
+// several messages at once: the HTTP protocol splits the requests in batched
+// mode, and the Pub/Sub protocol groups the messages it pulled.
+type BatchReceiver interface {
+	// ReceiveBatch blocks till at least one message is received or ctx expires.
+	// ReceiveBatch can be invoked safely from different goroutines.
+	//
+	// A non-nil error means the receiver is closed.
+	// io.EOF means it closed cleanly, any other value indicates an error.
+	// The caller is responsible for `Finish()` each of the returned messages,
+	// the result of each message being reported independently.
+	ReceiveBatch(ctx context.Context) ([]binding.Message, error)
+}
+
+// Pauser is implemented by the receivers able to stop fetching messages from
+// the transport, so that the messages are not buffered while the consumer is
+// overloaded: the Kafka protocols pause their partitions, the Pub/Sub protocol
+// holds the messages pulled until its flow control stops pulling, and the
+// AMQP protocol stops issuing link credit, see its WithReceiverCredit option.
+type Pauser interface {
+	// Pause stops fetching messages until Resume is invoked. The messages
+	// already fetched are still received.
+	Pause(ctx context.Context) error
+	// Resume resumes fetching messages.
+	Resume(ctx context.Context) error
+}
+
 // ResponseFn is the function callback provided from Responder.Respond to allow
 // for a receiver to "reply" to a message it receives.
 // transformers are applied when the message is written on the wire.
@@ -21,3 +21,15 @@ type Opener interface {
 type Closer interface {
 	Close(ctx context.Context) error
 }
+
+// Pinger is the interface for things that can check their connectivity up
+// front, e.g. by establishing their connections to the broker and
+// authenticating, so that misconfigurations are reported before the first
+// message is sent or received. It is implemented by the HTTP, Kafka (sarama
+// and confluent), NATS, NATS JetStream, AMQP, MQTT, Pub/Sub and PostgreSQL
+// protocols.
+type Pinger interface {
+	// Ping returns an error describing why the connectivity check failed, if
+	// any. It can be invoked several times.
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,18 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package multi implements a protocol merging the messages received from
+several protocols (e.g. HTTP and Kafka), so that a single client and receiver
+function can consume all of them:
+
+	p, err := multi.New([]interface{}{httpProtocol, kafkaProtocol})
+	c, err := client.New(p)
+	err = c.StartReceiver(ctx, fn)
+
+Opening the multi protocol opens all the protocols, and closing it closes all
+of them.
+*/
+package multi
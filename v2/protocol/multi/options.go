@@ -0,0 +1,27 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package multi
+
+import (
+	"errors"
+
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Option is the function signature required to be considered a multi.Option.
+type Option func(*Protocol) error
+
+// WithSender sets the Sender used to send the events. It is closed by Close
+// only if it is one of the merged protocols.
+func WithSender(sender protocol.Sender) Option {
+	return func(p *Protocol) error {
+		if sender == nil {
+			return errors.New("multi sender can not be nil")
+		}
+		p.sender = sender
+		return nil
+	}
+}
@@ -0,0 +1,219 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Protocol is a protocol.Responder and protocol.Receiver merging the messages
+// of several protocol.Receiver or protocol.Responder.
+type Protocol struct {
+	protocols []interface{}
+	sender    protocol.Sender
+	incoming  chan incoming
+	// finished is closed once the messages of all the protocols have been
+	// received, see OpenInbound.
+	finished     chan struct{}
+	finishedOnce sync.Once
+}
+
+type incoming struct {
+	ctx     context.Context
+	message binding.Message
+	respFn  protocol.ResponseFn
+}
+
+// New creates a Protocol merging the messages of protocols, each of them
+// being a protocol.Receiver or a protocol.Responder. Responders are preferred
+// when a protocol implements both, as the client does.
+func New(protocols []interface{}, opts ...Option) (*Protocol, error) {
+	if len(protocols) == 0 {
+		return nil, errors.New("multi protocols can not be empty")
+	}
+	for _, p := range protocols {
+		switch p.(type) {
+		case protocol.Responder, protocol.Receiver:
+		default:
+			return nil, fmt.Errorf("multi protocol %T is neither a protocol.Receiver nor a protocol.Responder", p)
+		}
+	}
+	p := &Protocol{
+		protocols: protocols,
+		incoming:  make(chan incoming),
+		finished:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// OpenInbound implements protocol.Opener: it opens the protocols that are
+// protocol.Opener and receives their messages until ctx is done. If opening
+// one of the protocols fails, the others are stopped and the error returned.
+// Once all the protocols are closed, Respond and Receive return io.EOF.
+func (p *Protocol) OpenInbound(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	var receivers sync.WaitGroup
+	receivers.Add(len(p.protocols))
+	go func() {
+		receivers.Wait()
+		p.finishedOnce.Do(func() { close(p.finished) })
+	}()
+	for _, proto := range p.protocols {
+		if opener, ok := proto.(protocol.Opener); ok {
+			g.Go(func() error {
+				if err := opener.OpenInbound(ctx); err != nil {
+					return fmt.Errorf("error while opening %T: %w", opener, err)
+				}
+				return nil
+			})
+		}
+		switch r := proto.(type) {
+		case protocol.Responder:
+			g.Go(func() error {
+				defer receivers.Done()
+				p.receive(ctx, r.Respond)
+				return nil
+			})
+		case protocol.Receiver:
+			g.Go(func() error {
+				defer receivers.Done()
+				p.receive(ctx, func(ctx context.Context) (binding.Message, protocol.ResponseFn, error) {
+					m, err := r.Receive(ctx)
+					return m, resultRespFn, err
+				})
+				return nil
+			})
+		}
+	}
+	return g.Wait()
+}
+
+// receive forwards the messages of a protocol to Respond until it is closed
+// or ctx is done.
+func (p *Protocol) receive(ctx context.Context, respond func(context.Context) (binding.Message, protocol.ResponseFn, error)) {
+	for {
+		m, respFn, err := respond(ctx)
+		if err == io.EOF { // Normal close
+			return
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			cecontext.LoggerFrom(ctx).Warnw("Error while receiving a message", zap.Error(err))
+			continue
+		}
+		select {
+		case p.incoming <- incoming{ctx: ctx, message: m, respFn: respFn}:
+		case <-ctx.Done():
+			_ = m.Finish(protocol.NewReceipt(false, "multi protocol closed"))
+			return
+		}
+	}
+}
+
+// Respond implements protocol.Responder, returning the next message received
+// by any of the protocols, or io.EOF once all of them are closed.
+func (p *Protocol) Respond(ctx context.Context) (binding.Message, protocol.ResponseFn, error) {
+	select {
+	case in := <-p.incoming:
+		return in.message, in.respFn, nil
+	case <-p.finished:
+		return nil, nil, io.EOF
+	case <-ctx.Done():
+		return nil, nil, io.EOF
+	}
+}
+
+// Receive implements protocol.Receiver, returning the next message received by
+// any of the protocols, or io.EOF once all of them are closed. Finishing a message received from a
+// protocol.Responder responds to it without response message.
+func (p *Protocol) Receive(ctx context.Context) (binding.Message, error) {
+	select {
+	case in := <-p.incoming:
+		return &respondingMessage{Message: in.message, ctx: in.ctx, respFn: in.respFn}, nil
+	case <-p.finished:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, io.EOF
+	}
+}
+
+// respondingMessage invokes the ResponseFn of the message when finished.
+type respondingMessage struct {
+	binding.Message
+	ctx    context.Context
+	respFn protocol.ResponseFn
+}
+
+func (m *respondingMessage) GetAttribute(k spec.Kind) (spec.Attribute, interface{}) {
+	return m.Message.(binding.MessageMetadataReader).GetAttribute(k)
+}
+
+func (m *respondingMessage) GetExtension(s string) interface{} {
+	return m.Message.(binding.MessageMetadataReader).GetExtension(s)
+}
+
+func (m *respondingMessage) GetWrappedMessage() binding.Message {
+	return m.Message
+}
+
+func (m *respondingMessage) Finish(err error) error {
+	return m.Message.Finish(m.respFn(m.ctx, nil, err))
+}
+
+// Send implements protocol.Sender using the Sender set by WithSender.
+func (p *Protocol) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	if p.sender == nil {
+		return errors.New("multi protocol has no sender")
+	}
+	return p.sender.Send(ctx, m, transformers...)
+}
+
+// Close implements protocol.Closer, closing all the protocols that are
+// protocol.Closer.
+func (p *Protocol) Close(ctx context.Context) error {
+	var errs []error
+	for _, proto := range p.protocols {
+		if closer, ok := proto.(protocol.Closer); ok {
+			if err := closer.Close(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("error while closing %T: %w", closer, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resultRespFn returns the result of the receiver function to the Receiver
+// through Finish, as the client does for protocols that are not Responders.
+func resultRespFn(_ context.Context, _ binding.Message, r protocol.Result, _ ...binding.Transformer) error {
+	return r
+}
+
+var (
+	_ protocol.Responder = (*Protocol)(nil)
+	_ protocol.Receiver  = (*Protocol)(nil)
+	_ protocol.Opener    = (*Protocol)(nil)
+	_ protocol.Sender    = (*Protocol)(nil)
+	_ protocol.Closer    = (*Protocol)(nil)
+)
@@ -0,0 +1,175 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package multi_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/gochan"
+	"github.com/cloudevents/sdk-go/v2/protocol/multi"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// failingOpener is a Receiver failing to open.
+type failingOpener struct {
+	gochan.Receiver
+}
+
+func (failingOpener) OpenInbound(ctx context.Context) error {
+	return errors.New("unreachable")
+}
+
+func TestMultiProtocol(t *testing.T) {
+	a, b := make(chan binding.Message), make(chan binding.Message)
+	out := gochan.New()
+	p, err := multi.New([]interface{}{gochan.Receiver(a), gochan.Receiver(b)}, multi.WithSender(out))
+	require.NoError(t, err)
+	c, err := client.New(p)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var received []string
+	done := make(chan error)
+	go func() {
+		done <- c.StartReceiver(ctx, func(e event.Event) protocol.Result {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, e.ID())
+			if e.ID() == "b" {
+				return protocol.ResultNACK
+			}
+			return protocol.ResultACK
+		})
+	}()
+
+	results := make(chan error, 2)
+	for id, ch := range map[string]chan binding.Message{"a": a, "b": b} {
+		e := test.FullEvent()
+		e.SetID(id)
+		ch <- binding.WithFinish(binding.ToMessage(&e), func(err error) { results <- err })
+	}
+	var acks int
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-results:
+			if protocol.IsACK(err) {
+				acks++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+	require.Equal(t, 1, acks)
+
+	// Events are sent through the sender.
+	e := test.FullEvent()
+	require.NoError(t, c.Send(ctx, e))
+	m, err := out.Receive(ctx)
+	require.NoError(t, err)
+	got, err := binding.ToEvent(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, e.ID(), got.ID())
+
+	cancel()
+	require.NoError(t, <-done)
+	mu.Lock()
+	sort.Strings(received)
+	require.Equal(t, []string{"a", "b"}, received)
+	mu.Unlock()
+	require.NoError(t, p.Close(context.Background()))
+}
+
+func TestMultiProtocolReceive(t *testing.T) {
+	in := make(chan binding.Message)
+	out := make(chan gochan.ChanResponderResponse, 1)
+	p, err := multi.New([]interface{}{&gochan.Responder{In: in, Out: out}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = p.OpenInbound(ctx)
+	}()
+
+	e := test.FullEvent()
+	in <- binding.ToMessage(&e)
+	m, err := p.Receive(ctx)
+	require.NoError(t, err)
+	got, err := binding.ToEvent(ctx, m)
+	require.NoError(t, err)
+	test.AssertEventEquals(t, e, *got)
+
+	// Finishing the message responds to the Responder.
+	require.NoError(t, m.Finish(protocol.ResultNACK))
+	resp := <-out
+	require.Nil(t, resp.Message)
+	require.True(t, protocol.IsNACK(resp.Result))
+}
+
+func TestMultiProtocolClosed(t *testing.T) {
+	a, b := make(chan binding.Message), make(chan binding.Message)
+	p, err := multi.New([]interface{}{gochan.Receiver(a), gochan.Receiver(b)})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		_ = p.OpenInbound(ctx)
+	}()
+
+	// The messages of the protocol still open are received.
+	close(a)
+	e := test.FullEvent()
+	go func() { b <- binding.ToMessage(&e) }()
+	m, _, err := p.Respond(ctx)
+	require.NoError(t, err)
+	require.NoError(t, m.Finish(nil))
+
+	// Once all the protocols are closed, io.EOF is returned.
+	close(b)
+	_, _, err = p.Respond(ctx)
+	require.Equal(t, io.EOF, err)
+	_, err = p.Receive(ctx)
+	require.Equal(t, io.EOF, err)
+	require.NoError(t, ctx.Err())
+}
+
+func TestMultiProtocolOpenError(t *testing.T) {
+	p, err := multi.New([]interface{}{gochan.New(), failingOpener{}})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.ErrorContains(t, p.OpenInbound(ctx), "unreachable")
+	require.NoError(t, ctx.Err())
+}
+
+func TestNew(t *testing.T) {
+	_, err := multi.New(nil)
+	require.EqualError(t, err, "multi protocols can not be empty")
+
+	_, err = multi.New([]interface{}{gochan.Sender(nil)})
+	require.EqualError(t, err, "multi protocol gochan.Sender is neither a protocol.Receiver nor a protocol.Responder")
+
+	_, err = multi.New([]interface{}{gochan.New()}, multi.WithSender(nil))
+	require.EqualError(t, err, "multi sender can not be nil")
+
+	p, err := multi.New([]interface{}{gochan.New()})
+	require.NoError(t, err)
+	e := test.FullEvent()
+	require.EqualError(t, p.Send(context.Background(), binding.ToMessage(&e)), "multi protocol has no sender")
+}
@@ -33,6 +33,26 @@ type SendCloser interface {
 	Closer
 }
 
+// ResultFn receives the result of an asynchronous send.
+type ResultFn func(result Result)
+
+// AsyncSender sends messages without waiting for their delivery to be
+// confirmed, for protocols with asynchronous confirmations.
+//
+// Optional interface that may be implemented by protocols that support
+// asynchronous delivery reports.
+type AsyncSender interface {
+	// SendAsync hands off the message to the protocol and returns without
+	// waiting for the delivery confirmation.
+	//
+	// When SendAsync returns a non-nil error, the message has not been sent
+	// and fn is not invoked. Otherwise fn is invoked exactly once, possibly from
+	// another goroutine, with the result of the delivery.
+	//
+	// m.Finish() is called as for Sender.Send().
+	SendAsync(ctx context.Context, m binding.Message, fn ResultFn, transformers ...binding.Transformer) error
+}
+
 // Requester sends a message and receives a response
 //
 // Optional interface that may be implemented by protocols that support
@@ -47,3 +67,18 @@ type RequesterCloser interface {
 	Requester
 	Closer
 }
+
+// BatchSender sends messages in batches, for protocols with a batch API,
+// e.g. SQS SendMessageBatch, EventBridge PutEvents or Kafka produce batches.
+//
+// Optional interface that may be implemented by protocols that support
+// sending batches.
+type BatchSender interface {
+	// SendBatch sends the messages in a single call. It returns nil if all
+	// the messages are acknowledged, a *BatchResult with the result of each
+	// message if some are not, or another Result applying to all the
+	// messages if the batch failed as a whole.
+	//
+	// m.Finish() is called for each message as for Sender.Send().
+	SendBatch(ctx context.Context, msgs []binding.Message, transformers ...binding.Transformer) error
+}
@@ -0,0 +1,16 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package pace implements a protocol.Sender wrapper limiting the rate of the
+deliveries to each destination, e.g. to stay below the rate limits of a
+partner API during a backfill.
+
+The deliveries are paced by a leaky bucket per destination: they are spaced
+evenly at the configured rate, rather than sent in bursts at the start of
+each period, and only a configured burst of them can go out back to back
+after the destination has been idle. Send blocks until the delivery is due.
+*/
+package pace
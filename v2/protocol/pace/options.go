@@ -0,0 +1,62 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+)
+
+// Option is the function signature required to be considered a pace.Option.
+type Option func(*Sender) error
+
+// DestinationFunc returns the destination of a message, which has its own
+// bucket.
+type DestinationFunc func(ctx context.Context, m binding.Message) string
+
+// WithBurst sets the number of deliveries which can go out back to back to
+// an idle destination. By default, the deliveries are always spaced.
+func WithBurst(burst int) Option {
+	return func(s *Sender) error {
+		if burst <= 0 {
+			return fmt.Errorf("pace burst must be positive, got %d", burst)
+		}
+		s.limit.burst = burst
+		return nil
+	}
+}
+
+// WithDestination sets the function returning the destination of the
+// messages. By default, the destination is the target in the context of
+// Send, set with context.WithTarget, so that the messages to the same target
+// share a bucket.
+func WithDestination(fn DestinationFunc) Option {
+	return func(s *Sender) error {
+		if fn == nil {
+			return errors.New("pace destination function can not be nil")
+		}
+		s.destination = fn
+		return nil
+	}
+}
+
+// WithDestinationRate sets the rate, in deliveries per second, and the burst
+// of a destination whose limits differ from those of the others.
+func WithDestinationRate(destination string, rate float64, burst int) Option {
+	return func(s *Sender) error {
+		if rate <= 0 {
+			return fmt.Errorf("pace rate of %q must be positive, got %v", destination, rate)
+		}
+		if burst <= 0 {
+			return fmt.Errorf("pace burst of %q must be positive, got %d", destination, burst)
+		}
+		s.limits[destination] = limit{rate: rate, burst: burst}
+		return nil
+	}
+}
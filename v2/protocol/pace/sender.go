@@ -0,0 +1,152 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// sweepInterval is the number of reservations between two removals of the
+// idle buckets.
+const sweepInterval = 1024
+
+type limit struct {
+	rate  float64
+	burst int
+}
+
+// interval returns the time between two deliveries.
+func (l limit) interval() time.Duration {
+	return time.Duration(float64(time.Second) / l.rate)
+}
+
+// bucket is the leaky bucket of a destination, as the theoretical arrival
+// time of the next delivery: the bucket is empty when it is in the past and
+// full when it is burst intervals in the future.
+type bucket struct {
+	limit limit
+	tat   time.Time
+}
+
+// Sender is a protocol.Sender delaying the messages so that the deliveries
+// to each destination do not exceed a rate.
+type Sender struct {
+	target      protocol.Sender
+	limit       limit
+	limits      map[string]limit
+	destination DestinationFunc
+	now         func() time.Time
+
+	mu           sync.Mutex
+	buckets      map[string]*bucket
+	reservations int
+}
+
+// New returns a Sender delivering the messages to target at rate deliveries
+// per second, per destination.
+func New(target protocol.Sender, rate float64, opts ...Option) (*Sender, error) {
+	if target == nil {
+		return nil, errors.New("pace target can not be nil")
+	}
+	if rate <= 0 {
+		return nil, fmt.Errorf("pace rate must be positive, got %v", rate)
+	}
+	s := &Sender{
+		target: target,
+		limit:  limit{rate: rate, burst: 1},
+		limits: map[string]limit{},
+		destination: func(ctx context.Context, _ binding.Message) string {
+			if u := cecontext.TargetFrom(ctx); u != nil {
+				return u.String()
+			}
+			return ""
+		},
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// reserve reserves the next delivery to destination and returns how long
+// to wait for it, along with a function cancelling the reservation.
+func (s *Sender) reserve(destination string) (time.Duration, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	s.reservations++
+	if s.reservations%sweepInterval == 0 {
+		for d, b := range s.buckets {
+			if b.tat.Before(now) {
+				delete(s.buckets, d)
+			}
+		}
+	}
+
+	b := s.buckets[destination]
+	if b == nil {
+		l, ok := s.limits[destination]
+		if !ok {
+			l = s.limit
+		}
+		b = &bucket{limit: l}
+		s.buckets[destination] = b
+	}
+	interval := b.limit.interval()
+	tat := b.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	wait := tat.Add(-time.Duration(b.limit.burst-1) * interval).Sub(now)
+	b.tat = tat.Add(interval)
+	return wait, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		b.tat = b.tat.Add(-interval)
+	}
+}
+
+// Send waits for the delivery of m to be due, then sends it to the target.
+// If ctx is done first, m is finished with the error of ctx.
+func (s *Sender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	wait, cancel := s.reserve(s.destination(ctx, m))
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			cancel()
+			err := ctx.Err()
+			_ = m.Finish(err)
+			return err
+		case <-timer.C:
+		}
+	}
+	return s.target.Send(ctx, m, transformers...)
+}
+
+// Close closes the target if it implements protocol.Closer.
+func (s *Sender) Close(ctx context.Context) error {
+	if c, ok := s.target.(protocol.Closer); ok {
+		return c.Close(ctx)
+	}
+	return nil
+}
+
+var _ protocol.SendCloser = (*Sender)(nil)
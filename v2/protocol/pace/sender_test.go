@@ -0,0 +1,118 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package pace
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// recordingSender records the time of each send.
+type recordingSender struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (s *recordingSender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	s.mu.Lock()
+	s.times = append(s.times, time.Now())
+	s.mu.Unlock()
+	return m.Finish(nil)
+}
+
+func TestReserve(t *testing.T) {
+	s, err := New(&recordingSender{}, 10, WithBurst(3), WithDestinationRate("slow", 1, 1))
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+
+	var waits []time.Duration
+	for i := 0; i < 5; i++ {
+		wait, _ := s.reserve("fast")
+		waits = append(waits, wait)
+	}
+	// The burst goes out at once, then the deliveries are spaced.
+	require.Equal(t, []time.Duration{-200 * time.Millisecond, -100 * time.Millisecond, 0, 100 * time.Millisecond, 200 * time.Millisecond}, waits)
+
+	wait, _ := s.reserve("slow")
+	require.LessOrEqual(t, wait, time.Duration(0))
+	wait, cancel := s.reserve("slow")
+	require.Equal(t, time.Second, wait)
+	cancel()
+	wait, _ = s.reserve("slow")
+	require.Equal(t, time.Second, wait)
+
+	// The bucket leaks while the destination is idle.
+	now = now.Add(250 * time.Millisecond)
+	wait, _ = s.reserve("fast")
+	require.Equal(t, 50*time.Millisecond, wait)
+	now = now.Add(time.Hour)
+	wait, _ = s.reserve("fast")
+	require.Equal(t, -200*time.Millisecond, wait)
+}
+
+func TestSendPacesPerDestination(t *testing.T) {
+	target := &recordingSender{}
+	s, err := New(target, 100)
+	require.NoError(t, err)
+
+	ctxA := cecontext.WithTarget(context.Background(), "http://a.example.com")
+	ctxB := cecontext.WithTarget(context.Background(), "http://b.example.com")
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		e := test.FullEvent()
+		require.NoError(t, s.Send(ctxA, binding.ToMessage(&e)))
+	}
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	// Another destination has its own bucket.
+	start = time.Now()
+	e := test.FullEvent()
+	require.NoError(t, s.Send(ctxB, binding.ToMessage(&e)))
+	require.Less(t, time.Since(start), 10*time.Millisecond)
+	require.Len(t, target.times, 6)
+}
+
+func TestSendCancelled(t *testing.T) {
+	target := &recordingSender{}
+	s, err := New(target, 1, WithDestination(func(context.Context, binding.Message) string { return "partner" }))
+	require.NoError(t, err)
+
+	e := test.FullEvent()
+	require.NoError(t, s.Send(context.Background(), binding.ToMessage(&e)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var finished error
+	m := binding.WithFinish(binding.ToMessage(&e), func(err error) { finished = err })
+	require.ErrorIs(t, s.Send(ctx, m), context.DeadlineExceeded)
+	require.ErrorIs(t, finished, context.DeadlineExceeded)
+	require.Len(t, target.times, 1)
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New(nil, 1)
+	require.Error(t, err)
+	_, err = New(&recordingSender{}, 0)
+	require.Error(t, err)
+	for _, opt := range []Option{
+		WithBurst(0),
+		WithDestination(nil),
+		WithDestinationRate("a", 0, 1),
+		WithDestinationRate("a", 1, 0),
+	} {
+		_, err := New(&recordingSender{}, 1, opt)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+const (
+	// DefaultReconnectInitialBackoff is the delay before the first
+	// reconnection attempt when ReconnectPolicy.InitialBackoff is not set.
+	DefaultReconnectInitialBackoff = 100 * time.Millisecond
+	// DefaultReconnectMaxBackoff is the maximum delay between two
+	// reconnection attempts when ReconnectPolicy.MaxBackoff is not set.
+	DefaultReconnectMaxBackoff = 30 * time.Second
+)
+
+// ReconnectPolicy configures how the protocol bindings connecting to a
+// broker, such as NATS, MQTT or AMQP, reconnect and resubscribe when the
+// connection is lost while receiving, e.g. during a restart of the broker.
+// If the broker can not be reached again, the binding gives up and its
+// receiver fails with an error, instead of silently stopping.
+type ReconnectPolicy struct {
+	// InitialBackoff is the delay before the first reconnection attempt,
+	// doubled after each failed attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two reconnection attempts.
+	MaxBackoff time.Duration
+	// MaxRetries is the number of consecutive failed attempts after which the
+	// binding gives up, 0 means it never gives up.
+	MaxRetries int
+	// OnReconnect hooks are invoked once the binding has reconnected and
+	// resubscribed, e.g. to log it or to refresh some state.
+	OnReconnect []func(ctx context.Context)
+}
+
+// BackoffFor returns the delay before the given reconnection attempt,
+// starting at 0.
+func (p *ReconnectPolicy) BackoffFor(attempt int) time.Duration {
+	backoff, max := p.InitialBackoff, p.MaxBackoff
+	if backoff <= 0 {
+		backoff = DefaultReconnectInitialBackoff
+	}
+	if max <= 0 {
+		max = DefaultReconnectMaxBackoff
+	}
+	for i := 0; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+// Reconnect invokes connect until it succeeds, waiting for the backoff on
+// the clock of ctx before each attempt, then invokes the OnReconnect hooks.
+// It returns an error if MaxRetries attempts failed, or if ctx is done.
+func (p *ReconnectPolicy) Reconnect(ctx context.Context, connect func(ctx context.Context) error) error {
+	logger := cecontext.LoggerFrom(ctx)
+	clock := cecontext.ClockFrom(ctx)
+	for attempt := 0; ; attempt++ {
+		timer := clock.NewTimer(p.BackoffFor(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+
+		err := connect(ctx)
+		if err == nil {
+			logger.Infow("reconnected", "attempts", attempt+1)
+			p.Reconnected(ctx)
+			return nil
+		}
+		if p.MaxRetries > 0 && attempt+1 >= p.MaxRetries {
+			return fmt.Errorf("failed to reconnect after %d attempts: %w", attempt+1, err)
+		}
+		logger.Warnw("failed to reconnect", "attempt", attempt+1, "error", err)
+	}
+}
+
+// Reconnected invokes the OnReconnect hooks, for the bindings whose
+// underlying client reconnects by itself.
+func (p *ReconnectPolicy) Reconnected(ctx context.Context) {
+	for _, fn := range p.OnReconnect {
+		fn(ctx)
+	}
+}
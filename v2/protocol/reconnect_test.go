@@ -0,0 +1,73 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReconnectPolicyBackoffFor(t *testing.T) {
+	p := ReconnectPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := p.BackoffFor(attempt); got != want {
+			t.Errorf("BackoffFor(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+
+	var defaults ReconnectPolicy
+	if got := defaults.BackoffFor(0); got != DefaultReconnectInitialBackoff {
+		t.Errorf("BackoffFor(0) = %v, want %v", got, DefaultReconnectInitialBackoff)
+	}
+	if got := defaults.BackoffFor(100); got != DefaultReconnectMaxBackoff {
+		t.Errorf("BackoffFor(100) = %v, want %v", got, DefaultReconnectMaxBackoff)
+	}
+}
+
+func TestReconnectPolicyReconnect(t *testing.T) {
+	hooks := 0
+	p := ReconnectPolicy{
+		InitialBackoff: time.Millisecond,
+		MaxRetries:     5,
+		OnReconnect:    []func(context.Context){func(context.Context) { hooks++ }},
+	}
+
+	attempts := 0
+	err := p.Reconnect(context.Background(), func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("unreachable")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Reconnect() = %v", err)
+	}
+	if attempts != 3 || hooks != 1 {
+		t.Errorf("got %d attempts and %d hooks, want 3 and 1", attempts, hooks)
+	}
+
+	unreachable := errors.New("unreachable")
+	attempts = 0
+	err = p.Reconnect(context.Background(), func(context.Context) error {
+		attempts++
+		return unreachable
+	})
+	if !errors.Is(err, unreachable) || attempts != 5 {
+		t.Errorf("Reconnect() = %v after %d attempts, want %v after 5", err, attempts, unreachable)
+	}
+	if hooks != 1 {
+		t.Errorf("got %d hooks, want 1", hooks)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Reconnect(ctx, func(context.Context) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Reconnect() = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// RetriableResult is implemented by the Results knowing whether the delivery
+// may succeed if retried, see IsRetriable.
+type RetriableResult interface {
+	Retriable() bool
+}
+
+// RetryAfterResult is implemented by the Results carrying the delay the
+// recipient asked to wait before retrying, see RetryAfter.
+type RetryAfterResult interface {
+	// RetryDelay returns the delay, zero if the recipient asked for none.
+	RetryDelay() time.Duration
+}
+
+// StatusCodeResult is implemented by the Results carrying the status code of
+// the recipient, e.g. the HTTP status code, see StatusCode.
+type StatusCodeResult interface {
+	Status() int
+}
+
+// BrokerResult is the Result wrapping an error of a broker, with the details
+// the bindings know about it. It matches the same Results as the error it
+// wraps.
+type BrokerResult struct {
+	// Err is the error of the broker or of its client library.
+	Err error
+	// Transient reports whether the error is transient, so that the delivery
+	// may succeed if retried.
+	Transient bool
+	// Code is the error code of the broker, zero if unknown.
+	Code int
+	// Delay is the delay the broker asked to wait before retrying, zero if
+	// none.
+	Delay time.Duration
+}
+
+// NewBrokerResult returns a *BrokerResult wrapping err, or nil if err is nil.
+func NewBrokerResult(err error, transient bool, code int) Result {
+	if err == nil {
+		return nil
+	}
+	return &BrokerResult{Err: err, Transient: transient, Code: code}
+}
+
+func (r *BrokerResult) Error() string {
+	return r.Err.Error()
+}
+
+// Unwrap returns the error of the broker.
+func (r *BrokerResult) Unwrap() error {
+	return r.Err
+}
+
+// Retriable implements RetriableResult.
+func (r *BrokerResult) Retriable() bool {
+	return r.Transient
+}
+
+// RetryDelay implements RetryAfterResult.
+func (r *BrokerResult) RetryDelay() time.Duration {
+	return r.Delay
+}
+
+// Status implements StatusCodeResult.
+func (r *BrokerResult) Status() int {
+	return r.Code
+}
+
+// IsRetriable reports whether the delivery with the given Result may succeed if
+// retried. The Results implementing RetriableResult decide, otherwise only the
+// NACKs are retriable. An ACK is never retriable.
+func IsRetriable(result Result) bool {
+	if IsACK(result) {
+		return false
+	}
+	var r RetriableResult
+	if ResultAs(result, &r) {
+		return r.Retriable()
+	}
+	return IsNACK(result)
+}
+
+// IsPermanent reports whether the delivery with the given Result failed for
+// good, so that retrying it is pointless: the Results implementing
+// RetriableResult which are not retriable, e.g. the HTTP 4xx responses, and
+// the event.ValidationErrors. Unlike IsRetriable, the other errors, such as
+// the plain errors of the clients of an unreachable broker, are not
+// permanent, so that the senders storing or forwarding the events keep them
+// until they are delivered. An ACK is never permanent.
+func IsPermanent(result Result) bool {
+	if IsACK(result) {
+		return false
+	}
+	var r RetriableResult
+	if ResultAs(result, &r) {
+		return !r.Retriable()
+	}
+	var v event.ValidationError
+	return ResultAs(result, &v)
+}
+
+// RetryAfter returns the delay the recipient asked to wait before retrying the
+// delivery with the given Result, if any.
+func RetryAfter(result Result) (time.Duration, bool) {
+	var r RetryAfterResult
+	if ResultAs(result, &r) {
+		if d := r.RetryDelay(); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// StatusCode returns the status code of the recipient of the delivery with the
+// given Result, if known, e.g. the HTTP status code or the error code of the
+// broker.
+func StatusCode(result Result) (int, bool) {
+	var r StatusCodeResult
+	if ResultAs(result, &r) {
+		if code := r.Status(); code != 0 {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// BrokerError returns the error of the broker wrapped in the given Result, if
+// any, see BrokerResult.
+func BrokerError(result Result) (error, bool) {
+	var r *BrokerResult
+	if ResultAs(result, &r) {
+		return r.Err, true
+	}
+	return nil, false
+}
@@ -0,0 +1,85 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestIsRetriable(t *testing.T) {
+	brokerErr := errors.New("broker unavailable")
+	tests := map[string]struct {
+		result Result
+		want   bool
+	}{
+		"nil":                      {result: nil, want: false},
+		"ACK":                      {result: ResultACK, want: false},
+		"NACK":                     {result: NewReceipt(false, "nope"), want: true},
+		"undelivered":              {result: errors.New("invalid event"), want: false},
+		"transient broker error":   {result: NewBrokerResult(brokerErr, true, 0), want: true},
+		"permanent broker error":   {result: NewBrokerResult(brokerErr, false, 0), want: false},
+		"wrapped broker error":     {result: fmt.Errorf("send: %w", NewBrokerResult(brokerErr, true, 0)), want: true},
+		"NACK of a broker error":   {result: NewReceipt(false, "%w", NewBrokerResult(brokerErr, false, 0)), want: false},
+		"ACK of a transient error": {result: NewReceipt(true, "%w", NewBrokerResult(brokerErr, true, 0)), want: false},
+	}
+	for n, tc := range tests {
+		t.Run(n, func(t *testing.T) {
+			require.Equal(t, tc.want, IsRetriable(tc.result))
+		})
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	brokerErr := errors.New("broker unavailable")
+	tests := map[string]struct {
+		result Result
+		want   bool
+	}{
+		"nil":                    {result: nil, want: false},
+		"ACK":                    {result: ResultACK, want: false},
+		"NACK":                   {result: NewReceipt(false, "nope"), want: false},
+		"plain error":            {result: brokerErr, want: false},
+		"transient broker error": {result: NewBrokerResult(brokerErr, true, 0), want: false},
+		"permanent broker error": {result: fmt.Errorf("send: %w", NewBrokerResult(brokerErr, false, 0)), want: true},
+		"validation error":       {result: fmt.Errorf("send: %w", event.ValidationError{"id": errors.New("empty")}), want: true},
+	}
+	for n, tc := range tests {
+		t.Run(n, func(t *testing.T) {
+			require.Equal(t, tc.want, IsPermanent(tc.result))
+		})
+	}
+}
+
+func TestResultDetails(t *testing.T) {
+	brokerErr := errors.New("throttled")
+	result := fmt.Errorf("send: %w", &BrokerResult{Err: brokerErr, Transient: true, Code: 42, Delay: time.Second})
+
+	d, ok := RetryAfter(result)
+	require.True(t, ok)
+	require.Equal(t, time.Second, d)
+	code, ok := StatusCode(result)
+	require.True(t, ok)
+	require.Equal(t, 42, code)
+	err, ok := BrokerError(result)
+	require.True(t, ok)
+	require.Equal(t, brokerErr, err)
+	require.ErrorIs(t, result, brokerErr)
+
+	_, ok = RetryAfter(NewBrokerResult(brokerErr, true, 0))
+	require.False(t, ok)
+	_, ok = StatusCode(NewBrokerResult(brokerErr, true, 0))
+	require.False(t, ok)
+	_, ok = BrokerError(ResultNACK)
+	require.False(t, ok)
+	require.Nil(t, NewBrokerResult(nil, true, 0))
+}
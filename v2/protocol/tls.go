@@ -0,0 +1,121 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+)
+
+// TLSOption configures the TLS connections of the protocol bindings. The
+// same options are accepted by the HTTP, Kafka, NATS, MQTT and AMQP
+// bindings, see NewTLSConfig.
+type TLSOption func(*tls.Config) error
+
+// WithTLSRootCAs sets the certificate authorities used to verify the
+// certificate of the server, instead of the system ones.
+func WithTLSRootCAs(pool *x509.CertPool) TLSOption {
+	return func(c *tls.Config) error {
+		if pool == nil {
+			return fmt.Errorf("tls option was given a nil root CA pool")
+		}
+		c.RootCAs = pool
+		return nil
+	}
+}
+
+// WithTLSRootCAFile adds the PEM encoded certificate authorities of the
+// file to the ones used to verify the certificate of the server. The system
+// ones are not used anymore.
+func WithTLSRootCAFile(path string) TLSOption {
+	return func(c *tls.Config) error {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read the root CAs: %w", err)
+		}
+		if c.RootCAs == nil {
+			c.RootCAs = x509.NewCertPool()
+		}
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in %s", path)
+		}
+		return nil
+	}
+}
+
+// WithTLSClientCertificate sets the certificate presented to the server.
+func WithTLSClientCertificate(cert tls.Certificate) TLSOption {
+	return func(c *tls.Config) error {
+		c.Certificates = append(c.Certificates, cert)
+		return nil
+	}
+}
+
+// WithTLSClientKeyPair sets the certificate presented to the server from a
+// pair of PEM encoded files.
+func WithTLSClientKeyPair(certFile, keyFile string) TLSOption {
+	return func(c *tls.Config) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		c.Certificates = append(c.Certificates, cert)
+		return nil
+	}
+}
+
+// WithTLSServerName overrides the name sent with SNI and expected in the
+// certificate of the server, which is otherwise derived from the address
+// connected to.
+func WithTLSServerName(name string) TLSOption {
+	return func(c *tls.Config) error {
+		c.ServerName = name
+		return nil
+	}
+}
+
+// WithTLSMinVersion sets the minimum TLS version, e.g. tls.VersionTLS13. It
+// is tls.VersionTLS12 by default.
+func WithTLSMinVersion(version uint16) TLSOption {
+	return func(c *tls.Config) error {
+		if version < tls.VersionTLS10 || version > tls.VersionTLS13 {
+			return fmt.Errorf("tls option was given an unknown version: %#x", version)
+		}
+		c.MinVersion = version
+		return nil
+	}
+}
+
+// WithTLSInsecureSkipVerify disables the verification of the certificate of
+// the server, making the connections vulnerable to man-in-the-middle
+// attacks. It must only be used for testing, a warning is logged whenever a
+// configuration using it is created.
+func WithTLSInsecureSkipVerify() TLSOption {
+	return func(c *tls.Config) error {
+		c.InsecureSkipVerify = true
+		return nil
+	}
+}
+
+// NewTLSConfig returns the TLS configuration of a client built from opts.
+func NewTLSConfig(opts ...TLSOption) (*tls.Config, error) {
+	c := &tls.Config{MinVersion: tls.VersionTLS12}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.InsecureSkipVerify {
+		cecontext.LoggerFrom(context.Background()).Warnw("TLS certificate verification is DISABLED, the connections are not secure: do not use WithTLSInsecureSkipVerify in production",
+			"serverName", c.ServerName)
+	}
+	return c, nil
+}
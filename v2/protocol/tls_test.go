@@ -0,0 +1,78 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+
+	config, err := NewTLSConfig(WithTLSRootCAFile(caFile), WithTLSServerName("example.com"), WithTLSMinVersion(tls.VersionTLS13))
+	require.NoError(t, err)
+	require.Equal(t, "example.com", config.ServerName)
+	require.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	require.False(t, config.InsecureSkipVerify)
+
+	// The test server certificate is valid for example.com.
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	// The system CAs do not trust the test server.
+	config, err = NewTLSConfig()
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	_, err = client.Get(srv.URL)
+	require.ErrorAs(t, err, new(*tls.CertificateVerificationError))
+
+	config, err = NewTLSConfig(WithTLSInsecureSkipVerify(), WithTLSClientCertificate(srv.TLS.Certificates[0]))
+	require.NoError(t, err)
+	require.True(t, config.InsecureSkipVerify)
+	require.Len(t, config.Certificates, 1)
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+
+	testCases := map[string]struct {
+		opt     TLSOption
+		wantErr string
+	}{
+		"nil pool":     {opt: WithTLSRootCAs(nil), wantErr: "tls option was given a nil root CA pool"},
+		"no CA":        {opt: WithTLSRootCAFile(empty), wantErr: "no certificate found in " + empty},
+		"bad version":  {opt: WithTLSMinVersion(0x0200), wantErr: "tls option was given an unknown version: 0x200"},
+		"missing pair": {opt: WithTLSClientKeyPair("missing.pem", "missing.key"), wantErr: "failed to load the client certificate: open missing.pem: no such file or directory"},
+		"valid pool":   {opt: WithTLSRootCAs(x509.NewCertPool())},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			_, err := NewTLSConfig(tc.opt)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// TransportMetadata describes how a message has been received, e.g. for the
+// authorization of its event. The fields not applicable to the transport are
+// left empty.
+type TransportMetadata struct {
+	// PeerCertificates are the certificates presented by the peer, the
+	// leaf first, when the message has been received over mutual TLS.
+	PeerCertificates []*x509.Certificate
+	// Header holds the headers, or the equivalent properties, of the message.
+	Header map[string][]string
+	// Topic is the topic, subject or address the message has been received
+	// from.
+	Topic string
+	// RemoteAddr is the network address of the peer.
+	RemoteAddr string
+	// DeliveryAttempt is the number of times the message has been
+	// delivered, 1 the first time, or 0 if the transport does not count
+	// the deliveries.
+	DeliveryAttempt int
+	// FirstDeliveryTime is the time the message was first delivered, or
+	// published if the transport only records that, zero if unknown.
+	FirstDeliveryTime time.Time
+}
+
+// TransportMetadataReader is implemented by the messages exposing the
+// metadata of the transport they have been received with.
+type TransportMetadataReader interface {
+	TransportMetadata() TransportMetadata
+}
@@ -0,0 +1,17 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package wal implements a durable protocol.Sender for producers with an
+intermittent connectivity, such as edge devices.
+
+Events sent through the Sender are appended to a local write-ahead log before
+Send returns, then delivered in order to the wrapped Sender by Drain, which
+keeps retrying while the target is unavailable. The log is bounded in size and
+age: events exceeding the configured retention are dropped, oldest first.
+
+The events can be encrypted at rest with WithEncryption.
+*/
+package wal
@@ -0,0 +1,132 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package wal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// encryptedVersion prefixes the encrypted records. It can not be mistaken
+// for the first byte of a JSON event.
+const encryptedVersion byte = 1
+
+// errIntegrity is returned when an encrypted record fails authentication.
+var errIntegrity = errors.New("wal: record integrity check failed")
+
+// KeyProvider provides the AES keys encrypting the events stored in the log,
+// see WithEncryption. Keys are identified so that they can be rotated: the
+// events are encrypted with the current key, and decrypted with the key they
+// were encrypted with.
+type KeyProvider interface {
+	// CurrentKey returns the key encrypting the new events, and its id. The
+	// key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or
+	// AES-256. The id can not be longer than 255 bytes.
+	CurrentKey() (id string, key []byte, err error)
+	// Key returns the key with the given id.
+	Key(id string) ([]byte, error)
+}
+
+// StaticKey is a KeyProvider always using the same key.
+type StaticKey []byte
+
+// CurrentKey implements KeyProvider.CurrentKey.
+func (k StaticKey) CurrentKey() (string, []byte, error) {
+	return "", k, nil
+}
+
+// Key implements KeyProvider.Key.
+func (k StaticKey) Key(id string) ([]byte, error) {
+	if id != "" {
+		return nil, fmt.Errorf("wal: unknown key %q", id)
+	}
+	return k, nil
+}
+
+// encryptor encrypts the records with AES-GCM. An encrypted record is laid
+// out as:
+//
+//	| version (1 byte) | key id length (1 byte) | key id | nonce (12 bytes) | ciphertext and tag |
+//
+// where the append time of the record is authenticated as additional data,
+// so that it can not be altered to bypass the maximum age.
+type encryptor struct {
+	keys KeyProvider
+}
+
+func (c *encryptor) encrypt(appended time.Time, data []byte) ([]byte, error) {
+	id, key, err := c.keys.CurrentKey()
+	if err != nil {
+		return nil, fmt.Errorf("wal: failed to get encryption key: %w", err)
+	}
+	if len(id) > 255 {
+		return nil, fmt.Errorf("wal: encryption key id too long, got %d bytes", len(id))
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 2+len(id)+aead.NonceSize()+len(data)+aead.Overhead())
+	out = append(out, encryptedVersion, byte(len(id)))
+	out = append(out, id...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("wal: failed to generate nonce: %w", err)
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, data, additionalData(appended)), nil
+}
+
+// decrypt returns the plaintext of an encrypted record, or errIntegrity if
+// the record is not encrypted or has been altered. Errors of the
+// KeyProvider are returned as is.
+func (c *encryptor) decrypt(appended time.Time, data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != encryptedVersion {
+		return nil, fmt.Errorf("%w: record is not encrypted", errIntegrity)
+	}
+	idLen := int(data[1])
+	if len(data) < 2+idLen {
+		return nil, fmt.Errorf("%w: truncated key id", errIntegrity)
+	}
+	id, data := string(data[2:2+idLen]), data[2+idLen:]
+
+	key, err := c.keys.Key(id)
+	if err != nil {
+		return nil, fmt.Errorf("wal: failed to get decryption key %q: %w", id, err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: truncated nonce", errIntegrity)
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData(appended))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errIntegrity, err)
+	}
+	return plain, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("wal: invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func additionalData(appended time.Time) []byte {
+	var b [timestampSize]byte
+	binary.BigEndian.PutUint64(b[:], uint64(appended.UnixNano()))
+	return b[:]
+}
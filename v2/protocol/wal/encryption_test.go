@@ -0,0 +1,132 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package wal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// rotatingKeys is a KeyProvider whose current key can be changed.
+type rotatingKeys struct {
+	mu      sync.Mutex
+	current string
+	keys    map[string][]byte
+}
+
+func (k *rotatingKeys) CurrentKey() (string, []byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.current, k.keys[k.current], nil
+}
+
+func (k *rotatingKeys) Key(id string) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", id)
+	}
+	return key, nil
+}
+
+func (k *rotatingKeys) rotate(id string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.current = id
+}
+
+func TestSenderEncryption(t *testing.T) {
+	dir := t.TempDir()
+	target := &flakySender{}
+	keys := &rotatingKeys{current: "a", keys: map[string][]byte{
+		"a": bytes.Repeat([]byte{1}, 32),
+		"b": bytes.Repeat([]byte{2}, 16),
+	}}
+
+	s, err := New(dir, target, WithEncryption(keys))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	want := sendEvents(t, s, 0, 3)
+	keys.rotate("b")
+	want = append(want, sendEvents(t, s, 3, 6)...)
+
+	// The events are not stored in clear.
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	require.NoError(t, err)
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.NotContains(t, string(b), test.FullEvent().Source())
+	}
+
+	stop := startDrain(t, s)
+	require.Eventually(t, func() bool { return len(target.received()) == len(want) }, 5*time.Second, time.Millisecond)
+	stop()
+	require.Equal(t, want, target.received())
+}
+
+func TestSenderEncryptionIntegrity(t *testing.T) {
+	dir := t.TempDir()
+	target := &flakySender{}
+
+	// Events not encrypted, or encrypted with another key, are dropped.
+	s, err := New(dir, target)
+	require.NoError(t, err)
+	sendEvents(t, s, 0, 2)
+	require.NoError(t, s.Close(context.Background()))
+	s, err = New(dir, target, WithEncryption(StaticKey(bytes.Repeat([]byte{1}, 16))))
+	require.NoError(t, err)
+	sendEvents(t, s, 2, 4)
+	require.NoError(t, s.Close(context.Background()))
+
+	s, err = New(dir, target, WithEncryption(StaticKey(bytes.Repeat([]byte{2}, 16))))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+	want := sendEvents(t, s, 4, 6)
+
+	stop := startDrain(t, s)
+	require.Eventually(t, func() bool { return s.PendingBytes() == 0 }, 5*time.Second, time.Millisecond)
+	stop()
+	require.Equal(t, want, target.received())
+}
+
+func TestSenderEncryptionUnknownKey(t *testing.T) {
+	dir := t.TempDir()
+	keys := &rotatingKeys{current: "a", keys: map[string][]byte{"a": bytes.Repeat([]byte{1}, 16)}}
+	s, err := New(dir, &flakySender{}, WithEncryption(keys))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+	sendEvents(t, s, 0, 1)
+
+	// The events are kept until the key is available.
+	delete(keys.keys, "a")
+	require.ErrorContains(t, s.Drain(context.Background()), `unknown key "a"`)
+	require.NotZero(t, s.PendingBytes())
+}
+
+func TestWithEncryptionInvalidKey(t *testing.T) {
+	s, err := New(t.TempDir(), &flakySender{}, WithEncryption(StaticKey("short")))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+	e := test.FullEvent()
+	require.ErrorContains(t, s.Send(context.Background(), binding.ToMessage(&e)), "invalid encryption key")
+
+	_, err = New(t.TempDir(), &flakySender{}, WithEncryption(nil))
+	require.EqualError(t, err, "wal key provider can not be nil")
+}
@@ -0,0 +1,448 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package wal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	segmentExt = ".wal"
+	cursorFile = "cursor"
+
+	// headerSize is the size of the record header: payload length and CRC.
+	headerSize = 8
+	// timestampSize is the size of the append timestamp prefixing the payload.
+	timestampSize = 8
+)
+
+var (
+	errEmpty   = errors.New("wal: no pending record")
+	errCorrupt = errors.New("wal: corrupted record")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// record is an entry of the log.
+type record struct {
+	appended time.Time
+	data     []byte
+	// position of the record and of the following one.
+	segment    uint64
+	offset     int64
+	nextOffset int64
+}
+
+type segment struct {
+	id   uint64
+	size int64
+}
+
+// log is an append-only log split in segment files, with a persisted cursor
+// pointing to the next record to consume. Consumed segments are deleted.
+//
+// Each record is laid out as:
+//
+//	| length (4 bytes) | crc32c (4 bytes) | append time (8 bytes) | data |
+//
+// where length and crc32c cover append time and data.
+type log struct {
+	dir             string
+	maxSegmentBytes int64
+	sync            bool
+
+	mu       sync.Mutex
+	segments []segment
+	writer   *os.File
+
+	// cursor position
+	readSegment uint64
+	readOffset  int64
+}
+
+func openLog(dir string, maxSegmentBytes int64, sync bool) (*log, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("wal: failed to create directory: %w", err)
+	}
+	l := &log{
+		dir:             dir,
+		maxSegmentBytes: maxSegmentBytes,
+		sync:            sync,
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("wal: failed to list directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("wal: failed to stat segment %s: %w", name, err)
+		}
+		l.segments = append(l.segments, segment{id: id, size: info.Size()})
+	}
+	sort.Slice(l.segments, func(i, j int) bool { return l.segments[i].id < l.segments[j].id })
+
+	if err := l.loadCursor(); err != nil {
+		return nil, err
+	}
+
+	if len(l.segments) == 0 {
+		if err := l.roll(); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := l.recoverTail(); err != nil {
+			return nil, err
+		}
+		last := l.segments[len(l.segments)-1]
+		l.writer, err = os.OpenFile(l.segmentPath(last.id), os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("wal: failed to open segment: %w", err)
+		}
+	}
+	l.fixCursor()
+	return l, nil
+}
+
+// fixCursor makes sure the cursor points to an existing segment, which might
+// not be the case after a crash or after the files have been manipulated.
+func (l *log) fixCursor() {
+	if idx := l.segmentIndex(l.readSegment); idx >= 0 {
+		if l.readOffset > l.segments[idx].size {
+			l.readOffset = l.segments[idx].size
+		}
+		return
+	}
+	for _, seg := range l.segments {
+		if seg.id > l.readSegment {
+			l.readSegment, l.readOffset = seg.id, 0
+			return
+		}
+	}
+	// Every segment precedes the cursor: they have all been consumed.
+	last := l.segments[len(l.segments)-1]
+	l.readSegment, l.readOffset = last.id, last.size
+}
+
+func (l *log) segmentPath(id uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+// recoverTail truncates a partially written record at the end of the last
+// segment, which happens when the process crashes in the middle of an append.
+func (l *log) recoverTail() error {
+	last := &l.segments[len(l.segments)-1]
+	f, err := os.Open(l.segmentPath(last.id))
+	if err != nil {
+		return fmt.Errorf("wal: failed to open segment: %w", err)
+	}
+	defer f.Close()
+
+	var valid int64
+	for {
+		rec, err := readRecord(f, valid, last.size)
+		if err != nil {
+			break
+		}
+		valid = rec.nextOffset
+	}
+	if valid < last.size {
+		if err := os.Truncate(l.segmentPath(last.id), valid); err != nil {
+			return fmt.Errorf("wal: failed to truncate segment: %w", err)
+		}
+		last.size = valid
+	}
+	return nil
+}
+
+// roll closes the current segment and starts a new one.
+func (l *log) roll() error {
+	var id uint64
+	if len(l.segments) > 0 {
+		id = l.segments[len(l.segments)-1].id + 1
+	}
+	if l.writer != nil {
+		if err := l.writer.Close(); err != nil {
+			return fmt.Errorf("wal: failed to close segment: %w", err)
+		}
+	}
+	f, err := os.OpenFile(l.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("wal: failed to create segment: %w", err)
+	}
+	l.writer = f
+	l.segments = append(l.segments, segment{id: id})
+	return nil
+}
+
+// append writes a record at the end of the log.
+func (l *log) append(appended time.Time, data []byte) error {
+	payloadSize := timestampSize + len(data)
+	buf := make([]byte, headerSize+payloadSize)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(payloadSize))
+	binary.BigEndian.PutUint64(buf[headerSize:headerSize+timestampSize], uint64(appended.UnixNano()))
+	copy(buf[headerSize+timestampSize:], data)
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(buf[headerSize:], crcTable))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.writer == nil {
+		return errors.New("wal: closed")
+	}
+	last := &l.segments[len(l.segments)-1]
+	if last.size > 0 && last.size+int64(len(buf)) > l.maxSegmentBytes {
+		if err := l.roll(); err != nil {
+			return err
+		}
+		last = &l.segments[len(l.segments)-1]
+	}
+	if _, err := l.writer.Write(buf); err != nil {
+		return fmt.Errorf("wal: failed to append record: %w", err)
+	}
+	if l.sync {
+		if err := l.writer.Sync(); err != nil {
+			return fmt.Errorf("wal: failed to sync segment: %w", err)
+		}
+	}
+	last.size += int64(len(buf))
+	return nil
+}
+
+// peek returns the record at the cursor without consuming it.
+// It returns errEmpty if there is no pending record.
+func (l *log) peek() (*record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for {
+		idx := l.segmentIndex(l.readSegment)
+		if idx < 0 {
+			return nil, errEmpty
+		}
+		seg := l.segments[idx]
+		if l.readOffset >= seg.size {
+			if idx == len(l.segments)-1 {
+				return nil, errEmpty
+			}
+			// The segment has been fully consumed, move to the next one.
+			if err := l.moveCursor(l.segments[idx+1].id, 0); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		f, err := os.Open(l.segmentPath(seg.id))
+		if err != nil {
+			return nil, fmt.Errorf("wal: failed to open segment: %w", err)
+		}
+		rec, err := readRecord(f, l.readOffset, seg.size)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w in segment %d at offset %d: %v", errCorrupt, seg.id, l.readOffset, err)
+		}
+		rec.segment = seg.id
+		return rec, nil
+	}
+}
+
+// skipSegment moves the cursor past the current segment. This is used to
+// recover from a corrupted record, losing the rest of the segment.
+func (l *log) skipSegment() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idx := l.segmentIndex(l.readSegment)
+	if idx < 0 {
+		return nil
+	}
+	if idx == len(l.segments)-1 {
+		if err := l.roll(); err != nil {
+			return err
+		}
+	}
+	return l.moveCursor(l.segments[idx+1].id, 0)
+}
+
+// commit consumes the given record, previously returned by peek.
+func (l *log) commit(rec *record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if rec.segment != l.readSegment || rec.offset != l.readOffset {
+		// The record has been dropped by the retention in the meantime.
+		return nil
+	}
+	return l.moveCursor(rec.segment, rec.nextOffset)
+}
+
+// moveCursor persists the new cursor position and deletes the segments
+// preceding it. Must be invoked with mu held.
+func (l *log) moveCursor(segmentID uint64, offset int64) error {
+	l.readSegment, l.readOffset = segmentID, offset
+	if err := l.storeCursor(); err != nil {
+		return err
+	}
+	for len(l.segments) > 1 && l.segments[0].id < segmentID {
+		if err := os.Remove(l.segmentPath(l.segments[0].id)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("wal: failed to delete segment: %w", err)
+		}
+		l.segments = l.segments[1:]
+	}
+	return nil
+}
+
+// dropOldestSegment deletes the oldest segment to enforce the retention,
+// returning the number of pending bytes released. There must be at least two
+// segments. Must be invoked with mu held.
+func (l *log) dropOldestSegment() (int64, error) {
+	dropped := l.segments[0]
+	released := dropped.size
+	if dropped.id == l.readSegment {
+		released -= l.readOffset
+	}
+	if err := l.moveCursor(l.segments[1].id, 0); err != nil {
+		return 0, err
+	}
+	return released, nil
+}
+
+// enforceMaxBytes drops the oldest segments until the pending records fit in
+// maxBytes, returning the number of bytes dropped. The segment being written is
+// never dropped, so the log can exceed maxBytes by up to one segment.
+func (l *log) enforceMaxBytes(maxBytes int64) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var dropped int64
+	for l.pendingBytes() > maxBytes && len(l.segments) > 1 {
+		released, err := l.dropOldestSegment()
+		if err != nil {
+			return dropped, err
+		}
+		dropped += released
+	}
+	return dropped, nil
+}
+
+// size returns the size of the records not consumed yet.
+func (l *log) size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pendingBytes()
+}
+
+// pendingBytes returns the size of the records not consumed yet.
+// Must be invoked with mu held.
+func (l *log) pendingBytes() int64 {
+	var size int64
+	for _, seg := range l.segments {
+		if seg.id < l.readSegment {
+			continue
+		}
+		size += seg.size
+		if seg.id == l.readSegment {
+			size -= l.readOffset
+		}
+	}
+	return size
+}
+
+// segmentIndex returns the index of the segment with the given id, or -1.
+// Must be invoked with mu held.
+func (l *log) segmentIndex(id uint64) int {
+	for i, seg := range l.segments {
+		if seg.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *log) loadCursor() error {
+	b, err := os.ReadFile(filepath.Join(l.dir, cursorFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("wal: failed to read cursor: %w", err)
+	}
+	if _, err := fmt.Sscanf(string(b), "%d %d", &l.readSegment, &l.readOffset); err != nil {
+		return fmt.Errorf("wal: failed to parse cursor: %w", err)
+	}
+	return nil
+}
+
+// storeCursor atomically persists the cursor position.
+func (l *log) storeCursor() error {
+	path := filepath.Join(l.dir, cursorFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d %d", l.readSegment, l.readOffset)), 0o600); err != nil {
+		return fmt.Errorf("wal: failed to write cursor: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("wal: failed to write cursor: %w", err)
+	}
+	return nil
+}
+
+func (l *log) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer == nil {
+		return nil
+	}
+	err := l.writer.Close()
+	l.writer = nil
+	return err
+}
+
+// readRecord reads and verifies the record at the given offset of r, a
+// segment of segmentSize bytes. A record length going past the end of the
+// segment is reported as corrupted.
+func readRecord(r io.ReaderAt, offset, segmentSize int64) (*record, error) {
+	var header [headerSize]byte
+	if _, err := r.ReadAt(header[:], offset); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size < timestampSize || int64(size) > segmentSize-offset-headerSize {
+		return nil, errCorrupt
+	}
+	payload := make([]byte, size)
+	if _, err := r.ReadAt(payload, offset+headerSize); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, errCorrupt
+	}
+	return &record{
+		appended:   time.Unix(0, int64(binary.BigEndian.Uint64(payload[:timestampSize]))),
+		data:       payload[timestampSize:],
+		offset:     offset,
+		nextOffset: offset + headerSize + int64(size),
+	}, nil
+}
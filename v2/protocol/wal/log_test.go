@@ -0,0 +1,31 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package wal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRecordLength(t *testing.T) {
+	for name, size := range map[string]uint32{
+		"too short":        timestampSize - 1,
+		"past segment end": timestampSize + 5,
+		// Not allocated before being checked against the segment size.
+		"max length": math.MaxUint32,
+	} {
+		t.Run(name, func(t *testing.T) {
+			segment := make([]byte, headerSize+timestampSize+4)
+			binary.BigEndian.PutUint32(segment, size)
+			_, err := readRecord(bytes.NewReader(segment), 0, int64(len(segment)))
+			require.ErrorIs(t, err, errCorrupt)
+		})
+	}
+}
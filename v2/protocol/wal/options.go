@@ -0,0 +1,96 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package wal
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultMaxSegmentBytes is the default size after which a new segment file is started.
+	DefaultMaxSegmentBytes = 16 * 1024 * 1024
+	// DefaultRetryInterval is the default delay between two delivery attempts of the same event.
+	DefaultRetryInterval = 5 * time.Second
+)
+
+// Option is the function signature required to be considered a wal.Option.
+type Option func(*Sender) error
+
+// WithMaxSegmentBytes sets the size after which a new segment file is
+// started. Segments are deleted once all their events have been delivered.
+func WithMaxSegmentBytes(size int64) Option {
+	return func(s *Sender) error {
+		if size <= 0 {
+			return fmt.Errorf("wal max segment bytes must be positive, got %d", size)
+		}
+		s.maxSegmentBytes = size
+		return nil
+	}
+}
+
+// WithMaxBytes bounds the size of the pending events. When exceeded, the
+// oldest segments are dropped, losing the events they contain.
+// By default the log is unbounded.
+func WithMaxBytes(size int64) Option {
+	return func(s *Sender) error {
+		if size <= 0 {
+			return fmt.Errorf("wal max bytes must be positive, got %d", size)
+		}
+		s.maxBytes = size
+		return nil
+	}
+}
+
+// WithMaxAge sets the maximum time an event is kept in the log. Events older
+// than maxAge are dropped instead of being delivered.
+// By default events never expire.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(s *Sender) error {
+		if maxAge <= 0 {
+			return fmt.Errorf("wal max age must be positive, got %s", maxAge)
+		}
+		s.maxAge = maxAge
+		return nil
+	}
+}
+
+// WithSync makes Send flush the log to stable storage (fsync) before
+// returning. This protects the events against power losses at the price of
+// a slower Send.
+func WithSync() Option {
+	return func(s *Sender) error {
+		s.sync = true
+		return nil
+	}
+}
+
+// WithRetryInterval sets the delay between two delivery attempts of the same
+// event when the target is unavailable. Default value is DefaultRetryInterval.
+func WithRetryInterval(interval time.Duration) Option {
+	return func(s *Sender) error {
+		if interval <= 0 {
+			return fmt.Errorf("wal retry interval must be positive, got %s", interval)
+		}
+		s.retryInterval = interval
+		return nil
+	}
+}
+
+// WithEncryption encrypts the events stored in the log with AES-GCM, using
+// the keys of the given provider. On replay, the events failing the integrity
+// check, including the events which are not encrypted, are dropped. If the
+// provider fails to return a key, Drain returns the error and the events are
+// kept in the log.
+func WithEncryption(keys KeyProvider) Option {
+	return func(s *Sender) error {
+		if keys == nil {
+			return fmt.Errorf("wal key provider can not be nil")
+		}
+		s.encryptor = &encryptor{keys: keys}
+		return nil
+	}
+}
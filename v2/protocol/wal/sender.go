@@ -0,0 +1,208 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package wal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// Sender is a protocol.Sender persisting events in a local write-ahead log
+// before they are delivered to the target Sender by Drain.
+type Sender struct {
+	target protocol.Sender
+	log    *log
+	notify chan struct{}
+
+	maxSegmentBytes int64
+	maxBytes        int64
+	maxAge          time.Duration
+	sync            bool
+	retryInterval   time.Duration
+	encryptor       *encryptor
+}
+
+// New opens (or creates) the write-ahead log stored in dir and returns a
+// Sender delivering its events to target. Events left in the log by a
+// previous process are delivered first.
+func New(dir string, target protocol.Sender, opts ...Option) (*Sender, error) {
+	if target == nil {
+		return nil, errors.New("wal target can not be nil")
+	}
+	s := &Sender{
+		target:          target,
+		notify:          make(chan struct{}, 1),
+		maxSegmentBytes: DefaultMaxSegmentBytes,
+		retryInterval:   DefaultRetryInterval,
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	l, err := openLog(dir, s.maxSegmentBytes, s.sync)
+	if err != nil {
+		return nil, err
+	}
+	s.log = l
+	return s, nil
+}
+
+// Send appends the event to the log and returns once it has been persisted.
+// A nil error does not mean the event has been delivered to the target.
+// The event is timestamped with the clock of ctx, the timestamp being checked
+// against the max age and authenticated along with the encrypted event.
+func (s *Sender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) (err error) {
+	if ctx == nil {
+		return fmt.Errorf("nil Context")
+	} else if m == nil {
+		return fmt.Errorf("nil Message")
+	}
+	defer func() {
+		if err2 := m.Finish(err); err == nil {
+			err = err2
+		}
+	}()
+
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	b, err := format.JSON.Marshal(e)
+	if err != nil {
+		return err
+	}
+	appended := cecontext.ClockFrom(ctx).Now()
+	if s.encryptor != nil {
+		if b, err = s.encryptor.encrypt(appended, b); err != nil {
+			return err
+		}
+	}
+	if err = s.log.append(appended, b); err != nil {
+		return err
+	}
+	if s.maxBytes > 0 {
+		dropped, err := s.log.enforceMaxBytes(s.maxBytes)
+		if err != nil {
+			return err
+		}
+		if dropped > 0 {
+			cecontext.LoggerFrom(ctx).Warnw("wal size exceeded, dropped oldest events", zap.Int64("bytes", dropped))
+		}
+	}
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Drain delivers the events of the log to the target in order, until ctx is
+// done. An event is removed from the log once the target acknowledged it, or
+// if the target rejected it permanently, see protocol.IsPermanent. On the
+// other failures, including the errors of an unreachable broker, the event is
+// kept and Drain waits for the retry interval before trying again.
+// The age of the events and the retry interval are measured on the clock of
+// ctx. This call is blocking and must not be invoked concurrently.
+func (s *Sender) Drain(ctx context.Context) error {
+	logger := cecontext.LoggerFrom(ctx)
+	clock := cecontext.ClockFrom(ctx)
+	for {
+		rec, err := s.log.peek()
+		switch {
+		case errors.Is(err, errEmpty):
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-s.notify:
+			}
+			continue
+		case errors.Is(err, errCorrupt):
+			logger.Errorw("wal segment corrupted, skipping the rest of the segment", zap.Error(err))
+			if err := s.log.skipSegment(); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
+			return err
+		}
+
+		if s.maxAge > 0 && clock.Now().Sub(rec.appended) > s.maxAge {
+			logger.Warnw("wal event expired, dropping it", zap.Time("appended", rec.appended))
+			if err := s.log.commit(rec); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data := rec.data
+		if s.encryptor != nil {
+			data, err = s.encryptor.decrypt(rec.appended, rec.data)
+			if errors.Is(err, errIntegrity) {
+				logger.Errorw("wal event failed the integrity check, dropping it", zap.Error(err))
+				if err := s.log.commit(rec); err != nil {
+					return err
+				}
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+
+		e := event.New()
+		if err := format.JSON.Unmarshal(data, &e); err != nil {
+			logger.Errorw("wal event can not be decoded, dropping it", zap.Error(err))
+			if err := s.log.commit(rec); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result := s.target.Send(ctx, binding.ToMessage(&e))
+		if protocol.IsACK(result) || protocol.IsPermanent(result) {
+			if !protocol.IsACK(result) {
+				logger.Warnw("wal event was rejected, dropping it", zap.Error(result), zap.String("id", e.ID()))
+			}
+			if err := s.log.commit(rec); err != nil {
+				return err
+			}
+			continue
+		}
+
+		logger.Debugw("wal event delivery failed, will retry", zap.Error(result), zap.String("id", e.ID()))
+		timer := clock.NewTimer(s.retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C():
+		}
+	}
+}
+
+// PendingBytes returns the size of the events not delivered yet.
+func (s *Sender) PendingBytes() int64 {
+	return s.log.size()
+}
+
+// Close closes the log. Pending events are kept on disk and are delivered
+// when a new Sender is created on the same directory.
+func (s *Sender) Close(ctx context.Context) error {
+	return s.log.close()
+}
+
+var _ protocol.SendCloser = (*Sender)(nil)
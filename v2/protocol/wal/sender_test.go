@@ -0,0 +1,312 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package wal
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+// flakySender NACKs every send while down is true.
+type flakySender struct {
+	mu   sync.Mutex
+	down bool
+	ids  []string
+}
+
+func (s *flakySender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return protocol.NewReceipt(false, "target down")
+	}
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	s.ids = append(s.ids, e.ID())
+	return nil
+}
+
+func (s *flakySender) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func (s *flakySender) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ids...)
+}
+
+func sendEvents(t *testing.T, s *Sender, from, to int) []string {
+	var ids []string
+	for i := from; i < to; i++ {
+		e := test.FullEvent()
+		e.SetID(strconv.Itoa(i))
+		require.NoError(t, s.Send(context.Background(), binding.ToMessage(&e)))
+		ids = append(ids, e.ID())
+	}
+	return ids
+}
+
+func startDrain(t *testing.T, s *Sender) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Drain(ctx)
+	}()
+	return func() {
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+func TestSenderDrainInOrder(t *testing.T) {
+	target := &flakySender{down: true}
+	s, err := New(t.TempDir(), target, WithRetryInterval(time.Millisecond), WithMaxSegmentBytes(1024))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	stop := startDrain(t, s)
+	defer stop()
+
+	want := sendEvents(t, s, 0, 20)
+	require.Greater(t, s.PendingBytes(), int64(0))
+	require.Empty(t, target.received())
+
+	target.setDown(false)
+	require.Eventually(t, func() bool { return len(target.received()) == len(want) }, 5*time.Second, time.Millisecond)
+	require.Equal(t, want, target.received())
+	require.Eventually(t, func() bool { return s.PendingBytes() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestSenderPersistsAcrossRestarts(t *testing.T) {
+	dir := t.TempDir()
+	target := &flakySender{}
+
+	s, err := New(dir, target, WithMaxSegmentBytes(512))
+	require.NoError(t, err)
+	want := sendEvents(t, s, 0, 10)
+	require.NoError(t, s.Close(context.Background()))
+
+	s, err = New(dir, target, WithMaxSegmentBytes(512))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+	want = append(want, sendEvents(t, s, 10, 15)...)
+
+	stop := startDrain(t, s)
+	require.Eventually(t, func() bool { return len(target.received()) == len(want) }, 5*time.Second, time.Millisecond)
+	stop()
+	require.Equal(t, want, target.received())
+
+	// Segments already delivered have been deleted.
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+func TestSenderRecoversTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	target := &flakySender{}
+
+	s, err := New(dir, target)
+	require.NoError(t, err)
+	want := sendEvents(t, s, 0, 3)
+	require.NoError(t, s.Close(context.Background()))
+
+	// Simulate a crash in the middle of an append.
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	require.NoError(t, err)
+	f, err := os.OpenFile(files[0], os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 1, 0, 42})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s, err = New(dir, target)
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+	want = append(want, sendEvents(t, s, 3, 5)...)
+
+	stop := startDrain(t, s)
+	require.Eventually(t, func() bool { return len(target.received()) == len(want) }, 5*time.Second, time.Millisecond)
+	stop()
+	require.Equal(t, want, target.received())
+}
+
+func TestSenderMaxBytes(t *testing.T) {
+	target := &flakySender{}
+	s, err := New(t.TempDir(), target, WithMaxSegmentBytes(1024), WithMaxBytes(2048))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	ids := sendEvents(t, s, 0, 50)
+	require.LessOrEqual(t, s.PendingBytes(), int64(2048+1024))
+
+	stop := startDrain(t, s)
+	require.Eventually(t, func() bool { return s.PendingBytes() == 0 }, 5*time.Second, time.Millisecond)
+	stop()
+
+	got := target.received()
+	require.NotEmpty(t, got)
+	require.Less(t, len(got), len(ids))
+	// The newest events are kept.
+	require.Equal(t, ids[len(ids)-len(got):], got)
+}
+
+func TestSenderMaxAge(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"clear":     nil,
+		"encrypted": {WithEncryption(StaticKey(make([]byte, 16)))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			target := &flakySender{}
+			s, err := New(t.TempDir(), target, append(opts, WithMaxAge(time.Hour))...)
+			require.NoError(t, err)
+			defer s.Close(context.Background())
+
+			// The events are timestamped with the clock of the context.
+			clock := cecontext.NewFakeClock(time.Now())
+			ctx := cecontext.WithClock(context.Background(), clock)
+			send := func(id string) {
+				e := test.FullEvent()
+				e.SetID(id)
+				require.NoError(t, s.Send(ctx, binding.ToMessage(&e)))
+			}
+			send("old")
+			clock.Advance(90 * time.Minute)
+			send("new")
+
+			ctx, cancel := context.WithCancel(ctx)
+			done := make(chan error)
+			go func() { done <- s.Drain(ctx) }()
+			require.Eventually(t, func() bool { return s.PendingBytes() == 0 }, time.Second, time.Millisecond)
+			cancel()
+			require.NoError(t, <-done)
+			require.Equal(t, []string{"new"}, target.received())
+		})
+	}
+}
+
+func TestSenderDropsUndeliverable(t *testing.T) {
+	var received []event.Event
+	var mu sync.Mutex
+	target := senderFunc(func(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+		e, err := binding.ToEvent(ctx, m, transformers...)
+		if err != nil {
+			return err
+		}
+		if e.ID() == "1" {
+			return event.ValidationError{"id": nil}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, *e)
+		return nil
+	})
+	s, err := New(t.TempDir(), target)
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	sendEvents(t, s, 0, 3)
+	stop := startDrain(t, s)
+	require.Eventually(t, func() bool { return s.PendingBytes() == 0 }, time.Second, time.Millisecond)
+	stop()
+	require.Len(t, received, 2)
+}
+
+func TestSenderKeepsTransientFailures(t *testing.T) {
+	for name, failure := range map[string]error{
+		"plain error":     errors.New("dial tcp: connection refused"),
+		"transient":       protocol.NewBrokerResult(errors.New("broker unavailable"), true, 0),
+		"retriable http":  http.NewResult(nethttp.StatusServiceUnavailable, "unavailable"),
+		"unknown failure": protocol.NewReceipt(false, "target down"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var attempts int
+			var received []string
+			target := senderFunc(func(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+				if attempts <= 3 {
+					return failure
+				}
+				e, err := binding.ToEvent(ctx, m, transformers...)
+				if err != nil {
+					return err
+				}
+				received = append(received, e.ID())
+				return nil
+			})
+			s, err := New(t.TempDir(), target, WithRetryInterval(time.Millisecond))
+			require.NoError(t, err)
+			defer s.Close(context.Background())
+
+			ids := sendEvents(t, s, 0, 3)
+			stop := startDrain(t, s)
+			require.Eventually(t, func() bool { return s.PendingBytes() == 0 }, time.Second, time.Millisecond)
+			stop()
+			require.Equal(t, ids, received)
+		})
+	}
+}
+
+func TestSenderDropsRejected(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	target := senderFunc(func(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+		e, err := binding.ToEvent(ctx, m, transformers...)
+		if err != nil {
+			return err
+		}
+		switch e.ID() {
+		case "0":
+			return http.NewResult(nethttp.StatusBadRequest, "bad event")
+		case "1":
+			return protocol.NewBrokerResult(errors.New("message too large"), false, 0)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, e.ID())
+		return nil
+	})
+	s, err := New(t.TempDir(), target, WithRetryInterval(time.Hour))
+	require.NoError(t, err)
+	defer s.Close(context.Background())
+
+	// The rejected events do not block the head of the log.
+	sendEvents(t, s, 0, 3)
+	stop := startDrain(t, s)
+	require.Eventually(t, func() bool { return s.PendingBytes() == 0 }, time.Second, time.Millisecond)
+	stop()
+	require.Equal(t, []string{"2"}, received)
+}
+
+type senderFunc func(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error
+
+func (f senderFunc) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	return f(ctx, m, transformers...)
+}
@@ -0,0 +1,18 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package quarantine keeps the received messages which can not be decoded or
+validated as events, instead of dropping them, so that operators can
+inspect them, fix them and inject them again.
+
+A client configured with client.WithQuarantine captures the raw content of
+each received message, with the metadata of its transport, and puts the
+messages its receiver function can not get in a Store before acknowledging
+them. The entries of the Store can be listed and inspected with Get, fixed
+by editing their attributes or data and putting them back, and sent again
+to any protocol.Sender with Reinject.
+*/
+package quarantine
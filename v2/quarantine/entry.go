@@ -0,0 +1,215 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package quarantine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/binding/spec"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// Entry is the raw content of a quarantined message.
+type Entry struct {
+	ID string `json:"id"`
+	// Received is the time the message was received.
+	Received time.Time `json:"received"`
+	// Reason is the error the message was quarantined for.
+	Reason string `json:"reason,omitempty"`
+
+	// Format is the media type of the event format of a message in the
+	// structured content mode, whose Data is the whole event. It is empty
+	// for a message in the binary content mode.
+	Format string `json:"format,omitempty"`
+	// Attributes are the context attributes and the extensions of a message
+	// in the binary content mode, by name, as received.
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Data is the body of the message.
+	Data []byte `json:"data,omitempty"`
+
+	// Topic, Header and RemoteAddr are the metadata of the transport the
+	// message was received with, if it exposes them.
+	Topic      string              `json:"topic,omitempty"`
+	Header     map[string][]string `json:"header,omitempty"`
+	RemoteAddr string              `json:"remoteAddr,omitempty"`
+}
+
+// Capture reads the raw content of m into a new Entry, without decoding nor
+// validating the event, received now on the clock of ctx. The content of m
+// may only be readable once: read the event from the Message of the Entry
+// instead. A message whose encoding is unknown is captured with its
+// transport metadata only.
+func Capture(ctx context.Context, m binding.Message) (*Entry, error) {
+	e := &Entry{
+		ID:       uuid.New().String(),
+		Received: cecontext.ClockFrom(ctx).Now(),
+	}
+	if r, ok := m.(protocol.TransportMetadataReader); ok {
+		md := r.TransportMetadata()
+		e.Topic, e.Header, e.RemoteAddr = md.Topic, md.Header, md.RemoteAddr
+	}
+	var err error
+	switch m.ReadEncoding() {
+	case binding.EncodingStructured:
+		err = m.ReadStructured(ctx, (*capture)(e))
+	case binding.EncodingBinary, binding.EncodingEvent:
+		e.Attributes = map[string]string{}
+		err = m.ReadBinary(ctx, (*capture)(e))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture message: %w", err)
+	}
+	return e, nil
+}
+
+// capture is a binding writer copying a message as received into an Entry.
+type capture Entry
+
+func (c *capture) SetStructuredEvent(ctx context.Context, f format.Format, event io.Reader) error {
+	data, err := io.ReadAll(event)
+	c.Format, c.Data = f.MediaType(), data
+	return err
+}
+
+func (c *capture) Start(ctx context.Context) error { return nil }
+
+func (c *capture) End(ctx context.Context) error { return nil }
+
+func (c *capture) SetAttribute(attribute spec.Attribute, value interface{}) error {
+	return c.SetExtension(attribute.Name(), value)
+}
+
+func (c *capture) SetExtension(name string, value interface{}) error {
+	if value == nil {
+		delete(c.Attributes, name)
+		return nil
+	}
+	s, err := types.Format(value)
+	if err != nil {
+		s = fmt.Sprint(value)
+	}
+	c.Attributes[name] = s
+	return nil
+}
+
+func (c *capture) SetData(data io.Reader) error {
+	b, err := io.ReadAll(data)
+	c.Data = b
+	return err
+}
+
+// Message returns a message with the content of e, which can be read
+// several times. Reading it fails as the original message did unless e has
+// been fixed.
+func (e *Entry) Message() binding.Message {
+	return (*entryMessage)(e)
+}
+
+// Event decodes and validates the event of e, e.g. to check a fix.
+func (e *Entry) Event(ctx context.Context) (*event.Event, error) {
+	ev, err := binding.ToEvent(ctx, e.Message())
+	if err != nil {
+		return nil, err
+	}
+	if err := ev.Validate(); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+type entryMessage Entry
+
+var _ binding.MessageMetadataReader = (*entryMessage)(nil)
+
+func (m *entryMessage) ReadEncoding() binding.Encoding {
+	switch {
+	case m.Format != "":
+		return binding.EncodingStructured
+	case m.Attributes != nil:
+		return binding.EncodingBinary
+	}
+	return binding.EncodingUnknown
+}
+
+func (m *entryMessage) ReadStructured(ctx context.Context, w binding.StructuredWriter) error {
+	if m.Format == "" {
+		return binding.ErrNotStructured
+	}
+	f := format.Lookup(m.Format)
+	if f == nil {
+		return fmt.Errorf("unknown event format %q", m.Format)
+	}
+	return w.SetStructuredEvent(ctx, f, bytes.NewReader(m.Data))
+}
+
+func (m *entryMessage) GetAttribute(k spec.Kind) (spec.Attribute, interface{}) {
+	specVersion := spec.VS.Version(m.Attributes["specversion"])
+	if specVersion == nil {
+		return nil, nil
+	}
+	attr := specVersion.AttributeFromKind(k)
+	if attr == nil {
+		return nil, nil
+	}
+	if value, ok := m.Attributes[attr.Name()]; ok {
+		return attr, value
+	}
+	return attr, nil
+}
+
+func (m *entryMessage) GetExtension(name string) interface{} {
+	if value, ok := m.Attributes[name]; ok {
+		return value
+	}
+	return nil
+}
+
+func (m *entryMessage) ReadBinary(ctx context.Context, w binding.BinaryWriter) error {
+	if m.Attributes == nil {
+		return binding.ErrNotBinary
+	}
+	specVersion := spec.VS.Version(m.Attributes["specversion"])
+	if specVersion == nil {
+		return fmt.Errorf("unknown specversion %q", m.Attributes["specversion"])
+	}
+	if err := w.Start(ctx); err != nil {
+		return err
+	}
+	// The specversion selects the context of the other attributes.
+	if err := w.SetAttribute(specVersion.AttributeFromKind(spec.SpecVersion), specVersion.String()); err != nil {
+		return err
+	}
+	for name, value := range m.Attributes {
+		var err error
+		if attr := specVersion.Attribute(name); attr == nil {
+			err = w.SetExtension(name, value)
+		} else if attr.Kind() != spec.SpecVersion {
+			err = w.SetAttribute(attr, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(m.Data) > 0 {
+		if err := w.SetData(bytes.NewReader(m.Data)); err != nil {
+			return err
+		}
+	}
+	return w.End(ctx)
+}
+
+func (m *entryMessage) Finish(error) error { return nil }
@@ -0,0 +1,86 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package quarantine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	bindingtest "github.com/cloudevents/sdk-go/v2/binding/test"
+	cecontext "github.com/cloudevents/sdk-go/v2/context"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestCaptureRoundTrip(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := cecontext.WithClock(context.Background(), cecontext.NewFakeClock(now))
+	e := test.FullEvent()
+
+	for name, m := range map[string]binding.Message{
+		"binary":     bindingtest.MustCreateMockBinaryMessage(e),
+		"structured": bindingtest.MustCreateMockStructuredMessage(t, e),
+		"event":      binding.ToMessage(&e),
+	} {
+		t.Run(name, func(t *testing.T) {
+			entry, err := Capture(ctx, m)
+			require.NoError(t, err)
+			require.NotEmpty(t, entry.ID)
+			require.True(t, now.Equal(entry.Received))
+
+			// The message of the entry can be read several times.
+			for i := 0; i < 2; i++ {
+				got, err := entry.Event(ctx)
+				require.NoError(t, err)
+				// Extensions are read back as their canonical strings.
+				test.AssertEventEquals(t, test.ConvertEventExtensionsToString(t, e), test.ConvertEventExtensionsToString(t, *got))
+			}
+		})
+	}
+}
+
+func TestCaptureInvalid(t *testing.T) {
+	e := test.FullEvent()
+	e.Context.(*event.EventContextV1).ID = ""
+	entry, err := Capture(context.Background(), bindingtest.MustCreateMockBinaryMessage(e))
+	require.NoError(t, err)
+	require.Equal(t, "", entry.Attributes["id"])
+	require.Equal(t, event.CloudEventsVersionV1, entry.Attributes["specversion"])
+
+	_, err = entry.Event(context.Background())
+	require.Error(t, err)
+
+	// Fix the entry.
+	entry.Attributes["id"] = "fixed"
+	got, err := entry.Event(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "fixed", got.ID())
+}
+
+func TestCaptureTransportMetadata(t *testing.T) {
+	e := test.FullEvent()
+	entry, err := Capture(context.Background(), metadataMessage{binding.ToMessage(&e).(*binding.EventMessage)})
+	require.NoError(t, err)
+	require.Equal(t, "orders", entry.Topic)
+	require.Equal(t, []string{"v"}, entry.Header["K"])
+}
+
+type metadataMessage struct {
+	*binding.EventMessage
+}
+
+func (m metadataMessage) GetWrappedMessage() binding.Message {
+	return m.EventMessage
+}
+
+func (m metadataMessage) TransportMetadata() protocol.TransportMetadata {
+	return protocol.TransportMetadata{Topic: "orders", Header: map[string][]string{"K": {"v"}}}
+}
@@ -0,0 +1,108 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// ErrNotFound is returned when a Store has no entry with the given ID.
+var ErrNotFound = errors.New("quarantine entry not found")
+
+// Store persists quarantined entries.
+type Store interface {
+	// Put adds e to the store, or replaces the entry with the same ID.
+	Put(ctx context.Context, e *Entry) error
+	// Get returns the entry with the given ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Entry, error)
+	// List returns the entries of the store, oldest first.
+	List(ctx context.Context) ([]*Entry, error)
+	// Delete removes the entry with the given ID, or returns ErrNotFound.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore is a Store keeping the entries in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*Entry
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*Entry{}}
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(ctx context.Context, e *Entry) error {
+	c := *e
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[e.ID] = &c
+	return nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *e
+	return &c, nil
+}
+
+// List implements Store.
+func (s *MemoryStore) List(ctx context.Context) ([]*Entry, error) {
+	s.mu.Lock()
+	entries := make([]*Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		c := *e
+		entries = append(entries, &c)
+	}
+	s.mu.Unlock()
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Received.Equal(entries[j].Received) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].Received.Before(entries[j].Received)
+	})
+	return entries, nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.entries, id)
+	return nil
+}
+
+// Reinject sends the message of the entry with the given ID to sender, and
+// removes the entry from store once sender acknowledged it.
+func Reinject(ctx context.Context, store Store, id string, sender protocol.Sender, transformers ...binding.Transformer) error {
+	e, err := store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if result := sender.Send(ctx, e.Message(), transformers...); !protocol.IsACK(result) {
+		return fmt.Errorf("failed to reinject quarantine entry %s: %w", id, result)
+	}
+	return store.Delete(ctx, id)
+}
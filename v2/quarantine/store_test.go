@@ -0,0 +1,81 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/test"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	t0 := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, s.Put(ctx, &Entry{ID: "b", Received: t0.Add(time.Second)}))
+	require.NoError(t, s.Put(ctx, &Entry{ID: "a", Received: t0}))
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "a", entries[0].ID)
+	require.Equal(t, "b", entries[1].ID)
+
+	e, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	e.Reason = "changed"
+	got, _ := s.Get(ctx, "a")
+	require.Empty(t, got.Reason, "entries must be copied")
+
+	require.NoError(t, s.Delete(ctx, "a"))
+	_, err = s.Get(ctx, "a")
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.True(t, errors.Is(s.Delete(ctx, "a"), ErrNotFound))
+}
+
+type sender struct {
+	result error
+	sent   []string
+}
+
+func (s *sender) Send(ctx context.Context, m binding.Message, transformers ...binding.Transformer) error {
+	e, err := binding.ToEvent(ctx, m, transformers...)
+	if err != nil {
+		return err
+	}
+	s.sent = append(s.sent, e.ID())
+	return s.result
+}
+
+func TestReinject(t *testing.T) {
+	ctx := context.Background()
+	e := test.FullEvent()
+	entry, err := Capture(ctx, binding.ToMessage(&e))
+	require.NoError(t, err)
+	s := NewMemoryStore()
+	require.NoError(t, s.Put(ctx, entry))
+
+	nack := &sender{result: protocol.ResultNACK}
+	require.Error(t, Reinject(ctx, s, entry.ID, nack))
+	require.Equal(t, []string{e.ID()}, nack.sent)
+	_, err = s.Get(ctx, entry.ID)
+	require.NoError(t, err, "a NACKed entry must stay quarantined")
+
+	ack := &sender{}
+	require.NoError(t, Reinject(ctx, s, entry.ID, ack))
+	require.Equal(t, []string{e.ID()}, ack.sent)
+	_, err = s.Get(ctx, entry.ID)
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	require.True(t, errors.Is(Reinject(ctx, s, entry.ID, ack), ErrNotFound))
+}
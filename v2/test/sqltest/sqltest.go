@@ -0,0 +1,139 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package sqltest provides a fake database/sql driver for the tests of the
+// packages backed by a SQL database, whose connections serve the statements
+// of the package under test.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// Conn is a connection of a fake database, executing the statements of the
+// package under test. The prepared statements are executed with ExecContext
+// and QueryContext as well.
+//
+// A Conn may also implement Begin() (driver.Tx, error) to support the
+// transactions, io.Closer to be notified when the connection is closed, and
+// driver.Pinger to report a broken connection.
+type Conn interface {
+	driver.ExecerContext
+	driver.QueryerContext
+}
+
+// Open returns a *sql.DB whose connections are returned by connect. The
+// *sql.DB is closed at the end of the test.
+func Open(t testing.TB, connect func() Conn) *sql.DB {
+	db := sql.OpenDB(connector{connect: connect})
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// Rows returns the rows of the given columns, as returned by a query.
+func Rows(columns []string, rows ...[]driver.Value) driver.Rows {
+	return &fakeRows{columns: columns, rows: rows}
+}
+
+type connector struct {
+	connect func() Conn
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return conn{c.connect()}, nil
+}
+
+func (c connector) Driver() driver.Driver { return c }
+
+func (c connector) Open(string) (driver.Conn, error) {
+	return c.Connect(context.Background())
+}
+
+type conn struct {
+	Conn
+}
+
+func (c conn) Prepare(query string) (driver.Stmt, error) {
+	return stmt{conn: c.Conn, query: query}, nil
+}
+
+func (c conn) Begin() (driver.Tx, error) {
+	if b, ok := c.Conn.(interface{ Begin() (driver.Tx, error) }); ok {
+		return b.Begin()
+	}
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+func (c conn) Close() error {
+	if closer, ok := c.Conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (c conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// stmt is a prepared statement, executed by its connection.
+type stmt struct {
+	conn  Conn
+	query string
+}
+
+func (s stmt) Close() error { return nil }
+
+// NumInput returns -1, so that database/sql does not check the arguments.
+func (s stmt) NumInput() int { return -1 }
+
+func (s stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,12 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package postgres implements a webhook.Store persisting the subscriptions of
+// the webhook.Manager in a PostgreSQL table, shared by the replicas of a
+// producer, and a webhook.LeaderElector electing the replica sweeping them
+// with an advisory lock. It only depends on database/sql: the caller
+// registers the driver of its choice (e.g. github.com/jackc/pgx/v5/stdlib or
+// github.com/lib/pq) and opens the *sql.DB.
+package postgres
@@ -0,0 +1,124 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/test/sqltest"
+)
+
+// fakeDB is a table of subscriptions and an advisory lock, served by a
+// database/sql driver understanding the statements of the package.
+type fakeDB struct {
+	mu     sync.Mutex
+	rows   map[string]fakeRow
+	holder *fakeConn
+}
+
+type fakeRow struct {
+	created      time.Time
+	subscription string
+}
+
+// newFakeDB returns an empty fakeDB and a *sql.DB connected to it.
+func newFakeDB(t *testing.T) (*fakeDB, *sql.DB) {
+	f := &fakeDB{rows: map[string]fakeRow{}}
+	return f, sqltest.Open(t, func() sqltest.Conn { return &fakeConn{db: f} })
+}
+
+type fakeConn struct {
+	db     *fakeDB
+	broken bool
+}
+
+// Close ends the session, releasing its lock.
+func (c *fakeConn) Close() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if c.db.holder == c {
+		c.db.holder = nil
+	}
+	return nil
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	if c.broken {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query = strings.TrimSpace(query)
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+	case strings.HasPrefix(query, "INSERT INTO"):
+		f.rows[args[0].Value.(string)] = fakeRow{created: args[1].Value.(time.Time), subscription: args[2].Value.(string)}
+	case strings.HasPrefix(query, "DELETE FROM"):
+		id := args[0].Value.(string)
+		if _, ok := f.rows[id]; !ok {
+			return driver.RowsAffected(0), nil
+		}
+		delete(f.rows, id)
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, "SELECT pg_advisory_unlock"):
+		if f.holder == c {
+			f.holder = nil
+		}
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "SELECT pg_try_advisory_lock"):
+		if f.holder == nil {
+			f.holder = c
+		}
+		return sqltest.Rows([]string{"locked"}, []driver.Value{f.holder == c}), nil
+	case strings.Contains(query, "WHERE id = $1"):
+		var rows [][]driver.Value
+		if row, ok := f.rows[args[0].Value.(string)]; ok {
+			rows = append(rows, []driver.Value{[]byte(row.subscription)})
+		}
+		return sqltest.Rows([]string{"subscription"}, rows...), nil
+	case strings.Contains(query, "ORDER BY created, id"):
+		ids := make([]string, 0, len(f.rows))
+		for id := range f.rows {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			a, b := f.rows[ids[i]], f.rows[ids[j]]
+			if !a.created.Equal(b.created) {
+				return a.created.Before(b.created)
+			}
+			return ids[i] < ids[j]
+		})
+		var rows [][]driver.Value
+		for _, id := range ids {
+			rows = append(rows, []driver.Value{[]byte(f.rows[id].subscription)})
+		}
+		return sqltest.Rows([]string{"subscription"}, rows...), nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
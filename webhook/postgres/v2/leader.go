@@ -0,0 +1,100 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/protocol/http/webhook"
+)
+
+// LockElector is a webhook.LeaderElector electing the replica holding a
+// session advisory lock: the first replica to take it leads until it resigns,
+// or its connection is lost, the lock being released with it.
+type LockElector struct {
+	db   *sql.DB
+	name string
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewLockElector returns a LockElector campaigning for the advisory lock of
+// name, the hash of the name being the key of the lock.
+func NewLockElector(db *sql.DB, name string) (*LockElector, error) {
+	if db == nil {
+		return nil, errors.New("postgres db can not be nil")
+	}
+	if name == "" {
+		return nil, errors.New("postgres lock name can not be empty")
+	}
+	return &LockElector{db: db, name: name}, nil
+}
+
+// IsLeader implements webhook.LeaderElector.IsLeader: it checks that the
+// connection holding the lock is alive, or tries to take the lock.
+func (l *LockElector) IsLeader(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn != nil {
+		err := l.conn.PingContext(ctx)
+		if err == nil {
+			return true, nil
+		}
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// The lock is lost with the connection.
+		discard(l.conn)
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("webhook: failed to campaign for lock %q: %w", l.name, err)
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, l.name).Scan(&locked); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("webhook: failed to campaign for lock %q: %w", l.name, err)
+	}
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Resign releases the lock, if held, so that another replica leads.
+func (l *LockElector) Resign(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return nil
+	}
+	conn := l.conn
+	l.conn = nil
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, l.name); err != nil {
+		discard(conn)
+		return fmt.Errorf("webhook: failed to release lock %q: %w", l.name, err)
+	}
+	return conn.Close()
+}
+
+// discard closes conn instead of returning it to the pool, with the locks of
+// its session.
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+var _ webhook.LeaderElector = (*LockElector)(nil)
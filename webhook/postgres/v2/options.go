@@ -0,0 +1,31 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultTable is the name of the table used when WithTable is not provided.
+const DefaultTable = "webhook_subscriptions"
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Option is the function signature required to be considered an postgres.Option.
+type Option func(*Store) error
+
+// WithTable sets the name of the table storing the subscriptions, optionally
+// qualified by a schema.
+func WithTable(name string) Option {
+	return func(s *Store) error {
+		if !tableName.MatchString(name) {
+			return fmt.Errorf("postgres table name %q is invalid", name)
+		}
+		s.table = name
+		return nil
+	}
+}
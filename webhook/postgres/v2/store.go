@@ -0,0 +1,133 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cloudevents/sdk-go/v2/protocol/http/webhook"
+)
+
+// Store is a webhook.Store backed by a PostgreSQL table, see CreateTable for
+// its layout.
+type Store struct {
+	db    *sql.DB
+	table string
+}
+
+// New returns a Store using db. The table must exist, see CreateTable. The
+// Store does not close db.
+func New(db *sql.DB, opts ...Option) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("postgres db can not be nil")
+	}
+	s := &Store{db: db, table: DefaultTable}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// CreateTable creates the table storing the subscriptions, if it does not
+// exist. The created column orders them, see webhook.Store.List.
+func (s *Store) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS `+s.table+` (
+	id           TEXT        PRIMARY KEY,
+	created      TIMESTAMPTZ NOT NULL,
+	subscription JSONB       NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("webhook: failed to create table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+// Put implements webhook.Store.Put.
+func (s *Store) Put(ctx context.Context, sub webhook.Subscription) error {
+	b, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO `+s.table+` (id, created, subscription) VALUES ($1, $2, $3)
+ON CONFLICT (id) DO UPDATE SET created = EXCLUDED.created, subscription = EXCLUDED.subscription`,
+		sub.ID, sub.CreatedAt, string(b))
+	if err != nil {
+		return fmt.Errorf("webhook: failed to put subscription %s: %w", sub.ID, err)
+	}
+	return nil
+}
+
+// Get implements webhook.Store.Get.
+func (s *Store) Get(ctx context.Context, id string) (webhook.Subscription, error) {
+	var b []byte
+	err := s.db.QueryRowContext(ctx, `SELECT subscription FROM `+s.table+` WHERE id = $1`, id).Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return webhook.Subscription{}, webhook.ErrNotFound
+	}
+	if err != nil {
+		return webhook.Subscription{}, fmt.Errorf("webhook: failed to get subscription %s: %w", id, err)
+	}
+	return decode(b)
+}
+
+// Delete implements webhook.Store.Delete.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM `+s.table+` WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("webhook: failed to delete subscription %s: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("webhook: failed to delete subscription %s: %w", id, err)
+	}
+	if n == 0 {
+		return webhook.ErrNotFound
+	}
+	return nil
+}
+
+// List implements webhook.Store.List, sorting the subscriptions by creation
+// time.
+func (s *Store) List(ctx context.Context) ([]webhook.Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT subscription FROM `+s.table+` ORDER BY created, id`)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: failed to list subscriptions: %w", err)
+	}
+	defer rows.Close()
+	subscriptions := []webhook.Subscription{}
+	for rows.Next() {
+		var b []byte
+		if err := rows.Scan(&b); err != nil {
+			return nil, fmt.Errorf("webhook: failed to list subscriptions: %w", err)
+		}
+		sub, err := decode(b)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("webhook: failed to list subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func decode(b []byte) (webhook.Subscription, error) {
+	var sub webhook.Subscription
+	if err := json.Unmarshal(b, &sub); err != nil {
+		return webhook.Subscription{}, fmt.Errorf("webhook: invalid subscription: %w", err)
+	}
+	return sub, nil
+}
+
+var _ webhook.Store = (*Store)(nil)
@@ -0,0 +1,71 @@
+/*
+ Copyright 2024 The CloudEvents Authors
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cloudevents/sdk-go/v2/protocol/http/webhook"
+	"github.com/cloudevents/sdk-go/v2/protocol/http/webhook/test"
+)
+
+func TestStore(t *testing.T) {
+	test.RunStoreTests(t, func(t *testing.T) webhook.Store {
+		_, db := newFakeDB(t)
+		s, err := New(db, WithTable("public.subscriptions"))
+		require.NoError(t, err)
+		require.NoError(t, s.CreateTable(context.Background()))
+		return s
+	})
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New(nil)
+	require.Error(t, err)
+	_, db := newFakeDB(t)
+	_, err = New(db, WithTable("subscriptions; DROP TABLE users"))
+	require.Error(t, err)
+}
+
+func TestLockElector(t *testing.T) {
+	ctx := context.Background()
+	f, db := newFakeDB(t)
+	a, err := NewLockElector(db, "orders")
+	require.NoError(t, err)
+	b, err := NewLockElector(db, "orders")
+	require.NoError(t, err)
+
+	isLeader := func(l *LockElector) bool {
+		leader, err := l.IsLeader(ctx)
+		require.NoError(t, err)
+		return leader
+	}
+	require.True(t, isLeader(a))
+	require.False(t, isLeader(b))
+	require.True(t, isLeader(a))
+
+	// The leader resigns.
+	require.NoError(t, a.Resign(ctx))
+	require.NoError(t, a.Resign(ctx))
+	require.True(t, isLeader(b))
+	require.False(t, isLeader(a))
+
+	// The leader loses its connection, and its lock with it.
+	f.mu.Lock()
+	f.holder.broken = true
+	f.holder = nil
+	f.mu.Unlock()
+	require.True(t, isLeader(a))
+	require.False(t, isLeader(b))
+
+	_, err = NewLockElector(nil, "orders")
+	require.Error(t, err)
+	_, err = NewLockElector(db, "")
+	require.Error(t, err)
+}